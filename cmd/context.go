@@ -0,0 +1,79 @@
+// Package cmd provides the CLI commands for OpenCode.
+// This file implements the context subcommand for previewing the system message.
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/prompt"
+	"github.com/spf13/cobra"
+)
+
+var contextShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Preview the context sent to the model",
+	Long: `Print the system message an agent is sent at the start of a turn: the agent
+prompt, environment and LSP information, and the instruction files listed in
+contextPaths. An estimated token count is shown, and context files are scanned
+for anything that looks like a secret.`,
+	Example: `
+  # Preview the coder agent's context
+  opencode context show
+
+  # Preview another agent in a specific directory
+  opencode context show --agent explorer -c /path/to/project`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		agentID, _ := cmd.Flags().GetString("agent")
+		cwd, _ := cmd.Flags().GetString("cwd")
+
+		if cwd == "" {
+			var err error
+			if cwd, err = os.Getwd(); err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+		}
+		cfg, err := config.Load(cwd, false)
+		if err != nil {
+			return err
+		}
+
+		agentName := config.AgentName(agentID)
+		if _, ok := agentregistry.GetRegistry().Get(agentName); !ok {
+			return fmt.Errorf("unknown agent %q", agentID)
+		}
+		var provider models.ModelProvider
+		if agentCfg, ok := cfg.Agents[agentName]; ok {
+			provider = models.SupportedModels[agentCfg.Model].Provider
+		}
+
+		preview := prompt.PreviewContext(agentName, provider)
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "System message for agent %s (~%d tokens):\n\n%s\n\n", preview.Agent, preview.EstimatedTokens, preview.SystemMessage)
+		if len(preview.ContextFiles) == 0 {
+			fmt.Fprintln(out, "No context files found.")
+		} else {
+			fmt.Fprintf(out, "Context files (%d):\n", len(preview.ContextFiles))
+			for _, path := range preview.ContextFiles {
+				fmt.Fprintf(out, "  %s\n", path)
+			}
+		}
+		if len(preview.SecretWarnings) > 0 {
+			fmt.Fprintf(out, "\n⚠ %d possible secret(s) in context files:\n", len(preview.SecretWarnings))
+			for _, warning := range preview.SecretWarnings {
+				fmt.Fprintf(out, "  %s\n", warning)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	contextShowCmd.Flags().StringP("agent", "a", string(config.AgentCoder), "Agent ID to preview (e.g. coder, hivemind)")
+	contextShowCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+}
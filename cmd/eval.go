@@ -0,0 +1,84 @@
+// Package cmd provides the CLI commands for OpenCode.
+// This file implements the eval subcommand for comparing model outputs.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/agent"
+	"github.com/spf13/cobra"
+)
+
+const defaultEvalWidth = 160
+
+var evalCmd = &cobra.Command{
+	Use:   "eval",
+	Short: "Compare the outputs of several models for one prompt",
+	Long: `Send the same prompt to several models and compare their answers.
+
+Each entry of --models is a model ID, which answers with the prompt of
+--agent, or the name of an agent, which answers with its own model and prompt.
+Every answer is shown beside the first one using the side-by-side diff view,
+followed by the token usage and estimated cost of each model. The prompt is
+sent once, without tools.`,
+	Example: `
+  # Compare two models on the same question
+  opencode eval --models claude-4-sonnet,gpt-4.1 --prompt "Explain the retry logic in client.go"
+
+  # Compare the coder and hivemind agents
+  opencode eval --models coder,hivemind --prompt "Summarize this repository"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		targets, _ := cmd.Flags().GetStringSlice("models")
+		evalPrompt, _ := cmd.Flags().GetString("prompt")
+		agentID, _ := cmd.Flags().GetString("agent")
+		width, _ := cmd.Flags().GetInt("width")
+		cwd, _ := cmd.Flags().GetString("cwd")
+
+		var models []string
+		for _, target := range targets {
+			if target = strings.TrimSpace(target); target != "" {
+				models = append(models, target)
+			}
+		}
+		if len(models) < 2 {
+			return fmt.Errorf("--models needs at least two models or agents to compare, got %d", len(models))
+		}
+		if strings.TrimSpace(evalPrompt) == "" {
+			return fmt.Errorf("--prompt is required")
+		}
+		if width < 40 {
+			return fmt.Errorf("--width must be at least 40, got %d", width)
+		}
+
+		if cwd == "" {
+			var err error
+			if cwd, err = os.Getwd(); err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+
+		results := agent.Evaluate(context.Background(), config.AgentName(agentID), evalPrompt, models)
+		report, err := agent.FormatEvalReport(results, width)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(cmd.OutOrStdout(), report)
+		return agent.EvalError(results)
+	},
+}
+
+func init() {
+	evalCmd.Flags().StringSlice("models", nil, "Comma-separated model IDs or agent names to compare")
+	evalCmd.Flags().StringP("prompt", "p", "", "Prompt to send to every model")
+	evalCmd.Flags().StringP("agent", "a", string(config.AgentCoder), "Agent whose prompt is used for model IDs")
+	evalCmd.Flags().Int("width", defaultEvalWidth, "Total width of the side-by-side view")
+	evalCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+}
@@ -17,10 +17,11 @@ import (
 	"github.com/MerrukTechnology/OpenCode-Native/internal/format"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/agent"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/session"
 )
 
-func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFormat format.OutputFormat, quiet bool) error {
+func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFormat format.OutputFormat, schema map[string]any, quiet bool) error {
 	logging.Info("Running in non-interactive mode")
 
 	var spinner *format.Spinner
@@ -47,6 +48,10 @@ func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFor
 	}
 	title := titlePrefix + titleSuffix
 
+	if err := a.ApplySessionWorkingDir(); err != nil {
+		return err
+	}
+
 	var sess session.Session
 	if a.InitialSession != nil {
 		sess = *a.InitialSession
@@ -69,6 +74,32 @@ func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFor
 
 	a.Permissions.AutoApproveSession(sess.ID)
 
+	var structured *structuredOutput
+	var streamed sync.WaitGroup
+	stopStreaming := func() {}
+	if outputFormat == format.JSONSchema {
+		structured = &structuredOutput{schema: schema, echo: !quiet}
+		if spinner != nil {
+			structured.onFirstDelta = spinner.Stop
+		}
+		var streamCtx context.Context
+		streamCtx, stopStreaming = context.WithCancel(ctx)
+		events := a.Messages.SubscribeWithContext(streamCtx)
+		streamed.Add(1)
+		go func() {
+			defer streamed.Done()
+			for event := range events {
+				if event.Payload.SessionID == sess.ID && event.Payload.Role == message.Assistant {
+					structured.update(event.Payload)
+				}
+			}
+		}()
+		defer func() {
+			stopStreaming()
+			streamed.Wait()
+		}()
+	}
+
 	done, err := a.ActiveAgent().Run(ctx, sess.ID, prompt)
 	if err != nil {
 		return fmt.Errorf("failed to start agent processing stream for session %s: %w", sess.ID, err)
@@ -90,11 +121,21 @@ func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFor
 	content := "No content available"
 
 	if outputFormat == format.JSONSchema {
+		// The stream is drained before the final message is read
+		stopStreaming()
+		streamed.Wait()
+		var outputErr error
 		if result.StructOutput != nil {
-			content = result.StructOutput.Content
+			structured.endEcho()
+			content, outputErr = validateStructOutput(schema, result.StructOutput.Content)
 		} else {
-			logging.Error("Failed to get structured output response for a provided schema", "error", "result.StructOutput is nil")
-			content = `{"error": "no structured output result found"}`
+			content, outputErr = structured.content(result.Message)
+		}
+		if errors.Is(outputErr, format.ErrIncompleteJSON) {
+			return fmt.Errorf("no structured output result found for session %s", sess.ID)
+		}
+		if outputErr != nil {
+			return fmt.Errorf("structured output for session %s: %w", sess.ID, outputErr)
 		}
 	} else if result.Message.Content().String() != "" {
 		content = result.Message.Content().String()
@@ -106,6 +147,82 @@ func runNonInteractive(ctx context.Context, a *app.App, prompt string, outputFor
 	return nil
 }
 
+// structuredOutput assembles the json_schema output of a non-interactive run
+// from the text of the assistant's messages as it streams. Each assistant
+// message starts a new value, so only the final answer makes up the result.
+type structuredOutput struct {
+	schema map[string]any
+	// echo writes the streamed text to stderr while the run is in progress.
+	echo bool
+	// onFirstDelta is called before the first streamed text is echoed.
+	onFirstDelta func()
+
+	messageID string
+	assembler *format.JSONAssembler
+	seen      int
+}
+
+// update feeds the text msg gained since the previous update.
+func (s *structuredOutput) update(msg message.Message) {
+	if msg.ID != s.messageID || s.assembler == nil {
+		s.endEcho()
+		s.messageID, s.seen = msg.ID, 0
+		s.assembler = format.NewJSONAssembler(s.schema)
+	}
+	text := msg.Content().String()
+	if len(text) <= s.seen {
+		return
+	}
+	delta := text[s.seen:]
+	s.seen = len(text)
+	// Decode and schema errors are reported by content
+	_, _ = s.assembler.Write(delta)
+	if s.echo {
+		if s.onFirstDelta != nil {
+			s.onFirstDelta()
+			s.onFirstDelta = nil
+		}
+		fmt.Fprint(os.Stderr, delta)
+	}
+}
+
+// endEcho ends the line of echoed text, if any.
+func (s *structuredOutput) endEcho() {
+	if s.echo && s.seen > 0 {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+// content feeds the rest of the final message, whose updates the stream may
+// have dropped, and returns its validated value. It is called once the
+// stream is drained.
+func (s *structuredOutput) content(final message.Message) (string, error) {
+	s.endEcho()
+	s.echo = false
+	s.update(final)
+	return s.assembler.Content()
+}
+
+// validateStructOutput checks the output of the struct_output tool against
+// schema and returns it unchanged when it matches.
+func validateStructOutput(schema map[string]any, content string) (string, error) {
+	var value any
+	if err := json.Unmarshal([]byte(content), &value); err != nil {
+		return "", fmt.Errorf("invalid JSON output: %w", err)
+	}
+	// Schemas other than objects with properties are passed to the tool
+	// wrapped in an "output" parameter
+	if properties, _ := schema["properties"].(map[string]any); schema["type"] != "object" || properties == nil {
+		if object, ok := value.(map[string]any); ok {
+			value = object["output"]
+		}
+	}
+	if err := format.ValidateValue(schema, value); err != nil {
+		return "", fmt.Errorf("output does not match the schema: %w", err)
+	}
+	return content, nil
+}
+
 func runFlowNonInteractive(ctx context.Context, a *app.App, flowID, prompt, sessionID string, fresh bool, argPairs []string, argsFile string, quiet bool) error {
 	var spinner *format.Spinner
 	if !quiet {
@@ -0,0 +1,139 @@
+// Package cmd provides the CLI commands for OpenCode.
+// This file implements the gc subcommand for pruning stale file history.
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/db"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultGCOlderThan    = "30d"
+	defaultGCKeepVersions = 10
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Prune old file history versions",
+	Long: `Prune file history versions stored in the session database.
+
+For every file of a session, the newest --keep-versions versions are kept,
+along with the initial version that diffs and discards compare against; older
+versions created before --older-than are removed. Without --yes the command only
+reports what would be removed.`,
+	Example: `
+  # Show what would be pruned
+  opencode gc --older-than 30d --keep-versions 10
+
+  # Prune for real
+  opencode gc --older-than 30d --keep-versions 10 --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		olderThanStr, _ := cmd.Flags().GetString("older-than")
+		keepVersions, _ := cmd.Flags().GetInt("keep-versions")
+		yes, _ := cmd.Flags().GetBool("yes")
+		cwd, _ := cmd.Flags().GetString("cwd")
+
+		olderThan, err := parseAge(olderThanStr)
+		if err != nil {
+			return fmt.Errorf("invalid --older-than value %q: %w", olderThanStr, err)
+		}
+		if keepVersions < 1 {
+			return fmt.Errorf("--keep-versions must be at least 1, got %d", keepVersions)
+		}
+
+		if cwd == "" {
+			if cwd, err = os.Getwd(); err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		conn, err := db.Connect(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		files := history.NewService(db.NewQuerier(conn), conn)
+		result, err := files.Prune(ctx, history.PruneOptions{
+			KeepVersions: keepVersions,
+			OlderThan:    olderThan,
+			DryRun:       !yes,
+		})
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(result.Pruned) == 0 {
+			fmt.Fprintln(out, "Nothing to prune.")
+			return nil
+		}
+		if !yes {
+			for _, f := range result.Pruned {
+				fmt.Fprintf(out, "  %s (%s, %s)\n", f.Path, f.Version, time.Unix(f.CreatedAt, 0).Format(time.DateTime))
+			}
+			fmt.Fprintf(out, "Would prune %d version(s), reclaiming %s. Run again with --yes to prune.\n",
+				len(result.Pruned), formatBytes(result.ReclaimedBytes))
+			return nil
+		}
+		fmt.Fprintf(out, "Pruned %d version(s), reclaimed %s.\n", len(result.Pruned), formatBytes(result.ReclaimedBytes))
+		return nil
+	},
+}
+
+func init() {
+	gcCmd.Flags().String("older-than", defaultGCOlderThan, "Only prune versions older than this age (e.g. 30d, 12h; 0 for any age)")
+	gcCmd.Flags().Int("keep-versions", defaultGCKeepVersions, "Number of newest versions to keep per file")
+	gcCmd.Flags().Bool("yes", false, "Delete the versions instead of only reporting them")
+	gcCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+}
+
+// parseAge parses a duration that may also use a day suffix, e.g. "30d".
+func parseAge(s string) (time.Duration, error) {
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected a non-negative number of days")
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("age must not be negative")
+	}
+	return d, nil
+}
+
+// formatBytes renders a byte count in a human-readable unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -14,8 +14,11 @@ import (
 	"github.com/MerrukTechnology/OpenCode-Native/internal/db"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/flow"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/format"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/install"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/pubsub"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/skill"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/tui"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/version"
 	tea "github.com/charmbracelet/bubbletea"
@@ -63,9 +66,18 @@ Key Features:
   # Run a single non-interactive prompt with JSON output format
   opencode -p "Explain the use of context in Go" -f json
 
+  # Run a non-interactive prompt with a specific agent (defaults to defaultAgent)
+  opencode -p "Plan the migration to the new API" -a hivemind
+
+  # Run a non-interactive prompt with high reasoning effort for this prompt only
+  opencode -p "Find the cause of this race condition" --reasoning-effort high
+
   # Run a non-interactive prompt with a 5-minute timeout
   opencode -p "Refactor this module" --timeout 5m
 
+  # Run the session in another directory, keeping the configuration of this one
+  opencode -w ../other-checkout
+
   # Run with a custom project ID to tag sessions
   opencode -P my-project-id
   `,
@@ -94,6 +106,8 @@ Key Features:
 		argsFile, _ := cmd.Flags().GetString("args-file")
 		timeoutStr, _ := cmd.Flags().GetString("timeout")
 		projectID, _ := cmd.Flags().GetString("project-id")
+		reasoningEffort, _ := cmd.Flags().GetString("reasoning-effort")
+		workDir, _ := cmd.Flags().GetString("workdir")
 
 		if deleteSession && sessionID == "" && flowID == "" {
 			return errors.New("--delete requires --session/-s or --flow/-F to be specified")
@@ -107,11 +121,26 @@ Key Features:
 		if len(flowArgs) > 0 && argsFile != "" {
 			return errors.New("--arg/-A and --args-file are mutually exclusive; use only one")
 		}
+		if workDir != "" && flowID != "" {
+			return errors.New("--workdir/-w cannot be used with --flow/-F")
+		}
+		if reasoningEffort != "" && (prompt == "" || flowID != "") {
+			return errors.New("--reasoning-effort requires --prompt/-p to be specified")
+		}
+		if reasoningEffort != "" && !provider.IsValidReasoningEffort(reasoningEffort) {
+			return fmt.Errorf("invalid --reasoning-effort value %q (use low, medium or high)", reasoningEffort)
+		}
 
-		// Parse format option (may include schema)
-		parsedOutputFormat, cliSchema, fmtErr := format.ParseWithSchema(outputFormat)
-		if fmtErr != nil {
-			return fmt.Errorf("invalid format option: %s\n%s", outputFormat, format.GetHelpText())
+		// Parse format option (may include schema). Without the flag, the
+		// output config provides the default once the config is loaded.
+		outputFormatSet := cmd.Flags().Changed("output-format")
+		parsedOutputFormat, cliSchema := format.Text, map[string]any(nil)
+		if outputFormatSet {
+			var fmtErr error
+			parsedOutputFormat, cliSchema, fmtErr = format.ParseWithSchema(outputFormat)
+			if fmtErr != nil {
+				return fmt.Errorf("invalid format option: %s\n%s", outputFormat, format.GetHelpText())
+			}
 		}
 
 		if cwd != "" {
@@ -134,13 +163,24 @@ Key Features:
 			spinner.Start()
 		}
 
-		_, loadCfgErr := config.Load(cwd, debug)
+		cfg, loadCfgErr := config.Load(cwd, debug)
 		if loadCfgErr != nil {
 			if spinner != nil {
 				spinner.Stop()
 			}
 			return loadCfgErr
 		}
+		// Auto LSP entries name built-in servers, which config.Load cannot check
+		if err := install.ValidateAuto(cfg); err != nil {
+			if spinner != nil {
+				spinner.Stop()
+			}
+			return err
+		}
+		if !outputFormatSet {
+			// Validated by config.Load
+			parsedOutputFormat, cliSchema, _ = cfg.Output.Resolve(cfg.WorkingDir)
+		}
 
 		// Connect DB, this will also run migrations
 		conn, dbConnErr := db.Connect(context.Background())
@@ -163,8 +203,23 @@ Key Features:
 			return appNewErr
 		}
 		defer app.Shutdown()
+		app.SessionWorkingDir = workDir
 
-		// Set active agent if specified
+		// Set active agent if specified. Prompts run without --agent use
+		// the configured defaultAgent.
+		agentFlagSet := agentID != ""
+		if prompt != "" {
+			agentID = cfg.PromptAgent(agentID)
+		}
+		if !agentFlagSet && agentID != "" {
+			// Markdown agents are not known to config validation
+			if info, ok := app.Registry.Get(agentID); ok && info.Hidden {
+				if spinner != nil {
+					spinner.Stop()
+				}
+				return fmt.Errorf("invalid defaultAgent: agent %s is hidden", agentID)
+			}
+		}
 		if agentID != "" {
 			if agentIDError := app.SetActiveAgent(agentID); agentIDError != nil {
 				if spinner != nil {
@@ -190,6 +245,14 @@ Key Features:
 				logging.Info("Deleted existing session, will recreate with same ID", "session_id", sessionID)
 			} else {
 				app.InitialSession = &sess
+				// A resumed session starts in the session working directory
+				// right away; new sessions switch to it when created.
+				if wdErr := app.ApplySessionWorkingDir(); wdErr != nil {
+					if spinner != nil {
+						spinner.Stop()
+					}
+					return wdErr
+				}
 			}
 			app.InitialSessionID = sessionID
 		}
@@ -227,7 +290,10 @@ Key Features:
 				nonInteractiveCtx, timeoutCancel = context.WithTimeout(ctx, timeoutDuration)
 				defer timeoutCancel()
 			}
-			runErr := runNonInteractive(nonInteractiveCtx, app, prompt, parsedOutputFormat, quiet)
+			if reasoningEffort != "" {
+				nonInteractiveCtx = provider.WithReasoningEffortOverride(nonInteractiveCtx, reasoningEffort)
+			}
+			runErr := runNonInteractive(nonInteractiveCtx, app, prompt, parsedOutputFormat, cliSchema, quiet)
 			app.ForceShutdown()
 			return runErr
 		}
@@ -410,7 +476,7 @@ func init() {
 	rootCmd.Flags().BoolP("debug", "d", false, "Debug")
 	rootCmd.Flags().StringP("cwd", "c", "", "Current working directory")
 	rootCmd.Flags().StringP("prompt", "p", "", "Prompt to run in non-interactive mode")
-	rootCmd.Flags().StringP("agent", "a", "", "Agent ID to use (e.g. coder, hivemind)")
+	rootCmd.Flags().StringP("agent", "a", "", "Agent ID to use (e.g. coder, hivemind); overrides defaultAgent for prompts")
 	rootCmd.Flags().StringP("session", "s", "", "Session ID to resume or create")
 	rootCmd.Flags().BoolP("delete", "D", false, "Delete the session specified by --session/-s before starting")
 
@@ -429,6 +495,12 @@ func init() {
 	// Add timeout flag for non-interactive mode
 	rootCmd.Flags().StringP("timeout", "t", "", "Timeout for non-interactive mode (e.g. 10s, 30m, 1h)")
 
+	// Add reasoning effort flag for non-interactive mode
+	rootCmd.Flags().String("reasoning-effort", "", "Reasoning effort (low, medium, high) for the prompt only, overriding the agent's reasoningEffort")
+
+	// Add session working directory flag
+	rootCmd.Flags().StringP("workdir", "w", "", "Working directory for the session, keeping the configuration loaded from the current directory")
+
 	// Add project ID flag
 	rootCmd.Flags().StringP("project-id", "P", "", "Custom project ID (overrides auto-detected Git/directory-based ID)")
 
@@ -547,4 +619,46 @@ func init() {
 	}
 	flowCmd.AddCommand(flowListCmd)
 	rootCmd.AddCommand(flowCmd)
+
+	// Add skill schema command
+	skillSchemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for SKILL.md frontmatter",
+		Long:  "Print a JSON Schema describing the SKILL.md frontmatter, for editor validation and autocompletion.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			fmt.Fprintln(cmd.OutOrStdout(), string(skill.FrontmatterSchema()))
+			return nil
+		},
+	}
+
+	// Add skill command group
+	skillCmd := &cobra.Command{
+		Use:   "skill",
+		Short: "Manage skills",
+		Long:  "Commands for authoring and inspecting skills.",
+	}
+	skillCmd.AddCommand(skillSchemaCmd)
+	rootCmd.AddCommand(skillCmd)
+
+	rootCmd.AddCommand(gcCmd)
+	rootCmd.AddCommand(evalCmd)
+
+	// Add context command group
+	contextCmd := &cobra.Command{
+		Use:   "context",
+		Short: "Inspect the context sent to the model",
+		Long:  "Commands for inspecting the system prompt and context files sent to the model.",
+	}
+	contextCmd.AddCommand(contextShowCmd)
+	rootCmd.AddCommand(contextCmd)
+
+	// Add session command group
+	sessionCmd := &cobra.Command{
+		Use:   "session",
+		Short: "Manage sessions",
+		Long:  "Commands for managing sessions and the changes they made.",
+	}
+	sessionCmd.AddCommand(sessionDiscardCmd)
+	rootCmd.AddCommand(sessionCmd)
 }
@@ -103,6 +103,78 @@ func generateSchema() map[string]any {
 		},
 	}
 
+	schema["properties"].(map[string]any)["worktreeMarkers"] = map[string]any{
+		"type":        "array",
+		"description": "Files or directories marking the project root; the nearest directory containing any of them bounds skill and context file discovery",
+		"items": map[string]any{
+			"type":      "string",
+			"minLength": 1,
+		},
+		"default": []string{".git"},
+	}
+
+	schema["properties"].(map[string]any)["contextMaxBytes"] = map[string]any{
+		"type":        "integer",
+		"description": "Maximum combined size in bytes of the context files added to the system prompt; the largest files are truncated to fit (0 for no limit)",
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["maxFileSizeForTools"] = map[string]any{
+		"type":        "integer",
+		"description": "Size in bytes above which files are left out of ls and glob results and are only read in chunks (0 for no limit)",
+		"minimum":     0,
+	}
+
+	schema["properties"].(map[string]any)["context"] = map[string]any{
+		"type":        "object",
+		"description": "Extra context attached to tool results",
+		"properties": map[string]any{
+			"gitBlame": map[string]any{
+				"type":        "boolean",
+				"description": "List the most recent commits touching a file when it is read",
+				"default":     false,
+			},
+			"gitBlameCommits": map[string]any{
+				"type":        "integer",
+				"description": "Number of recent commits listed by gitBlame (default 5)",
+				"minimum":     1,
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["session"] = map[string]any{
+		"type":        "object",
+		"description": "Per-session spending limits; a turn is halted before a provider call that would exceed them",
+		"properties": map[string]any{
+			"maxCostUSD": map[string]any{
+				"type":        "number",
+				"description": "Maximum cost in USD a session may spend (0 for no limit)",
+				"minimum":     0,
+			},
+			"maxTokens": map[string]any{
+				"type":        "integer",
+				"description": "Maximum input and output tokens a session may use (0 for no limit)",
+				"minimum":     0,
+			},
+		},
+	}
+
+	schema["properties"].(map[string]any)["output"] = map[string]any{
+		"type":        "object",
+		"description": "Default output format of non-interactive mode; the --output-format flag overrides it",
+		"properties": map[string]any{
+			"format": map[string]any{
+				"type":        "string",
+				"description": "Output format",
+				"enum":        []string{"text", "json", "json_schema"},
+			},
+			"schema": map[string]any{
+				"description": "JSON schema of the json_schema format: a schema object, which may be {\"$ref\": \"path/to/schema.json\"}, or a path to a schema file",
+				"type":        []string{"object", "string"},
+			},
+		},
+	}
+
 	schema["properties"].(map[string]any)["tui"] = map[string]any{
 		"type":        "object",
 		"description": "Terminal User Interface configuration",
@@ -169,6 +241,11 @@ func generateSchema() map[string]any {
 						"type": "string",
 					},
 				},
+				"keepaliveInterval": map[string]any{
+					"type":        "integer",
+					"description": "Seconds between pings sent to an SSE or HTTP server while a tool call waits for it, so proxies do not drop the idle stream and a dropped one fails the call (0 disables)",
+					"minimum":     0,
+				},
 			},
 			"required": []string{"command"},
 		},
@@ -202,6 +279,27 @@ func generateSchema() map[string]any {
 						"type": "string",
 					},
 				},
+				"modelMap": map[string]any{
+					"type":        "object",
+					"description": "Rename API model names for gateways that serve them under custom names",
+					"additionalProperties": map[string]any{
+						"type": "string",
+					},
+				},
+				"disableStreaming": map[string]any{
+					"type":        "boolean",
+					"description": "Send each request as a single buffered call instead of streaming the response",
+					"default":     false,
+				},
+				"idempotencyKey": map[string]any{
+					"type":        "boolean",
+					"description": "Send an Idempotency-Key header that stays the same across retries of a request. Supported by the OpenAI-compatible, Anthropic (including Bedrock and Vertex AI Claude models), DeepSeek and xAI clients; ignored by Gemini and Kilo",
+					"default":     false,
+				},
+				"userAgent": map[string]any{
+					"type":        "string",
+					"description": "User-Agent sent to the provider, replacing the default opencode/<version>",
+				},
 			},
 		},
 	}
@@ -240,11 +338,65 @@ func generateSchema() map[string]any {
 					"description": "Maximum tokens for the agent",
 					"minimum":     1,
 				},
+				"maxReasoningTokens": map[string]any{
+					"type":        "integer",
+					"description": "Maximum tokens a reasoning model may spend thinking, for providers with a separate thinking budget",
+					"minimum":     1,
+				},
+				"routing": map[string]any{
+					"type":        "array",
+					"description": "Rules sending individual requests to other models; the first matching rule wins",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"when": map[string]any{
+								"type":        "string",
+								"description": "Condition on the estimated prompt tokens or message count, e.g. tokens>50000 or messages>=40",
+								"pattern":     `^\s*(tokens|messages)\s*(>=|<=|==|>|<)\s*\d+\s*$`,
+							},
+							"model": map[string]any{
+								"type":        "string",
+								"description": "Model ID to use for matching requests",
+							},
+						},
+						"required": []string{"when", "model"},
+					},
+				},
 				"reasoningEffort": map[string]any{
 					"type":        "string",
 					"description": "Reasoning effort for models that support it (OpenAI, Anthropic). 'max' is only available for models with maximum thinking support.",
 					"enum":        []string{"low", "medium", "high", "max"},
 				},
+				"maxParallelTools": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of independent tool calls from one response to run concurrently (default 1, sequential)",
+					"minimum":     1,
+				},
+				"maxToolIterations": map[string]any{
+					"type":        "integer",
+					"description": "Maximum rounds of tool calls in one turn for this agent, overriding the global maxToolIterations",
+					"minimum":     1,
+				},
+				"temperature": map[string]any{
+					"type":        "number",
+					"description": "Sampling temperature; unset uses the provider default. Ignored by Anthropic models while thinking",
+					"minimum":     0,
+					"maximum":     2,
+				},
+				"topP": map[string]any{
+					"type":             "number",
+					"description":      "Nucleus sampling probability; unset uses the provider default",
+					"exclusiveMinimum": 0,
+					"maximum":          1,
+				},
+				"stopSequences": map[string]any{
+					"type":        "array",
+					"description": "Sequences that end generation when the model produces them",
+					"items": map[string]any{
+						"type":      "string",
+						"minLength": 1,
+					},
+				},
 				"mode": map[string]any{
 					"type":        "string",
 					"description": "Agent mode: 'agent' for primary agents, 'subagent' for agents invoked by task tool",
@@ -370,6 +522,31 @@ func generateSchema() map[string]any {
 					"description": "Whether the LSP server is disabled",
 					"default":     false,
 				},
+				"auto": map[string]any{
+					"type":        "boolean",
+					"description": "Use the built-in server for the language this entry is keyed by, a file extension or language name such as \"go\" or \"rust\", without specifying a command",
+					"default":     false,
+				},
+				"autoRestart": map[string]any{
+					"type":        "boolean",
+					"description": "Restart the server automatically when its connection breaks, for example after a crash",
+					"default":     true,
+				},
+				"maxRestarts": map[string]any{
+					"type":        "integer",
+					"description": "Maximum number of automatic restarts of the server (0 for the default of 3)",
+					"minimum":     0,
+				},
+				"download": map[string]any{
+					"type":        "string",
+					"description": "Allow or deny installing the built-in server when it is missing, overriding disableLSPDownload",
+					"enum":        []string{"allow", "deny"},
+				},
+				"version": map[string]any{
+					"type":        "string",
+					"description": "Release of the built-in server to install and run, such as 0.16.2, instead of the latest one or a binary on PATH",
+					"pattern":     `^v?\d+(\.\d+){0,3}([-+][0-9A-Za-z.-]+)?$`,
+				},
 				"command": map[string]any{
 					"type":        "string",
 					"description": "Command to execute for the LSP server",
@@ -399,6 +576,18 @@ func generateSchema() map[string]any {
 					"type":        "object",
 					"description": "Initialization options sent to the LSP server during the initialize request. Options vary by server.",
 				},
+				"readyTimeout": map[string]any{
+					"type":        "integer",
+					"description": "Seconds to wait for the server to become ready on each attempt",
+					"default":     30,
+					"minimum":     1,
+				},
+				"readyRetries": map[string]any{
+					"type":        "integer",
+					"description": "Extra readiness attempts, with exponential backoff, before the server is marked as errored",
+					"default":     0,
+					"minimum":     0,
+				},
 			},
 		},
 	}
@@ -410,6 +599,17 @@ func generateSchema() map[string]any {
 		"default":     false,
 	}
 
+	schema["properties"].(map[string]any)["lspDiagnosticsDebounce"] = map[string]any{
+		"type":        "integer",
+		"description": "Milliseconds in which diagnostics requests after edits are merged into one notification and wait; negative disables merging",
+		"default":     100,
+	}
+
+	schema["properties"].(map[string]any)["defaultAgent"] = map[string]any{
+		"type":        "string",
+		"description": "Agent that runs non-interactive prompts (-p) when --agent is not given. Must be an enabled, non-hidden agent with mode \"agent\"",
+	}
+
 	// Add shell configuration
 	schema["properties"].(map[string]any)["shell"] = map[string]any{
 		"type":        "object",
@@ -437,6 +637,62 @@ func generateSchema() map[string]any {
 		"default":     true,
 	}
 
+	schema["properties"].(map[string]any)["maxToolIterations"] = map[string]any{
+		"type":        "integer",
+		"description": "Maximum rounds of tool calls the model may make in one turn before it is halted (default 20)",
+		"minimum":     1,
+	}
+
+	// Add confirmPlans flag
+	schema["properties"].(map[string]any)["confirmPlans"] = map[string]any{
+		"type":        "boolean",
+		"description": "Ask for approval of the full list of tool calls before running any when a turn makes several",
+		"default":     false,
+	}
+
+	// Add locale
+	schema["properties"].(map[string]any)["locale"] = map[string]any{
+		"type":        "string",
+		"description": "Language of tool descriptions sent to the model, such as es or pt-BR; tools without a translation are described in English",
+	}
+
+	// Add guard configuration
+	schema["properties"].(map[string]any)["guard"] = map[string]any{
+		"type":        "object",
+		"description": "Checks the file editing tools make before changing a file",
+		"properties": map[string]any{
+			"protectGenerated": map[string]any{
+				"type":        "boolean",
+				"description": "Ask for confirmation before editing generated files (\"Code generated ... DO NOT EDIT.\" or @generated headers) and vendored files (under vendor, node_modules and similar directories)",
+				"default":     false,
+			},
+			"syntaxCheck": map[string]any{
+				"type":        "boolean",
+				"description": "Parse the content an edit would write for Go and JSON files and warn when the edit introduces a syntax error",
+				"default":     false,
+			},
+			"strict": map[string]any{
+				"type":        "boolean",
+				"description": "Block edits of generated and vendored files instead of asking for confirmation, and edits introducing a syntax error instead of warning",
+				"default":     false,
+			},
+		},
+	}
+
+	// Add strictMigration flag
+	schema["properties"].(map[string]any)["strictMigration"] = map[string]any{
+		"type":        "boolean",
+		"description": "Fail on deprecated agent names (task, title) instead of migrating them to explorer and descriptor",
+		"default":     false,
+	}
+
+	// Add strictValidation flag
+	schema["properties"].(map[string]any)["strictValidation"] = map[string]any{
+		"type":        "boolean",
+		"description": "Fail to load on problems that are otherwise fixed up with a warning, such as an unsupported model or a provider without an API key",
+		"default":     false,
+	}
+
 	// Add session provider configuration
 	schema["properties"].(map[string]any)["sessionProvider"] = map[string]any{
 		"type":        "object",
@@ -543,6 +799,101 @@ func generateSchema() map[string]any {
 		},
 	}
 
+	// Add tools configuration
+	schema["properties"].(map[string]any)["tools"] = map[string]any{
+		"type":        "object",
+		"description": "Configuration shared by the built-in tools",
+		"properties": map[string]any{
+			"maxErrorInputChars": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of characters of a tool's raw input echoed back when its parameters fail to parse",
+				"default":     1000,
+				"minimum":     1,
+			},
+			"followSymlinks": map[string]any{
+				"type":        "boolean",
+				"description": "Descend into symlinked directories in the ls and glob tools",
+				"default":     false,
+			},
+			"summarizeLargeOutput": map[string]any{
+				"type":        "boolean",
+				"description": "Condense tool results too large for the context with the summarizer agent instead of keeping only their first and last lines; the full output is saved under the data directory",
+				"default":     false,
+			},
+			"defaultIgnore": map[string]any{
+				"type":        "array",
+				"description": "Glob patterns excluded by the ls, glob and grep tools in addition to each call's ignore list",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"fetch": map[string]any{
+				"type":        "object",
+				"description": "Configuration for the webfetch tool",
+				"properties": map[string]any{
+					"allowedContentTypes": map[string]any{
+						"type":        "array",
+						"description": "Media types the webfetch tool will download; a trailing /* matches any subtype",
+						"default":     []string{"text/*", "application/json", "application/xml", "application/xhtml+xml", "application/javascript", "application/yaml"},
+						"items": map[string]any{
+							"type": "string",
+						},
+					},
+				},
+			},
+			"read": map[string]any{
+				"type":        "object",
+				"description": "Configuration for the read tool",
+				"properties": map[string]any{
+					"summaryThreshold": map[string]any{
+						"type":        "integer",
+						"description": "Line count above which reading a whole file returns an outline with its first and last lines; 0 disables summaries",
+						"default":     0,
+						"minimum":     0,
+					},
+				},
+			},
+			"delete": map[string]any{
+				"type":        "object",
+				"description": "Configuration for the delete tool",
+				"properties": map[string]any{
+					"maxPreviewBytes": map[string]any{
+						"type":        "integer",
+						"description": "Maximum bytes of removed content previewed when confirming a deletion and in its result, per file and overall",
+						"default":     32768,
+						"minimum":     0,
+					},
+				},
+			},
+			"bom": map[string]any{
+				"type":        "string",
+				"description": "UTF-8 byte-order mark of files written by the edit and write tools: preserve each file's current state (new files get none), add one or strip it. A call's bom parameter overrides it",
+				"enum":        []string{"preserve", "add", "strip"},
+				"default":     "preserve",
+			},
+		},
+	}
+
+	// Add hivemind configuration
+	schema["properties"].(map[string]any)["hivemind"] = map[string]any{
+		"type":        "object",
+		"description": "Limits for subagents launched in parallel through the task tool",
+		"properties": map[string]any{
+			"maxParallel": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of subagents running at the same time",
+				"default":     config.DefaultHivemindMaxParallel,
+				"minimum":     1,
+			},
+			"perAgentTimeout": map[string]any{
+				"type":        "integer",
+				"description": "Seconds a single subagent may run before it is cancelled (0 disables the limit)",
+				"default":     0,
+				"minimum":     0,
+			},
+		},
+	}
+
 	// Add permission configuration
 	schema["properties"].(map[string]any)["permission"] = map[string]any{
 		"type":        "object",
@@ -557,6 +908,13 @@ func generateSchema() map[string]any {
 					"enum":        []string{"allow", "deny", "ask"},
 				},
 			},
+			"autoAllowPaths": map[string]any{
+				"type":        "array",
+				"description": "Globs relative to the working directory (e.g. 'tmp/**') where file tools never ask for permission",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
 		},
 		"additionalProperties": map[string]any{
 			"anyOf": []map[string]any{
@@ -0,0 +1,96 @@
+// Package cmd provides the CLI commands for OpenCode.
+// This file implements the session subcommands.
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/db"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	"github.com/spf13/cobra"
+)
+
+var sessionDiscardCmd = &cobra.Command{
+	Use:   "discard <session-id>",
+	Short: "Restore the files a session changed to their state before it",
+	Long: `Revert every file change made by a session and its subagents, using the
+file history recorded by the edit tools. Files the session created are removed;
+files it modified or deleted are rewritten with their content from before the
+session.
+
+The affected paths are listed and confirmation is asked for before anything is
+changed, unless --yes is given.`,
+	Example: `
+  # Review and discard the changes of a session
+  opencode session discard 3f6c1c9e-...
+
+  # Discard without asking
+  opencode session discard 3f6c1c9e-... --yes`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		sessionID := args[0]
+		yes, _ := cmd.Flags().GetBool("yes")
+		cwd, _ := cmd.Flags().GetString("cwd")
+
+		if cwd == "" {
+			var err error
+			if cwd, err = os.Getwd(); err != nil {
+				return fmt.Errorf("failed to get current working directory: %w", err)
+			}
+		}
+		if _, err := config.Load(cwd, false); err != nil {
+			return err
+		}
+
+		ctx := context.Background()
+		conn, err := db.Connect(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+
+		changes, err := history.SessionChanges(ctx, history.NewService(db.NewQuerier(conn), conn), sessionID)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if len(changes) == 0 {
+			fmt.Fprintf(out, "Session %s changed no files.\n", sessionID)
+			return nil
+		}
+		fmt.Fprintf(out, "Discarding session %s will restore %d file(s):\n", sessionID, len(changes))
+		for _, change := range changes {
+			action := "restore"
+			if change.Created {
+				action = "remove"
+			}
+			fmt.Fprintf(out, "  %-7s %s\n", action, change.Path)
+		}
+
+		if !yes {
+			fmt.Fprint(out, "Proceed? [y/N] ")
+			answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				fmt.Fprintln(out, "Aborted.")
+				return nil
+			}
+		}
+
+		if err := history.RestoreFileChanges(changes); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Restored %d file(s).\n", len(changes))
+		return nil
+	},
+}
+
+func init() {
+	sessionDiscardCmd.Flags().Bool("yes", false, "Restore the files without asking for confirmation")
+	sessionDiscardCmd.Flags().StringP("cwd", "c", "", "Current working directory")
+}
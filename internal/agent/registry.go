@@ -41,6 +41,11 @@ type AgentInfo struct {
 	Tools           map[string]bool  `yaml:"tools,omitempty"`
 	Output          *Output          `yaml:"output,omitempty"`
 	Location        string           `yaml:"-"`
+	// MaxParallelTools caps concurrent tool execution within one response.
+	MaxParallelTools int `yaml:"maxParallelTools,omitempty"`
+	// MaxToolIterations caps the rounds of tool calls in one turn; zero uses
+	// the global maxToolIterations.
+	MaxToolIterations int `yaml:"maxToolIterations,omitempty"`
 }
 
 // Registry provides access to agent configurations.
@@ -90,6 +95,9 @@ func newRegistry() Registry {
 	discoverMarkdownAgents(agents, cfg)
 	applyConfigOverrides(agents, cfg)
 	removeDisabledAgents(agents)
+	for _, id := range undescribedSubagents(agents) {
+		logging.Warn("Subagent has no description: set one so other agents know when to invoke it", "agentID", id)
+	}
 
 	globalPerms := buildGlobalPerms(cfg)
 
@@ -191,13 +199,16 @@ func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 			Mode:        config.AgentModeAgent,
 			Native:      true,
 			Tools: map[string]bool{
-				"bash":      false,
-				"edit":      false,
-				"multiedit": false,
-				"write":     false,
-				"delete":    false,
-				"patch":     false,
-				"lsp":       false,
+				"bash":           false,
+				"edit":           false,
+				"multiedit":      false,
+				"search_replace": false,
+				"write":          false,
+				"delete":         false,
+				"patch":          false,
+				"apply_proposal": false,
+				"rename_symbol":  false,
+				"lsp":            false,
 			},
 		},
 		{
@@ -207,13 +218,16 @@ func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 			Mode:        config.AgentModeSubagent,
 			Native:      true,
 			Tools: map[string]bool{
-				"bash":      false,
-				"edit":      false,
-				"multiedit": false,
-				"write":     false,
-				"delete":    false,
-				"patch":     false,
-				"task":      false,
+				"bash":           false,
+				"edit":           false,
+				"multiedit":      false,
+				"search_replace": false,
+				"write":          false,
+				"delete":         false,
+				"patch":          false,
+				"apply_proposal": false,
+				"rename_symbol":  false,
+				"task":           false,
 			},
 		},
 		{
@@ -256,6 +270,8 @@ func registerBuiltins(agents map[string]AgentInfo, cfg *config.Config) {
 			b.Model = string(agentCfg.Model)
 			b.MaxTokens = agentCfg.MaxTokens
 			b.ReasoningEffort = agentCfg.ReasoningEffort
+			b.MaxParallelTools = agentCfg.MaxParallelTools
+			b.MaxToolIterations = agentCfg.MaxToolIterations
 		}
 		agents[b.ID] = b
 	}
@@ -305,6 +321,12 @@ func applyConfigOverrides(agents map[string]AgentInfo, cfg *config.Config) {
 		if agentCfg.ReasoningEffort != "" {
 			existing.ReasoningEffort = agentCfg.ReasoningEffort
 		}
+		if agentCfg.MaxParallelTools > 0 {
+			existing.MaxParallelTools = agentCfg.MaxParallelTools
+		}
+		if agentCfg.MaxToolIterations > 0 {
+			existing.MaxToolIterations = agentCfg.MaxToolIterations
+		}
 		if agentCfg.Name != "" {
 			existing.Name = agentCfg.Name
 		}
@@ -411,6 +433,21 @@ func removeDisabledAgents(agents map[string]AgentInfo) {
 	}
 }
 
+// undescribedSubagents returns the IDs, sorted, of the visible subagents
+// without a description. Subagents are chosen by their parent from the
+// description, so these are never picked. The check runs once markdown agents
+// and config overrides are merged, since either may supply the description.
+func undescribedSubagents(agents map[string]AgentInfo) []string {
+	var ids []string
+	for id, a := range agents {
+		if a.Mode == config.AgentModeSubagent && !a.Hidden && strings.TrimSpace(a.Description) == "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 func buildGlobalPerms(cfg *config.Config) map[string]any {
 	perms := make(map[string]any)
 	if cfg.Permission != nil {
@@ -318,6 +318,36 @@ func TestDisabledAgentRemovedFromRegistry(t *testing.T) {
 	}
 }
 
+func TestUndescribedSubagents(t *testing.T) {
+	agents := map[string]AgentInfo{
+		"reviewer":  {ID: "reviewer", Mode: config.AgentModeSubagent},
+		"tester":    {ID: "tester", Mode: config.AgentModeSubagent, Description: "Runs the tests"},
+		"internal":  {ID: "internal", Mode: config.AgentModeSubagent, Hidden: true},
+		"assistant": {ID: "assistant", Mode: config.AgentModeAgent},
+		"blank":     {ID: "blank", Mode: config.AgentModeSubagent, Description: "  "},
+	}
+
+	got := undescribedSubagents(agents)
+	if want := []string{"blank", "reviewer"}; strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("undescribedSubagents() = %v, want %v", got, want)
+	}
+}
+
+func TestMarkdownDescriptionCoversConfigOverride(t *testing.T) {
+	agents := map[string]AgentInfo{}
+	md := AgentInfo{ID: "reviewer", Mode: config.AgentModeSubagent, Description: "Reviews diffs"}
+	agents[md.ID] = md
+
+	// The config only overrides the model of the markdown agent
+	applyConfigOverrides(agents, &config.Config{
+		Agents: map[config.AgentName]config.Agent{"reviewer": {Model: "gpt-4.1"}},
+	})
+
+	if got := undescribedSubagents(agents); len(got) != 0 {
+		t.Errorf("undescribedSubagents() = %v, want none", got)
+	}
+}
+
 func TestDisabledViaConfigOverride(t *testing.T) {
 	agents := map[string]AgentInfo{
 		"coder": {
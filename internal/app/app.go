@@ -44,10 +44,27 @@ type App struct {
 
 	InitialSession   *session.Session
 	InitialSessionID string
+	// SessionWorkingDir, when set, is the working directory sessions run in
+	// instead of the one the configuration was loaded from.
+	SessionWorkingDir string
 
 	cliOutputSchema map[string]any
 }
 
+// ApplySessionWorkingDir switches the working directory to
+// SessionWorkingDir, if set. It is called when a session is created or
+// resumed.
+func (app *App) ApplySessionWorkingDir() error {
+	if app.SessionWorkingDir == "" {
+		return nil
+	}
+	if err := config.SetWorkingDirectory(app.SessionWorkingDir); err != nil {
+		return err
+	}
+	logging.Info("Using session working directory", "dir", config.WorkingDirectory())
+	return nil
+}
+
 func (app *App) ActiveAgent() agent.Service {
 	if len(app.PrimaryAgentKeys) == 0 {
 		return app.activeAgent
@@ -120,6 +137,9 @@ func New(ctx context.Context, conn *sql.DB, cliSchema map[string]any, projectID
 	}
 
 	app.initTheme()
+	for _, warning := range config.DetectContextConflicts() {
+		logging.Warn(warning)
+	}
 
 	// Start LSP in background with guarded goroutine to handle errors and panics
 	// Use context.Background() so LSP init runs independently of New()'s ctx
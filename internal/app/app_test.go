@@ -1,10 +1,12 @@
 package app
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestApp_ActiveAgentName(t *testing.T) {
@@ -99,3 +101,24 @@ func TestApp_SwitchAgent(t *testing.T) {
 		})
 	}
 }
+
+func TestApp_ApplySessionWorkingDir(t *testing.T) {
+	config.Reset()
+	t.Cleanup(config.Reset)
+	loadDir := t.TempDir()
+	_, err := config.Load(loadDir, false)
+	require.NoError(t, err)
+
+	app := &App{}
+	require.NoError(t, app.ApplySessionWorkingDir())
+	assert.Equal(t, loadDir, config.WorkingDirectory(), "no session working directory keeps the loaded one")
+
+	sessionDir := t.TempDir()
+	app.SessionWorkingDir = sessionDir
+	require.NoError(t, app.ApplySessionWorkingDir())
+	assert.Equal(t, sessionDir, config.WorkingDirectory())
+
+	app.SessionWorkingDir = filepath.Join(sessionDir, "missing")
+	assert.Error(t, app.ApplySessionWorkingDir())
+	assert.Equal(t, sessionDir, config.WorkingDirectory())
+}
@@ -3,6 +3,8 @@ package app
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"maps"
 	"os"
 	"path/filepath"
@@ -27,21 +29,34 @@ type lspService struct {
 	clients   map[string]*lsp.Client
 	clientsCh chan *lsp.Client
 	mu        sync.RWMutex
+	// replaced hands clients restarted outside a supervisor, after a
+	// workspace watcher panic, to the supervisor of their server
+	replaced map[string]chan transportClient
 
 	watcherCancelFuncs []context.CancelFunc
 	cancelMu           sync.Mutex
 	watcherWG          sync.WaitGroup
+
+	diagnostics *diagnosticsCoalescer
 }
 
 func NewLspService() lsp.LspService {
-	return &lspService{
+	s := &lspService{
 		clients:   make(map[string]*lsp.Client),
 		clientsCh: make(chan *lsp.Client, 50),
 	}
+	s.diagnostics = newDiagnosticsCoalescer(lspDiagnosticsWindow(), s.waitForFilesDiagnostics)
+	return s
 }
 
 func (s *lspService) Init(ctx context.Context) error {
 	cfg := config.Get()
+	// Supervisors stop before the clients are shut down deliberately
+	superviseCtx, cancelSupervise := context.WithCancel(ctx)
+	s.cancelMu.Lock()
+	s.watcherCancelFuncs = append(s.watcherCancelFuncs, cancelSupervise)
+	s.cancelMu.Unlock()
+
 	wg := sync.WaitGroup{}
 	for name, server := range install.ResolveServers(cfg) {
 		wg.Add(1)
@@ -51,7 +66,14 @@ func (s *lspService) Init(ctx context.Context) error {
 				logging.ErrorPersist("Panic while starting " + lspName)
 			})
 			defer wg.Done()
-			s.startLSPServer(ctx, nm, srv)
+			client := s.startLSPServer(ctx, nm, srv)
+			if client == nil {
+				return
+			}
+			s.clientsCh <- client
+			if srv.AutoRestart {
+				go s.superviseLSPClient(superviseCtx, nm, client, srv.MaxRestarts)
+			}
 		}(name, server)
 	}
 	go func() {
@@ -168,14 +190,27 @@ func (s *lspService) NotifyOpenFile(ctx context.Context, filePath string) {
 	}
 }
 
+// WaitForDiagnostics notifies the servers that filePath changed and waits
+// for its diagnostics. Requests made within lspDiagnosticsDebounce of each
+// other share one notification of all their files and one wait.
 func (s *lspService) WaitForDiagnostics(ctx context.Context, filePath string) error {
-	clients := s.Clients()
-	if len(clients) == 0 {
+	if len(s.Clients()) == 0 {
 		// TODO: This is a temporary workaround to avoid waiting for diagnostics when no LSP clients are available.
 		// In the future, we should refactor this to only wait for diagnostics from relevant clients, and to not wait at all if there are no relevant clients.
 		logging.Debug("No LSP clients available to wait for diagnostics")
 		return nil
 	}
+	return s.diagnostics.Wait(ctx, filePath)
+}
+
+// waitForFilesDiagnostics notifies every server that files changed and waits
+// up to 5 seconds for diagnostics of any of them.
+func (s *lspService) waitForFilesDiagnostics(ctx context.Context, files []string) error {
+	clients := s.Clients()
+	requested := make(map[string]bool, len(files))
+	for _, file := range files {
+		requested[file] = true
+	}
 
 	diagChan := make(chan struct{}, 1)
 
@@ -191,7 +226,7 @@ func (s *lspService) WaitForDiagnostics(ctx context.Context, filePath string) er
 				return
 			}
 
-			if diagParams.URI.Path() == filePath || lsp.HasDiagnosticsChanged(client.GetDiagnostics(), originalDiags) {
+			if requested[diagParams.URI.Path()] || lsp.HasDiagnosticsChanged(client.GetDiagnostics(), originalDiags) {
 				select {
 				case diagChan <- struct{}{}:
 				default:
@@ -213,10 +248,12 @@ func (s *lspService) WaitForDiagnostics(ctx context.Context, filePath string) er
 			client.UnregisterNotificationHandler("textDocument/publishDiagnostics")
 		}
 
-		if client.IsFileOpen(filePath) {
-			_ = client.NotifyChange(ctx, filePath)
-		} else {
-			_ = client.OpenFile(ctx, filePath)
+		for _, filePath := range files {
+			if client.IsFileOpen(filePath) {
+				_ = client.NotifyChange(ctx, filePath)
+			} else {
+				_ = client.OpenFile(ctx, filePath)
+			}
 		}
 	}
 
@@ -275,30 +312,32 @@ func hasMatchingFiles(rootDir string, extensions []string) bool {
 	return found
 }
 
-func (s *lspService) startLSPServer(ctx context.Context, name string, server install.ResolvedServer) {
+// startLSPServer starts the server name and returns its client, or nil when
+// the server is not needed or could not be started.
+func (s *lspService) startLSPServer(ctx context.Context, name string, server install.ResolvedServer) *lsp.Client {
 	cfg := config.Get()
 
 	if !hasMatchingFiles(config.WorkingDirectory(), server.Extensions) {
 		logging.Debug("No matching files found, skipping LSP server", "name", name, "extensions", server.Extensions)
-		return
+		return nil
 	}
 
 	command, args, err := install.ResolveCommand(ctx, server, cfg.DisableLSPDownload)
 	if err != nil {
 		logging.Debug("LSP server not available, skipping", "name", name, "reason", err)
-		return
+		return nil
 	}
 
-	s.createAndStartLSPClient(ctx, name, server, command, args...)
+	return s.createAndStartLSPClient(ctx, name, server, command, args...)
 }
 
-func (s *lspService) createAndStartLSPClient(ctx context.Context, name string, server install.ResolvedServer, command string, args ...string) {
+func (s *lspService) createAndStartLSPClient(ctx context.Context, name string, server install.ResolvedServer, command string, args ...string) *lsp.Client {
 	logging.Info("Creating LSP client", "name", name, "command", command, "args", args)
 
 	lspClient, err := lsp.NewClient(ctx, command, server.Env, args...)
 	if err != nil {
 		logging.Error("Failed to create LSP client for", name, err)
-		return
+		return nil
 	}
 
 	lspClient.SetExtensions(server.Extensions)
@@ -315,15 +354,13 @@ func (s *lspService) createAndStartLSPClient(ctx context.Context, name string, s
 	if err != nil {
 		logging.Error("Initialize failed", "name", name, "error", err)
 		lspClient.Close()
-		return
+		return nil
 	}
 
-	if err := lspClient.WaitForServerReady(initCtx); err != nil {
+	if err := waitForServerReady(ctx, lspClient, name, server.ReadyTimeout, server.ReadyRetries); err != nil {
 		logging.Error("Server failed to become ready", "name", name, "error", err)
-		lspClient.SetServerState(lsp.StateError)
 	} else {
 		logging.Info("LSP server is ready", "name", name)
-		lspClient.SetServerState(lsp.StateReady)
 	}
 
 	watchCtx, cancelFunc := context.WithCancel(ctx)
@@ -341,28 +378,80 @@ func (s *lspService) createAndStartLSPClient(ctx context.Context, name string, s
 	s.clients[name] = lspClient
 	s.mu.Unlock()
 
-	s.clientsCh <- lspClient
-
 	go s.runWorkspaceWatcher(watchCtx, name, workspaceWatcher)
+	return lspClient
+}
+
+// readyWaiter is the part of an LSP client used while waiting for the server
+// to become ready.
+type readyWaiter interface {
+	WaitForServerReady(ctx context.Context) error
+	SetServerState(state lsp.ServerState)
+}
+
+const defaultLSPReadyTimeout = 30 * time.Second
+
+// lspReadyBackoff is the delay before the first readiness retry; it doubles
+// on every further retry.
+var lspReadyBackoff = 2 * time.Second
+
+// waitForServerReady waits up to timeout for the server to become ready,
+// retrying up to retries more times with exponential backoff. The client ends
+// in StateReady on success and StateError once all attempts have failed.
+func waitForServerReady(ctx context.Context, client readyWaiter, name string, timeout time.Duration, retries int) error {
+	if timeout <= 0 {
+		timeout = defaultLSPReadyTimeout
+	}
+	retries = max(retries, 0)
+
+	backoff := lspReadyBackoff
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			logging.Warn("LSP server not ready, retrying", "name", name, "attempt", attempt, "retries", retries, "backoff", backoff, "error", err)
+			select {
+			case <-ctx.Done():
+				client.SetServerState(lsp.StateError)
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+		err = client.WaitForServerReady(attemptCtx)
+		cancel()
+		if err == nil {
+			client.SetServerState(lsp.StateReady)
+			return nil
+		}
+	}
+
+	client.SetServerState(lsp.StateError)
+	return err
 }
 
 func (s *lspService) runWorkspaceWatcher(ctx context.Context, name string, workspaceWatcher *watcher.WorkspaceWatcher) {
 	defer s.watcherWG.Done()
 	defer logging.RecoverPanic("LSP-"+name, func() {
-		s.restartLSPClient(ctx, name)
+		if client := s.restartLSPClient(ctx, name); client != nil {
+			s.clientReplaced(name, client)
+		}
 	})
 
 	workspaceWatcher.WatchWorkspace(ctx, config.WorkingDirectory())
 	logging.Info("Workspace watcher stopped", "client", name)
 }
 
-func (s *lspService) restartLSPClient(ctx context.Context, name string) {
+// restartLSPClient replaces the client of the server name with a new one and
+// returns it, or nil when the server could not be started again.
+func (s *lspService) restartLSPClient(ctx context.Context, name string) *lsp.Client {
 	cfg := config.Get()
 	servers := install.ResolveServers(cfg)
 	server, exists := servers[name]
 	if !exists {
 		logging.Error("Cannot restart client, configuration not found", "client", name)
-		return
+		return nil
 	}
 
 	s.mu.Lock()
@@ -380,8 +469,120 @@ func (s *lspService) restartLSPClient(ctx context.Context, name string) {
 		}
 		cancel()
 	}
-
-	s.startLSPServer(ctx, name, server)
+	// The new client registers itself under the lock
 	s.mu.Unlock()
+	if exists && oldClient != nil {
+		oldClient.Close()
+	}
+
+	client := s.startLSPServer(ctx, name, server)
+	if client == nil {
+		logging.Error("Failed to restart LSP client", "client", name)
+		return nil
+	}
 	logging.Info("Successfully restarted LSP client", "client", name)
+	return client
+}
+
+// transportClient is the part of an LSP client watched for connection
+// failures.
+type transportClient interface {
+	TransportFailed() <-chan struct{}
+	GetServerState() lsp.ServerState
+}
+
+// lspRestartBackoff is the delay before the first automatic restart of a
+// server; it doubles on every further restart.
+var lspRestartBackoff = time.Second
+
+// superviseLSPClient restarts the server name when the connection to client
+// breaks, at most maxRestarts times. A client that replaces it outside the
+// supervisor is watched from then on.
+func (s *lspService) superviseLSPClient(ctx context.Context, name string, client *lsp.Client, maxRestarts int) {
+	replaced := make(chan transportClient, 1)
+	s.mu.Lock()
+	if s.replaced == nil {
+		s.replaced = make(map[string]chan transportClient)
+	}
+	s.replaced[name] = replaced
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.replaced, name)
+		s.mu.Unlock()
+	}()
+
+	superviseTransport(ctx, name, client, maxRestarts, replaced, func(ctx context.Context) (transportClient, error) {
+		restarted := s.restartLSPClient(ctx, name)
+		if restarted == nil {
+			return nil, errors.New("server could not be started")
+		}
+		return restarted, nil
+	})
+}
+
+// clientReplaced tells the supervisor of the server name, if it has one, to
+// watch client instead of the client it replaced.
+func (s *lspService) clientReplaced(name string, client transportClient) {
+	s.mu.RLock()
+	replaced := s.replaced[name]
+	s.mu.RUnlock()
+	if replaced == nil {
+		return
+	}
+	// Only the latest replacement is worth watching
+	select {
+	case <-replaced:
+	default:
+	}
+	select {
+	case replaced <- client:
+	default:
+	}
+}
+
+// superviseTransport calls restart whenever the connection to client breaks,
+// then watches the client it returns, or the one received from replaced. A restart only succeeds once the new
+// client is ready; failed restarts are retried. Restarts are delayed by
+// lspRestartBackoff, doubling each time, and stop after maxRestarts in total
+// so a crashing server is not restarted forever. It returns when ctx is done
+// or the restarts are used up.
+func superviseTransport(ctx context.Context, name string, client transportClient, maxRestarts int, replaced <-chan transportClient, restart func(context.Context) (transportClient, error)) {
+	backoff := lspRestartBackoff
+	restarts := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case next := <-replaced:
+			client = next
+			continue
+		case <-client.TransportFailed():
+		}
+
+		for {
+			if restarts >= maxRestarts {
+				logging.ErrorPersist(fmt.Sprintf("LSP server %s failed and was restarted %d times already; not restarting it again", name, restarts))
+				return
+			}
+			logging.Warn("Restarting LSP server after connection failure", "name", name, "restart", restarts+1, "maxRestarts", maxRestarts, "backoff", backoff)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			restarts++
+
+			next, err := restart(ctx)
+			if err == nil && next.GetServerState() != lsp.StateReady {
+				err = errors.New("server did not become ready")
+			}
+			if err == nil {
+				client = next
+				break
+			}
+			logging.Warn("LSP server restart failed", "name", name, "error", err)
+		}
+	}
 }
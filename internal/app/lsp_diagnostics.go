@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+)
+
+// diagnosticsCoalescer merges diagnostics requests made within a short
+// window, such as those of the edits of one multiedit or of parallel tool
+// calls. The files requested in the window are notified together and every
+// request shares a single wait for their diagnostics.
+type diagnosticsCoalescer struct {
+	window time.Duration
+	// wait notifies the servers that files changed and waits for their
+	// diagnostics.
+	wait func(ctx context.Context, files []string) error
+
+	mu      sync.Mutex
+	pending *diagnosticsBatch
+}
+
+// diagnosticsBatch is the files requested within one window and the result
+// of waiting for them, set before done is closed.
+type diagnosticsBatch struct {
+	files map[string]bool
+	done  chan struct{}
+	err   error
+}
+
+func newDiagnosticsCoalescer(window time.Duration, wait func(ctx context.Context, files []string) error) *diagnosticsCoalescer {
+	return &diagnosticsCoalescer{window: window, wait: wait}
+}
+
+// lspDiagnosticsWindow returns the configured lspDiagnosticsDebounce window,
+// or zero when merging is disabled.
+func lspDiagnosticsWindow() time.Duration {
+	debounce := config.DefaultLSPDiagnosticsDebounce
+	if cfg := config.Get(); cfg != nil && cfg.LSPDiagnosticsDebounce != 0 {
+		debounce = cfg.LSPDiagnosticsDebounce
+	}
+	if debounce < 0 {
+		return 0
+	}
+	return time.Duration(debounce) * time.Millisecond
+}
+
+// Wait adds filePath to the current batch, starting one if none is pending,
+// and returns once the batch's wait ends or ctx is done.
+func (c *diagnosticsCoalescer) Wait(ctx context.Context, filePath string) error {
+	if c.window <= 0 {
+		return c.wait(ctx, []string{filePath})
+	}
+
+	c.mu.Lock()
+	batch := c.pending
+	if batch == nil {
+		batch = &diagnosticsBatch{files: map[string]bool{}, done: make(chan struct{})}
+		c.pending = batch
+		// The batch outlives the request that started it, so it is not bound
+		// to that request's context; the wait is bounded on its own.
+		time.AfterFunc(c.window, func() { c.flush(context.WithoutCancel(ctx), batch) })
+	}
+	batch.files[filePath] = true
+	c.mu.Unlock()
+
+	select {
+	case <-batch.done:
+		return batch.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush closes the window of batch and waits for the diagnostics of its
+// files.
+func (c *diagnosticsCoalescer) flush(ctx context.Context, batch *diagnosticsBatch) {
+	c.mu.Lock()
+	if c.pending == batch {
+		c.pending = nil
+	}
+	files := make([]string, 0, len(batch.files))
+	for file := range batch.files {
+		files = append(files, file)
+	}
+	c.mu.Unlock()
+
+	slices.Sort(files)
+	batch.err = c.wait(ctx, files)
+	close(batch.done)
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingWait stands in for the LSP wait, recording the files of each call.
+type recordingWait struct {
+	mu    sync.Mutex
+	calls [][]string
+	err   error
+}
+
+func (r *recordingWait) wait(_ context.Context, files []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, files)
+	return r.err
+}
+
+func (r *recordingWait) recorded() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls
+}
+
+// waitAll requests diagnostics for each file at once and returns the error
+// each request returned.
+func waitAll(c *diagnosticsCoalescer, files ...string) []error {
+	errs := make([]error, len(files))
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = c.Wait(context.Background(), file)
+		}()
+	}
+	wg.Wait()
+	return errs
+}
+
+func TestDiagnosticsCoalescer_MergesRequestsForOneFile(t *testing.T) {
+	diagnosticsErr := errors.New("diagnostics result")
+	rec := &recordingWait{err: diagnosticsErr}
+	c := newDiagnosticsCoalescer(200*time.Millisecond, rec.wait)
+
+	errs := waitAll(c, "/p/main.go", "/p/main.go", "/p/main.go")
+
+	assert.Equal(t, [][]string{{"/p/main.go"}}, rec.recorded())
+	for _, err := range errs {
+		assert.Same(t, diagnosticsErr, err)
+	}
+}
+
+func TestDiagnosticsCoalescer_NotifiesChangedFilesTogether(t *testing.T) {
+	rec := &recordingWait{}
+	c := newDiagnosticsCoalescer(200*time.Millisecond, rec.wait)
+
+	errs := waitAll(c, "/p/b.go", "/p/a.go", "/p/b.go")
+
+	assert.Equal(t, [][]string{{"/p/a.go", "/p/b.go"}}, rec.recorded())
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+
+	// A request after the window closed starts a new batch
+	require.NoError(t, c.Wait(context.Background(), "/p/a.go"))
+	assert.Len(t, rec.recorded(), 2)
+}
+
+func TestDiagnosticsCoalescer_Disabled(t *testing.T) {
+	rec := &recordingWait{}
+	c := newDiagnosticsCoalescer(0, rec.wait)
+
+	waitAll(c, "/p/main.go", "/p/main.go", "/p/main.go")
+
+	assert.Len(t, rec.recorded(), 3)
+}
+
+func TestDiagnosticsCoalescer_CanceledRequest(t *testing.T) {
+	release := make(chan struct{})
+	c := newDiagnosticsCoalescer(10*time.Millisecond, func(context.Context, []string) error {
+		<-release
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Wait(ctx, "/p/main.go") }()
+	other := make(chan error, 1)
+	go func() { other <- c.Wait(context.Background(), "/p/main.go") }()
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	// The wait still completes for the request that was not canceled
+	close(release)
+	assert.NoError(t, <-other)
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeReadyClient becomes ready on the readyOn-th wait; earlier waits block
+// until their context expires, like a server that is still indexing.
+type fakeReadyClient struct {
+	readyOn  int
+	attempts int
+	state    lsp.ServerState
+}
+
+func (f *fakeReadyClient) WaitForServerReady(ctx context.Context) error {
+	f.attempts++
+	f.state = lsp.StateStarting
+	if f.attempts >= f.readyOn {
+		return nil
+	}
+	<-ctx.Done()
+	return errors.New("timeout waiting for LSP server to be ready")
+}
+
+func (f *fakeReadyClient) SetServerState(state lsp.ServerState) {
+	f.state = state
+}
+
+func TestWaitForServerReady(t *testing.T) {
+	original := lspReadyBackoff
+	lspReadyBackoff = time.Millisecond
+	t.Cleanup(func() { lspReadyBackoff = original })
+
+	tests := []struct {
+		name         string
+		readyOn      int
+		retries      int
+		wantErr      bool
+		wantAttempts int
+		wantState    lsp.ServerState
+	}{
+		{
+			name:         "ready on first attempt",
+			readyOn:      1,
+			retries:      2,
+			wantAttempts: 1,
+			wantState:    lsp.StateReady,
+		},
+		{
+			name:         "ready on second attempt",
+			readyOn:      2,
+			retries:      1,
+			wantAttempts: 2,
+			wantState:    lsp.StateReady,
+		},
+		{
+			name:         "no retries configured",
+			readyOn:      2,
+			retries:      0,
+			wantErr:      true,
+			wantAttempts: 1,
+			wantState:    lsp.StateError,
+		},
+		{
+			name:         "retries exhausted",
+			readyOn:      5,
+			retries:      2,
+			wantErr:      true,
+			wantAttempts: 3,
+			wantState:    lsp.StateError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeReadyClient{readyOn: tt.readyOn}
+			err := waitForServerReady(context.Background(), client, "test", 10*time.Millisecond, tt.retries)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantAttempts, client.attempts)
+			assert.Equal(t, tt.wantState, client.state)
+		})
+	}
+}
+
+func TestWaitForServerReady_Canceled(t *testing.T) {
+	original := lspReadyBackoff
+	lspReadyBackoff = time.Hour
+	t.Cleanup(func() { lspReadyBackoff = original })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := &fakeReadyClient{readyOn: 2}
+	err := waitForServerReady(ctx, client, "test", 5*time.Millisecond, 3)
+	require.Error(t, err)
+	assert.Equal(t, 1, client.attempts)
+	assert.Equal(t, lsp.StateError, client.state)
+}
+
+// fakeTransportClient is a client whose connection breaks when fail is
+// called.
+type fakeTransportClient struct {
+	fakeReadyClient
+	failed chan struct{}
+}
+
+func newFakeTransportClient(readyOn int) *fakeTransportClient {
+	return &fakeTransportClient{fakeReadyClient: fakeReadyClient{readyOn: readyOn}, failed: make(chan struct{})}
+}
+
+func (f *fakeTransportClient) TransportFailed() <-chan struct{} { return f.failed }
+
+func (f *fakeTransportClient) GetServerState() lsp.ServerState { return f.state }
+
+func (f *fakeTransportClient) fail() {
+	f.state = lsp.StateError
+	close(f.failed)
+}
+
+func TestSuperviseTransport(t *testing.T) {
+	originalRestart, originalReady := lspRestartBackoff, lspReadyBackoff
+	lspRestartBackoff, lspReadyBackoff = time.Millisecond, time.Millisecond
+	t.Cleanup(func() { lspRestartBackoff, lspReadyBackoff = originalRestart, originalReady })
+
+	t.Run("recovers after one restart", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		client := newFakeTransportClient(1)
+		client.fail()
+		restarted := make(chan *fakeTransportClient, 3)
+		restart := func(ctx context.Context) (transportClient, error) {
+			next := newFakeTransportClient(2)
+			err := waitForServerReady(ctx, next, "test", 10*time.Millisecond, 1)
+			restarted <- next
+			return next, err
+		}
+
+		done := make(chan struct{})
+		go func() {
+			superviseTransport(ctx, "test", client, 3, nil, restart)
+			close(done)
+		}()
+
+		select {
+		case next := <-restarted:
+			assert.Equal(t, lsp.StateReady, next.GetServerState())
+		case <-time.After(5 * time.Second):
+			t.Fatal("the client was not restarted")
+		}
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the supervisor did not stop when its context was canceled")
+		}
+		assert.Len(t, restarted, 0, "a ready client is not restarted again")
+	})
+
+	t.Run("gives up after maxRestarts", func(t *testing.T) {
+		client := newFakeTransportClient(1)
+		client.fail()
+		calls := 0
+		restart := func(context.Context) (transportClient, error) {
+			calls++
+			return nil, errors.New("server could not be started")
+		}
+
+		done := make(chan struct{})
+		go func() {
+			superviseTransport(context.Background(), "test", client, 2, nil, restart)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the supervisor kept restarting the server")
+		}
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("counts restarts across failures", func(t *testing.T) {
+		client := newFakeTransportClient(1)
+		client.fail()
+		calls := 0
+		restart := func(context.Context) (transportClient, error) {
+			calls++
+			// Every restarted server connects, then crashes again
+			next := newFakeTransportClient(1)
+			next.state = lsp.StateReady
+			close(next.failed)
+			return next, nil
+		}
+
+		done := make(chan struct{})
+		go func() {
+			superviseTransport(context.Background(), "test", client, 3, nil, restart)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the supervisor kept restarting the server")
+		}
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("watches a client replaced outside the supervisor", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		s := &lspService{}
+		replaced := make(chan transportClient, 1)
+		s.replaced = map[string]chan transportClient{"test": replaced}
+		restarted := make(chan struct{}, 1)
+		restart := func(context.Context) (transportClient, error) {
+			restarted <- struct{}{}
+			next := newFakeTransportClient(1)
+			next.state = lsp.StateReady
+			return next, nil
+		}
+		go superviseTransport(ctx, "test", newFakeTransportClient(1), 3, replaced, restart)
+
+		replacement := newFakeTransportClient(1)
+		replacement.state = lsp.StateReady
+		s.clientReplaced("test", replacement)
+		replacement.fail()
+
+		select {
+		case <-restarted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("a failure of the replacement client was not detected")
+		}
+	})
+}
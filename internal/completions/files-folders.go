@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/tui/components/dialog"
@@ -57,7 +58,10 @@ func processNullTerminatedOutput(outputBytes []byte) []string {
 }
 
 func (cg *filesAndFoldersContextGroup) getFiles(query string) ([]string, error) {
-	cmdRg := fileutil.GetRgCmd("") // No glob pattern for this use case
+	cfg := config.Get()
+	followSymlinks := cfg != nil && cfg.Tools.FollowSymlinks
+
+	cmdRg := fileutil.GetRgCmd("", followSymlinks) // No glob pattern for this use case
 	cmdFzf := fileutil.GetFzfCmd(query)
 
 	var matches []string
@@ -114,7 +118,7 @@ func (cg *filesAndFoldersContextGroup) getFiles(query string) ([]string, error)
 		// Case 3: Only fzf available
 	} else if cmdFzf != nil {
 		logging.Debug("Using FZF with doublestar fallback for file completions")
-		files, _, err := fileutil.GlobWithDoublestar("**/*", ".", 0)
+		files, _, err := fileutil.GlobWithDoublestar("**/*", ".", 0, followSymlinks)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list files for fzf: %w", err)
 		}
@@ -151,7 +155,7 @@ func (cg *filesAndFoldersContextGroup) getFiles(query string) ([]string, error)
 		// Case 4: Fallback to doublestar with fuzzy match
 	} else {
 		logging.Debug("Using doublestar with fuzzy match for file completions")
-		allFiles, _, err := fileutil.GlobWithDoublestar("**/*", ".", 0)
+		allFiles, _, err := fileutil.GlobWithDoublestar("**/*", ".", 0, followSymlinks)
 		if err != nil {
 			return nil, fmt.Errorf("failed to glob files: %w", err)
 		}
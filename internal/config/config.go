@@ -5,12 +5,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/format"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 	"github.com/spf13/viper"
@@ -34,6 +41,10 @@ type MCPServer struct {
 	Type    MCPType           `json:"type"`
 	URL     string            `json:"url"`
 	Headers map[string]string `json:"headers"`
+	// KeepaliveInterval is how often, in seconds, an SSE or HTTP server is
+	// pinged while a tool call waits for it, so proxies do not drop the idle
+	// stream and a dropped one is noticed. Zero disables the pings.
+	KeepaliveInterval int `json:"keepaliveInterval,omitempty"`
 }
 
 // AgentName is a string alias to allow flexibility
@@ -94,6 +105,84 @@ type Agent struct {
 	Hidden          bool            `json:"hidden,omitempty"`
 	Disabled        bool            `json:"disabled,omitempty"`
 	Output          *AgentOutput    `json:"output,omitempty"`
+	// MaxParallelTools caps how many independent tool calls from a single
+	// response run at the same time. Zero or one runs them sequentially.
+	MaxParallelTools int `json:"maxParallelTools,omitempty"`
+	// MaxToolIterations overrides the global maxToolIterations for this
+	// agent. Zero uses the global value.
+	MaxToolIterations int `json:"maxToolIterations,omitempty"`
+	// Temperature, TopP and StopSequences tune sampling. Unset values are
+	// left to the provider's defaults.
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+	// MaxReasoningTokens caps the thinking budget of reasoning models,
+	// separately from MaxTokens. Zero leaves the provider default.
+	MaxReasoningTokens int64 `json:"maxReasoningTokens,omitempty"`
+	// Routing sends individual requests to other models, e.g. large prompts
+	// to a model with a bigger context window. The first matching rule wins;
+	// requests no rule matches use Model.
+	Routing []RoutingRule `json:"routing,omitempty"`
+}
+
+// RoutingRule sends a request to Model when the When condition holds for
+// it, such as "tokens>50000".
+type RoutingRule struct {
+	When  string         `json:"when"`
+	Model models.ModelID `json:"model"`
+}
+
+// Metrics a routing condition can test.
+const (
+	// RoutingMetricTokens is the estimated prompt size of the request.
+	RoutingMetricTokens = "tokens"
+	// RoutingMetricMessages is the number of messages in the request.
+	RoutingMetricMessages = "messages"
+)
+
+// RoutingCondition is a parsed RoutingRule.When: a metric compared with a
+// value.
+type RoutingCondition struct {
+	Metric string
+	Op     string
+	Value  int64
+}
+
+var routingConditionPattern = regexp.MustCompile(`^\s*(tokens|messages)\s*(>=|<=|==|>|<)\s*(\d+)\s*$`)
+
+// ParseRoutingCondition parses a condition of the form <metric><op><value>,
+// where metric is tokens or messages and op one of >, >=, <, <= and ==.
+func ParseRoutingCondition(when string) (RoutingCondition, error) {
+	match := routingConditionPattern.FindStringSubmatch(when)
+	if match == nil {
+		return RoutingCondition{}, fmt.Errorf("invalid routing condition %q (expected e.g. \"tokens>50000\" or \"messages>=40\")", when)
+	}
+	value, err := strconv.ParseInt(match[3], 10, 64)
+	if err != nil {
+		return RoutingCondition{}, fmt.Errorf("invalid routing condition %q: %w", when, err)
+	}
+	return RoutingCondition{Metric: match[1], Op: match[2], Value: value}, nil
+}
+
+// Matches reports whether the condition holds for a request with the given
+// estimated prompt tokens and message count.
+func (c RoutingCondition) Matches(tokens, messages int64) bool {
+	actual := tokens
+	if c.Metric == RoutingMetricMessages {
+		actual = messages
+	}
+	switch c.Op {
+	case ">":
+		return actual > c.Value
+	case ">=":
+		return actual >= c.Value
+	case "<":
+		return actual < c.Value
+	case "<=":
+		return actual <= c.Value
+	default:
+		return actual == c.Value
+	}
 }
 
 // Provider defines configuration for an LLM provider.
@@ -102,6 +191,20 @@ type Provider struct {
 	Disabled bool              `json:"disabled"`
 	BaseURL  string            `json:"baseURL"`
 	Headers  map[string]string `json:"headers,omitempty"`
+	// ModelMap renames API models for gateways that serve them under custom
+	// names, e.g. {"gpt-4o": "my-gpt"}. Model IDs are unaffected.
+	ModelMap map[string]string `json:"modelMap,omitempty"`
+	// DisableStreaming sends each request as a single buffered call, for
+	// gateways that stream poorly.
+	DisableStreaming bool `json:"disableStreaming,omitempty"`
+	// IdempotencyKey sends an Idempotency-Key header that stays the same
+	// across retries of a request. Only the OpenAI-compatible, Anthropic
+	// (including Bedrock and Vertex AI Claude models), DeepSeek and xAI
+	// clients send it; Gemini and Kilo ignore it.
+	IdempotencyKey bool `json:"idempotencyKey,omitempty"`
+	// UserAgent replaces the User-Agent sent to the provider, which is
+	// opencode/<version> by default.
+	UserAgent string `json:"userAgent,omitempty"`
 }
 
 // Data defines storage configuration.
@@ -111,14 +214,48 @@ type Data struct {
 
 // LSPConfig defines configuration for Language Server Protocol integration.
 type LSPConfig struct {
-	Disabled       bool              `json:"disabled"`
+	Disabled bool `json:"disabled"`
+	// Auto resolves the entry, keyed by a file extension or language name
+	// such as "go" or "rust", to the built-in server for it, so no command
+	// is needed.
+	Auto           bool              `json:"auto,omitempty"`
 	Command        string            `json:"command"`
 	Args           []string          `json:"args"`
 	Extensions     []string          `json:"extensions,omitempty"`
 	Env            map[string]string `json:"env,omitempty"`
 	Initialization any               `json:"initialization,omitempty"`
+	// ReadyTimeout is the number of seconds to wait for the server to become
+	// ready on each attempt. Zero uses the default of 30 seconds.
+	ReadyTimeout int `json:"readyTimeout,omitempty"`
+	// ReadyRetries is how many more times to wait for readiness, with
+	// backoff, before the server is marked as errored.
+	ReadyRetries int `json:"readyRetries,omitempty"`
+	// AutoRestart restarts the server, with backoff, when the connection to
+	// it breaks mid-session. Unset means on.
+	AutoRestart *bool `json:"autoRestart,omitempty"`
+	// MaxRestarts bounds the automatic restarts of the server in a session
+	// so a crashing server is not restarted forever. Zero uses the default.
+	MaxRestarts int `json:"maxRestarts,omitempty"`
+	// Download allows or denies installing a missing built-in server,
+	// overriding disableLSPDownload for this entry.
+	Download LSPDownload `json:"download,omitempty"`
+	// Version pins the release of a built-in server that is installed, such
+	// as "0.16.2". It is installed separately from servers on PATH.
+	Version string `json:"version,omitempty"`
 }
 
+// LSPDownload controls installing the built-in server of one LSP entry.
+type LSPDownload string
+
+const (
+	LSPDownloadAllow LSPDownload = "allow"
+	LSPDownloadDeny  LSPDownload = "deny"
+)
+
+// lspVersionPattern matches release versions such as 1.2, v0.16.2 or
+// 5.0.0-beta.1.
+var lspVersionPattern = regexp.MustCompile(`^v?\d+(\.\d+){0,3}([-+][0-9A-Za-z.-]+)?$`)
+
 // TUIConfig defines the configuration for the Terminal User Interface.
 type TUIConfig struct {
 	Theme string `json:"theme,omitempty"`
@@ -169,6 +306,10 @@ type PermissionConfig struct {
 	// Rules maps tool names to permission logic.
 	Rules map[string]any `json:"rules,omitempty"` // tool name -> "allow" | {"pattern": "action"}
 
+	// AutoAllowPaths are globs, relative to the working directory, under which
+	// file tools never prompt (e.g. "tmp/**"). Deny rules still apply.
+	AutoAllowPaths []string `json:"autoAllowPaths,omitempty"`
+
 	// Deprecated: use Rules instead, Needed for backward compatibility.
 	Skill map[string]string `json:"skill,omitempty"`
 }
@@ -185,23 +326,174 @@ type WebSearchConfig struct {
 	Providers map[string]WebSearchProvider `json:"providers,omitempty"`
 }
 
+// GuardConfig defines the checks the file editing tools make before changing
+// a file.
+type GuardConfig struct {
+	// ProtectGenerated asks for confirmation before editing a generated file,
+	// one with a "Code generated ... DO NOT EDIT." or @generated header, or a
+	// vendored file, one under a directory such as vendor or node_modules.
+	ProtectGenerated bool `json:"protectGenerated,omitempty"`
+	// SyntaxCheck parses the content an edit would write, for languages with
+	// a built-in parser such as Go and JSON, and warns when it has a syntax
+	// error the file did not have before.
+	SyntaxCheck bool `json:"syntaxCheck,omitempty"`
+	// Strict blocks these edits instead of asking for confirmation or
+	// warning.
+	Strict bool `json:"strict,omitempty"`
+}
+
+// ToolsConfig defines configuration shared by the built-in tools.
+type ToolsConfig struct {
+	// MaxErrorInputChars caps how much of a tool's raw input is echoed back
+	// when its parameters fail to parse.
+	MaxErrorInputChars int `json:"maxErrorInputChars,omitempty"`
+	// FollowSymlinks makes the ls and glob tools descend into symlinked
+	// directories, for both the ripgrep and built-in walker code paths.
+	FollowSymlinks bool `json:"followSymlinks,omitempty"`
+	// SummarizeLargeOutput condenses a tool result too large for the context
+	// with the summarizer agent. Without it, or when summarizing fails, only
+	// the first and last lines of the result are kept.
+	SummarizeLargeOutput bool `json:"summarizeLargeOutput,omitempty"`
+	// DefaultIgnore holds glob patterns excluded by the ls, glob and grep
+	// tools in addition to each call's own ignore list.
+	DefaultIgnore []string `json:"defaultIgnore,omitempty"`
+	// Fetch configures the webfetch tool.
+	Fetch FetchConfig `json:"fetch,omitempty"`
+	// Read configures the read tool.
+	Read ReadConfig `json:"read,omitempty"`
+	// Delete configures the delete tool.
+	Delete DeleteConfig `json:"delete,omitempty"`
+	// BOM controls the UTF-8 byte-order mark of files written by the edit
+	// and write tools. Unset preserves the BOM state of each file.
+	BOM BOMMode `json:"bom,omitempty"`
+}
+
+// BOMMode controls whether a file written by a tool starts with a UTF-8
+// byte-order mark.
+type BOMMode string
+
+const (
+	// BOMPreserve keeps the BOM state of the file being changed; new files
+	// get no BOM.
+	BOMPreserve BOMMode = "preserve"
+	// BOMAdd writes a BOM.
+	BOMAdd BOMMode = "add"
+	// BOMStrip writes no BOM.
+	BOMStrip BOMMode = "strip"
+)
+
+// Valid reports whether m is a known mode or unset.
+func (m BOMMode) Valid() bool {
+	switch m {
+	case "", BOMPreserve, BOMAdd, BOMStrip:
+		return true
+	}
+	return false
+}
+
+// DeleteConfig defines configuration for the delete tool.
+type DeleteConfig struct {
+	// MaxPreviewBytes bounds the preview of removed content shown when
+	// confirming a deletion and in its result, for each file and overall.
+	// Zero uses the default.
+	MaxPreviewBytes int `json:"maxPreviewBytes,omitempty"`
+}
+
+// ReadConfig defines configuration for the read tool.
+type ReadConfig struct {
+	// SummaryThreshold is the line count above which reading a whole file
+	// returns an outline with its first and last lines instead of the full
+	// content. Zero disables summaries.
+	SummaryThreshold int `json:"summaryThreshold,omitempty"`
+}
+
+// FetchConfig defines configuration for the webfetch tool.
+type FetchConfig struct {
+	// AllowedContentTypes lists the media types the tool will download, such
+	// as "application/json" or "text/*". Empty uses text-like defaults.
+	AllowedContentTypes []string `json:"allowedContentTypes,omitempty"`
+}
+
+// HivemindConfig controls how subagents launched through the task tool are
+// run when a single response requests several of them.
+type HivemindConfig struct {
+	// MaxParallel caps how many subagents run at the same time.
+	MaxParallel int `json:"maxParallel,omitempty"`
+	// PerAgentTimeout is the number of seconds a single subagent may run
+	// before it is cancelled. Zero means no limit.
+	PerAgentTimeout int `json:"perAgentTimeout,omitempty"`
+}
+
+// SessionConfig limits what a single session may spend on provider calls.
+type SessionConfig struct {
+	// MaxCostUSD halts a turn before a provider call that would take the
+	// session's cost above this amount. Zero means no limit.
+	MaxCostUSD float64 `json:"maxCostUSD,omitempty"`
+	// MaxTokens halts a turn before a provider call that would take the
+	// input and output tokens used by the session above this count. Zero
+	// means no limit.
+	MaxTokens int64 `json:"maxTokens,omitempty"`
+}
+
+// OutputConfig sets the default output format of non-interactive runs. The
+// --output-format flag overrides it.
+type OutputConfig struct {
+	// Format is text, json or json_schema.
+	Format string `json:"format,omitempty"`
+	// Schema is the schema of the json_schema format: an object, which may
+	// hold a root "$ref" to a schema file, or a file path. Relative paths are
+	// resolved against the working directory. Viper lowercases the keys of an
+	// inline object, so schemas with mixed-case keys belong in a file.
+	Schema any `json:"schema,omitempty"`
+}
+
+// ContextConfig controls extra context attached to tool results.
+type ContextConfig struct {
+	// GitBlame makes the read tool append the most recent commits touching
+	// the file, so the model sees why the code looks the way it does before
+	// editing it. Files outside a git repository get no summary.
+	GitBlame bool `json:"gitBlame,omitempty"`
+	// GitBlameCommits is how many commits the summary lists. Zero uses
+	// DefaultGitBlameCommits.
+	GitBlameCommits int `json:"gitBlameCommits,omitempty"`
+}
+
 // Config is the main configuration structure for the application.
 type Config struct {
-	Data               Data                              `json:"data"`
-	WorkingDir         string                            `json:"wd,omitempty"`
-	MCPServers         map[string]MCPServer              `json:"mcpServers,omitempty"`
-	Providers          map[models.ModelProvider]Provider `json:"providers,omitempty"`
-	LSP                map[string]LSPConfig              `json:"lsp,omitempty"`
-	Agents             map[AgentName]Agent               `json:"agents,omitempty"`
-	Debug              bool                              `json:"debug,omitempty"`
-	DebugLSP           bool                              `json:"debugLSP,omitempty"`
-	ContextPaths       []string                          `json:"contextPaths,omitempty"`
-	TUI                TUIConfig                         `json:"tui"`
-	Shell              ShellConfig                       `json:"shell,omitempty"`
-	AutoCompact        bool                              `json:"autoCompact,omitempty"`
-	DisableLSPDownload bool                              `json:"disableLSPDownload,omitempty"`
-	SessionProvider    SessionProviderConfig             `json:"sessionProvider,omitempty"`
-	WebSearch          *WebSearchConfig                  `json:"webSearch,omitempty"`
+	Data                Data                              `json:"data"`
+	WorkingDir          string                            `json:"wd,omitempty"`
+	MCPServers          map[string]MCPServer              `json:"mcpServers,omitempty"`
+	Providers           map[models.ModelProvider]Provider `json:"providers,omitempty"`
+	LSP                 map[string]LSPConfig              `json:"lsp,omitempty"`
+	Agents              map[AgentName]Agent               `json:"agents,omitempty"`
+	DefaultAgent        AgentName                         `json:"defaultAgent,omitempty"`
+	Debug               bool                              `json:"debug,omitempty"`
+	DebugLSP            bool                              `json:"debugLSP,omitempty"`
+	ContextPaths        []string                          `json:"contextPaths,omitempty"`
+	WorktreeMarkers     []string                          `json:"worktreeMarkers,omitempty"`
+	ContextMaxBytes     int                               `json:"contextMaxBytes,omitempty"`
+	MaxFileSizeForTools int64                             `json:"maxFileSizeForTools,omitempty"`
+	Context             ContextConfig                     `json:"context,omitempty"`
+	TUI                 TUIConfig                         `json:"tui"`
+	Shell               ShellConfig                       `json:"shell,omitempty"`
+	AutoCompact         bool                              `json:"autoCompact,omitempty"`
+	ConfirmPlans        bool                              `json:"confirmPlans,omitempty"`
+	MaxToolIterations   int                               `json:"maxToolIterations,omitempty"`
+	StrictMigration     bool                              `json:"strictMigration,omitempty"`
+	StrictValidation    bool                              `json:"strictValidation,omitempty"`
+	DisableLSPDownload  bool                              `json:"disableLSPDownload,omitempty"`
+	SessionProvider     SessionProviderConfig             `json:"sessionProvider,omitempty"`
+	Session             SessionConfig                     `json:"session,omitempty"`
+	Output              OutputConfig                      `json:"output,omitempty"`
+	WebSearch           *WebSearchConfig                  `json:"webSearch,omitempty"`
+	Tools               ToolsConfig                       `json:"tools,omitempty"`
+	Hivemind            HivemindConfig                    `json:"hivemind,omitempty"`
+	Locale              string                            `json:"locale,omitempty"`
+	Guard               GuardConfig                       `json:"guard,omitempty"`
+
+	// LSPDiagnosticsDebounce is the window, in milliseconds, in which
+	// diagnostics requests are merged into one wait. Negative disables it.
+	LSPDiagnosticsDebounce int `json:"lspDiagnosticsDebounce,omitempty"`
 
 	// Deprecated: use Rules instead, Needed for backward compatibility.
 	Skills     *SkillsConfig     `json:"skills,omitempty"`
@@ -215,6 +507,24 @@ const (
 	appName              = "opencode"
 
 	MaxTokensFallbackDefault = 4096
+
+	DefaultHivemindMaxParallel = 3
+
+	// DefaultMaxToolIterations is how many rounds of tool calls a single
+	// turn may make when maxToolIterations is not set.
+	DefaultMaxToolIterations = 20
+
+	// DefaultGitBlameCommits is how many recent commits context.gitBlame
+	// lists when context.gitBlameCommits is not set.
+	DefaultGitBlameCommits = 5
+
+	// DefaultLSPMaxRestarts is how many times an LSP server is restarted
+	// automatically when lsp.<name>.maxRestarts is not set.
+	DefaultLSPMaxRestarts = 3
+
+	// DefaultLSPDiagnosticsDebounce is the window, in milliseconds, in which
+	// diagnostics requests are merged when lspDiagnosticsDebounce is not set.
+	DefaultLSPDiagnosticsDebounce = 100
 )
 
 var defaultContextPaths = []string{
@@ -233,6 +543,23 @@ var defaultContextPaths = []string{
 	"AGENTS.md",
 }
 
+// defaultWorktreeMarkers are the files or directories that mark the root of a
+// project when worktreeMarkers is not set.
+var defaultWorktreeMarkers = []string{".git"}
+
+// primaryInstructionFiles are the context files that each hold a complete set
+// of agent instructions, as opposed to .local overrides and rule directories
+// that add to them.
+var primaryInstructionFiles = []string{
+	".github/copilot-instructions.md",
+	".cursorrules",
+	"CLAUDE.md",
+	"opencode.md",
+	"OpenCode.md",
+	"OPENCODE.md",
+	"AGENTS.md",
+}
+
 // Configurator interface for testability
 type Configurator interface {
 	WorkingDirectory() string
@@ -276,7 +603,8 @@ func Load(workingDir string, debug bool) (*Config, error) {
 		return cfg, err
 	}
 
-	// Load and merge local config
+	// Load and merge the OPENCODE_ENV config, then the local config
+	mergeEnvConfig(workingDir)
 	mergeLocalConfig(workingDir)
 
 	// Map environment variables to viper
@@ -288,19 +616,28 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	}
 
 	// 1. MIGRATION: Handle Upstream rename logic
-	migrateOldAgentNames()
+	if err := migrateOldAgentNames(); err != nil {
+		return cfg, err
+	}
 
 	// 2. DEFAULTS: Set provider defaults based on environment and available credentials
 	setProviderDefaults()
 
 	applyDefaultValues()
+	expandHomePaths()
 
 	// Initialize logging
 	if err := initLogging(debug); err != nil {
 		return cfg, err
 	}
 
-	// Validate configuration
+	// Validate configuration. With strictValidation set, problems Validate
+	// would work around are errors instead.
+	if cfg.StrictValidation {
+		if err := ValidateStrict(); err != nil {
+			return cfg, fmt.Errorf("config validation failed: %w", err)
+		}
+	}
 	if err := Validate(); err != nil {
 		return cfg, fmt.Errorf("config validation failed: %w", err)
 	}
@@ -314,27 +651,33 @@ func Load(workingDir string, debug bool) (*Config, error) {
 	return cfg, nil
 }
 
-// migrateOldAgentNames handles backward compatibility for upstream changes
-func migrateOldAgentNames() {
+// deprecatedAgentNames lists agent names renamed upstream with their new names.
+var deprecatedAgentNames = []struct{ from, to AgentName }{
+	{AgentTask, AgentExplorer},
+	{AgentTitle, AgentDescriptor},
+}
+
+// migrateOldAgentNames handles backward compatibility for upstream changes.
+// With strictMigration set, a deprecated name is an error instead.
+func migrateOldAgentNames() error {
 	if cfg.Agents == nil {
 		cfg.Agents = make(map[AgentName]Agent)
 	}
-	// Migrate "task" -> "explorer"
-	if agent, ok := cfg.Agents[AgentTask]; ok {
-		if _, exists := cfg.Agents[AgentExplorer]; !exists {
-			cfg.Agents[AgentExplorer] = agent
+	for _, rename := range deprecatedAgentNames {
+		agent, ok := cfg.Agents[rename.from]
+		if !ok {
+			continue
 		}
-		delete(cfg.Agents, AgentTask)
-		logging.Warn("agent name 'task' is deprecated, migrated to 'explorer'")
-	}
-	// Migrate "title" -> "descriptor"
-	if agent, ok := cfg.Agents[AgentTitle]; ok {
-		if _, exists := cfg.Agents[AgentDescriptor]; !exists {
-			cfg.Agents[AgentDescriptor] = agent
+		if cfg.StrictMigration {
+			return fmt.Errorf("agent name '%s' is deprecated, rename it to '%s' in your config (strictMigration is enabled)", rename.from, rename.to)
+		}
+		if _, exists := cfg.Agents[rename.to]; !exists {
+			cfg.Agents[rename.to] = agent
 		}
-		delete(cfg.Agents, AgentTitle)
-		logging.Warn("agent name 'title' is deprecated, migrated to 'descriptor'")
+		delete(cfg.Agents, rename.from)
+		logging.Warn("deprecated agent name migrated", "agent", rename.from, "to", rename.to)
 	}
+	return nil
 }
 
 // initLogging handles the creation of log files and logger initialization
@@ -382,19 +725,26 @@ func initLogging(debug bool) error {
 func configureViper() {
 	viper.SetConfigName("." + appName)
 	viper.SetConfigType("json")
-	viper.AddConfigPath("$HOME")
-	viper.AddConfigPath("$XDG_CONFIG_HOME/" + appName)
-	viper.AddConfigPath("$HOME/.config/" + appName)
+	addGlobalConfigPaths(viper.GetViper())
 	viper.SetEnvPrefix(strings.ToUpper(appName))
 	viper.AutomaticEnv()
 }
 
+// addGlobalConfigPaths adds the user-level config locations to v.
+func addGlobalConfigPaths(v *viper.Viper) {
+	v.AddConfigPath("$HOME")
+	v.AddConfigPath("$XDG_CONFIG_HOME/" + appName)
+	v.AddConfigPath("$HOME/.config/" + appName)
+}
+
 // setDefaults configures default values for configuration options.
 func setDefaults(debug bool) {
 	viper.SetDefault("data.directory", defaultDataDirectory)
 	viper.SetDefault("contextPaths", defaultContextPaths)
+	viper.SetDefault("worktreeMarkers", defaultWorktreeMarkers)
 	viper.SetDefault("tui.theme", "opencode")
 	viper.SetDefault("autoCompact", true)
+	viper.SetDefault("hivemind.maxParallel", DefaultHivemindMaxParallel)
 
 	// LSP download control
 	if v := os.Getenv("OPENCODE_DISABLE_LSP_DOWNLOAD"); v == "true" || v == "1" {
@@ -518,6 +868,29 @@ func readConfig(err error) error {
 	return fmt.Errorf("failed to read config: %w", err)
 }
 
+// mergeEnvConfig loads and merges the .opencode.<env> configuration selected
+// by OPENCODE_ENV. The working directory is searched first, then the global
+// config locations.
+func mergeEnvConfig(workingDir string) {
+	env := os.Getenv("OPENCODE_ENV")
+	if env == "" {
+		return
+	}
+	if strings.ContainsAny(env, `/\`) {
+		logging.Warn("OPENCODE_ENV must be a plain name, ignoring", "env", env)
+		return
+	}
+
+	envConfig := viper.New()
+	envConfig.SetConfigName("." + appName + "." + env)
+	envConfig.SetConfigType("json")
+	envConfig.AddConfigPath(workingDir)
+	addGlobalConfigPaths(envConfig)
+	if err := envConfig.ReadInConfig(); err == nil {
+		viper.MergeConfigMap(envConfig.AllSettings())
+	}
+}
+
 // mergeLocalConfig loads and merges configuration from the local directory.
 func mergeLocalConfig(workingDir string) {
 	local := viper.New()
@@ -539,6 +912,29 @@ func applyDefaultValues() {
 	}
 }
 
+// expandHomePaths expands a leading ~ in the configured paths and LSP
+// commands, which would otherwise be used literally.
+func expandHomePaths() {
+	cfg.Data.Directory = fileutil.ExpandHome(cfg.Data.Directory)
+	if cfg.Skills != nil {
+		for i, p := range cfg.Skills.Paths {
+			cfg.Skills.Paths[i] = fileutil.ExpandHome(p)
+		}
+	}
+	if cfg.Permission != nil {
+		for i, p := range cfg.Permission.AutoAllowPaths {
+			cfg.Permission.AutoAllowPaths[i] = fileutil.ExpandHome(p)
+		}
+	}
+	for name, lsp := range cfg.LSP {
+		lsp.Command = fileutil.ExpandHome(lsp.Command)
+		for i, arg := range lsp.Args {
+			lsp.Args[i] = fileutil.ExpandHome(arg)
+		}
+		cfg.LSP[name] = lsp
+	}
+}
+
 // setDefaultModelForAgent sets default models based on available providers
 func setDefaultModelForAgent(agent AgentName) bool {
 	definitions := []providerDefinition{
@@ -727,17 +1123,111 @@ func configureAgent(agent AgentName, def providerDefinition) {
 	}
 }
 
-// validateAgent validates model IDs and providers.
-func validateAgent(cfg *Config, name AgentName, agent Agent) error {
+// builtinAgentModes holds the mode of each built-in agent when the config
+// does not override it.
+var builtinAgentModes = map[AgentName]AgentMode{
+	AgentCoder:      AgentModeAgent,
+	AgentHivemind:   AgentModeAgent,
+	AgentExplorer:   AgentModeSubagent,
+	AgentWorkhorse:  AgentModeSubagent,
+	AgentSummarizer: AgentModeSubagent,
+	AgentDescriptor: AgentModeSubagent,
+}
+
+// effectiveAgentMode returns the mode an agent runs in: the configured mode,
+// else the built-in default. Custom agents default to subagents.
+func effectiveAgentMode(name AgentName, agent Agent) AgentMode {
+	if agent.Mode != "" {
+		return agent.Mode
+	}
+	if mode, ok := builtinAgentModes[name]; ok {
+		return mode
+	}
+	return AgentModeSubagent
+}
+
+// validateAgentModes checks that at least one enabled agent can be used as a
+// top-level agent. Built-in agents missing from the config keep their default
+// mode.
+func validateAgentModes(cfg *Config) error {
+	for name, mode := range builtinAgentModes {
+		if _, configured := cfg.Agents[name]; !configured && mode == AgentModeAgent {
+			return nil
+		}
+	}
+	for name, agent := range cfg.Agents {
+		if !agent.Disabled && effectiveAgentMode(name, agent) == AgentModeAgent {
+			return nil
+		}
+	}
+	return errors.New("no agent with mode \"agent\" is configured: at least one top-level agent is required")
+}
+
+// validateDefaultAgent checks that defaultAgent names an enabled, visible
+// agent with mode "agent". Names the config does not know may be markdown
+// agents, which are checked when a prompt is run.
+func validateDefaultAgent(cfg *Config) error {
+	name := cfg.DefaultAgent
+	if name == "" {
+		return nil
+	}
+	agent, configured := cfg.Agents[name]
+	if _, builtin := builtinAgentModes[name]; !configured && !builtin {
+		return nil
+	}
+	switch {
+	case agent.Disabled:
+		return fmt.Errorf("invalid defaultAgent: agent %s is disabled", name)
+	case agent.Hidden:
+		return fmt.Errorf("invalid defaultAgent: agent %s is hidden", name)
+	case effectiveAgentMode(name, agent) != AgentModeAgent:
+		return fmt.Errorf("invalid defaultAgent: %s is a subagent; only agents with mode \"agent\" can run prompts", name)
+	}
+	return nil
+}
+
+// validateAgent validates the agent mode, model IDs and providers. Problems
+// that are worked around with a warning are also added to issues when it is
+// non-nil, and the agent is left unchanged.
+func validateAgent(cfg *Config, name AgentName, agent Agent, issues *validationIssues) error {
+	if agent.Temperature != nil && (*agent.Temperature < 0 || *agent.Temperature > 2) {
+		return fmt.Errorf("agent %s: invalid temperature %g (must be between 0 and 2)", name, *agent.Temperature)
+	}
+	if agent.TopP != nil && (*agent.TopP <= 0 || *agent.TopP > 1) {
+		return fmt.Errorf("agent %s: invalid topP %g (must be greater than 0 and at most 1)", name, *agent.TopP)
+	}
+	if slices.Contains(agent.StopSequences, "") {
+		return fmt.Errorf("agent %s: stopSequences must not contain empty strings", name)
+	}
+	if agent.MaxReasoningTokens < 0 {
+		return fmt.Errorf("agent %s: invalid maxReasoningTokens %d (must be zero for the default or positive)", name, agent.MaxReasoningTokens)
+	}
+	for i, rule := range agent.Routing {
+		if _, err := ParseRoutingCondition(rule.When); err != nil {
+			return fmt.Errorf("agent %s: routing rule %d: %w", name, i+1, err)
+		}
+		if _, ok := models.SupportedModels[rule.Model]; !ok {
+			return fmt.Errorf("agent %s: routing rule %d: unsupported model %q", name, i+1, rule.Model)
+		}
+	}
+
 	// Check if model exists
 	model, modelExists := models.SupportedModels[agent.Model]
 	if !modelExists {
 		logging.Warn("unsupported model configured, reverting to default", "agent", name, "model", agent.Model)
+		if issues != nil {
+			issues.add("agent %s: unsupported model %q", name, agent.Model)
+			return nil
+		}
 		if setDefaultModelForAgent(name) {
 			return nil
 		}
 		return fmt.Errorf("no valid provider available for agent %s", name)
 	}
+	if model.ContextWindow > 0 && agent.MaxReasoningTokens > model.ContextWindow {
+		return fmt.Errorf("agent %s: maxReasoningTokens %d exceeds the %d token context window of %q",
+			name, agent.MaxReasoningTokens, model.ContextWindow, model.ID)
+	}
 
 	// Check if provider for the model is configured
 	provider := model.Provider
@@ -747,6 +1237,10 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 		apiKey := GetProviderAPIKey(provider)
 		if apiKey == "" {
 			logging.Warn("provider not configured for model, reverting default", "agent", name)
+			if issues != nil {
+				issues.add("agent %s: provider %s for model %q is not configured", name, provider, agent.Model)
+				return nil
+			}
 			if setDefaultModelForAgent(name) {
 				return nil
 			}
@@ -759,6 +1253,10 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 		cfg.Providers[provider] = Provider{APIKey: apiKey}
 	} else if providerCfg.Disabled || providerCfg.APIKey == "" {
 		logging.Warn("provider disabled/empty, reverting default", "agent", name)
+		if issues != nil {
+			issues.add("agent %s: provider %s for model %q is disabled or has no API key", name, provider, agent.Model)
+			return nil
+		}
 		if setDefaultModelForAgent(name) {
 			return nil
 		}
@@ -776,6 +1274,16 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 		cfg.Agents[name] = updatedAgent
 	}
 
+	// Make sure the requested output leaves room for input
+	if updatedAgent := cfg.Agents[name]; updatedAgent.MaxTokens > 0 {
+		if clamped := clampMaxTokens(name, updatedAgent.MaxTokens, model); clamped != updatedAgent.MaxTokens {
+			issues.add("agent %s: maxTokens %d leaves no room for input in the %d token context window of %q",
+				name, updatedAgent.MaxTokens, model.ContextWindow, model.ID)
+			updatedAgent.MaxTokens = clamped
+			cfg.Agents[name] = updatedAgent
+		}
+	}
+
 	// Reasoning effort checks
 	if model.CanReason && (provider == models.ProviderOpenAI || provider == models.ProviderLocal) {
 		if agent.ReasoningEffort == "" {
@@ -788,8 +1296,88 @@ func validateAgent(cfg *Config, name AgentName, agent Agent) error {
 	return nil
 }
 
-// Validate checks if the configuration is valid.
+// clampMaxTokens returns maxTokens limited so that it leaves room for input
+// within the model's context window. Oversized values fall back to the model's
+// default max tokens, or half the context window when that does not fit either.
+func clampMaxTokens(name AgentName, maxTokens int64, model models.Model) int64 {
+	if model.ContextWindow <= 0 || maxTokens < model.ContextWindow {
+		return maxTokens
+	}
+
+	clamped := model.ContextWindow / 2
+	if model.DefaultMaxTokens > 0 && model.DefaultMaxTokens < model.ContextWindow {
+		clamped = model.DefaultMaxTokens
+	}
+	logging.Warn("maxTokens leaves no room for input in the model's context window, clamping",
+		"agent", name,
+		"model", model.ID,
+		"maxTokens", maxTokens,
+		"contextWindow", model.ContextWindow,
+		"clampedTo", clamped,
+	)
+	return clamped
+}
+
+// ValidationError lists the issues found by ValidateStrict.
+type ValidationError struct {
+	Issues []string
+}
+
+// Error returns a formatted error string listing every issue.
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "config validation found %d issue(s):", len(e.Issues))
+	for _, issue := range e.Issues {
+		b.WriteString("\n  - " + issue)
+	}
+	return b.String()
+}
+
+// validationIssues collects the problems Validate works around with a
+// warning. Adding to a nil collector does nothing.
+type validationIssues struct {
+	issues []string
+}
+
+func (v *validationIssues) add(format string, args ...any) {
+	if v != nil {
+		v.issues = append(v.issues, fmt.Sprintf(format, args...))
+	}
+}
+
+// Validate checks if the configuration is valid. Recoverable problems, such
+// as an unsupported model or a provider without an API key, are logged and
+// fixed up in place.
 func Validate() error {
+	return validate(nil)
+}
+
+// ValidateStrict checks the configuration like Validate, but returns a
+// *ValidationError listing every problem Validate would only warn about. The
+// configuration is left unchanged.
+func ValidateStrict() error {
+	if cfg == nil {
+		return errors.New("config not loaded")
+	}
+	// Validation fills in defaults and disables what it cannot use as it
+	// goes; the strict check only reports, so those changes are undone.
+	agents, providers, lsps := maps.Clone(cfg.Agents), maps.Clone(cfg.Providers), maps.Clone(cfg.LSP)
+	defer func() {
+		cfg.Agents, cfg.Providers, cfg.LSP = agents, providers, lsps
+	}()
+
+	issues := &validationIssues{}
+	if err := validate(issues); err != nil {
+		return err
+	}
+	if len(issues.issues) > 0 {
+		slices.Sort(issues.issues)
+		return &ValidationError{Issues: issues.issues}
+	}
+	return nil
+}
+
+func validate(issues *validationIssues) error {
 	if cfg == nil {
 		return errors.New("config not loaded")
 	}
@@ -798,17 +1386,60 @@ func Validate() error {
 		return fmt.Errorf("session provider validation failed: %w", err)
 	}
 
+	if cfg.ContextMaxBytes < 0 {
+		return fmt.Errorf("invalid contextMaxBytes: %d (must be zero for no limit or positive)", cfg.ContextMaxBytes)
+	}
+	if cfg.MaxFileSizeForTools < 0 {
+		return fmt.Errorf("invalid maxFileSizeForTools: %d (must be zero for no limit or positive)", cfg.MaxFileSizeForTools)
+	}
+	if slices.Contains(cfg.WorktreeMarkers, "") {
+		return errors.New("invalid worktreeMarkers: markers must not be empty strings")
+	}
+	for _, pattern := range cfg.Tools.DefaultIgnore {
+		if err := fileutil.ValidateGlob(strings.TrimPrefix(pattern, "!")); err != nil {
+			return fmt.Errorf("invalid tools.defaultIgnore: %w", err)
+		}
+	}
+	if cfg.Context.GitBlameCommits < 0 {
+		return fmt.Errorf("invalid context.gitBlameCommits: %d (must be zero for the default or positive)", cfg.Context.GitBlameCommits)
+	}
+	if cfg.Session.MaxCostUSD < 0 {
+		return fmt.Errorf("invalid session.maxCostUSD: %g (must be zero for no limit or positive)", cfg.Session.MaxCostUSD)
+	}
+	if cfg.Session.MaxTokens < 0 {
+		return fmt.Errorf("invalid session.maxTokens: %d (must be zero for no limit or positive)", cfg.Session.MaxTokens)
+	}
+	if !cfg.Tools.BOM.Valid() {
+		return fmt.Errorf("invalid tools.bom: %q (must be %q, %q or %q)", cfg.Tools.BOM, BOMPreserve, BOMAdd, BOMStrip)
+	}
+	if cfg.Tools.Delete.MaxPreviewBytes < 0 {
+		return fmt.Errorf("invalid tools.delete.maxPreviewBytes: %d (must be zero for the default or positive)", cfg.Tools.Delete.MaxPreviewBytes)
+	}
+	if cfg.MaxToolIterations < 0 {
+		return fmt.Errorf("invalid maxToolIterations: %d (must be zero for the default or positive)", cfg.MaxToolIterations)
+	}
+	if _, _, err := cfg.Output.Resolve(cfg.WorkingDir); err != nil {
+		return fmt.Errorf("invalid output config: %w", err)
+	}
+
 	for name, agent := range cfg.Agents {
-		if err := validateAgent(cfg, name, agent); err != nil {
+		if err := validateAgent(cfg, name, agent, issues); err != nil {
 			return err
 		}
 	}
+	if err := validateAgentModes(cfg); err != nil {
+		return err
+	}
+	if err := validateDefaultAgent(cfg); err != nil {
+		return err
+	}
 
 	// Validate providers
 	for provider, providerCfg := range cfg.Providers {
 		if providerCfg.APIKey == "" && !providerCfg.Disabled {
 			fmt.Printf("provider has no API key, marking as disabled %s", provider)
 			logging.Warn("provider has no API key, marking as disabled", "provider", provider)
+			issues.add("provider %s: no API key", provider)
 			providerCfg.Disabled = true
 			cfg.Providers[provider] = providerCfg
 		}
@@ -816,16 +1447,71 @@ func Validate() error {
 
 	// Validate LSP configurations
 	for language, lspConfig := range cfg.LSP {
-		if lspConfig.Command == "" && !lspConfig.Disabled && len(lspConfig.Extensions) == 0 {
+		if lspConfig.MaxRestarts < 0 {
+			return fmt.Errorf("invalid lsp.%s.maxRestarts: %d (must be zero for the default or positive)", language, lspConfig.MaxRestarts)
+		}
+		if err := validateLSPInstall(language, lspConfig); err != nil {
+			return err
+		}
+		if lspConfig.Command == "" && !lspConfig.Disabled && !lspConfig.Auto && len(lspConfig.Extensions) == 0 {
 			logging.Warn("LSP configuration has no command, marking as disabled", "language", language)
+			issues.add("lsp %s: no command", language)
 			lspConfig.Disabled = true
 			cfg.LSP[language] = lspConfig
 		}
 	}
+	for _, names := range duplicateLSPCommands(cfg.LSP) {
+		logging.Warn("LSP configurations run the same command; they will share one server", "languages", names)
+		issues.add("lsp %s: same command as %s", strings.Join(names[1:], ", "), names[0])
+	}
+
+	for name, mcpServer := range cfg.MCPServers {
+		if mcpServer.KeepaliveInterval < 0 {
+			return fmt.Errorf("invalid mcpServers.%s.keepaliveInterval: %d (must be zero to disable or positive)", name, mcpServer.KeepaliveInterval)
+		}
+		if mcpServer.KeepaliveInterval > 0 && mcpServer.Type == MCPStdio {
+			logging.Warn("keepaliveInterval only applies to sse and http MCP servers, ignoring it", "server", name)
+			issues.add("mcpServers %s: keepaliveInterval ignored for stdio", name)
+		}
+	}
+
+	return nil
+}
 
+// validateLSPInstall checks the download and version options of an LSP
+// entry.
+func validateLSPInstall(language string, lspConfig LSPConfig) error {
+	if lspConfig.Download != "" && lspConfig.Download != LSPDownloadAllow && lspConfig.Download != LSPDownloadDeny {
+		return fmt.Errorf("invalid lsp.%s.download: %q (must be %q or %q)", language, lspConfig.Download, LSPDownloadAllow, LSPDownloadDeny)
+	}
+	if lspConfig.Version != "" && !lspVersionPattern.MatchString(lspConfig.Version) {
+		return fmt.Errorf("invalid lsp.%s.version: %q (must be a release version such as 1.2.3 or v1.2.3)", language, lspConfig.Version)
+	}
 	return nil
 }
 
+// duplicateLSPCommands groups the enabled LSP entries that configure the same
+// command and arguments. Each group is sorted and has at least two names.
+func duplicateLSPCommands(lsps map[string]LSPConfig) [][]string {
+	byCommand := make(map[string][]string)
+	for name, lspConfig := range lsps {
+		if lspConfig.Disabled || lspConfig.Command == "" {
+			continue
+		}
+		key := strings.Join(append([]string{lspConfig.Command}, lspConfig.Args...), "\x00")
+		byCommand[key] = append(byCommand[key], name)
+	}
+	var groups [][]string
+	for _, names := range byCommand {
+		if len(names) > 1 {
+			slices.Sort(names)
+			groups = append(groups, names)
+		}
+	}
+	slices.SortFunc(groups, func(a, b []string) int { return strings.Compare(a[0], b[0]) })
+	return groups
+}
+
 // validateSessionProvider validates the session provider configuration.
 func validateSessionProvider() error {
 	providerType := cfg.SessionProvider.Type
@@ -943,6 +1629,104 @@ func Get() *Config {
 	return cfg
 }
 
+// ResolveContextFiles returns the context instruction files that exist under
+// workDir for the given context paths, relative to workDir. Paths ending in
+// "/" are directories whose files are all included.
+func ResolveContextFiles(workDir string, paths []string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		rel, err := filepath.Rel(workDir, path)
+		if err != nil || seen[rel] {
+			return
+		}
+		seen[rel] = true
+		files = append(files, rel)
+	}
+
+	for _, p := range paths {
+		fullPath := filepath.Join(workDir, p)
+		if strings.HasSuffix(p, "/") {
+			_ = filepath.WalkDir(fullPath, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() {
+					add(path)
+				}
+				return nil
+			})
+			continue
+		}
+		if info, err := os.Stat(fullPath); err == nil && !info.IsDir() {
+			add(fullPath)
+		}
+	}
+	return files
+}
+
+// DetectContextConflicts returns a warning when more than one primary
+// instruction file, such as AGENTS.md and CLAUDE.md, is read as context, since
+// their guidance may contradict each other. It is diagnostic only; all the
+// files are still read.
+func DetectContextConflicts() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	if cfg == nil {
+		return nil
+	}
+
+	var found []string
+	var infos []os.FileInfo
+	for _, name := range primaryInstructionFiles {
+		if !slices.Contains(cfg.ContextPaths, name) {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(cfg.WorkingDir, name))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		// opencode.md and OPENCODE.md are the same file on case-insensitive
+		// file systems.
+		if slices.ContainsFunc(infos, func(other os.FileInfo) bool { return os.SameFile(info, other) }) {
+			continue
+		}
+		infos = append(infos, info)
+		found = append(found, name)
+	}
+	if len(found) < 2 {
+		return nil
+	}
+	return []string{fmt.Sprintf("multiple instruction files are read as context and may give conflicting guidance: %s", strings.Join(found, ", "))}
+}
+
+// Resolve returns the configured output format and, for json_schema, its
+// resolved and validated schema. Relative schema paths are resolved against
+// workingDir. The format is text when none is configured.
+func (o OutputConfig) Resolve(workingDir string) (format.OutputFormat, map[string]any, error) {
+	if o.Format == "" {
+		if o.Schema != nil {
+			return "", nil, errors.New("output.schema requires output.format json_schema")
+		}
+		return format.Text, nil, nil
+	}
+	outputFormat, err := format.Parse(o.Format)
+	if err != nil {
+		return "", nil, err
+	}
+	if outputFormat != format.JSONSchema {
+		if o.Schema != nil {
+			return "", nil, fmt.Errorf("output.schema is only used with the json_schema format, not %s", outputFormat)
+		}
+		return outputFormat, nil, nil
+	}
+	schema, err := format.ResolveSchema(o.Schema, workingDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("output.schema: %w", err)
+	}
+	return format.JSONSchema, schema, nil
+}
+
 // WorkingDirectory returns the current working directory.
 func WorkingDirectory() string {
 	mu.Lock()
@@ -953,10 +1737,102 @@ func WorkingDirectory() string {
 	return cfg.WorkingDir
 }
 
+// WorktreeRoot returns the project root for dir: the nearest directory, dir
+// itself or an ancestor, that contains any of the configured worktreeMarkers.
+// The nearest marker wins regardless of which marker it is, so a go.mod in a
+// subdirectory of a git repository roots the project there when both are
+// markers. It returns dir when no marker is found.
+func WorktreeRoot(dir string) string {
+	markers := defaultWorktreeMarkers
+	if c := Get(); c != nil && len(c.WorktreeMarkers) > 0 {
+		markers = c.WorktreeMarkers
+	}
+
+	current := dir
+	for {
+		for _, marker := range markers {
+			if _, err := os.Stat(filepath.Join(current, marker)); err == nil {
+				return current
+			}
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+var (
+	workingDirListeners   []func(dir string)
+	workingDirListenersMu sync.Mutex
+)
+
+// OnWorkingDirectoryChange registers fn to be called with the new directory
+// after SetWorkingDirectory changes it. Packages that cache state derived from
+// the working directory use it to refresh that state.
+func OnWorkingDirectoryChange(fn func(dir string)) {
+	workingDirListenersMu.Lock()
+	defer workingDirListenersMu.Unlock()
+	workingDirListeners = append(workingDirListeners, fn)
+}
+
+// SetWorkingDirectory switches the working directory of the loaded
+// configuration to dir, which must be an existing, readable directory.
+// Configuration files are not re-read.
+func SetWorkingDirectory(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("invalid working directory %s: %w", dir, err)
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return fmt.Errorf("invalid working directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid working directory: %s is not a directory", absDir)
+	}
+	f, err := os.Open(absDir)
+	if err != nil {
+		return fmt.Errorf("working directory is not readable: %w", err)
+	}
+	_, err = f.Readdirnames(1)
+	f.Close()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("working directory is not readable: %w", err)
+	}
+
+	mu.Lock()
+	if cfg == nil {
+		mu.Unlock()
+		return errors.New("config not loaded")
+	}
+	cfg.WorkingDir = absDir
+	mu.Unlock()
+
+	workingDirListenersMu.Lock()
+	listeners := slices.Clone(workingDirListeners)
+	workingDirListenersMu.Unlock()
+	for _, fn := range listeners {
+		fn(absDir)
+	}
+	return nil
+}
+
 func (c *Config) WorkingDirectory() string {
 	return WorkingDirectory()
 }
 
+// PromptAgent returns the agent that runs a non-interactive prompt: the one
+// given with --agent, else defaultAgent. An empty name keeps the first
+// primary agent.
+func (c *Config) PromptAgent(flagAgent AgentName) AgentName {
+	if flagAgent != "" {
+		return flagAgent
+	}
+	return c.DefaultAgent
+}
+
 // UpdateAgentModel updates an agent's model in the config.
 func UpdateAgentModel(agentName AgentName, modelID models.ModelID) error {
 	mu.Lock()
@@ -980,7 +1856,7 @@ func UpdateAgentModel(agentName AgentName, modelID models.ModelID) error {
 	newAgentCfg.MaxTokens = maxTokens
 	cfg.Agents[agentName] = newAgentCfg
 
-	if err := validateAgent(cfg, agentName, newAgentCfg); err != nil {
+	if err := validateAgent(cfg, agentName, newAgentCfg, nil); err != nil {
 		cfg.Agents[agentName] = existingAgentCfg
 		return fmt.Errorf("failed to update agent model: %w", err)
 	}
@@ -1,11 +1,16 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/format"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/spf13/viper"
 )
 
 // Helper to test string fields in structs
@@ -508,3 +513,931 @@ func TestConfigStruct(t *testing.T) {
 	testBoolField(t, cfg, func(c Config) bool { return c.AutoCompact }, true, "AutoCompact")
 	testStringSliceField(t, cfg, func(c Config) []string { return c.ContextPaths }, []string{"/context"}, "ContextPaths")
 }
+
+// =============================================================================
+// MaxTokens Validation Tests
+// =============================================================================
+
+func TestClampMaxTokens(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxTokens int64
+		model     models.Model
+		expected  int64
+	}{
+		{
+			name:      "reasonable value passes untouched",
+			maxTokens: 8000,
+			model:     models.Model{ContextWindow: 200_000, DefaultMaxTokens: 20000},
+			expected:  8000,
+		},
+		{
+			name:      "exceeding window clamps to model default",
+			maxTokens: 500_000,
+			model:     models.Model{ContextWindow: 200_000, DefaultMaxTokens: 20000},
+			expected:  20000,
+		},
+		{
+			name:      "equal to window leaves no room for input",
+			maxTokens: 200_000,
+			model:     models.Model{ContextWindow: 200_000, DefaultMaxTokens: 20000},
+			expected:  20000,
+		},
+		{
+			name:      "oversized default falls back to half the window",
+			maxTokens: 300_000,
+			model:     models.Model{ContextWindow: 100_000, DefaultMaxTokens: 150_000},
+			expected:  50_000,
+		},
+		{
+			name:      "unknown context window is not checked",
+			maxTokens: 300_000,
+			model:     models.Model{},
+			expected:  300_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampMaxTokens(AgentCoder, tt.maxTokens, tt.model); got != tt.expected {
+				t.Errorf("clampMaxTokens() = %d, want %d", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestValidateAgentClampsMaxTokens(t *testing.T) {
+	model := models.SupportedModels[models.GPT41Mini]
+	testCfg := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			model.Provider: {APIKey: "test-key"},
+		},
+		Agents: map[AgentName]Agent{
+			AgentCoder:    {Model: model.ID, MaxTokens: model.ContextWindow * 2},
+			AgentExplorer: {Model: model.ID, MaxTokens: 4096},
+		},
+	}
+
+	for name, agent := range testCfg.Agents {
+		if err := validateAgent(testCfg, name, agent, nil); err != nil {
+			t.Fatalf("validateAgent(%s) returned error: %v", name, err)
+		}
+	}
+
+	if got := testCfg.Agents[AgentCoder].MaxTokens; got != model.DefaultMaxTokens {
+		t.Errorf("coder maxTokens = %d, want clamped to %d", got, model.DefaultMaxTokens)
+	}
+	if got := testCfg.Agents[AgentExplorer].MaxTokens; got != 4096 {
+		t.Errorf("explorer maxTokens = %d, want 4096 untouched", got)
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	defer func() { cfg = nil }()
+	model := models.SupportedModels[models.GPT41Mini]
+
+	newConfig := func() *Config {
+		return &Config{
+			Providers: map[models.ModelProvider]Provider{
+				model.Provider:           {APIKey: "test-key"},
+				models.ProviderAnthropic: {},
+			},
+			Agents: map[AgentName]Agent{
+				AgentCoder:    {Model: "no-such-model", MaxTokens: 1000},
+				AgentExplorer: {Model: model.ID, MaxTokens: 1000},
+			},
+		}
+	}
+
+	t.Run("strict enumerates every issue", func(t *testing.T) {
+		cfg = newConfig()
+		err := ValidateStrict()
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("ValidateStrict() error = %v, want *ValidationError", err)
+		}
+		want := []string{
+			`agent coder: unsupported model "no-such-model"`,
+			"provider anthropic: no API key",
+		}
+		if !slices.Equal(validationErr.Issues, want) {
+			t.Errorf("Issues = %q, want %q", validationErr.Issues, want)
+		}
+		for _, issue := range want {
+			if !strings.Contains(err.Error(), "\n  - "+issue) {
+				t.Errorf("error %q does not list %q", err, issue)
+			}
+		}
+		if got := cfg.Agents[AgentCoder].Model; got != "no-such-model" {
+			t.Errorf("strict validation changed coder model to %q", got)
+		}
+		if cfg.Providers[models.ProviderAnthropic].Disabled {
+			t.Error("strict validation disabled the anthropic provider")
+		}
+	})
+
+	t.Run("strict leaves defaults unset", func(t *testing.T) {
+		cfg = newConfig()
+		delete(cfg.Providers, models.ProviderAnthropic)
+		cfg.Agents[AgentCoder] = Agent{Model: model.ID}
+		cfg.LSP = map[string]LSPConfig{"go": {}}
+		err := ValidateStrict()
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("ValidateStrict() error = %v, want *ValidationError", err)
+		}
+		if want := []string{"lsp go: no command"}; !slices.Equal(validationErr.Issues, want) {
+			t.Errorf("Issues = %q, want %q", validationErr.Issues, want)
+		}
+		if got := cfg.Agents[AgentCoder].MaxTokens; got != 0 {
+			t.Errorf("strict validation set coder maxTokens to %d", got)
+		}
+		if cfg.LSP["go"].Disabled {
+			t.Error("strict validation disabled the go LSP")
+		}
+	})
+
+	t.Run("strict passes a clean config", func(t *testing.T) {
+		cfg = newConfig()
+		delete(cfg.Providers, models.ProviderAnthropic)
+		cfg.Agents[AgentCoder] = Agent{Model: model.ID, MaxTokens: 1000}
+		if err := ValidateStrict(); err != nil {
+			t.Errorf("ValidateStrict() error = %v", err)
+		}
+	})
+
+	t.Run("duplicate lsp commands", func(t *testing.T) {
+		cfg = newConfig()
+		delete(cfg.Providers, models.ProviderAnthropic)
+		cfg.Agents[AgentCoder] = Agent{Model: model.ID, MaxTokens: 1000}
+		cfg.LSP = map[string]LSPConfig{
+			"go":     {Command: "gopls", Extensions: []string{".go"}},
+			"gomod":  {Command: "gopls", Extensions: []string{".mod"}},
+			"remote": {Command: "gopls", Args: []string{"-remote=auto"}},
+		}
+		err := ValidateStrict()
+
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) {
+			t.Fatalf("ValidateStrict() error = %v, want *ValidationError", err)
+		}
+		want := []string{"lsp gomod: same command as go"}
+		if !slices.Equal(validationErr.Issues, want) {
+			t.Errorf("Issues = %q, want %q", validationErr.Issues, want)
+		}
+	})
+
+	t.Run("lenient only warns", func(t *testing.T) {
+		cfg = newConfig()
+		cfg.Agents[AgentCoder] = Agent{Model: model.ID, MaxTokens: 1000}
+		if err := Validate(); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+		if !cfg.Providers[models.ProviderAnthropic].Disabled {
+			t.Error("keyless provider should be disabled")
+		}
+	})
+}
+
+// =============================================================================
+// Agent Mode Validation Tests
+// =============================================================================
+
+func TestValidateAgentSampling(t *testing.T) {
+	model := models.SupportedModels[models.GPT41Mini]
+	testCfg := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			model.Provider: {APIKey: "test-key"},
+		},
+		Agents: map[AgentName]Agent{},
+	}
+	ptr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		agent   Agent
+		wantErr bool
+	}{
+		{name: "unset", agent: Agent{}},
+		{name: "zero temperature", agent: Agent{Temperature: ptr(0)}},
+		{name: "in range", agent: Agent{Temperature: ptr(1.5), TopP: ptr(1), StopSequences: []string{"END"}}},
+		{name: "temperature too high", agent: Agent{Temperature: ptr(2.5)}, wantErr: true},
+		{name: "negative temperature", agent: Agent{Temperature: ptr(-0.1)}, wantErr: true},
+		{name: "zero topP", agent: Agent{TopP: ptr(0)}, wantErr: true},
+		{name: "topP above one", agent: Agent{TopP: ptr(1.1)}, wantErr: true},
+		{name: "empty stop sequence", agent: Agent{StopSequences: []string{"END", ""}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.agent.Model = model.ID
+			testCfg.Agents[AgentCoder] = tt.agent
+			err := validateAgent(testCfg, AgentCoder, tt.agent, nil)
+			if tt.wantErr && err == nil {
+				t.Error("expected an error for invalid sampling parameters")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAgentMaxReasoningTokens(t *testing.T) {
+	model := models.SupportedModels[models.Claude37Sonnet]
+	testCfg := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			model.Provider: {APIKey: "test-key"},
+		},
+		Agents: map[AgentName]Agent{},
+	}
+
+	tests := []struct {
+		name               string
+		maxReasoningTokens int64
+		wantErr            bool
+	}{
+		{name: "unset"},
+		{name: "within the window", maxReasoningTokens: 4000},
+		{name: "whole window", maxReasoningTokens: model.ContextWindow},
+		{name: "over the window", maxReasoningTokens: model.ContextWindow + 1, wantErr: true},
+		{name: "negative", maxReasoningTokens: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := Agent{Model: model.ID, MaxReasoningTokens: tt.maxReasoningTokens}
+			testCfg.Agents[AgentCoder] = agent
+			err := validateAgent(testCfg, AgentCoder, agent, nil)
+			if tt.wantErr && err == nil {
+				t.Error("expected an error for invalid maxReasoningTokens")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseRoutingCondition(t *testing.T) {
+	tests := []struct {
+		when     string
+		want     RoutingCondition
+		wantErr  bool
+		tokens   int64
+		messages int64
+		matches  bool
+	}{
+		{when: "tokens>50000", want: RoutingCondition{Metric: RoutingMetricTokens, Op: ">", Value: 50000}, tokens: 50001, matches: true},
+		{when: "tokens>50000", want: RoutingCondition{Metric: RoutingMetricTokens, Op: ">", Value: 50000}, tokens: 50000},
+		{when: " messages >= 20 ", want: RoutingCondition{Metric: RoutingMetricMessages, Op: ">=", Value: 20}, messages: 20, matches: true},
+		{when: "tokens<1000", want: RoutingCondition{Metric: RoutingMetricTokens, Op: "<", Value: 1000}, tokens: 1000},
+		{when: "turns>3", wantErr: true},
+		{when: "tokens>-1", wantErr: true},
+		{when: "tokens", wantErr: true},
+		{when: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.when, func(t *testing.T) {
+			got, err := ParseRoutingCondition(tt.when)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseRoutingCondition(%q) = %+v, want an error", tt.when, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseRoutingCondition(%q) error = %v", tt.when, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseRoutingCondition(%q) = %+v, want %+v", tt.when, got, tt.want)
+			}
+			if matches := got.Matches(tt.tokens, tt.messages); matches != tt.matches {
+				t.Errorf("Matches(%d, %d) = %v, want %v", tt.tokens, tt.messages, matches, tt.matches)
+			}
+		})
+	}
+}
+
+func TestValidateAgentRouting(t *testing.T) {
+	model := models.SupportedModels[models.Claude37Sonnet]
+	testCfg := &Config{
+		Providers: map[models.ModelProvider]Provider{
+			model.Provider: {APIKey: "test-key"},
+		},
+		Agents: map[AgentName]Agent{},
+	}
+
+	tests := []struct {
+		name    string
+		rule    RoutingRule
+		wantErr bool
+	}{
+		{name: "valid rule", rule: RoutingRule{When: "tokens>50000", Model: models.Claude4Sonnet}},
+		{name: "unknown model", rule: RoutingRule{When: "tokens>50000", Model: "no-such-model"}, wantErr: true},
+		{name: "invalid condition", rule: RoutingRule{When: "tokens is large", Model: models.Claude4Sonnet}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := Agent{Model: model.ID, Routing: []RoutingRule{tt.rule}}
+			testCfg.Agents[AgentCoder] = agent
+			err := validateAgent(testCfg, AgentCoder, agent, nil)
+			if tt.wantErr && err == nil {
+				t.Error("expected an error for the invalid routing rule")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateAgentModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		agents  map[AgentName]Agent
+		wantErr bool
+	}{
+		{
+			name: "only subagents",
+			agents: map[AgentName]Agent{
+				AgentCoder:    {Mode: AgentModeSubagent},
+				AgentHivemind: {Mode: AgentModeSubagent},
+				AgentExplorer: {},
+			},
+			wantErr: true,
+		},
+		{
+			name: "top-level agents disabled",
+			agents: map[AgentName]Agent{
+				AgentCoder:    {Disabled: true},
+				AgentHivemind: {Disabled: true},
+			},
+			wantErr: true,
+		},
+		{
+			name: "built-in coder is a top-level agent",
+			agents: map[AgentName]Agent{
+				AgentCoder:    {},
+				AgentHivemind: {Mode: AgentModeSubagent},
+			},
+		},
+		{
+			name: "custom top-level agent",
+			agents: map[AgentName]Agent{
+				AgentCoder:    {Mode: AgentModeSubagent},
+				AgentHivemind: {Disabled: true},
+				"planner":     {Mode: AgentModeAgent},
+			},
+		},
+		{
+			name:   "unconfigured built-ins keep their mode",
+			agents: map[AgentName]Agent{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAgentModes(&Config{Agents: tt.agents})
+			if tt.wantErr && err == nil {
+				t.Error("expected an error when no top-level agent exists")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateDefaultAgent(t *testing.T) {
+	tests := []struct {
+		name    string
+		agent   AgentName
+		agents  map[AgentName]Agent
+		wantErr string
+	}{
+		{name: "unset"},
+		{name: "built-in top-level agent", agent: AgentHivemind},
+		{
+			name:   "subagent configured as a top-level agent",
+			agent:  AgentExplorer,
+			agents: map[AgentName]Agent{AgentExplorer: {Mode: AgentModeAgent}},
+		},
+		{name: "markdown agents are checked at run time", agent: "reviewer"},
+		{name: "built-in subagent", agent: AgentExplorer, wantErr: "is a subagent"},
+		{
+			name:    "custom subagent",
+			agent:   "planner",
+			agents:  map[AgentName]Agent{"planner": {Description: "plans"}},
+			wantErr: "is a subagent",
+		},
+		{
+			name:    "hidden agent",
+			agent:   "planner",
+			agents:  map[AgentName]Agent{"planner": {Mode: AgentModeAgent, Hidden: true}},
+			wantErr: "is hidden",
+		},
+		{
+			name:    "disabled agent",
+			agent:   AgentCoder,
+			agents:  map[AgentName]Agent{AgentCoder: {Disabled: true}},
+			wantErr: "is disabled",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDefaultAgent(&Config{Agents: tt.agents, DefaultAgent: tt.agent})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPromptAgent(t *testing.T) {
+	cfg := &Config{DefaultAgent: AgentHivemind}
+	if got := cfg.PromptAgent(""); got != AgentHivemind {
+		t.Errorf("expected the configured default agent, got %q", got)
+	}
+	if got := cfg.PromptAgent(AgentCoder); got != AgentCoder {
+		t.Errorf("expected --agent to override the default, got %q", got)
+	}
+	if got := (&Config{}).PromptAgent(""); got != "" {
+		t.Errorf("expected no agent without a default, got %q", got)
+	}
+}
+
+func TestResolveContextFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"AGENTS.md", ".cursor/rules/style.md", ".cursor/rules/tests.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := ResolveContextFiles(dir, []string{"CLAUDE.md", "AGENTS.md", ".cursor/rules/", "AGENTS.md"})
+	want := []string{
+		"AGENTS.md",
+		filepath.Join(".cursor", "rules", "style.md"),
+		filepath.Join(".cursor", "rules", "tests.md"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ResolveContextFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestDetectContextConflicts(t *testing.T) {
+	defer func() { cfg = nil }()
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "single file", files: []string{"AGENTS.md"}},
+		{name: "main file with local override", files: []string{"CLAUDE.md", "CLAUDE.local.md", ".cursor/rules/style.md"}},
+		{name: "AGENTS.md and CLAUDE.md", files: []string{"AGENTS.md", "CLAUDE.md"}, want: "CLAUDE.md, AGENTS.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.files {
+				path := filepath.Join(dir, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg = &Config{WorkingDir: dir, ContextPaths: defaultContextPaths}
+
+			warnings := DetectContextConflicts()
+			if tt.want == "" {
+				if len(warnings) != 0 {
+					t.Errorf("DetectContextConflicts() = %v, want no warnings", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.want) {
+				t.Errorf("DetectContextConflicts() = %v, want a warning naming %s", warnings, tt.want)
+			}
+		})
+	}
+
+	t.Run("files outside contextPaths are ignored", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"AGENTS.md", "CLAUDE.md"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cfg = &Config{WorkingDir: dir, ContextPaths: []string{"AGENTS.md"}}
+		if warnings := DetectContextConflicts(); len(warnings) != 0 {
+			t.Errorf("DetectContextConflicts() = %v, want no warnings", warnings)
+		}
+	})
+}
+
+func TestMigrateOldAgentNames(t *testing.T) {
+	defer func() { cfg = nil }()
+
+	t.Run("lenient migrates task to explorer", func(t *testing.T) {
+		cfg = &Config{Agents: map[AgentName]Agent{
+			AgentTask: {MaxTokens: 1000},
+		}}
+		if err := migrateOldAgentNames(); err != nil {
+			t.Fatalf("migrateOldAgentNames() error = %v", err)
+		}
+		if _, ok := cfg.Agents[AgentTask]; ok {
+			t.Errorf("deprecated agent %q should be removed", AgentTask)
+		}
+		if got := cfg.Agents[AgentExplorer].MaxTokens; got != 1000 {
+			t.Errorf("migrated explorer MaxTokens = %d, want 1000", got)
+		}
+	})
+
+	t.Run("lenient keeps an existing target", func(t *testing.T) {
+		cfg = &Config{Agents: map[AgentName]Agent{
+			AgentTitle:      {MaxTokens: 10},
+			AgentDescriptor: {MaxTokens: 20},
+		}}
+		if err := migrateOldAgentNames(); err != nil {
+			t.Fatalf("migrateOldAgentNames() error = %v", err)
+		}
+		if got := cfg.Agents[AgentDescriptor].MaxTokens; got != 20 {
+			t.Errorf("descriptor MaxTokens = %d, want 20", got)
+		}
+	})
+
+	t.Run("strict returns an error naming the deprecated key", func(t *testing.T) {
+		cfg = &Config{
+			StrictMigration: true,
+			Agents:          map[AgentName]Agent{AgentTask: {MaxTokens: 1000}},
+		}
+		err := migrateOldAgentNames()
+		if err == nil {
+			t.Fatal("migrateOldAgentNames() expected error in strict mode")
+		}
+		if !strings.Contains(err.Error(), "'task'") || !strings.Contains(err.Error(), "'explorer'") {
+			t.Errorf("error = %q, want it to name 'task' and 'explorer'", err)
+		}
+		if _, ok := cfg.Agents[AgentTask]; !ok {
+			t.Error("strict mode should not migrate the agent")
+		}
+	})
+
+	t.Run("strict passes without deprecated names", func(t *testing.T) {
+		cfg = &Config{
+			StrictMigration: true,
+			Agents:          map[AgentName]Agent{AgentExplorer: {}},
+		}
+		if err := migrateOldAgentNames(); err != nil {
+			t.Errorf("migrateOldAgentNames() error = %v", err)
+		}
+	})
+}
+
+func TestExpandHomePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	cfg = &Config{
+		Data:       Data{Directory: "~/.opencode"},
+		Skills:     &SkillsConfig{Paths: []string{"~/.config/skills", "skills"}},
+		Permission: &PermissionConfig{AutoAllowPaths: []string{"~/notes/**"}},
+		LSP: map[string]LSPConfig{
+			"go": {Command: "~/bin/gopls", Args: []string{"-logfile", "~/gopls.log"}},
+		},
+	}
+	defer func() { cfg = nil }()
+
+	expandHomePaths()
+
+	if want := filepath.Join(home, ".opencode"); cfg.Data.Directory != want {
+		t.Errorf("Data.Directory = %q, want %q", cfg.Data.Directory, want)
+	}
+	if want := []string{filepath.Join(home, ".config/skills"), "skills"}; !slices.Equal(cfg.Skills.Paths, want) {
+		t.Errorf("Skills.Paths = %v, want %v", cfg.Skills.Paths, want)
+	}
+	if want := filepath.Join(home, "notes/**"); cfg.Permission.AutoAllowPaths[0] != want {
+		t.Errorf("AutoAllowPaths[0] = %q, want %q", cfg.Permission.AutoAllowPaths[0], want)
+	}
+	lsp := cfg.LSP["go"]
+	if want := filepath.Join(home, "bin/gopls"); lsp.Command != want {
+		t.Errorf("LSP command = %q, want %q", lsp.Command, want)
+	}
+	if want := []string{"-logfile", filepath.Join(home, "gopls.log")}; !slices.Equal(lsp.Args, want) {
+		t.Errorf("LSP args = %v, want %v", lsp.Args, want)
+	}
+}
+
+func TestLoadEnvConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setup := func(t *testing.T, env string) (home, workDir string) {
+		t.Helper()
+		home, workDir = t.TempDir(), t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+		t.Setenv("OPENCODE_ENV", env)
+		cfg = nil
+		viper.Reset()
+		t.Cleanup(func() {
+			cfg = nil
+			viper.Reset()
+		})
+
+		writeConfig(t, filepath.Join(home, ".opencode.json"),
+			`{"tui": {"theme": "dracula"}, "providers": {"openai": {"apiKey": "base-key"}}}`)
+		writeConfig(t, filepath.Join(home, ".opencode.staging.json"),
+			`{"tui": {"theme": "tokyonight"}}`)
+		return home, workDir
+	}
+
+	t.Run("env config overrides base and inherits the rest", func(t *testing.T) {
+		_, workDir := setup(t, "staging")
+		loaded, err := Load(workDir, false)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if loaded.TUI.Theme != "tokyonight" {
+			t.Errorf("TUI.Theme = %q, want %q", loaded.TUI.Theme, "tokyonight")
+		}
+		if got := loaded.Providers[models.ProviderOpenAI].APIKey; got != "base-key" {
+			t.Errorf("openai APIKey = %q, want %q inherited from the base config", got, "base-key")
+		}
+	})
+
+	t.Run("local config overrides env config", func(t *testing.T) {
+		_, workDir := setup(t, "staging")
+		writeConfig(t, filepath.Join(workDir, ".opencode.json"), `{"tui": {"theme": "gruvbox"}}`)
+		loaded, err := Load(workDir, false)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if loaded.TUI.Theme != "gruvbox" {
+			t.Errorf("TUI.Theme = %q, want %q", loaded.TUI.Theme, "gruvbox")
+		}
+	})
+
+	t.Run("unset env only loads the base", func(t *testing.T) {
+		_, workDir := setup(t, "")
+		loaded, err := Load(workDir, false)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if loaded.TUI.Theme != "dracula" {
+			t.Errorf("TUI.Theme = %q, want %q", loaded.TUI.Theme, "dracula")
+		}
+	})
+}
+
+func TestLoadOutputConfig(t *testing.T) {
+	load := func(t *testing.T, config string) (*Config, error) {
+		t.Helper()
+		home, workDir := t.TempDir(), t.TempDir()
+		t.Setenv("HOME", home)
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+		cfg = nil
+		viper.Reset()
+		t.Cleanup(func() {
+			cfg = nil
+			viper.Reset()
+		})
+		schema := `{"type":"object","properties":{"verdict":{"type":"string"}}}`
+		if err := os.WriteFile(filepath.Join(workDir, "verdict.json"), []byte(schema), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(workDir, ".opencode.json"), []byte(config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return Load(workDir, false)
+	}
+
+	t.Run("json_schema default with a schema file ref", func(t *testing.T) {
+		loaded, err := load(t, `{"output": {"format": "json_schema", "schema": {"$ref": "verdict.json"}}}`)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		outputFormat, schema, err := loaded.Output.Resolve(loaded.WorkingDir)
+		if err != nil {
+			t.Fatalf("Resolve() error = %v", err)
+		}
+		if outputFormat != format.JSONSchema {
+			t.Errorf("format = %q, want %q", outputFormat, format.JSONSchema)
+		}
+		if _, ok := schema["properties"].(map[string]any)["verdict"]; !ok {
+			t.Errorf("schema = %v, want the schema loaded from verdict.json", schema)
+		}
+	})
+
+	t.Run("no output config defaults to text", func(t *testing.T) {
+		loaded, err := load(t, `{}`)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if outputFormat, schema, err := loaded.Output.Resolve(loaded.WorkingDir); err != nil || outputFormat != format.Text || schema != nil {
+			t.Errorf("Resolve() = %q, %v, %v, want text without schema", outputFormat, schema, err)
+		}
+	})
+
+	for name, config := range map[string]string{
+		"unknown format":             `{"output": {"format": "yaml"}}`,
+		"missing schema file":        `{"output": {"format": "json_schema", "schema": "missing.json"}}`,
+		"json_schema without schema": `{"output": {"format": "json_schema"}}`,
+		"schema without json_schema": `{"output": {"format": "json", "schema": "verdict.json"}}`,
+	} {
+		t.Run(name+" fails to load", func(t *testing.T) {
+			if _, err := load(t, config); err == nil {
+				t.Error("Load() succeeded, want an invalid output config error")
+			}
+		})
+	}
+}
+
+func TestLoadStrictValidation(t *testing.T) {
+	home, workDir := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	cfg = nil
+	viper.Reset()
+	t.Cleanup(func() {
+		cfg = nil
+		viper.Reset()
+	})
+	config := `{"strictValidation": true, "agents": {"coder": {"model": "no-such-model"}}}`
+	if err := os.WriteFile(filepath.Join(workDir, ".opencode.json"), []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := Load(workDir, false)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Load() error = %v, want *ValidationError", err)
+	}
+	if !slices.Contains(validationErr.Issues, `agent coder: unsupported model "no-such-model"`) {
+		t.Errorf("Issues = %q, want the unsupported coder model", validationErr.Issues)
+	}
+}
+
+func TestSetWorkingDirectory(t *testing.T) {
+	original := t.TempDir()
+	cfg = &Config{WorkingDir: original}
+	defer func() { cfg = nil }()
+
+	var notified []string
+	OnWorkingDirectoryChange(func(dir string) { notified = append(notified, dir) })
+
+	t.Run("valid directory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := SetWorkingDirectory(dir); err != nil {
+			t.Fatalf("SetWorkingDirectory(%q) error = %v", dir, err)
+		}
+		if got := WorkingDirectory(); got != dir {
+			t.Errorf("WorkingDirectory() = %q, want %q", got, dir)
+		}
+		if !slices.Equal(notified, []string{dir}) {
+			t.Errorf("listeners notified with %v, want [%s]", notified, dir)
+		}
+	})
+
+	t.Run("invalid directory", func(t *testing.T) {
+		before := WorkingDirectory()
+		notified = nil
+
+		file := filepath.Join(t.TempDir(), "file.txt")
+		if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		for _, dir := range []string{filepath.Join(before, "missing"), file} {
+			if err := SetWorkingDirectory(dir); err == nil {
+				t.Errorf("SetWorkingDirectory(%q) expected error", dir)
+			}
+		}
+		if got := WorkingDirectory(); got != before {
+			t.Errorf("WorkingDirectory() = %q, want unchanged %q", got, before)
+		}
+		if len(notified) != 0 {
+			t.Errorf("listeners should not run on error, got %v", notified)
+		}
+	})
+
+	t.Run("relative directory is made absolute", func(t *testing.T) {
+		t.Chdir(original)
+		if err := os.Mkdir("sub", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := SetWorkingDirectory("sub"); err != nil {
+			t.Fatalf("SetWorkingDirectory() error = %v", err)
+		}
+		if got, want := WorkingDirectory(), filepath.Join(original, "sub"); got != want {
+			t.Errorf("WorkingDirectory() = %q, want %q", got, want)
+		}
+	})
+}
+
+func TestWorktreeRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	mkdir := func(parts ...string) string {
+		dir := filepath.Join(append([]string{tmpDir}, parts...)...)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	touch := func(dir, name string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("module example\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A git repository with a Go module in a subdirectory
+	gitRepo := mkdir("repo")
+	mkdir("repo", ".git")
+	service := mkdir("repo", "service")
+	touch(service, "go.mod")
+	servicePkg := mkdir("repo", "service", "pkg")
+	repoSrc := mkdir("repo", "src", "pkg")
+
+	// A Go module without git
+	module := mkdir("module")
+	touch(module, "go.mod")
+	moduleSub := mkdir("module", "internal", "app")
+
+	tests := []struct {
+		name       string
+		markers    []string
+		workingDir string
+		want       string
+	}{
+		{name: "git root", workingDir: gitRepo, want: gitRepo},
+		{name: "below git root", workingDir: repoSrc, want: gitRepo},
+		{name: "no marker", workingDir: tmpDir, want: tmpDir},
+		{name: "go.mod is not a default marker", workingDir: moduleSub, want: moduleSub},
+		{name: "go.mod without git", markers: []string{".git", "go.mod"}, workingDir: moduleSub, want: module},
+		{name: "nearest marker wins over git", markers: []string{".git", "go.mod"}, workingDir: servicePkg, want: service},
+		{name: "git only ignores nested go.mod", markers: []string{".git"}, workingDir: servicePkg, want: gitRepo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg = &Config{WorktreeMarkers: tt.markers}
+			defer func() { cfg = nil }()
+			if got := WorktreeRoot(tt.workingDir); got != tt.want {
+				t.Errorf("WorktreeRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLSPInstall(t *testing.T) {
+	tests := []struct {
+		name    string
+		lsp     LSPConfig
+		wantErr string
+	}{
+		{name: "unset"},
+		{name: "allow", lsp: LSPConfig{Download: LSPDownloadAllow}},
+		{name: "deny", lsp: LSPConfig{Download: LSPDownloadDeny}},
+		{name: "unknown download", lsp: LSPConfig{Download: "never"}, wantErr: `invalid lsp.gopls.download: "never"`},
+		{name: "version", lsp: LSPConfig{Version: "0.16.2"}},
+		{name: "v prefixed version", lsp: LSPConfig{Version: "v0.16.2"}},
+		{name: "prerelease version", lsp: LSPConfig{Version: "5.0.0-beta.1"}},
+		{name: "latest is not a version", lsp: LSPConfig{Version: "latest"}, wantErr: `invalid lsp.gopls.version: "latest"`},
+		{name: "range is not a version", lsp: LSPConfig{Version: "^1.2.0"}, wantErr: "must be a release version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLSPInstall("gopls", tt.lsp)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
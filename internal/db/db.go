@@ -72,6 +72,9 @@ func Prepare(ctx context.Context, db DBTX) (*Queries, error) {
 	if q.getSessionByIDStmt, err = db.PrepareContext(ctx, getSessionByID); err != nil {
 		return nil, fmt.Errorf("error preparing query GetSessionByID: %w", err)
 	}
+	if q.listAllFilesStmt, err = db.PrepareContext(ctx, listAllFiles); err != nil {
+		return nil, fmt.Errorf("error preparing query ListAllFiles: %w", err)
+	}
 	if q.listChildSessionsStmt, err = db.PrepareContext(ctx, listChildSessions); err != nil {
 		return nil, fmt.Errorf("error preparing query ListChildSessions: %w", err)
 	}
@@ -199,6 +202,11 @@ func (q *Queries) Close() error {
 			err = fmt.Errorf("error closing getSessionByIDStmt: %w", cerr)
 		}
 	}
+	if q.listAllFilesStmt != nil {
+		if cerr := q.listAllFilesStmt.Close(); cerr != nil {
+			err = fmt.Errorf("error closing listAllFilesStmt: %w", cerr)
+		}
+	}
 	if q.listChildSessionsStmt != nil {
 		if cerr := q.listChildSessionsStmt.Close(); cerr != nil {
 			err = fmt.Errorf("error closing listChildSessionsStmt: %w", cerr)
@@ -324,6 +332,7 @@ type Queries struct {
 	getMaxSeqBySessionStmt            *sql.Stmt
 	getMessageStmt                    *sql.Stmt
 	getSessionByIDStmt                *sql.Stmt
+	listAllFilesStmt                  *sql.Stmt
 	listChildSessionsStmt             *sql.Stmt
 	listFilesByPathStmt               *sql.Stmt
 	listFilesBySessionStmt            *sql.Stmt
@@ -360,6 +369,7 @@ func (q *Queries) WithTx(tx *sql.Tx) *Queries {
 		getMaxSeqBySessionStmt:            q.getMaxSeqBySessionStmt,
 		getMessageStmt:                    q.getMessageStmt,
 		getSessionByIDStmt:                q.getSessionByIDStmt,
+		listAllFilesStmt:                  q.listAllFilesStmt,
 		listChildSessionsStmt:             q.listChildSessionsStmt,
 		listFilesByPathStmt:               q.listFilesByPathStmt,
 		listFilesBySessionStmt:            q.listFilesBySessionStmt,
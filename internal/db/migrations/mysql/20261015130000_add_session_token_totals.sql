@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sessions
+  ADD COLUMN total_prompt_tokens BIGINT NOT NULL DEFAULT 0 AFTER cost,
+  ADD COLUMN total_completion_tokens BIGINT NOT NULL DEFAULT 0 AFTER total_prompt_tokens;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sessions
+  DROP COLUMN total_completion_tokens,
+  DROP COLUMN total_prompt_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE files ADD COLUMN is_new BOOLEAN NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE files DROP COLUMN is_new;
@@ -16,6 +16,7 @@ type File struct {
 	Version   string `json:"version"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
+	IsNew     bool   `json:"is_new"`
 }
 
 type FlowState struct {
@@ -44,16 +45,18 @@ type Message struct {
 }
 
 type Session struct {
-	ID               string         `json:"id"`
-	ParentSessionID  sql.NullString `json:"parent_session_id"`
-	Title            string         `json:"title"`
-	MessageCount     int64          `json:"message_count"`
-	PromptTokens     int64          `json:"prompt_tokens"`
-	CompletionTokens int64          `json:"completion_tokens"`
-	Cost             float64        `json:"cost"`
-	UpdatedAt        int64          `json:"updated_at"`
-	CreatedAt        int64          `json:"created_at"`
-	SummaryMessageID sql.NullString `json:"summary_message_id"`
-	ProjectID        sql.NullString `json:"project_id"`
-	RootSessionID    sql.NullString `json:"root_session_id"`
+	ID                    string         `json:"id"`
+	ParentSessionID       sql.NullString `json:"parent_session_id"`
+	Title                 string         `json:"title"`
+	MessageCount          int64          `json:"message_count"`
+	PromptTokens          int64          `json:"prompt_tokens"`
+	CompletionTokens      int64          `json:"completion_tokens"`
+	Cost                  float64        `json:"cost"`
+	UpdatedAt             int64          `json:"updated_at"`
+	CreatedAt             int64          `json:"created_at"`
+	SummaryMessageID      sql.NullString `json:"summary_message_id"`
+	ProjectID             sql.NullString `json:"project_id"`
+	RootSessionID         sql.NullString `json:"root_session_id"`
+	TotalPromptTokens     int64          `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64          `json:"total_completion_tokens"`
 }
@@ -17,10 +17,11 @@ INSERT INTO files (
     path,
     content,
     version,
+    is_new,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, UNIX_TIMESTAMP(), UNIX_TIMESTAMP()
+    ?, ?, ?, ?, ?, ?, UNIX_TIMESTAMP(), UNIX_TIMESTAMP()
 )
 `
 
@@ -30,6 +31,7 @@ type CreateFileParams struct {
 	Path      string `json:"path"`
 	Content   string `json:"content"`
 	Version   string `json:"version"`
+	IsNew     bool   `json:"is_new"`
 }
 
 func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (sql.Result, error) {
@@ -39,6 +41,7 @@ func (q *Queries) CreateFile(ctx context.Context, arg CreateFileParams) (sql.Res
 		arg.Path,
 		arg.Content,
 		arg.Version,
+		arg.IsNew,
 	)
 }
 
@@ -63,7 +66,7 @@ func (q *Queries) DeleteSessionFiles(ctx context.Context, sessionID string) erro
 }
 
 const getFile = `-- name: GetFile :one
-SELECT id, session_id, path, version, content, created_at, updated_at
+SELECT id, session_id, path, version, content, is_new, created_at, updated_at
 FROM files
 WHERE id = ? LIMIT 1
 `
@@ -77,6 +80,7 @@ func (q *Queries) GetFile(ctx context.Context, id string) (File, error) {
 		&i.Path,
 		&i.Version,
 		&i.Content,
+		&i.IsNew,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -84,7 +88,7 @@ func (q *Queries) GetFile(ctx context.Context, id string) (File, error) {
 }
 
 const getFileByPathAndSession = `-- name: GetFileByPathAndSession :one
-SELECT id, session_id, path, version, content, created_at, updated_at
+SELECT id, session_id, path, version, content, is_new, created_at, updated_at
 FROM files
 WHERE path = ? AND session_id = ?
 ORDER BY created_at DESC
@@ -105,14 +109,53 @@ func (q *Queries) GetFileByPathAndSession(ctx context.Context, arg GetFileByPath
 		&i.Path,
 		&i.Version,
 		&i.Content,
+		&i.IsNew,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const listAllFiles = `-- name: ListAllFiles :many
+SELECT id, session_id, path, version, content, is_new, created_at, updated_at
+FROM files
+ORDER BY path, created_at DESC
+`
+
+func (q *Queries) ListAllFiles(ctx context.Context) ([]File, error) {
+	rows, err := q.db.QueryContext(ctx, listAllFiles)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []File{}
+	for rows.Next() {
+		var i File
+		if err := rows.Scan(
+			&i.ID,
+			&i.SessionID,
+			&i.Path,
+			&i.Version,
+			&i.Content,
+			&i.IsNew,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listFilesByPath = `-- name: ListFilesByPath :many
-SELECT id, session_id, path, version, content, created_at, updated_at
+SELECT id, session_id, path, version, content, is_new, created_at, updated_at
 FROM files
 WHERE path = ?
 ORDER BY created_at DESC
@@ -133,6 +176,7 @@ func (q *Queries) ListFilesByPath(ctx context.Context, path string) ([]File, err
 			&i.Path,
 			&i.Version,
 			&i.Content,
+			&i.IsNew,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -150,7 +194,7 @@ func (q *Queries) ListFilesByPath(ctx context.Context, path string) ([]File, err
 }
 
 const listFilesBySession = `-- name: ListFilesBySession :many
-SELECT id, session_id, path, version, content, created_at, updated_at
+SELECT id, session_id, path, version, content, is_new, created_at, updated_at
 FROM files
 WHERE session_id = ?
 ORDER BY created_at ASC
@@ -171,6 +215,7 @@ func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]F
 			&i.Path,
 			&i.Version,
 			&i.Content,
+			&i.IsNew,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -188,7 +233,7 @@ func (q *Queries) ListFilesBySession(ctx context.Context, sessionID string) ([]F
 }
 
 const listFilesBySessionTree = `-- name: ListFilesBySessionTree :many
-SELECT f.id, f.session_id, f.path, f.version, f.content, f.created_at, f.updated_at
+SELECT f.id, f.session_id, f.path, f.version, f.content, f.is_new, f.created_at, f.updated_at
 FROM files f
 INNER JOIN sessions s ON f.session_id = s.id
 WHERE s.root_session_id = ?
@@ -210,6 +255,7 @@ func (q *Queries) ListFilesBySessionTree(ctx context.Context, rootSessionID sql.
 			&i.Path,
 			&i.Version,
 			&i.Content,
+			&i.IsNew,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -227,7 +273,7 @@ func (q *Queries) ListFilesBySessionTree(ctx context.Context, rootSessionID sql.
 }
 
 const listLatestSessionFiles = `-- name: ListLatestSessionFiles :many
-SELECT f.id, f.session_id, f.path, f.version, f.content, f.created_at, f.updated_at
+SELECT f.id, f.session_id, f.path, f.version, f.content, f.is_new, f.created_at, f.updated_at
 FROM files f
 INNER JOIN (
     SELECT session_id, path, MAX(created_at) as max_created_at
@@ -253,6 +299,7 @@ func (q *Queries) ListLatestSessionFiles(ctx context.Context, sessionID string)
 			&i.Path,
 			&i.Version,
 			&i.Content,
+			&i.IsNew,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -270,7 +317,7 @@ func (q *Queries) ListLatestSessionFiles(ctx context.Context, sessionID string)
 }
 
 const listLatestSessionTreeFiles = `-- name: ListLatestSessionTreeFiles :many
-SELECT f.id, f.session_id, f.path, f.version, f.content, f.created_at, f.updated_at
+SELECT f.id, f.session_id, f.path, f.version, f.content, f.is_new, f.created_at, f.updated_at
 FROM files f
 INNER JOIN sessions s ON f.session_id = s.id
 INNER JOIN (
@@ -298,6 +345,7 @@ func (q *Queries) ListLatestSessionTreeFiles(ctx context.Context, rootSessionID
 			&i.Path,
 			&i.Version,
 			&i.Content,
+			&i.IsNew,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -14,6 +14,7 @@ type File struct {
 	Path      string `json:"path"`
 	Version   string `json:"version"`
 	Content   string `json:"content"`
+	IsNew     bool   `json:"is_new"`
 	CreatedAt int64  `json:"created_at"`
 	UpdatedAt int64  `json:"updated_at"`
 }
@@ -44,16 +45,18 @@ type Message struct {
 }
 
 type Session struct {
-	ID               string         `json:"id"`
-	ParentSessionID  sql.NullString `json:"parent_session_id"`
-	RootSessionID    sql.NullString `json:"root_session_id"`
-	Title            string         `json:"title"`
-	MessageCount     int64          `json:"message_count"`
-	PromptTokens     int64          `json:"prompt_tokens"`
-	CompletionTokens int64          `json:"completion_tokens"`
-	Cost             float64        `json:"cost"`
-	UpdatedAt        int64          `json:"updated_at"`
-	CreatedAt        int64          `json:"created_at"`
-	SummaryMessageID sql.NullString `json:"summary_message_id"`
-	ProjectID        sql.NullString `json:"project_id"`
+	ID                    string         `json:"id"`
+	ParentSessionID       sql.NullString `json:"parent_session_id"`
+	RootSessionID         sql.NullString `json:"root_session_id"`
+	Title                 string         `json:"title"`
+	MessageCount          int64          `json:"message_count"`
+	PromptTokens          int64          `json:"prompt_tokens"`
+	CompletionTokens      int64          `json:"completion_tokens"`
+	Cost                  float64        `json:"cost"`
+	TotalPromptTokens     int64          `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64          `json:"total_completion_tokens"`
+	UpdatedAt             int64          `json:"updated_at"`
+	CreatedAt             int64          `json:"created_at"`
+	SummaryMessageID      sql.NullString `json:"summary_message_id"`
+	ProjectID             sql.NullString `json:"project_id"`
 }
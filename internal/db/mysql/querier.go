@@ -26,6 +26,7 @@ type Querier interface {
 	GetMaxSeqBySession(ctx context.Context, sessionID string) (int64, error)
 	GetMessage(ctx context.Context, id string) (Message, error)
 	GetSessionByID(ctx context.Context, id string) (Session, error)
+	ListAllFiles(ctx context.Context) ([]File, error)
 	ListChildSessions(ctx context.Context, rootSessionID sql.NullString) ([]Session, error)
 	ListFilesByPath(ctx context.Context, path string) ([]File, error)
 	ListFilesBySession(ctx context.Context, sessionID string) ([]File, error)
@@ -77,7 +77,7 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 }
 
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, parent_session_id, root_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id
+SELECT id, parent_session_id, root_session_id, title, message_count, prompt_tokens, completion_tokens, cost, total_prompt_tokens, total_completion_tokens, updated_at, created_at, summary_message_id, project_id
 FROM sessions
 WHERE id = ? LIMIT 1
 `
@@ -94,6 +94,8 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.PromptTokens,
 		&i.CompletionTokens,
 		&i.Cost,
+		&i.TotalPromptTokens,
+		&i.TotalCompletionTokens,
 		&i.UpdatedAt,
 		&i.CreatedAt,
 		&i.SummaryMessageID,
@@ -103,7 +105,7 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 }
 
 const listChildSessions = `-- name: ListChildSessions :many
-SELECT id, parent_session_id, root_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id
+SELECT id, parent_session_id, root_session_id, title, message_count, prompt_tokens, completion_tokens, cost, total_prompt_tokens, total_completion_tokens, updated_at, created_at, summary_message_id, project_id
 FROM sessions
 WHERE root_session_id = ?
 ORDER BY created_at ASC
@@ -127,6 +129,8 @@ func (q *Queries) ListChildSessions(ctx context.Context, rootSessionID sql.NullS
 			&i.PromptTokens,
 			&i.CompletionTokens,
 			&i.Cost,
+			&i.TotalPromptTokens,
+			&i.TotalCompletionTokens,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.SummaryMessageID,
@@ -146,7 +150,7 @@ func (q *Queries) ListChildSessions(ctx context.Context, rootSessionID sql.NullS
 }
 
 const listSessions = `-- name: ListSessions :many
-SELECT id, parent_session_id, root_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id
+SELECT id, parent_session_id, root_session_id, title, message_count, prompt_tokens, completion_tokens, cost, total_prompt_tokens, total_completion_tokens, updated_at, created_at, summary_message_id, project_id
 FROM sessions
 WHERE parent_session_id is NULL AND project_id = ?
 ORDER BY created_at DESC
@@ -170,6 +174,8 @@ func (q *Queries) ListSessions(ctx context.Context, projectID sql.NullString) ([
 			&i.PromptTokens,
 			&i.CompletionTokens,
 			&i.Cost,
+			&i.TotalPromptTokens,
+			&i.TotalCompletionTokens,
 			&i.UpdatedAt,
 			&i.CreatedAt,
 			&i.SummaryMessageID,
@@ -194,18 +200,22 @@ SET
     title = ?,
     prompt_tokens = ?,
     completion_tokens = ?,
+    total_prompt_tokens = ?,
+    total_completion_tokens = ?,
     summary_message_id = ?,
     cost = ?
 WHERE id = ?
 `
 
 type UpdateSessionParams struct {
-	Title            string         `json:"title"`
-	PromptTokens     int64          `json:"prompt_tokens"`
-	CompletionTokens int64          `json:"completion_tokens"`
-	SummaryMessageID sql.NullString `json:"summary_message_id"`
-	Cost             float64        `json:"cost"`
-	ID               string         `json:"id"`
+	Title                 string         `json:"title"`
+	PromptTokens          int64          `json:"prompt_tokens"`
+	CompletionTokens      int64          `json:"completion_tokens"`
+	TotalPromptTokens     int64          `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64          `json:"total_completion_tokens"`
+	SummaryMessageID      sql.NullString `json:"summary_message_id"`
+	Cost                  float64        `json:"cost"`
+	ID                    string         `json:"id"`
 }
 
 func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (sql.Result, error) {
@@ -213,6 +223,8 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (s
 		arg.Title,
 		arg.PromptTokens,
 		arg.CompletionTokens,
+		arg.TotalPromptTokens,
+		arg.TotalCompletionTokens,
 		arg.SummaryMessageID,
 		arg.Cost,
 		arg.ID,
@@ -55,18 +55,20 @@ func (q *MySQLQuerier) CreateSession(ctx context.Context, arg CreateSessionParam
 	}
 
 	return Session{
-		ID:               mysqlSession.ID,
-		ParentSessionID:  mysqlSession.ParentSessionID,
-		RootSessionID:    mysqlSession.RootSessionID,
-		Title:            mysqlSession.Title,
-		MessageCount:     mysqlSession.MessageCount,
-		PromptTokens:     mysqlSession.PromptTokens,
-		CompletionTokens: mysqlSession.CompletionTokens,
-		Cost:             mysqlSession.Cost,
-		UpdatedAt:        mysqlSession.UpdatedAt,
-		CreatedAt:        mysqlSession.CreatedAt,
-		SummaryMessageID: mysqlSession.SummaryMessageID,
-		ProjectID:        mysqlSession.ProjectID,
+		ID:                    mysqlSession.ID,
+		ParentSessionID:       mysqlSession.ParentSessionID,
+		RootSessionID:         mysqlSession.RootSessionID,
+		Title:                 mysqlSession.Title,
+		MessageCount:          mysqlSession.MessageCount,
+		PromptTokens:          mysqlSession.PromptTokens,
+		CompletionTokens:      mysqlSession.CompletionTokens,
+		Cost:                  mysqlSession.Cost,
+		TotalPromptTokens:     mysqlSession.TotalPromptTokens,
+		TotalCompletionTokens: mysqlSession.TotalCompletionTokens,
+		UpdatedAt:             mysqlSession.UpdatedAt,
+		CreatedAt:             mysqlSession.CreatedAt,
+		SummaryMessageID:      mysqlSession.SummaryMessageID,
+		ProjectID:             mysqlSession.ProjectID,
 	}, nil
 }
 
@@ -78,18 +80,20 @@ func (q *MySQLQuerier) GetSessionByID(ctx context.Context, id string) (Session,
 	}
 
 	return Session{
-		ID:               mysqlSession.ID,
-		ParentSessionID:  mysqlSession.ParentSessionID,
-		RootSessionID:    mysqlSession.RootSessionID,
-		Title:            mysqlSession.Title,
-		MessageCount:     mysqlSession.MessageCount,
-		PromptTokens:     mysqlSession.PromptTokens,
-		CompletionTokens: mysqlSession.CompletionTokens,
-		Cost:             mysqlSession.Cost,
-		UpdatedAt:        mysqlSession.UpdatedAt,
-		CreatedAt:        mysqlSession.CreatedAt,
-		SummaryMessageID: mysqlSession.SummaryMessageID,
-		ProjectID:        mysqlSession.ProjectID,
+		ID:                    mysqlSession.ID,
+		ParentSessionID:       mysqlSession.ParentSessionID,
+		RootSessionID:         mysqlSession.RootSessionID,
+		Title:                 mysqlSession.Title,
+		MessageCount:          mysqlSession.MessageCount,
+		PromptTokens:          mysqlSession.PromptTokens,
+		CompletionTokens:      mysqlSession.CompletionTokens,
+		Cost:                  mysqlSession.Cost,
+		TotalPromptTokens:     mysqlSession.TotalPromptTokens,
+		TotalCompletionTokens: mysqlSession.TotalCompletionTokens,
+		UpdatedAt:             mysqlSession.UpdatedAt,
+		CreatedAt:             mysqlSession.CreatedAt,
+		SummaryMessageID:      mysqlSession.SummaryMessageID,
+		ProjectID:             mysqlSession.ProjectID,
 	}, nil
 }
 
@@ -103,18 +107,20 @@ func (q *MySQLQuerier) ListSessions(ctx context.Context, projectID sql.NullStrin
 	sessions := make([]Session, len(mysqlSessions))
 	for i, s := range mysqlSessions {
 		sessions[i] = Session{
-			ID:               s.ID,
-			ParentSessionID:  s.ParentSessionID,
-			RootSessionID:    s.RootSessionID,
-			Title:            s.Title,
-			MessageCount:     s.MessageCount,
-			PromptTokens:     s.PromptTokens,
-			CompletionTokens: s.CompletionTokens,
-			Cost:             s.Cost,
-			UpdatedAt:        s.UpdatedAt,
-			CreatedAt:        s.CreatedAt,
-			SummaryMessageID: s.SummaryMessageID,
-			ProjectID:        s.ProjectID,
+			ID:                    s.ID,
+			ParentSessionID:       s.ParentSessionID,
+			RootSessionID:         s.RootSessionID,
+			Title:                 s.Title,
+			MessageCount:          s.MessageCount,
+			PromptTokens:          s.PromptTokens,
+			CompletionTokens:      s.CompletionTokens,
+			Cost:                  s.Cost,
+			TotalPromptTokens:     s.TotalPromptTokens,
+			TotalCompletionTokens: s.TotalCompletionTokens,
+			UpdatedAt:             s.UpdatedAt,
+			CreatedAt:             s.CreatedAt,
+			SummaryMessageID:      s.SummaryMessageID,
+			ProjectID:             s.ProjectID,
 		}
 	}
 	return sessions, nil
@@ -130,18 +136,20 @@ func (q *MySQLQuerier) ListChildSessions(ctx context.Context, rootSessionID sql.
 	sessions := make([]Session, len(mysqlSessions))
 	for i, s := range mysqlSessions {
 		sessions[i] = Session{
-			ID:               s.ID,
-			ParentSessionID:  s.ParentSessionID,
-			RootSessionID:    s.RootSessionID,
-			Title:            s.Title,
-			MessageCount:     s.MessageCount,
-			PromptTokens:     s.PromptTokens,
-			CompletionTokens: s.CompletionTokens,
-			Cost:             s.Cost,
-			UpdatedAt:        s.UpdatedAt,
-			CreatedAt:        s.CreatedAt,
-			SummaryMessageID: s.SummaryMessageID,
-			ProjectID:        s.ProjectID,
+			ID:                    s.ID,
+			ParentSessionID:       s.ParentSessionID,
+			RootSessionID:         s.RootSessionID,
+			Title:                 s.Title,
+			MessageCount:          s.MessageCount,
+			PromptTokens:          s.PromptTokens,
+			CompletionTokens:      s.CompletionTokens,
+			Cost:                  s.Cost,
+			TotalPromptTokens:     s.TotalPromptTokens,
+			TotalCompletionTokens: s.TotalCompletionTokens,
+			UpdatedAt:             s.UpdatedAt,
+			CreatedAt:             s.CreatedAt,
+			SummaryMessageID:      s.SummaryMessageID,
+			ProjectID:             s.ProjectID,
 		}
 	}
 	return sessions, nil
@@ -150,12 +158,14 @@ func (q *MySQLQuerier) ListChildSessions(ctx context.Context, rootSessionID sql.
 // UpdateSession updates a session and returns it
 func (q *MySQLQuerier) UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error) {
 	_, err := q.queries.UpdateSession(ctx, mysqldb.UpdateSessionParams{
-		Title:            arg.Title,
-		PromptTokens:     arg.PromptTokens,
-		CompletionTokens: arg.CompletionTokens,
-		SummaryMessageID: arg.SummaryMessageID,
-		Cost:             arg.Cost,
-		ID:               arg.ID,
+		Title:                 arg.Title,
+		PromptTokens:          arg.PromptTokens,
+		CompletionTokens:      arg.CompletionTokens,
+		TotalPromptTokens:     arg.TotalPromptTokens,
+		TotalCompletionTokens: arg.TotalCompletionTokens,
+		SummaryMessageID:      arg.SummaryMessageID,
+		Cost:                  arg.Cost,
+		ID:                    arg.ID,
 	})
 	if err != nil {
 		return Session{}, err
@@ -261,6 +271,7 @@ func (q *MySQLQuerier) CreateFile(ctx context.Context, arg CreateFileParams) (Fi
 		Path:      arg.Path,
 		Content:   arg.Content,
 		Version:   arg.Version,
+		IsNew:     arg.IsNew,
 	})
 	if err != nil {
 		return File{}, err
@@ -279,6 +290,7 @@ func (q *MySQLQuerier) CreateFile(ctx context.Context, arg CreateFileParams) (Fi
 		Version:   mysqlFile.Version,
 		CreatedAt: mysqlFile.CreatedAt,
 		UpdatedAt: mysqlFile.UpdatedAt,
+		IsNew:     mysqlFile.IsNew,
 	}, nil
 }
 
@@ -297,6 +309,7 @@ func (q *MySQLQuerier) GetFile(ctx context.Context, id string) (File, error) {
 		Version:   mysqlFile.Version,
 		CreatedAt: mysqlFile.CreatedAt,
 		UpdatedAt: mysqlFile.UpdatedAt,
+		IsNew:     mysqlFile.IsNew,
 	}, nil
 }
 
@@ -318,6 +331,7 @@ func (q *MySQLQuerier) GetFileByPathAndSession(ctx context.Context, arg GetFileB
 		Version:   mysqlFile.Version,
 		CreatedAt: mysqlFile.CreatedAt,
 		UpdatedAt: mysqlFile.UpdatedAt,
+		IsNew:     mysqlFile.IsNew,
 	}, nil
 }
 
@@ -338,6 +352,30 @@ func (q *MySQLQuerier) ListFilesBySession(ctx context.Context, sessionID string)
 			Version:   f.Version,
 			CreatedAt: f.CreatedAt,
 			UpdatedAt: f.UpdatedAt,
+			IsNew:     f.IsNew,
+		}
+	}
+	return files, nil
+}
+
+// ListAllFiles lists every file version
+func (q *MySQLQuerier) ListAllFiles(ctx context.Context) ([]File, error) {
+	mysqlFiles, err := q.queries.ListAllFiles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]File, len(mysqlFiles))
+	for i, f := range mysqlFiles {
+		files[i] = File{
+			ID:        f.ID,
+			SessionID: f.SessionID,
+			Path:      f.Path,
+			Content:   f.Content,
+			Version:   f.Version,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			IsNew:     f.IsNew,
 		}
 	}
 	return files, nil
@@ -360,6 +398,7 @@ func (q *MySQLQuerier) ListFilesByPath(ctx context.Context, path string) ([]File
 			Version:   f.Version,
 			CreatedAt: f.CreatedAt,
 			UpdatedAt: f.UpdatedAt,
+			IsNew:     f.IsNew,
 		}
 	}
 	return files, nil
@@ -382,6 +421,7 @@ func (q *MySQLQuerier) ListFilesBySessionTree(ctx context.Context, rootSessionID
 			Version:   f.Version,
 			CreatedAt: f.CreatedAt,
 			UpdatedAt: f.UpdatedAt,
+			IsNew:     f.IsNew,
 		}
 	}
 	return files, nil
@@ -428,6 +468,7 @@ func (q *MySQLQuerier) ListLatestSessionFiles(ctx context.Context, sessionID str
 			Version:   f.Version,
 			CreatedAt: f.CreatedAt,
 			UpdatedAt: f.UpdatedAt,
+			IsNew:     f.IsNew,
 		}
 	}
 	return files, nil
@@ -450,6 +491,7 @@ func (q *MySQLQuerier) ListLatestSessionTreeFiles(ctx context.Context, rootSessi
 			Version:   f.Version,
 			CreatedAt: f.CreatedAt,
 			UpdatedAt: f.UpdatedAt,
+			IsNew:     f.IsNew,
 		}
 	}
 	return files, nil
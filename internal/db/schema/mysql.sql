@@ -7,6 +7,8 @@ CREATE TABLE IF NOT EXISTS sessions (
   prompt_tokens BIGINT NOT NULL DEFAULT 0,
   completion_tokens BIGINT NOT NULL DEFAULT 0,
   cost DOUBLE NOT NULL DEFAULT 0.0,
+  total_prompt_tokens BIGINT NOT NULL DEFAULT 0,
+  total_completion_tokens BIGINT NOT NULL DEFAULT 0,
   updated_at BIGINT NOT NULL,
   created_at BIGINT NOT NULL,
   summary_message_id VARCHAR(255),
@@ -22,6 +24,7 @@ CREATE TABLE IF NOT EXISTS files (
   path VARCHAR(1024) NOT NULL,
   version VARCHAR(255) NOT NULL,
   content LONGTEXT NOT NULL,
+  is_new BOOLEAN NOT NULL DEFAULT FALSE,
   created_at BIGINT NOT NULL,
   updated_at BIGINT NOT NULL,
   UNIQUE KEY idx_path_version (path(255), session_id, version),
@@ -37,7 +37,7 @@ INSERT INTO sessions (
     null,
     unixepoch('now'),
     unixepoch('now')
-) RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id
+) RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id, total_prompt_tokens, total_completion_tokens
 `
 
 type CreateSessionParams struct {
@@ -78,6 +78,8 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 		&i.SummaryMessageID,
 		&i.ProjectID,
 		&i.RootSessionID,
+		&i.TotalPromptTokens,
+		&i.TotalCompletionTokens,
 	)
 	return i, err
 }
@@ -93,7 +95,7 @@ func (q *Queries) DeleteSession(ctx context.Context, id string) error {
 }
 
 const getSessionByID = `-- name: GetSessionByID :one
-SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id
+SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id, total_prompt_tokens, total_completion_tokens
 FROM sessions
 WHERE id = ? LIMIT 1
 `
@@ -114,12 +116,14 @@ func (q *Queries) GetSessionByID(ctx context.Context, id string) (Session, error
 		&i.SummaryMessageID,
 		&i.ProjectID,
 		&i.RootSessionID,
+		&i.TotalPromptTokens,
+		&i.TotalCompletionTokens,
 	)
 	return i, err
 }
 
 const listChildSessions = `-- name: ListChildSessions :many
-SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id
+SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id, total_prompt_tokens, total_completion_tokens
 FROM sessions
 WHERE root_session_id = ?
 ORDER BY created_at ASC
@@ -147,6 +151,8 @@ func (q *Queries) ListChildSessions(ctx context.Context, rootSessionID sql.NullS
 			&i.SummaryMessageID,
 			&i.ProjectID,
 			&i.RootSessionID,
+			&i.TotalPromptTokens,
+			&i.TotalCompletionTokens,
 		); err != nil {
 			return nil, err
 		}
@@ -162,7 +168,7 @@ func (q *Queries) ListChildSessions(ctx context.Context, rootSessionID sql.NullS
 }
 
 const listSessions = `-- name: ListSessions :many
-SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id
+SELECT id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id, total_prompt_tokens, total_completion_tokens
 FROM sessions
 WHERE parent_session_id is NULL AND project_id = ?
 ORDER BY created_at DESC
@@ -190,6 +196,8 @@ func (q *Queries) ListSessions(ctx context.Context, projectID sql.NullString) ([
 			&i.SummaryMessageID,
 			&i.ProjectID,
 			&i.RootSessionID,
+			&i.TotalPromptTokens,
+			&i.TotalCompletionTokens,
 		); err != nil {
 			return nil, err
 		}
@@ -210,19 +218,23 @@ SET
     title = ?,
     prompt_tokens = ?,
     completion_tokens = ?,
+    total_prompt_tokens = ?,
+    total_completion_tokens = ?,
     summary_message_id = ?,
     cost = ?
 WHERE id = ?
-RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id
+RETURNING id, parent_session_id, title, message_count, prompt_tokens, completion_tokens, cost, updated_at, created_at, summary_message_id, project_id, root_session_id, total_prompt_tokens, total_completion_tokens
 `
 
 type UpdateSessionParams struct {
-	Title            string         `json:"title"`
-	PromptTokens     int64          `json:"prompt_tokens"`
-	CompletionTokens int64          `json:"completion_tokens"`
-	SummaryMessageID sql.NullString `json:"summary_message_id"`
-	Cost             float64        `json:"cost"`
-	ID               string         `json:"id"`
+	Title                 string         `json:"title"`
+	PromptTokens          int64          `json:"prompt_tokens"`
+	CompletionTokens      int64          `json:"completion_tokens"`
+	TotalPromptTokens     int64          `json:"total_prompt_tokens"`
+	TotalCompletionTokens int64          `json:"total_completion_tokens"`
+	SummaryMessageID      sql.NullString `json:"summary_message_id"`
+	Cost                  float64        `json:"cost"`
+	ID                    string         `json:"id"`
 }
 
 func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (Session, error) {
@@ -230,6 +242,8 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		arg.Title,
 		arg.PromptTokens,
 		arg.CompletionTokens,
+		arg.TotalPromptTokens,
+		arg.TotalCompletionTokens,
 		arg.SummaryMessageID,
 		arg.Cost,
 		arg.ID,
@@ -248,6 +262,8 @@ func (q *Queries) UpdateSession(ctx context.Context, arg UpdateSessionParams) (S
 		&i.SummaryMessageID,
 		&i.ProjectID,
 		&i.RootSessionID,
+		&i.TotalPromptTokens,
+		&i.TotalCompletionTokens,
 	)
 	return i, err
 }
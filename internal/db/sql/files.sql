@@ -16,6 +16,11 @@ FROM files
 WHERE session_id = ?
 ORDER BY created_at ASC;
 
+-- name: ListAllFiles :many
+SELECT *
+FROM files
+ORDER BY path, created_at DESC;
+
 -- name: ListFilesByPath :many
 SELECT *
 FROM files
@@ -29,10 +34,11 @@ INSERT INTO files (
     path,
     content,
     version,
+    is_new,
     created_at,
     updated_at
 ) VALUES (
-    ?, ?, ?, ?, ?, unixepoch('now'), unixepoch('now')
+    ?, ?, ?, ?, ?, ?, unixepoch('now'), unixepoch('now')
 )
 RETURNING *;
 
@@ -9,8 +9,11 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/tui/theme"
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
@@ -50,6 +53,7 @@ type DiffLine struct {
 	Kind      LineType  // Type of line (added, removed, context)
 	Content   string    // Content of the line
 	Segments  []Segment // Segments for intraline highlighting
+	NoNewline bool      // Line is the last in its file and has no trailing newline
 }
 
 // Hunk represents a section of changes in a diff
@@ -60,9 +64,10 @@ type Hunk struct {
 
 // DiffResult contains the parsed result of a diff
 type DiffResult struct {
-	OldFile string
-	NewFile string
-	Hunks   []Hunk
+	OldFile  string
+	NewFile  string
+	Language string // Language of the file, derived from its extension ("" if unknown)
+	Hunks    []Hunk
 }
 
 // linePair represents a pair of lines for side-by-side display
@@ -77,12 +82,32 @@ type linePair struct {
 
 // ParseConfig configures the behavior of diff parsing
 type ParseConfig struct {
-	ContextSize int // Number of context lines to include
+	ContextSize         int  // Number of context lines to include
+	MaxLineForIntraline int  // Lines longer than this (in bytes) skip intraline highlighting; 0 disables the limit
+	TruncateLongLines   bool // Truncate lines longer than MaxLineForIntraline for display
 }
 
 // ParseOption modifies a ParseConfig
 type ParseOption func(*ParseConfig)
 
+// defaultMaxLineForIntraline keeps character-level diffing away from
+// minified files, where it is slow and produces unreadable output.
+const defaultMaxLineForIntraline = 1000
+
+// NewParseConfig creates a ParseConfig with default values
+func NewParseConfig(opts ...ParseOption) ParseConfig {
+	config := ParseConfig{
+		ContextSize:         3,
+		MaxLineForIntraline: defaultMaxLineForIntraline,
+	}
+
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	return config
+}
+
 // WithContextSize sets the number of context lines to include
 func WithContextSize(size int) ParseOption {
 	return func(p *ParseConfig) {
@@ -92,6 +117,24 @@ func WithContextSize(size int) ParseOption {
 	}
 }
 
+// WithMaxLineForIntraline sets the longest line, in bytes, that still gets
+// intraline highlighting. Zero disables the limit.
+func WithMaxLineForIntraline(size int) ParseOption {
+	return func(p *ParseConfig) {
+		if size >= 0 {
+			p.MaxLineForIntraline = size
+		}
+	}
+}
+
+// WithTruncateLongLines truncates lines longer than MaxLineForIntraline for
+// display, appending a marker with the number of bytes omitted.
+func WithTruncateLongLines(truncate bool) ParseOption {
+	return func(p *ParseConfig) {
+		p.TruncateLongLines = truncate
+	}
+}
+
 // -------------------------------------------------------------------------
 // Side-by-Side Configuration
 // -------------------------------------------------------------------------
@@ -130,13 +173,19 @@ func WithTotalWidth(width int) SideBySideOption {
 // Diff Parsing
 // -------------------------------------------------------------------------
 
+const devNull = "/dev/null"
+
+// hunkHeaderRe matches a hunk header, capturing the old and new ranges and
+// the optional section heading that follows.
+var hunkHeaderRe = regexp.MustCompile(`^@@ -(\d+),?(\d*) \+(\d+),?(\d*) @@(.*)$`)
+
 // ParseUnifiedDiff parses a unified diff format string into structured data
 func ParseUnifiedDiff(diff string) (DiffResult, error) {
 	var result DiffResult
 	var currentHunk *Hunk
 
-	hunkHeaderRe := regexp.MustCompile(`^@@ -(\d+),?(\d*) \+(\d+),?(\d*) @@`)
-	lines := strings.Split(diff, "\n")
+	// A trailing newline ends the last line rather than starting an empty one
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
 
 	var oldLine, newLine int
 	inFileHeader := true
@@ -148,11 +197,20 @@ func ParseUnifiedDiff(diff string) (DiffResult, error) {
 				result.OldFile = strings.TrimPrefix(line, "--- a/")
 				continue
 			}
+			if line == "--- "+devNull {
+				result.OldFile = devNull
+				continue
+			}
 			if strings.HasPrefix(line, "+++ b/") {
 				result.NewFile = strings.TrimPrefix(line, "+++ b/")
 				inFileHeader = false
 				continue
 			}
+			if line == "+++ "+devNull {
+				result.NewFile = devNull
+				inFileHeader = false
+				continue
+			}
 		}
 
 		// Parse hunk headers
@@ -172,8 +230,11 @@ func ParseUnifiedDiff(diff string) (DiffResult, error) {
 			continue
 		}
 
-		// Ignore "No newline at end of file" markers
-		if strings.HasPrefix(line, "\\ No newline at end of file") {
+		// "No newline at end of file" applies to the preceding line
+		if strings.HasPrefix(line, `\ No newline at end of file`) {
+			if currentHunk != nil && len(currentHunk.Lines) > 0 {
+				currentHunk.Lines[len(currentHunk.Lines)-1].NoNewline = true
+			}
 			continue
 		}
 
@@ -205,7 +266,7 @@ func ParseUnifiedDiff(diff string) (DiffResult, error) {
 					OldLineNo: oldLine,
 					NewLineNo: newLine,
 					Kind:      LineContext,
-					Content:   line,
+					Content:   strings.TrimPrefix(line, " "),
 				})
 				oldLine++
 				newLine++
@@ -228,11 +289,116 @@ func ParseUnifiedDiff(diff string) (DiffResult, error) {
 		result.Hunks = append(result.Hunks, *currentHunk)
 	}
 
+	result.Language = DetectLanguage(result)
 	return result, nil
 }
 
-// HighlightIntralineChanges updates lines in a hunk to show character-level differences
-func HighlightIntralineChanges(h *Hunk) {
+// RenderUnified serializes result as a unified diff that git apply accepts,
+// the inverse of ParseUnifiedDiff. File names get a/ and b/ prefixes, hunk
+// ranges are recomputed from the hunk lines and lines without a trailing
+// newline are followed by a "\ No newline at end of file" marker.
+func RenderUnified(result DiffResult) string {
+	if len(result.Hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", diffFileName("a/", result.OldFile), diffFileName("b/", result.NewFile))
+	for _, hunk := range result.Hunks {
+		oldStart, newStart, section := hunkStarts(hunk)
+		var oldCount, newCount int
+		for _, line := range hunk.Lines {
+			if line.Kind != LineAdded {
+				oldCount++
+			}
+			if line.Kind != LineRemoved {
+				newCount++
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%s +%s @@%s\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount), section)
+
+		for _, line := range hunk.Lines {
+			switch line.Kind {
+			case LineAdded:
+				b.WriteByte('+')
+			case LineRemoved:
+				b.WriteByte('-')
+			default:
+				b.WriteByte(' ')
+			}
+			b.WriteString(line.Content)
+			b.WriteByte('\n')
+			if line.NoNewline {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+func diffFileName(prefix, name string) string {
+	if name == "" || name == devNull {
+		return devNull
+	}
+	return prefix + name
+}
+
+// hunkStarts returns the start lines of a hunk, taken from its header when it
+// has one and otherwise from the line numbers of its lines.
+func hunkStarts(hunk Hunk) (oldStart, newStart int, section string) {
+	if m := hunkHeaderRe.FindStringSubmatch(hunk.Header); m != nil {
+		oldStart, _ = strconv.Atoi(m[1])
+		newStart, _ = strconv.Atoi(m[3])
+		return oldStart, newStart, m[5]
+	}
+	for _, line := range hunk.Lines {
+		if oldStart == 0 && line.OldLineNo > 0 {
+			oldStart = line.OldLineNo
+		}
+		if newStart == 0 && line.NewLineNo > 0 {
+			newStart = line.NewLineNo
+		}
+	}
+	return oldStart, newStart, ""
+}
+
+// hunkRange formats a hunk range, omitting the count when it is 1 as diff
+// and git do.
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// DetectLanguage infers the language of the diffed file from the extension of
+// NewFile, falling back to OldFile for deletions. It returns "" when the
+// extension is unknown.
+func DetectLanguage(result DiffResult) string {
+	name := result.NewFile
+	if name == "" || name == "/dev/null" {
+		name = result.OldFile
+	}
+	if name == "" {
+		return ""
+	}
+
+	// JSX/TSX are tokenized like their base language
+	switch lang := lsp.DetectLanguageID(name); lang {
+	case protocol.LangTypeScriptReact:
+		return string(protocol.LangTypeScript)
+	case protocol.LangJavaScriptReact:
+		return string(protocol.LangJavaScript)
+	default:
+		return string(lang)
+	}
+}
+
+// HighlightIntralineChanges updates lines in a hunk to show character-level
+// differences. Pairs where either line is longer than MaxLineForIntraline are
+// left as whole-line changes.
+func HighlightIntralineChanges(h *Hunk, opts ...ParseOption) {
+	config := NewParseConfig(opts...)
 	var updated []DiffLine
 	dmp := diffmatchpatch.New()
 
@@ -240,7 +406,9 @@ func HighlightIntralineChanges(h *Hunk) {
 		// Look for removed line followed by added line
 		if i+1 < len(h.Lines) &&
 			h.Lines[i].Kind == LineRemoved &&
-			h.Lines[i+1].Kind == LineAdded {
+			h.Lines[i+1].Kind == LineAdded &&
+			!config.isLongLine(h.Lines[i].Content) &&
+			!config.isLongLine(h.Lines[i+1].Content) {
 			oldLine := h.Lines[i]
 			newLine := h.Lines[i+1]
 
@@ -284,13 +452,32 @@ func HighlightIntralineChanges(h *Hunk) {
 			updated = append(updated, oldLine, newLine)
 			i++ // Skip the next line as we've already processed it
 		} else {
-			updated = append(updated, h.Lines[i])
+			line := h.Lines[i]
+			if config.TruncateLongLines && config.isLongLine(line.Content) {
+				line.Content = truncateLine(line.Content, config.MaxLineForIntraline)
+			}
+			updated = append(updated, line)
 		}
 	}
 
 	h.Lines = updated
 }
 
+// isLongLine reports whether content exceeds MaxLineForIntraline.
+func (p ParseConfig) isLongLine(content string) bool {
+	return p.MaxLineForIntraline > 0 && len(content) > p.MaxLineForIntraline
+}
+
+// truncateLine cuts content to at most limit bytes on a rune boundary and
+// appends a marker with the number of bytes omitted.
+func truncateLine(content string, limit int) string {
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s … [%d bytes truncated]", content[:cut], len(content)-cut)
+}
+
 // pairLines converts a flat list of diff lines to pairs for side-by-side display
 func pairLines(lines []DiffLine) []linePair {
 	var pairs []linePair
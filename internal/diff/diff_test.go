@@ -1,6 +1,10 @@
 package diff
 
 import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -370,6 +374,166 @@ func TestParseUnifiedDiff(t *testing.T) {
 	}
 }
 
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		result DiffResult
+		want   string
+	}{
+		{name: "go", result: DiffResult{OldFile: "main.go", NewFile: "main.go"}, want: "go"},
+		{name: "tsx", result: DiffResult{NewFile: "web/App.tsx"}, want: "typescript"},
+		{name: "jsx", result: DiffResult{NewFile: "App.jsx"}, want: "javascript"},
+		{name: "deleted file falls back to old name", result: DiffResult{OldFile: "script.py", NewFile: "/dev/null"}, want: "python"},
+		{name: "unknown extension", result: DiffResult{NewFile: "data.unknownext"}, want: ""},
+		{name: "no file names", result: DiffResult{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectLanguage(tt.result); got != tt.want {
+				t.Errorf("DetectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseUnifiedDiff_Language(t *testing.T) {
+	result, err := ParseUnifiedDiff("--- a/cmd/main.go\n+++ b/cmd/main.go\n@@ -1 +1 @@\n-old\n+new")
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+	if result.Language != "go" {
+		t.Errorf("Language = %q, want %q", result.Language, "go")
+	}
+}
+
+func TestParseUnifiedDiff_NoNewlineMarker(t *testing.T) {
+	result, err := ParseUnifiedDiff("--- a/f.txt\n+++ b/f.txt\n@@ -1,2 +1,2 @@\n keep\n-old\n\\ No newline at end of file\n+new\n\\ No newline at end of file\n")
+	if err != nil {
+		t.Fatalf("ParseUnifiedDiff() error = %v", err)
+	}
+	lines := result.Hunks[0].Lines
+	if len(lines) != 3 {
+		t.Fatalf("Lines length = %d, want 3", len(lines))
+	}
+	if lines[0].Content != "keep" || lines[0].NoNewline {
+		t.Errorf("context line = %+v, want content %q with newline", lines[0], "keep")
+	}
+	if !lines[1].NoNewline || !lines[2].NoNewline {
+		t.Errorf("removed and added lines should be marked NoNewline: %+v", lines)
+	}
+}
+
+func TestRenderUnified(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+	}{
+		{
+			name: "multiple hunks with section heading",
+			diff: `--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@ package main
+ line1
+-removed
++added
++another
+ line3
+@@ -10,2 +11,2 @@
+ ctx
+-old
++new
+`,
+		},
+		{
+			name: "no newline at end of file",
+			diff: `--- a/file.txt
++++ b/file.txt
+@@ -1,2 +1,2 @@
+ line1
+-last
+\ No newline at end of file
++last
+`,
+		},
+		{
+			name: "new file",
+			diff: `--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++hello
++world
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseUnifiedDiff(tt.diff)
+			if err != nil {
+				t.Fatalf("ParseUnifiedDiff() error = %v", err)
+			}
+			if got := RenderUnified(result); got != tt.diff {
+				t.Errorf("RenderUnified() =\n%s\nwant\n%s", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestRenderUnified_RecomputesRanges(t *testing.T) {
+	result := DiffResult{
+		OldFile: "a.txt",
+		NewFile: "a.txt",
+		Hunks: []Hunk{{Lines: []DiffLine{
+			{OldLineNo: 3, NewLineNo: 3, Kind: LineContext, Content: "ctx"},
+			{OldLineNo: 4, Kind: LineRemoved, Content: "old"},
+			{NewLineNo: 4, Kind: LineAdded, Content: "new"},
+			{NewLineNo: 5, Kind: LineAdded, Content: "more"},
+		}}},
+	}
+	want := "--- a/a.txt\n+++ b/a.txt\n@@ -3,2 +3,3 @@\n ctx\n-old\n+new\n+more\n"
+	if got := RenderUnified(result); got != want {
+		t.Errorf("RenderUnified() = %q, want %q", got, want)
+	}
+	if got := RenderUnified(DiffResult{}); got != "" {
+		t.Errorf("RenderUnified(empty) = %q, want empty", got)
+	}
+}
+
+func TestRenderUnified_GitApply(t *testing.T) {
+	git, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("one\ntwo\nthree"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Parsing and rendering a diff must produce a patch git can apply
+	result, err := ParseUnifiedDiff("--- a/file.txt\n+++ b/file.txt\n@@ -1,3 +1,3 @@\n one\n two\n-three\n\\ No newline at end of file\n+THREE\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patch := filepath.Join(dir, "change.patch")
+	if err := os.WriteFile(patch, []byte(RenderUnified(result)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(git, "apply", "change.patch")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git apply failed: %v\n%s", err, out)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "file.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "one\ntwo\nTHREE\n" {
+		t.Errorf("patched file = %q, want %q", got, "one\ntwo\nTHREE\n")
+	}
+}
+
 func TestParseUnifiedDiff_LineNumbers(t *testing.T) {
 	diff := `--- a/file.txt
 +++ b/file.txt
@@ -463,6 +627,62 @@ func TestHighlightIntralineChanges_NoChanges(t *testing.T) {
 	}
 }
 
+func TestHighlightIntralineChanges_LongLine(t *testing.T) {
+	oldLine := strings.Repeat("a", 50000)
+	newLine := strings.Repeat("a", 25000) + "b" + strings.Repeat("a", 24999)
+
+	t.Run("skips intraline segments", func(t *testing.T) {
+		hunk := &Hunk{
+			Lines: []DiffLine{
+				{OldLineNo: 1, Kind: LineRemoved, Content: oldLine},
+				{NewLineNo: 1, Kind: LineAdded, Content: newLine},
+			},
+		}
+
+		HighlightIntralineChanges(hunk)
+
+		for i, line := range hunk.Lines {
+			if len(line.Segments) != 0 {
+				t.Errorf("Line %d has %d segments, want none for a long line", i, len(line.Segments))
+			}
+		}
+		if hunk.Lines[0].Content != oldLine || hunk.Lines[1].Content != newLine {
+			t.Errorf("Long lines should be kept whole without truncation")
+		}
+	})
+
+	t.Run("truncates for display", func(t *testing.T) {
+		hunk := &Hunk{
+			Lines: []DiffLine{
+				{OldLineNo: 1, Kind: LineRemoved, Content: oldLine},
+				{NewLineNo: 1, Kind: LineAdded, Content: newLine},
+				{OldLineNo: 2, NewLineNo: 2, Kind: LineContext, Content: "short"},
+			},
+		}
+
+		HighlightIntralineChanges(hunk, WithMaxLineForIntraline(100), WithTruncateLongLines(true))
+
+		want := strings.Repeat("a", 100) + " … [49900 bytes truncated]"
+		for i, line := range hunk.Lines[:2] {
+			if len(line.Segments) != 0 {
+				t.Errorf("Line %d has %d segments, want none", i, len(line.Segments))
+			}
+			if line.Content != want {
+				t.Errorf("Line %d content = %.120q, want %q", i, line.Content, want)
+			}
+		}
+		if hunk.Lines[2].Content != "short" {
+			t.Errorf("Short line content = %q, want %q", hunk.Lines[2].Content, "short")
+		}
+	})
+
+	t.Run("truncation keeps runes whole", func(t *testing.T) {
+		if got := truncateLine(strings.Repeat("é", 10), 5); got != "éé … [16 bytes truncated]" {
+			t.Errorf("truncateLine = %q", got)
+		}
+	})
+}
+
 // TestParseConfigOptions tests ParseConfig option functions
 func TestParseConfigOptions(t *testing.T) {
 	tests := []struct {
@@ -479,6 +699,27 @@ func TestParseConfigOptions(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "NewParseConfig",
+			fn: func(t *testing.T) {
+				cfg := NewParseConfig()
+				if cfg.MaxLineForIntraline != defaultMaxLineForIntraline {
+					t.Errorf("MaxLineForIntraline = %d, want %d", cfg.MaxLineForIntraline, defaultMaxLineForIntraline)
+				}
+				if cfg.TruncateLongLines {
+					t.Errorf("TruncateLongLines should default to false")
+				}
+			},
+		},
+		{
+			name: "WithMaxLineForIntraline",
+			fn: func(t *testing.T) {
+				cfg := NewParseConfig(WithMaxLineForIntraline(0))
+				if cfg.MaxLineForIntraline != 0 {
+					t.Errorf("MaxLineForIntraline = %d, want 0", cfg.MaxLineForIntraline)
+				}
+			},
+		},
 		{
 			name: "WithContextSize_negativeIgnored",
 			fn: func(t *testing.T) {
@@ -79,6 +79,54 @@ func contextError(index int, context string, isEOF bool) DiffError {
 	return NewDiffError(fmt.Sprintf("%s %d:\n%s", prefix, index, context))
 }
 
+// Hunk statuses reported by DryRunPatch.
+const (
+	HunkApplied = "applied"
+	HunkFailed  = "failed"
+)
+
+// Reasons a hunk fails in DryRunPatch.
+const (
+	HunkContextNotFound  = "context not found"
+	HunkContextAmbiguous = "context is ambiguous"
+)
+
+// HunkReport is the outcome of one @@ section of an update in a dry run.
+type HunkReport struct {
+	Hunk   int    `json:"hunk"`             // 1-based position of the hunk in its file section
+	Status string `json:"status"`           // HunkApplied or HunkFailed
+	Line   int    `json:"line,omitempty"`   // 1-based line the hunk's context matched at
+	Fuzz   int    `json:"fuzz,omitempty"`   // fuzz needed to match the context
+	Reason string `json:"reason,omitempty"` // why the hunk failed
+}
+
+// FileReport is the outcome of one file section of a patch in a dry run.
+type FileReport struct {
+	Path     string       `json:"path"`
+	Action   ActionType   `json:"action"`
+	MovePath string       `json:"move_path,omitempty"`
+	Hunks    []HunkReport `json:"hunks,omitempty"`
+}
+
+// PatchReport is the result of DryRunPatch, with files in patch order.
+type PatchReport struct {
+	Files []FileReport
+	Fuzz  int
+}
+
+// Failed returns the number of hunks that would not apply.
+func (r PatchReport) Failed() int {
+	failed := 0
+	for _, file := range r.Files {
+		for _, hunk := range file.Hunks {
+			if hunk.Status == HunkFailed {
+				failed++
+			}
+		}
+	}
+	return failed
+}
+
 // Parser parses patch text into structured Patch data.
 type Parser struct {
 	currentFiles map[string]string
@@ -86,6 +134,12 @@ type Parser struct {
 	index        int
 	patch        Patch
 	fuzz         int
+
+	// dryRun records the outcome of every hunk in hunks instead of stopping
+	// at the first one whose context cannot be located.
+	dryRun  bool
+	hunks   []HunkReport
+	reports []FileReport
 }
 
 // NewParser creates a new Parser with the given current files and patch text lines.
@@ -167,6 +221,7 @@ func (p *Parser) Parse() error {
 				action.MovePath = &moveTo
 			}
 			p.patch.Actions[path] = action
+			p.report(FileReport{Path: path, Action: ActionUpdate, MovePath: moveTo, Hunks: p.hunks})
 			continue
 		}
 
@@ -179,6 +234,7 @@ func (p *Parser) Parse() error {
 				return fileError("Delete", "Missing File", path)
 			}
 			p.patch.Actions[path] = PatchAction{Type: ActionDelete, Chunks: []Chunk{}}
+			p.report(FileReport{Path: path, Action: ActionDelete})
 			continue
 		}
 
@@ -195,6 +251,7 @@ func (p *Parser) Parse() error {
 				return err
 			}
 			p.patch.Actions[path] = action
+			p.report(FileReport{Path: path, Action: ActionAdd})
 			continue
 		}
 
@@ -209,10 +266,18 @@ func (p *Parser) Parse() error {
 	return nil
 }
 
+// report records the outcome of a file section in a dry run.
+func (p *Parser) report(file FileReport) {
+	if p.dryRun {
+		p.reports = append(p.reports, file)
+	}
+}
+
 func (p *Parser) parseUpdateFile(text string) (PatchAction, error) {
 	action := PatchAction{Type: ActionUpdate, Chunks: []Chunk{}}
 	fileLines := strings.Split(text, "\n")
 	index := 0
+	p.hunks = nil
 
 	endPrefixes := []string{
 		"*** End Patch",
@@ -223,6 +288,7 @@ func (p *Parser) parseUpdateFile(text string) (PatchAction, error) {
 	}
 
 	for !p.isDone(endPrefixes) {
+		fuzzBefore := p.fuzz
 		defStr := p.readStr("@@ ", false)
 		sectionStr := ""
 		if defStr == "" && p.index < len(p.lines) && p.lines[p.index] == "@@" {
@@ -274,6 +340,24 @@ func (p *Parser) parseUpdateFile(text string) (PatchAction, error) {
 
 		nextChunkContext, chunks, endPatchIndex, eof := peekNextSection(p.lines, p.index)
 		newIndex, fuzz := findContext(fileLines, nextChunkContext, index, eof)
+		if p.dryRun {
+			hunk := HunkReport{Hunk: len(p.hunks) + 1, Status: HunkFailed}
+			switch {
+			case newIndex == -1:
+				hunk.Reason = HunkContextNotFound
+			case !eof && ambiguousContext(fileLines, nextChunkContext, newIndex, fuzz):
+				hunk.Reason = HunkContextAmbiguous
+			default:
+				hunk.Status = HunkApplied
+				hunk.Line = newIndex + 1
+				hunk.Fuzz = p.fuzz - fuzzBefore + fuzz
+			}
+			p.hunks = append(p.hunks, hunk)
+			if hunk.Status == HunkFailed {
+				p.index = endPatchIndex
+				continue
+			}
+		}
 		if newIndex == -1 {
 			ctxText := strings.Join(nextChunkContext, "\n")
 			return action, contextError(index, ctxText, eof)
@@ -339,26 +423,26 @@ func normalizeUnicode(s string) string {
 	return b.String()
 }
 
+// contextMatchPasses are the comparisons tried, from strictest to loosest,
+// when locating the context of a hunk, with the fuzz each one adds.
+var contextMatchPasses = []struct {
+	compare func(string, string) bool
+	fuzz    int
+}{
+	{func(a, b string) bool { return a == b }, 0},
+	{func(a, b string) bool { return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t") }, 1},
+	{func(a, b string) bool { return strings.TrimSpace(a) == strings.TrimSpace(b) }, 100},
+	{func(a, b string) bool {
+		return normalizeUnicode(strings.TrimSpace(a)) == normalizeUnicode(strings.TrimSpace(b))
+	}, 1000},
+}
+
 func findContextCore(lines []string, context []string, start int) (int, int) {
 	if len(context) == 0 {
 		return start, 0
 	}
 
-	type matchPass struct {
-		compare func(string, string) bool
-		fuzz    int
-	}
-
-	passes := []matchPass{
-		{func(a, b string) bool { return a == b }, 0},
-		{func(a, b string) bool { return strings.TrimRight(a, " \t") == strings.TrimRight(b, " \t") }, 1},
-		{func(a, b string) bool { return strings.TrimSpace(a) == strings.TrimSpace(b) }, 100},
-		{func(a, b string) bool {
-			return normalizeUnicode(strings.TrimSpace(a)) == normalizeUnicode(strings.TrimSpace(b))
-		}, 1000},
-	}
-
-	for _, pass := range passes {
+	for _, pass := range contextMatchPasses {
 		if idx := tryFindMatch(lines, context, start, pass.compare); idx >= 0 {
 			return idx, pass.fuzz
 		}
@@ -367,6 +451,20 @@ func findContextCore(lines []string, context []string, start int) (int, int) {
 	return -1, 0
 }
 
+// ambiguousContext reports whether context, found at idx with fuzz, also
+// matches further down lines with the same comparison.
+func ambiguousContext(lines []string, context []string, idx, fuzz int) bool {
+	if len(context) == 0 {
+		return false
+	}
+	for _, pass := range contextMatchPasses {
+		if pass.fuzz == fuzz {
+			return tryFindMatch(lines, context, idx+1, pass.compare) >= 0
+		}
+	}
+	return false
+}
+
 func tryFindMatch(lines []string, context []string, start int,
 	compareFunc func(string, string) bool,
 ) int {
@@ -497,6 +595,26 @@ func TextToPatch(text string, orig map[string]string) (Patch, int, error) {
 	return parser.patch, parser.fuzz, nil
 }
 
+// DryRunPatch parses text against orig like TextToPatch, but reports the
+// outcome of every hunk instead of stopping at the first one whose context
+// cannot be located. Hunks whose context matches more than one place are
+// reported as failed too. It returns an error only for patch text that cannot
+// be parsed, or that names files which are missing or already exist.
+func DryRunPatch(text string, orig map[string]string) (PatchReport, error) {
+	text = strings.TrimSpace(text)
+	lines := strings.Split(text, "\n")
+	if len(lines) < 2 || !strings.HasPrefix(lines[0], "*** Begin Patch") || lines[len(lines)-1] != "*** End Patch" {
+		return PatchReport{}, NewDiffError("Invalid patch text")
+	}
+	parser := NewParser(orig, lines)
+	parser.index = 1
+	parser.dryRun = true
+	if err := parser.Parse(); err != nil {
+		return PatchReport{}, err
+	}
+	return PatchReport{Files: parser.reports, Fuzz: parser.fuzz}, nil
+}
+
 func IdentifyFilesNeeded(text string) []string {
 	text = strings.TrimSpace(text)
 	lines := strings.Split(text, "\n")
@@ -518,6 +636,8 @@ func IdentifyFilesNeeded(text string) []string {
 	return files
 }
 
+// IdentifyFilesAdded returns the paths a patch creates: added files and the
+// targets of moves.
 func IdentifyFilesAdded(text string) []string {
 	text = strings.TrimSpace(text)
 	lines := strings.Split(text, "\n")
@@ -527,6 +647,9 @@ func IdentifyFilesAdded(text string) []string {
 		if strings.HasPrefix(line, "*** Add File: ") {
 			result[line[len("*** Add File: "):]] = true
 		}
+		if strings.HasPrefix(line, "*** Move to: ") {
+			result[line[len("*** Move to: "):]] = true
+		}
 	}
 
 	files := make([]string, 0, len(result))
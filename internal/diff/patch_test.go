@@ -305,6 +305,48 @@ func TestIdentifyFilesAdded(t *testing.T) {
 	assert.ElementsMatch(t, []string{"c.txt", "d.txt"}, files)
 }
 
+func TestIdentifyFilesAdded_MoveTarget(t *testing.T) {
+	patchText := "*** Begin Patch\n*** Update File: a.txt\n*** Move to: moved/a.txt\n@@\n-old\n+new\n*** End Patch"
+
+	assert.ElementsMatch(t, []string{"moved/a.txt"}, IdentifyFilesAdded(patchText))
+	assert.ElementsMatch(t, []string{"a.txt"}, IdentifyFilesNeeded(patchText))
+}
+
+func TestTextToPatch_MoveDirective(t *testing.T) {
+	files := map[string]string{
+		"pkg/old.go": "package pkg\n\nfunc Old() {}\n",
+		"keep.go":    "package main\n",
+	}
+
+	patchText := "*** Begin Patch\n" +
+		"*** Update File: pkg/old.go\n" +
+		"*** Move to: pkg/renamed/new.go\n" +
+		"@@\n package pkg\n \n-func Old() {}\n+func New() {}\n" +
+		"*** Update File: keep.go\n" +
+		"@@\n-package main\n+package keep\n" +
+		"*** End Patch"
+
+	patch, fuzz, err := TextToPatch(patchText, files)
+	require.NoError(t, err)
+	assert.Equal(t, 0, fuzz)
+	require.Len(t, patch.Actions, 2)
+
+	moved := patch.Actions["pkg/old.go"]
+	assert.Equal(t, ActionUpdate, moved.Type)
+	require.NotNil(t, moved.MovePath)
+	assert.Equal(t, "pkg/renamed/new.go", *moved.MovePath)
+	require.Len(t, moved.Chunks, 1)
+	assert.Equal(t, []string{"func Old() {}"}, moved.Chunks[0].DelLines)
+	assert.Equal(t, []string{"func New() {}"}, moved.Chunks[0].InsLines)
+
+	assert.Nil(t, patch.Actions["keep.go"].MovePath, "an update without a move keeps its path")
+
+	result := applyPatchInMemory(t, patchText, files)
+	assert.NotContains(t, result, "pkg/old.go")
+	assert.Equal(t, "package pkg\n\nfunc New() {}\n", result["pkg/renamed/new.go"])
+	assert.Equal(t, "package keep\n", result["keep.go"])
+}
+
 func TestPatchToCommit_Delete(t *testing.T) {
 	files := map[string]string{
 		"delete.txt": "old content\n",
@@ -456,3 +498,38 @@ func TestPatch_ReplaceMultipleLinesWithMultiple(t *testing.T) {
 	result := applyPatchInMemory(t, patchText, files)
 	assert.Equal(t, "x\ny\nz\n", result["file.txt"])
 }
+
+func TestDryRunPatch(t *testing.T) {
+	files := map[string]string{
+		"app.go": "func a() {  \n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n",
+		"old.go": "package old\n",
+	}
+	patchText := "*** Begin Patch\n" +
+		"*** Update File: app.go\n" +
+		"@@\n func a() {\n-\treturn 1\n+\treturn 10\n" +
+		"@@\n func c() {\n-\treturn 3\n+\treturn 30\n" +
+		"@@\n }\n+\n+func d() {}\n" +
+		"@@\n func b() {\n-\treturn 2\n+\treturn 20\n" +
+		"*** Delete File: old.go\n" +
+		"*** End Patch"
+
+	report, err := DryRunPatch(patchText, files)
+	require.NoError(t, err)
+
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, FileReport{Path: "app.go", Action: ActionUpdate, Hunks: []HunkReport{
+		{Hunk: 1, Status: HunkApplied, Line: 1, Fuzz: 1},
+		{Hunk: 2, Status: HunkFailed, Reason: HunkContextNotFound},
+		{Hunk: 3, Status: HunkFailed, Reason: HunkContextAmbiguous},
+		{Hunk: 4, Status: HunkApplied, Line: 5},
+	}}, report.Files[0])
+	assert.Equal(t, FileReport{Path: "old.go", Action: ActionDelete}, report.Files[1])
+	assert.Equal(t, 2, report.Failed())
+	assert.Equal(t, 1, report.Fuzz)
+	assert.Equal(t, "func a() {  \n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n", files["app.go"], "a dry run leaves the files untouched")
+}
+
+func TestDryRunPatch_InvalidPatch(t *testing.T) {
+	_, err := DryRunPatch("*** Begin Patch\n*** Update File: missing.go\n@@\n-x\n+y\n*** End Patch", map[string]string{})
+	assert.Error(t, err)
+}
@@ -4,10 +4,11 @@ package fileutil
 
 import (
 	"bufio"
+	"compress/bzip2"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
-	"io/fs"
 	"net/http"
 	"os"
 	"os/exec"
@@ -15,16 +16,23 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 	"github.com/bmatcuk/doublestar/v4"
 )
 
-var (
-	rgPath  string
-	fzfPath string
-)
+// externalTools holds the resolved paths of the optional external tools.
+// ReloadTools replaces it as a whole, so readers always see a consistent pair
+// even while it runs concurrently.
+var externalTools atomic.Pointer[toolPaths]
+
+// toolPaths are the paths of ripgrep and fzf; empty when not installed.
+type toolPaths struct {
+	rg  string
+	fzf string
+}
 
 // Standard permissions for a Native application
 const (
@@ -175,31 +183,48 @@ func init() {
 	ReloadTools()
 }
 
-// ReloadTools refreshes the path for external dependencies
+// ReloadTools refreshes the path for external dependencies. It is safe to
+// call at runtime, e.g. after PATH changed.
 func ReloadTools() {
+	var paths toolPaths
 	var err error
-	rgPath, err = exec.LookPath("rg")
+	paths.rg, err = exec.LookPath("rg")
 	if err != nil {
 		logging.Warn("Ripgrep (rg) not found in $PATH. Some features might be limited or slower.")
-		rgPath = ""
+		paths.rg = ""
 	}
-	fzfPath, err = exec.LookPath("fzf")
+	paths.fzf, err = exec.LookPath("fzf")
 	if err != nil {
 		logging.Warn("FZF not found in $PATH. Some features might be limited or slower.")
-		fzfPath = ""
+		paths.fzf = ""
 	}
+	externalTools.Store(&paths)
+}
+
+// RgPath returns the path of ripgrep, or "" when it is not installed.
+func RgPath() string {
+	return externalTools.Load().rg
+}
+
+// FzfPath returns the path of fzf, or "" when it is not installed.
+func FzfPath() string {
+	return externalTools.Load().fzf
 }
 
-// GetRgCmd returns a command for ripgrep with the given glob pattern
-func GetRgCmd(globPattern string) *exec.Cmd {
+// GetRgCmd returns a command for ripgrep with the given glob pattern.
+// Symlinks are only followed when followSymlinks is set.
+func GetRgCmd(globPattern string, followSymlinks bool) *exec.Cmd {
+	rgPath := externalTools.Load().rg
 	if rgPath == "" {
 		return nil
 	}
 	rgArgs := []string{
 		"--files",
-		"-L",
 		"--null",
 	}
+	if followSymlinks {
+		rgArgs = append(rgArgs, "-L")
+	}
 	if globPattern != "" {
 		if !filepath.IsAbs(globPattern) && !strings.HasPrefix(globPattern, "/") {
 			globPattern = "/" + globPattern
@@ -213,6 +238,7 @@ func GetRgCmd(globPattern string) *exec.Cmd {
 
 // GetFzfCmd returns a command for fzf with the given query
 func GetFzfCmd(query string) *exec.Cmd {
+	fzfPath := externalTools.Load().fzf
 	if fzfPath == "" {
 		return nil
 	}
@@ -240,6 +266,19 @@ func ResolvePath(path, workingDir string) string {
 	return filepath.Clean(filepath.Join(workingDir, path))
 }
 
+// ExpandHome replaces a leading ~ in path with the user's home directory.
+// Other paths, including the ~user form, are returned unchanged.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
 // ResolvePaths resolves multiple paths to absolute paths
 func ResolvePaths(paths []string, workingDir string) []string {
 	result := make([]string, len(paths))
@@ -321,6 +360,16 @@ func NormalizePathForAI(path string) string {
 	return filepath.ToSlash(filepath.Clean(path))
 }
 
+// ToNativePath converts a slash-separated path, as produced by
+// NormalizePathForAI or written by a model, to the platform's native
+// separators. It is the inverse of NormalizePathForAI.
+func ToNativePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
 // IsDirectory checks if a path is a directory
 func IsDirectory(path string) bool {
 	info, err := os.Stat(path)
@@ -487,9 +536,94 @@ func ReadFile(path string) (string, error) {
 	return string(data), nil
 }
 
+// errDecompressedTooLarge is returned when a compressed file expands past
+// MaxReadSize.
+var errDecompressedTooLarge = fmt.Errorf("decompressed content too large. Max allowed: %d", MaxReadSize)
+
+// IsCompressedFile reports whether path has an extension SafeReadFile
+// decompresses transparently.
+func IsCompressedFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz", ".bz2":
+		return true
+	default:
+		return false
+	}
+}
+
+// decompressor returns a reader that decompresses r when path has a
+// compressed file extension, or nil when it does not.
+func decompressor(path string, r io.Reader) (io.Reader, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".gz":
+		return gzip.NewReader(r)
+	case ".bz2":
+		return bzip2.NewReader(r), nil
+	default:
+		return nil, nil
+	}
+}
+
+// OpenDecompressed opens path for reading and decompresses gzip and bzip2
+// files transparently. Like SafeReadFile, it reads the raw bytes of a file
+// whose gzip header is invalid. Closing the reader closes the decompressor and
+// the file.
+func OpenDecompressed(path string) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := decompressor(path, f)
+	if r == nil || err != nil {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+		return f, nil
+	}
+	return &decompressedFile{Reader: r, file: f}, nil
+}
+
+// decompressedFile reads the decompressed content of file.
+type decompressedFile struct {
+	io.Reader
+	file *os.File
+}
+
+func (d *decompressedFile) Close() error {
+	var err error
+	if closer, ok := d.Reader.(io.Closer); ok {
+		err = closer.Close()
+	}
+	return errors.Join(err, d.file.Close())
+}
+
+// readDecompressed decompresses f and returns its content if it is text. The
+// size cap applies to the decompressed content so small archives cannot
+// expand without bound.
+func readDecompressed(f *os.File) (string, bool, error) {
+	r, err := decompressor(f.Name(), f)
+	if r == nil || err != nil {
+		return "", false, err
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r, MaxReadSize+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(content) > MaxReadSize {
+		return "", false, errDecompressedTooLarge
+	}
+	if !isTextFileFromBytes(content[:min(len(content), 512)]) {
+		return "", false, nil
+	}
+	return string(content), true, nil
+}
+
 // SafeReadFile reads a file only if it meets security and size requirements.
 // It opens the file once and reuses the file descriptor for all operations,
-// avoiding redundant I/O operations.
+// avoiding redundant I/O operations. Gzip and bzip2 files are decompressed
+// transparently; if decompression fails the raw bytes are read instead.
 func SafeReadFile(path, workingDir string) (string, error) {
 	safePath, err := SecureResolvePath(path, workingDir)
 	if err != nil {
@@ -513,6 +647,18 @@ func SafeReadFile(path, workingDir string) (string, error) {
 		return "", fmt.Errorf("file too large (%d bytes). Max allowed: %d", info.Size(), MaxReadSize)
 	}
 
+	content, ok, err := readDecompressed(f)
+	if errors.Is(err, errDecompressedTooLarge) {
+		return "", err
+	}
+	if ok {
+		return content, nil
+	}
+	// Not compressed, or not valid compressed text: treat the raw bytes as usual
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
 	// Read first 512 bytes for content type detection
 	header := make([]byte, 512)
 	n, err := f.Read(header)
@@ -536,11 +682,11 @@ func SafeReadFile(path, workingDir string) (string, error) {
 	}
 
 	// Combine header and remainder
-	content := make([]byte, 0, len(header)+len(remainder))
-	content = append(content, header...)
-	content = append(content, remainder...)
+	raw := make([]byte, 0, len(header)+len(remainder))
+	raw = append(raw, header...)
+	raw = append(raw, remainder...)
 
-	return string(content), nil
+	return string(raw), nil
 }
 
 // ReadFileWithLimit reads a file with line offset and limit
@@ -637,33 +783,97 @@ func DeleteDir(path string) error {
 // GLOB AND PATTERN MATCHING
 // ============================================
 
-// GlobWithDoublestar finds files matching a pattern
-func GlobWithDoublestar(pattern, searchPath string, limit int) ([]string, bool, error) {
-	fsys := os.DirFS(searchPath)
+// ValidateGlob reports why pattern is not a valid glob: unbalanced braces,
+// an unterminated or empty character class, or a trailing backslash. The
+// error wraps doublestar.ErrBadPattern. Patterns use '/' as the separator.
+func ValidateGlob(pattern string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid glob pattern: %q has %s: %w", pattern, reason, doublestar.ErrBadPattern)
+	}
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			if i++; i >= len(pattern) {
+				return invalid("a trailing backslash")
+			}
+		case '[':
+			start := i
+			i++
+			if i < len(pattern) && (pattern[i] == '^' || pattern[i] == '!') {
+				i++
+			}
+			if i < len(pattern) && pattern[i] == ']' {
+				return invalid(fmt.Sprintf("an empty character class at offset %d", start))
+			}
+			for i < len(pattern) && pattern[i] != ']' {
+				if pattern[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(pattern) {
+				return invalid(fmt.Sprintf("an unterminated character class at offset %d", start))
+			}
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return invalid(fmt.Sprintf("an unmatched '}' at offset %d", i))
+			}
+			depth--
+		}
+	}
+	if depth > 0 {
+		return invalid("an unclosed '{'")
+	}
+	return nil
+}
+
+// GlobWithDoublestar finds files matching a pattern.
+// Symlinked directories are only descended into when followSymlinks is set.
+func GlobWithDoublestar(pattern, searchPath string, limit int, followSymlinks bool) ([]string, bool, error) {
 	relPattern := strings.TrimPrefix(pattern, "/")
-	var matches []FileInfo
+	if err := ValidateGlob(relPattern); err != nil {
+		return nil, false, fmt.Errorf("glob walk error: %w", err)
+	}
 
-	err := doublestar.GlobWalk(fsys, relPattern, func(path string, d fs.DirEntry) error {
-		if d.IsDir() {
+	// Only walk below the static prefix of the pattern, and no deeper than
+	// the pattern can reach when it has no "**".
+	base, rest := doublestar.SplitPattern(relPattern)
+	root := filepath.Join(searchPath, filepath.FromSlash(base))
+	maxDepth := -1
+	if !strings.Contains(rest, "**") {
+		maxDepth = strings.Count(rest, "/") + 1
+	}
+
+	var matches []FileInfo
+	err := Walk(root, followSymlinks, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
 			return nil
 		}
-		if SkipHidden(path) {
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil || rel == "." {
 			return nil
 		}
-		info, err := d.Info()
-		if err != nil {
+		rel = filepath.ToSlash(rel)
+
+		if info.IsDir() {
+			if maxDepth >= 0 && strings.Count(rel, "/")+1 >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if SkipHidden(filepath.Join(base, rel)) {
 			return nil
 		}
-		absPath := path
-		if !strings.HasPrefix(absPath, searchPath) && searchPath != "." {
-			absPath = filepath.Join(searchPath, absPath)
-		} else if !strings.HasPrefix(absPath, "/") && searchPath == "." {
-			absPath = filepath.Join(searchPath, absPath)
+		if matched, _ := doublestar.Match(rest, rel); !matched {
+			return nil
 		}
 
-		matches = append(matches, FileInfo{Path: absPath, ModTime: info.ModTime()})
+		matches = append(matches, FileInfo{Path: path, ModTime: info.ModTime()})
 		if limit > 0 && len(matches) >= limit*2 {
-			return fs.SkipAll
+			return filepath.SkipAll
 		}
 		return nil
 	})
@@ -716,29 +926,37 @@ func EscapeRegexPattern(pattern string) string {
 // DIRECTORY LISTING
 // ============================================
 
-// ListDirectory lists files in a directory
-func ListDirectory(initialPath string, ignorePatterns []string, limit int) ([]string, bool, error) {
+// ListDirectory lists files in a directory.
+// Symlinked directories are only descended into when followSymlinks is set.
+func ListDirectory(initialPath string, ignorePatterns []string, limit int, followSymlinks bool) ([]string, bool, error) {
 	var results []string
 	truncated := false
 
-	err := filepath.Walk(initialPath, func(path string, info os.FileInfo, err error) error {
+	err := Walk(initialPath, followSymlinks, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip files we don't have permission to access
 		}
+		if path == initialPath {
+			return nil
+		}
 
-		if ShouldSkipPath(path, ignorePatterns) {
+		// Match against the path relative to the listed directory so that
+		// ignored names in its ancestors (e.g. /tmp) don't hide everything.
+		rel, relErr := filepath.Rel(initialPath, path)
+		if relErr != nil {
+			rel = path
+		}
+		if ShouldSkipPath(rel, ignorePatterns) {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		if path != initialPath {
-			if info.IsDir() {
-				path = path + string(filepath.Separator)
-			}
-			results = append(results, path)
+		if info.IsDir() {
+			path = path + string(filepath.Separator)
 		}
+		results = append(results, path)
 
 		if len(results) >= limit {
 			truncated = true
@@ -754,6 +972,75 @@ func ListDirectory(initialPath string, ignorePatterns []string, limit int) ([]st
 	return results, truncated, nil
 }
 
+// Walk walks the file tree rooted at root in lexical order, calling fn for
+// each file or directory like filepath.Walk. When followSymlinks is set,
+// symlinks are resolved and symlinked directories are descended into; each
+// real directory is visited at most once so symlink loops terminate.
+func Walk(root string, followSymlinks bool, fn filepath.WalkFunc) error {
+	var visited map[string]bool
+	if followSymlinks {
+		visited = make(map[string]bool)
+	}
+	err := walk(root, visited, fn)
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walk visits path and its children. A nil visited map disables symlink
+// following.
+func walk(path string, visited map[string]bool, fn filepath.WalkFunc) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return fn(path, nil, err)
+	}
+	if visited != nil && info.Mode()&os.ModeSymlink != 0 {
+		// Broken links are reported as the link itself.
+		if target, statErr := os.Stat(path); statErr == nil {
+			info = target
+		}
+	}
+
+	if err := fn(path, info, nil); err != nil {
+		if info.IsDir() && errors.Is(err, filepath.SkipDir) {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+
+	if visited != nil {
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil
+		}
+		if visited[realPath] {
+			return nil
+		}
+		visited[realPath] = true
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if err := fn(path, info, err); err != nil && !errors.Is(err, filepath.SkipDir) {
+			return err
+		}
+		return nil
+	}
+	for _, entry := range entries {
+		if err := walk(filepath.Join(path, entry.Name()), visited, fn); err != nil {
+			if errors.Is(err, filepath.SkipDir) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
 // ============================================
 // FILE CONTENT SEARCHING
 // ============================================
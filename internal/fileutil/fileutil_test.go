@@ -1,9 +1,15 @@
 package fileutil
 
 import (
+	"compress/gzip"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -12,6 +18,23 @@ import (
 // Helper Functions
 // ============================================================================
 
+// writeGzip writes content to path gzip-compressed
+func writeGzip(t *testing.T, path string, content []byte) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	if _, err := w.Write(content); err != nil {
+		t.Fatalf("failed to write gzip content: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close gzip writer: %v", err)
+	}
+}
+
 // boolTestCase represents a test case for functions returning bool
 type boolTestCase struct {
 	name     string
@@ -134,6 +157,39 @@ func TestPathOperations(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("NativePathRoundTrip", func(t *testing.T) {
+		native := func(p string) string {
+			return strings.ReplaceAll(p, "/", string(filepath.Separator))
+		}
+		tests := []struct {
+			name string
+			path string
+		}{
+			{name: "posix absolute", path: "/home/user/project/main.go"},
+			{name: "posix relative", path: "src/internal/file.go"},
+			{name: "windows drive", path: "C:/Users/user/project/main.go"},
+			{name: "single segment", path: "file.go"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got := ToNativePath(tt.path)
+				if got != native(tt.path) {
+					t.Errorf("ToNativePath(%q) = %q, want %q", tt.path, got, native(tt.path))
+				}
+				if back := NormalizePathForAI(got); back != tt.path {
+					t.Errorf("NormalizePathForAI(ToNativePath(%q)) = %q, want the original path", tt.path, back)
+				}
+			})
+		}
+
+		if got := ToNativePath("src//app/../file.go"); got != native("src/file.go") {
+			t.Errorf("ToNativePath cleans the path: got %q, want %q", got, native("src/file.go"))
+		}
+		if got := ToNativePath(""); got != "" {
+			t.Errorf("ToNativePath(\"\") = %q, want empty", got)
+		}
+	})
 }
 
 // ============================================================================
@@ -539,7 +595,7 @@ func TestListDirectory(t *testing.T) {
 	os.WriteFile(filepath.Join(tmpDir, ".hiddenfile"), []byte("test"), 0o644)
 	os.WriteFile(filepath.Join(tmpDir, "subdir1", "nested.txt"), []byte("test"), 0o644)
 
-	files, truncated, err := ListDirectory(tmpDir, []string{}, 100)
+	files, truncated, err := ListDirectory(tmpDir, []string{}, 100, false)
 	if err != nil {
 		t.Fatalf("ListDirectory failed: %v", err)
 	}
@@ -556,7 +612,7 @@ func TestListDirectory(t *testing.T) {
 		}
 	}
 
-	files, truncated, err = ListDirectory(tmpDir, []string{}, 1)
+	files, truncated, err = ListDirectory(tmpDir, []string{}, 1, false)
 	if err != nil {
 		t.Fatalf("ListDirectory with limit failed: %v", err)
 	}
@@ -571,7 +627,7 @@ func TestListDirectory(t *testing.T) {
 		t.Errorf("Expected truncation with limit 1, got truncated=%v with %d files", truncated, len(files))
 	}
 
-	files, _, err = ListDirectory(tmpDir, []string{"**/*.go"}, 100)
+	files, _, err = ListDirectory(tmpDir, []string{"**/*.go"}, 100, false)
 	if err != nil {
 		t.Fatalf("ListDirectory with pattern failed: %v", err)
 	}
@@ -582,6 +638,142 @@ func TestListDirectory(t *testing.T) {
 	}
 }
 
+func TestFollowSymlinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	project := filepath.Join(tmpDir, "project")
+	shared := filepath.Join(tmpDir, "shared")
+
+	os.MkdirAll(filepath.Join(project, "src"), 0o755)
+	os.MkdirAll(shared, 0o755)
+	os.WriteFile(filepath.Join(project, "src", "main.go"), []byte("main"), 0o644)
+	os.WriteFile(filepath.Join(shared, "lib.go"), []byte("lib"), 0o644)
+	if err := os.Symlink(shared, filepath.Join(project, "linked")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	// A link back to the project root must not cause an endless walk.
+	if err := os.Symlink(project, filepath.Join(shared, "loop")); err != nil {
+		t.Fatalf("failed to create loop symlink: %v", err)
+	}
+
+	containsSuffix := func(paths []string, suffix string) bool {
+		for _, p := range paths {
+			if strings.HasSuffix(filepath.ToSlash(p), suffix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	tests := []struct {
+		name   string
+		follow bool
+	}{
+		{name: "not following", follow: false},
+		{name: "following", follow: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, _, err := ListDirectory(project, nil, 100, tt.follow)
+			if err != nil {
+				t.Fatalf("ListDirectory failed: %v", err)
+			}
+			if !containsSuffix(files, "src/main.go") {
+				t.Errorf("ListDirectory missing src/main.go: %v", files)
+			}
+			if got := containsSuffix(files, "linked/lib.go"); got != tt.follow {
+				t.Errorf("ListDirectory included linked/lib.go = %v, want %v: %v", got, tt.follow, files)
+			}
+
+			files, _, err = GlobWithDoublestar("**/*.go", project, 100, tt.follow)
+			if err != nil {
+				t.Fatalf("GlobWithDoublestar failed: %v", err)
+			}
+			if !containsSuffix(files, "src/main.go") {
+				t.Errorf("GlobWithDoublestar missing src/main.go: %v", files)
+			}
+			if got := containsSuffix(files, "linked/lib.go"); got != tt.follow {
+				t.Errorf("GlobWithDoublestar included linked/lib.go = %v, want %v: %v", got, tt.follow, files)
+			}
+		})
+	}
+}
+
+func TestGetRgCmdFollowSymlinks(t *testing.T) {
+	if externalTools.Load().rg == "" {
+		t.Skip("ripgrep not installed")
+	}
+	if cmd := GetRgCmd("", false); slices.Contains(cmd.Args, "-L") {
+		t.Errorf("expected no -L flag, got %v", cmd.Args)
+	}
+	if cmd := GetRgCmd("", true); !slices.Contains(cmd.Args, "-L") {
+		t.Errorf("expected -L flag, got %v", cmd.Args)
+	}
+}
+
+func TestExpandHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"~", home},
+		{"~/x", filepath.Join(home, "x")},
+		{"~/.config/skills", filepath.Join(home, ".config", "skills")},
+		{"/abs/path", "/abs/path"},
+		{"relative/path", "relative/path"},
+		{"~user/x", "~user/x"},
+		{"dir/~/x", "dir/~/x"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := ExpandHome(tt.path); got != tt.want {
+			t.Errorf("ExpandHome(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestReloadToolsConcurrent(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake rg")
+	}
+	t.Cleanup(ReloadTools)
+	binDir := t.TempDir()
+	fakeRg := filepath.Join(binDir, "rg")
+	if err := os.WriteFile(fakeRg, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+	ReloadTools()
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 100)
+	for range 50 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ReloadTools()
+		}()
+		go func() {
+			defer wg.Done()
+			cmd := GetRgCmd("*.go", false)
+			if cmd == nil || cmd.Path != fakeRg {
+				errs <- fmt.Sprintf("GetRgCmd = %v, want a command for %s", cmd, fakeRg)
+			}
+			if GetFzfCmd("query") != nil {
+				errs <- "GetFzfCmd returned a command without fzf in PATH"
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
 // ============================================================================
 // File I/O Tests
 // ============================================================================
@@ -669,7 +861,7 @@ func TestGlobWithDoublestar(t *testing.T) {
 	os.WriteFile(filepath.Join(tmpDir, "src", "util", "helper.go"), []byte("helper"), 0o644)
 	os.WriteFile(filepath.Join(tmpDir, ".hidden.go"), []byte("hidden"), 0o644)
 
-	files, _, err := GlobWithDoublestar("*.go", tmpDir, 10)
+	files, _, err := GlobWithDoublestar("*.go", tmpDir, 10, false)
 	if err != nil {
 		t.Fatalf("GlobWithDoublestar failed: %v", err)
 	}
@@ -677,7 +869,7 @@ func TestGlobWithDoublestar(t *testing.T) {
 		t.Errorf("Expected 1 file, got %d", len(files))
 	}
 
-	files, _, err = GlobWithDoublestar("**/*.go", tmpDir, 10)
+	files, _, err = GlobWithDoublestar("**/*.go", tmpDir, 10, false)
 	if err != nil {
 		t.Fatalf("GlobWithDoublestar recursive failed: %v", err)
 	}
@@ -699,6 +891,40 @@ func TestGlobWithDoublestar(t *testing.T) {
 	}
 }
 
+func TestValidateGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr string
+	}{
+		{pattern: "**/*.go"},
+		{pattern: "*.{ts,tsx}"},
+		{pattern: "src/{a,{b,c}}/*.go"},
+		{pattern: "[a-z]*.go"},
+		{pattern: "[!_]*.go"},
+		{pattern: `file\[1\].txt`},
+		{pattern: "*.{ts,tsx", wantErr: "an unclosed '{'"},
+		{pattern: "src/{a,{b,c}/*.go", wantErr: "an unclosed '{'"},
+		{pattern: "*.ts}", wantErr: "an unmatched '}' at offset 4"},
+		{pattern: "[a-z*.go", wantErr: "an unterminated character class at offset 0"},
+		{pattern: "file[].go", wantErr: "an empty character class at offset 4"},
+		{pattern: `trailing\`, wantErr: "a trailing backslash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			err := ValidateGlob(tt.pattern)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateGlob(%q) = %v, want nil", tt.pattern, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) || !strings.HasPrefix(err.Error(), "invalid glob pattern: ") {
+				t.Errorf("ValidateGlob(%q) = %v, want an error containing %q", tt.pattern, err, tt.wantErr)
+			}
+		})
+	}
+}
+
 // ============================================================================
 // File Info Tests
 // ============================================================================
@@ -807,6 +1033,56 @@ func TestSafeReadFile(t *testing.T) {
 		}
 	})
 
+	t.Run("gzip text file is decompressed", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "app.log.gz")
+		want := strings.Repeat("2024-01-01 INFO request served\n", 100)
+		writeGzip(t, testFile, []byte(want))
+
+		result, err := SafeReadFile(testFile, tmpDir)
+		if err != nil {
+			t.Fatalf("SafeReadFile(%q) unexpected error: %v", testFile, err)
+		}
+		if result != want {
+			t.Errorf("SafeReadFile(%q) = %.40q..., want decompressed text", testFile, result)
+		}
+	})
+
+	t.Run("gzip decompressed size exceeds cap", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "bomb.txt.gz")
+		writeGzip(t, testFile, make([]byte, 2*MaxReadSize))
+
+		info, err := os.Stat(testFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Size() > MaxReadSize {
+			t.Fatalf("compressed fixture is %d bytes, want it under the cap", info.Size())
+		}
+
+		_, err = SafeReadFile(testFile, tmpDir)
+		if err == nil {
+			t.Fatalf("SafeReadFile(%q) expected error for oversized decompressed content", testFile)
+		}
+		if !strings.Contains(err.Error(), "decompressed content too large") {
+			t.Errorf("SafeReadFile(%q) error = %v, want error containing 'decompressed content too large'", testFile, err)
+		}
+	})
+
+	t.Run("invalid gzip falls back to raw bytes", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "notes.gz")
+		if err := os.WriteFile(testFile, []byte("plain text with a .gz name"), 0o644); err != nil {
+			t.Fatalf("failed to create temp file: %v", err)
+		}
+
+		result, err := SafeReadFile(testFile, tmpDir)
+		if err != nil {
+			t.Fatalf("SafeReadFile(%q) unexpected error: %v", testFile, err)
+		}
+		if result != "plain text with a .gz name" {
+			t.Errorf("SafeReadFile(%q) = %q, want the raw content", testFile, result)
+		}
+	})
+
 	t.Run("path traversal attempt", func(t *testing.T) {
 		// Try to read a file outside the working directory
 		_, err := SafeReadFile("/etc/passwd", tmpDir)
@@ -823,6 +1099,43 @@ func TestSafeReadFile(t *testing.T) {
 	})
 }
 
+func TestOpenDecompressed(t *testing.T) {
+	tmpDir := t.TempDir()
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		r, err := OpenDecompressed(path)
+		if err != nil {
+			t.Fatalf("OpenDecompressed(%q) unexpected error: %v", path, err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("reading %q: %v", path, err)
+		}
+		if err := r.Close(); err != nil {
+			t.Errorf("closing %q: %v", path, err)
+		}
+		return string(content)
+	}
+
+	t.Run("gzip file is decompressed", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "app.log.gz")
+		writeGzip(t, testFile, []byte("request served\n"))
+		if got := read(t, testFile); got != "request served\n" {
+			t.Errorf("OpenDecompressed(%q) read %q, want the decompressed text", testFile, got)
+		}
+	})
+
+	t.Run("invalid gzip falls back to raw bytes", func(t *testing.T) {
+		testFile := filepath.Join(tmpDir, "notes.gz")
+		if err := os.WriteFile(testFile, []byte("plain text"), 0o644); err != nil {
+			t.Fatalf("failed to create temp file: %v", err)
+		}
+		if got := read(t, testFile); got != "plain text" {
+			t.Errorf("OpenDecompressed(%q) read %q, want the raw content", testFile, got)
+		}
+	})
+}
+
 // ============================================================================
 // IsTextFile Tests
 // ============================================================================
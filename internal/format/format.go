@@ -134,6 +134,47 @@ func ResolveSchemaRef(schema map[string]any, baseDir string) (map[string]any, er
 	return loadSchemaFromFile(refPath)
 }
 
+// ResolveSchema resolves and validates a schema given in config. value is
+// either a schema object, which may hold a root $ref, or a string with inline
+// JSON or a file path. Relative paths are resolved against baseDir.
+func ResolveSchema(value any, baseDir string) (map[string]any, error) {
+	var schema map[string]any
+	switch v := value.(type) {
+	case map[string]any:
+		resolved, err := ResolveSchemaRef(v, baseDir)
+		if err != nil {
+			return nil, err
+		}
+		schema = resolved
+	case string:
+		raw := strings.TrimSpace(v)
+		if err := json.Unmarshal([]byte(raw), &schema); err == nil {
+			resolved, refErr := ResolveSchemaRef(schema, baseDir)
+			if refErr != nil {
+				return nil, refErr
+			}
+			schema = resolved
+		} else {
+			if baseDir != "" {
+				raw = fileutil.ResolvePath(raw, baseDir)
+			}
+			loaded, loadErr := loadSchemaFromFile(raw)
+			if loadErr != nil {
+				return nil, loadErr
+			}
+			schema = loaded
+		}
+	case nil:
+		return nil, errors.New("json_schema format requires a schema")
+	default:
+		return nil, fmt.Errorf("schema must be an object or a file path, got %T", value)
+	}
+	if err := ValidateJSONSchema(schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
 // loadSchemaFromFile reads a JSON file and unmarshals it into a schema map.
 func loadSchemaFromFile(path string) (map[string]any, error) {
 	data, err := os.ReadFile(path)
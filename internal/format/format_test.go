@@ -462,3 +462,34 @@ func TestResolveSchemaRef(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveSchema(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "schema.json"), []byte(`{"type":"object","properties":{"score":{"type":"number"}}}`), 0o644)
+
+	tests := []struct {
+		name    string
+		value   any
+		wantErr bool
+	}{
+		{name: "inline object", value: map[string]any{"type": "object"}},
+		{name: "object with relative $ref", value: map[string]any{"$ref": "schema.json"}},
+		{name: "relative file path", value: "schema.json"},
+		{name: "inline JSON string", value: `{"type":"object"}`},
+		{name: "missing file", value: "missing.json", wantErr: true},
+		{name: "invalid schema", value: map[string]any{"type": 42}, wantErr: true},
+		{name: "no schema", value: nil, wantErr: true},
+		{name: "unsupported type", value: 42, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ResolveSchema(tt.value, dir)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSchema() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && schema["type"] != "object" {
+				t.Errorf("type = %v, want 'object'", schema["type"])
+			}
+		})
+	}
+}
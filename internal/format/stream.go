@@ -0,0 +1,125 @@
+package format
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrIncompleteJSON is returned for the result of a JSONAssembler whose
+// deltas have not formed a complete JSON value yet.
+var ErrIncompleteJSON = errors.New("the streamed JSON value is incomplete")
+
+// JSONAssembler assembles a JSON object or array from text streamed in
+// deltas, such as a model's response in json_schema format, and validates it
+// against a schema once it is complete. Text around the value, like a
+// markdown code fence, is kept for display but is not part of the result.
+type JSONAssembler struct {
+	schema map[string]any
+	raw    strings.Builder
+	value  strings.Builder
+
+	depth    int
+	inString bool
+	escaped  bool
+	complete bool
+
+	result any
+	err    error
+}
+
+// NewJSONAssembler returns an assembler validating against schema. A nil
+// schema accepts any JSON object or array.
+func NewJSONAssembler(schema map[string]any) *JSONAssembler {
+	return &JSONAssembler{schema: schema}
+}
+
+// Write appends a streamed delta. It reports true once the deltas hold a
+// complete top-level value, together with the error decoding or validating
+// it, if any. Deltas written after that only extend Raw.
+func (a *JSONAssembler) Write(delta string) (bool, error) {
+	a.raw.WriteString(delta)
+	if a.complete {
+		return true, a.err
+	}
+
+	for i := 0; i < len(delta); i++ {
+		c := delta[i]
+		if a.value.Len() == 0 && c != '{' && c != '[' {
+			continue
+		}
+		a.value.WriteByte(c)
+
+		switch {
+		case a.inString:
+			switch {
+			case a.escaped:
+				a.escaped = false
+			case c == '\\':
+				a.escaped = true
+			case c == '"':
+				a.inString = false
+			}
+		case c == '"':
+			a.inString = true
+		case c == '{' || c == '[':
+			a.depth++
+		case c == '}' || c == ']':
+			a.depth--
+			if a.depth == 0 {
+				a.finish()
+				return true, a.err
+			}
+		}
+	}
+	return false, nil
+}
+
+// finish decodes and validates the complete value.
+func (a *JSONAssembler) finish() {
+	a.complete = true
+	var result any
+	if err := json.Unmarshal([]byte(a.value.String()), &result); err != nil {
+		a.err = fmt.Errorf("invalid JSON output: %w", err)
+		return
+	}
+	if err := ValidateValue(a.schema, result); err != nil {
+		a.err = fmt.Errorf("output does not match the schema: %w", err)
+		return
+	}
+	a.result = result
+}
+
+// Raw returns every delta written so far, for display while streaming.
+func (a *JSONAssembler) Raw() string {
+	return a.raw.String()
+}
+
+// Complete reports whether a complete top-level value has formed.
+func (a *JSONAssembler) Complete() bool {
+	return a.complete
+}
+
+// Result returns the validated value. It fails with ErrIncompleteJSON until
+// the value is complete.
+func (a *JSONAssembler) Result() (any, error) {
+	if !a.complete {
+		return nil, ErrIncompleteJSON
+	}
+	return a.result, a.err
+}
+
+// Content returns the validated value as indented JSON, the form structured
+// output is returned in.
+func (a *JSONAssembler) Content() (string, error) {
+	result, err := a.Result()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format output: %w", err)
+	}
+	return string(data), nil
+}
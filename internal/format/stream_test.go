@@ -0,0 +1,104 @@
+package format
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var reviewSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"verdict": map[string]any{"type": "string", "enum": []any{"approve", "reject"}},
+		"score":   map[string]any{"type": "integer"},
+		"issues": map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		},
+	},
+	"required":             []any{"verdict", "score"},
+	"additionalProperties": false,
+}
+
+func TestJSONAssembler(t *testing.T) {
+	deltas := []string{
+		"```json\n",
+		`{"verdict": "app`,
+		`rove", "score"`,
+		`: 8, "issues": ["brace } in `,
+		`a \"string\" ]"]`,
+		"}",
+		"\n```",
+	}
+	a := NewJSONAssembler(reviewSchema)
+	completedAt := -1
+	for i, delta := range deltas {
+		complete, err := a.Write(delta)
+		if err != nil {
+			t.Fatalf("Write(%q) error = %v", delta, err)
+		}
+		if complete && completedAt == -1 {
+			completedAt = i
+		} else if !complete {
+			if _, err := a.Result(); !errors.Is(err, ErrIncompleteJSON) {
+				t.Errorf("Result() before completion error = %v, want ErrIncompleteJSON", err)
+			}
+		}
+	}
+	if completedAt != 5 {
+		t.Errorf("value complete after delta %d, want 5", completedAt)
+	}
+
+	result, err := a.Result()
+	if err != nil {
+		t.Fatalf("Result() error = %v", err)
+	}
+	object := result.(map[string]any)
+	if object["verdict"] != "approve" || object["score"] != float64(8) {
+		t.Errorf("Result() = %v", object)
+	}
+	if issues := object["issues"].([]any); len(issues) != 1 || issues[0] != `brace } in a "string" ]` {
+		t.Errorf("issues = %q", issues)
+	}
+	if got := a.Raw(); got != strings.Join(deltas, "") {
+		t.Errorf("Raw() = %q, want every delta", got)
+	}
+	content, err := a.Content()
+	if err != nil || !strings.HasPrefix(content, "{\n  \"issues\"") {
+		t.Errorf("Content() = %q, %v, want indented JSON", content, err)
+	}
+}
+
+func TestJSONAssembler_SchemaViolation(t *testing.T) {
+	tests := []struct {
+		name   string
+		deltas []string
+		want   string
+	}{
+		{"wrong type", []string{`{"verdict": "approve", `, `"score": 7.5}`}, "$.score: expected integer, got number"},
+		{"missing required", []string{`{"verdict":`, ` "reject"}`}, `missing required property "score"`},
+		{"not in enum", []string{`{"verdict": "maybe", "score": 1}`}, "$.verdict: \"maybe\" is not one of the allowed values"},
+		{"unexpected property", []string{`{"verdict": "approve", "score": 1,`, ` "extra": true}`}, "$.extra: unexpected property"},
+		{"array item", []string{`{"verdict": "approve", "score": 1, "issues": ["a", 2]}`}, "$.issues[1]: expected string, got number"},
+		{"invalid JSON", []string{`{"verdict": "approve"`, `, "score": 1]`}, "invalid JSON output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewJSONAssembler(reviewSchema)
+			var complete bool
+			var err error
+			for _, delta := range tt.deltas {
+				complete, err = a.Write(delta)
+			}
+			if !complete {
+				t.Fatal("value not complete")
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Write() error = %v, want %q", err, tt.want)
+			}
+			if _, resultErr := a.Result(); resultErr == nil {
+				t.Error("Result() returned no error for an invalid value")
+			}
+		})
+	}
+}
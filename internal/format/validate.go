@@ -0,0 +1,174 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidateValue checks a decoded JSON value against schema. It supports the
+// keywords structured output schemas use: type, enum, const, properties,
+// required, additionalProperties and items. Other keywords are ignored.
+func ValidateValue(schema map[string]any, value any) error {
+	return validateValue(schema, value, "$")
+}
+
+func validateValue(schema map[string]any, value any, path string) error {
+	if len(schema) == 0 {
+		return nil
+	}
+	if err := checkSchemaType(schema["type"], value, path); err != nil {
+		return err
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, value) {
+		return fmt.Errorf("%s: %s is not one of the allowed values", path, describeValue(value))
+	}
+	if want, ok := schema["const"]; ok && !equalValues(want, value) {
+		return fmt.Errorf("%s: %s does not equal the required value", path, describeValue(value))
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		return validateObject(schema, v, path)
+	case []any:
+		items, _ := schema["items"].(map[string]any)
+		for i, item := range v {
+			if err := validateValue(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateObject(schema map[string]any, object map[string]any, path string) error {
+	required, _ := schema["required"].([]any)
+	for _, r := range required {
+		if key, ok := r.(string); ok {
+			if _, exists := object[key]; !exists {
+				return fmt.Errorf("%s: missing required property %q", path, key)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	// Report the first violation deterministically
+	sort.Strings(keys)
+	for _, key := range keys {
+		propertyPath := path + "." + key
+		if propertySchema, defined := properties[key]; defined {
+			sub, _ := propertySchema.(map[string]any)
+			if err := validateValue(sub, object[key], propertyPath); err != nil {
+				return err
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				return fmt.Errorf("%s: unexpected property", propertyPath)
+			}
+		case map[string]any:
+			if err := validateValue(additional, object[key], propertyPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkSchemaType checks value against a type keyword, which is a type name
+// or a list of them.
+func checkSchemaType(schemaType any, value any, path string) error {
+	var types []string
+	switch t := schemaType.(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, name := range t {
+			if s, ok := name.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	for _, t := range types {
+		if hasJSONType(value, t) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value))
+}
+
+func hasJSONType(value any, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonTypeName(value) == schemaType
+	}
+}
+
+// jsonTypeName returns the JSON type of a value decoded by encoding/json.
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if equalValues(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues compares JSON values, treating numbers of any Go type in a
+// schema as equal to the float64 they decode to.
+func equalValues(a, b any) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(ja) == string(jb)
+}
+
+func describeValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(data) > 60 {
+		return string(data[:60]) + "..."
+	}
+	return string(data)
+}
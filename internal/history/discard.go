@@ -0,0 +1,104 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FileChange is a file changed during a session together with the content it
+// had before the session first touched it.
+type FileChange struct {
+	Path     string
+	Original string
+	// Created reports that the file did not exist before the session.
+	Created bool
+}
+
+// SessionChanges lists every file changed by sessionID and its subagent
+// sessions, with the content each had before the session, sorted by path.
+func SessionChanges(ctx context.Context, files Service, sessionID string) ([]FileChange, error) {
+	own, err := files.ListBySession(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session files: %w", err)
+	}
+	tree, err := files.ListBySessionTree(ctx, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subagent session files: %w", err)
+	}
+
+	all := own
+	for _, f := range tree {
+		if !slices.ContainsFunc(own, func(o File) bool { return o.ID == f.ID }) {
+			all = append(all, f)
+		}
+	}
+
+	earliest := earliestByPath(all)
+	changes := make([]FileChange, 0, len(earliest))
+	for _, f := range earliest {
+		changes = append(changes, FileChange{
+			Path:     f.Path,
+			Original: f.Content,
+			Created:  f.IsNew,
+		})
+	}
+	slices.SortFunc(changes, func(a, b FileChange) int { return strings.Compare(a.Path, b.Path) })
+	return changes, nil
+}
+
+// RestoreFileChanges puts every file back to its state before the session:
+// created files are removed, modified and deleted files are rewritten with
+// their original content. It restores as many files as it can and returns
+// the errors of those it could not.
+func RestoreFileChanges(changes []FileChange) error {
+	var errs []error
+	for _, change := range changes {
+		if change.Created {
+			if err := os.Remove(change.Path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", change.Path, err))
+			}
+			continue
+		}
+		perm := os.FileMode(0o644)
+		if info, err := os.Stat(change.Path); err == nil {
+			perm = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(change.Path), 0o755); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create directory for %s: %w", change.Path, err))
+			continue
+		}
+		if err := os.WriteFile(change.Path, []byte(change.Original), perm); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", change.Path, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// earliestByPath returns the lowest version of every path, ranked by version
+// number, then creation time.
+func earliestByPath(files []File) []File {
+	best := make(map[string]File)
+	var paths []string
+	for _, f := range files {
+		e, exists := best[f.Path]
+		if !exists {
+			paths = append(paths, f.Path)
+			best[f.Path] = f
+			continue
+		}
+		n, en := parseVersionNum(f.Version), parseVersionNum(e.Version)
+		if n < en || (n == en && f.CreatedAt < e.CreatedAt) {
+			best[f.Path] = f
+		}
+	}
+	result := make([]File, 0, len(best))
+	for _, p := range paths {
+		result = append(result, best[p])
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/db"
+)
+
+// sessionQuerier serves the session file listings from memory; other Querier
+// methods are not used by SessionChanges.
+type sessionQuerier struct {
+	db.QuerierWithTx
+	files []db.File
+	roots map[string]string
+}
+
+func (q *sessionQuerier) ListFilesBySession(_ context.Context, sessionID string) ([]db.File, error) {
+	var files []db.File
+	for _, f := range q.files {
+		if f.SessionID == sessionID {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func (q *sessionQuerier) ListFilesBySessionTree(_ context.Context, rootSessionID sql.NullString) ([]db.File, error) {
+	var files []db.File
+	for _, f := range q.files {
+		if q.roots[f.SessionID] == rootSessionID.String {
+			files = append(files, f)
+		}
+	}
+	return files, nil
+}
+
+func TestSessionChanges_Discard(t *testing.T) {
+	dir := t.TempDir()
+	created := filepath.Join(dir, "created.go")
+	edited := filepath.Join(dir, "edited.go")
+	wasEmpty := filepath.Join(dir, "empty.go")
+	deleted := filepath.Join(dir, "sub", "deleted.go")
+	untouched := filepath.Join(dir, "untouched.go")
+
+	for path, content := range map[string]string{
+		created:   "package demo\n\nfunc New() {}\n",
+		edited:    "package demo\n\nfunc Edited() {}\n",
+		wasEmpty:  "package demo\n",
+		untouched: "package demo\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q := &sessionQuerier{
+		roots: map[string]string{"earlier": "earlier", "s1": "s1", "s1-task": "s1"},
+		files: []db.File{
+			// An earlier session already edited this file.
+			{ID: "1", SessionID: "earlier", Path: edited, Version: InitialVersion, Content: "package demo\n\nfunc Ancient() {}\n", CreatedAt: 1},
+			{ID: "2", SessionID: "earlier", Path: edited, Version: "v1", Content: "package demo\n\nfunc Original() {}\n", CreatedAt: 2},
+
+			{ID: "3", SessionID: "s1", Path: created, Version: InitialVersion, Content: "", IsNew: true, CreatedAt: 10},
+			{ID: "4", SessionID: "s1", Path: created, Version: "v1", Content: "package demo\n\nfunc New() {}\n", CreatedAt: 10},
+			{ID: "5", SessionID: "s1", Path: edited, Version: "v2", Content: "package demo\n\nfunc Original() {}\n", CreatedAt: 11},
+			{ID: "6", SessionID: "s1", Path: edited, Version: "v3", Content: "package demo\n\nfunc Edited() {}\n", CreatedAt: 11},
+			// The file existed, empty, before the session wrote it.
+			{ID: "9", SessionID: "s1", Path: wasEmpty, Version: InitialVersion, Content: "", CreatedAt: 11},
+			{ID: "10", SessionID: "s1", Path: wasEmpty, Version: "v1", Content: "package demo\n", CreatedAt: 11},
+			// A subagent of the session deleted a file.
+			{ID: "7", SessionID: "s1-task", Path: deleted, Version: InitialVersion, Content: "package sub\n", CreatedAt: 12},
+			{ID: "8", SessionID: "s1-task", Path: deleted, Version: "v1", Content: "", CreatedAt: 12},
+		},
+	}
+
+	changes, err := SessionChanges(context.Background(), NewService(q, nil), "s1")
+	if err != nil {
+		t.Fatalf("SessionChanges: %v", err)
+	}
+	want := []FileChange{
+		{Path: created, Created: true},
+		{Path: edited, Original: "package demo\n\nfunc Original() {}\n"},
+		{Path: wasEmpty},
+		{Path: deleted, Original: "package sub\n"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("SessionChanges = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	if err := RestoreFileChanges(changes); err != nil {
+		t.Fatalf("RestoreFileChanges: %v", err)
+	}
+
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("created file still exists (stat error %v)", err)
+	}
+	for path, content := range map[string]string{
+		edited:    "package demo\n\nfunc Original() {}\n",
+		wasEmpty:  "",
+		deleted:   "package sub\n",
+		untouched: "package demo\n",
+	} {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Errorf("read %s: %v", path, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s = %q, want %q", path, got, content)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -29,12 +30,18 @@ type File struct {
 	Version   string
 	CreatedAt int64
 	UpdatedAt int64
+	// IsNew reports that the file did not exist when this version, the
+	// initial version of a file the session created, was captured.
+	IsNew bool
 }
 
 // Service provides operations for managing file history.
 type Service interface {
 	pubsub.Subscriber[File]
 	Create(ctx context.Context, sessionID, path, content string) (File, error)
+	// CreateNew stores the initial version of a file that did not exist
+	// before the session created it.
+	CreateNew(ctx context.Context, sessionID, path string) (File, error)
 	CreateVersion(ctx context.Context, sessionID, path, content string) (File, error)
 	Get(ctx context.Context, id string) (File, error)
 	GetByPathAndSession(ctx context.Context, path, sessionID string) (File, error)
@@ -45,6 +52,27 @@ type Service interface {
 	Update(ctx context.Context, file File) (File, error)
 	Delete(ctx context.Context, id string) error
 	DeleteSessionFiles(ctx context.Context, sessionID string) error
+	Prune(ctx context.Context, opts PruneOptions) (PruneResult, error)
+}
+
+// PruneOptions controls which file versions Prune removes.
+type PruneOptions struct {
+	// KeepVersions is the number of newest versions kept for each path of a
+	// session. The latest version and the initial version, the baseline that
+	// diffs and discards compare against, are always kept.
+	KeepVersions int
+	// OlderThan restricts pruning to versions created more than this long
+	// ago. Zero prunes regardless of age.
+	OlderThan time.Duration
+	// DryRun reports what would be pruned without deleting anything.
+	DryRun bool
+}
+
+// PruneResult describes the versions removed, or that would be removed in a
+// dry run, by Prune.
+type PruneResult struct {
+	Pruned         []File
+	ReclaimedBytes int64
 }
 
 type service struct {
@@ -63,7 +91,11 @@ func NewService(q db.QuerierWithTx, database *sql.DB) Service {
 }
 
 func (s *service) Create(ctx context.Context, sessionID, path, content string) (File, error) {
-	return s.createWithVersion(ctx, sessionID, path, content, InitialVersion)
+	return s.createWithVersion(ctx, sessionID, path, content, InitialVersion, false)
+}
+
+func (s *service) CreateNew(ctx context.Context, sessionID, path string) (File, error) {
+	return s.createWithVersion(ctx, sessionID, path, "", InitialVersion, true)
 }
 
 func (s *service) CreateVersion(ctx context.Context, sessionID, path, content string) (File, error) {
@@ -93,10 +125,10 @@ func (s *service) CreateVersion(ctx context.Context, sessionID, path, content st
 		nextVersion = fmt.Sprintf("v%d", latestFile.CreatedAt)
 	}
 
-	return s.createWithVersion(ctx, sessionID, path, content, nextVersion)
+	return s.createWithVersion(ctx, sessionID, path, content, nextVersion, false)
 }
 
-func (s *service) createWithVersion(ctx context.Context, sessionID, path, content, version string) (File, error) {
+func (s *service) createWithVersion(ctx context.Context, sessionID, path, content, version string, isNew bool) (File, error) {
 	const maxRetries = 3
 	var file File
 	var err error
@@ -116,6 +148,7 @@ func (s *service) createWithVersion(ctx context.Context, sessionID, path, conten
 			Path:      path,
 			Content:   content,
 			Version:   version,
+			IsNew:     isNew,
 		})
 		if txErr != nil {
 			if rbErr := tx.Rollback(); rbErr != nil {
@@ -282,6 +315,35 @@ func (s *service) DeleteSessionFiles(ctx context.Context, sessionID string) erro
 	return nil
 }
 
+// Prune removes old file versions across all sessions, keeping the newest
+// opts.KeepVersions versions and the initial version of every path in each
+// session.
+func (s *service) Prune(ctx context.Context, opts PruneOptions) (PruneResult, error) {
+	dbFiles, err := s.q.ListAllFiles(ctx)
+	if err != nil {
+		return PruneResult{}, fmt.Errorf("failed to list file history: %w", err)
+	}
+
+	var cutoff int64
+	if opts.OlderThan > 0 {
+		cutoff = time.Now().Add(-opts.OlderThan).Unix()
+	}
+
+	var result PruneResult
+	for _, dbFile := range prunableVersions(dbFiles, opts.KeepVersions, cutoff) {
+		file := s.fromDBItem(dbFile)
+		if !opts.DryRun {
+			if err := s.q.DeleteFile(ctx, file.ID); err != nil {
+				return result, fmt.Errorf("failed to delete version %s of %s: %w", file.Version, file.Path, err)
+			}
+			s.Publish(pubsub.DeletedEvent, file)
+		}
+		result.Pruned = append(result.Pruned, file)
+		result.ReclaimedBytes += int64(len(file.Content))
+	}
+	return result, nil
+}
+
 func (s *service) fromDBItem(item db.File) File {
 	return File{
 		ID:        item.ID,
@@ -291,6 +353,7 @@ func (s *service) fromDBItem(item db.File) File {
 		Version:   item.Version,
 		CreatedAt: item.CreatedAt,
 		UpdatedAt: item.UpdatedAt,
+		IsNew:     item.IsNew,
 	}
 }
 
@@ -350,3 +413,46 @@ func latestByPath(files []db.File) []db.File {
 	}
 	return result
 }
+
+// prunableVersions returns the versions that fall outside the newest keep
+// versions of their session's path and, when cutoff is set, were created
+// before it. Versions are ranked by version number, then creation time.
+// Initial versions are never returned.
+func prunableVersions(files []db.File, keep int, cutoff int64) []db.File {
+	keep = max(keep, 1)
+
+	type sessionPath struct{ sessionID, path string }
+	byPath := make(map[sessionPath][]db.File)
+	var paths []sessionPath
+	for _, f := range files {
+		key := sessionPath{f.SessionID, f.Path}
+		if _, exists := byPath[key]; !exists {
+			paths = append(paths, key)
+		}
+		byPath[key] = append(byPath[key], f)
+	}
+
+	var prunable []db.File
+	for _, p := range paths {
+		versions := byPath[p]
+		sort.SliceStable(versions, func(i, j int) bool {
+			ni, nj := parseVersionNum(versions[i].Version), parseVersionNum(versions[j].Version)
+			if ni != nj {
+				return ni > nj
+			}
+			return versions[i].CreatedAt > versions[j].CreatedAt
+		})
+		if len(versions) <= keep {
+			continue
+		}
+		for _, f := range versions[keep:] {
+			if f.Version == InitialVersion {
+				continue
+			}
+			if cutoff == 0 || f.CreatedAt < cutoff {
+				prunable = append(prunable, f)
+			}
+		}
+	}
+	return prunable
+}
@@ -1,7 +1,11 @@
 package history
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/db"
 )
@@ -153,3 +157,141 @@ func TestLatestByPath(t *testing.T) {
 		})
 	}
 }
+
+// pruneQuerier serves ListAllFiles and DeleteFile from memory; other Querier
+// methods are not used by Prune.
+type pruneQuerier struct {
+	db.QuerierWithTx
+	files []db.File
+}
+
+func (q *pruneQuerier) ListAllFiles(context.Context) ([]db.File, error) {
+	return slices.Clone(q.files), nil
+}
+
+func (q *pruneQuerier) DeleteFile(_ context.Context, id string) error {
+	q.files = slices.DeleteFunc(q.files, func(f db.File) bool { return f.ID == id })
+	return nil
+}
+
+func manyVersions(path string, count int, createdAt func(i int) int64) []db.File {
+	files := make([]db.File, count)
+	for i := range count {
+		version := InitialVersion
+		if i > 0 {
+			version = fmt.Sprintf("v%d", i)
+		}
+		files[i] = db.File{
+			ID:        fmt.Sprintf("%s-%d", path, i),
+			SessionID: "session",
+			Path:      path,
+			Version:   version,
+			Content:   "0123456789",
+			CreatedAt: createdAt(i),
+		}
+	}
+	return files
+}
+
+func TestPrune(t *testing.T) {
+	old := time.Now().Add(-60 * 24 * time.Hour).Unix()
+	recent := time.Now().Unix()
+
+	t.Run("keeps only the newest and initial versions per path", func(t *testing.T) {
+		files := append(
+			manyVersions("/a.go", 25, func(i int) int64 { return old + int64(i) }),
+			manyVersions("/b.go", 3, func(i int) int64 { return old + int64(i) })...,
+		)
+		q := &pruneQuerier{files: files}
+		svc := NewService(q, nil)
+
+		result, err := svc.Prune(context.Background(), PruneOptions{KeepVersions: 10, OlderThan: 30 * 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if len(result.Pruned) != 14 {
+			t.Errorf("pruned %d versions, want 14", len(result.Pruned))
+		}
+		if result.ReclaimedBytes != 140 {
+			t.Errorf("ReclaimedBytes = %d, want 140", result.ReclaimedBytes)
+		}
+
+		var remainingA []string
+		remainingB := 0
+		for _, f := range q.files {
+			switch f.Path {
+			case "/a.go":
+				remainingA = append(remainingA, f.Version)
+			case "/b.go":
+				remainingB++
+			}
+		}
+		wantA := []string{InitialVersion, "v15", "v16", "v17", "v18", "v19", "v20", "v21", "v22", "v23", "v24"}
+		slices.Sort(remainingA)
+		slices.Sort(wantA)
+		if !slices.Equal(remainingA, wantA) {
+			t.Errorf("remaining /a.go versions = %v, want %v", remainingA, wantA)
+		}
+		if remainingB != 3 {
+			t.Errorf("remaining /b.go versions = %d, want 3", remainingB)
+		}
+	})
+
+	t.Run("recent versions survive the age limit", func(t *testing.T) {
+		files := manyVersions("/a.go", 20, func(i int) int64 {
+			if i < 5 {
+				return old
+			}
+			return recent
+		})
+		q := &pruneQuerier{files: files}
+
+		result, err := NewService(q, nil).Prune(context.Background(), PruneOptions{KeepVersions: 10, OlderThan: 30 * 24 * time.Hour})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if len(result.Pruned) != 4 {
+			t.Errorf("pruned %d versions, want the 4 old ones after the initial version", len(result.Pruned))
+		}
+		if len(q.files) != 16 {
+			t.Errorf("%d versions remain, want 16", len(q.files))
+		}
+	})
+
+	t.Run("dry run deletes nothing", func(t *testing.T) {
+		q := &pruneQuerier{files: manyVersions("/a.go", 12, func(i int) int64 { return old })}
+
+		result, err := NewService(q, nil).Prune(context.Background(), PruneOptions{KeepVersions: 10, DryRun: true})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		if len(result.Pruned) != 1 {
+			t.Errorf("would prune %d versions, want 1", len(result.Pruned))
+		}
+		if len(q.files) != 12 {
+			t.Errorf("dry run deleted versions: %d remain, want 12", len(q.files))
+		}
+	})
+	t.Run("ranks versions per session", func(t *testing.T) {
+		files := manyVersions("/a.go", 3, func(i int) int64 { return old + int64(i) })
+		other := manyVersions("/a.go", 3, func(i int) int64 { return old + int64(i) })
+		for i := range other {
+			other[i].ID = "other-" + other[i].ID
+			other[i].SessionID = "other"
+		}
+		q := &pruneQuerier{files: append(files, other...)}
+
+		result, err := NewService(q, nil).Prune(context.Background(), PruneOptions{KeepVersions: 1})
+		if err != nil {
+			t.Fatalf("Prune() error = %v", err)
+		}
+		var pruned []string
+		for _, f := range result.Pruned {
+			pruned = append(pruned, f.SessionID+":"+f.Version)
+		}
+		slices.Sort(pruned)
+		if want := []string{"other:v1", "session:v1"}; !slices.Equal(pruned, want) {
+			t.Errorf("pruned %v, want %v", pruned, want)
+		}
+	})
+}
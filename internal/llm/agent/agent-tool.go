@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
@@ -21,6 +22,9 @@ type agentTool struct {
 	permissions permission.Service
 	registry    agentregistry.Registry
 	factory     AgentFactory
+
+	// costMu serializes parent session cost updates from parallel tasks.
+	costMu sync.Mutex
 }
 
 const (
@@ -172,20 +176,8 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 	}
 	logging.Debug("Task completed", "subagent", subagentType, "structured", isStructOutput, "error", result.Error)
 
-	updatedSession, err := b.sessions.Get(ctx, taskSession.ID)
-	if err != nil {
-		return tools.ToolResponse{}, fmt.Errorf("error getting session: %w", err)
-	}
-	parentSession, err := b.sessions.Get(ctx, sessionID)
-	if err != nil {
-		return tools.ToolResponse{}, fmt.Errorf("error getting parent session: %w", err)
-	}
-
-	parentSession.Cost += updatedSession.Cost
-
-	_, err = b.sessions.Save(ctx, parentSession)
-	if err != nil {
-		return tools.ToolResponse{}, fmt.Errorf("error saving parent session: %w", err)
+	if err := b.addCostToParent(ctx, taskSession.ID, sessionID); err != nil {
+		return tools.ToolResponse{}, err
 	}
 
 	agentName := subagentType
@@ -204,6 +196,28 @@ func (b *agentTool) Run(ctx context.Context, call tools.ToolCall) (tools.ToolRes
 		}), nil
 }
 
+// addCostToParent adds the task session's cost to its parent session.
+func (b *agentTool) addCostToParent(ctx context.Context, taskSessionID, parentSessionID string) error {
+	b.costMu.Lock()
+	defer b.costMu.Unlock()
+
+	updatedSession, err := b.sessions.Get(ctx, taskSessionID)
+	if err != nil {
+		return fmt.Errorf("error getting session: %w", err)
+	}
+	parentSession, err := b.sessions.Get(ctx, parentSessionID)
+	if err != nil {
+		return fmt.Errorf("error getting parent session: %w", err)
+	}
+
+	parentSession.Cost += updatedSession.Cost
+
+	if _, err := b.sessions.Save(ctx, parentSession); err != nil {
+		return fmt.Errorf("error saving parent session: %w", err)
+	}
+	return nil
+}
+
 func NewAgentTool(
 	sessions session.Service,
 	permissions permission.Service,
@@ -76,8 +76,9 @@ type Service interface {
 
 type agent struct {
 	*pubsub.Broker[AgentEvent]
-	sessions session.Service
-	messages message.Service
+	sessions    session.Service
+	messages    message.Service
+	permissions permission.Service
 
 	agentID   config.AgentName
 	toolsCh   <-chan tools.BaseTool
@@ -88,6 +89,18 @@ type agent struct {
 	titleProvider     provider.Provider
 	summarizeProvider provider.Provider
 
+	// routes send requests matching a routing rule to another model, whose
+	// providers are created on first use.
+	routes          []modelRoute
+	routedMu        sync.Mutex
+	routedProviders map[models.ModelID]provider.Provider
+
+	// maxParallelTools caps how many independent tool calls run at once.
+	maxParallelTools int
+	// maxToolIterations caps the rounds of tool calls in one turn; zero
+	// uses the global maxToolIterations.
+	maxToolIterations int
+
 	activeRequests sync.Map
 }
 
@@ -128,9 +141,13 @@ func newAgent(
 		provider:          agentProvider,
 		messages:          messages,
 		sessions:          sessions,
+		permissions:       permissions,
 		toolsCh:           agentTools,
 		titleProvider:     titleProvider,
 		summarizeProvider: summarizeProvider,
+		maxParallelTools:  agentInfo.MaxParallelTools,
+		maxToolIterations: agentInfo.MaxToolIterations,
+		routes:            agentRoutes(agentInfo.ID),
 		activeRequests:    sync.Map{},
 	}
 
@@ -306,11 +323,13 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	}
 	ctx = context.WithValue(ctx, tools.SessionIDContextKey, sessionID)
 	ctx = context.WithValue(ctx, tools.AgentIDContextKey, a.AgentID())
+	ctx = withSkillScope(ctx)
 
 	userMsg, err := a.createUserMessage(ctx, sessionID, content, attachmentParts)
 	if err != nil {
 		return a.err(fmt.Errorf("failed to create user message: %w", err))
 	}
+	ctx = context.WithValue(ctx, tools.TurnIDContextKey, userMsg.ID)
 	// Append the new user message to the conversation history.
 	msgHistory := append(msgs, userMsg)
 	var agentMessage message.Message
@@ -319,7 +338,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 	structOutputIsErr := true
 	cycles := 0
 	consecutiveEmptyCycles := 0
-	const maxCycles = 20                // Prevent infinite loops
+	maxCycles := a.toolIterationLimit() // Prevent infinite loops
 	const maxConsecutiveEmptyCycles = 3 // Exit if model keeps returning empty tool inputs
 
 	// Suspend to get lazy tools
@@ -335,7 +354,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			// Continue processing
 		}
 
-		etaTokens, shouldTriggerAutoCompaction := a.provider.CountTokens(ctx, AutoCompactionThreshold, msgHistory, toolSet)
+		requestProvider, etaTokens, shouldTriggerAutoCompaction := a.measureRequest(ctx, msgHistory, toolSet)
 		// Check if auto-compaction should be triggered before each model call
 		// This is crucial for long tool use loops that can exceed context limits
 		// NOTE: since tool may provide output exceeding context limit when combined with existing history,
@@ -376,7 +395,7 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 					msgHistory = append(msgs, userMsg)
 				}
 
-				etaTokens, shouldTriggerAutoCompaction = a.provider.CountTokens(ctx, AutoCompactionThreshold, msgHistory, toolSet)
+				requestProvider, etaTokens, shouldTriggerAutoCompaction = a.measureRequest(ctx, msgHistory, toolSet)
 				if shouldTriggerAutoCompaction {
 					logging.Warn(
 						"Context compacted, but still exceed context threshold",
@@ -397,10 +416,16 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			}
 		}
 
+		if reason, err := a.checkBudget(ctx, sessionID, requestProvider.Model(), etaTokens); err != nil {
+			return a.err(err)
+		} else if reason != "" {
+			return a.haltForBudget(ctx, sessionID, reason)
+		}
+
 		// Ensure we don't run into API limitation (max_token to be generated + current tokens count)
-		a.provider.AdjustMaxTokens(etaTokens)
+		requestProvider.AdjustMaxTokens(etaTokens)
 
-		agentMessage, toolResults, err = a.streamAndHandleEvents(ctx, sessionID, msgHistory, toolSet)
+		agentMessage, toolResults, err = a.streamAndHandleEvents(ctx, requestProvider, sessionID, msgHistory, toolSet)
 		if err != nil {
 			a.createErrorToolResults(ctx, agentMessage)
 			if errors.Is(err, context.Canceled) {
@@ -467,11 +492,10 @@ func (a *agent) processGeneration(ctx context.Context, sessionID, content string
 			// Prevent infinite loops: exit if max cycles reached
 			if cycles >= maxCycles {
 				logging.Error("Max cycles reached, terminating tool use loop", "cycles", cycles, "maxCycles", maxCycles)
-				return AgentEvent{
-					Type:    AgentEventTypeResponse,
-					Message: agentMessage,
-					Done:    true,
-				}
+				return a.haltTurn(ctx, sessionID, fmt.Sprintf(
+					"Stopped after %d rounds of tool calls in this turn (maxToolIterations). Send another message to continue.",
+					maxCycles,
+				), message.FinishReasonMaxToolIterations)
 			}
 
 			// Exit early if model keeps returning empty tool inputs (likely rate limited)
@@ -504,13 +528,13 @@ func (a *agent) createUserMessage(ctx context.Context, sessionID, content string
 	})
 }
 
-func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msgHistory []message.Message, toolSet []tools.BaseTool) (message.Message, *message.Message, error) {
-	eventChan := a.provider.StreamResponse(ctx, msgHistory, toolSet)
+func (a *agent) streamAndHandleEvents(ctx context.Context, requestProvider provider.Provider, sessionID string, msgHistory []message.Message, toolSet []tools.BaseTool) (message.Message, *message.Message, error) {
+	eventChan := requestProvider.StreamResponse(ctx, msgHistory, toolSet)
 
 	assistantMsg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
 		Role:  message.Assistant,
 		Parts: []message.ContentPart{},
-		Model: a.provider.Model().ID,
+		Model: requestProvider.Model().ID,
 	})
 	if err != nil {
 		return assistantMsg, nil, fmt.Errorf("failed to create assistant message: %w", err)
@@ -520,7 +544,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 
 	// Process provider response first
 	for event := range eventChan {
-		if processErr := a.processEvent(ctx, sessionID, &assistantMsg, event); processErr != nil {
+		if processErr := a.processEvent(ctx, sessionID, requestProvider.Model(), &assistantMsg, event); processErr != nil {
 			return assistantMsg, nil, processErr
 		}
 		if ctx.Err() != nil {
@@ -529,8 +553,15 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 	}
 
 	// Process tool calls
-	toolResults := make([]message.ToolResult, len(assistantMsg.ToolCalls()))
 	toolCalls := assistantMsg.ToolCalls()
+	if !a.approveToolCallPlan(sessionID, toolCalls, toolSet) {
+		toolMsg, err := a.rejectToolCallPlan(ctx, &assistantMsg)
+		return assistantMsg, toolMsg, err
+	}
+	toolResults := make([]message.ToolResult, len(toolCalls))
+	var attachments []message.BinaryContent
+	// Tool calls run up front, concurrently where they are independent
+	callResults := runToolCallBatch(ctx, toolSet, toolCalls, a.maxParallelTools)
 	for i, toolCall := range toolCalls {
 		select {
 		case <-ctx.Done():
@@ -579,23 +610,17 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 					"input_preview", toolCallInput,
 				)
 				toolResults[i] = message.ToolResult{
-					ToolCallID: toolCall.ID,
-					Name:       toolCall.Name,
-					Content:    "Error: model returned empty or malformed tool input (possible rate limiting or provider streaming issue)",
-					IsError:    true,
+					ToolCallID:  toolCall.ID,
+					Name:        toolCall.Name,
+					Content:     "Error: model returned empty or malformed tool input (possible rate limiting or provider streaming issue)",
+					IsError:     true,
+					ErrorDetail: &tools.ToolErrorDetail{Code: tools.ErrorCodeParseError},
 				}
 				continue
 			}
 
-			now := time.Now()
-
-			// TODO: add parallelism so tool calls can run concurrently (at least for Task tool)
-			toolResult, toolErr := tool.Run(ctx, tools.ToolCall{
-				ID:    toolCall.ID,
-				Name:  toolCall.Name,
-				Input: toolCall.Input,
-			})
-			gauge := time.Since(now).Milliseconds()
+			toolResult, toolErr := callResults[i].response, callResults[i].err
+			gauge := callResults[i].duration.Milliseconds()
 			if toolErr != nil {
 				if errors.Is(toolErr, permission.ErrorPermissionDenied) {
 					logging.Warn("Tool call denied", "tool", toolCall.Name,
@@ -604,12 +629,27 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 						"gauge", gauge,
 					)
 					toolResults[i] = message.ToolResult{
-						ToolCallID: toolCall.ID,
-						Name:       toolCall.Name,
-						Content:    "Permission denied",
-						IsError:    true,
+						ToolCallID:  toolCall.ID,
+						Name:        toolCall.Name,
+						Content:     "Permission denied",
+						IsError:     true,
+						ErrorDetail: &tools.ToolErrorDetail{Code: tools.ErrorCodePermissionDenied},
 					}
 					for j := i + 1; j < len(toolCalls); j++ {
+						// Calls that already ran concurrently keep their result
+						if result, ok := callResults[j]; ok && result.started && result.err == nil {
+							toolResults[j] = message.ToolResult{
+								Type:        message.ToolResultType(result.response.Type),
+								Name:        toolCalls[j].Name,
+								ToolCallID:  toolCalls[j].ID,
+								Content:     result.response.Content,
+								Metadata:    result.response.Metadata,
+								IsError:     result.response.IsError,
+								ErrorDetail: result.response.ErrorDetail,
+							}
+							attachments = append(attachments, toolAttachments(result.response)...)
+							continue
+						}
 						toolResults[j] = message.ToolResult{
 							ToolCallID: toolCalls[j].ID,
 							Name:       toolCalls[j].Name,
@@ -618,7 +658,7 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 						}
 					}
 					a.finishMessage(ctx, &assistantMsg, message.FinishReasonPermissionDenied)
-					break
+					goto out
 				} else {
 					logging.Error("Tool call failed", "tool", toolCall.Name,
 						"ID", toolCall.ID,
@@ -641,14 +681,19 @@ func (a *agent) streamAndHandleEvents(ctx context.Context, sessionID string, msg
 				"successful", !toolResult.IsError,
 				"gauge", gauge,
 			)
+			if toolResult.Type == tools.ToolResponseTypeText && !toolResult.IsError {
+				toolResult.Content = a.condenseToolOutput(ctx, sessionID, requestProvider.Model(), toolCall.Name, toolResult.Content)
+			}
 			toolResults[i] = message.ToolResult{
-				Type:       message.ToolResultType(toolResult.Type),
-				Name:       toolCall.Name,
-				ToolCallID: toolCall.ID,
-				Content:    toolResult.Content,
-				Metadata:   toolResult.Metadata,
-				IsError:    toolResult.IsError,
+				Type:        message.ToolResultType(toolResult.Type),
+				Name:        toolCall.Name,
+				ToolCallID:  toolCall.ID,
+				Content:     toolResult.Content,
+				Metadata:    toolResult.Metadata,
+				IsError:     toolResult.IsError,
+				ErrorDetail: toolResult.ErrorDetail,
 			}
+			attachments = append(attachments, toolAttachments(toolResult)...)
 		}
 	}
 out:
@@ -659,6 +704,9 @@ out:
 	for _, tr := range toolResults {
 		parts = append(parts, tr)
 	}
+	for _, attachment := range attachments {
+		parts = append(parts, attachment)
+	}
 	msg, err := a.messages.Create(ctx, assistantMsg.SessionID, message.CreateMessageParams{
 		Role:  message.Tool,
 		Parts: parts,
@@ -670,6 +718,53 @@ out:
 	return assistantMsg, &msg, nil
 }
 
+// toolAttachments converts the attachments of a successful tool response to
+// binary message parts, which follow the tool results in the tool message.
+func toolAttachments(response tools.ToolResponse) []message.BinaryContent {
+	if response.IsError {
+		return nil
+	}
+	parts := make([]message.BinaryContent, 0, len(response.Attachments))
+	for _, attachment := range response.Attachments {
+		parts = append(parts, message.BinaryContent{
+			Path:     attachment.Path,
+			MIMEType: attachment.MIMEType,
+			Data:     attachment.Data,
+		})
+	}
+	return parts
+}
+
+// toolIterationLimit returns how many rounds of tool calls a turn may make:
+// the agent's maxToolIterations, else the global one, else the default.
+func (a *agent) toolIterationLimit() int {
+	if a.maxToolIterations > 0 {
+		return a.maxToolIterations
+	}
+	if cfg := config.Get(); cfg != nil && cfg.MaxToolIterations > 0 {
+		return cfg.MaxToolIterations
+	}
+	return config.DefaultMaxToolIterations
+}
+
+// haltTurn ends a turn early with an assistant message explaining why.
+func (a *agent) haltTurn(ctx context.Context, sessionID, text string, reason message.FinishReason) AgentEvent {
+	msg, err := a.messages.Create(ctx, sessionID, message.CreateMessageParams{
+		Role:  message.Assistant,
+		Parts: []message.ContentPart{message.TextContent{Text: text}},
+		Model: a.provider.Model().ID,
+	})
+	if err != nil {
+		return a.err(fmt.Errorf("failed to create %s message: %w", reason, err))
+	}
+	a.finishMessage(ctx, &msg, reason)
+	return AgentEvent{
+		Type:    AgentEventTypeResponse,
+		Message: msg,
+		Done:    true,
+	}
+}
+
 func (a *agent) finishMessage(ctx context.Context, msg *message.Message, finishReason message.FinishReason) {
 	msg.AddFinish(finishReason)
 	_ = a.messages.Update(ctx, *msg)
@@ -725,7 +820,7 @@ func (a *agent) mergeToolCalls(assistantMsg *message.Message, accumulated []mess
 	}
 }
 
-func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg *message.Message, event provider.ProviderEvent) error {
+func (a *agent) processEvent(ctx context.Context, sessionID string, model models.Model, assistantMsg *message.Message, event provider.ProviderEvent) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -735,7 +830,7 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 
 	switch event.Type {
 	case provider.EventThinkingDelta:
-		assistantMsg.AppendReasoningContent(event.Content)
+		assistantMsg.AppendReasoningContent(event.Thinking)
 		return a.messages.Update(ctx, *assistantMsg)
 	case provider.EventContentDelta:
 		assistantMsg.AppendContent(event.Content)
@@ -773,24 +868,30 @@ func (a *agent) processEvent(ctx context.Context, sessionID string, assistantMsg
 		if err := a.messages.Update(ctx, *assistantMsg); err != nil {
 			return fmt.Errorf("failed to update message: %w", err)
 		}
-		return a.TrackUsage(ctx, sessionID, a.provider.Model(), event.Response.Usage)
+		return a.TrackUsage(ctx, sessionID, model, event.Response.Usage)
 	}
 
 	return nil
 }
 
+// usageCost returns the estimated cost in USD of usage with model.
+func usageCost(model models.Model, usage provider.TokenUsage) float64 {
+	return model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
+		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
+		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
+		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
+}
+
 func (a *agent) TrackUsage(ctx context.Context, sessionID string, model models.Model, usage provider.TokenUsage) error {
 	sess, err := a.sessions.Get(ctx, sessionID)
 	if err != nil {
 		return fmt.Errorf("failed to get session: %w", err)
 	}
 
-	cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
+	cost := usageCost(model, usage)
 
 	sess.Cost += cost
+	addTokenTotals(&sess, usage)
 	sess.CompletionTokens = usage.OutputTokens + usage.CacheReadTokens
 	sess.PromptTokens = usage.InputTokens + usage.CacheCreationTokens
 
@@ -826,6 +927,10 @@ func (a *agent) Update(agentName config.AgentName, modelID models.ModelID) (mode
 	}
 
 	a.provider = provider
+	a.routes = agentRoutes(agentName)
+	a.routedMu.Lock()
+	a.routedProviders = nil
+	a.routedMu.Unlock()
 
 	return a.provider.Model(), nil
 }
@@ -941,16 +1046,15 @@ func (a *agent) performSynchronousCompaction(ctx context.Context, sessionID stri
 	oldSession.PromptTokens = 0
 	model := a.summarizeProvider.Model()
 	usage := response.Usage
-	cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-		model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-		model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-		model.CostPer1MOut/1e6*float64(usage.OutputTokens)
+	cost := usageCost(model, usage)
 	oldSession.Cost += cost
+	addTokenTotals(&oldSession, usage)
 
 	_, err = a.sessions.Save(summarizeCtx, oldSession)
 	if err != nil {
 		return fmt.Errorf("failed to save session: %w", err)
 	}
+	tools.ForgetFileViews(sessionID)
 
 	logging.Info("Synchronous compaction completed successfully", "session_id", sessionID)
 	return nil
@@ -1107,11 +1211,9 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 		oldSession.PromptTokens = 0
 		model := a.summarizeProvider.Model()
 		usage := response.Usage
-		cost := model.CostPer1MInCached/1e6*float64(usage.CacheCreationTokens) +
-			model.CostPer1MOutCached/1e6*float64(usage.CacheReadTokens) +
-			model.CostPer1MIn/1e6*float64(usage.InputTokens) +
-			model.CostPer1MOut/1e6*float64(usage.OutputTokens)
+		cost := usageCost(model, usage)
 		oldSession.Cost += cost
+		addTokenTotals(&oldSession, usage)
 		_, err = a.sessions.Save(summarizeCtx, oldSession)
 		if err != nil {
 			event = AgentEvent{
@@ -1121,6 +1223,7 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 			}
 			a.Publish(pubsub.CreatedEvent, event)
 		}
+		tools.ForgetFileViews(sessionID)
 
 		event = AgentEvent{
 			Type:      AgentEventTypeSummarize,
@@ -1135,7 +1238,14 @@ func (a *agent) Summarize(ctx context.Context, sessionID string) error {
 	return nil
 }
 
-func createAgentProvider(agentName config.AgentName) (agentProvider provider.Provider, err error) {
+func createAgentProvider(agentName config.AgentName) (provider.Provider, error) {
+	return createAgentProviderWithModel(agentName, "")
+}
+
+// createAgentProviderWithModel creates the provider for agentName, using
+// modelID instead of the configured model when it is set. A replaced model
+// uses its own default max tokens.
+func createAgentProviderWithModel(agentName config.AgentName, modelID models.ModelID) (agentProvider provider.Provider, err error) {
 	defer func() {
 		if err == nil {
 			logging.Info("Agent provider created", "agent", agentName, "model", agentProvider.Model())
@@ -1159,14 +1269,22 @@ func createAgentProvider(agentName config.AgentName) (agentProvider provider.Pro
 				return nil, fmt.Errorf("agent %s has no model and coder agent not configured", agentName)
 			}
 			agentConfig = config.Agent{
-				Model:           coderCfg.Model,
-				MaxTokens:       coderCfg.MaxTokens,
-				ReasoningEffort: coderCfg.ReasoningEffort,
+				Model:              coderCfg.Model,
+				MaxTokens:          coderCfg.MaxTokens,
+				MaxReasoningTokens: coderCfg.MaxReasoningTokens,
+				ReasoningEffort:    coderCfg.ReasoningEffort,
+				Temperature:        coderCfg.Temperature,
+				TopP:               coderCfg.TopP,
+				StopSequences:      coderCfg.StopSequences,
 			}
 		} else {
 			return nil, fmt.Errorf("agent %s not found", agentName)
 		}
 	}
+	if modelID != "" {
+		agentConfig.Model = modelID
+		agentConfig.MaxTokens = 0
+	}
 	model, ok := models.SupportedModels[agentConfig.Model]
 	if !ok {
 		return nil, fmt.Errorf("model %s not supported", agentConfig.Model)
@@ -1196,6 +1314,30 @@ func createAgentProvider(agentName config.AgentName) (agentProvider provider.Pro
 	if len(providerCfg.Headers) != 0 {
 		opts = append(opts, provider.WithHeaders(providerCfg.Headers))
 	}
+	if len(providerCfg.ModelMap) != 0 {
+		opts = append(opts, provider.WithModelMap(providerCfg.ModelMap))
+	}
+	if providerCfg.DisableStreaming {
+		opts = append(opts, provider.WithDisableStreaming(true))
+	}
+	if providerCfg.IdempotencyKey {
+		opts = append(opts, provider.WithIdempotencyKey(true))
+	}
+	if providerCfg.UserAgent != "" {
+		opts = append(opts, provider.WithUserAgent(providerCfg.UserAgent))
+	}
+	if agentConfig.Temperature != nil {
+		opts = append(opts, provider.WithTemperature(*agentConfig.Temperature))
+	}
+	if agentConfig.TopP != nil {
+		opts = append(opts, provider.WithTopP(*agentConfig.TopP))
+	}
+	if len(agentConfig.StopSequences) != 0 {
+		opts = append(opts, provider.WithStopSequences(agentConfig.StopSequences...))
+	}
+	if agentConfig.MaxReasoningTokens > 0 {
+		opts = append(opts, provider.WithMaxReasoningTokens(agentConfig.MaxReasoningTokens))
+	}
 
 	if model.Provider == models.ProviderOpenAI || model.Provider == models.ProviderLocal && model.CanReason {
 		opts = append(
@@ -1215,6 +1357,13 @@ func createAgentProvider(agentName config.AgentName) (agentProvider provider.Pro
 			opts,
 			provider.WithAnthropicOptions(anthropicOpts...),
 		)
+	} else if model.Provider == models.ProviderXAI && model.CanReason {
+		opts = append(
+			opts,
+			provider.WithXAIOptions(
+				provider.WithXAIReasoningEffort(agentConfig.ReasoningEffort),
+			),
+		)
 	}
 	agentProvider, err = provider.NewProvider(
 		model.Provider,
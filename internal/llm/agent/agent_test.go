@@ -2,11 +2,28 @@ package agent
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/metrics"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/session"
 )
 
+func TestMain(m *testing.M) {
+	// Tool runs are measured; keep their metrics out of the package directory
+	metrics.SetDefault(metrics.NewCollector(""))
+	os.Exit(m.Run())
+}
+
 // TestAgentID tests the AgentID method - tests the basic getter functionality
 func TestAgentID(t *testing.T) {
 	// Test that AgentID returns the correct agent ID
@@ -242,3 +259,338 @@ func TestAutoCompactionThreshold(t *testing.T) {
 		t.Errorf("AutoCompactionThreshold = %v, want 0.95", AutoCompactionThreshold)
 	}
 }
+
+// memMessages keeps messages in memory; only the methods used by a turn are
+// implemented.
+type memMessages struct {
+	message.Service
+	mu   sync.Mutex
+	msgs []message.Message
+}
+
+func (m *memMessages) Create(_ context.Context, sessionID string, params message.CreateMessageParams) (message.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	msg := message.Message{
+		ID:        fmt.Sprintf("msg-%d", len(m.msgs)+1),
+		SessionID: sessionID,
+		Role:      params.Role,
+		Parts:     params.Parts,
+		Model:     params.Model,
+	}
+	m.msgs = append(m.msgs, msg)
+	return msg, nil
+}
+
+func (m *memMessages) Update(_ context.Context, msg message.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.msgs {
+		if m.msgs[i].ID == msg.ID {
+			m.msgs[i] = msg
+		}
+	}
+	return nil
+}
+
+func (m *memMessages) List(_ context.Context, sessionID string) ([]message.Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var msgs []message.Message
+	for _, msg := range m.msgs {
+		if msg.SessionID == sessionID {
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs, nil
+}
+
+// memSessions serves a single session from memory.
+type memSessions struct {
+	session.Service
+	sess session.Session
+}
+
+func (s *memSessions) Get(context.Context, string) (session.Session, error) {
+	return s.sess, nil
+}
+
+func (s *memSessions) Save(_ context.Context, sess session.Session) (session.Session, error) {
+	s.sess = sess
+	return sess, nil
+}
+
+// toolLoopProvider requests another call of tool, echo by default, on every
+// turn.
+type toolLoopProvider struct {
+	provider.Provider
+	calls atomic.Int32
+	tool  string
+}
+
+func (p *toolLoopProvider) StreamResponse(context.Context, []message.Message, []tools.BaseTool) <-chan provider.ProviderEvent {
+	n := p.calls.Add(1)
+	name := p.tool
+	if name == "" {
+		name = "echo"
+	}
+	toolCall := message.ToolCall{ID: fmt.Sprintf("call-%d", n), Name: name, Input: `{}`, Type: "function"}
+	events := make(chan provider.ProviderEvent, 3)
+	events <- provider.ProviderEvent{Type: provider.EventToolUseStart, ToolCall: &toolCall}
+	events <- provider.ProviderEvent{Type: provider.EventToolUseStop, ToolCall: &toolCall}
+	events <- provider.ProviderEvent{Type: provider.EventComplete, Response: &provider.ProviderResponse{
+		ToolCalls:    []message.ToolCall{toolCall},
+		FinishReason: message.FinishReasonToolUse,
+	}}
+	close(events)
+	return events
+}
+
+func (p *toolLoopProvider) CountTokens(context.Context, float64, []message.Message, []tools.BaseTool) (int64, bool) {
+	return 0, false
+}
+
+func (p *toolLoopProvider) AdjustMaxTokens(int64) int64 { return 0 }
+
+func (p *toolLoopProvider) Model() models.Model { return models.Model{} }
+
+type echoTool struct{}
+
+func (echoTool) Info() tools.ToolInfo { return tools.ToolInfo{Name: "echo"} }
+
+func (echoTool) Run(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+	return tools.NewTextResponse("echo"), nil
+}
+
+func TestProcessGenerationStopsAtMaxToolIterations(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	defer func() { cfg.MaxToolIterations = 0 }()
+
+	tests := []struct {
+		name             string
+		global, perAgent int
+		want             int
+	}{
+		{name: "default", want: config.DefaultMaxToolIterations},
+		{name: "global", global: 3, want: 3},
+		{name: "agent override", global: 3, perAgent: 5, want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.MaxToolIterations = tt.global
+			toolsCh := make(chan tools.BaseTool, 1)
+			toolsCh <- echoTool{}
+			close(toolsCh)
+			p := &toolLoopProvider{}
+			messages := &memMessages{}
+			a := &agent{
+				agentID:           config.AgentCoder,
+				provider:          p,
+				messages:          messages,
+				sessions:          &memSessions{sess: session.Session{ID: "loop"}},
+				toolsCh:           toolsCh,
+				maxToolIterations: tt.perAgent,
+			}
+
+			event := a.processGeneration(t.Context(), "loop", "keep going", nil)
+
+			if event.Error != nil {
+				t.Fatalf("processGeneration() error = %v", event.Error)
+			}
+			if got := int(p.calls.Load()); got != tt.want {
+				t.Errorf("provider called %d times, want %d", got, tt.want)
+			}
+			if got := event.Message.FinishReason(); got != message.FinishReasonMaxToolIterations {
+				t.Errorf("FinishReason() = %q, want %q", got, message.FinishReasonMaxToolIterations)
+			}
+			if !strings.Contains(event.Message.Content().String(), "maxToolIterations") {
+				t.Errorf("halt message %q does not mention maxToolIterations", event.Message.Content().String())
+			}
+		})
+	}
+}
+
+// chartTool returns a text summary together with a PNG attachment.
+type chartTool struct{}
+
+var chartPNG = []byte("\x89PNG\r\n\x1a\nchart")
+
+func (chartTool) Info() tools.ToolInfo { return tools.ToolInfo{Name: "chart"} }
+
+func (chartTool) Run(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+	return tools.WithResponseAttachments(tools.NewTextResponse("Revenue grew 12%"),
+		tools.ToolAttachment{Path: "chart.png", MIMEType: "image/png", Data: chartPNG}), nil
+}
+
+func TestProcessGenerationToolAttachments(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	toolsCh := make(chan tools.BaseTool, 1)
+	toolsCh <- chartTool{}
+	close(toolsCh)
+	messages := &memMessages{}
+	a := &agent{
+		agentID:           config.AgentCoder,
+		provider:          &toolLoopProvider{tool: "chart"},
+		messages:          messages,
+		sessions:          &memSessions{sess: session.Session{ID: "chart"}},
+		toolsCh:           toolsCh,
+		maxToolIterations: 1,
+	}
+
+	if event := a.processGeneration(t.Context(), "chart", "plot revenue", nil); event.Error != nil {
+		t.Fatalf("processGeneration() error = %v", event.Error)
+	}
+
+	var toolMsg *message.Message
+	for i := range messages.msgs {
+		if messages.msgs[i].Role == message.Tool {
+			toolMsg = &messages.msgs[i]
+			break
+		}
+	}
+	if toolMsg == nil {
+		t.Fatal("no tool message was created")
+	}
+	results := toolMsg.ToolResults()
+	if len(results) != 1 || results[0].Content != "Revenue grew 12%" {
+		t.Errorf("ToolResults() = %+v, want the text summary", results)
+	}
+	binary := toolMsg.BinaryContent()
+	if len(binary) != 1 {
+		t.Fatalf("BinaryContent() has %d parts, want 1", len(binary))
+	}
+	if binary[0].MIMEType != "image/png" || string(binary[0].Data) != string(chartPNG) || binary[0].Path != "chart.png" {
+		t.Errorf("BinaryContent() = %+v, want the PNG attachment", binary[0])
+	}
+}
+
+// sizedProvider is a toolLoopProvider for model that estimates every prompt
+// at tokens, reporting full when the estimate crosses its compaction
+// threshold.
+type sizedProvider struct {
+	toolLoopProvider
+	model  models.ModelID
+	tokens int64
+	full   bool
+}
+
+func (p *sizedProvider) CountTokens(context.Context, float64, []message.Message, []tools.BaseTool) (int64, bool) {
+	return p.tokens, p.full
+}
+
+func (p *sizedProvider) Model() models.Model { return models.Model{ID: p.model} }
+
+func TestProcessGenerationRouting(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	coder := cfg.Agents[config.AgentCoder]
+	cfg.Agents[config.AgentCoder] = config.Agent{
+		Model:   models.Claude4Sonnet,
+		Routing: []config.RoutingRule{{When: "tokens>50000", Model: models.Gemini25}},
+	}
+	defer func() { cfg.Agents[config.AgentCoder] = coder }()
+
+	tests := []struct {
+		name      string
+		tokens    int64
+		wantModel models.ModelID
+	}{
+		{name: "small request stays on the default", tokens: 1000, wantModel: models.Claude4Sonnet},
+		{name: "large request routes to the big-context model", tokens: 60000, wantModel: models.Gemini25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolsCh := make(chan tools.BaseTool, 1)
+			toolsCh <- echoTool{}
+			close(toolsCh)
+			defaultProvider := &sizedProvider{model: models.Claude4Sonnet, tokens: tt.tokens}
+			bigProvider := &sizedProvider{model: models.Gemini25, tokens: tt.tokens}
+			messages := &memMessages{}
+			a := &agent{
+				agentID:           config.AgentCoder,
+				provider:          defaultProvider,
+				messages:          messages,
+				sessions:          &memSessions{sess: session.Session{ID: "routing"}},
+				toolsCh:           toolsCh,
+				maxToolIterations: 1,
+				routes:            agentRoutes(config.AgentCoder),
+				routedProviders:   map[models.ModelID]provider.Provider{models.Gemini25: bigProvider},
+			}
+
+			if event := a.processGeneration(t.Context(), "routing", "go", nil); event.Error != nil {
+				t.Fatalf("processGeneration() error = %v", event.Error)
+			}
+
+			wantCalls := map[models.ModelID]int32{tt.wantModel: 1}
+			if got := defaultProvider.calls.Load(); got != wantCalls[models.Claude4Sonnet] {
+				t.Errorf("default provider called %d times, want %d", got, wantCalls[models.Claude4Sonnet])
+			}
+			if got := bigProvider.calls.Load(); got != wantCalls[models.Gemini25] {
+				t.Errorf("routed provider called %d times, want %d", got, wantCalls[models.Gemini25])
+			}
+			for _, msg := range messages.msgs {
+				if msg.Role == message.Assistant {
+					if msg.Model != tt.wantModel {
+						t.Errorf("assistant message model = %q, want %q", msg.Model, tt.wantModel)
+					}
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestMeasureRequest(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	coder := cfg.Agents[config.AgentCoder]
+	cfg.Agents[config.AgentCoder] = config.Agent{
+		Model:   models.Gemini25,
+		Routing: []config.RoutingRule{{When: "messages<3", Model: models.Claude4Sonnet}},
+	}
+	defer func() { cfg.Agents[config.AgentCoder] = coder }()
+
+	defaultProvider := &sizedProvider{model: models.Gemini25, tokens: 150000}
+	smallProvider := &sizedProvider{model: models.Claude4Sonnet, tokens: 160000, full: true}
+	a := &agent{
+		agentID:         config.AgentCoder,
+		provider:        defaultProvider,
+		routes:          agentRoutes(config.AgentCoder),
+		routedProviders: map[models.ModelID]provider.Provider{models.Claude4Sonnet: smallProvider},
+	}
+
+	history := []message.Message{{Role: message.User}}
+	got, tokens, full := a.measureRequest(t.Context(), history, nil)
+	if got != smallProvider || tokens != 160000 || !full {
+		t.Errorf("measureRequest() = %v, %d, %v; want the routed provider's estimate and threshold", got.Model().ID, tokens, full)
+	}
+
+	history = append(history, message.Message{Role: message.Assistant}, message.Message{Role: message.User})
+	got, tokens, full = a.measureRequest(t.Context(), history, nil)
+	if got != defaultProvider || tokens != 150000 || full {
+		t.Errorf("measureRequest() = %v, %d, %v; want the agent provider's estimate and threshold", got.Model().ID, tokens, full)
+	}
+}
+
+func TestProcessEvent_ThinkingDelta(t *testing.T) {
+	a := &agent{messages: &memMessages{}}
+	assistantMsg := message.Message{ID: "m1", Role: message.Assistant}
+	for _, thinking := range []string{"Let me ", "check."} {
+		event := provider.ProviderEvent{Type: provider.EventThinkingDelta, Thinking: thinking}
+		if err := a.processEvent(context.Background(), "s1", models.Model{}, &assistantMsg, event); err != nil {
+			t.Fatalf("processEvent() error = %v", err)
+		}
+	}
+	if got := assistantMsg.ReasoningContent().Thinking; got != "Let me check." {
+		t.Errorf("reasoning = %q, want %q", got, "Let me check.")
+	}
+}
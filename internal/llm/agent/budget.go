@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/session"
+)
+
+// sessionUsage is what a session has spent, or is about to spend, on
+// provider calls.
+type sessionUsage struct {
+	cost   float64
+	tokens int64
+}
+
+// addTokenTotals adds the tokens of one provider call to the running totals
+// persisted on sess, which session.maxTokens is enforced from.
+func addTokenTotals(sess *session.Session, usage provider.TokenUsage) {
+	sess.TotalPromptTokens += usage.InputTokens + usage.CacheCreationTokens + usage.CacheReadTokens
+	sess.TotalCompletionTokens += usage.OutputTokens
+}
+
+// chargeUsage adds the cost and tokens of a side call, such as summarizing a
+// tool result, to the session. Unlike TrackUsage it leaves the context size of
+// the conversation as it is.
+func (a *agent) chargeUsage(ctx context.Context, sessionID string, model models.Model, usage provider.TokenUsage) error {
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+	sess.Cost += usageCost(model, usage)
+	addTokenTotals(&sess, usage)
+	if _, err := a.sessions.Save(ctx, sess); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// budgetExceeded explains why spending next on top of spent would exceed the
+// session limits, or returns "" when the call fits the budget.
+func budgetExceeded(limits config.SessionConfig, spent, next sessionUsage) string {
+	if limits.MaxCostUSD > 0 && spent.cost+next.cost > limits.MaxCostUSD {
+		return fmt.Sprintf("Session budget exhausted: $%.4f spent, and the next request (about $%.4f) would exceed session.maxCostUSD ($%.2f).",
+			spent.cost, next.cost, limits.MaxCostUSD)
+	}
+	if limits.MaxTokens > 0 && spent.tokens+next.tokens > limits.MaxTokens {
+		return fmt.Sprintf("Session budget exhausted: %d tokens used, and the next request (about %d tokens) would exceed session.maxTokens (%d).",
+			spent.tokens, next.tokens, limits.MaxTokens)
+	}
+	return ""
+}
+
+// checkBudget reports whether the next provider call to model, estimated at
+// inputTokens, would exceed the configured session budget.
+func (a *agent) checkBudget(ctx context.Context, sessionID string, model models.Model, inputTokens int64) (string, error) {
+	cfg := config.Get()
+	if cfg == nil || (cfg.Session.MaxCostUSD <= 0 && cfg.Session.MaxTokens <= 0) {
+		return "", nil
+	}
+	sess, err := a.sessions.Get(ctx, sessionID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session: %w", err)
+	}
+	spent := sessionUsage{cost: sess.Cost, tokens: sess.TotalPromptTokens + sess.TotalCompletionTokens}
+	next := sessionUsage{cost: model.CostPer1MIn / 1e6 * float64(inputTokens), tokens: inputTokens}
+	return budgetExceeded(cfg.Session, spent, next), nil
+}
+
+// haltForBudget ends the turn with an assistant message explaining that the
+// session budget is exhausted.
+func (a *agent) haltForBudget(ctx context.Context, sessionID, reason string) AgentEvent {
+	logging.Warn("Session budget exhausted, halting turn", "session_id", sessionID, "reason", reason)
+	return a.haltTurn(ctx, sessionID, reason, message.FinishReasonBudgetExceeded)
+}
@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/session"
+)
+
+func TestBudgetExceeded(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits config.SessionConfig
+		spent  sessionUsage
+		next   sessionUsage
+		want   string
+	}{
+		{
+			name:  "no limits",
+			spent: sessionUsage{cost: 1000, tokens: 1_000_000},
+			next:  sessionUsage{cost: 1, tokens: 1000},
+		},
+		{
+			name:   "cost within budget",
+			limits: config.SessionConfig{MaxCostUSD: 1},
+			spent:  sessionUsage{cost: 0.5},
+			next:   sessionUsage{cost: 0.5},
+		},
+		{
+			name:   "cost exceeded by next call",
+			limits: config.SessionConfig{MaxCostUSD: 1},
+			spent:  sessionUsage{cost: 0.99},
+			next:   sessionUsage{cost: 0.02},
+			want:   "session.maxCostUSD",
+		},
+		{
+			name:   "tokens exceeded by next call",
+			limits: config.SessionConfig{MaxTokens: 10_000},
+			spent:  sessionUsage{tokens: 9_500},
+			next:   sessionUsage{tokens: 600},
+			want:   "session.maxTokens",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := budgetExceeded(tt.limits, tt.spent, tt.next)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("budgetExceeded() = %q, want within budget", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("budgetExceeded() = %q, want mention of %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckBudget_TokensPersistedOnSession(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	cfg.Session.MaxTokens = 1000
+	defer func() { cfg.Session.MaxTokens = 0 }()
+
+	sessions := &memSessions{sess: session.Session{ID: "s1"}}
+	a := &agent{sessions: sessions}
+	ctx := context.Background()
+	for range 9 {
+		if err := a.TrackUsage(ctx, "s1", models.Model{}, provider.TokenUsage{InputTokens: 60, CacheReadTokens: 20, OutputTokens: 20}); err != nil {
+			t.Fatalf("TrackUsage() error = %v", err)
+		}
+	}
+	if got := sessions.sess.TotalPromptTokens; got != 720 {
+		t.Errorf("TotalPromptTokens = %d, want 720", got)
+	}
+	if got := sessions.sess.TotalCompletionTokens; got != 180 {
+		t.Errorf("TotalCompletionTokens = %d, want 180", got)
+	}
+
+	reason, err := a.checkBudget(ctx, "s1", models.Model{}, 100)
+	if err != nil {
+		t.Fatalf("checkBudget() error = %v", err)
+	}
+	if reason != "" {
+		t.Fatalf("call reaching the cap exactly was blocked: %s", reason)
+	}
+	reason, err = a.checkBudget(ctx, "s1", models.Model{}, 101)
+	if err != nil {
+		t.Fatalf("checkBudget() error = %v", err)
+	}
+	if reason == "" {
+		t.Fatal("call past the cap was not blocked")
+	}
+}
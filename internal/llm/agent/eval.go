@@ -0,0 +1,164 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+// EvalResult is the answer of one model to an evaluation prompt.
+type EvalResult struct {
+	// Target is the model ID or agent name the prompt was run against.
+	Target   string
+	Model    models.Model
+	Output   string
+	Usage    provider.TokenUsage
+	Cost     float64
+	Duration time.Duration
+	Err      error
+}
+
+// evalProvider creates the provider answering for target. It wraps
+// createEvalProvider and is replaced in tests.
+var evalProvider = createEvalProvider
+
+// createEvalProvider resolves target to a provider. An agent name uses that
+// agent's model and prompt; a model ID uses the model with the prompt of
+// agentName.
+func createEvalProvider(agentName config.AgentName, target string) (provider.Provider, error) {
+	if _, ok := config.Get().Agents[config.AgentName(target)]; ok {
+		return createAgentProvider(config.AgentName(target))
+	}
+	if _, ok := agentregistry.GetRegistry().Get(target); ok {
+		return createAgentProvider(config.AgentName(target))
+	}
+	if _, ok := models.SupportedModels[models.ModelID(target)]; ok {
+		return createAgentProviderWithModel(agentName, models.ModelID(target))
+	}
+	return nil, fmt.Errorf("unknown model or agent %q", target)
+}
+
+// Evaluate sends prompt to every target, a model ID or agent name, at once
+// and returns their answers in the order of targets. A target that fails has
+// its error in its result; the others are unaffected.
+func Evaluate(ctx context.Context, agentName config.AgentName, prompt string, targets []string) []EvalResult {
+	results := make([]EvalResult, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = evaluateTarget(ctx, agentName, prompt, target)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func evaluateTarget(ctx context.Context, agentName config.AgentName, prompt, target string) EvalResult {
+	result := EvalResult{Target: target}
+	p, err := evalProvider(agentName, target)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Model = p.Model()
+
+	start := time.Now()
+	response, err := p.SendMessages(ctx, []message.Message{{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: prompt}},
+	}}, make([]tools.BaseTool, 0))
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Output = response.Content
+	result.Usage = response.Usage
+	result.Cost = usageCost(result.Model, response.Usage)
+	return result
+}
+
+// FormatEvalReport renders results for the terminal: each answer beside the
+// first successful one using the side-by-side diff renderer, then the token
+// usage and estimated cost of every model. width is the total width of the
+// side-by-side columns.
+func FormatEvalReport(results []EvalResult, width int) (string, error) {
+	var sb strings.Builder
+	var base *EvalResult
+	for i := range results {
+		r := &results[i]
+		if r.Err != nil {
+			continue
+		}
+		if base == nil {
+			base = r
+			continue
+		}
+		fmt.Fprintf(&sb, "%s (left) vs %s (right)\n\n", base.Target, r.Target)
+		if base.Output == r.Output {
+			sb.WriteString("The outputs are identical.\n\n")
+			continue
+		}
+		unified, _, _ := diff.GenerateDiff(base.Output, r.Output, "output.md")
+		sideBySide, err := diff.FormatDiff(unified, diff.WithTotalWidth(width))
+		if err != nil {
+			return "", fmt.Errorf("failed to render the outputs of %s and %s: %w", base.Target, r.Target, err)
+		}
+		sb.WriteString(sideBySide)
+		sb.WriteString("\n")
+	}
+	if base == nil {
+		sb.WriteString("No model answered, so there is nothing to compare.\n\n")
+	}
+
+	cheapest := -1.0
+	for _, r := range results {
+		if r.Err == nil && (cheapest < 0 || r.Cost < cheapest) {
+			cheapest = r.Cost
+		}
+	}
+	sb.WriteString("Usage and estimated cost:\n")
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(&sb, "  %-24s failed: %v\n", r.Target, r.Err)
+			continue
+		}
+		relative := "cheapest"
+		switch {
+		case r.Cost > cheapest && cheapest > 0:
+			relative = fmt.Sprintf("%.1fx the cheapest", r.Cost/cheapest)
+		case r.Cost > cheapest:
+			relative = "the cheapest is free"
+		}
+		fmt.Fprintf(&sb, "  %-24s %s: %d in, %d out, %d cached, $%.4f (%s), %s\n",
+			r.Target, r.Model.Name,
+			r.Usage.InputTokens, r.Usage.OutputTokens, r.Usage.CacheCreationTokens+r.Usage.CacheReadTokens,
+			r.Cost, relative, r.Duration.Round(time.Millisecond))
+	}
+	return sb.String(), nil
+}
+
+// EvalError returns an error when every result failed, joining their errors.
+func EvalError(results []EvalResult) error {
+	var errs []error
+	for _, r := range results {
+		if r.Err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", r.Target, r.Err))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,119 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// evalStubProvider answers every prompt with output and usage.
+type evalStubProvider struct {
+	provider.Provider
+	model  models.Model
+	output string
+	usage  provider.TokenUsage
+}
+
+func (p *evalStubProvider) SendMessages(context.Context, []message.Message, []tools.BaseTool) (*provider.ProviderResponse, error) {
+	return &provider.ProviderResponse{Content: p.output, Usage: p.usage}, nil
+}
+
+func (p *evalStubProvider) Model() models.Model { return p.model }
+
+func TestEvaluate(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	stubs := map[string]*evalStubProvider{
+		"cheap": {
+			model:  models.Model{Name: "Cheap", CostPer1MIn: 1, CostPer1MOut: 2},
+			output: "func add(a, b int) int {\n\treturn a + b\n}\n",
+			usage:  provider.TokenUsage{InputTokens: 1_000_000, OutputTokens: 500_000},
+		},
+		"pricey": {
+			model:  models.Model{Name: "Pricey", CostPer1MIn: 3, CostPer1MOut: 12},
+			output: "func add(a, b int) int {\n\treturn b + a\n}\n",
+			usage:  provider.TokenUsage{InputTokens: 1_000_000, OutputTokens: 500_000},
+		},
+	}
+	original := evalProvider
+	evalProvider = func(_ config.AgentName, target string) (provider.Provider, error) {
+		if stub, ok := stubs[target]; ok {
+			return stub, nil
+		}
+		return nil, errors.New("unknown model or agent")
+	}
+	defer func() { evalProvider = original }()
+
+	results := Evaluate(t.Context(), config.AgentCoder, "write add", []string{"cheap", "pricey", "missing"})
+	if len(results) != 3 {
+		t.Fatalf("Evaluate() returned %d results, want 3", len(results))
+	}
+	if results[0].Cost != 2 || results[1].Cost != 9 {
+		t.Errorf("costs = %v, %v, want 2 and 9", results[0].Cost, results[1].Cost)
+	}
+	if results[2].Err == nil {
+		t.Error("the unknown target did not fail")
+	}
+	if err := EvalError(results); err != nil {
+		t.Errorf("EvalError() = %v, want nil when some models answered", err)
+	}
+
+	report, err := FormatEvalReport(results, 120)
+	if err != nil {
+		t.Fatalf("FormatEvalReport() error = %v", err)
+	}
+	report = ansi.Strip(report)
+	for _, want := range []string{
+		"cheap (left) vs pricey (right)",
+		"return a + b",
+		"return b + a",
+		"$2.0000 (cheapest)",
+		"$9.0000 (4.5x the cheapest)",
+		"missing",
+		"failed: unknown model or agent",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+	// The changed line is rendered once in each column
+	for _, line := range strings.Split(report, "\n") {
+		if strings.Contains(line, "return a + b") && !strings.Contains(line, "return b + a") {
+			t.Errorf("the outputs are not side by side in %q", line)
+		}
+	}
+}
+
+func TestEvaluate_IdenticalAndFailedOutputs(t *testing.T) {
+	original := evalProvider
+	evalProvider = func(_ config.AgentName, target string) (provider.Provider, error) {
+		if target == "broken" {
+			return nil, errors.New("provider openai is not enabled")
+		}
+		return &evalStubProvider{model: models.Model{Name: target}, output: "same\n"}, nil
+	}
+	defer func() { evalProvider = original }()
+
+	results := Evaluate(t.Context(), config.AgentCoder, "hi", []string{"a", "b"})
+	report, err := FormatEvalReport(results, 120)
+	if err != nil {
+		t.Fatalf("FormatEvalReport() error = %v", err)
+	}
+	if !strings.Contains(report, "The outputs are identical.") {
+		t.Errorf("report does not note the identical outputs:\n%s", report)
+	}
+
+	results = Evaluate(t.Context(), config.AgentCoder, "hi", []string{"broken", "broken"})
+	if err := EvalError(results); err == nil || !strings.Contains(err.Error(), "not enabled") {
+		t.Errorf("EvalError() = %v, want the errors of every model", err)
+	}
+}
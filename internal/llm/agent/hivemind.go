@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+)
+
+// subagentRunner executes a single task tool call.
+type subagentRunner func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error)
+
+// hivemindLimits returns the configured subagent parallelism and per-agent
+// timeout, falling back to the defaults when unset.
+func hivemindLimits() (int, time.Duration) {
+	maxParallel := config.DefaultHivemindMaxParallel
+	var timeout time.Duration
+	if cfg := config.Get(); cfg != nil {
+		if cfg.Hivemind.MaxParallel > 0 {
+			maxParallel = cfg.Hivemind.MaxParallel
+		}
+		if cfg.Hivemind.PerAgentTimeout > 0 {
+			timeout = time.Duration(cfg.Hivemind.PerAgentTimeout) * time.Second
+		}
+	}
+	return maxParallel, timeout
+}
+
+// subagentPool bounds the subagents started by one batch of tool calls: at
+// most maxParallel run at once, each for at most perAgentTimeout when it is
+// positive.
+type subagentPool struct {
+	sem             chan struct{}
+	perAgentTimeout time.Duration
+}
+
+func newSubagentPool(maxParallel int, perAgentTimeout time.Duration) *subagentPool {
+	if maxParallel <= 0 {
+		maxParallel = 1
+	}
+	return &subagentPool{sem: make(chan struct{}, maxParallel), perAgentTimeout: perAgentTimeout}
+}
+
+// run waits for a free worker and runs call on it.
+func (p *subagentPool) run(ctx context.Context, call tools.ToolCall, run subagentRunner) (tools.ToolResponse, error) {
+	select {
+	case p.sem <- struct{}{}:
+		defer func() { <-p.sem }()
+	case <-ctx.Done():
+		return tools.ToolResponse{}, ctx.Err()
+	}
+
+	runCtx, cancel := ctx, context.CancelFunc(func() {})
+	if p.perAgentTimeout > 0 {
+		runCtx, cancel = context.WithTimeout(ctx, p.perAgentTimeout)
+	}
+	defer cancel()
+
+	response, err := run(runCtx, call)
+	if err != nil && ctx.Err() == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("subagent timed out after %s: %w", p.perAgentTimeout, err)
+	}
+	return response, err
+}
@@ -0,0 +1,98 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+)
+
+// runSubagents runs task calls as runToolCallBatch does: scheduled by
+// runToolCalls, with a single tool slot, on a subagent pool.
+func runSubagents(ctx context.Context, calls []tools.ToolCall, maxParallel int, perAgentTimeout time.Duration, run subagentRunner) []toolCallResult {
+	pool := newSubagentPool(maxParallel, perAgentTimeout)
+	return runToolCalls(ctx, calls, nil, 1, func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		return pool.run(ctx, call, run)
+	})
+}
+
+func TestRunSubagentsCapsParallelism(t *testing.T) {
+	var running, peak atomic.Int32
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Finish in reverse order so results complete out of call order
+		time.Sleep(time.Duration(10-len(call.ID)) * 5 * time.Millisecond)
+		return tools.NewTextResponse("done " + call.ID), nil
+	}
+
+	calls := make([]tools.ToolCall, 6)
+	for i := range calls {
+		calls[i] = tools.ToolCall{ID: strings.Repeat("x", i+1), Name: TaskToolName}
+	}
+
+	results := runSubagents(context.Background(), calls, 2, 0, run)
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", got)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("peak concurrency = %d, expected subagents to run in parallel", got)
+	}
+	for i, result := range results {
+		if result.err != nil {
+			t.Fatalf("result %d: unexpected error %v", i, result.err)
+		}
+		if want := "done " + calls[i].ID; result.response.Content != want {
+			t.Errorf("result %d = %q, want %q", i, result.response.Content, want)
+		}
+	}
+}
+
+func TestRunSubagentsPerAgentTimeout(t *testing.T) {
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		if call.ID == "fast" {
+			return tools.NewTextResponse("ok"), nil
+		}
+		<-ctx.Done()
+		return tools.ToolResponse{}, fmt.Errorf("error while running task agent: %w", ctx.Err())
+	}
+
+	calls := []tools.ToolCall{{ID: "slow", Name: TaskToolName}, {ID: "fast", Name: TaskToolName}}
+	results := runSubagents(context.Background(), calls, 2, 20*time.Millisecond, run)
+
+	if results[0].err == nil || !strings.Contains(results[0].err.Error(), "timed out") {
+		t.Errorf("expected timeout error for slow subagent, got %v", results[0].err)
+	}
+	if results[1].err != nil || results[1].response.Content != "ok" {
+		t.Errorf("fast subagent result = %+v", results[1])
+	}
+}
+
+func TestRunSubagentsCanceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var calls atomic.Int32
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		calls.Add(1)
+		return tools.ToolResponse{}, ctx.Err()
+	}
+
+	results := runSubagents(ctx, []tools.ToolCall{{ID: "a", Name: TaskToolName}, {ID: "b", Name: TaskToolName}, {ID: "c", Name: TaskToolName}}, 1, 0, run)
+	for i, result := range results {
+		if result.err == nil {
+			t.Errorf("result %d: expected an error for canceled context", i)
+		}
+	}
+}
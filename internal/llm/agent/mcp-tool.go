@@ -88,6 +88,10 @@ func (r *mcpRegistry) StartClient(ctx context.Context, name string) (c *client.C
 			m.URL,
 			client.WithHeaders(m.Headers),
 		)
+		// The SSE stream is bound to the context it is started with, so it
+		// must outlive starting; Close ends it, and the transport bounds the
+		// wait for the server's endpoint itself
+		startCtx = context.WithoutCancel(ctx)
 	case config.MCPHttp:
 		c, err = client.NewStreamableHttpClient(
 			m.URL,
@@ -290,10 +294,65 @@ func (b *mcpTool) Run(ctx context.Context, params tools.ToolCall) (tools.ToolRes
 		return tools.NewTextErrorResponse(err.Error()), nil
 	}
 	defer c.Close()
-	return runTool(ctx, c, b.tool.Name, params.Input)
+	return runTool(ctx, c, b.tool.Name, params.Input, b.keepaliveInterval())
 }
 
-func runTool(ctx context.Context, c MCPClient, toolName string, input string) (tools.ToolResponse, error) {
+// keepaliveInterval returns how often the server is pinged during a call, or
+// zero for stdio servers and servers without keepaliveInterval.
+func (b *mcpTool) keepaliveInterval() time.Duration {
+	if b.mcpConfig.Type != config.MCPSse && b.mcpConfig.Type != config.MCPHttp {
+		return 0
+	}
+	return time.Duration(b.mcpConfig.KeepaliveInterval) * time.Second
+}
+
+// mcpPinger is the part of an MCP client used for keepalive pings.
+type mcpPinger interface {
+	Ping(ctx context.Context) error
+}
+
+// errMCPConnectionLost is the cause of a call canceled because its server
+// stopped answering keepalive pings.
+var errMCPConnectionLost = errors.New("MCP server connection lost")
+
+// keepMCPAlive pings c every interval until the returned stop function is
+// called. Each ping must be answered within interval; when one is not, the
+// connection is considered dropped and the returned context is canceled with
+// errMCPConnectionLost so the call fails instead of waiting for a response
+// that will never arrive.
+func keepMCPAlive(ctx context.Context, c mcpPinger, interval time.Duration) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			pingCtx, cancelPing := context.WithTimeout(ctx, interval)
+			err := c.Ping(pingCtx)
+			cancelPing()
+			if err != nil && ctx.Err() == nil {
+				cancel(fmt.Errorf("%w: keepalive ping failed: %v", errMCPConnectionLost, err))
+				return
+			}
+		}
+	}()
+	return ctx, func() {
+		close(done)
+		cancel(nil)
+	}
+}
+
+// runTool initializes c and calls toolName with input. When keepalive is
+// positive the server is pinged at that interval while the call runs; a
+// dropped connection fails the call, and the next call connects again.
+func runTool(ctx context.Context, c MCPClient, toolName string, input string, keepalive time.Duration) (tools.ToolResponse, error) {
 	initRequest := mcp.InitializeRequest{}
 	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
 	initRequest.Params.ClientInfo = mcp.Implementation{
@@ -313,8 +372,17 @@ func runTool(ctx context.Context, c MCPClient, toolName string, input string) (t
 		return tools.NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
 	}
 	toolRequest.Params.Arguments = args
-	result, err := c.CallTool(ctx, toolRequest)
+	callCtx := ctx
+	if pinger, ok := c.(mcpPinger); ok && keepalive > 0 {
+		var stop func()
+		callCtx, stop = keepMCPAlive(ctx, pinger, keepalive)
+		defer stop()
+	}
+	result, err := c.CallTool(callCtx, toolRequest)
 	if err != nil {
+		if cause := context.Cause(callCtx); errors.Is(cause, errMCPConnectionLost) {
+			return tools.NewTextErrorResponse(fmt.Sprintf("%v; the tool may or may not have run. The next call reconnects to the server.", cause)), nil
+		}
 		return tools.NewTextErrorResponse(err.Error()), nil
 	}
 
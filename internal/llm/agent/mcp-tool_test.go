@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// newTestMCPServer serves an MCP server over SSE with a "wait" tool that
+// answers after delay, or never when delay is zero, and signals started
+// when it is called.
+func newTestMCPServer(t *testing.T, delay time.Duration, started chan<- struct{}) *server.MCPServer {
+	t.Helper()
+	srv := server.NewMCPServer("test", "1.0.0")
+	srv.AddTool(mcp.NewTool("wait"), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		if delay == 0 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return mcp.NewToolResultText("done"), nil
+	})
+	return srv
+}
+
+// startTestSSEClient connects to the SSE server at url. The client must be
+// closed before the server, which waits for open streams.
+func startTestSSEClient(t *testing.T, url string) *client.Client {
+	t.Helper()
+	c, err := client.NewSSEMCPClient(url)
+	if err != nil {
+		t.Fatalf("NewSSEMCPClient() error = %v", err)
+	}
+	if err := c.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	return c
+}
+
+func TestRunTool_KeepaliveDetectsDroppedConnection(t *testing.T) {
+	started := make(chan struct{}, 1)
+	ts := server.NewTestServer(newTestMCPServer(t, 0, started))
+	defer ts.Close()
+	c := startTestSSEClient(t, ts.URL+"/sse")
+	defer c.Close()
+
+	type result struct {
+		response tools.ToolResponse
+		err      error
+	}
+	results := make(chan result, 1)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	go func() {
+		response, err := runTool(ctx, c, "wait", "{}", 20*time.Millisecond)
+		results <- result{response, err}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tool was not called")
+	}
+	// Drop the idle stream like a proxy would
+	ts.CloseClientConnections()
+
+	select {
+	case r := <-results:
+		if r.err != nil {
+			t.Fatalf("runTool() error = %v", r.err)
+		}
+		if !r.response.IsError || !strings.Contains(r.response.Content, "MCP server connection lost") {
+			t.Errorf("runTool() = %+v, want a lost connection error", r.response)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the dropped connection was not detected")
+	}
+
+	// The next call connects again
+	config.Reset()
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	t.Cleanup(config.Reset)
+	quick := server.NewTestServer(newTestMCPServer(t, time.Millisecond, make(chan struct{}, 1)))
+	defer quick.Close()
+	cfg.MCPServers["test"] = config.MCPServer{Type: config.MCPSse, URL: quick.URL + "/sse"}
+	reconnected, err := (&mcpRegistry{}).StartClient(ctx, "test")
+	if err != nil {
+		t.Fatalf("StartClient() error = %v", err)
+	}
+	defer reconnected.Close()
+	response, err := runTool(ctx, reconnected, "wait", "{}", time.Second)
+	if err != nil || response.IsError || response.Content != "done" {
+		t.Errorf("runTool() after reconnecting = %+v, %v, want done", response, err)
+	}
+}
+
+func TestRunTool_KeepaliveKeepsHealthyCall(t *testing.T) {
+	ts := server.NewTestServer(newTestMCPServer(t, 200*time.Millisecond, make(chan struct{}, 1)))
+	defer ts.Close()
+	c := startTestSSEClient(t, ts.URL+"/sse")
+	defer c.Close()
+
+	// The call outlasts several pings, which the server answers meanwhile
+	response, err := runTool(context.Background(), c, "wait", "{}", 20*time.Millisecond)
+	if err != nil || response.IsError || response.Content != "done" {
+		t.Errorf("runTool() = %+v, %v, want done", response, err)
+	}
+}
+
+func TestMCPToolKeepaliveInterval(t *testing.T) {
+	tests := []struct {
+		name   string
+		server config.MCPServer
+		want   time.Duration
+	}{
+		{"sse", config.MCPServer{Type: config.MCPSse, KeepaliveInterval: 30}, 30 * time.Second},
+		{"http", config.MCPServer{Type: config.MCPHttp, KeepaliveInterval: 5}, 5 * time.Second},
+		{"stdio is never pinged", config.MCPServer{Type: config.MCPStdio, KeepaliveInterval: 30}, 0},
+		{"disabled", config.MCPServer{Type: config.MCPSse}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &mcpTool{mcpConfig: tt.server}
+			if got := tool.keepaliveInterval(); got != tt.want {
+				t.Errorf("keepaliveInterval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
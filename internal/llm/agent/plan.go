@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+// PlanToolName is the tool name of the permission request that approves all
+// tool calls of a turn at once when confirmPlans is enabled.
+const PlanToolName = "tool_plan"
+
+// maxPlanInputLength caps the summarized input of each planned tool call.
+const maxPlanInputLength = 120
+
+// PlannedToolCall is one tool call of a plan with its summarized input.
+type PlannedToolCall struct {
+	Name  string `json:"name"`
+	Input string `json:"input"`
+}
+
+// ToolCallPlan lists the tool calls a turn intends to make. It is the
+// params of a PlanToolName permission request.
+type ToolCallPlan struct {
+	Calls []PlannedToolCall `json:"calls"`
+}
+
+// newToolCallPlan summarizes toolCalls for review by the user.
+func newToolCallPlan(toolCalls []message.ToolCall) ToolCallPlan {
+	plan := ToolCallPlan{Calls: make([]PlannedToolCall, len(toolCalls))}
+	for i, tc := range toolCalls {
+		plan.Calls[i] = PlannedToolCall{Name: tc.Name, Input: summarizeToolInput(tc.Input)}
+	}
+	return plan
+}
+
+// summarizeToolInput compacts a tool call's JSON input and truncates it to
+// maxPlanInputLength characters.
+func summarizeToolInput(input string) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(input)); err == nil {
+		input = buf.String()
+	}
+	if runes := []rune(input); len(runes) > maxPlanInputLength {
+		input = string(runes[:maxPlanInputLength]) + "..."
+	}
+	return input
+}
+
+// String renders the plan as a markdown list for the permission dialog.
+func (p ToolCallPlan) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Run %d tool calls:\n\n", len(p.Calls))
+	for i, call := range p.Calls {
+		fmt.Fprintf(&sb, "%d. **%s** `%s`\n", i+1, call.Name, call.Input)
+	}
+	return sb.String()
+}
+
+// approveToolCallPlan asks the user to approve a turn's tool calls before any
+// of them runs. Approval is only asked for when confirmPlans is enabled and
+// the model returned more than one tool call, at least one of which is of a
+// mutating tool in toolSet.
+func (a *agent) approveToolCallPlan(sessionID string, toolCalls []message.ToolCall, toolSet []tools.BaseTool) bool {
+	if len(toolCalls) < 2 || a.permissions == nil || !hasMutatingToolCall(toolCalls, toolSet) {
+		return true
+	}
+	if cfg := config.Get(); cfg == nil || !cfg.ConfirmPlans {
+		return true
+	}
+	plan := newToolCallPlan(toolCalls)
+	return a.permissions.Request(permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		ToolName:    PlanToolName,
+		Action:      "execute",
+		Path:        config.WorkingDirectory(),
+		Description: plan.String(),
+		Params:      plan,
+	})
+}
+
+// hasMutatingToolCall reports whether any of toolCalls is of a tool in
+// toolSet that may change state. Calls of tools missing from toolSet count as
+// mutating.
+func hasMutatingToolCall(toolCalls []message.ToolCall, toolSet []tools.BaseTool) bool {
+	readOnly := make(map[string]bool, len(toolSet))
+	for _, tool := range toolSet {
+		info := tool.Info()
+		readOnly[info.Name] = !info.Mutating()
+	}
+	for _, tc := range toolCalls {
+		if !readOnly[tc.Name] {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectToolCallPlan finishes a turn whose tool call plan was rejected and
+// records a denied result for each of its tool calls.
+func (a *agent) rejectToolCallPlan(ctx context.Context, assistantMsg *message.Message) (*message.Message, error) {
+	a.finishMessage(ctx, assistantMsg, message.FinishReasonPermissionDenied)
+	toolCalls := assistantMsg.ToolCalls()
+	parts := make([]message.ContentPart, len(toolCalls))
+	for i, tc := range toolCalls {
+		parts[i] = message.ToolResult{
+			ToolCallID:  tc.ID,
+			Name:        tc.Name,
+			Content:     "Tool call plan rejected by user",
+			IsError:     true,
+			ErrorDetail: &tools.ToolErrorDetail{Code: tools.ErrorCodePermissionDenied},
+		}
+	}
+	msg, err := a.messages.Create(ctx, assistantMsg.SessionID, message.CreateMessageParams{
+		Role:  message.Tool,
+		Parts: parts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rejected plan tool message: %w", err)
+	}
+	return &msg, nil
+}
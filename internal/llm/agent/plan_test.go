@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+func TestApproveToolCallPlan(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	defer func() { cfg.ConfirmPlans = false }()
+
+	calls := []message.ToolCall{
+		{ID: "1", Name: tools.ReadToolName, Input: `{"file_path": "/repo/main.go"}`},
+		{ID: "2", Name: tools.BashToolName, Input: `{"command": "go test ./..."}`},
+	}
+	toolSet := []tools.BaseTool{
+		infoTool{tools.ToolInfo{Name: tools.ReadToolName, ReadOnly: true}},
+		infoTool{tools.ToolInfo{Name: tools.GrepToolName, ReadOnly: true}},
+		infoTool{tools.ToolInfo{Name: tools.BashToolName}},
+	}
+
+	newPlanAgent := func(t *testing.T) (*agent, permission.Service, <-chan permission.PermissionRequest) {
+		t.Helper()
+		perms := permission.NewPermissionService()
+		requests := make(chan permission.PermissionRequest, 1)
+		events := perms.SubscribeWithContext(t.Context())
+		go func() {
+			for event := range events {
+				requests <- event.Payload
+			}
+		}()
+		return &agent{permissions: perms}, perms, requests
+	}
+
+	t.Run("disabled runs immediately", func(t *testing.T) {
+		cfg.ConfirmPlans = false
+		a, _, events := newPlanAgent(t)
+		if !a.approveToolCallPlan("plan-off", calls, toolSet) {
+			t.Fatal("plan was not approved with confirmPlans disabled")
+		}
+		select {
+		case <-events:
+			t.Error("permission was requested with confirmPlans disabled")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("single tool call is not a plan", func(t *testing.T) {
+		cfg.ConfirmPlans = true
+		a, _, _ := newPlanAgent(t)
+		if !a.approveToolCallPlan("plan-single", calls[:1], toolSet) {
+			t.Fatal("single tool call was not approved")
+		}
+	})
+
+	t.Run("read-only plan runs immediately", func(t *testing.T) {
+		cfg.ConfirmPlans = true
+		a, _, events := newPlanAgent(t)
+		readOnly := []message.ToolCall{
+			calls[0],
+			{ID: "3", Name: tools.GrepToolName, Input: `{"pattern": "TODO"}`},
+		}
+		if !a.approveToolCallPlan("plan-read-only", readOnly, toolSet) {
+			t.Fatal("read-only plan was not approved")
+		}
+		select {
+		case <-events:
+			t.Error("permission was requested for a read-only plan")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	for _, approve := range []bool{true, false} {
+		name := "waits for approval"
+		if !approve {
+			name = "rejected plan runs nothing"
+		}
+		t.Run(name, func(t *testing.T) {
+			cfg.ConfirmPlans = true
+			a, perms, requests := newPlanAgent(t)
+
+			approved := make(chan bool, 1)
+			go func() { approved <- a.approveToolCallPlan("plan-on", calls, toolSet) }()
+
+			var request permission.PermissionRequest
+			select {
+			case request = <-requests:
+			case <-time.After(time.Second):
+				t.Fatal("no plan permission was requested")
+			}
+			if request.ToolName != PlanToolName {
+				t.Errorf("requested permission for %q, want %q", request.ToolName, PlanToolName)
+			}
+			for _, want := range []string{"Run 2 tool calls", "**read**", `{"file_path":"/repo/main.go"}`, "**bash**", "go test ./..."} {
+				if !strings.Contains(request.Description, want) {
+					t.Errorf("plan description %q does not contain %q", request.Description, want)
+				}
+			}
+
+			select {
+			case <-approved:
+				t.Fatal("plan returned before the user answered")
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			if approve {
+				perms.Grant(request)
+			} else {
+				perms.Deny(request)
+			}
+			if got := <-approved; got != approve {
+				t.Errorf("approveToolCallPlan() = %v, want %v", got, approve)
+			}
+		})
+	}
+}
+
+// infoTool is a tool that only describes itself.
+type infoTool struct {
+	info tools.ToolInfo
+}
+
+func (t infoTool) Info() tools.ToolInfo { return t.info }
+
+func (t infoTool) Run(context.Context, tools.ToolCall) (tools.ToolResponse, error) {
+	return tools.NewTextResponse(""), nil
+}
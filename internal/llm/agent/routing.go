@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+// modelRoute is a parsed routing rule from the agent's config.
+type modelRoute struct {
+	condition config.RoutingCondition
+	model     models.ModelID
+}
+
+// agentRoutes returns the routing rules configured for agentName. The rules
+// are checked when the config is validated, so any that do not parse are
+// skipped with a warning.
+func agentRoutes(agentName config.AgentName) []modelRoute {
+	cfg := config.Get()
+	if cfg == nil {
+		return nil
+	}
+	var routes []modelRoute
+	for _, rule := range cfg.Agents[agentName].Routing {
+		condition, err := config.ParseRoutingCondition(rule.When)
+		if err != nil {
+			logging.Warn("Skipping invalid routing rule", "agent", agentName, "error", err)
+			continue
+		}
+		routes = append(routes, modelRoute{condition: condition, model: rule.Model})
+	}
+	return routes
+}
+
+// routedProvider returns the provider for a request with the given estimated
+// prompt tokens and message count: the provider for the model of the first
+// matching routing rule, or the agent's own provider when no rule matches or
+// the routed provider cannot be created.
+func (a *agent) routedProvider(tokens int64, messages int) provider.Provider {
+	for _, route := range a.routes {
+		if !route.condition.Matches(tokens, int64(messages)) {
+			continue
+		}
+		if route.model == a.provider.Model().ID {
+			return a.provider
+		}
+		routed, err := a.routeProvider(route.model)
+		if err != nil {
+			logging.Warn("Failed to create provider for routed model, using the agent's model",
+				"agent", a.agentID, "model", route.model, "error", err)
+			return a.provider
+		}
+		logging.Debug("Routing request", "agent", a.agentID, "model", route.model, "tokens", tokens, "messages", messages)
+		return routed
+	}
+	return a.provider
+}
+
+// measureRequest routes a request by the agent provider's token estimate and
+// measures it again against the routed provider, so the auto-compaction
+// threshold is checked against the context window of the model that will
+// actually serve it.
+func (a *agent) measureRequest(ctx context.Context, msgHistory []message.Message, toolSet []tools.BaseTool) (provider.Provider, int64, bool) {
+	tokens, hitThreshold := a.provider.CountTokens(ctx, AutoCompactionThreshold, msgHistory, toolSet)
+	requestProvider := a.routedProvider(tokens, len(msgHistory))
+	if requestProvider == a.provider {
+		return requestProvider, tokens, hitThreshold
+	}
+	tokens, hitThreshold = requestProvider.CountTokens(ctx, AutoCompactionThreshold, msgHistory, toolSet)
+	return requestProvider, tokens, hitThreshold
+}
+
+// routeProvider returns the provider for a routed model, creating it on first
+// use.
+func (a *agent) routeProvider(modelID models.ModelID) (provider.Provider, error) {
+	a.routedMu.Lock()
+	defer a.routedMu.Unlock()
+	if p, ok := a.routedProviders[modelID]; ok {
+		return p, nil
+	}
+	p, err := createAgentProviderWithModel(a.agentID, modelID)
+	if err != nil {
+		return nil, err
+	}
+	if a.routedProviders == nil {
+		a.routedProviders = make(map[models.ModelID]provider.Provider)
+	}
+	a.routedProviders[modelID] = p
+	return p, nil
+}
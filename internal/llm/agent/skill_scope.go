@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/skill"
+)
+
+type skillScopeContextKey struct{}
+
+// skillScope restricts the file writes of a turn to the allowed-directories
+// of the skill driving it: the last skill with allowed-directories that was
+// loaded in the turn. Loading a skill without allowed-directories afterwards
+// does not lift the restriction. Calls whose writes cannot be checked, such
+// as bash, are refused while the scope is restricted.
+type skillScope struct {
+	mu    sync.Mutex
+	skill *skill.Info
+}
+
+// withSkillScope returns ctx carrying a new, unrestricted skill scope for
+// one turn.
+func withSkillScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skillScopeContextKey{}, &skillScope{})
+}
+
+// skillScopeFrom returns the skill scope of the turn, or nil outside a turn.
+func skillScopeFrom(ctx context.Context) *skillScope {
+	scope, _ := ctx.Value(skillScopeContextKey{}).(*skillScope)
+	return scope
+}
+
+// load restricts the scope to the skills loaded by calls. Calls in the same
+// batch as the skill call are already restricted, since they run alongside
+// it.
+func (s *skillScope) load(calls []message.ToolCall) {
+	if s == nil {
+		return
+	}
+	for _, call := range calls {
+		if call.Name != tools.SkillToolName {
+			continue
+		}
+		var params tools.SkillParams
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			continue
+		}
+		info, err := skill.Get(params.Name)
+		if err != nil || len(info.AllowedDirectories) == 0 {
+			continue
+		}
+		s.mu.Lock()
+		s.skill = info
+		s.mu.Unlock()
+	}
+}
+
+// sessionStateTools change the state of the session, never files, so a
+// skill scope does not restrict them.
+var sessionStateTools = map[string]bool{
+	tools.PlanTaskToolName:   true,
+	tools.UpdateStepToolName: true,
+}
+
+// check returns an error response for a file write outside the allowed
+// directories of the scope's skill, and for calls whose writes cannot be
+// known before they run, such as bash, rename_symbol, task and MCP tools. It
+// reports false when call, of a tool described by info, may run.
+func (s *skillScope) check(call tools.ToolCall, info tools.ToolInfo) (tools.ToolResponse, bool) {
+	if s == nil || !info.Mutating() || sessionStateTools[call.Name] {
+		return tools.ToolResponse{}, false
+	}
+	s.mu.Lock()
+	scoped := s.skill
+	s.mu.Unlock()
+	if scoped == nil {
+		return tools.ToolResponse{}, false
+	}
+
+	allowed := strings.Join(scoped.AllowedDirectories, ", ")
+	paths, ok := writtenPaths(call, info)
+	if !ok {
+		response := tools.NewTextErrorResponse(fmt.Sprintf(
+			"%s cannot run while the %s skill is in use, since the files it changes cannot be checked against the directories the skill may change (%s); use the file editing tools instead",
+			call.Name, scoped.Name, allowed))
+		response.ErrorDetail = &tools.ToolErrorDetail{Code: tools.ErrorCodePermissionDenied}
+		return response, true
+	}
+
+	workingDir := config.WorkingDirectory()
+	for _, path := range paths {
+		if scoped.AllowsPath(path, workingDir) {
+			continue
+		}
+		display := path
+		if rel, err := filepath.Rel(workingDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			display = filepath.ToSlash(rel)
+		}
+		response := tools.NewTextErrorResponse(fmt.Sprintf(
+			"%s is outside the directories the %s skill may change (%s); only files matching them can be written while it is in use",
+			display, scoped.Name, allowed))
+		response.ErrorDetail = &tools.ToolErrorDetail{Code: tools.ErrorCodePermissionDenied, Path: path}
+		return response, true
+	}
+	return tools.ToolResponse{}, false
+}
+
+// writtenPaths returns every path call, of a tool described by info, may
+// change, including the targets of a patch's moves and the file of an applied
+// proposal. It reports false when the paths cannot be derived from the input.
+func writtenPaths(call tools.ToolCall, info tools.ToolInfo) ([]string, bool) {
+	switch {
+	case fileWriteTools[call.Name]:
+		access := accessForToolCall(call, info)
+		return access.paths, len(access.paths) > 0
+	case call.Name == tools.PatchToolName:
+		var params struct {
+			tools.PatchParams
+			Cwd string `json:"cwd"`
+		}
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return nil, false
+		}
+		var paths []string
+		for _, p := range append(diff.IdentifyFilesNeeded(params.PatchText), diff.IdentifyFilesAdded(params.PatchText)...) {
+			paths = append(paths, normalizeToolPath(p, params.Cwd))
+		}
+		return paths, len(paths) > 0
+	case call.Name == tools.ApplyProposalToolName:
+		var params tools.ApplyProposalParams
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return nil, false
+		}
+		path, err := tools.ProposalFilePath(params.ProposalID)
+		if err != nil {
+			// Unknown proposals are reported by the tool itself
+			return nil, true
+		}
+		return []string{path}, true
+	}
+	return nil, false
+}
@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/skill"
+)
+
+// recordingTool records the inputs it is run with.
+type recordingTool struct {
+	name     string
+	readOnly bool
+	mu       sync.Mutex
+	runs     []string
+}
+
+func (t *recordingTool) Info() tools.ToolInfo {
+	return tools.ToolInfo{Name: t.name, ReadOnly: t.readOnly}
+}
+
+func (t *recordingTool) Run(_ context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.runs = append(t.runs, call.Input)
+	return tools.NewTextResponse("ok"), nil
+}
+
+func TestRunToolCallBatchSkillScope(t *testing.T) {
+	workingDir := t.TempDir()
+	config.Reset()
+	if _, err := config.Load(workingDir, false); err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	t.Cleanup(config.Reset)
+	skillDir := filepath.Join(workingDir, ".opencode", "skills", "infra-ops")
+	if err := os.MkdirAll(skillDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	skillFile := "---\nname: infra-ops\ndescription: Manage the Terraform configuration\nallowed-directories:\n  - infra/**\n---\nEdit infra only.\n"
+	if err := os.WriteFile(filepath.Join(skillDir, "SKILL.md"), []byte(skillFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	skill.Invalidate()
+	t.Cleanup(skill.Invalidate)
+
+	edit := &recordingTool{name: tools.EditToolName}
+	patch := &recordingTool{name: tools.PatchToolName}
+	bash := &recordingTool{name: tools.BashToolName}
+	toolSet := []tools.BaseTool{
+		&recordingTool{name: tools.SkillToolName, readOnly: true},
+		edit, patch, bash,
+		&recordingTool{name: tools.ReadToolName, readOnly: true},
+	}
+	infraEdit := `{"file_path":"infra/main.tf","old_string":"a","new_string":"b"}`
+	srcEdit := `{"file_path":"src/app.go","old_string":"a","new_string":"b"}`
+
+	t.Run("writes outside the skill's directories are blocked", func(t *testing.T) {
+		edit.runs = nil
+		ctx := withSkillScope(context.Background())
+		results := runToolCallBatch(ctx, toolSet, []message.ToolCall{
+			{ID: "1", Name: tools.SkillToolName, Input: `{"name":"infra-ops"}`},
+		}, 4)
+		if results[0].response.IsError {
+			t.Fatalf("skill call failed: %s", results[0].response.Content)
+		}
+
+		results = runToolCallBatch(ctx, toolSet, []message.ToolCall{
+			{ID: "2", Name: tools.EditToolName, Input: infraEdit},
+			{ID: "3", Name: tools.EditToolName, Input: srcEdit},
+		}, 4)
+		if results[0].response.IsError {
+			t.Errorf("edit of infra/main.tf was blocked: %s", results[0].response.Content)
+		}
+		blocked := results[1].response
+		if !blocked.IsError || !strings.Contains(blocked.Content, "src/app.go is outside the directories the infra-ops skill may change (infra/**)") {
+			t.Errorf("edit of src/app.go = %+v, want it blocked", blocked)
+		}
+		if blocked.ErrorDetail == nil || blocked.ErrorDetail.Code != tools.ErrorCodePermissionDenied {
+			t.Errorf("error detail = %+v, want %s", blocked.ErrorDetail, tools.ErrorCodePermissionDenied)
+		}
+		if results[1].err != nil {
+			t.Errorf("blocked edit returned error %v; it must not cancel the batch", results[1].err)
+		}
+		if len(edit.runs) != 1 || edit.runs[0] != infraEdit {
+			t.Errorf("edit ran with %q, want only the infra edit", edit.runs)
+		}
+	})
+
+	t.Run("calls alongside the skill call are restricted", func(t *testing.T) {
+		edit.runs = nil
+		ctx := withSkillScope(context.Background())
+		results := runToolCallBatch(ctx, toolSet, []message.ToolCall{
+			{ID: "1", Name: tools.SkillToolName, Input: `{"name":"infra-ops"}`},
+			{ID: "2", Name: tools.EditToolName, Input: srcEdit},
+		}, 4)
+		if !results[1].response.IsError {
+			t.Error("edit of src/app.go in the skill's batch was not blocked")
+		}
+		if len(edit.runs) != 0 {
+			t.Errorf("edit ran with %q", edit.runs)
+		}
+	})
+
+	t.Run("every path of a patch is checked", func(t *testing.T) {
+		patch.runs = nil
+		ctx := withSkillScope(context.Background())
+		infraPatch := `{"patch_text":"*** Begin Patch\n*** Update File: infra/main.tf\n@@\n-a\n+b\n*** End Patch"}`
+		movePatch := `{"patch_text":"*** Begin Patch\n*** Update File: infra/main.tf\n*** Move to: src/main.tf\n@@\n-a\n+b\n*** End Patch"}`
+		cwdPatch := `{"cwd":"src","patch_text":"*** Begin Patch\n*** Update File: infra/main.tf\n@@\n-a\n+b\n*** End Patch"}`
+		results := runToolCallBatch(ctx, toolSet, []message.ToolCall{
+			{ID: "1", Name: tools.SkillToolName, Input: `{"name":"infra-ops"}`},
+			{ID: "2", Name: tools.PatchToolName, Input: infraPatch},
+			{ID: "3", Name: tools.PatchToolName, Input: movePatch},
+			{ID: "4", Name: tools.PatchToolName, Input: cwdPatch},
+		}, 4)
+		if results[1].response.IsError {
+			t.Errorf("patch of infra/main.tf was blocked: %s", results[1].response.Content)
+		}
+		if !strings.Contains(results[2].response.Content, "src/main.tf is outside the directories") {
+			t.Errorf("patch moving to src/main.tf = %+v, want it blocked", results[2].response)
+		}
+		if !strings.Contains(results[3].response.Content, "src/infra/main.tf is outside the directories") {
+			t.Errorf("patch with cwd src = %+v, want it blocked", results[3].response)
+		}
+		if len(patch.runs) != 1 || patch.runs[0] != infraPatch {
+			t.Errorf("patch ran with %q, want only the infra patch", patch.runs)
+		}
+	})
+
+	t.Run("calls with unknown writes are refused", func(t *testing.T) {
+		bash.runs = nil
+		ctx := withSkillScope(context.Background())
+		results := runToolCallBatch(ctx, toolSet, []message.ToolCall{
+			{ID: "1", Name: tools.SkillToolName, Input: `{"name":"infra-ops"}`},
+			{ID: "2", Name: tools.BashToolName, Input: `{"command":"rm -rf src"}`},
+			{ID: "3", Name: tools.ReadToolName, Input: `{"file_path":"src/app.go"}`},
+		}, 4)
+		refused := results[1].response
+		if !refused.IsError || !strings.Contains(refused.Content, "bash cannot run while the infra-ops skill is in use") {
+			t.Errorf("bash call = %+v, want it refused", refused)
+		}
+		if refused.ErrorDetail == nil || refused.ErrorDetail.Code != tools.ErrorCodePermissionDenied {
+			t.Errorf("error detail = %+v, want %s", refused.ErrorDetail, tools.ErrorCodePermissionDenied)
+		}
+		if len(bash.runs) != 0 {
+			t.Errorf("bash ran with %q", bash.runs)
+		}
+		if results[2].response.IsError {
+			t.Errorf("read of src/app.go was blocked: %s", results[2].response.Content)
+		}
+	})
+
+	t.Run("turns without the skill are unrestricted", func(t *testing.T) {
+		edit.runs = nil
+		results := runToolCallBatch(withSkillScope(context.Background()), toolSet, []message.ToolCall{
+			{ID: "1", Name: tools.EditToolName, Input: srcEdit},
+		}, 4)
+		if results[0].response.IsError {
+			t.Errorf("edit of src/app.go was blocked: %s", results[0].response.Content)
+		}
+	})
+}
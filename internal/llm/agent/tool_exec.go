@@ -0,0 +1,255 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/metrics"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+// toolRunner executes a single tool call.
+type toolRunner func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error)
+
+// runMeasured runs a tool call and records its latency and outcome in the
+// local session metrics.
+func runMeasured(ctx context.Context, tool tools.BaseTool, call tools.ToolCall) (tools.ToolResponse, error) {
+	start := time.Now()
+	response, err := tool.Run(ctx, call)
+	sessionID, _ := tools.GetContextValues(ctx)
+	metrics.Record(sessionID, call.Name, time.Since(start), err != nil || response.IsError)
+	return response, err
+}
+
+type toolCallResult struct {
+	response tools.ToolResponse
+	err      error
+	// started is false when the call was skipped because an earlier call in
+	// the batch was denied permission or the context was canceled.
+	started bool
+	// duration is how long the call ran, excluding the wait for its turn.
+	duration time.Duration
+}
+
+// fileWriteTools change only the files named in their input.
+var fileWriteTools = map[string]bool{
+	tools.WriteToolName:         true,
+	tools.EditToolName:          true,
+	tools.MultiEditToolName:     true,
+	tools.SearchReplaceToolName: true,
+	tools.DeleteToolName:        true,
+}
+
+// toolCallAccess describes the files a tool call touches. Exclusive calls may
+// have effects that cannot be derived from their input (bash, patch, MCP
+// tools, ...) and are ordered against every other call. Task calls are
+// exclusive too, except against each other: their subagents run side by side
+// on the hivemind pool.
+type toolCallAccess struct {
+	paths     []string
+	writes    bool
+	exclusive bool
+	subagent  bool
+}
+
+// accessForToolCall returns the access of call to a tool described by info.
+// Read-only tools only need ordering against writes to the paths they name.
+func accessForToolCall(call tools.ToolCall, info tools.ToolInfo) toolCallAccess {
+	if call.Name == TaskToolName {
+		return toolCallAccess{exclusive: true, subagent: true}
+	}
+	readOnly := !info.Mutating()
+	if !readOnly && !fileWriteTools[call.Name] {
+		return toolCallAccess{exclusive: true}
+	}
+
+	var input struct {
+		FilePath    string   `json:"file_path"`
+		LSPFilePath string   `json:"filePath"`
+		Path        string   `json:"path"`
+		Paths       []string `json:"paths"`
+		Cwd         string   `json:"cwd"`
+	}
+	if err := json.Unmarshal([]byte(call.Input), &input); err != nil {
+		// Writes with an unreadable target are ordered conservatively
+		return toolCallAccess{exclusive: !readOnly}
+	}
+
+	access := toolCallAccess{writes: !readOnly}
+	for _, p := range append([]string{input.FilePath, input.LSPFilePath, input.Path}, input.Paths...) {
+		if p != "" {
+			access.paths = append(access.paths, normalizeToolPath(p, input.Cwd))
+		}
+	}
+	if access.writes && len(access.paths) == 0 {
+		access.exclusive = true
+	}
+	return access
+}
+
+// normalizeToolPath resolves p against cwd, the directory a call may set,
+// and the working directory.
+func normalizeToolPath(p, cwd string) string {
+	if !filepath.IsAbs(p) && cwd != "" {
+		p = filepath.Join(cwd, p)
+	}
+	if !filepath.IsAbs(p) {
+		if cfg := config.Get(); cfg != nil {
+			p = filepath.Join(cfg.WorkingDir, p)
+		}
+	}
+	return filepath.Clean(p)
+}
+
+// conflicts reports whether two calls must run in their original order.
+func (a toolCallAccess) conflicts(b toolCallAccess) bool {
+	if a.subagent && b.subagent {
+		return false
+	}
+	if a.exclusive || b.exclusive {
+		return true
+	}
+	if !a.writes && !b.writes {
+		return false
+	}
+	for _, pa := range a.paths {
+		for _, pb := range b.paths {
+			if pathsOverlap(pa, pb) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pathsOverlap reports whether a and b are the same path or one contains the other.
+func pathsOverlap(a, b string) bool {
+	if a == b {
+		return true
+	}
+	sep := string(filepath.Separator)
+	return strings.HasPrefix(a, strings.TrimSuffix(b, sep)+sep) || strings.HasPrefix(b, strings.TrimSuffix(a, sep)+sep)
+}
+
+// runToolCalls runs calls with at most maxParallel executing at once. infos
+// describes the tools called, by name. A call waits for every earlier call it
+// conflicts with, so edits to the same file
+// keep their order while independent calls overlap. Task calls do not take
+// one of the maxParallel slots, since run bounds them on the hivemind pool.
+// Once a call is denied permission, calls that have not started yet are
+// skipped. Results are returned in the order of calls.
+func runToolCalls(ctx context.Context, calls []tools.ToolCall, infos map[string]tools.ToolInfo, maxParallel int, run toolRunner) []toolCallResult {
+	results := make([]toolCallResult, len(calls))
+	if maxParallel <= 0 {
+		maxParallel = 1
+	}
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	accesses := make([]toolCallAccess, len(calls))
+	done := make([]chan struct{}, len(calls))
+	for i, call := range calls {
+		accesses[i] = accessForToolCall(call, infos[call.Name])
+		done[i] = make(chan struct{})
+	}
+
+	sem := make(chan struct{}, maxParallel)
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+
+			// Dependencies are always earlier calls and are waited for
+			// before taking a slot, so the pool cannot deadlock.
+			for j := range i {
+				if accesses[i].conflicts(accesses[j]) {
+					<-done[j]
+				}
+			}
+
+			if !accesses[i].subagent {
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-batchCtx.Done():
+					results[i] = toolCallResult{err: batchCtx.Err()}
+					return
+				}
+			}
+			if err := batchCtx.Err(); err != nil {
+				results[i] = toolCallResult{err: err}
+				return
+			}
+
+			start := time.Now()
+			response, err := run(ctx, call)
+			if errors.Is(err, permission.ErrorPermissionDenied) {
+				cancel()
+			}
+			results[i] = toolCallResult{response: response, err: err, started: true, duration: time.Since(start)}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// runToolCallBatch runs the tool calls of one assistant message that resolve
+// to a tool in toolSet and have valid input. Task calls run on the hivemind
+// worker pool. File writes outside the allowed directories of a skill loaded
+// in the turn are rejected. Results are keyed by tool call index.
+func runToolCallBatch(ctx context.Context, toolSet []tools.BaseTool, toolCalls []message.ToolCall, maxParallel int) map[int]toolCallResult {
+	byName := make(map[string]tools.BaseTool, len(toolSet))
+	infos := make(map[string]tools.ToolInfo, len(toolSet))
+	for _, t := range toolSet {
+		if info := t.Info(); byName[info.Name] == nil {
+			byName[info.Name] = t
+			infos[info.Name] = info
+		}
+	}
+
+	var indexes []int
+	var calls []tools.ToolCall
+	for i, toolCall := range toolCalls {
+		if byName[toolCall.Name] == nil || !tools.IsValidToolInput(toolCall.Input) {
+			continue
+		}
+		indexes = append(indexes, i)
+		calls = append(calls, tools.ToolCall{
+			ID:    toolCall.ID,
+			Name:  toolCall.Name,
+			Input: toolCall.Input,
+		})
+	}
+
+	scope := skillScopeFrom(ctx)
+	scope.load(toolCalls)
+	subagents := newSubagentPool(hivemindLimits())
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		if response, blocked := scope.check(call, infos[call.Name]); blocked {
+			return response, nil
+		}
+		if call.Name == TaskToolName {
+			return subagents.run(ctx, call, func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+				return runMeasured(ctx, byName[call.Name], call)
+			})
+		}
+		return runMeasured(ctx, byName[call.Name], call)
+	}
+	results := make(map[int]toolCallResult, len(calls))
+	for i, result := range runToolCalls(ctx, calls, infos, maxParallel, run) {
+		results[indexes[i]] = result
+	}
+	return results
+}
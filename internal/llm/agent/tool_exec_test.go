@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+// testToolInfos describes the tools the scheduling tests call. Tools missing
+// from it are mutating.
+var testToolInfos = map[string]tools.ToolInfo{
+	tools.ReadToolName: {Name: tools.ReadToolName, ReadOnly: true},
+}
+
+func TestRunToolCallsIndependentReadsRunConcurrently(t *testing.T) {
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		if n == 3 {
+			close(release)
+		}
+		select {
+		case <-release:
+		case <-time.After(time.Second):
+		}
+		return tools.NewTextResponse("read " + call.ID), nil
+	}
+
+	calls := []tools.ToolCall{
+		{ID: "a", Name: tools.ReadToolName, Input: `{"file_path":"/repo/a.go"}`},
+		{ID: "b", Name: tools.ReadToolName, Input: `{"file_path":"/repo/b.go"}`},
+		{ID: "c", Name: tools.ReadToolName, Input: `{"file_path":"/repo/c.go"}`},
+	}
+	results := runToolCalls(context.Background(), calls, testToolInfos, 3, run)
+
+	if got := peak.Load(); got != 3 {
+		t.Errorf("peak concurrency = %d, want 3", got)
+	}
+	for i, result := range results {
+		if !result.started || result.err != nil {
+			t.Fatalf("result %d = %+v", i, result)
+		}
+		if want := "read " + calls[i].ID; result.response.Content != want {
+			t.Errorf("result %d = %q, want %q", i, result.response.Content, want)
+		}
+	}
+}
+
+func TestRunToolCallsSameFileEditsRunInOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	var running atomic.Int32
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		if running.Add(1) > 1 {
+			t.Errorf("call %s overlapped with another edit", call.ID)
+		}
+		defer running.Add(-1)
+		// The first edit is slower, so it would finish last if not serialized
+		if call.ID == "first" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		mu.Lock()
+		order = append(order, call.ID)
+		mu.Unlock()
+		return tools.NewTextResponse("ok"), nil
+	}
+
+	calls := []tools.ToolCall{
+		{ID: "first", Name: tools.EditToolName, Input: `{"file_path":"/repo/main.go","old_string":"a","new_string":"b"}`},
+		{ID: "second", Name: tools.EditToolName, Input: `{"file_path":"/repo/main.go","old_string":"b","new_string":"c"}`},
+	}
+	results := runToolCalls(context.Background(), calls, testToolInfos, 4, run)
+
+	if fmt.Sprint(order) != "[first second]" {
+		t.Errorf("edit order = %v, want [first second]", order)
+	}
+	if got := results[0].duration; got < 30*time.Millisecond {
+		t.Errorf("first duration = %v, want the time the call ran", got)
+	}
+	if got := results[1].duration; got >= 30*time.Millisecond {
+		t.Errorf("second duration = %v, want it to exclude the wait for the first call", got)
+	}
+}
+
+func TestRunToolCallsTaskKeepsItsPosition(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		// The task is slower, so the edit would finish first if it did not wait
+		if call.Name == TaskToolName {
+			time.Sleep(30 * time.Millisecond)
+		}
+		mu.Lock()
+		order = append(order, call.ID)
+		mu.Unlock()
+		return tools.NewTextResponse("ok"), nil
+	}
+
+	calls := []tools.ToolCall{
+		{ID: "task", Name: TaskToolName, Input: `{"prompt":"write the tests"}`},
+		{ID: "edit", Name: tools.EditToolName, Input: `{"file_path":"/repo/main.go","old_string":"a","new_string":"b"}`},
+	}
+	runToolCalls(context.Background(), calls, testToolInfos, 4, run)
+
+	if fmt.Sprint(order) != "[task edit]" {
+		t.Errorf("call order = %v, want [task edit]", order)
+	}
+}
+
+func TestRunToolCallsPermissionDeniedSkipsPending(t *testing.T) {
+	run := func(ctx context.Context, call tools.ToolCall) (tools.ToolResponse, error) {
+		if call.ID == "denied" {
+			return tools.ToolResponse{}, permission.ErrorPermissionDenied
+		}
+		return tools.NewTextResponse("ok"), nil
+	}
+
+	calls := []tools.ToolCall{
+		{ID: "denied", Name: tools.BashToolName, Input: `{"command":"rm -rf build"}`},
+		{ID: "after", Name: tools.BashToolName, Input: `{"command":"ls"}`},
+	}
+	results := runToolCalls(context.Background(), calls, testToolInfos, 2, run)
+
+	if results[1].started {
+		t.Errorf("call after a permission denial should not run, got %+v", results[1])
+	}
+}
+
+func TestToolCallAccessConflicts(t *testing.T) {
+	access := func(name, input string) toolCallAccess {
+		return accessForToolCall(tools.ToolCall{Name: name, Input: input}, testToolInfos[name])
+	}
+
+	tests := []struct {
+		name string
+		a, b toolCallAccess
+		want bool
+	}{
+		{
+			name: "reads never conflict",
+			a:    access(tools.ReadToolName, `{"file_path":"/repo/a.go"}`),
+			b:    access(tools.ReadToolName, `{"file_path":"/repo/a.go"}`),
+		},
+		{
+			name: "edits to different files",
+			a:    access(tools.EditToolName, `{"file_path":"/repo/a.go"}`),
+			b:    access(tools.WriteToolName, `{"file_path":"/repo/b.go"}`),
+		},
+		{
+			name: "read after edit of the same file",
+			a:    access(tools.EditToolName, `{"file_path":"/repo/a.go"}`),
+			b:    access(tools.ReadToolName, `{"file_path":"/repo/./a.go"}`),
+			want: true,
+		},
+		{
+			name: "delete of a directory and a write inside it",
+			a:    access(tools.DeleteToolName, `{"path":"/repo/pkg"}`),
+			b:    access(tools.WriteToolName, `{"file_path":"/repo/pkg/x.go"}`),
+			want: true,
+		},
+		{
+			name: "bash is ordered against everything",
+			a:    access(tools.BashToolName, `{"command":"go test"}`),
+			b:    access(tools.ReadToolName, `{"file_path":"/repo/a.go"}`),
+			want: true,
+		},
+		{
+			name: "task is ordered against a read",
+			a:    access(TaskToolName, `{"prompt":"refactor"}`),
+			b:    access(tools.ReadToolName, `{"file_path":"/repo/a.go"}`),
+			want: true,
+		},
+		{
+			name: "tasks run side by side",
+			a:    access(TaskToolName, `{"prompt":"one"}`),
+			b:    access(TaskToolName, `{"prompt":"two"}`),
+		},
+		{
+			name: "relative path under a cwd",
+			a:    access(tools.EditToolName, `{"cwd":"sub","file_path":"a.go"}`),
+			b:    access(tools.ReadToolName, `{"file_path":"sub/a.go"}`),
+			want: true,
+		},
+		{
+			name: "same relative path under different cwds",
+			a:    access(tools.EditToolName, `{"cwd":"one","file_path":"a.go"}`),
+			b:    access(tools.EditToolName, `{"cwd":"two","file_path":"a.go"}`),
+		},
+		{
+			name: "similar prefixes are distinct files",
+			a:    access(tools.WriteToolName, `{"file_path":"/repo/a.go"}`),
+			b:    access(tools.WriteToolName, `{"file_path":"/repo/a.go.bak"}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.conflicts(tt.b); got != tt.want {
+				t.Errorf("conflicts() = %v, want %v", got, tt.want)
+			}
+			if got := tt.b.conflicts(tt.a); got != tt.want {
+				t.Errorf("conflicts() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
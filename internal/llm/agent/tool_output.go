@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+const (
+	// toolOutputContextShare is the share of the model's context window a
+	// single tool result may take before it is condensed.
+	toolOutputContextShare = 0.25
+	// defaultToolOutputTokens is the tool result budget for models that do
+	// not report their context window.
+	defaultToolOutputTokens = 50_000
+	// toolOutputDirName is the directory, under the data directory, that
+	// oversized tool results are saved to.
+	toolOutputDirName = "tool-output"
+)
+
+// toolOutputBudget returns how many tokens a single tool result may take in
+// the context of model.
+func toolOutputBudget(model models.Model) int {
+	if model.ContextWindow > 0 {
+		return int(float64(model.ContextWindow) * toolOutputContextShare)
+	}
+	return defaultToolOutputTokens
+}
+
+// condenseToolOutput returns content unchanged when it fits the tool output
+// budget of model. Larger output is saved to a file and replaced by a summary
+// from the summarizer agent when tools.summarizeLargeOutput is set, or else
+// by its first and last lines, either way referencing the saved file.
+func (a *agent) condenseToolOutput(ctx context.Context, sessionID string, model models.Model, toolName, content string) string {
+	budget := toolOutputBudget(model)
+	// Rough estimation: ~4 characters per token
+	if len(content)/4 <= budget {
+		return content
+	}
+
+	savedNote := ""
+	if path, err := saveToolOutput(toolName, content); err != nil {
+		logging.Warn("Failed to save oversized tool output", "tool", toolName, "error", err)
+	} else {
+		savedNote = fmt.Sprintf(" The full output is saved to %s; read it with offset and limit for details.", path)
+	}
+
+	if cfg := config.Get(); cfg != nil && cfg.Tools.SummarizeLargeOutput {
+		summary, err := a.summarizeToolOutput(ctx, sessionID, toolName, content)
+		if err == nil {
+			return fmt.Sprintf("<tool_output_summary>\n%s\n</tool_output_summary>\n\n(The output of %s was %d bytes, too large for the context, and was summarized.%s)",
+				summary, toolName, len(content), savedNote)
+		}
+		logging.Warn("Failed to summarize oversized tool output, truncating it instead", "tool", toolName, "error", err)
+	}
+	return fmt.Sprintf("%s\n\n(The output of %s was %d bytes, too large for the context, and was cut to its first and last lines.%s)",
+		truncateHeadTail(content, budget*4), toolName, len(content), savedNote)
+}
+
+// summarizeToolOutput asks the summarizer agent to condense the output of
+// toolName, charging the call to the session. Output beyond the summarizer's
+// own budget is cut to its first and last lines first.
+func (a *agent) summarizeToolOutput(ctx context.Context, sessionID, toolName, content string) (string, error) {
+	if a.summarizeProvider == nil {
+		return "", errors.New("summarize provider not available")
+	}
+	prompt, err := AgentPrompts.ReadFile("prompts/tool_output.md")
+	if err != nil {
+		return "", fmt.Errorf("failed to load tool output prompt: %w", err)
+	}
+
+	content = truncateHeadTail(content, toolOutputBudget(a.summarizeProvider.Model())*4)
+	text := fmt.Sprintf("%s\n\n<tool_output tool=%q>\n%s\n</tool_output>", prompt, toolName, content)
+	response, err := a.summarizeProvider.SendMessages(ctx, []message.Message{{
+		Role:  message.User,
+		Parts: []message.ContentPart{message.TextContent{Text: text}},
+	}}, make([]tools.BaseTool, 0))
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize: %w", err)
+	}
+	if err := a.chargeUsage(ctx, sessionID, a.summarizeProvider.Model(), response.Usage); err != nil {
+		logging.Warn("Failed to track tool output summary usage", "session_id", sessionID, "error", err)
+	}
+	summary := strings.TrimSpace(response.Content)
+	if summary == "" {
+		return "", errors.New("empty summary returned")
+	}
+	return summary, nil
+}
+
+// toolOutputDir returns the directory oversized tool results are saved to.
+func toolOutputDir() string {
+	dataDir := ".opencode"
+	if cfg := config.Get(); cfg != nil && cfg.Data.Directory != "" {
+		dataDir = cfg.Data.Directory
+	}
+	return filepath.Join(fileutil.ResolvePath(dataDir, config.WorkingDirectory()), toolOutputDirName)
+}
+
+// saveToolOutput writes the full output of toolName to the tool output
+// directory and returns the path of the file.
+func saveToolOutput(toolName, content string) (string, error) {
+	dir := toolOutputDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create tool output directory: %w", err)
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d.txt", toolName, time.Now().UnixNano()))
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write tool output: %w", err)
+	}
+	return path, nil
+}
+
+// truncateHeadTail keeps the first and last lines of content that fit in
+// maxChars together, with a marker counting the lines left out between them.
+func truncateHeadTail(content string, maxChars int) string {
+	if len(content) <= maxChars {
+		return content
+	}
+	half := maxChars / 2
+	head := content[:half]
+	if idx := strings.LastIndex(head, "\n"); idx > 0 {
+		head = head[:idx]
+	}
+	tail := content[len(content)-half:]
+	// Drop the partial line the tail starts in, if any
+	if content[len(content)-half-1] != '\n' {
+		if idx := strings.Index(tail, "\n"); idx >= 0 && idx < len(tail)-1 {
+			tail = tail[idx+1:]
+		}
+	}
+	omitted := strings.Count(content[len(head):len(content)-len(tail)], "\n") - 1
+	return fmt.Sprintf("%s\n\n[... %d lines omitted ...]\n\n%s", head, max(omitted, 0), tail)
+}
@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/provider"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+// stubSummarizer answers every request with summary, or fails with err.
+type stubSummarizer struct {
+	provider.Provider
+	summary string
+	err     error
+	prompts []string
+}
+
+func (s *stubSummarizer) SendMessages(_ context.Context, msgs []message.Message, _ []tools.BaseTool) (*provider.ProviderResponse, error) {
+	s.prompts = append(s.prompts, msgs[len(msgs)-1].Content().String())
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &provider.ProviderResponse{Content: s.summary, Usage: provider.TokenUsage{InputTokens: 20_000, OutputTokens: 1_000}}, nil
+}
+
+func (s *stubSummarizer) Model() models.Model {
+	return models.Model{ContextWindow: 100_000, CostPer1MIn: 1, CostPer1MOut: 5}
+}
+
+var savedToolOutputPattern = regexp.MustCompile(`saved to (\S+);`)
+
+func TestCondenseToolOutput(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	oldData, oldTools := cfg.Data, cfg.Tools
+	cfg.Data.Directory = t.TempDir()
+	defer func() { cfg.Data, cfg.Tools = oldData, oldTools }()
+
+	// A budget of 250 tokens, about 1000 characters
+	model := models.Model{ContextWindow: 1000}
+	var sb strings.Builder
+	for i := range 200 {
+		fmt.Fprintf(&sb, "--- FAIL: TestCase%03d\n", i)
+	}
+	output := sb.String()
+
+	assertSaved := func(t *testing.T, condensed string) {
+		t.Helper()
+		match := savedToolOutputPattern.FindStringSubmatch(condensed)
+		if match == nil {
+			t.Fatalf("condensed output %q does not reference the saved output", condensed)
+		}
+		saved, err := os.ReadFile(match[1])
+		if err != nil {
+			t.Fatalf("reading saved output: %v", err)
+		}
+		if string(saved) != output {
+			t.Errorf("saved output has %d bytes, want the %d bytes of the raw output", len(saved), len(output))
+		}
+	}
+
+	t.Run("output within budget is unchanged", func(t *testing.T) {
+		a := &agent{sessions: &memSessions{}}
+		if got := a.condenseToolOutput(t.Context(), "session", model, tools.BashToolName, "ok\n"); got != "ok\n" {
+			t.Errorf("condenseToolOutput() = %q, want the output unchanged", got)
+		}
+	})
+
+	t.Run("summarized with the summarizer agent", func(t *testing.T) {
+		cfg.Tools.SummarizeLargeOutput = true
+		defer func() { cfg.Tools.SummarizeLargeOutput = false }()
+		summarizer := &stubSummarizer{summary: "200 tests failed, TestCase000 to TestCase199"}
+		a := &agent{summarizeProvider: summarizer, sessions: &memSessions{}}
+
+		got := a.condenseToolOutput(t.Context(), "session", model, tools.BashToolName, output)
+		if !strings.HasPrefix(got, "<tool_output_summary>\n200 tests failed, TestCase000 to TestCase199\n</tool_output_summary>") {
+			t.Errorf("condenseToolOutput() = %q, want the summary", got)
+		}
+		if len(summarizer.prompts) != 1 || !strings.Contains(summarizer.prompts[0], "--- FAIL: TestCase199") {
+			t.Errorf("summarizer prompts = %q, want one prompt with the output", summarizer.prompts)
+		}
+		sess := a.sessions.(*memSessions).sess
+		if sess.Cost != 0.025 || sess.TotalPromptTokens != 20_000 || sess.TotalCompletionTokens != 1_000 {
+			t.Errorf("session cost = %v, tokens = %d/%d; want the summary charged to the session",
+				sess.Cost, sess.TotalPromptTokens, sess.TotalCompletionTokens)
+		}
+		assertSaved(t, got)
+	})
+
+	t.Run("truncated when summarizing fails", func(t *testing.T) {
+		cfg.Tools.SummarizeLargeOutput = true
+		defer func() { cfg.Tools.SummarizeLargeOutput = false }()
+		a := &agent{summarizeProvider: &stubSummarizer{err: errors.New("overloaded")}, sessions: &memSessions{}}
+
+		got := a.condenseToolOutput(t.Context(), "session", model, tools.BashToolName, output)
+		if !strings.Contains(got, "was cut to its first and last lines") {
+			t.Errorf("condenseToolOutput() = %q, want the truncated output", got)
+		}
+		assertSaved(t, got)
+	})
+
+	t.Run("truncated when summarizing is disabled", func(t *testing.T) {
+		summarizer := &stubSummarizer{summary: "unused"}
+		a := &agent{summarizeProvider: summarizer, sessions: &memSessions{}}
+
+		got := a.condenseToolOutput(t.Context(), "session", model, tools.BashToolName, output)
+		if len(summarizer.prompts) != 0 {
+			t.Error("the summarizer was called although summarizeLargeOutput is off")
+		}
+		for _, want := range []string{"--- FAIL: TestCase000\n", "lines omitted", "--- FAIL: TestCase199\n"} {
+			if !strings.Contains(got, want) {
+				t.Errorf("condenseToolOutput() = %q, want it to contain %q", got, want)
+			}
+		}
+		if strings.Contains(got, "TestCase100") {
+			t.Error("the middle of the output was kept")
+		}
+		assertSaved(t, got)
+	})
+}
+
+func TestTruncateHeadTail(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\nfive\nsix\n"
+	if got := truncateHeadTail(content, 100); got != content {
+		t.Errorf("truncateHeadTail() = %q, want content that fits unchanged", got)
+	}
+	want := "one\ntwo\n\n[... 2 lines omitted ...]\n\nfive\nsix\n"
+	if got := truncateHeadTail(content, 18); got != want {
+		t.Errorf("truncateHeadTail() = %q, want %q", got, want)
+	}
+}
@@ -26,6 +26,14 @@ var (
 		tools.GlobToolName,
 		tools.GrepToolName,
 		tools.ReadToolName,
+		tools.ReadManyToolName,
+		tools.ReadSymbolToolName,
+		tools.ExistsToolName,
+		tools.RepoOverviewToolName,
+		tools.DirDiffToolName,
+		tools.GitDiffRangeToolName,
+		tools.EnvInfoToolName,
+		tools.ProposeEditToolName,
 		tools.ViewImageToolName,
 		tools.WebFetchToolName,
 		tools.SkillToolName,
@@ -35,8 +43,10 @@ var (
 		tools.WriteToolName,
 		tools.EditToolName,
 		tools.MultiEditToolName,
+		tools.SearchReplaceToolName,
 		tools.DeleteToolName,
 		tools.PatchToolName,
+		tools.ApplyProposalToolName,
 		tools.BashToolName,
 	}
 	// TODO: add todo tool
@@ -46,6 +56,32 @@ var (
 		tools.UpdateStepToolName,
 	}
 
+	// callDirectoryToolNames take paths or run commands, so their calls
+	// accept a cwd parameter that relative paths are resolved against.
+	callDirectoryToolNames = map[string]bool{
+		tools.LSToolName:            true,
+		tools.GlobToolName:          true,
+		tools.GrepToolName:          true,
+		tools.ReadToolName:          true,
+		tools.ReadManyToolName:      true,
+		tools.ReadSymbolToolName:    true,
+		tools.ExistsToolName:        true,
+		tools.DirDiffToolName:       true,
+		tools.GitDiffRangeToolName:  true,
+		tools.ProposeEditToolName:   true,
+		tools.ViewImageToolName:     true,
+		tools.WriteToolName:         true,
+		tools.EditToolName:          true,
+		tools.MultiEditToolName:     true,
+		tools.SearchReplaceToolName: true,
+		tools.DeleteToolName:        true,
+		tools.PatchToolName:         true,
+		tools.BashToolName:          true,
+		tools.LSPToolName:           true,
+		tools.DiagnosticsToolName:   true,
+		tools.RenameSymbolToolName:  true,
+	}
+
 	// Shared task service instance for PlanTaskTool and UpdateStepTool
 	taskService     task.Service
 	taskServiceOnce sync.Once
@@ -76,7 +112,7 @@ func NewToolSet(
 	agentID := info.ID
 	result := make(chan tools.BaseTool, 100)
 
-	createTool := func(name string) tools.BaseTool {
+	newTool := func(name string) tools.BaseTool {
 		switch name {
 		case tools.LSToolName:
 			return tools.NewLsTool(config.Get())
@@ -86,6 +122,22 @@ func NewToolSet(
 			return tools.NewGrepTool()
 		case tools.ReadToolName:
 			return tools.NewViewTool(lspService)
+		case tools.ReadManyToolName:
+			return tools.NewReadManyTool()
+		case tools.ReadSymbolToolName:
+			return tools.NewReadSymbolTool(lspService)
+		case tools.ExistsToolName:
+			return tools.NewExistsTool()
+		case tools.RepoOverviewToolName:
+			return tools.NewRepoOverviewTool(config.Get())
+		case tools.DirDiffToolName:
+			return tools.NewDirDiffTool()
+		case tools.GitDiffRangeToolName:
+			return tools.NewGitDiffRangeTool()
+		case tools.EnvInfoToolName:
+			return tools.NewEnvInfoTool()
+		case tools.ProposeEditToolName:
+			return tools.NewProposeEditTool()
 		case tools.ViewImageToolName:
 			return tools.NewViewImageTool()
 		case tools.WebFetchToolName:
@@ -102,10 +154,14 @@ func NewToolSet(
 			return tools.NewEditTool(lspService, permissions, historyService, reg)
 		case tools.MultiEditToolName:
 			return tools.NewMultiEditTool(lspService, permissions, historyService, reg)
+		case tools.SearchReplaceToolName:
+			return tools.NewSearchReplaceTool(lspService, permissions, historyService, reg)
 		case tools.DeleteToolName:
 			return tools.NewDeleteTool(permissions, historyService, reg)
 		case tools.PatchToolName:
 			return tools.NewPatchTool(lspService, permissions, historyService, reg)
+		case tools.ApplyProposalToolName:
+			return tools.NewApplyProposalTool(lspService, permissions, historyService, reg)
 		case tools.BashToolName:
 			return tools.NewBashTool(permissions, reg)
 		case TaskToolName:
@@ -118,6 +174,13 @@ func NewToolSet(
 			return nil
 		}
 	}
+	createTool := func(name string) tools.BaseTool {
+		t := newTool(name)
+		if t != nil && callDirectoryToolNames[name] {
+			t = tools.WithCallDirectory(t)
+		}
+		return t
+	}
 
 	for _, name := range viewerToolNames {
 		if reg.IsToolEnabled(agentID, name) {
@@ -195,8 +258,17 @@ func NewToolSet(
 		defer logging.RecoverPanic("LSP-goroutine", nil)
 		defer wg.Done()
 		cfg := config.Get()
-		if len(install.ResolveServers(cfg)) > 0 && reg.IsToolEnabled(agentID, tools.LSPToolName) {
-			result <- tools.NewLspTool(lspService)
+		if len(install.ResolveServers(cfg)) == 0 {
+			return
+		}
+		if reg.IsToolEnabled(agentID, tools.LSPToolName) {
+			result <- tools.WithCallDirectory(tools.NewLspTool(lspService))
+		}
+		if reg.IsToolEnabled(agentID, tools.DiagnosticsToolName) {
+			result <- tools.WithCallDirectory(tools.NewDiagnosticsTool(lspService))
+		}
+		if reg.IsToolEnabled(agentID, tools.RenameSymbolToolName) {
+			result <- tools.WithCallDirectory(tools.NewRenameSymbolTool(lspService, permissions, historyService, reg))
 		}
 	}()
 
@@ -213,6 +285,7 @@ func (a *agent) resolveTools() []tools.BaseTool {
 		toolSet := make([]tools.BaseTool, 0, 20)
 		toolNames := make([]string, 0, 20)
 		for t := range a.toolsCh {
+			t = tools.WithLocalizedDescriptions(t)
 			toolSet = append(toolSet, t)
 			toolNames = append(toolNames, t.Info().Name)
 		}
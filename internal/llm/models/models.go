@@ -19,6 +19,7 @@ type Model struct {
 	CanReason                bool          `json:"can_reason"`
 	SupportsAdaptiveThinking bool          `json:"supports_adaptive_thinking"`
 	SupportsMaximumThinking  bool          `json:"supports_maximum_thinking"`
+	SupportsReasoningEffort  bool          `json:"supports_reasoning_effort"`
 	SupportsAttachments      bool          `json:"supports_attachments"`
 }
 
@@ -9,6 +9,7 @@ const (
 	XAIGrok4FastReasoning     ModelID = "grok-4-fast-reasoning"
 	XAIGrok4FastNonReasoning  ModelID = "grok-4-fast-non-reasoning"
 	XAIGrok40709              ModelID = "grok-4-0709"
+	XAIGrok3Mini              ModelID = "grok-3-mini"
 )
 
 var XAIModels = map[ModelID]Model{
@@ -23,6 +24,7 @@ var XAIModels = map[ModelID]Model{
 		CostPer1MOutCached: 0,
 		ContextWindow:      2_000_000,
 		DefaultMaxTokens:   64_000,
+		CanReason:          true,
 	},
 	XAIGrok41FastNonReasoning: {
 		ID:                 XAIGrok41FastNonReasoning,
@@ -47,6 +49,7 @@ var XAIModels = map[ModelID]Model{
 		CostPer1MOutCached: 0,
 		ContextWindow:      256_000,
 		DefaultMaxTokens:   32_000,
+		CanReason:          true,
 	},
 	XAIGrok4FastReasoning: {
 		ID:                 XAIGrok4FastReasoning,
@@ -59,6 +62,7 @@ var XAIModels = map[ModelID]Model{
 		CostPer1MOutCached: 0,
 		ContextWindow:      2_000_000,
 		DefaultMaxTokens:   64_000,
+		CanReason:          true,
 	},
 	XAIGrok4FastNonReasoning: {
 		ID:                 XAIGrok4FastNonReasoning,
@@ -83,5 +87,20 @@ var XAIModels = map[ModelID]Model{
 		CostPer1MOutCached: 0.75,
 		ContextWindow:      256_000,
 		DefaultMaxTokens:   20_000,
+		CanReason:          true,
+	},
+	XAIGrok3Mini: {
+		ID:                      XAIGrok3Mini,
+		Name:                    "Grok 3 Mini",
+		Provider:                ProviderXAI,
+		APIModel:                "grok-3-mini",
+		CostPer1MIn:             0.30,
+		CostPer1MInCached:       0.075,
+		CostPer1MOut:            0.50,
+		CostPer1MOutCached:      0,
+		ContextWindow:           131_072,
+		DefaultMaxTokens:        16_000,
+		CanReason:               true,
+		SupportsReasoningEffort: true,
 	},
 }
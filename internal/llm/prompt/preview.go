@@ -0,0 +1,79 @@
+package prompt
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+// ContextPreview is the system message an agent would be sent, together with
+// the context files it includes and a rough size estimate.
+type ContextPreview struct {
+	Agent           config.AgentName
+	SystemMessage   string
+	ContextFiles    []string // Context file paths, relative to the working directory
+	SecretWarnings  []string // Lines in context files that look like secrets
+	EstimatedTokens int64
+}
+
+// secretPatterns match common credential formats. They are only used to warn
+// before context files are sent, so false positives are acceptable.
+var secretPatterns = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"AWS access key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"API key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}\b`)},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(api[_-]?key|secret|token|password)\b\s*[:=]\s*["']?[A-Za-z0-9_\-/+]{16,}`)},
+}
+
+// PreviewContext assembles the system message agentName would receive with a
+// model from provider and estimates its token count. Context files are
+// scanned for likely secrets so they can be removed before a session starts.
+func PreviewContext(agentName config.AgentName, provider models.ModelProvider) ContextPreview {
+	cfg := config.Get()
+	preview := ContextPreview{
+		Agent:         agentName,
+		SystemMessage: GetAgentPrompt(agentName, provider),
+	}
+
+	for _, f := range readContextFiles(contextDirs(cfg.WorkingDir), cfg.ContextPaths) {
+		path := f.path
+		if rel, err := filepath.Rel(cfg.WorkingDir, f.path); err == nil {
+			path = rel
+		}
+		preview.ContextFiles = append(preview.ContextFiles, path)
+		preview.SecretWarnings = append(preview.SecretWarnings, scanSecrets(path, f.content)...)
+	}
+	slices.Sort(preview.ContextFiles)
+	slices.Sort(preview.SecretWarnings)
+
+	preview.EstimatedTokens = message.EstimateTokens([]message.Message{{
+		Role:  message.System,
+		Parts: []message.ContentPart{message.TextContent{Text: preview.SystemMessage}},
+	}}, nil)
+	return preview
+}
+
+// scanSecrets returns a warning for each line of content that matches a
+// secret pattern.
+func scanSecrets(path, content string) []string {
+	var warnings []string
+	for i, line := range strings.Split(content, "\n") {
+		for _, p := range secretPatterns {
+			if p.re.MatchString(line) {
+				warnings = append(warnings, fmt.Sprintf("%s:%d: possible %s", path, i+1, p.name))
+				break
+			}
+		}
+	}
+	return warnings
+}
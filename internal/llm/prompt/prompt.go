@@ -6,9 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
@@ -53,6 +55,7 @@ func lspInformation() string {
 Tools that support it will also include useful diagnostics such as linting and typechecking.
 - These diagnostics will be automatically enabled when you run the tool, and will be displayed in the output at the bottom within the <file_diagnostics></file_diagnostics> and <project_diagnostics></project_diagnostics> tags.
 - Take necessary actions to fix the issues.
+- Use the diagnostics tool to re-check a file on demand, for example after a series of edits.
 - You should ignore diagnostics of files that you did not change or are not related or caused by your changes unless the user explicitly asks you to fix them.
 `
 }
@@ -119,65 +122,127 @@ func GetAgentPrompt(agentName config.AgentName, provider models.ModelProvider) s
 var (
 	onceContext    sync.Once
 	contextContent string
+	contextMu      sync.Mutex
 )
 
+func init() {
+	// Context files are resolved relative to the working directory
+	config.OnWorkingDirectoryChange(func(string) { resetContextFromPaths() })
+}
+
+// resetContextFromPaths drops the cached context so it is read again on the
+// next prompt.
+func resetContextFromPaths() {
+	contextMu.Lock()
+	defer contextMu.Unlock()
+	onceContext = sync.Once{}
+	contextContent = ""
+}
+
 func getContextFromPaths() string {
+	contextMu.Lock()
+	defer contextMu.Unlock()
 	onceContext.Do(func() {
 		var (
 			cfg          = config.Get()
 			workDir      = cfg.WorkingDir
 			contextPaths = cfg.ContextPaths
 		)
-		contextContent = processContextPaths(workDir, contextPaths)
+		contextContent = processContextPaths(workDir, contextPaths, cfg.ContextMaxBytes)
 		logging.Debug("Context content", "context", contextContent)
 	})
 
 	return contextContent
 }
 
-func processContextPaths(workDir string, paths []string) string {
+type contextFile struct {
+	path    string
+	content string
+}
+
+// processContextPaths reads the context files for paths and formats them for
+// the system prompt. Paths are looked up in workDir and each parent up to the
+// worktree root. When maxBytes is positive and the files are larger combined,
+// the largest files are truncated to fit.
+func processContextPaths(workDir string, paths []string, maxBytes int) string {
+	files := readContextFiles(contextDirs(workDir), paths)
+
+	if truncated := truncateContextFiles(files, maxBytes); len(truncated) > 0 {
+		logging.Warn("Context files exceed contextMaxBytes and were truncated",
+			"limit", maxBytes, "files", truncated)
+	}
+
+	results := make([]string, 0, len(files))
+	for _, f := range files {
+		results = append(results, "# From:"+f.path+"\n"+f.content)
+	}
+	return strings.Join(results, "\n")
+}
+
+// contextDirs returns workDir and its parents up to the worktree root, nearest
+// first.
+func contextDirs(workDir string) []string {
+	root := config.WorktreeRoot(workDir)
+	dirs := []string{workDir}
+	for dir := workDir; dir != root; {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// readContextFiles reads the files named by paths in each of dirs, walking
+// entries with a trailing slash as directories. Each file is read once even
+// when several paths or symlinks lead to it.
+func readContextFiles(dirs []string, paths []string) []contextFile {
 	var (
 		wg       sync.WaitGroup
-		resultCh = make(chan string)
+		resultCh = make(chan contextFile)
 	)
 
 	// Track processed files to avoid duplicates
 	processedFiles := make(map[string]bool)
 	var processedMutex sync.Mutex
 
-	for _, path := range paths {
-		wg.Add(1)
-		go func(p string) {
-			defer wg.Done()
+	for _, workDir := range dirs {
+		for _, path := range paths {
+			wg.Add(1)
+			go func(workDir, p string) {
+				defer wg.Done()
 
-			if strings.HasSuffix(p, "/") {
-				// Walk the directory (error is handled inside the walk function)
-				_ = filepath.WalkDir(filepath.Join(workDir, p), func(path string, d os.DirEntry, errors error) error {
-					if errors != nil {
-						return errors // This stops the walk for this specific path
-					}
-					if !d.IsDir() {
-						if tryMarkProcessed(path, processedFiles, &processedMutex) {
-							if result := processFile(path); result != "" {
-								resultCh <- result
+				if strings.HasSuffix(p, "/") {
+					// Walk the directory (error is handled inside the walk function)
+					_ = filepath.WalkDir(filepath.Join(workDir, p), func(path string, d os.DirEntry, errors error) error {
+						if errors != nil {
+							return errors // This stops the walk for this specific path
+						}
+						if !d.IsDir() {
+							if tryMarkProcessed(path, processedFiles, &processedMutex) {
+								if result, ok := processFile(path); ok {
+									resultCh <- result
+								}
 							}
 						}
-					}
-					return nil
-				})
-
-				// if _err != nil {
-				//	return _err // Handle the error as needed, e.g., log it and decide whether to continue or return
-				//}
-			} else {
-				fullPath := filepath.Join(workDir, p)
-				if tryMarkProcessed(fullPath, processedFiles, &processedMutex) {
-					if result := processFile(fullPath); result != "" {
-						resultCh <- result
+						return nil
+					})
+
+					// if _err != nil {
+					//	return _err // Handle the error as needed, e.g., log it and decide whether to continue or return
+					//}
+				} else {
+					fullPath := filepath.Join(workDir, p)
+					if tryMarkProcessed(fullPath, processedFiles, &processedMutex) {
+						if result, ok := processFile(fullPath); ok {
+							resultCh <- result
+						}
 					}
 				}
-			}
-		}(path)
+			}(workDir, path)
+		}
 	}
 
 	go func() {
@@ -185,12 +250,58 @@ func processContextPaths(workDir string, paths []string) string {
 		close(resultCh)
 	}()
 
-	results := make([]string, 0)
+	files := make([]contextFile, 0)
 	for result := range resultCh {
-		results = append(results, result)
+		files = append(files, result)
 	}
+	return files
+}
 
-	return strings.Join(results, "\n")
+// truncateContextFiles shrinks the largest files so the combined content fits
+// in maxBytes. Every file keeps at most the same share of the budget, so small
+// files stay whole. It returns the paths of the truncated files.
+func truncateContextFiles(files []contextFile, maxBytes int) []string {
+	total := 0
+	for _, f := range files {
+		total += len(f.content)
+	}
+	if maxBytes <= 0 || total <= maxBytes {
+		return nil
+	}
+
+	sizes := make([]int, len(files))
+	for i, f := range files {
+		sizes[i] = len(f.content)
+	}
+	slices.Sort(sizes)
+
+	// Find the per-file cap: files below an even share of the remaining
+	// budget keep their size and hand the rest to the larger files.
+	limit, remaining := 0, maxBytes
+	for i, size := range sizes {
+		share := remaining / (len(sizes) - i)
+		if size > share {
+			limit = share
+			break
+		}
+		remaining -= size
+	}
+
+	var truncated []string
+	for i, f := range files {
+		if len(f.content) <= limit {
+			continue
+		}
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(f.content[cut]) {
+			cut--
+		}
+		files[i].content = f.content[:cut] + fmt.Sprintf("\n[... truncated: %d of %d bytes omitted to fit contextMaxBytes]\n",
+			len(f.content)-cut, len(f.content))
+		truncated = append(truncated, f.path)
+	}
+	slices.Sort(truncated)
+	return truncated
 }
 
 // tryMarkProcessed resolves symlinks to obtain the canonical path and uses it
@@ -212,10 +323,10 @@ func tryMarkProcessed(path string, processed map[string]bool, mu *sync.Mutex) bo
 	return true
 }
 
-func processFile(filePath string) string {
+func processFile(filePath string) (contextFile, bool) {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return ""
+		return contextFile{}, false
 	}
-	return "# From:" + filePath + "\n" + string(content)
+	return contextFile{path: filePath, content: string(content)}, true
 }
@@ -74,6 +74,9 @@ func newAnthropicClient(opts providerClientOptions) AnthropicClient {
 		}
 	}
 
+	if opts.userAgent != "" {
+		anthropicClientOptions = append(anthropicClientOptions, option.WithHeader(UserAgentHeader, opts.userAgent))
+	}
 	if opts.headers != nil {
 		for k, v := range opts.headers {
 			anthropicClientOptions = append(anthropicClientOptions, option.WithHeader(k, v))
@@ -98,6 +101,15 @@ func newAnthropicClient(opts providerClientOptions) AnthropicClient {
 	}
 }
 
+// anthropicHeaderOptions returns request options setting per-request headers.
+func anthropicHeaderOptions(headers map[string]string) []option.RequestOption {
+	opts := make([]option.RequestOption, 0, len(headers))
+	for key, value := range headers {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+	return opts
+}
+
 func (a *anthropicClient) convertMessages(messages []message.Message) (anthropicMessages []anthropic.MessageParam) {
 	for i, msg := range messages {
 		cache := false
@@ -128,10 +140,6 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 					content.OfText.CacheControl = anthropic.NewCacheControlEphemeralParam()
 				}
 				blocks = append(blocks, content)
-			} else {
-				logging.Warn("Assistant message with empty content reached provider conversion",
-					"message_index", i,
-				)
 			}
 
 			for _, toolCall := range msg.ToolCalls() {
@@ -147,13 +155,6 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 				}
 				blocks = append(blocks, anthropic.NewToolUseBlock(toolCall.ID, inputMap, toolCall.Name))
 			}
-
-			if len(blocks) == 0 {
-				logging.Warn("Unexpected: assistant message with no content blocks reached provider conversion",
-					"message_index", i, "message_id", msg.ID,
-				)
-				continue
-			}
 			anthropicMessages = append(anthropicMessages, anthropic.NewAssistantMessage(blocks...))
 
 		case message.Tool:
@@ -175,6 +176,10 @@ func (a *anthropicClient) convertMessages(messages []message.Message) (anthropic
 					results[i] = anthropic.NewToolResultBlock(toolResult.ToolCallID, toolResult.Content, toolResult.IsError)
 				}
 			}
+			// Attachments follow the tool results, which must come first
+			for _, binaryContent := range msg.BinaryContent() {
+				results = append(results, anthropic.NewImageBlockBase64(binaryContent.MIMEType, binaryContent.String(models.ProviderAnthropic)))
+			}
 			anthropicMessages = append(anthropicMessages, anthropic.NewUserMessage(results...))
 		}
 	}
@@ -188,7 +193,7 @@ func (a *anthropicClient) convertTools(tools []toolsPkg.BaseTool) []anthropic.To
 		info := tool.Info()
 		toolParam := anthropic.ToolParam{
 			Name:        info.Name,
-			Description: anthropic.String(info.Description),
+			Description: anthropic.String(toolDescription(info)),
 			InputSchema: anthropic.ToolInputSchemaParam{
 				Properties: info.Parameters,
 				Required:   info.Required,
@@ -220,14 +225,27 @@ func (a *anthropicClient) finishReason(reason string) message.FinishReason {
 	}
 }
 
-func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, tools []anthropic.ToolUnionParam) anthropic.MessageNewParams {
+// thinkingBudget returns the extended thinking budget: 80% of max tokens,
+// lowered to the configured maxReasoningTokens.
+func (a *anthropicClient) thinkingBudget() int64 {
+	budget := int64(float64(a.providerOptions.maxTokens) * 0.8)
+	if limit := a.providerOptions.maxReasoningTokens; limit > 0 && limit < budget {
+		budget = limit
+	}
+	return budget
+}
+
+func (a *anthropicClient) preparedMessages(ctx context.Context, messages []anthropic.MessageParam, tools []anthropic.ToolUnionParam) anthropic.MessageNewParams {
 	var thinkingParam anthropic.ThinkingConfigParamUnion
 	var outputConfig anthropic.OutputConfigParam
 	lastMessage := messages[len(messages)-1]
 	isUser := lastMessage.Role == anthropic.MessageParamRoleUser
 	messageContent := ""
-	// TODO: parameterise temperature via agent config
-	temperature := anthropic.Float(0)
+	var temperature param.Opt[float64]
+	if a.providerOptions.temperature != nil {
+		temperature = anthropic.Float(*a.providerOptions.temperature)
+	}
+	thinking := false
 	if isUser {
 		for _, m := range lastMessage.Content {
 			if m.OfText != nil && m.OfText.Text != "" {
@@ -238,7 +256,8 @@ func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, to
 			adaptiveParam := anthropic.NewThinkingConfigAdaptiveParam()
 			thinkingParam = anthropic.ThinkingConfigParamUnion{OfAdaptive: &adaptiveParam}
 			temperature = anthropic.Float(1)
-			effort := a.options.reasoningEffort
+			thinking = true
+			effort := requestReasoningEffort(ctx, a.providerOptions.model, a.options.reasoningEffort)
 			if effort == "" {
 				effort = "high"
 			}
@@ -246,20 +265,22 @@ func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, to
 				Effort: anthropic.OutputConfigEffort(effort),
 			}
 		} else if messageContent != "" && a.options.shouldThink != nil && a.options.shouldThink(messageContent) {
-			thinkingParam = anthropic.ThinkingConfigParamOfEnabled(int64(float64(a.providerOptions.maxTokens) * 0.8))
+			thinkingParam = anthropic.ThinkingConfigParamOfEnabled(a.thinkingBudget())
 			temperature = anthropic.Float(1)
+			thinking = true
 		}
 	}
 
 	// TODO: Consider adding ToolChoice in case of agent having output schema set, however it limits tool calls
-	return anthropic.MessageNewParams{
-		Model:        anthropic.Model(a.providerOptions.model.APIModel),
-		MaxTokens:    a.providerOptions.maxTokens,
-		Temperature:  temperature,
-		Messages:     messages,
-		Tools:        tools,
-		Thinking:     thinkingParam,
-		OutputConfig: outputConfig,
+	params := anthropic.MessageNewParams{
+		Model:         anthropic.Model(a.providerOptions.model.APIModel),
+		MaxTokens:     a.providerOptions.maxTokens,
+		Temperature:   temperature,
+		StopSequences: a.providerOptions.stopSequences,
+		Messages:      messages,
+		Tools:         tools,
+		Thinking:      thinkingParam,
+		OutputConfig:  outputConfig,
 		System: []anthropic.TextBlockParam{
 			{
 				Text:         a.providerOptions.systemMessage,
@@ -267,22 +288,31 @@ func (a *anthropicClient) preparedMessages(messages []anthropic.MessageParam, to
 			},
 		},
 	}
+	// Claude models accept temperature or top_p but not both, so top_p is
+	// only sent when no temperature is. Extended thinking requires a
+	// temperature of 1 and restricts top_p, so it also drops top_p.
+	if a.providerOptions.topP != nil && a.providerOptions.temperature == nil && !thinking {
+		params.TopP = anthropic.Float(*a.providerOptions.topP)
+	}
+	return params
 }
 
 func (a *anthropicClient) send(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) (resposne *ProviderResponse, err error) {
-	preparedMessages := a.preparedMessages(a.convertMessages(messages), a.convertTools(tools))
+	preparedMessages := a.preparedMessages(ctx, a.convertMessages(messages), a.convertTools(tools))
 	cfg := config.Get()
 	if cfg.Debug {
 		jsonData, _ := json.Marshal(preparedMessages)
 		logging.Debug("Prepared messages", "messages", string(jsonData))
 	}
 
+	requestOpts := anthropicHeaderOptions(a.providerOptions.idempotencyHeaderValues())
 	attempts := 0
 	for {
 		attempts++
 		anthropicResponse, err := a.client.Messages.New(
 			ctx,
 			preparedMessages,
+			requestOpts...,
 		)
 		// If there is an error we are going to see if we can retry the call
 		if err != nil {
@@ -303,15 +333,19 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 			return nil, retryErr
 		}
 
-		var sb strings.Builder
+		var sb, thinking strings.Builder
 		for _, block := range anthropicResponse.Content {
-			if text, ok := block.AsAny().(anthropic.TextBlock); ok {
-				sb.WriteString(text.Text)
+			switch block := block.AsAny().(type) {
+			case anthropic.TextBlock:
+				sb.WriteString(block.Text)
+			case anthropic.ThinkingBlock:
+				thinking.WriteString(block.Thinking)
 			}
 		}
 
 		return &ProviderResponse{
 			Content:   sb.String(),
+			Thinking:  thinking.String(),
 			ToolCalls: a.toolCalls(*anthropicResponse),
 			Usage:     a.usage(*anthropicResponse),
 		}, nil
@@ -319,7 +353,7 @@ func (a *anthropicClient) send(ctx context.Context, messages []message.Message,
 }
 
 func (a *anthropicClient) stream(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) <-chan ProviderEvent {
-	preparedMessages := a.preparedMessages(a.convertMessages(messages), a.convertTools(tools))
+	preparedMessages := a.preparedMessages(ctx, a.convertMessages(messages), a.convertTools(tools))
 	cfg := config.Get()
 
 	var sessionID string
@@ -336,6 +370,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 			logging.Debug("Prepared messages", "messages", string(jsonData))
 		}
 	}
+	requestOpts := anthropicHeaderOptions(a.providerOptions.idempotencyHeaderValues())
 	attempts := 0
 	eventChan := make(chan ProviderEvent)
 	go func() {
@@ -344,6 +379,7 @@ func (a *anthropicClient) stream(ctx context.Context, messages []message.Message
 			anthropicStream := a.client.Messages.NewStreaming(
 				ctx,
 				preparedMessages,
+				requestOpts...,
 			)
 			accumulatedMessage := anthropic.Message{}
 
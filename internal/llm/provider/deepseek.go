@@ -103,6 +103,10 @@ func newDeepSeekClient(opts providerClientOptions) DeepSeekClient {
 		deepSeekClientOptions = append(deepSeekClientOptions, option.WithBaseURL(deepSeekOpts.baseURL))
 	}
 
+	if opts.userAgent != "" {
+		deepSeekClientOptions = append(deepSeekClientOptions, option.WithHeader(UserAgentHeader, opts.userAgent))
+	}
+
 	if deepSeekOpts.extraHeaders != nil {
 		for key, value := range deepSeekOpts.extraHeaders {
 			deepSeekClientOptions = append(deepSeekClientOptions, option.WithHeader(key, value))
@@ -193,7 +197,7 @@ func (d *deepSeekClient) convertTools(tools []tools.BaseTool) []openai.ChatCompl
 		deepSeekTools[i] = openai.ChatCompletionFunctionTool(
 			openai.FunctionDefinitionParam{
 				Name:        info.Name,
-				Description: openai.String(info.Description),
+				Description: openai.String(toolDescription(info)),
 				Parameters: openai.FunctionParameters{
 					"type":       "object",
 					"properties": info.Parameters,
@@ -245,6 +249,7 @@ func (d *deepSeekClient) preparedParams(messages []openai.ChatCompletionMessageP
 	if d.options.store {
 		params.Store = openai.Bool(true)
 	}
+	applyOpenAISampling(&params, d.providerOptions)
 
 	return params
 }
@@ -257,10 +262,11 @@ func (d *deepSeekClient) send(ctx context.Context, messages []message.Message, t
 		logging.Debug("DeepSeek prepared messages", "messages", string(jsonData))
 	}
 
+	requestOpts := openaiHeaderOptions(d.providerOptions.idempotencyHeaderValues())
 	attempts := 0
 	for {
 		attempts++
-		deepSeekResponse, err := d.client.Chat.Completions.New(ctx, params)
+		deepSeekResponse, err := d.client.Chat.Completions.New(ctx, params, requestOpts...)
 		// If there is an error we are going to see if we can retry the call
 		if err != nil {
 			retry, after, retryErr := d.shouldRetry(attempts, err)
@@ -312,13 +318,14 @@ func (d *deepSeekClient) stream(ctx context.Context, messages []message.Message,
 		logging.Debug("DeepSeek prepared messages", "messages", string(jsonData))
 	}
 
+	requestOpts := openaiHeaderOptions(d.providerOptions.idempotencyHeaderValues())
 	attempts := 0
 	eventChan := make(chan ProviderEvent)
 
 	go func() {
 		for {
 			attempts++
-			deepSeekStream := d.client.Chat.Completions.NewStreaming(ctx, params)
+			deepSeekStream := d.client.Chat.Completions.NewStreaming(ctx, params, requestOpts...)
 
 			acc := openai.ChatCompletionAccumulator{}
 			currentContent := ""
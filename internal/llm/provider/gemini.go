@@ -65,18 +65,12 @@ func newGeminiClient(opts providerClientOptions) GeminiClient {
 
 func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Content {
 	var history []*genai.Content
-	for i, msg := range messages {
+	for _, msg := range messages {
 		switch msg.Role {
 		case message.User:
 			var parts []*genai.Part
 			parts = append(parts, &genai.Part{Text: msg.Content().String()})
-			for _, binaryContent := range msg.BinaryContent() {
-				imageFormat := strings.Split(binaryContent.MIMEType, "/")
-				parts = append(parts, &genai.Part{InlineData: &genai.Blob{
-					MIMEType: imageFormat[1],
-					Data:     binaryContent.Data,
-				}})
-			}
+			parts = append(parts, geminiBinaryParts(msg.BinaryContent())...)
 			history = append(history, &genai.Content{
 				Parts: parts,
 				Role:  "user",
@@ -86,10 +80,6 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 
 			if msg.Content().String() != "" {
 				assistantParts = append(assistantParts, &genai.Part{Text: msg.Content().String()})
-			} else {
-				logging.Warn("Assistant message with empty content reached provider conversion",
-					"message_index", i,
-				)
 			}
 
 			if len(msg.ToolCalls()) > 0 {
@@ -116,13 +106,6 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 					assistantParts = append(assistantParts, part)
 				}
 			}
-
-			if len(assistantParts) == 0 {
-				logging.Warn("Unexpected: assistant message with no content parts reached provider conversion",
-					"message_index", i,
-				)
-				continue
-			}
 			history = append(history, &genai.Content{
 				Role:  "model",
 				Parts: assistantParts,
@@ -160,12 +143,31 @@ func (g *geminiClient) convertMessages(messages []message.Message) []*genai.Cont
 					Role: "user",
 				})
 			}
+			if binaryContents := msg.BinaryContent(); len(binaryContents) > 0 {
+				history = append(history, &genai.Content{
+					Parts: geminiBinaryParts(binaryContents),
+					Role:  "user",
+				})
+			}
 		}
 	}
 
 	return history
 }
 
+// geminiBinaryParts converts binary message content to inline data parts.
+func geminiBinaryParts(binaryContents []message.BinaryContent) []*genai.Part {
+	parts := make([]*genai.Part, 0, len(binaryContents))
+	for _, binaryContent := range binaryContents {
+		imageFormat := strings.Split(binaryContent.MIMEType, "/")
+		parts = append(parts, &genai.Part{InlineData: &genai.Blob{
+			MIMEType: imageFormat[len(imageFormat)-1],
+			Data:     binaryContent.Data,
+		}})
+	}
+	return parts
+}
+
 func (g *geminiClient) convertTools(tools []tools.BaseTool) []*genai.Tool {
 	geminiTool := &genai.Tool{}
 	geminiTool.FunctionDeclarations = make([]*genai.FunctionDeclaration, 0, len(tools))
@@ -174,7 +176,7 @@ func (g *geminiClient) convertTools(tools []tools.BaseTool) []*genai.Tool {
 		info := tool.Info()
 		declaration := &genai.FunctionDeclaration{
 			Name:        info.Name,
-			Description: info.Description,
+			Description: toolDescription(info),
 			Parameters: &genai.Schema{
 				Type:       genai.TypeObject,
 				Properties: convertSchemaProperties(info.Parameters),
@@ -199,6 +201,28 @@ func (g *geminiClient) finishReason(reason genai.FinishReason) message.FinishRea
 	}
 }
 
+// applySampling sets the sampling parameters configured for the agent on
+// config. Unset parameters are omitted.
+func (g *geminiClient) applySampling(config *genai.GenerateContentConfig) {
+	if t := g.providerOptions.temperature; t != nil {
+		config.Temperature = genai.Ptr(float32(*t))
+	}
+	if p := g.providerOptions.topP; p != nil {
+		config.TopP = genai.Ptr(float32(*p))
+	}
+	if len(g.providerOptions.stopSequences) > 0 {
+		config.StopSequences = g.providerOptions.stopSequences
+	}
+}
+
+// applyReasoningBudget sets the configured maxReasoningTokens as the thinking
+// budget on config.
+func (g *geminiClient) applyReasoningBudget(config *genai.GenerateContentConfig) {
+	if limit := g.providerOptions.maxReasoningTokens; limit > 0 {
+		config.ThinkingConfig = &genai.ThinkingConfig{ThinkingBudget: genai.Ptr(int32(limit))}
+	}
+}
+
 func (g *geminiClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (*ProviderResponse, error) {
 	// Convert messages
 	geminiMessages := g.convertMessages(messages)
@@ -217,9 +241,11 @@ func (g *geminiClient) send(ctx context.Context, messages []message.Message, too
 			Parts: []*genai.Part{{Text: g.providerOptions.systemMessage}},
 		},
 	}
-	if len(g.providerOptions.headers) != 0 {
+	g.applySampling(config)
+	g.applyReasoningBudget(config)
+	if header := g.providerOptions.asHeader(); len(*header) != 0 {
 		config.HTTPOptions = &genai.HTTPOptions{
-			Headers: *g.providerOptions.asHeader(),
+			Headers: *header,
 		}
 	}
 	if len(tools) > 0 {
@@ -315,6 +341,8 @@ func (g *geminiClient) stream(ctx context.Context, messages []message.Message, t
 			Parts: []*genai.Part{{Text: g.providerOptions.systemMessage}},
 		},
 	}
+	g.applySampling(config)
+	g.applyReasoningBudget(config)
 	if len(tools) > 0 {
 		config.Tools = g.convertTools(tools)
 	}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"strings"
 	"time"
@@ -21,6 +22,9 @@ import (
 
 const kiloDefaultBaseURL = "https://api.kilo.ai/api/gateway"
 
+// kiloDefaultTemperature is sent when the agent does not configure one.
+const kiloDefaultTemperature = 0.7
+
 // ==========================================
 // 1. Kilo SDK (Internal Logic)
 // ==========================================
@@ -45,13 +49,19 @@ func newKiloSDK(baseURL, apiKey string, headers map[string]string) *kiloAPIClien
 }
 
 // createChatRequest builds the strictly typed payload Kilo expects.
-func (k *kiloAPIClient) createChatRequest(model string, messages []message.Message, tools []tools.BaseTool, maxTokens int64, opts kiloOptions) ([]byte, error) {
+func (k *kiloAPIClient) createChatRequest(messages []message.Message, tools []tools.BaseTool, providerOpts providerClientOptions, opts kiloOptions) ([]byte, error) {
+	temperature := kiloDefaultTemperature
+	if providerOpts.temperature != nil {
+		temperature = *providerOpts.temperature
+	}
 	req := kiloRequest{
-		Model:       model,
+		Model:       providerOpts.model.APIModel,
 		Messages:    k.convertMessages(messages),
 		Stream:      true, // Always stream to handle Kilo's protocol correctly
-		MaxTokens:   maxTokens,
-		Temperature: 0.7,
+		MaxTokens:   providerOpts.maxTokens,
+		Temperature: &temperature,
+		TopP:        providerOpts.topP,
+		Stop:        providerOpts.stopSequences,
 	}
 
 	// Apply options
@@ -155,7 +165,7 @@ func (k *kiloAPIClient) convertTools(baseTools []tools.BaseTool) []kiloReqTool {
 			Type: "function",
 			Function: kiloReqDefFunction{
 				Name:        info.Name,
-				Description: info.Description,
+				Description: toolDescription(info),
 				Parameters: map[string]interface{}{
 					"type":       "object",
 					"properties": info.Parameters,
@@ -408,10 +418,15 @@ func newKiloClient(opts providerClientOptions) KiloClient {
 
 	logging.Info("Kilo: Initializing provider", "model", opts.model.APIModel, "baseURL", kiloOpts.baseURL)
 
+	headers := kiloOpts.extraHeaders
+	if opts.userAgent != "" {
+		headers = map[string]string{UserAgentHeader: opts.userAgent}
+		maps.Copy(headers, kiloOpts.extraHeaders)
+	}
 	return &kiloClient{
 		providerOptions: opts,
 		options:         kiloOpts,
-		sdk:             newKiloSDK(kiloOpts.baseURL, opts.apiKey, kiloOpts.extraHeaders),
+		sdk:             newKiloSDK(kiloOpts.baseURL, opts.apiKey, headers),
 	}
 }
 
@@ -420,7 +435,7 @@ func (k *kiloClient) send(ctx context.Context, messages []message.Message, tools
 	// Re-use stream logic for unified handling
 	eventChan := k.stream(ctx, messages, tools)
 
-	var fullContent strings.Builder
+	var fullContent, thinking strings.Builder
 
 	// Map ID -> ToolCall
 	activeToolCalls := make(map[string]*message.ToolCall)
@@ -447,6 +462,9 @@ func (k *kiloClient) send(ctx context.Context, messages []message.Message, tools
 		case EventContentDelta:
 			fullContent.WriteString(event.Content)
 
+		case EventThinkingDelta:
+			thinking.WriteString(event.Thinking)
+
 		case EventToolUseStart:
 			if event.ToolCall != nil && event.ToolCall.ID != "" {
 				id := event.ToolCall.ID
@@ -563,6 +581,7 @@ func (k *kiloClient) send(ctx context.Context, messages []message.Message, tools
 
 	return &ProviderResponse{
 		Content:      fullContent.String(),
+		Thinking:     thinking.String(),
 		ToolCalls:    finalToolCalls,
 		Usage:        usage,
 		FinishReason: finishReason,
@@ -570,14 +589,11 @@ func (k *kiloClient) send(ctx context.Context, messages []message.Message, tools
 }
 
 func (k *kiloClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	opts := k.options
+	opts.reasoningEffort = requestReasoningEffort(ctx, k.providerOptions.model, opts.reasoningEffort)
+
 	// Build request payload once
-	payload, err := k.sdk.createChatRequest(
-		k.providerOptions.model.APIModel,
-		messages,
-		tools,
-		k.providerOptions.maxTokens,
-		k.options,
-	)
+	payload, err := k.sdk.createChatRequest(messages, tools, k.providerOptions, opts)
 	if err != nil {
 		ch := make(chan ProviderEvent, 1)
 		ch <- ProviderEvent{Type: EventError, Error: err}
@@ -609,7 +625,7 @@ func (k *kiloClient) countTokens(ctx context.Context, messages []message.Message
 	// Add tool definitions
 	for _, tool := range tools {
 		info := tool.Info()
-		total += int64(len(info.Name)/4) + int64(len(info.Description)/4)
+		total += int64(len(info.Name)/4) + int64(len(toolDescription(info))/4)
 	}
 
 	// Add overhead for message structure (~10 tokens per message)
@@ -635,7 +651,9 @@ type kiloRequest struct {
 	Messages        []kiloReqMessage `json:"messages"`
 	Stream          bool             `json:"stream"`
 	MaxTokens       int64            `json:"max_tokens,omitempty"`
-	Temperature     float64          `json:"temperature,omitempty"`
+	Temperature     *float64         `json:"temperature,omitempty"`
+	TopP            *float64         `json:"top_p,omitempty"`
+	Stop            []string         `json:"stop,omitempty"`
 	Tools           []kiloReqTool    `json:"tools,omitempty"`
 	ReasoningEffort *string          `json:"reasoning_effort,omitempty"`
 	ServiceTier     *string          `json:"service_tier,omitempty"`
@@ -66,6 +66,9 @@ func newOpenAIClient(opts providerClientOptions) OpenAIClient {
 		// Fallback to generic provider options
 		openaiClientOptions = append(openaiClientOptions, option.WithBaseURL(opts.baseURL))
 	}
+	if opts.userAgent != "" {
+		openaiClientOptions = append(openaiClientOptions, option.WithHeader(UserAgentHeader, opts.userAgent))
+	}
 	// Handle generic headers
 	if opts.headers != nil {
 		for key, value := range opts.headers {
@@ -87,6 +90,34 @@ func newOpenAIClient(opts providerClientOptions) OpenAIClient {
 	}
 }
 
+// openaiToolAttachmentsMessage returns a user message with the images attached
+// to the tool results in msg. Tool messages only carry text, so the images
+// follow them in a message of their own.
+func openaiToolAttachmentsMessage(msg message.Message) (openai.ChatCompletionMessageParamUnion, bool) {
+	binaryContents := msg.BinaryContent()
+	if len(binaryContents) == 0 {
+		return openai.ChatCompletionMessageParamUnion{}, false
+	}
+	textBlock := openai.ChatCompletionContentPartTextParam{Text: "Attachments returned by the tool calls above:"}
+	content := []openai.ChatCompletionContentPartUnionParam{{OfText: &textBlock}}
+	for _, binaryContent := range binaryContents {
+		imageURL := openai.ChatCompletionContentPartImageImageURLParam{URL: binaryContent.String(models.ProviderOpenAI)}
+		imageBlock := openai.ChatCompletionContentPartImageParam{ImageURL: imageURL}
+		content = append(content, openai.ChatCompletionContentPartUnionParam{OfImageURL: &imageBlock})
+	}
+	return openai.UserMessage(content), true
+}
+
+// openaiHeaderOptions returns request options setting headers, for the
+// per-request headers of clients built on the OpenAI SDK.
+func openaiHeaderOptions(headers map[string]string) []option.RequestOption {
+	opts := make([]option.RequestOption, 0, len(headers))
+	for key, value := range headers {
+		opts = append(opts, option.WithHeader(key, value))
+	}
+	return opts
+}
+
 func (o *openaiClient) convertMessages(messages []message.Message) []openai.ChatCompletionMessageParamUnion {
 	// Add system message first
 	openaiMessages := []openai.ChatCompletionMessageParamUnion{
@@ -146,6 +177,9 @@ func (o *openaiClient) convertMessages(messages []message.Message) []openai.Chat
 					openai.ToolMessage(result.Content, result.ToolCallID),
 				)
 			}
+			if attachments, ok := openaiToolAttachmentsMessage(msg); ok {
+				openaiMessages = append(openaiMessages, attachments)
+			}
 		}
 	}
 
@@ -160,7 +194,7 @@ func (o *openaiClient) convertTools(tools []tools.BaseTool) []openai.ChatComplet
 		openaiTools[i] = openai.ChatCompletionFunctionTool(
 			openai.FunctionDefinitionParam{
 				Name:        info.Name,
-				Description: openai.String(info.Description),
+				Description: openai.String(toolDescription(info)),
 				Parameters: openai.FunctionParameters{
 					"type":       "object",
 					"properties": info.Parameters,
@@ -186,7 +220,7 @@ func (o *openaiClient) finishReason(reason string) message.FinishReason {
 	}
 }
 
-func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolUnionParam) openai.ChatCompletionNewParams {
+func (o *openaiClient) preparedParams(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolUnionParam) openai.ChatCompletionNewParams {
 	params := openai.ChatCompletionNewParams{
 		Model:    openai.ChatModel(o.providerOptions.model.APIModel),
 		Messages: messages,
@@ -211,7 +245,7 @@ func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessagePar
 
 	if o.providerOptions.model.CanReason {
 		params.MaxCompletionTokens = openai.Int(o.providerOptions.maxTokens)
-		switch o.options.reasoningEffort {
+		switch requestReasoningEffort(ctx, o.providerOptions.model, o.options.reasoningEffort) {
 		case "low":
 			params.ReasoningEffort = shared.ReasoningEffortLow
 		case "medium":
@@ -224,12 +258,27 @@ func (o *openaiClient) preparedParams(messages []openai.ChatCompletionMessagePar
 	} else {
 		params.MaxTokens = openai.Int(o.providerOptions.maxTokens)
 	}
+	applyOpenAISampling(&params, o.providerOptions)
 
 	return params
 }
 
+// applyOpenAISampling sets the sampling parameters configured for the agent
+// on params of an OpenAI-compatible API. Unset parameters are omitted.
+func applyOpenAISampling(params *openai.ChatCompletionNewParams, opts providerClientOptions) {
+	if opts.temperature != nil {
+		params.Temperature = openai.Float(*opts.temperature)
+	}
+	if opts.topP != nil {
+		params.TopP = openai.Float(*opts.topP)
+	}
+	if len(opts.stopSequences) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: opts.stopSequences}
+	}
+}
+
 func (o *openaiClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (response *ProviderResponse, err error) {
-	params := o.preparedParams(o.convertMessages(messages), o.convertTools(tools))
+	params := o.preparedParams(ctx, o.convertMessages(messages), o.convertTools(tools))
 	cfg := config.Get()
 	if cfg.Debug {
 		jsonData, err := json.Marshal(params)
@@ -239,12 +288,14 @@ func (o *openaiClient) send(ctx context.Context, messages []message.Message, too
 			logging.Debug("Prepared messages", "messages", string(jsonData))
 		}
 	}
+	requestOpts := openaiHeaderOptions(o.providerOptions.idempotencyHeaderValues())
 	attempts := 0
 	for {
 		attempts++
 		openaiResponse, err := o.client.Chat.Completions.New(
 			ctx,
 			params,
+			requestOpts...,
 		)
 		// If there is an error we are going to see if we can retry the call
 		if err != nil {
@@ -286,7 +337,7 @@ func (o *openaiClient) send(ctx context.Context, messages []message.Message, too
 }
 
 func (o *openaiClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
-	params := o.preparedParams(o.convertMessages(messages), o.convertTools(tools))
+	params := o.preparedParams(ctx, o.convertMessages(messages), o.convertTools(tools))
 	// Ensure usage is requested for streaming
 	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
 		IncludeUsage: openai.Bool(true),
@@ -306,11 +357,12 @@ func (o *openaiClient) stream(ctx context.Context, messages []message.Message, t
 
 	go func() {
 		defer close(eventChan)
+		requestOpts := openaiHeaderOptions(o.providerOptions.idempotencyHeaderValues())
 		attempts := 0
 
 		for {
 			attempts++
-			openaiStream := o.client.Chat.Completions.NewStreaming(ctx, params)
+			openaiStream := o.client.Chat.Completions.NewStreaming(ctx, params, requestOpts...)
 
 			// Use SDK Accumulator for easy final object creation
 			acc := openai.ChatCompletionAccumulator{}
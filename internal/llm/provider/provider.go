@@ -11,7 +11,7 @@ package provider
 //   - DeepSeek: DeepSeek API via OpenAI-compatible SDK
 //   - OpenRouter: Multi-provider aggregation via OpenRouter.ai
 //   - Groq: Fast inference via GroqCloud
-//   - xAI: Grok models via xAI API
+//   - xAI: Grok models via xAI API (OpenAI-compatible SDK)
 //   - Mistral: Mistral models via Mistral API
 //   - Kilo: Kilo's API gateway
 //   - Local: Self-hosted OpenAI-compatible endpoints
@@ -24,6 +24,7 @@ package provider
 //   - Automatic token counting with fallback estimation
 //   - Dynamic max_tokens adjustment based on context window
 //   - Message sanitization and tool pair validation
+//   - Per-provider message transformers (see MessageTransformer)
 //   - Streaming support with proper event handling
 //
 // Usage:
@@ -47,13 +48,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
 	toolsPkg "github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/version"
+	"github.com/google/uuid"
 )
 
 // EventType represents the type of event during streaming.
@@ -102,7 +108,10 @@ type TokenUsage struct {
 
 // ProviderResponse represents a complete response from a provider.
 type ProviderResponse struct {
-	Content      string
+	Content string
+	// Thinking is the reasoning or thinking content of the response, for
+	// providers that return it.
+	Thinking     string
 	ToolCalls    []message.ToolCall
 	Usage        TokenUsage
 	FinishReason message.FinishReason
@@ -145,27 +154,137 @@ type providerClientOptions struct {
 	maxTokens     int64
 	systemMessage string
 	baseURL       string
+	userAgent     string
 	headers       map[string]string
+	modelMap      map[string]string
+
+	disableStreaming bool
+
+	// idempotencyHeaders maps header names to value templates containing
+	// IdempotencyKeyPlaceholder. They are filled per logical request by
+	// idempotencyHeaderValues and kept out of the static headers.
+	idempotencyHeaders map[string]string
+
+	// Sampling parameters; nil and empty values leave the provider defaults.
+	temperature   *float64
+	topP          *float64
+	stopSequences []string
+
+	// maxReasoningTokens caps the thinking budget of providers that take
+	// one separately from maxTokens. Zero leaves the provider default.
+	maxReasoningTokens int64
+
+	messageTransformers []MessageTransformer
 
 	anthropicOptions []AnthropicOption
 	openaiOptions    []OpenAIOption
 	geminiOptions    []GeminiOption
 	bedrockOptions   []BedrockOption
 	deepSeekOptions  []DeepSeekOption
+	xaiOptions       []XAIOption
 	kiloOptions      []KiloOption
 }
 
 func (opts *providerClientOptions) asHeader() *http.Header {
 	header := http.Header{}
-	if opts.headers == nil {
-		return &header
+	if opts.userAgent != "" {
+		header.Set(UserAgentHeader, opts.userAgent)
 	}
 	for k, v := range opts.headers {
-		header.Add(k, v)
+		header.Set(k, v)
 	}
 	return &header
 }
 
+// UserAgentHeader is the header set by WithUserAgent.
+const UserAgentHeader = "User-Agent"
+
+// DefaultUserAgent returns the User-Agent sent to providers without a
+// configured one, opencode/<version>.
+func DefaultUserAgent() string {
+	return "opencode/" + version.Version
+}
+
+// IdempotencyKeyPlaceholder is replaced, in a configured header value, with a
+// key generated for each logical request and reused for its retries.
+const IdempotencyKeyPlaceholder = "{{idempotencyKey}}"
+
+// IdempotencyKeyHeader is the header set by WithIdempotencyKey.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// splitIdempotencyHeaders moves headers whose value contains
+// IdempotencyKeyPlaceholder from the static headers to idempotencyHeaders.
+func (opts *providerClientOptions) splitIdempotencyHeaders() {
+	var static map[string]string
+	for k, v := range opts.headers {
+		if !strings.Contains(v, IdempotencyKeyPlaceholder) {
+			continue
+		}
+		if static == nil {
+			static = maps.Clone(opts.headers)
+		}
+		delete(static, k)
+		if opts.idempotencyHeaders == nil {
+			opts.idempotencyHeaders = map[string]string{}
+		}
+		opts.idempotencyHeaders[k] = v
+	}
+	if static != nil {
+		opts.headers = static
+	}
+}
+
+// idempotencyHeaderValues returns the idempotency headers filled with a new
+// key, or nil when none are configured. Clients call it once per logical
+// request, before their retry loop, so every retry sends the same key.
+func (opts *providerClientOptions) idempotencyHeaderValues() map[string]string {
+	if len(opts.idempotencyHeaders) == 0 {
+		return nil
+	}
+	key := uuid.NewString()
+	values := make(map[string]string, len(opts.idempotencyHeaders))
+	for k, v := range opts.idempotencyHeaders {
+		values[k] = strings.ReplaceAll(v, IdempotencyKeyPlaceholder, key)
+	}
+	return values
+}
+
+// supportsIdempotencyKey reports whether the client for providerName sends
+// idempotency headers with model: the OpenAI-compatible, Anthropic (also
+// through Bedrock and Vertex AI), DeepSeek and xAI clients. The Gemini and
+// Kilo clients do not.
+func supportsIdempotencyKey(providerName models.ModelProvider, model models.Model) bool {
+	switch providerName {
+	case models.ProviderGemini, models.ProviderKilo:
+		return false
+	case models.ProviderVertexAI:
+		_, anthropicModel := models.VertexAIAnthropicModels[model.ID]
+		return anthropicModel
+	}
+	return true
+}
+
+// supportsReasoningBudget reports whether the client for providerName sends a
+// thinking budget separate from max tokens for model: Anthropic models with
+// manual extended thinking and Gemini models.
+func supportsReasoningBudget(providerName models.ModelProvider, model models.Model) bool {
+	if !model.CanReason {
+		return false
+	}
+	switch providerName {
+	case models.ProviderGemini:
+		return true
+	case models.ProviderVertexAI:
+		if _, anthropicModel := models.VertexAIAnthropicModels[model.ID]; !anthropicModel {
+			return true
+		}
+		return !model.SupportsAdaptiveThinking
+	case models.ProviderAnthropic, models.ProviderBedrock:
+		return !model.SupportsAdaptiveThinking
+	}
+	return false
+}
+
 // ProviderClientOption is a function that configures provider client options.
 type ProviderClientOption func(*providerClientOptions)
 
@@ -190,6 +309,26 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 	for _, o := range opts {
 		o(&clientOptions)
 	}
+	clientOptions.splitIdempotencyHeaders()
+	if clientOptions.userAgent == "" {
+		clientOptions.userAgent = DefaultUserAgent()
+	}
+	if len(clientOptions.idempotencyHeaders) > 0 && !supportsIdempotencyKey(providerName, clientOptions.model) {
+		logging.Warn("Idempotency keys are not supported for this provider and are not sent",
+			"provider", providerName, "model", clientOptions.model.ID)
+	}
+	if clientOptions.maxReasoningTokens > 0 && !supportsReasoningBudget(providerName, clientOptions.model) {
+		logging.Warn("maxReasoningTokens is not supported for this model and is ignored",
+			"provider", providerName, "model", clientOptions.model.ID)
+		clientOptions.maxReasoningTokens = 0
+	}
+	if apiModel, ok := clientOptions.modelMap[clientOptions.model.APIModel]; ok && apiModel != "" {
+		clientOptions.model.APIModel = apiModel
+	}
+	clientOptions.messageTransformers = append(
+		slices.Clone(defaultMessageTransformers[providerName]),
+		clientOptions.messageTransformers...,
+	)
 	switch providerName {
 	case models.ProviderVertexAI:
 		return &baseProvider[VertexAIClient]{
@@ -244,12 +383,9 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 			name:    providerName,
 		}, nil
 	case models.ProviderXAI:
-		clientOptions.openaiOptions = append(clientOptions.openaiOptions,
-			WithOpenAIBaseURL("https://api.x.ai/v1"),
-		)
-		return &baseProvider[OpenAIClient]{
+		return &baseProvider[XAIClient]{
 			options: clientOptions,
-			client:  newOpenAIClient(clientOptions),
+			client:  newXAIClient(clientOptions),
 			name:    providerName,
 		}, nil
 	case models.ProviderMistral:
@@ -290,23 +426,24 @@ func NewProvider(providerName models.ModelProvider, opts ...ProviderClientOption
 	return nil, fmt.Errorf("provider not supported: %s", providerName)
 }
 
-func (p *baseProvider[C]) cleanMessages(messages []message.Message) (cleaned []message.Message) {
+func (p *baseProvider[C]) cleanMessages(messages []message.Message) []message.Message {
+	cleaned := make([]message.Message, 0, len(messages))
 	for _, msg := range messages {
 		// The message has no content parts at all
 		if len(msg.Parts) == 0 {
 			continue
 		}
-		// Skip assistant messages that have no text content and no tool calls
-		// (e.g., canceled messages that only contain a Finish part)
-		if msg.Role == message.Assistant && msg.Content().String() == "" && len(msg.ToolCalls()) == 0 {
-			logging.Warn("Skipping assistant message with no content or tool calls (likely canceled)",
-				"message_id", msg.ID,
-			)
-			continue
-		}
 		cleaned = append(cleaned, msg)
 	}
-	return
+	return cleaned
+}
+
+// prepareMessages cleans the history, applies the provider's message
+// transformers and then repairs tool_use/tool_result pairs.
+func (p *baseProvider[C]) prepareMessages(messages []message.Message) []message.Message {
+	messages = p.cleanMessages(messages)
+	messages = TransformMessages(messages, p.options.messageTransformers...)
+	return p.sanitizeToolPairs(messages)
 }
 
 // sanitizeToolPairs ensures that tool_use/tool_result message pairs are consistent.
@@ -457,8 +594,7 @@ func (p *baseProvider[C]) sanitizeToolPairs(messages []message.Message) []messag
 }
 
 func (p *baseProvider[C]) SendMessages(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) (*ProviderResponse, error) {
-	messages = p.cleanMessages(messages)
-	messages = p.sanitizeToolPairs(messages)
+	messages = p.prepareMessages(messages)
 	return p.client.send(ctx, messages, tools)
 }
 
@@ -467,11 +603,38 @@ func (p *baseProvider[C]) Model() models.Model {
 }
 
 func (p *baseProvider[C]) StreamResponse(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) <-chan ProviderEvent {
-	messages = p.cleanMessages(messages)
-	messages = p.sanitizeToolPairs(messages)
+	messages = p.prepareMessages(messages)
+	if p.options.disableStreaming {
+		return p.sendAsStream(ctx, messages, tools)
+	}
 	return p.client.stream(ctx, messages, tools)
 }
 
+// sendAsStream makes a buffered send request and delivers its response as
+// stream events: the whole thinking and content as a single delta each
+// followed by EventComplete, or EventError if the request failed.
+func (p *baseProvider[C]) sendAsStream(ctx context.Context, messages []message.Message, tools []toolsPkg.BaseTool) <-chan ProviderEvent {
+	// Buffered for every event so the goroutine never blocks on a consumer
+	// that stopped reading.
+	eventChan := make(chan ProviderEvent, 3)
+	go func() {
+		defer close(eventChan)
+		response, err := p.client.send(ctx, messages, tools)
+		if err != nil {
+			eventChan <- ProviderEvent{Type: EventError, Error: err}
+			return
+		}
+		if response.Thinking != "" {
+			eventChan <- ProviderEvent{Type: EventThinkingDelta, Thinking: response.Thinking}
+		}
+		if response.Content != "" {
+			eventChan <- ProviderEvent{Type: EventContentDelta, Content: response.Content}
+		}
+		eventChan <- ProviderEvent{Type: EventComplete, Response: response}
+	}()
+	return eventChan
+}
+
 func (p *baseProvider[C]) CountTokens(ctx context.Context, threshold float64, messages []message.Message, tools []toolsPkg.BaseTool) (int64, bool) {
 	estimatedTokens, err := p.client.countTokens(ctx, messages, tools)
 	// Fallback to local estimation
@@ -541,6 +704,21 @@ func (p *baseProvider[C]) AdjustMaxTokens(estimatedTokens int64) int64 {
 	return newMaxTokens
 }
 
+// toolDescription returns the description sent for a tool. None of the
+// clients pass examples natively, so they are appended to the description.
+func toolDescription(info toolsPkg.ToolInfo) string {
+	if len(info.Examples) == 0 {
+		return info.Description
+	}
+	var b strings.Builder
+	b.WriteString(info.Description)
+	b.WriteString("\n\nEXAMPLES:")
+	for _, example := range info.Examples {
+		b.WriteString("\n- " + example.Description + ":\n  " + example.Input)
+	}
+	return b.String()
+}
+
 // WithBaseURL sets the base URL for the provider.
 func WithBaseURL(baseURL string) ProviderClientOption {
 	return func(options *providerClientOptions) {
@@ -555,6 +733,54 @@ func WithHeaders(headers map[string]string) ProviderClientOption {
 	}
 }
 
+// WithUserAgent sets the User-Agent of requests to the provider, replacing
+// DefaultUserAgent. A User-Agent in the custom headers takes precedence.
+func WithUserAgent(userAgent string) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.userAgent = userAgent
+	}
+}
+
+// WithIdempotencyKey sends an Idempotency-Key header with a key generated
+// for each logical request and reused across its retries, so gateways can
+// deduplicate requests that were retried after a timeout or rate limit.
+func WithIdempotencyKey(enabled bool) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		if !enabled {
+			return
+		}
+		if options.idempotencyHeaders == nil {
+			options.idempotencyHeaders = map[string]string{}
+		}
+		options.idempotencyHeaders[IdempotencyKeyHeader] = IdempotencyKeyPlaceholder
+	}
+}
+
+// WithMaxReasoningTokens caps the tokens a reasoning model may spend thinking,
+// separately from max tokens. Providers without a separate thinking budget
+// ignore it with a warning.
+func WithMaxReasoningTokens(maxReasoningTokens int64) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.maxReasoningTokens = maxReasoningTokens
+	}
+}
+
+// WithModelMap renames the model's APIModel when it is a key of modelMap, for
+// gateways that serve models under their own names. The model ID is kept.
+func WithModelMap(modelMap map[string]string) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.modelMap = modelMap
+	}
+}
+
+// WithDisableStreaming makes StreamResponse use a single buffered request
+// instead of streaming, for gateways whose streaming is unreliable.
+func WithDisableStreaming(disable bool) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.disableStreaming = disable
+	}
+}
+
 // WithAPIKey sets the API key for the provider.
 func WithAPIKey(apiKey string) ProviderClientOption {
 	return func(options *providerClientOptions) {
@@ -576,6 +802,28 @@ func WithMaxTokens(maxTokens int64) ProviderClientOption {
 	}
 }
 
+// WithTemperature sets the sampling temperature sent with each request.
+func WithTemperature(temperature float64) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.temperature = &temperature
+	}
+}
+
+// WithTopP sets the nucleus sampling probability sent with each request.
+func WithTopP(topP float64) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.topP = &topP
+	}
+}
+
+// WithStopSequences sets sequences that end generation when the model
+// produces them.
+func WithStopSequences(stopSequences ...string) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.stopSequences = stopSequences
+	}
+}
+
 // WithSystemMessage sets the system message for the provider.
 func WithSystemMessage(systemMessage string) ProviderClientOption {
 	return func(options *providerClientOptions) {
@@ -583,6 +831,14 @@ func WithSystemMessage(systemMessage string) ProviderClientOption {
 	}
 }
 
+// WithMessageTransformers adds transformers that run after the provider's
+// default ones, before messages are converted for the provider API.
+func WithMessageTransformers(transformers ...MessageTransformer) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.messageTransformers = append(options.messageTransformers, transformers...)
+	}
+}
+
 // WithOpenAIOptions sets OpenAI-specific options.
 func WithOpenAIOptions(openaiOptions ...OpenAIOption) ProviderClientOption {
 	return func(options *providerClientOptions) {
@@ -618,6 +874,13 @@ func WithDeepSeekOptions(deepSeekOptions ...DeepSeekOption) ProviderClientOption
 	}
 }
 
+// WithXAIOptions sets xAI-specific options.
+func WithXAIOptions(xaiOptions ...XAIOption) ProviderClientOption {
+	return func(options *providerClientOptions) {
+		options.xaiOptions = xaiOptions
+	}
+}
+
 // WithKiloOptions sets Kilo-specific options.
 func WithKiloOptions(kiloOptions ...KiloOption) ProviderClientOption {
 	return func(options *providerClientOptions) {
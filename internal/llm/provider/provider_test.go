@@ -1,9 +1,22 @@
 package provider
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	toolsPkg "github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/anthropics/anthropic-sdk-go"
+	"google.golang.org/genai"
 )
 
 func newTestProvider() *baseProvider[AnthropicClient] {
@@ -334,12 +347,12 @@ func TestCleanMessages(t *testing.T) {
 			wantMsgCount: 2,
 		},
 		{
-			name: "removes assistant with only finish part",
+			name: "leaves assistant with only finish part to the transformers",
 			messages: []message.Message{
 				{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hello"}}},
 				{Role: message.Assistant, Parts: []message.ContentPart{message.Finish{Reason: message.FinishReasonCanceled}}},
 			},
-			wantMsgCount: 1,
+			wantMsgCount: 2,
 		},
 	}
 
@@ -353,3 +366,623 @@ func TestCleanMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestToolDescription(t *testing.T) {
+	info := toolsPkg.ToolInfo{Name: "ls", Description: "Lists files."}
+	if got := toolDescription(info); got != "Lists files." {
+		t.Errorf("toolDescription without examples = %q, want the plain description", got)
+	}
+
+	info.Examples = []toolsPkg.ToolExample{
+		{Description: "List the project root", Input: `{"path": "."}`},
+	}
+	want := "Lists files.\n\nEXAMPLES:\n- List the project root:\n  {\"path\": \".\"}"
+	if got := toolDescription(info); got != want {
+		t.Errorf("toolDescription = %q, want %q", got, want)
+	}
+}
+
+func TestNewProviderModelMap(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		modelMap  map[string]string
+		wantModel string
+	}{
+		{name: "mapped", modelMap: map[string]string{"gpt-4o": "my-gpt"}, wantModel: "my-gpt"},
+		{name: "other model mapped", modelMap: map[string]string{"gpt-4.1": "my-gpt"}, wantModel: "gpt-4o"},
+		{name: "no map", wantModel: "gpt-4o"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requestBody map[string]any
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				_ = json.Unmarshal(body, &requestBody)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{
+					"id": "resp_1",
+					"object": "chat.completion",
+					"created": 1,
+					"model": "gpt-4o",
+					"choices": [{
+						"index": 0,
+						"finish_reason": "stop",
+						"message": {"role": "assistant", "content": "Hello"}
+					}]
+				}`)
+			}))
+			defer server.Close()
+
+			p, err := NewProvider(models.ProviderOpenAI,
+				WithAPIKey("test-key"),
+				WithBaseURL(server.URL),
+				WithModel(models.SupportedModels[models.GPT4o]),
+				WithModelMap(tt.modelMap),
+			)
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			messages := []message.Message{
+				{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}},
+			}
+			if _, err := p.SendMessages(context.Background(), messages, nil); err != nil {
+				t.Fatalf("SendMessages: %v", err)
+			}
+
+			if got := requestBody["model"]; got != tt.wantModel {
+				t.Errorf("request model = %v, want %q", got, tt.wantModel)
+			}
+			if got := p.Model().ID; got != models.GPT4o {
+				t.Errorf("Model().ID = %q, want %q", got, models.GPT4o)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		provider models.ModelProvider
+		model    models.ModelID
+		opts     func(baseURL string) []ProviderClientOption
+		want     string
+	}{
+		{
+			name:     "openai default",
+			provider: models.ProviderOpenAI,
+			model:    models.GPT4o,
+			opts:     func(baseURL string) []ProviderClientOption { return []ProviderClientOption{WithBaseURL(baseURL)} },
+			want:     DefaultUserAgent(),
+		},
+		{
+			name:     "openai configured",
+			provider: models.ProviderOpenAI,
+			model:    models.GPT4o,
+			opts: func(baseURL string) []ProviderClientOption {
+				return []ProviderClientOption{WithBaseURL(baseURL), WithUserAgent("gateway-client/2")}
+			},
+			want: "gateway-client/2",
+		},
+		{
+			name:     "custom header takes precedence",
+			provider: models.ProviderOpenAI,
+			model:    models.GPT4o,
+			opts: func(baseURL string) []ProviderClientOption {
+				return []ProviderClientOption{
+					WithBaseURL(baseURL),
+					WithUserAgent("gateway-client/2"),
+					WithHeaders(map[string]string{"User-Agent": "from-headers"}),
+				}
+			},
+			want: "from-headers",
+		},
+		{
+			name:     "deepseek default",
+			provider: models.ProviderDeepSeek,
+			model:    models.DeepSeekChat,
+			opts: func(baseURL string) []ProviderClientOption {
+				return []ProviderClientOption{WithDeepSeekOptions(WithDeepSeekBaseURL(baseURL))}
+			},
+			want: DefaultUserAgent(),
+		},
+		{
+			name:     "deepseek configured",
+			provider: models.ProviderDeepSeek,
+			model:    models.DeepSeekChat,
+			opts: func(baseURL string) []ProviderClientOption {
+				return []ProviderClientOption{WithDeepSeekOptions(WithDeepSeekBaseURL(baseURL)), WithUserAgent("gateway-client/2")}
+			},
+			want: "gateway-client/2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userAgents []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userAgents = r.Header.Values("User-Agent")
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{
+					"id": "resp_1",
+					"object": "chat.completion",
+					"created": 1,
+					"model": "m",
+					"choices": [{
+						"index": 0,
+						"finish_reason": "stop",
+						"message": {"role": "assistant", "content": "Hello"}
+					}]
+				}`)
+			}))
+			defer server.Close()
+
+			opts := append([]ProviderClientOption{
+				WithAPIKey("test-key"),
+				WithModel(models.SupportedModels[tt.model]),
+			}, tt.opts(server.URL)...)
+			p, err := NewProvider(tt.provider, opts...)
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			messages := []message.Message{
+				{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}},
+			}
+			if _, err := p.SendMessages(context.Background(), messages, nil); err != nil {
+				t.Fatalf("SendMessages: %v", err)
+			}
+
+			if len(userAgents) != 1 || userAgents[0] != tt.want {
+				t.Errorf("User-Agent = %q, want %q", userAgents, tt.want)
+			}
+		})
+	}
+
+	if got := DefaultUserAgent(); !strings.HasPrefix(got, "opencode/") {
+		t.Errorf("DefaultUserAgent() = %q, want an opencode/<version> value", got)
+	}
+}
+
+func TestIdempotencyKeyReusedAcrossRetries(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+
+	var keys, templated, static []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get(IdempotencyKeyHeader))
+		templated = append(templated, r.Header.Get("X-Request-Id"))
+		static = append(static, r.Header.Get("X-Team"))
+		if len(keys) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error": {"message": "rate limited"}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "resp_1",
+			"object": "chat.completion",
+			"created": 1,
+			"model": "gpt-4o",
+			"choices": [{
+				"index": 0,
+				"finish_reason": "stop",
+				"message": {"role": "assistant", "content": "Hello"}
+			}]
+		}`)
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(models.ProviderOpenAI,
+		WithAPIKey("test-key"),
+		WithBaseURL(server.URL),
+		WithModel(models.SupportedModels[models.GPT4o]),
+		WithHeaders(map[string]string{"X-Request-Id": "opencode-" + IdempotencyKeyPlaceholder, "X-Team": "core"}),
+		WithIdempotencyKey(true),
+	)
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}},
+	}
+	for range 2 {
+		if _, err := p.SendMessages(context.Background(), messages, nil); err != nil {
+			t.Fatalf("SendMessages: %v", err)
+		}
+	}
+
+	if len(keys) != 3 {
+		t.Fatalf("got %d requests, want 3 (rate limited, retry, next request)", len(keys))
+	}
+	if keys[0] == "" {
+		t.Fatal("Idempotency-Key header was not sent")
+	}
+	if keys[1] != keys[0] {
+		t.Errorf("retry sent key %q, want the original %q", keys[1], keys[0])
+	}
+	if keys[2] == keys[0] {
+		t.Errorf("next request reused key %q", keys[2])
+	}
+	for i, key := range keys {
+		if want := "opencode-" + key; templated[i] != want {
+			t.Errorf("request %d: X-Request-Id = %q, want %q", i, templated[i], want)
+		}
+		if static[i] != "core" {
+			t.Errorf("request %d: X-Team = %q, want %q", i, static[i], "core")
+		}
+	}
+}
+
+func TestSupportsIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		provider models.ModelProvider
+		model    models.ModelID
+		want     bool
+	}{
+		{models.ProviderOpenAI, models.GPT4o, true},
+		{models.ProviderAnthropic, models.Claude37Sonnet, true},
+		{models.ProviderVertexAI, models.VertexAISonnet46, true},
+		{models.ProviderVertexAI, models.VertexAIGemini30Pro, false},
+		{models.ProviderGemini, models.Gemini25Flash, false},
+		{models.ProviderKilo, models.KiloAutoFree, false},
+	}
+	for _, tt := range tests {
+		if got := supportsIdempotencyKey(tt.provider, models.SupportedModels[tt.model]); got != tt.want {
+			t.Errorf("supportsIdempotencyKey(%s, %s) = %v, want %v", tt.provider, tt.model, got, tt.want)
+		}
+	}
+}
+
+// recordingClient records whether the buffered or the streaming path was used.
+type recordingClient struct {
+	sent, streamed bool
+	response       *ProviderResponse
+}
+
+func (c *recordingClient) send(context.Context, []message.Message, []toolsPkg.BaseTool) (*ProviderResponse, error) {
+	c.sent = true
+	return c.response, nil
+}
+
+func (c *recordingClient) stream(context.Context, []message.Message, []toolsPkg.BaseTool) <-chan ProviderEvent {
+	c.streamed = true
+	events := make(chan ProviderEvent, 1)
+	events <- ProviderEvent{Type: EventComplete, Response: c.response}
+	close(events)
+	return events
+}
+
+func (c *recordingClient) countTokens(context.Context, []message.Message, []toolsPkg.BaseTool) (int64, error) {
+	return 0, nil
+}
+
+func (c *recordingClient) maxTokens() int64 { return 0 }
+
+func (c *recordingClient) setMaxTokens(int64) {}
+
+func TestStreamResponseDisableStreaming(t *testing.T) {
+	toolCall := message.ToolCall{ID: "tc-1", Name: "bash", Input: `{"command":"ls"}`, Finished: true}
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}},
+	}
+
+	for _, disable := range []bool{false, true} {
+		t.Run(fmt.Sprintf("disabled=%v", disable), func(t *testing.T) {
+			client := &recordingClient{response: &ProviderResponse{
+				Content:      "Hello",
+				ToolCalls:    []message.ToolCall{toolCall},
+				FinishReason: message.FinishReasonToolUse,
+			}}
+			options := providerClientOptions{}
+			WithDisableStreaming(disable)(&options)
+			p := &baseProvider[*recordingClient]{options: options, client: client}
+
+			var events []ProviderEvent
+			for event := range p.StreamResponse(t.Context(), messages, nil) {
+				events = append(events, event)
+			}
+
+			if client.sent != disable || client.streamed == disable {
+				t.Errorf("sent = %v, streamed = %v, want sent = %v", client.sent, client.streamed, disable)
+			}
+			last := events[len(events)-1]
+			if last.Type != EventComplete || last.Response != client.response {
+				t.Fatalf("last event = %+v, want EventComplete with the response", last)
+			}
+			completes := 0
+			for _, event := range events {
+				if event.Type == EventComplete {
+					completes++
+				}
+			}
+			if completes != 1 {
+				t.Errorf("got %d complete events, want 1", completes)
+			}
+			if disable && (events[0].Type != EventContentDelta || events[0].Content != "Hello") {
+				t.Errorf("first event = %+v, want the whole content as one delta", events[0])
+			}
+		})
+	}
+}
+
+func TestStreamResponseDisableStreamingThinking(t *testing.T) {
+	client := &recordingClient{response: &ProviderResponse{
+		Content:      "Hello",
+		Thinking:     "The user greets me.",
+		FinishReason: message.FinishReasonEndTurn,
+	}}
+	p := &baseProvider[*recordingClient]{options: providerClientOptions{disableStreaming: true}, client: client}
+
+	var events []ProviderEvent
+	for event := range p.StreamResponse(t.Context(), nil, nil) {
+		events = append(events, event)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want thinking, content and complete: %+v", len(events), events)
+	}
+	if events[0].Type != EventThinkingDelta || events[0].Thinking != "The user greets me." {
+		t.Errorf("first event = %+v, want the whole thinking as one delta", events[0])
+	}
+	if events[1].Type != EventContentDelta || events[1].Content != "Hello" {
+		t.Errorf("second event = %+v, want the whole content as one delta", events[1])
+	}
+	if events[2].Type != EventComplete {
+		t.Errorf("last event = %+v, want EventComplete", events[2])
+	}
+}
+
+func TestStreamResponseDisableStreamingError(t *testing.T) {
+	p := &baseProvider[*failingClient]{
+		options: providerClientOptions{disableStreaming: true},
+		client:  &failingClient{},
+	}
+	var events []ProviderEvent
+	for event := range p.StreamResponse(t.Context(), nil, nil) {
+		events = append(events, event)
+	}
+	if len(events) != 1 || events[0].Type != EventError || events[0].Error == nil {
+		t.Errorf("events = %+v, want a single EventError", events)
+	}
+}
+
+type failingClient struct{ recordingClient }
+
+func (c *failingClient) send(context.Context, []message.Message, []toolsPkg.BaseTool) (*ProviderResponse, error) {
+	return nil, errors.New("gateway error")
+}
+
+func TestNewProviderStreamsByDefault(t *testing.T) {
+	if _, err := config.Load(t.TempDir(), false); err != nil {
+		t.Fatalf("config.Load: %v", err)
+	}
+	p, err := NewProvider(models.ProviderOpenAI, WithAPIKey("test-key"), WithModel(models.SupportedModels[models.GPT4o]))
+	if err != nil {
+		t.Fatalf("NewProvider: %v", err)
+	}
+	if p.(*baseProvider[OpenAIClient]).options.disableStreaming {
+		t.Error("streaming is disabled by default")
+	}
+}
+
+func TestSamplingParams(t *testing.T) {
+	temperature, topP := 0.0, 0.9
+	configured := providerClientOptions{
+		model:         models.Model{APIModel: "test-model"},
+		maxTokens:     100,
+		temperature:   &temperature,
+		stopSequences: []string{"END", "\n\n"},
+	}
+	unset := providerClientOptions{model: models.Model{APIModel: "test-model"}, maxTokens: 100}
+
+	toJSON := func(t *testing.T, params any) map[string]any {
+		t.Helper()
+		raw, err := json.Marshal(params)
+		if err != nil {
+			t.Fatalf("marshal params: %v", err)
+		}
+		var decoded map[string]any
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("unmarshal params: %v", err)
+		}
+		return decoded
+	}
+	assertParams := func(t *testing.T, got map[string]any, want map[string]any, absent ...string) {
+		t.Helper()
+		for key, value := range want {
+			if fmt.Sprint(got[key]) != fmt.Sprint(value) {
+				t.Errorf("%s = %v, want %v", key, got[key], value)
+			}
+		}
+		for _, key := range absent {
+			if _, ok := got[key]; ok {
+				t.Errorf("%s = %v, want it omitted", key, got[key])
+			}
+		}
+	}
+
+	t.Run("openai", func(t *testing.T) {
+		client := newOpenAIClient(configured).(*openaiClient)
+		assertParams(t, toJSON(t, client.preparedParams(context.Background(), nil, nil)),
+			map[string]any{"temperature": 0, "stop": []string{"END", "\n\n"}}, "top_p")
+
+		client = newOpenAIClient(unset).(*openaiClient)
+		assertParams(t, toJSON(t, client.preparedParams(context.Background(), nil, nil)),
+			nil, "temperature", "top_p", "stop")
+	})
+
+	t.Run("anthropic", func(t *testing.T) {
+		messages := []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("hi"))}
+		withTopP := configured
+		withTopP.temperature = nil
+		withTopP.topP = &topP
+		client := newAnthropicClient(withTopP).(*anthropicClient)
+		assertParams(t, toJSON(t, client.preparedMessages(context.Background(), messages, nil)),
+			map[string]any{"top_p": 0.9, "stop_sequences": []string{"END", "\n\n"}}, "temperature")
+
+		withBoth := configured
+		withBoth.topP = &topP
+		client = newAnthropicClient(withBoth).(*anthropicClient)
+		assertParams(t, toJSON(t, client.preparedMessages(context.Background(), messages, nil)),
+			map[string]any{"temperature": 0}, "top_p")
+
+		client = newAnthropicClient(unset).(*anthropicClient)
+		assertParams(t, toJSON(t, client.preparedMessages(context.Background(), messages, nil)),
+			nil, "temperature", "top_p", "stop_sequences")
+	})
+
+	t.Run("kilo", func(t *testing.T) {
+		payload, err := (&kiloAPIClient{}).createChatRequest(nil, nil, configured, kiloOptions{})
+		if err != nil {
+			t.Fatalf("createChatRequest: %v", err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(payload, &got); err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		assertParams(t, got, map[string]any{"temperature": 0, "stop": []string{"END", "\n\n"}}, "top_p")
+
+		payload, err = (&kiloAPIClient{}).createChatRequest(nil, nil, unset, kiloOptions{})
+		if err != nil {
+			t.Fatalf("createChatRequest: %v", err)
+		}
+		got = nil
+		if err := json.Unmarshal(payload, &got); err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		assertParams(t, got, map[string]any{"temperature": kiloDefaultTemperature}, "top_p", "stop")
+	})
+}
+
+func TestMaxReasoningTokens(t *testing.T) {
+	model := models.SupportedModels[models.Claude37Sonnet]
+	messages := []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("think about it"))}
+	budget := func(t *testing.T, opts providerClientOptions) any {
+		t.Helper()
+		client := newAnthropicClient(opts).(*anthropicClient)
+		client.options.shouldThink = DefaultShouldThinkFn
+		raw, err := json.Marshal(client.preparedMessages(context.Background(), messages, nil))
+		if err != nil {
+			t.Fatalf("marshal params: %v", err)
+		}
+		var params struct {
+			Thinking map[string]any `json:"thinking"`
+		}
+		if err := json.Unmarshal(raw, &params); err != nil {
+			t.Fatalf("unmarshal params: %v", err)
+		}
+		return params.Thinking["budget_tokens"]
+	}
+
+	t.Run("capable model sends the budget", func(t *testing.T) {
+		got := budget(t, providerClientOptions{model: model, maxTokens: 10000, maxReasoningTokens: 2048})
+		if got != float64(2048) {
+			t.Errorf("budget_tokens = %v, want 2048", got)
+		}
+	})
+
+	t.Run("default budget", func(t *testing.T) {
+		got := budget(t, providerClientOptions{model: model, maxTokens: 10000})
+		if got != float64(8000) {
+			t.Errorf("budget_tokens = %v, want 8000", got)
+		}
+	})
+
+	t.Run("cap above the default budget", func(t *testing.T) {
+		got := budget(t, providerClientOptions{model: model, maxTokens: 10000, maxReasoningTokens: 9500})
+		if got != float64(8000) {
+			t.Errorf("budget_tokens = %v, want 8000", got)
+		}
+	})
+
+	t.Run("gemini", func(t *testing.T) {
+		var config genai.GenerateContentConfig
+		client := &geminiClient{providerOptions: providerClientOptions{maxReasoningTokens: 1024}}
+		client.applyReasoningBudget(&config)
+		if config.ThinkingConfig == nil || config.ThinkingConfig.ThinkingBudget == nil || *config.ThinkingConfig.ThinkingBudget != 1024 {
+			t.Errorf("ThinkingConfig = %+v, want a budget of 1024", config.ThinkingConfig)
+		}
+	})
+
+	t.Run("support", func(t *testing.T) {
+		tests := []struct {
+			provider models.ModelProvider
+			model    models.ModelID
+			want     bool
+		}{
+			{models.ProviderAnthropic, models.Claude37Sonnet, true},
+			{models.ProviderAnthropic, models.Claude46Opus, false},
+			{models.ProviderAnthropic, models.Claude35Haiku, false},
+			{models.ProviderOpenAI, models.GPT4o, false},
+		}
+		for _, tt := range tests {
+			if got := supportsReasoningBudget(tt.provider, models.SupportedModels[tt.model]); got != tt.want {
+				t.Errorf("supportsReasoningBudget(%s, %s) = %v, want %v", tt.provider, tt.model, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("unsupported provider ignores the cap", func(t *testing.T) {
+		p, err := NewProvider(models.ProviderOpenAI,
+			WithModel(models.SupportedModels[models.GPT4o]),
+			WithMaxReasoningTokens(2048),
+		)
+		if err != nil {
+			t.Fatalf("NewProvider: %v", err)
+		}
+		if got := p.(*baseProvider[OpenAIClient]).options.maxReasoningTokens; got != 0 {
+			t.Errorf("maxReasoningTokens = %d, want 0", got)
+		}
+	})
+}
+
+func TestToolAttachmentsConversion(t *testing.T) {
+	toolMsg := message.Message{Role: message.Tool, Parts: []message.ContentPart{
+		message.ToolResult{ToolCallID: "call_1", Name: "chart", Content: "Revenue grew 12%"},
+		message.BinaryContent{MIMEType: "image/png", Data: []byte("png")},
+	}}
+	opts := providerClientOptions{model: models.Model{APIModel: "test-model"}, maxTokens: 100}
+
+	t.Run("openai", func(t *testing.T) {
+		converted := newOpenAIClient(opts).(*openaiClient).convertMessages([]message.Message{toolMsg})
+		raw, err := json.Marshal(converted)
+		if err != nil {
+			t.Fatalf("marshal messages: %v", err)
+		}
+		var decoded []map[string]any
+		if err := json.Unmarshal(raw, &decoded); err != nil {
+			t.Fatalf("unmarshal messages: %v", err)
+		}
+		// system, tool result, attachments
+		if len(decoded) != 3 {
+			t.Fatalf("got %d messages, want 3: %s", len(decoded), raw)
+		}
+		if decoded[1]["role"] != "tool" || decoded[1]["content"] != "Revenue grew 12%" {
+			t.Errorf("tool message = %v", decoded[1])
+		}
+		if decoded[2]["role"] != "user" || !strings.Contains(string(raw), "data:image/png;base64,cG5n") {
+			t.Errorf("attachments message = %v", decoded[2])
+		}
+	})
+
+	t.Run("anthropic", func(t *testing.T) {
+		converted := newAnthropicClient(opts).(*anthropicClient).convertMessages([]message.Message{toolMsg})
+		if len(converted) != 1 {
+			t.Fatalf("got %d messages, want 1", len(converted))
+		}
+		blocks := converted[0].Content
+		if len(blocks) != 2 || blocks[0].OfToolResult == nil || blocks[1].OfImage == nil {
+			t.Errorf("content = %+v, want a tool result followed by an image", blocks)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+)
+
+type reasoningEffortContextKey string
+
+// ReasoningEffortContextKey carries a per-request reasoning effort override.
+const ReasoningEffortContextKey reasoningEffortContextKey = "reasoning_effort"
+
+// WithReasoningEffortOverride returns a context whose requests use the given
+// reasoning effort ("low", "medium" or "high") instead of the agent's
+// configured one. The stored configuration is left untouched.
+func WithReasoningEffortOverride(ctx context.Context, effort string) context.Context {
+	return context.WithValue(ctx, ReasoningEffortContextKey, effort)
+}
+
+// IsValidReasoningEffort reports whether effort is one of the supported
+// reasoning efforts.
+func IsValidReasoningEffort(effort string) bool {
+	switch effort {
+	case "low", "medium", "high":
+		return true
+	}
+	return false
+}
+
+// requestReasoningEffort returns the reasoning effort for a single request:
+// the override carried by ctx when it is valid and the model can reason,
+// otherwise the configured effort.
+func requestReasoningEffort(ctx context.Context, model models.Model, configured string) string {
+	effort, ok := ctx.Value(ReasoningEffortContextKey).(string)
+	if !ok || effort == "" {
+		return configured
+	}
+	if !model.CanReason {
+		logging.Warn("Ignoring reasoning effort override for model without reasoning support",
+			"model", model.Name,
+			"effort", effort,
+		)
+		return configured
+	}
+	if !IsValidReasoningEffort(effort) {
+		logging.Warn("Ignoring invalid reasoning effort override",
+			"model", model.Name,
+			"effort", effort,
+		)
+		return configured
+	}
+	return effort
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+func TestRequestReasoningEffort(t *testing.T) {
+	reasoning := models.Model{Name: "reasoner", CanReason: true}
+	plain := models.Model{Name: "plain"}
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		model    models.Model
+		expected string
+	}{
+		{
+			name:     "no override uses configured effort",
+			ctx:      context.Background(),
+			model:    reasoning,
+			expected: "low",
+		},
+		{
+			name:     "override is used for reasoning models",
+			ctx:      WithReasoningEffortOverride(context.Background(), "high"),
+			model:    reasoning,
+			expected: "high",
+		},
+		{
+			name:     "override is ignored for models that cannot reason",
+			ctx:      WithReasoningEffortOverride(context.Background(), "high"),
+			model:    plain,
+			expected: "low",
+		},
+		{
+			name:     "invalid override is ignored",
+			ctx:      WithReasoningEffortOverride(context.Background(), "extreme"),
+			model:    reasoning,
+			expected: "low",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := requestReasoningEffort(tt.ctx, tt.model, "low"); got != tt.expected {
+				t.Errorf("requestReasoningEffort() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestOpenAIReasoningEffortOverride(t *testing.T) {
+	client := &openaiClient{
+		providerOptions: providerClientOptions{
+			model:     models.Model{APIModel: "o3", CanReason: true},
+			maxTokens: 1000,
+		},
+		options: openaiOptions{reasoningEffort: "low"},
+	}
+
+	ctx := WithReasoningEffortOverride(context.Background(), "high")
+	params := client.preparedParams(ctx, nil, nil)
+	if params.ReasoningEffort != shared.ReasoningEffortHigh {
+		t.Errorf("expected overridden effort %q, got %q", shared.ReasoningEffortHigh, params.ReasoningEffort)
+	}
+	if client.options.reasoningEffort != "low" {
+		t.Errorf("configured effort changed to %q", client.options.reasoningEffort)
+	}
+
+	params = client.preparedParams(context.Background(), nil, nil)
+	if params.ReasoningEffort != shared.ReasoningEffortLow {
+		t.Errorf("expected configured effort %q without override, got %q", shared.ReasoningEffortLow, params.ReasoningEffort)
+	}
+}
+
+func TestAnthropicReasoningEffortOverride(t *testing.T) {
+	client := &anthropicClient{
+		providerOptions: providerClientOptions{
+			model:     models.Model{APIModel: "claude-opus", CanReason: true, SupportsAdaptiveThinking: true},
+			maxTokens: 1000,
+		},
+		options: anthropicOptions{reasoningEffort: "medium"},
+	}
+	messages := []anthropic.MessageParam{anthropic.NewUserMessage(anthropic.NewTextBlock("debug this"))}
+
+	ctx := WithReasoningEffortOverride(context.Background(), "high")
+	params := client.preparedMessages(ctx, messages, nil)
+	if params.OutputConfig.Effort != anthropic.OutputConfigEffort("high") {
+		t.Errorf("expected overridden effort high, got %q", params.OutputConfig.Effort)
+	}
+	if client.options.reasoningEffort != "medium" {
+		t.Errorf("configured effort changed to %q", client.options.reasoningEffort)
+	}
+
+	params = client.preparedMessages(context.Background(), messages, nil)
+	if params.OutputConfig.Effort != anthropic.OutputConfigEffort("medium") {
+		t.Errorf("expected configured effort medium without override, got %q", params.OutputConfig.Effort)
+	}
+}
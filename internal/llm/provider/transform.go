@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+// MessageTransformer is a single step that reshapes the conversation history
+// before it is handed to a provider client. Transformers must not modify the
+// messages they receive; they return a new slice instead.
+type MessageTransformer func([]message.Message) []message.Message
+
+// turnTransformers drop canceled assistant turns and leave the history in
+// alternating user/assistant turns, with all tool results for a turn in a
+// single message. The Messages and Gemini APIs require that shape; Chat
+// Completions clients split the tool results back into one message each.
+var turnTransformers = []MessageTransformer{DropEmptyAssistant, MergeConsecutiveSameRole, CoalesceToolResults}
+
+// defaultMessageTransformers lists the transformers each provider opts into.
+// They run after messages without parts have been removed and before tool
+// pairs are sanitized, so clients only have to map messages to their SDK
+// shape.
+var defaultMessageTransformers = map[models.ModelProvider][]MessageTransformer{
+	models.ProviderAnthropic:  turnTransformers,
+	models.ProviderVertexAI:   turnTransformers,
+	models.ProviderBedrock:    turnTransformers,
+	models.ProviderGemini:     turnTransformers,
+	models.ProviderOpenAI:     turnTransformers,
+	models.ProviderGroq:       turnTransformers,
+	models.ProviderOpenRouter: turnTransformers,
+	models.ProviderMistral:    turnTransformers,
+	models.ProviderLocal:      turnTransformers,
+	models.ProviderXAI:        turnTransformers,
+	models.ProviderDeepSeek:   turnTransformers,
+	models.ProviderKilo:       turnTransformers,
+}
+
+// TransformMessages runs messages through the given transformers in order.
+func TransformMessages(messages []message.Message, transformers ...MessageTransformer) []message.Message {
+	for _, transform := range transformers {
+		messages = transform(messages)
+	}
+	return messages
+}
+
+// MergeConsecutiveSameRole merges consecutive user messages and consecutive
+// assistant messages into one. Text is joined with a blank line; all other
+// parts are kept in order. Tool messages are left to CoalesceToolResults.
+func MergeConsecutiveSameRole(messages []message.Message) []message.Message {
+	return mergeAdjacent(messages, func(prev, next message.Message) bool {
+		return prev.Role == next.Role && (next.Role == message.User || next.Role == message.Assistant)
+	})
+}
+
+// CoalesceToolResults merges consecutive tool messages so that every result
+// answering one assistant turn is carried by a single message.
+func CoalesceToolResults(messages []message.Message) []message.Message {
+	return mergeAdjacent(messages, func(prev, next message.Message) bool {
+		return prev.Role == message.Tool && next.Role == message.Tool
+	})
+}
+
+// DropEmptyAssistant removes assistant messages that have neither text content
+// nor tool calls, such as canceled turns that only carry a Finish part.
+func DropEmptyAssistant(messages []message.Message) []message.Message {
+	result := make([]message.Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role == message.Assistant && msg.Content().String() == "" && len(msg.ToolCalls()) == 0 {
+			logging.Warn("Skipping assistant message with no content or tool calls (likely canceled)",
+				"message_id", msg.ID,
+			)
+			continue
+		}
+		result = append(result, msg)
+	}
+	return result
+}
+
+func mergeAdjacent(messages []message.Message, shouldMerge func(prev, next message.Message) bool) []message.Message {
+	result := make([]message.Message, 0, len(messages))
+	for _, msg := range messages {
+		if n := len(result); n > 0 && shouldMerge(result[n-1], msg) {
+			result[n-1] = mergeMessages(result[n-1], msg)
+			continue
+		}
+		result = append(result, msg)
+	}
+	return result
+}
+
+// mergeMessages combines b into a copy of a. Text parts are joined into the
+// first text part, since providers only read one, and only the last Finish
+// part is kept.
+func mergeMessages(a, b message.Message) message.Message {
+	var texts []string
+	var finish *message.Finish
+	parts := make([]message.ContentPart, 0, len(a.Parts)+len(b.Parts))
+	textIdx := -1
+
+	for _, part := range append(append([]message.ContentPart{}, a.Parts...), b.Parts...) {
+		switch p := part.(type) {
+		case message.TextContent:
+			if p.Text != "" {
+				texts = append(texts, p.Text)
+			}
+			if textIdx < 0 {
+				textIdx = len(parts)
+				parts = append(parts, p)
+			}
+		case message.Finish:
+			finish = &p
+		default:
+			parts = append(parts, part)
+		}
+	}
+	if textIdx >= 0 {
+		parts[textIdx] = message.TextContent{Text: strings.Join(texts, "\n\n")}
+	}
+	if finish != nil {
+		parts = append(parts, *finish)
+	}
+
+	merged := a
+	merged.Parts = parts
+	if b.UpdatedAt > merged.UpdatedAt {
+		merged.UpdatedAt = b.UpdatedAt
+	}
+	return merged
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+)
+
+func TestMergeConsecutiveSameRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []message.Message
+		check    func(*testing.T, []message.Message)
+	}{
+		{
+			name: "two consecutive user messages are merged",
+			messages: []message.Message{
+				{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "first"}}},
+				{Role: message.User, Parts: []message.ContentPart{
+					message.TextContent{Text: "second"},
+					message.BinaryContent{MIMEType: "image/png", Data: []byte("png")},
+				}},
+			},
+			check: func(t *testing.T, msgs []message.Message) {
+				if len(msgs) != 1 {
+					t.Fatalf("expected 1 message, got %d", len(msgs))
+				}
+				if got := msgs[0].Content().String(); got != "first\n\nsecond" {
+					t.Errorf("expected joined text, got %q", got)
+				}
+				if len(msgs[0].BinaryContent()) != 1 {
+					t.Errorf("expected binary content to be kept")
+				}
+			},
+		},
+		{
+			name: "consecutive assistant messages keep tool calls and last finish",
+			messages: []message.Message{
+				{Role: message.Assistant, Parts: []message.ContentPart{
+					message.TextContent{Text: "thinking aloud"},
+					message.Finish{Reason: message.FinishReasonEndTurn},
+				}},
+				{Role: message.Assistant, Parts: []message.ContentPart{
+					message.ToolCall{ID: "tc-1", Name: "bash", Input: "{}", Finished: true},
+					message.Finish{Reason: message.FinishReasonToolUse},
+				}},
+			},
+			check: func(t *testing.T, msgs []message.Message) {
+				if len(msgs) != 1 {
+					t.Fatalf("expected 1 message, got %d", len(msgs))
+				}
+				if len(msgs[0].ToolCalls()) != 1 {
+					t.Errorf("expected tool call to be kept")
+				}
+				if got := msgs[0].FinishReason(); got != message.FinishReasonToolUse {
+					t.Errorf("expected last finish reason, got %s", got)
+				}
+			},
+		},
+		{
+			name: "alternating roles and tool messages are untouched",
+			messages: []message.Message{
+				{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "a"}}},
+				{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "b"}}},
+				{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "1"}}},
+				{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "2"}}},
+			},
+			check: func(t *testing.T, msgs []message.Message) {
+				if len(msgs) != 4 {
+					t.Errorf("expected 4 messages, got %d", len(msgs))
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, MergeConsecutiveSameRole(tt.messages))
+		})
+	}
+}
+
+func TestMergeConsecutiveSameRoleDoesNotMutateInput(t *testing.T) {
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "first"}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "second"}}},
+	}
+	MergeConsecutiveSameRole(messages)
+	if got := messages[0].Content().String(); got != "first" {
+		t.Errorf("input message was modified: %q", got)
+	}
+	if len(messages[0].Parts) != 1 {
+		t.Errorf("input parts were modified: %d parts", len(messages[0].Parts))
+	}
+}
+
+func TestDropEmptyAssistant(t *testing.T) {
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hello"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.Finish{Reason: message.FinishReasonCanceled}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "tc-1", Name: "bash", Input: "{}", Finished: true},
+		}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.TextContent{Text: "done"}}},
+	}
+	result := DropEmptyAssistant(messages)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(result))
+	}
+	if len(result[1].ToolCalls()) != 1 {
+		t.Errorf("assistant with only tool calls should be kept")
+	}
+}
+
+func TestCoalesceToolResults(t *testing.T) {
+	messages := []message.Message{
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.ToolCall{ID: "tc-1", Name: "bash", Input: "{}", Finished: true},
+			message.ToolCall{ID: "tc-2", Name: "ls", Input: "{}", Finished: true},
+		}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "tc-1", Content: "a"}}},
+		{Role: message.Tool, Parts: []message.ContentPart{message.ToolResult{ToolCallID: "tc-2", Content: "b"}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "next"}}},
+	}
+	result := CoalesceToolResults(messages)
+	if len(result) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(result))
+	}
+	results := result[1].ToolResults()
+	if len(results) != 2 || results[0].ToolCallID != "tc-1" || results[1].ToolCallID != "tc-2" {
+		t.Errorf("expected both tool results in order, got %+v", results)
+	}
+
+	// With the results coalesced, sanitizeToolPairs sees a complete pair and
+	// has nothing to synthesize.
+	sanitized := newTestProvider().sanitizeToolPairs(result)
+	if len(sanitized) != 3 {
+		t.Errorf("expected 3 messages after sanitizing, got %d", len(sanitized))
+	}
+}
+
+func TestPrepareMessagesUsesTransformers(t *testing.T) {
+	p := &baseProvider[AnthropicClient]{
+		options: providerClientOptions{
+			messageTransformers: []MessageTransformer{DropEmptyAssistant, MergeConsecutiveSameRole},
+		},
+	}
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "one"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.Finish{Reason: message.FinishReasonCanceled}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "two"}}},
+	}
+	result := p.prepareMessages(messages)
+	if len(result) != 1 {
+		t.Fatalf("expected empty assistant dropped and users merged, got %d messages", len(result))
+	}
+	if got := result[0].Content().String(); got != "one\n\ntwo" {
+		t.Errorf("unexpected merged content %q", got)
+	}
+}
+
+func TestProvidersOptIntoTransformers(t *testing.T) {
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "one"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{message.Finish{Reason: message.FinishReasonCanceled}}},
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "two"}}},
+	}
+	for _, name := range []models.ModelProvider{
+		models.ProviderAnthropic, models.ProviderOpenAI, models.ProviderDeepSeek, models.ProviderXAI, models.ProviderKilo,
+	} {
+		t.Run(string(name), func(t *testing.T) {
+			p, err := NewProvider(name, WithAPIKey("test"), WithModel(models.Model{APIModel: "test-model"}))
+			if err != nil {
+				t.Fatalf("NewProvider(%s): %v", name, err)
+			}
+			preparer, ok := p.(interface {
+				prepareMessages([]message.Message) []message.Message
+			})
+			if !ok {
+				t.Fatalf("unexpected provider type %T", p)
+			}
+			result := preparer.prepareMessages(messages)
+			if len(result) != 1 || result[0].Content().String() != "one\n\ntwo" {
+				t.Errorf("prepareMessages() = %d messages, want the empty assistant dropped and the users merged", len(result))
+			}
+		})
+	}
+}
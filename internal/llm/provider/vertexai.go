@@ -62,18 +62,9 @@ func newVertexAIClient(opts providerClientOptions) VertexAIClient {
 		Backend:  genai.BackendVertexAI,
 	}
 
-	if opts.baseURL != "" {
-		if opts.headers != nil {
-			header := opts.asHeader()
-			genaiConfig.HTTPOptions = genai.HTTPOptions{
-				BaseURL: opts.baseURL,
-				Headers: *header,
-			}
-		} else {
-			genaiConfig.HTTPOptions = genai.HTTPOptions{
-				BaseURL: opts.baseURL,
-			}
-		}
+	genaiConfig.HTTPOptions = genai.HTTPOptions{
+		BaseURL: opts.baseURL,
+		Headers: *opts.asHeader(),
 	}
 
 	if opts.apiKey != "" {
@@ -0,0 +1,473 @@
+package provider
+
+// xAI provider implementation using the OpenAI SDK with the xAI API.
+// Grok models are served through an OpenAI-compatible chat completions endpoint;
+// reasoning variants take max_completion_tokens, report reasoning tokens in
+// usage and may stream their reasoning as "reasoning_content" deltas.
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/packages/respjson"
+	"github.com/openai/openai-go/v3/shared"
+)
+
+const xaiDefaultBaseURL = "https://api.x.ai/v1"
+
+type xaiOptions struct {
+	baseURL         string
+	extraHeaders    map[string]string
+	reasoningEffort string // low, high; only honoured by models that accept it
+}
+
+// XAIOption is a function that configures xAI provider options.
+type XAIOption func(*xaiOptions)
+
+type xaiClient struct {
+	providerOptions providerClientOptions
+	options         xaiOptions
+	client          openai.Client
+}
+
+// XAIClient is the interface for xAI provider operations.
+type XAIClient ProviderClient
+
+func newXAIClient(opts providerClientOptions) XAIClient {
+	xaiOpts := xaiOptions{}
+	for _, o := range opts.xaiOptions {
+		o(&xaiOpts)
+	}
+	if xaiOpts.baseURL == "" {
+		xaiOpts.baseURL = opts.baseURL
+	}
+	if xaiOpts.baseURL == "" {
+		xaiOpts.baseURL = xaiDefaultBaseURL
+	}
+
+	xaiClientOptions := []option.RequestOption{
+		option.WithBaseURL(xaiOpts.baseURL),
+	}
+	if opts.apiKey != "" {
+		xaiClientOptions = append(xaiClientOptions, option.WithAPIKey(opts.apiKey))
+	}
+	if opts.userAgent != "" {
+		xaiClientOptions = append(xaiClientOptions, option.WithHeader(UserAgentHeader, opts.userAgent))
+	}
+	for key, value := range opts.headers {
+		xaiClientOptions = append(xaiClientOptions, option.WithHeader(key, value))
+	}
+	for key, value := range xaiOpts.extraHeaders {
+		xaiClientOptions = append(xaiClientOptions, option.WithHeader(key, value))
+	}
+
+	return &xaiClient{
+		providerOptions: opts,
+		options:         xaiOpts,
+		client:          openai.NewClient(xaiClientOptions...),
+	}
+}
+
+func (x *xaiClient) convertMessages(messages []message.Message) []openai.ChatCompletionMessageParamUnion {
+	// Add system message first
+	xaiMessages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(x.providerOptions.systemMessage),
+	}
+	for _, msg := range messages {
+		switch msg.Role {
+		case message.User:
+			var content []openai.ChatCompletionContentPartUnionParam
+			textBlock := openai.ChatCompletionContentPartTextParam{Text: msg.Content().String()}
+			content = append(content, openai.ChatCompletionContentPartUnionParam{OfText: &textBlock})
+			for _, binaryContent := range msg.BinaryContent() {
+				imageURL := openai.ChatCompletionContentPartImageImageURLParam{URL: binaryContent.String(models.ProviderXAI)}
+				imageBlock := openai.ChatCompletionContentPartImageParam{ImageURL: imageURL}
+				content = append(content, openai.ChatCompletionContentPartUnionParam{OfImageURL: &imageBlock})
+			}
+			xaiMessages = append(xaiMessages, openai.UserMessage(content))
+
+		case message.Assistant:
+			assistantMsg := openai.ChatCompletionAssistantMessageParam{
+				Role: "assistant",
+			}
+
+			if msg.Content().String() != "" {
+				assistantMsg.Content = openai.ChatCompletionAssistantMessageParamContentUnion{
+					OfString: openai.String(msg.Content().String()),
+				}
+			}
+
+			if len(msg.ToolCalls()) > 0 {
+				assistantMsg.ToolCalls = make([]openai.ChatCompletionMessageToolCallUnionParam, len(msg.ToolCalls()))
+				for i, call := range msg.ToolCalls() {
+					toolCall := openai.ChatCompletionMessageFunctionToolCallParam{
+						ID:   call.ID,
+						Type: "function",
+						Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+							Name:      call.Name,
+							Arguments: call.Input,
+						},
+					}
+					assistantMsg.ToolCalls[i] = openai.ChatCompletionMessageToolCallUnionParam{
+						OfFunction: &toolCall,
+					}
+				}
+			}
+
+			xaiMessages = append(xaiMessages, openai.ChatCompletionMessageParamUnion{
+				OfAssistant: &assistantMsg,
+			})
+
+		case message.Tool:
+			for _, result := range msg.ToolResults() {
+				xaiMessages = append(xaiMessages,
+					openai.ToolMessage(result.Content, result.ToolCallID),
+				)
+			}
+			if attachments, ok := openaiToolAttachmentsMessage(msg); ok {
+				xaiMessages = append(xaiMessages, attachments)
+			}
+		}
+	}
+
+	return xaiMessages
+}
+
+func (x *xaiClient) convertTools(tools []tools.BaseTool) []openai.ChatCompletionToolUnionParam {
+	if len(tools) == 0 {
+		return nil
+	}
+
+	xaiTools := make([]openai.ChatCompletionToolUnionParam, len(tools))
+	for i, tool := range tools {
+		info := tool.Info()
+		xaiTools[i] = openai.ChatCompletionFunctionTool(
+			openai.FunctionDefinitionParam{
+				Name:        info.Name,
+				Description: openai.String(toolDescription(info)),
+				Parameters: openai.FunctionParameters{
+					"type":       "object",
+					"properties": info.Parameters,
+					"required":   info.Required,
+				},
+			},
+		)
+	}
+
+	return xaiTools
+}
+
+func (x *xaiClient) finishReason(reason string) message.FinishReason {
+	switch reason {
+	case "stop":
+		return message.FinishReasonEndTurn
+	case "length":
+		return message.FinishReasonMaxTokens
+	case "tool_calls":
+		return message.FinishReasonToolUse
+	default:
+		return message.FinishReasonUnknown
+	}
+}
+
+func (x *xaiClient) preparedParams(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, tools []openai.ChatCompletionToolUnionParam) openai.ChatCompletionNewParams {
+	model := x.providerOptions.model
+	params := openai.ChatCompletionNewParams{
+		Model:    openai.ChatModel(model.APIModel),
+		Messages: messages,
+	}
+	if len(tools) > 0 {
+		params.Tools = tools
+	}
+	applyOpenAISampling(&params, x.providerOptions)
+
+	if !model.CanReason {
+		params.MaxTokens = openai.Int(x.providerOptions.maxTokens)
+		return params
+	}
+
+	params.MaxCompletionTokens = openai.Int(x.providerOptions.maxTokens)
+	// Grok 4 reasoning models reason unconditionally and reject
+	// reasoning_effort; the models that accept it take "low" or "high".
+	if model.SupportsReasoningEffort {
+		switch requestReasoningEffort(ctx, model, x.options.reasoningEffort) {
+		case "low":
+			params.ReasoningEffort = shared.ReasoningEffortLow
+		case "medium", "high":
+			params.ReasoningEffort = shared.ReasoningEffortHigh
+		}
+	}
+	return params
+}
+
+func (x *xaiClient) send(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (response *ProviderResponse, err error) {
+	params := x.preparedParams(ctx, x.convertMessages(messages), x.convertTools(tools))
+	cfg := config.Get()
+	if cfg != nil && cfg.Debug {
+		jsonData, _ := json.Marshal(params)
+		logging.Debug("xAI prepared messages", "messages", string(jsonData))
+	}
+
+	requestOpts := openaiHeaderOptions(x.providerOptions.idempotencyHeaderValues())
+	attempts := 0
+	for {
+		attempts++
+		xaiResponse, err := x.client.Chat.Completions.New(ctx, params, requestOpts...)
+		// If there is an error we are going to see if we can retry the call
+		if err != nil {
+			retry, after, retryErr := x.shouldRetry(attempts, err)
+			if retryErr != nil {
+				return nil, retryErr
+			}
+			if retry {
+				logging.WarnPersist(fmt.Sprintf("xAI: Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Duration(after) * time.Millisecond):
+					continue
+				}
+			}
+			return nil, retryErr
+		}
+		if len(xaiResponse.Choices) == 0 {
+			return nil, errors.New("xAI: response contained no choices")
+		}
+
+		toolCalls := x.toolCalls(*xaiResponse)
+		finishReason := x.finishReason(string(xaiResponse.Choices[0].FinishReason))
+		if len(toolCalls) > 0 {
+			finishReason = message.FinishReasonToolUse
+		}
+
+		return &ProviderResponse{
+			Content:      xaiResponse.Choices[0].Message.Content,
+			Thinking:     xaiReasoningContent(xaiResponse.Choices[0].Message.JSON.ExtraFields),
+			ToolCalls:    toolCalls,
+			Usage:        x.usage(*xaiResponse),
+			FinishReason: finishReason,
+		}, nil
+	}
+}
+
+func (x *xaiClient) stream(ctx context.Context, messages []message.Message, tools []tools.BaseTool) <-chan ProviderEvent {
+	params := x.preparedParams(ctx, x.convertMessages(messages), x.convertTools(tools))
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+		IncludeUsage: openai.Bool(true),
+	}
+
+	cfg := config.Get()
+	if cfg != nil && cfg.Debug {
+		jsonData, _ := json.Marshal(params)
+		logging.Debug("xAI prepared messages", "messages", string(jsonData))
+	}
+
+	requestOpts := openaiHeaderOptions(x.providerOptions.idempotencyHeaderValues())
+	attempts := 0
+	eventChan := make(chan ProviderEvent)
+
+	go func() {
+		for {
+			attempts++
+			xaiStream := x.client.Chat.Completions.NewStreaming(ctx, params, requestOpts...)
+
+			acc := openai.ChatCompletionAccumulator{}
+			var content strings.Builder
+
+			for xaiStream.Next() {
+				chunk := xaiStream.Current()
+				acc.AddChunk(chunk)
+
+				for _, choice := range chunk.Choices {
+					if thinking := xaiReasoningContent(choice.Delta.JSON.ExtraFields); thinking != "" {
+						eventChan <- ProviderEvent{
+							Type:     EventThinkingDelta,
+							Thinking: thinking,
+						}
+					}
+					if choice.Delta.Content != "" {
+						eventChan <- ProviderEvent{
+							Type:    EventContentDelta,
+							Content: choice.Delta.Content,
+						}
+						content.WriteString(choice.Delta.Content)
+					}
+				}
+			}
+
+			err := xaiStream.Err()
+			if err == nil || errors.Is(err, io.EOF) {
+				if len(acc.Choices) == 0 {
+					eventChan <- ProviderEvent{Type: EventError, Error: errors.New("xAI: stream contained no choices")}
+					close(eventChan)
+					return
+				}
+
+				toolCalls := x.toolCalls(acc.ChatCompletion)
+				finishReason := x.finishReason(string(acc.Choices[0].FinishReason))
+				if len(toolCalls) > 0 {
+					finishReason = message.FinishReasonToolUse
+				}
+
+				eventChan <- ProviderEvent{
+					Type: EventComplete,
+					Response: &ProviderResponse{
+						Content:      content.String(),
+						ToolCalls:    toolCalls,
+						Usage:        x.usage(acc.ChatCompletion),
+						FinishReason: finishReason,
+					},
+				}
+				close(eventChan)
+				return
+			}
+
+			// If there is an error we are going to see if we can retry the call
+			retry, after, retryErr := x.shouldRetry(attempts, err)
+			if retryErr != nil {
+				eventChan <- ProviderEvent{Type: EventError, Error: retryErr}
+				close(eventChan)
+				return
+			}
+			if retry {
+				logging.WarnPersist(fmt.Sprintf("xAI: Retrying due to rate limit... attempt %d of %d", attempts, maxRetries), logging.PersistTimeArg, time.Millisecond*time.Duration(after+100))
+				select {
+				case <-ctx.Done():
+					if ctx.Err() != nil {
+						eventChan <- ProviderEvent{Type: EventError, Error: ctx.Err()}
+					}
+					close(eventChan)
+					return
+				case <-time.After(time.Duration(after) * time.Millisecond):
+					continue
+				}
+			}
+			eventChan <- ProviderEvent{Type: EventError, Error: retryErr}
+			close(eventChan)
+			return
+		}
+	}()
+
+	return eventChan
+}
+
+// xaiReasoningContent returns the reasoning text carried by a message or a
+// streamed delta, given its extra fields. xAI sends it as a non-standard
+// "reasoning_content" field.
+func xaiReasoningContent(extraFields map[string]respjson.Field) string {
+	field, ok := extraFields["reasoning_content"]
+	if !ok || field.Raw() == "" {
+		return ""
+	}
+	var thinking string
+	if err := json.Unmarshal([]byte(field.Raw()), &thinking); err != nil {
+		return ""
+	}
+	return thinking
+}
+
+func (x *xaiClient) shouldRetry(attempts int, err error) (bool, int64, error) {
+	var apierr *openai.Error
+	if !errors.As(err, &apierr) {
+		return false, 0, err
+	}
+
+	if apierr.StatusCode != http.StatusTooManyRequests && apierr.StatusCode != http.StatusInternalServerError {
+		return false, 0, err
+	}
+
+	if attempts > maxRetries {
+		return false, 0, fmt.Errorf("xAI: maximum retry attempts reached: %d retries", maxRetries)
+	}
+
+	backoffMs := 2000 * (1 << (attempts - 1))
+	jitterMs := int(float64(backoffMs) * 0.2)
+	retryMs := backoffMs + jitterMs
+
+	retryAfterValues := apierr.Response.Header.Values("Retry-After")
+	if len(retryAfterValues) > 0 {
+		if _, err := fmt.Sscanf(retryAfterValues[0], "%d", &retryMs); err == nil {
+			retryMs *= 1000
+		}
+	}
+	return true, int64(retryMs), nil
+}
+
+func (x *xaiClient) toolCalls(completion openai.ChatCompletion) []message.ToolCall {
+	var toolCalls []message.ToolCall
+
+	if len(completion.Choices) > 0 {
+		for _, call := range completion.Choices[0].Message.ToolCalls {
+			toolCalls = append(toolCalls, message.ToolCall{
+				ID:       call.ID,
+				Name:     call.Function.Name,
+				Input:    call.Function.Arguments,
+				Type:     "function",
+				Finished: true,
+			})
+		}
+	}
+
+	return toolCalls
+}
+
+// usage converts xAI token usage. Reasoning tokens are reported separately
+// from completion tokens but billed as output, so they are added to
+// OutputTokens.
+func (x *xaiClient) usage(completion openai.ChatCompletion) TokenUsage {
+	cachedTokens := completion.Usage.PromptTokensDetails.CachedTokens
+	outputTokens := completion.Usage.CompletionTokens + completion.Usage.CompletionTokensDetails.ReasoningTokens
+
+	return TokenUsage{
+		InputTokens:     completion.Usage.PromptTokens - cachedTokens,
+		OutputTokens:    outputTokens,
+		CacheReadTokens: cachedTokens,
+		TotalTokens:     completion.Usage.PromptTokens + outputTokens,
+	}
+}
+
+func (x *xaiClient) countTokens(ctx context.Context, messages []message.Message, tools []tools.BaseTool) (int64, error) {
+	return 0, fmt.Errorf("countTokens is unsupported by xai client: %w", errors.ErrUnsupported)
+}
+
+func (x *xaiClient) setMaxTokens(maxTokens int64) {
+	x.providerOptions.maxTokens = maxTokens
+}
+
+func (x *xaiClient) maxTokens() int64 {
+	return x.providerOptions.maxTokens
+}
+
+// WithXAIBaseURL sets a custom base URL for the xAI API.
+func WithXAIBaseURL(baseURL string) XAIOption {
+	return func(options *xaiOptions) {
+		options.baseURL = baseURL
+	}
+}
+
+// WithXAIExtraHeaders sets additional HTTP headers for xAI API requests.
+func WithXAIExtraHeaders(headers map[string]string) XAIOption {
+	return func(options *xaiOptions) {
+		options.extraHeaders = headers
+	}
+}
+
+// WithXAIReasoningEffort sets the reasoning effort for xAI models that accept
+// it. Values: "low", "high"; "medium" is sent as "high".
+func WithXAIReasoningEffort(effort string) XAIOption {
+	return func(options *xaiOptions) {
+		options.reasoningEffort = effort
+	}
+}
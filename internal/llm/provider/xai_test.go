@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/models"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestXAIClient(baseURL string, model models.Model) *xaiClient {
+	return newXAIClient(providerClientOptions{
+		apiKey:        "test-key",
+		model:         model,
+		maxTokens:     1000,
+		systemMessage: "You are Grok.",
+		xaiOptions:    []XAIOption{WithXAIBaseURL(baseURL)},
+	}).(*xaiClient)
+}
+
+func TestXAIConvertMessages(t *testing.T) {
+	client := newTestXAIClient("http://localhost", models.SupportedModels[models.XAIGrok41FastReasoning])
+
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "list files"}}},
+		{Role: message.Assistant, Parts: []message.ContentPart{
+			message.TextContent{Text: "Listing."},
+			message.ToolCall{ID: "call_1", Name: "ls", Input: `{"path":"."}`, Type: "function", Finished: true},
+		}},
+		{Role: message.Tool, Parts: []message.ContentPart{
+			message.ToolResult{ToolCallID: "call_1", Name: "ls", Content: "main.go"},
+		}},
+	}
+
+	converted := client.convertMessages(messages)
+	require.Len(t, converted, 4)
+
+	raw, err := json.Marshal(converted)
+	require.NoError(t, err)
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(raw, &decoded))
+
+	assert.Equal(t, "system", decoded[0]["role"])
+	assert.Equal(t, "You are Grok.", decoded[0]["content"])
+	assert.Equal(t, "user", decoded[1]["role"])
+	assert.Equal(t, "assistant", decoded[2]["role"])
+	assert.Equal(t, "Listing.", decoded[2]["content"])
+	toolCalls, ok := decoded[2]["tool_calls"].([]any)
+	require.True(t, ok)
+	require.Len(t, toolCalls, 1)
+	assert.Equal(t, "call_1", toolCalls[0].(map[string]any)["id"])
+	assert.Equal(t, "tool", decoded[3]["role"])
+	assert.Equal(t, "call_1", decoded[3]["tool_call_id"])
+}
+
+func TestXAIPreparedParams(t *testing.T) {
+	t.Run("reasoning models use max_completion_tokens", func(t *testing.T) {
+		client := newTestXAIClient("http://localhost", models.SupportedModels[models.XAIGrok41FastReasoning])
+		params := client.preparedParams(context.Background(), nil, nil)
+		assert.Equal(t, int64(1000), params.MaxCompletionTokens.Value)
+		assert.False(t, params.MaxTokens.Valid())
+		assert.Empty(t, params.ReasoningEffort)
+	})
+
+	t.Run("non-reasoning models use max_tokens", func(t *testing.T) {
+		client := newTestXAIClient("http://localhost", models.SupportedModels[models.XAIGrok41FastNonReasoning])
+		params := client.preparedParams(context.Background(), nil, nil)
+		assert.Equal(t, int64(1000), params.MaxTokens.Value)
+		assert.False(t, params.MaxCompletionTokens.Valid())
+	})
+
+	t.Run("reasoning effort is sent to models that accept it", func(t *testing.T) {
+		client := newTestXAIClient("http://localhost", models.SupportedModels[models.XAIGrok3Mini])
+		client.options.reasoningEffort = "low"
+		params := client.preparedParams(context.Background(), nil, nil)
+		assert.EqualValues(t, "low", params.ReasoningEffort)
+
+		params = client.preparedParams(WithReasoningEffortOverride(context.Background(), "medium"), nil, nil)
+		assert.EqualValues(t, "high", params.ReasoningEffort)
+	})
+}
+
+func TestXAISendParsesUsage(t *testing.T) {
+	var requestBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &requestBody)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "resp_1",
+			"object": "chat.completion",
+			"created": 1,
+			"model": "grok-4-1-fast-reasoning",
+			"choices": [{
+				"index": 0,
+				"finish_reason": "stop",
+				"message": {"role": "assistant", "content": "Hello there", "reasoning_content": "Greet back."}
+			}],
+			"usage": {
+				"prompt_tokens": 120,
+				"completion_tokens": 30,
+				"total_tokens": 250,
+				"prompt_tokens_details": {"cached_tokens": 20},
+				"completion_tokens_details": {"reasoning_tokens": 100}
+			}
+		}`)
+	}))
+	defer server.Close()
+
+	client := newTestXAIClient(server.URL, models.SupportedModels[models.XAIGrok41FastReasoning])
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}},
+	}
+
+	resp, err := client.send(context.Background(), messages, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "grok-4-1-fast-reasoning", requestBody["model"])
+	assert.EqualValues(t, 1000, requestBody["max_completion_tokens"])
+	assert.Equal(t, "Hello there", resp.Content)
+	assert.Equal(t, "Greet back.", resp.Thinking)
+	assert.Equal(t, message.FinishReasonEndTurn, resp.FinishReason)
+	assert.Equal(t, TokenUsage{
+		InputTokens:     100,
+		OutputTokens:    130,
+		CacheReadTokens: 20,
+		TotalTokens:     250,
+	}, resp.Usage)
+}
+
+func TestXAIStream(t *testing.T) {
+	chunks := []string{
+		`{"id":"c","object":"chat.completion.chunk","created":1,"model":"grok-4-1-fast-reasoning","choices":[{"index":0,"delta":{"role":"assistant","reasoning_content":"Thinking."}}]}`,
+		`{"id":"c","object":"chat.completion.chunk","created":1,"model":"grok-4-1-fast-reasoning","choices":[{"index":0,"delta":{"content":"Hel"}}]}`,
+		`{"id":"c","object":"chat.completion.chunk","created":1,"model":"grok-4-1-fast-reasoning","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+		`{"id":"c","object":"chat.completion.chunk","created":1,"model":"grok-4-1-fast-reasoning","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":2,"total_tokens":17,"completion_tokens_details":{"reasoning_tokens":5}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := newTestXAIClient(server.URL, models.SupportedModels[models.XAIGrok41FastReasoning])
+	messages := []message.Message{
+		{Role: message.User, Parts: []message.ContentPart{message.TextContent{Text: "hi"}}},
+	}
+
+	var thinking, content strings.Builder
+	var final *ProviderResponse
+	for event := range client.stream(context.Background(), messages, nil) {
+		switch event.Type {
+		case EventThinkingDelta:
+			thinking.WriteString(event.Thinking)
+		case EventContentDelta:
+			content.WriteString(event.Content)
+		case EventComplete:
+			final = event.Response
+		case EventError:
+			t.Fatalf("unexpected stream error: %v", event.Error)
+		}
+	}
+
+	assert.Equal(t, "Thinking.", thinking.String())
+	assert.Equal(t, "Hello", content.String())
+	require.NotNil(t, final)
+	assert.Equal(t, "Hello", final.Content)
+	assert.Equal(t, message.FinishReasonEndTurn, final.FinishReason)
+	assert.Equal(t, int64(10), final.Usage.InputTokens)
+	assert.Equal(t, int64(7), final.Usage.OutputTokens)
+}
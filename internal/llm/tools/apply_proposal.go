@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+type ApplyProposalParams struct {
+	ProposalID string `json:"proposal_id"`
+}
+
+type applyProposalTool struct {
+	multiEdit BaseTool
+}
+
+const (
+	ApplyProposalToolName    = "apply_proposal"
+	applyProposalDescription = `Applies an edit proposal created by the propose_edit tool.
+
+WHEN TO USE THIS TOOL:
+- After the user has reviewed and approved a proposal
+
+HOW TO USE:
+- Provide the proposal_id returned by propose_edit
+
+FEATURES:
+- The edits are written through the multiedit tool, with the same permission checks and file history
+- The proposal is removed once it has been applied
+
+LIMITATIONS:
+- Fails if the file changed since the proposal was made; create a new proposal in that case`
+)
+
+// NewApplyProposalTool returns the apply_proposal tool. Proposals are written
+// by a multiedit tool built from the same services.
+func NewApplyProposalTool(lspService lsp.LspService, permissions permission.Service, files history.Service, reg agentregistry.Registry) BaseTool {
+	return &applyProposalTool{
+		multiEdit: NewMultiEditTool(lspService, permissions, files, reg),
+	}
+}
+
+func (a *applyProposalTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ApplyProposalToolName,
+		Description: applyProposalDescription,
+		Parameters: map[string]any{
+			"proposal_id": map[string]any{
+				"type":        "string",
+				"description": "The ID of the proposal returned by propose_edit",
+			},
+		},
+		Required: []string{"proposal_id"},
+	}
+}
+
+func (a *applyProposalTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ApplyProposalParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
+	}
+	if params.ProposalID == "" {
+		return NewMissingParamResponse("proposal_id", "proposal_id is required"), nil
+	}
+
+	proposal, err := loadProposal(params.ProposalID)
+	if errors.Is(err, errProposalNotFound) {
+		return NewTextErrorResponse(fmt.Sprintf("proposal %s not found", params.ProposalID)), nil
+	}
+	if err != nil {
+		return NewEmptyResponse(), err
+	}
+
+	hash, err := fileContentHash(proposal.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewNotFoundResponse("file not found: "+proposal.FilePath, proposal.FilePath), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
+	}
+	if hash != proposal.FileHash {
+		return NewTextErrorResponse(fmt.Sprintf("%s has changed since proposal %s was made. Read the file and create a new proposal", proposal.FilePath, proposal.ID)), nil
+	}
+	// The matching hash shows the content is what the proposal was reviewed
+	// against, which satisfies multiedit's read-before-edit check.
+	recordFileRead(proposal.FilePath)
+
+	input, err := json.Marshal(MultiEditParams{FilePath: proposal.FilePath, Edits: proposal.Edits})
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("failed to encode edits: %w", err)
+	}
+	response, err := a.multiEdit.Run(ctx, ToolCall{ID: call.ID, Name: MultiEditToolName, Input: string(input)})
+	if err != nil || response.IsError {
+		return response, err
+	}
+
+	if err := os.Remove(proposalPath(proposal.ID)); err != nil {
+		logging.Warn("Failed to remove applied proposal", "proposal", proposal.ID, "error", err)
+	}
+	response.Content = fmt.Sprintf("Applied proposal %s.\n%s", proposal.ID, response.Content)
+	return response, nil
+}
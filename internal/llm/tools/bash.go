@@ -218,7 +218,7 @@ func (b *bashTool) Info() ToolInfo {
 func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params BashParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("invalid parameters"), err
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
 	}
 
 	if params.Timeout > MaxTimeout {
@@ -231,7 +231,7 @@ func (b *bashTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse("missing command"), nil
 	}
 
-	workdir := params.Workdir
+	workdir := resolveCallPath(ctx, params.Workdir)
 	if workdir == "" {
 		workdir = config.WorkingDirectory()
 	}
@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+)
+
+// utf8BOM is the UTF-8 encoding of the byte-order mark U+FEFF.
+const utf8BOM = "\xEF\xBB\xBF"
+
+// bomParameter is the schema of the bom parameter of the edit and write
+// tools.
+var bomParameter = map[string]any{
+	"type":        "string",
+	"enum":        []string{string(config.BOMPreserve), string(config.BOMAdd), string(config.BOMStrip)},
+	"description": "Whether the written file starts with a UTF-8 byte-order mark: preserve the file's current state (new files get none), add one or strip it. Defaults to the tools.bom setting, or preserve",
+}
+
+// splitBOM returns content without a leading UTF-8 BOM and whether it had
+// one. Edits match and diff the content without it.
+func splitBOM(content string) (string, bool) {
+	if rest, ok := strings.CutPrefix(content, utf8BOM); ok {
+		return rest, true
+	}
+	return content, false
+}
+
+// resolveBOMMode returns the BOM mode of a call: the call's own, the
+// tools.bom setting, or preserve.
+func resolveBOMMode(param string) (config.BOMMode, error) {
+	mode := config.BOMMode(param)
+	if !mode.Valid() {
+		return "", fmt.Errorf("invalid bom %q: must be %q, %q or %q", param, config.BOMPreserve, config.BOMAdd, config.BOMStrip)
+	}
+	if mode == "" {
+		if cfg := config.Get(); cfg != nil {
+			mode = cfg.Tools.BOM
+		}
+	}
+	if mode == "" {
+		mode = config.BOMPreserve
+	}
+	return mode, nil
+}
+
+// withBOM returns content, which has no BOM, as it is written in mode for a
+// file whose current BOM state is hadBOM.
+func withBOM(content string, mode config.BOMMode, hadBOM bool) string {
+	if mode == config.BOMAdd || (mode == config.BOMPreserve && hadBOM) {
+		return utf8BOM + content
+	}
+	return content
+}
+
+// restoreBOM returns content, split by splitBOM, as it was before the split.
+// File history stores files with their BOM, so restoring a version restores
+// the file's bytes.
+func restoreBOM(content string, hadBOM bool) string {
+	return withBOM(content, config.BOMPreserve, hadBOM)
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditTool_BOMModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		bom     string
+		initial string
+		want    string
+	}{
+		{"default preserves a BOM", "", utf8BOM + "hello world\n", utf8BOM + "hello there\n"},
+		{"default preserves no BOM", "", "hello world\n", "hello there\n"},
+		{"preserve", "preserve", utf8BOM + "hello world\n", utf8BOM + "hello there\n"},
+		{"add", "add", "hello world\n", utf8BOM + "hello there\n"},
+		{"add keeps a single BOM", "add", utf8BOM + "hello world\n", utf8BOM + "hello there\n"},
+		{"strip", "strip", utf8BOM + "hello world\n", "hello there\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, tmpPath, tool := setupEditTest(t)
+			writeAndTrack(t, tmpPath, tt.initial)
+
+			resp := runEdit(t, tool, ctx, EditParams{
+				FilePath:  tmpPath,
+				OldString: "world",
+				NewString: "there",
+				BOM:       tt.bom,
+			})
+			require.False(t, resp.IsError, resp.Content)
+
+			content, err := os.ReadFile(tmpPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+			files := tool.(*editTool).files.(*stubHistoryService)
+			assert.Equal(t, []string{tt.initial, tt.want}, files.versions, "history keeps the bytes on disk")
+		})
+	}
+}
+
+func TestEditTool_BOMMatchesAtFileStart(t *testing.T) {
+	ctx, tmpPath, tool := setupEditTest(t)
+	writeAndTrack(t, tmpPath, utf8BOM+"package main\n")
+
+	resp := runEdit(t, tool, ctx, EditParams{
+		FilePath:   tmpPath,
+		OldString:  "package main\n",
+		NewString:  "",
+		ReplaceAll: true,
+	})
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, utf8BOM, string(content))
+}
+
+func TestEditTool_BOMConfigDefault(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.Tools.BOM
+	t.Cleanup(func() { cfg.Tools.BOM = old })
+	cfg.Tools.BOM = config.BOMStrip
+
+	ctx, tmpPath, tool := setupEditTest(t)
+	writeAndTrack(t, tmpPath, utf8BOM+"hello world\n")
+	resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "world", NewString: "there"})
+	require.False(t, resp.IsError, resp.Content)
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "hello there\n", string(content))
+
+	// A call's own mode overrides the setting
+	writeAndTrack(t, tmpPath, utf8BOM+"hello world\n")
+	resp = runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "world", NewString: "there", BOM: "preserve"})
+	require.False(t, resp.IsError, resp.Content)
+	content, err = os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, utf8BOM+"hello there\n", string(content))
+}
+
+func TestEditTool_InvalidBOM(t *testing.T) {
+	ctx, tmpPath, tool := setupEditTest(t)
+	writeAndTrack(t, tmpPath, "hello world\n")
+
+	resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "world", NewString: "there", BOM: "keep"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, `invalid bom "keep"`)
+
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", string(content))
+}
+
+func TestEditTool_CreateFileWithoutBOM(t *testing.T) {
+	ctx, _, tool := setupEditTest(t)
+	dir := t.TempDir()
+
+	resp := runEdit(t, tool, ctx, EditParams{FilePath: filepath.Join(dir, "new.txt"), NewString: "content\n"})
+	require.False(t, resp.IsError, resp.Content)
+	content, err := os.ReadFile(filepath.Join(dir, "new.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "content\n", string(content))
+
+	resp = runEdit(t, tool, ctx, EditParams{FilePath: filepath.Join(dir, "bom.txt"), NewString: "content\n", BOM: "add"})
+	require.False(t, resp.IsError, resp.Content)
+	content, err = os.ReadFile(filepath.Join(dir, "bom.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, utf8BOM+"content\n", string(content))
+}
+
+func TestWriteTool_BOMModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		bom     string
+		content string
+		want    string
+	}{
+		{"default preserves a BOM", "", "replaced\n", utf8BOM + "replaced\n"},
+		{"content with its own BOM is not doubled", "", utf8BOM + "replaced\n", utf8BOM + "replaced\n"},
+		{"add", "add", "replaced\n", utf8BOM + "replaced\n"},
+		{"strip", "strip", "replaced\n", "replaced\n"},
+		{"strip removes a BOM in the content", "strip", utf8BOM + "replaced\n", "replaced\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, files, tool := setupWriteTest(t)
+			path := createTempFileInWorkingDir(t, "write_bom_*.txt")
+			writeAndTrack(t, path, utf8BOM+"original\n")
+
+			resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: tt.content, Overwrite: true, BOM: tt.bom})
+			require.False(t, resp.IsError, resp.Content)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+			assert.Equal(t, []string{utf8BOM + "original\n", tt.want}, files.versions, "history keeps the bytes on disk")
+		})
+	}
+}
+
+func TestWriteTool_NewFileHasNoBOM(t *testing.T) {
+	ctx, _, tool := setupWriteTest(t)
+	path := filepath.Join(createTempDirInWorkingDir(t, "write_bom_*"), "new.txt")
+
+	resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "hello\n"})
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+}
+
+func TestWriteTool_BOMOnlyChange(t *testing.T) {
+	ctx, _, tool := setupWriteTest(t)
+	path := createTempFileInWorkingDir(t, "write_bom_*.txt")
+	writeAndTrack(t, path, utf8BOM+"same\n")
+
+	resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "same\n", Overwrite: true})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "already contains the exact content")
+
+	resp = runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "same\n", Overwrite: true, BOM: "strip"})
+	require.False(t, resp.IsError, resp.Content)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "same\n", string(content))
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+)
+
+type callDirectoryContextKey string
+
+// CallDirectoryContextKey holds the absolute directory a tool call set with
+// its cwd parameter.
+const CallDirectoryContextKey callDirectoryContextKey = "call_directory"
+
+// callDirectoryTool adds an optional cwd parameter to a path-taking tool.
+// Relative paths of the call are resolved against cwd, which must be inside
+// the working directory, and shell commands run there.
+type callDirectoryTool struct {
+	BaseTool
+}
+
+// WithCallDirectory wraps tool so its calls accept a cwd parameter.
+func WithCallDirectory(tool BaseTool) BaseTool {
+	return &callDirectoryTool{BaseTool: tool}
+}
+
+func (c *callDirectoryTool) Info() ToolInfo {
+	info := c.BaseTool.Info()
+	params := maps.Clone(info.Parameters)
+	if params == nil {
+		params = map[string]any{}
+	}
+	params["cwd"] = map[string]any{
+		"type":        "string",
+		"description": "Optional directory, relative to the working directory, that relative paths of this call are resolved against",
+	}
+	info.Parameters = params
+	return info
+}
+
+func (c *callDirectoryTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params struct {
+		Cwd string `json:"cwd"`
+	}
+	// Input the tool cannot parse is reported by the tool itself
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil || params.Cwd == "" {
+		return c.BaseTool.Run(ctx, call)
+	}
+
+	dir, err := ValidatePathInWorkingDirectory(params.Cwd)
+	if err != nil {
+		return NewPathErrorResponse(fmt.Errorf("invalid cwd: %s is outside the working directory (%w)", params.Cwd, ErrOutsideWorkingDirectory), params.Cwd), nil
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewNotFoundResponse("cwd does not exist: "+dir, dir), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("failed to access cwd: %w", err)
+	}
+	if !info.IsDir() {
+		return NewTextErrorResponse("cwd is not a directory: " + dir), nil
+	}
+	return c.BaseTool.Run(context.WithValue(ctx, CallDirectoryContextKey, dir), call)
+}
+
+// callDirectory returns the directory relative paths of the call are
+// resolved against: its cwd if it set one, otherwise the working directory.
+func callDirectory(ctx context.Context) string {
+	if dir, ok := ctx.Value(CallDirectoryContextKey).(string); ok {
+		return dir
+	}
+	return config.WorkingDirectory()
+}
+
+// resolveCallPath joins a relative path to the cwd of the call, and returns
+// the cwd itself for an empty path. Absolute paths and paths of calls without
+// a cwd are returned unchanged.
+func resolveCallPath(ctx context.Context, path string) string {
+	dir, ok := ctx.Value(CallDirectoryContextKey).(string)
+	switch {
+	case !ok || filepath.IsAbs(path):
+		return path
+	case path == "":
+		return dir
+	}
+	return filepath.Join(dir, fileutil.ToNativePath(path))
+}
@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCallDirectory(t *testing.T) {
+	runEditIn := func(t *testing.T, cwd, filePath string) ToolResponse {
+		t.Helper()
+		ctx, _, editTool := setupEditTest(t)
+		input, err := json.Marshal(map[string]any{
+			"cwd":        cwd,
+			"file_path":  filePath,
+			"old_string": "answer = 1",
+			"new_string": "answer = 42",
+		})
+		require.NoError(t, err)
+		resp, err := WithCallDirectory(editTool).Run(ctx, ToolCall{Name: EditToolName, Input: string(input)})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("relative paths resolve against cwd", func(t *testing.T) {
+		dir := createTempDirInWorkingDir(t, "cwd-*")
+		sub := filepath.Join(dir, "sub")
+		require.NoError(t, os.Mkdir(sub, 0o755))
+		target := filepath.Join(sub, "file.go")
+		writeAndTrack(t, target, "package sub\n\nvar answer = 1\n")
+		decoy := filepath.Join(dir, "file.go")
+		writeAndTrack(t, decoy, "package cwd\n\nvar answer = 1\n")
+
+		rel, err := filepath.Rel(config.WorkingDirectory(), sub)
+		require.NoError(t, err)
+		resp := runEditIn(t, rel, "file.go")
+		require.False(t, resp.IsError, resp.Content)
+
+		content, err := os.ReadFile(target)
+		require.NoError(t, err)
+		assert.Equal(t, "package sub\n\nvar answer = 42\n", string(content))
+		content, err = os.ReadFile(decoy)
+		require.NoError(t, err)
+		assert.Equal(t, "package cwd\n\nvar answer = 1\n", string(content), "only the file under cwd is edited")
+	})
+
+	t.Run("cwd outside the working directory", func(t *testing.T) {
+		for _, cwd := range []string{"../..", t.TempDir()} {
+			resp := runEditIn(t, cwd, "file.go")
+			assert.True(t, resp.IsError)
+			assert.Contains(t, resp.Content, "outside the working directory")
+			require.NotNil(t, resp.ErrorDetail)
+			assert.Equal(t, ErrorCodeOutsideWorkdir, resp.ErrorDetail.Code)
+		}
+	})
+
+	t.Run("cwd that is not a directory", func(t *testing.T) {
+		path := writeWorkingDirFile(t, "cwd-*.txt", []byte("x"))
+		resp := runEditIn(t, filepath.Base(path), "file.go")
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "not a directory")
+	})
+
+	t.Run("cwd parameter is advertised", func(t *testing.T) {
+		_, _, editTool := setupEditTest(t)
+		info := WithCallDirectory(editTool).Info()
+		assert.Contains(t, info.Parameters, "cwd")
+		assert.Contains(t, info.Parameters, "file_path")
+		assert.NotContains(t, editTool.Info().Parameters, "cwd", "the wrapped tool's parameters are not modified")
+	})
+}
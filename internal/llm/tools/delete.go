@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"unicode/utf8"
 
 	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
@@ -30,12 +33,29 @@ type DeleteResponseMetadata struct {
 	FilesDeleted int    `json:"files_deleted"`
 }
 
+// deletedFile is a file removed by a directory deletion.
+type deletedFile struct {
+	path    string
+	content string
+	diff    string
+}
+
 type deleteTool struct {
 	permissions permission.Service
 	files       history.Service
 	registry    agentregistry.Registry
 }
 
+const (
+	// DefaultDeleteMaxPreviewBytes bounds the preview of removed content
+	// when tools.delete.maxPreviewBytes is not set.
+	DefaultDeleteMaxPreviewBytes = 32 * 1024
+
+	// minDeletePreviewFileBytes is the smallest share of the preview a file
+	// of a deleted directory gets.
+	minDeletePreviewFileBytes = 1024
+)
+
 const (
 	DeleteToolName    = "delete"
 	deleteDescription = `File and directory deletion tool that removes files or directories from the filesystem while tracking changes in file history.
@@ -53,7 +73,7 @@ HOW TO USE:
 FEATURES:
 - Supports both files and directories (directories are deleted recursively)
 - Tracks all deletions in file history for visibility in the sidebar
-- Generates diffs showing what was removed
+- Generates diffs showing what was removed, bounded by tools.delete.maxPreviewBytes
 - Requires permission for deletions
 
 LIMITATIONS:
@@ -87,32 +107,46 @@ func (d *deleteTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"path"},
+		Examples: []ToolExample{
+			{
+				Description: "Delete a file",
+				Input:       `{"path": "/project/old_handler.go"}`,
+			},
+			{
+				Description: "Delete a directory and its contents",
+				Input:       `{"path": "/project/legacy"}`,
+			},
+		},
 	}
 }
 
 func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params DeleteParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.Path == "" {
-		return NewTextErrorResponse("path is required"), nil
+		return NewMissingParamResponse("path", "path is required"), nil
 	}
 
-	absPath, err := ValidatePathInWorkingDirectory(params.Path)
+	absPath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.Path))
 	if err != nil {
-		return NewTextErrorResponse(err.Error()), nil
+		return NewPathErrorResponse(err, params.Path), nil
 	}
 
 	fileInfo, err := os.Lstat(absPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewTextErrorResponse("file or directory does not exist: " + absPath), nil
+			return NewNotFoundResponse("file or directory does not exist: "+absPath, absPath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("error checking path: %w", err)
 	}
 
+	if response, err := checkProtectedFile(ctx, d.permissions, DeleteToolName, absPath); err != nil || response.IsError {
+		return response, err
+	}
+
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
 		return NewEmptyResponse(), errors.New("session_id and message_id are required")
@@ -125,8 +159,9 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 		}
 
 		diffStr, _, removals := diff.GenerateDiff(string(content), "", absPath)
+		diffStr = truncateDeletePreview(diffStr, deleteMaxPreviewBytes())
 
-		action := d.registry.EvaluatePermission(string(GetAgentID(ctx)), DeleteToolName, absPath)
+		action := evaluateFilePermission(ctx, d.registry, DeleteToolName, absPath)
 		switch action {
 		case permission.ActionAllow:
 		case permission.ActionDeny:
@@ -186,11 +221,7 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 		), nil
 	}
 
-	type fileEntry struct {
-		path    string
-		content string
-	}
-	var files []fileEntry
+	var files []deletedFile
 	totalRemovals := 0
 
 	err = filepath.WalkDir(absPath, func(path string, d os.DirEntry, err error) error {
@@ -213,21 +244,24 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 			return err
 		}
 
-		files = append(files, fileEntry{
+		diffStr, _, removals := diff.GenerateDiff(string(content), "", path)
+		totalRemovals += removals
+
+		files = append(files, deletedFile{
 			path:    path,
 			content: string(content),
+			diff:    diffStr,
 		})
 
-		_, _, removals := diff.GenerateDiff(string(content), "", path)
-		totalRemovals += removals
-
 		return nil
 	})
 	if err != nil {
 		return NewTextErrorResponse("directory contains more than 500 files. Use bash rm -rf for large directory deletions, or delete subdirectories individually"), err
 	}
 
-	action := d.registry.EvaluatePermission(GetAgentID(ctx), DeleteToolName, absPath)
+	preview := directoryDeletePreview(files, deleteMaxPreviewBytes())
+
+	action := evaluateFilePermission(ctx, d.registry, DeleteToolName, absPath)
 	switch action {
 	case permission.ActionAllow:
 	case permission.ActionDeny:
@@ -242,7 +276,7 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 				Description: fmt.Sprintf("Delete directory %s (%d files)", absPath, len(files)),
 				Params: DeletePermissionsParams{
 					Path: absPath,
-					Diff: fmt.Sprintf("Deleting %d files", len(files)),
+					Diff: preview,
 				},
 			},
 		)
@@ -282,9 +316,56 @@ func (d *deleteTool) Run(ctx context.Context, call ToolCall) (ToolResponse, erro
 	result := fmt.Sprintf("<result>\nDirectory successfully deleted: %s (%d files removed)\n</result>", absPath, len(files))
 	return WithResponseMetadata(NewTextResponse(result),
 		DeleteResponseMetadata{
-			Diff:         fmt.Sprintf("Deleted %d files", len(files)),
+			Diff:         preview,
 			Removals:     totalRemovals,
 			FilesDeleted: len(files),
 		},
 	), nil
 }
+
+func deleteMaxPreviewBytes() int {
+	if cfg := config.Get(); cfg != nil && cfg.Tools.Delete.MaxPreviewBytes > 0 {
+		return cfg.Tools.Delete.MaxPreviewBytes
+	}
+	return DefaultDeleteMaxPreviewBytes
+}
+
+// directoryDeletePreview joins the diffs of the files of a deleted directory
+// into at most limit bytes. Each file gets an equal share of the limit, but
+// at least minDeletePreviewFileBytes; files that no longer fit are counted
+// in a closing marker.
+func directoryDeletePreview(files []deletedFile, limit int) string {
+	if len(files) == 0 {
+		return "Deleted 0 files"
+	}
+	perFile := min(max(limit/len(files), minDeletePreviewFileBytes), limit)
+
+	var sb strings.Builder
+	for i, f := range files {
+		preview := truncateDeletePreview(f.diff, perFile)
+		if sb.Len()+len(preview) > limit {
+			fmt.Fprintf(&sb, "(%d more files omitted)\n", len(files)-i)
+			break
+		}
+		sb.WriteString(preview)
+	}
+	return sb.String()
+}
+
+// truncateDeletePreview cuts preview to at most limit bytes, at a line
+// boundary where possible, and notes how many bytes were dropped.
+func truncateDeletePreview(preview string, limit int) string {
+	if len(preview) <= limit {
+		return preview
+	}
+	cut := strings.LastIndex(preview[:limit], "\n") + 1
+	separator := ""
+	if cut == 0 {
+		cut = limit
+		for cut > 0 && !utf8.RuneStart(preview[cut]) {
+			cut--
+		}
+		separator = "\n"
+	}
+	return fmt.Sprintf("%s%s(%d more bytes omitted)\n", preview[:cut], separator, len(preview)-cut)
+}
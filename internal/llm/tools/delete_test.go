@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
@@ -172,6 +173,7 @@ func TestDeleteTool_ErrorCases(t *testing.T) {
 		name             string
 		params           DeleteParams
 		expectedError    string
+		expectedCode     ToolErrorCode
 		prepare          func(t *testing.T) string // returns path for verification
 		verifyNotDeleted func(t *testing.T, path string)
 	}{
@@ -179,11 +181,13 @@ func TestDeleteTool_ErrorCases(t *testing.T) {
 			name:          "empty path",
 			params:        DeleteParams{Path: ""},
 			expectedError: "path is required",
+			expectedCode:  ErrorCodeMissingParam,
 		},
 		{
 			name:          "non-existent path",
 			params:        DeleteParams{Path: filepath.Join(workingDir, "nonexistent_file_12345.txt")},
 			expectedError: "does not exist",
+			expectedCode:  ErrorCodeNotFound,
 		},
 		{
 			name:   "path outside working directory",
@@ -197,6 +201,7 @@ func TestDeleteTool_ErrorCases(t *testing.T) {
 				return tmpPath
 			},
 			expectedError: "outside the working directory",
+			expectedCode:  ErrorCodeOutsideWorkdir,
 			verifyNotDeleted: func(t *testing.T, path string) {
 				_, err := os.Stat(path)
 				assert.False(t, os.IsNotExist(err), "File should NOT be deleted")
@@ -206,6 +211,7 @@ func TestDeleteTool_ErrorCases(t *testing.T) {
 			name:          "invalid JSON",
 			params:        DeleteParams{Path: "test.txt"},
 			expectedError: "error parsing parameters",
+			expectedCode:  ErrorCodeParseError,
 			// Override to use raw input
 			prepare: func(t *testing.T) string { return "" },
 		},
@@ -230,6 +236,8 @@ func TestDeleteTool_ErrorCases(t *testing.T) {
 			}
 
 			assertDeleteError(t, resp, tt.expectedError)
+			require.NotNil(t, resp.ErrorDetail)
+			assert.Equal(t, tt.expectedCode, resp.ErrorDetail.Code)
 		})
 	}
 }
@@ -351,3 +359,57 @@ func TestDeleteTool_EmptyDirectory(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, metadata.FilesDeleted)
 }
+
+func TestDeleteTool_PreviewIsBounded(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.Tools.Delete.MaxPreviewBytes
+	cfg.Tools.Delete.MaxPreviewBytes = 4096
+	t.Cleanup(func() { cfg.Tools.Delete.MaxPreviewBytes = old })
+
+	ctx, tool, ctrl := setupDeleteTest(t)
+	defer ctrl.Finish()
+
+	tmpDir := createTempDirInWorkingDir(t, "delete_preview_test_*")
+	large := strings.Repeat("a line of content that will be removed\n", 1000)
+	for i := range 8 {
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("file%d.txt", i)), []byte(large), 0o644))
+	}
+
+	resp := runDelete(t, tool, ctx, DeleteParams{Path: tmpDir})
+	assertDeleteSuccess(t, resp, tmpDir, 8)
+	assert.NoDirExists(t, tmpDir, "the deletion itself is not bounded")
+
+	var metadata DeleteResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &metadata))
+	assert.Equal(t, 8000, metadata.Removals)
+	assert.LessOrEqual(t, len(metadata.Diff), 4096+100, "preview should be capped near maxPreviewBytes")
+	assert.Contains(t, metadata.Diff, "-a line of content that will be removed")
+	assert.Contains(t, metadata.Diff, "more bytes omitted)")
+	assert.Contains(t, metadata.Diff, "more files omitted)")
+}
+
+func TestDeleteTool_SingleFilePreviewIsBounded(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.Tools.Delete.MaxPreviewBytes
+	cfg.Tools.Delete.MaxPreviewBytes = 2048
+	t.Cleanup(func() { cfg.Tools.Delete.MaxPreviewBytes = old })
+
+	ctx, tool, ctrl := setupDeleteTest(t)
+	defer ctrl.Finish()
+
+	path := writeWorkingDirFile(t, "delete_preview_*.txt", []byte(strings.Repeat("x\n", 10000)))
+	resp := runDelete(t, tool, ctx, DeleteParams{Path: path})
+	assertDeleteSuccess(t, resp, path, 1)
+
+	var metadata DeleteResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &metadata))
+	assert.Equal(t, 10000, metadata.Removals)
+	assert.LessOrEqual(t, len(metadata.Diff), 2048+50)
+	assert.Regexp(t, `\(\d+ more bytes omitted\)\n$`, metadata.Diff)
+}
+
+func TestTruncateDeletePreview(t *testing.T) {
+	assert.Equal(t, "short\n", truncateDeletePreview("short\n", 100))
+	assert.Equal(t, "one\n(9 more bytes omitted)\n", truncateDeletePreview("one\ntwo\nthree", 7))
+	assert.Equal(t, "abcd\n(6 more bytes omitted)\n", truncateDeletePreview("abcdefghij", 4), "a single long line is cut mid-line")
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+)
+
+type DiagnosticsParams struct {
+	FilePath string `json:"file_path"`
+	Timeout  int    `json:"timeout,omitempty"`
+}
+
+type DiagnosticsResponseMetadata struct {
+	FilePath string `json:"file_path"`
+	Clean    bool   `json:"clean"`
+}
+
+type diagnosticsTool struct {
+	lsp lsp.LspService
+}
+
+const (
+	DiagnosticsToolName       = "diagnostics"
+	defaultDiagnosticsTimeout = 5
+	maxDiagnosticsTimeout     = 30
+	diagnosticsDescription    = `Reports compile errors and warnings for a file from the configured LSP servers.
+
+WHEN TO USE THIS TOOL:
+- After editing a file, to check whether the change introduced errors
+- Before finishing a task, to confirm the touched files are clean
+
+HOW TO USE:
+- Provide the path of the file to check
+- Optionally set a timeout (in seconds) to wait for the language server to publish diagnostics
+
+FEATURES:
+- Opens or refreshes the file in every LSP server that handles it
+- Waits for diagnostics to settle, then lists errors and warnings for the file and the project
+- Reports "no issues" when the file is clean
+
+LIMITATIONS:
+- Only works for file types handled by a configured LSP server; otherwise it reports that diagnostics are unavailable
+- Language servers may take longer than the timeout on first start, in which case results can be incomplete
+`
+)
+
+func NewDiagnosticsTool(lspService lsp.LspService) BaseTool {
+	return &diagnosticsTool{lspService}
+}
+
+func (t *diagnosticsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        DiagnosticsToolName,
+		ReadOnly:    true,
+		Description: diagnosticsDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file to check",
+			},
+			"timeout": map[string]any{
+				"type":        "number",
+				"description": fmt.Sprintf("Seconds to wait for diagnostics (default %d, max %d)", defaultDiagnosticsTimeout, maxDiagnosticsTimeout),
+			},
+		},
+		Required: []string{"file_path"},
+	}
+}
+
+func (t *diagnosticsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params DiagnosticsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+	}
+
+	if params.FilePath == "" {
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
+	}
+
+	file, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
+	if err != nil {
+		return NewPathErrorResponse(err, params.FilePath), nil
+	}
+
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return NewNotFoundResponse("file not found: "+file, file), nil
+	}
+
+	relPath, _ := filepath.Rel(config.WorkingDirectory(), file)
+
+	if len(t.lsp.ClientsForFile(file)) == 0 {
+		return NewTextResponse(fmt.Sprintf("No LSP server handles %s, diagnostics are unavailable for this file type.", relPath)), nil
+	}
+
+	timeout := params.Timeout
+	if timeout <= 0 {
+		timeout = defaultDiagnosticsTimeout
+	}
+	timeout = min(timeout, maxDiagnosticsTimeout)
+
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+	if err := t.lsp.WaitForDiagnostics(waitCtx, file); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return ToolResponse{}, err
+	}
+
+	output := t.lsp.FormatDiagnostics(file)
+	clean := strings.TrimSpace(output) == ""
+	if clean {
+		output = fmt.Sprintf("No issues found in %s.", relPath)
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(output),
+		DiagnosticsResponseMetadata{
+			FilePath: file,
+			Clean:    clean,
+		},
+	), nil
+}
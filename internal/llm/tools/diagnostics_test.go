@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	mock_lsp "github.com/MerrukTechnology/OpenCode-Native/internal/lsp/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func runDiagnostics(t *testing.T, tool BaseTool, params DiagnosticsParams) ToolResponse {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := tool.Run(t.Context(), ToolCall{Name: DiagnosticsToolName, Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestDiagnosticsTool_ReportsDiagnostics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	file := createTempFileInWorkingDir(t, "diag-*.go")
+
+	diagnostics := "\n<file_diagnostics>\nError: " + file + ":3:2 [compiler] undefined: foo\n</file_diagnostics>\n"
+	svc := mock_lsp.NewMockLspService(ctrl)
+	svc.EXPECT().ClientsForFile(file).Return([]*lsp.Client{{}})
+	svc.EXPECT().WaitForDiagnostics(gomock.Any(), file).Return(nil)
+	svc.EXPECT().FormatDiagnostics(file).Return(diagnostics)
+
+	resp := runDiagnostics(t, NewDiagnosticsTool(svc), DiagnosticsParams{FilePath: file})
+	assert.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "undefined: foo")
+
+	var meta DiagnosticsResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.False(t, meta.Clean)
+}
+
+func TestDiagnosticsTool_Clean(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	file := createTempFileInWorkingDir(t, "diag-*.go")
+
+	svc := mock_lsp.NewMockLspService(ctrl)
+	svc.EXPECT().ClientsForFile(file).Return([]*lsp.Client{{}})
+	svc.EXPECT().WaitForDiagnostics(gomock.Any(), file).Return(nil)
+	svc.EXPECT().FormatDiagnostics(file).Return("")
+
+	resp := runDiagnostics(t, NewDiagnosticsTool(svc), DiagnosticsParams{FilePath: file, Timeout: 1})
+	assert.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "No issues found")
+
+	var meta DiagnosticsResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.True(t, meta.Clean)
+}
+
+func TestDiagnosticsTool_NoClientForExtension(t *testing.T) {
+	file := createTempFileInWorkingDir(t, "diag-*.unknown")
+
+	resp := runDiagnostics(t, NewDiagnosticsTool(&noopLspService{}), DiagnosticsParams{FilePath: file})
+	assert.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "diagnostics are unavailable")
+}
+
+func TestDiagnosticsTool_FileNotFound(t *testing.T) {
+	resp := runDiagnostics(t, NewDiagnosticsTool(&noopLspService{}), DiagnosticsParams{FilePath: "missing_diagnostics_file.go"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "file not found")
+}
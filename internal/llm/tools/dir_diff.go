@@ -0,0 +1,265 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+type DirDiffParams struct {
+	Left      string `json:"left"`
+	Right     string `json:"right"`
+	Glob      string `json:"glob,omitempty"`
+	ShowHunks bool   `json:"show_hunks,omitempty"`
+}
+
+// DirDiffChange is a file present in both directories whose contents differ.
+type DirDiffChange struct {
+	Path      string `json:"path"`
+	Additions int    `json:"additions"`
+	Removals  int    `json:"removals"`
+	Binary    bool   `json:"binary,omitempty"`
+}
+
+// DirDiffResponseMetadata categorizes the files of two directories by their
+// path relative to each directory.
+type DirDiffResponseMetadata struct {
+	Added     []string        `json:"added"`
+	Removed   []string        `json:"removed"`
+	Changed   []DirDiffChange `json:"changed"`
+	Unchanged int             `json:"unchanged"`
+}
+
+type dirDiffTool struct{}
+
+const (
+	DirDiffToolName    = "dir_diff"
+	dirDiffDescription = `Compares two directory trees file by file and reports which files were added, removed or changed.
+
+WHEN TO USE THIS TOOL:
+- To verify generated output against an expected fixture directory
+- To see what differs between two copies of a project or package
+
+HOW TO USE:
+- Provide the left (expected or original) and right (actual or new) directories
+- Optionally provide a glob to only compare matching files (e.g. "**/*.go")
+- Set show_hunks to include the unified diff of each changed file
+
+FEATURES:
+- Files are paired by their path relative to each directory
+- Added files exist only on the right, removed files only on the left
+- Changed files are listed with their added and removed line counts
+- Hidden files and ignored directories such as node_modules are skipped
+
+LIMITATIONS:
+- Both directories must be inside the working directory
+- Binary files and environment files (.env) are reported as changed without a diff
+- Symlinks are not followed
+
+TIPS:
+- Start with the summary, then rerun with show_hunks or read individual files to inspect the changes`
+)
+
+func NewDirDiffTool() BaseTool {
+	return &dirDiffTool{}
+}
+
+func (d *dirDiffTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        DirDiffToolName,
+		ReadOnly:    true,
+		Description: dirDiffDescription,
+		Parameters: map[string]any{
+			"left": map[string]any{
+				"type":        "string",
+				"description": "The original or expected directory",
+			},
+			"right": map[string]any{
+				"type":        "string",
+				"description": "The new or actual directory",
+			},
+			"glob": map[string]any{
+				"type":        "string",
+				"description": "Only compare files whose relative path matches this pattern (e.g. \"**/*.go\")",
+			},
+			"show_hunks": map[string]any{
+				"type":        "boolean",
+				"description": "Include the unified diff of each changed file",
+			},
+		},
+		Required: []string{"left", "right"},
+	}
+}
+
+func (d *dirDiffTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params DirDiffParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+	}
+	if params.Left == "" {
+		return NewMissingParamResponse("left", "left is required"), nil
+	}
+	if params.Right == "" {
+		return NewMissingParamResponse("right", "right is required"), nil
+	}
+	if err := fileutil.ValidateGlob(params.Glob); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	left, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.Left))
+	if err != nil {
+		return NewPathErrorResponse(err, params.Left), nil
+	}
+	right, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.Right))
+	if err != nil {
+		return NewPathErrorResponse(err, params.Right), nil
+	}
+	for _, dir := range []string{left, right} {
+		if !fileutil.DirExists(dir) {
+			return NewTextErrorResponse(fmt.Sprintf("not a directory: %s", dir)), nil
+		}
+	}
+
+	leftFiles, err := listDirDiffFiles(ctx, left, params.Glob)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	rightFiles, err := listDirDiffFiles(ctx, right, params.Glob)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+
+	meta := DirDiffResponseMetadata{Added: []string{}, Removed: []string{}, Changed: []DirDiffChange{}}
+	var hunks strings.Builder
+	for _, rel := range rightFiles {
+		if _, found := slices.BinarySearch(leftFiles, rel); !found {
+			meta.Added = append(meta.Added, rel)
+		}
+	}
+	for _, rel := range leftFiles {
+		if _, found := slices.BinarySearch(rightFiles, rel); !found {
+			meta.Removed = append(meta.Removed, rel)
+			continue
+		}
+		if ctx.Err() != nil {
+			return ToolResponse{}, ctx.Err()
+		}
+		leftPath, rightPath := filepath.Join(left, rel), filepath.Join(right, rel)
+		before, err := os.ReadFile(leftPath)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error reading %s: %s", leftPath, err)), nil
+		}
+		after, err := os.ReadFile(rightPath)
+		if err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("error reading %s: %s", rightPath, err)), nil
+		}
+		if bytes.Equal(before, after) {
+			meta.Unchanged++
+			continue
+		}
+
+		change := DirDiffChange{Path: rel}
+		if isEnvFile(rel) {
+			meta.Changed = append(meta.Changed, change)
+			continue
+		}
+		if binary, _ := isBinaryFile(leftPath); binary {
+			change.Binary = true
+		} else if binary, _ := isBinaryFile(rightPath); binary {
+			change.Binary = true
+		}
+		if change.Binary {
+			meta.Changed = append(meta.Changed, change)
+			continue
+		}
+		var unified string
+		unified, change.Additions, change.Removals = diff.GenerateDiff(string(before), string(after), rel)
+		meta.Changed = append(meta.Changed, change)
+		if params.ShowHunks {
+			fmt.Fprintf(&hunks, "\n=== %s ===\n%s", rel, unified)
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Compared %s with %s: %d added, %d removed, %d changed, %d unchanged\n",
+		left, right, len(meta.Added), len(meta.Removed), len(meta.Changed), meta.Unchanged)
+	writeDirDiffSection(&sb, "Added", meta.Added)
+	writeDirDiffSection(&sb, "Removed", meta.Removed)
+	if len(meta.Changed) > 0 {
+		sb.WriteString("\nChanged:\n")
+		for _, change := range meta.Changed {
+			switch {
+			case change.Binary:
+				fmt.Fprintf(&sb, "  %s (binary)\n", change.Path)
+			case isEnvFile(change.Path):
+				fmt.Fprintf(&sb, "  %s (environment file, diff hidden)\n", change.Path)
+			default:
+				fmt.Fprintf(&sb, "  %s (+%d -%d)\n", change.Path, change.Additions, change.Removals)
+			}
+		}
+	}
+	sb.WriteString(hunks.String())
+
+	return WithResponseMetadata(NewTextResponse(sb.String()), meta), nil
+}
+
+// listDirDiffFiles returns the sorted slash-separated paths, relative to
+// root, of the regular files under root that match glob. Paths are checked
+// with fileutil.ShouldSkipPath relative to root, so ignored names in the
+// ancestors of root don't hide everything.
+func listDirDiffFiles(ctx context.Context, root, glob string) ([]string, error) {
+	var files []string
+	err := fileutil.Walk(root, false, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil || rel == "." {
+			return nil
+		}
+		if fileutil.ShouldSkipPath(rel, nil) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if glob != "" {
+			if matched, _ := doublestar.Match(glob, rel); !matched {
+				return nil
+			}
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error walking %s: %w", root, err)
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+func writeDirDiffSection(sb *strings.Builder, title string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n%s:\n", title)
+	for _, path := range paths {
+		fmt.Fprintf(sb, "  %s\n", path)
+	}
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDirDiffFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := createTempDirInWorkingDir(t, "dirdiff-")
+	for rel, content := range files {
+		path := filepath.Join(dir, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func runDirDiff(t *testing.T, params DirDiffParams) (ToolResponse, DirDiffResponseMetadata) {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := NewDirDiffTool().Run(t.Context(), ToolCall{Name: DirDiffToolName, Input: string(input)})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+
+	var meta DirDiffResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	return resp, meta
+}
+
+func TestDirDiffTool(t *testing.T) {
+	left := writeDirDiffFixture(t, map[string]string{
+		"same.txt":        "unchanged\n",
+		"removed.txt":     "gone\n",
+		"pkg/modified.go": "package pkg\n\nconst A = 1\n",
+		".git/HEAD":       "ref: refs/heads/main\n",
+	})
+	right := writeDirDiffFixture(t, map[string]string{
+		"same.txt":        "unchanged\n",
+		"pkg/added.go":    "package pkg\n",
+		"pkg/modified.go": "package pkg\n\nconst A = 2\n",
+		".git/HEAD":       "ref: refs/heads/other\n",
+	})
+
+	t.Run("summary", func(t *testing.T) {
+		resp, meta := runDirDiff(t, DirDiffParams{Left: left, Right: right})
+		assert.Equal(t, []string{"pkg/added.go"}, meta.Added)
+		assert.Equal(t, []string{"removed.txt"}, meta.Removed)
+		assert.Equal(t, []DirDiffChange{{Path: "pkg/modified.go", Additions: 1, Removals: 1}}, meta.Changed)
+		assert.Equal(t, 1, meta.Unchanged)
+		assert.Contains(t, resp.Content, "1 added, 1 removed, 1 changed, 1 unchanged")
+		assert.Contains(t, resp.Content, "pkg/modified.go (+1 -1)")
+		assert.NotContains(t, resp.Content, "const A", "hunks are only shown on request")
+		assert.NotContains(t, resp.Content, ".git", "hidden paths are skipped")
+	})
+
+	t.Run("show hunks", func(t *testing.T) {
+		resp, _ := runDirDiff(t, DirDiffParams{Left: left, Right: right, ShowHunks: true})
+		assert.Contains(t, resp.Content, "=== pkg/modified.go ===")
+		assert.Contains(t, resp.Content, "-const A = 1")
+		assert.Contains(t, resp.Content, "+const A = 2")
+	})
+
+	t.Run("glob", func(t *testing.T) {
+		_, meta := runDirDiff(t, DirDiffParams{Left: left, Right: right, Glob: "**/*.go"})
+		assert.Equal(t, []string{"pkg/added.go"}, meta.Added)
+		assert.Empty(t, meta.Removed)
+		assert.Len(t, meta.Changed, 1)
+		assert.Zero(t, meta.Unchanged)
+	})
+}
+
+func TestDirDiffTool_EnvFileHunksHidden(t *testing.T) {
+	left := writeDirDiffFixture(t, map[string]string{"prod.env": "API_TOKEN=old-secret\n"})
+	right := writeDirDiffFixture(t, map[string]string{"prod.env": "API_TOKEN=new-secret\n"})
+
+	resp, meta := runDirDiff(t, DirDiffParams{Left: left, Right: right, ShowHunks: true})
+	assert.Equal(t, []DirDiffChange{{Path: "prod.env"}}, meta.Changed)
+	assert.NotContains(t, resp.Content, "secret")
+}
@@ -25,6 +25,9 @@ type EditParams struct {
 	OldString  string `json:"old_string"`
 	NewString  string `json:"new_string"`
 	ReplaceAll bool   `json:"replace_all,omitempty"`
+	Reindent   bool   `json:"reindent,omitempty"`
+	Overwrite  bool   `json:"overwrite,omitempty"`
+	BOM        string `json:"bom,omitempty"`
 }
 
 type EditPermissionsParams struct {
@@ -61,9 +64,11 @@ To make a file edit, provide the following:
 2. old_string: The text to replace (must match the file contents exactly, including all whitespace and indentation)
 3. new_string: The edited text to replace the old_string
 4. replace_all: (optional) Replace all occurrences of old_string (default false)
+5. reindent: (optional) Re-indent new_string to match the indentation of the line where old_string starts, using the file's tabs/spaces style (default false)
+6. overwrite: (optional) When creating a file with an empty old_string, replace the file if it already exists (default false)
 
 Special cases:
-- To create a new file: provide file_path and new_string, leave old_string empty
+- To create a new file: provide file_path and new_string, leave old_string empty. This FAILS if the file exists unless overwrite is true; the previous content is kept in the file history
 - To delete content: provide file_path and old_string, leave new_string empty
 
 The edit will FAIL if old_string is not found in the file.
@@ -94,7 +99,7 @@ func NewEditTool(
 }
 
 func (e *editTool) Info() ToolInfo {
-	return ToolInfo{
+	return withPreconditions(ToolInfo{
 		Name:        EditToolName,
 		Description: editDescription,
 		Parameters: map[string]any{
@@ -114,31 +119,65 @@ func (e *editTool) Info() ToolInfo {
 				"type":        "boolean",
 				"description": "Replace all occurrences of old_string (default false)",
 			},
+			"reindent": map[string]any{
+				"type":        "boolean",
+				"description": "Re-indent new_string to match the indentation of the line where old_string starts (default false)",
+			},
+			"overwrite": map[string]any{
+				"type":        "boolean",
+				"description": "Replace an existing file when old_string is empty (default false)",
+			},
+			"bom": bomParameter,
 		},
 		Required: []string{"file_path", "old_string", "new_string"},
-	}
+		Examples: []ToolExample{
+			{
+				Description: "Replace a single unique occurrence",
+				Input:       `{"file_path": "/project/main.go", "old_string": "func greet() {\n\tfmt.Println(\"hi\")", "new_string": "func greet() {\n\tfmt.Println(\"hello\")"}`,
+			},
+			{
+				Description: "Rename an identifier everywhere in the file",
+				Input:       `{"file_path": "/project/main.go", "old_string": "oldName", "new_string": "newName", "replace_all": true}`,
+			},
+		},
+	}, ReadFilePrecondition)
 }
 
 func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params EditParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("invalid parameters"), nil
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
 	}
 
 	if params.FilePath == "" {
-		return NewTextErrorResponse("file_path is required"), nil
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
 	}
 
 	if !filepath.IsAbs(params.FilePath) {
-		wd := config.WorkingDirectory()
+		wd := callDirectory(ctx)
 		params.FilePath = fileutil.ResolvePath(params.FilePath, wd)
 	}
 
+	bom, err := resolveBOMMode(params.BOM)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
 	var response ToolResponse
-	var err error
+
+	if isEnvFile(params.FilePath) {
+		_, statErr := os.Stat(params.FilePath)
+		if params.NewString == "" || (params.OldString == "" && statErr == nil) {
+			return NewTextErrorResponse("edits to environment files may only add new keys; existing entries cannot be changed or removed"), nil
+		}
+	}
+
+	if response, err := checkProtectedFile(ctx, e.permissions, EditToolName, params.FilePath); err != nil || response.IsError {
+		return response, err
+	}
 
 	if params.OldString == "" {
-		response, err = e.createNewFile(ctx, params.FilePath, params.NewString)
+		response, err = e.createNewFile(ctx, params.FilePath, params.NewString, params.Overwrite, bom)
 		if err != nil {
 			return response, err
 		}
@@ -146,14 +185,14 @@ func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 
 	if params.NewString == "" {
-		response, err = e.deleteContent(ctx, params.FilePath, params.OldString, params.ReplaceAll)
+		response, err = e.deleteContent(ctx, params.FilePath, params.OldString, params.ReplaceAll, bom)
 		if err != nil {
 			return response, err
 		}
 		return response, nil
 	}
 
-	response, err = e.replaceContent(ctx, params.FilePath, params.OldString, params.NewString, params.ReplaceAll)
+	response, err = e.replaceContent(ctx, params.FilePath, params.OldString, params.NewString, params.ReplaceAll, params.Reindent, bom)
 	if err != nil {
 		return response, err
 	}
@@ -175,13 +214,36 @@ func (e *editTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	return response, nil
 }
 
-func (e *editTool) createNewFile(ctx context.Context, filePath, content string) (ToolResponse, error) {
+func (e *editTool) createNewFile(ctx context.Context, filePath, content string, overwrite bool, bom config.BOMMode) (ToolResponse, error) {
+	oldContent := ""
+	exists := false
+	hadBOM := false
+	content, _ = splitBOM(content)
 	fileInfo, err := fileutil.GetFileInfo(filePath)
 	if err == nil {
 		if fileInfo.IsDir() {
 			return NewTextErrorResponse("path is a directory, not a file: " + filePath), nil
 		}
-		return NewTextErrorResponse("file already exists: " + filePath), nil
+		if !overwrite {
+			return NewTextErrorResponse("file already exists: " + filePath + ". Set overwrite to true to replace it"), nil
+		}
+
+		lastRead := getLastReadTime(filePath)
+		if lastRead.IsZero() {
+			return NewTextErrorResponse("you must read the file before overwriting it. Use the Read tool first"), nil
+		}
+		if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+			return NewTextErrorResponse(
+				fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+					filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+				)), nil
+		}
+
+		if oldContent, err = fileutil.ReadFile(filePath); err != nil {
+			return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
+		}
+		oldContent, hadBOM = splitBOM(oldContent)
+		exists = true
 	} else if !os.IsNotExist(err) {
 		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
 	}
@@ -195,8 +257,13 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 		return NewEmptyResponse(), errors.New("session ID and message ID are required for creating a new file")
 	}
 
+	syntaxResponse, syntaxWarning := checkSyntax(filePath, oldContent, content)
+	if syntaxResponse.IsError {
+		return syntaxResponse, nil
+	}
+
 	diff, additions, removals := diff.GenerateDiff(
-		"",
+		oldContent,
 		content,
 		filePath,
 	)
@@ -205,8 +272,12 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
+	description := "Create file " + filePath
+	if exists {
+		description = "Overwrite file " + filePath
+	}
 
-	action := e.registry.EvaluatePermission(string(GetAgentID(ctx)), EditToolName, filePath)
+	action := evaluateFilePermission(ctx, e.registry, EditToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
 		// Allowed by config
@@ -219,7 +290,7 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 				Path:        permissionPath,
 				ToolName:    EditToolName,
 				Action:      "write",
-				Description: "Create file " + filePath,
+				Description: description,
 				Params: EditPermissionsParams{
 					FilePath: filePath,
 					Diff:     diff,
@@ -231,30 +302,26 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 		}
 	}
 
-	err = os.WriteFile(filePath, []byte(content), 0o644)
+	written := withBOM(content, bom, hadBOM)
+	err = writeFileAtomic(ctx, filePath, []byte(written))
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
 	}
 
-	// File can't be in the history so we create a new file history
-	_, err = e.files.Create(ctx, sessionID, filePath, "")
-	if err != nil {
-		// Log error but don't fail the operation
-		return NewEmptyResponse(), fmt.Errorf("error creating file history: %w", err)
-	}
-
-	// Add the new content to the file history
-	_, err = e.files.CreateVersion(ctx, sessionID, filePath, content)
-	if err != nil {
-		// Log error but don't fail the operation
-		logging.Debug("Error creating file history version", "error", err)
+	// Record the previous content (empty for a new file) so an overwrite can be undone
+	if err = recordOverwriteHistory(ctx, e.files, sessionID, filePath, restoreBOM(oldContent, hadBOM), written, exists); err != nil {
+		return NewEmptyResponse(), err
 	}
 
 	recordFileWrite(filePath)
 	recordFileRead(filePath)
 
+	result := "File created: " + filePath
+	if exists {
+		result = "File overwritten: " + filePath
+	}
 	return WithResponseMetadata(
-		NewTextResponse("File created: "+filePath),
+		NewTextResponse(result+syntaxWarning),
 		EditResponseMetadata{
 			Diff:      diff,
 			Additions: additions,
@@ -263,11 +330,11 @@ func (e *editTool) createNewFile(ctx context.Context, filePath, content string)
 	), nil
 }
 
-func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string, replaceAll bool) (ToolResponse, error) {
+func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string, replaceAll bool, bom config.BOMMode) (ToolResponse, error) {
 	fileInfo, err := fileutil.GetFileInfo(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewTextErrorResponse("file not found: " + filePath), nil
+			return NewNotFoundResponse("file not found: "+filePath, filePath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
 	}
@@ -293,6 +360,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
 	}
+	content, hadBOM := splitBOM(content)
 
 	oldContent := strings.ReplaceAll(content, "\r\n", "\n")
 	normalizedOldString := strings.ReplaceAll(oldString, "\r\n", "\n")
@@ -320,6 +388,11 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		return NewEmptyResponse(), errors.New("session ID and message ID are required for creating a new file")
 	}
 
+	syntaxResponse, syntaxWarning := checkSyntax(filePath, oldContent, newContent)
+	if syntaxResponse.IsError {
+		return syntaxResponse, nil
+	}
+
 	diff, additions, removals := diff.GenerateDiff(
 		oldContent,
 		newContent,
@@ -331,7 +404,7 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
-	action := e.registry.EvaluatePermission(GetAgentID(ctx), EditToolName, filePath)
+	action := evaluateFilePermission(ctx, e.registry, EditToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
 		// Allowed by config
@@ -356,22 +429,23 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 		}
 	}
 
-	err = os.WriteFile(filePath, []byte(newContent), 0o644)
+	err = writeEditedFile(ctx, filePath, []byte(withBOM(newContent, bom, hadBOM)))
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// Check if file exists in history
+	original := restoreBOM(oldContent, hadBOM)
 	file, err := e.files.GetByPathAndSession(ctx, filePath, sessionID)
 	if err != nil {
-		_, err = e.files.Create(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.Create(ctx, sessionID, filePath, original)
 		if err != nil {
 			// Log error but don't fail the operation
 			return NewEmptyResponse(), fmt.Errorf("error creating file history: %w", err)
 		}
-	} else if file.Content != oldContent {
+	} else if file.Content != original {
 		// User Manually changed the content store an intermediate version
-		_, err = e.files.CreateVersion(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.CreateVersion(ctx, sessionID, filePath, original)
 		if err != nil {
 			logging.Debug("Error creating file history version", "error", err)
 		}
@@ -385,8 +459,14 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	recordFileWrite(filePath)
 	recordFileRead(filePath)
 
+	var check editCheck
+	if !replaceAll {
+		check.oldString = normalizedOldString
+	}
+	warning := verifyEditWritten(filePath, newContent, check)
+
 	return WithResponseMetadata(
-		NewTextResponse("Content deleted from file: "+filePath),
+		NewTextResponse("Content deleted from file: "+filePath+warning+syntaxWarning),
 		EditResponseMetadata{
 			Diff:      diff,
 			Additions: additions,
@@ -395,11 +475,11 @@ func (e *editTool) deleteContent(ctx context.Context, filePath, oldString string
 	), nil
 }
 
-func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newString string, replaceAll bool) (ToolResponse, error) {
+func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newString string, replaceAll, reindent bool, bom config.BOMMode) (ToolResponse, error) {
 	fileInfo, err := fileutil.GetFileInfo(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewTextErrorResponse("file not found: " + filePath), nil
+			return NewNotFoundResponse("file not found: "+filePath, filePath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
 	}
@@ -425,39 +505,65 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
 	}
+	content, hadBOM := splitBOM(content)
 
 	oldContent := strings.ReplaceAll(content, "\r\n", "\n")
 	normalizedOldString := strings.ReplaceAll(oldString, "\r\n", "\n")
 	normalizedNewString := strings.ReplaceAll(newString, "\r\n", "\n")
 
-	index := strings.Index(oldContent, normalizedOldString)
+	// Environment files are read with redacted values, so the edit applies to
+	// that view and is merged back into the real content afterwards.
+	envFile := isEnvFile(filePath)
+	viewContent := oldContent
+	if envFile {
+		viewContent = redactEnvContent(oldContent)
+	}
+
+	index := strings.Index(viewContent, normalizedOldString)
 	if index == -1 {
 		return NewTextErrorResponse("old_string not found in file. Make sure it matches exactly, including whitespace and line breaks"), nil
 	}
 
-	var newContent string
-	if replaceAll {
-		newContent = strings.ReplaceAll(oldContent, normalizedOldString, normalizedNewString)
-	} else {
-		lastIndex := strings.LastIndex(oldContent, normalizedOldString)
+	if !replaceAll {
+		lastIndex := strings.LastIndex(viewContent, normalizedOldString)
 		if index != lastIndex {
-			count := strings.Count(oldContent, normalizedOldString)
+			count := strings.Count(viewContent, normalizedOldString)
 			return NewTextErrorResponse(fmt.Sprintf("old_string appears %d times in the file. Please provide more surrounding context lines in old_string to make the match unique, or use replace_all=true to replace all occurrences", count)), nil
 		}
-		newContent = oldContent[:index] + normalizedNewString + oldContent[index+len(normalizedOldString):]
 	}
 
-	if oldContent == newContent {
+	var newViewContent string
+	switch {
+	case reindent:
+		newViewContent = reindentReplace(viewContent, normalizedOldString, normalizedNewString, replaceAll)
+	case replaceAll:
+		newViewContent = strings.ReplaceAll(viewContent, normalizedOldString, normalizedNewString)
+	default:
+		newViewContent = viewContent[:index] + normalizedNewString + viewContent[index+len(normalizedOldString):]
+	}
+
+	if viewContent == newViewContent {
 		return NewTextErrorResponse("new content is the same as old content. No changes made."), nil
 	}
+
+	newContent := newViewContent
+	if envFile {
+		if newContent, err = mergeEnvAdditions(oldContent, newViewContent); err != nil {
+			return NewTextErrorResponse(err.Error()), nil
+		}
+	}
 	sessionID, messageID := GetContextValues(ctx)
 
 	if sessionID == "" || messageID == "" {
 		return NewEmptyResponse(), errors.New("session ID and message ID are required for creating a new file")
 	}
+	syntaxResponse, syntaxWarning := checkSyntax(filePath, oldContent, newContent)
+	if syntaxResponse.IsError {
+		return syntaxResponse, nil
+	}
 	diff, additions, removals := diff.GenerateDiff(
-		oldContent,
-		newContent,
+		viewContent,
+		newViewContent,
 		filePath,
 	)
 	rootDir := config.WorkingDirectory()
@@ -465,7 +571,7 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
-	action := e.registry.EvaluatePermission(GetAgentID(ctx), EditToolName, filePath)
+	action := evaluateFilePermission(ctx, e.registry, EditToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
 		// Allowed by config
@@ -490,29 +596,31 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 		}
 	}
 
-	err = fileutil.WriteFile(filePath, newContent)
+	written := withBOM(newContent, bom, hadBOM)
+	err = writeEditedFile(ctx, filePath, []byte(written))
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// Check if file exists in history
+	original := restoreBOM(oldContent, hadBOM)
 	file, err := e.files.GetByPathAndSession(ctx, filePath, sessionID)
 	if err != nil {
-		file, err = e.files.Create(ctx, sessionID, filePath, oldContent)
+		file, err = e.files.Create(ctx, sessionID, filePath, original)
 		if err != nil {
 			// Log error but don't fail the operation
 			return NewEmptyResponse(), fmt.Errorf("error creating file history: %w", err)
 		}
 	}
-	if file.Content != oldContent {
+	if file.Content != original {
 		// User Manually changed the content store an intermediate version
-		_, err = e.files.CreateVersion(ctx, sessionID, filePath, oldContent)
+		_, err = e.files.CreateVersion(ctx, sessionID, filePath, original)
 		if err != nil {
 			logging.Debug("Error creating file history version", "error", err)
 		}
 	}
 	// Store the new version
-	_, err = e.files.CreateVersion(ctx, sessionID, filePath, newContent)
+	_, err = e.files.CreateVersion(ctx, sessionID, filePath, written)
 	if err != nil {
 		logging.Debug("Error creating file history version", "error", err)
 	}
@@ -520,8 +628,14 @@ func (e *editTool) replaceContent(ctx context.Context, filePath, oldString, newS
 	recordFileWrite(filePath)
 	recordFileRead(filePath)
 
+	check := editCheck{newString: normalizedNewString}
+	if !replaceAll && !reindent {
+		check.oldString = normalizedOldString
+	}
+	warning := verifyEditWritten(filePath, newContent, check)
+
 	return WithResponseMetadata(
-		NewTextResponse("Content replaced in file: "+filePath),
+		NewTextResponse("Content replaced in file: "+filePath+warning+syntaxWarning),
 		EditResponseMetadata{
 			Diff:      diff,
 			Additions: additions,
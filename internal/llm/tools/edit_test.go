@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 
@@ -44,6 +45,13 @@ func (s *stubRegistry) EvaluatePermission(agentID, toolName, input string) permi
 	return permission.ActionAllow
 }
 
+// askRegistry asks the permission service for every tool call.
+type askRegistry struct{ stubRegistry }
+
+func (s *askRegistry) EvaluatePermission(agentID, toolName, input string) permission.Action {
+	return permission.ActionAsk
+}
+
 func (s *stubRegistry) IsToolEnabled(agentID, toolName string) bool {
 	return true
 }
@@ -54,6 +62,7 @@ func (s *stubRegistry) GlobalPermissions() map[string]any {
 
 type stubHistoryService struct {
 	lastContent string
+	versions    []string
 	mu          sync.Mutex
 	subs        map[chan pubsub.Event[history.File]]struct{}
 	done        chan struct{}
@@ -126,11 +135,19 @@ func (s *stubHistoryService) Publish(context.Context, history.File) error {
 
 func (s *stubHistoryService) Create(_ context.Context, _, path, content string) (history.File, error) {
 	s.lastContent = content
+	s.versions = append(s.versions, content)
 	return history.File{Path: path, Content: content}, nil
 }
 
+func (s *stubHistoryService) CreateNew(_ context.Context, _, path string) (history.File, error) {
+	s.lastContent = ""
+	s.versions = append(s.versions, "")
+	return history.File{Path: path, IsNew: true}, nil
+}
+
 func (s *stubHistoryService) CreateVersion(_ context.Context, _, path, content string) (history.File, error) {
 	s.lastContent = content
+	s.versions = append(s.versions, content)
 	return history.File{Path: path, Content: content}, nil
 }
 
@@ -166,6 +183,10 @@ func (s *stubHistoryService) Delete(context.Context, string) error { return nil
 
 func (s *stubHistoryService) DeleteSessionFiles(context.Context, string) error { return nil }
 
+func (s *stubHistoryService) Prune(context.Context, history.PruneOptions) (history.PruneResult, error) {
+	return history.PruneResult{}, nil
+}
+
 func setupEditTest(t *testing.T) (context.Context, string, BaseTool) {
 	t.Helper()
 	ctrl := gomock.NewController(t)
@@ -224,6 +245,62 @@ func TestEditTool_Info(t *testing.T) {
 	assert.Contains(t, info.Parameters, "replace_all")
 }
 
+func TestEditTools_AdvertiseReadPrecondition(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPerms := mock_permission.NewMockService(ctrl)
+	files := newStubHistoryService()
+	for _, tool := range []BaseTool{
+		NewEditTool(&noopLspService{}, mockPerms, files, &stubRegistry{}),
+		NewMultiEditTool(&noopLspService{}, mockPerms, files, &stubRegistry{}),
+		NewSearchReplaceTool(&noopLspService{}, mockPerms, files, &stubRegistry{}),
+		NewWriteTool(&noopLspService{}, mockPerms, files, &stubRegistry{}),
+	} {
+		info := tool.Info()
+		t.Run(info.Name, func(t *testing.T) {
+			assert.Equal(t, []string{ReadFilePrecondition}, info.Preconditions)
+			assert.Contains(t, info.Description, "PRECONDITIONS:\n- "+ReadFilePrecondition)
+		})
+	}
+}
+
+func TestCoreTools_Examples(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockPerms := mock_permission.NewMockService(ctrl)
+	files := newStubHistoryService()
+	for _, tt := range []struct {
+		tool   BaseTool
+		params func() any
+	}{
+		{NewEditTool(&noopLspService{}, mockPerms, files, &stubRegistry{}), func() any { return &EditParams{} }},
+		{NewMultiEditTool(&noopLspService{}, mockPerms, files, &stubRegistry{}), func() any { return &MultiEditParams{} }},
+		{NewLsTool(nil), func() any { return &LSParams{} }},
+		{NewDeleteTool(mockPerms, files, &stubRegistry{}), func() any { return &DeleteParams{} }},
+	} {
+		info := tt.tool.Info()
+		t.Run(info.Name, func(t *testing.T) {
+			require.NotEmpty(t, info.Examples)
+			for _, example := range info.Examples {
+				assert.NotEmpty(t, example.Description)
+
+				dec := json.NewDecoder(strings.NewReader(example.Input))
+				dec.DisallowUnknownFields()
+				params := tt.params()
+				require.NoError(t, dec.Decode(params), example.Input)
+
+				var fields map[string]any
+				require.NoError(t, json.Unmarshal([]byte(example.Input), &fields))
+				for _, name := range info.Required {
+					assert.Contains(t, fields, name, "example %q is missing a required parameter", example.Description)
+				}
+			}
+		})
+	}
+}
+
 // --- Edit Tool Tests ---
 
 func TestEditTool_Replace(t *testing.T) {
@@ -289,6 +366,106 @@ func TestEditTool_Replace(t *testing.T) {
 	}
 }
 
+func TestEditTool_Reindent(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		params      EditParams
+		wantContent string
+	}{
+		{
+			name: "tab-indented block aligns to deeply nested anchor",
+			content: "func f() {\n" +
+				"\tif a {\n" +
+				"\t\tfor {\n" +
+				"\t\t\tstep()\n" +
+				"\t\t}\n" +
+				"\t}\n" +
+				"}\n",
+			params: EditParams{
+				OldString: "\t\t\tstep()\n",
+				NewString: "if b {\n    step()\n    done()\n}\n",
+				Reindent:  true,
+			},
+			wantContent: "func f() {\n" +
+				"\tif a {\n" +
+				"\t\tfor {\n" +
+				"\t\t\tif b {\n" +
+				"\t\t\t\tstep()\n" +
+				"\t\t\t\tdone()\n" +
+				"\t\t\t}\n" +
+				"\t\t}\n" +
+				"\t}\n" +
+				"}\n",
+		},
+		{
+			name: "two-space file keeps its indent width",
+			content: "def f():\n" +
+				"  if a:\n" +
+				"    pass\n",
+			params: EditParams{
+				OldString: "pass",
+				NewString: "for x in y:\n\tprint(x)",
+				Reindent:  true,
+			},
+			wantContent: "def f():\n" +
+				"  if a:\n" +
+				"    for x in y:\n" +
+				"      print(x)\n",
+		},
+		{
+			name: "mid-line anchor continues existing text",
+			content: "class A {\n" +
+				"    int x = compute();\n" +
+				"}\n",
+			params: EditParams{
+				OldString: "compute();",
+				NewString: "compute(\na,\nb);",
+				Reindent:  true,
+			},
+			wantContent: "class A {\n" +
+				"    int x = compute(\n" +
+				"    a,\n" +
+				"    b);\n" +
+				"}\n",
+		},
+		{
+			name:        "without reindent the block is inserted verbatim",
+			content:     "\t\tstep()\n",
+			params:      EditParams{OldString: "step()", NewString: "a()\nb()"},
+			wantContent: "\t\ta()\nb()\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, tmpPath, tool := setupEditTest(t)
+			tt.params.FilePath = tmpPath
+			writeAndTrack(t, tmpPath, tt.content)
+
+			resp := runEdit(t, tool, ctx, tt.params)
+			require.False(t, resp.IsError, resp.Content)
+
+			content, err := os.ReadFile(tmpPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantContent, string(content))
+		})
+	}
+}
+
+func TestDetectIndentStyle(t *testing.T) {
+	style, ok := detectIndentStyle("a\n  b\n    c\n  d\n")
+	assert.True(t, ok)
+	assert.Equal(t, indentStyle{width: 2}, style)
+
+	style, ok = detectIndentStyle("a\n\tb\n\t\tc\n")
+	assert.True(t, ok)
+	assert.True(t, style.useTabs)
+
+	_, ok = detectIndentStyle("a\nb\n")
+	assert.False(t, ok)
+}
+
 func TestEditTool_CreateFile(t *testing.T) {
 	ctx, _, tool := setupEditTest(t)
 
@@ -307,6 +484,66 @@ func TestEditTool_CreateFile(t *testing.T) {
 	assert.Equal(t, "new file content", string(content))
 }
 
+func TestEditTool_CreateFileCollision(t *testing.T) {
+	ctx, tmpPath, tool := setupEditTest(t)
+	writeAndTrack(t, tmpPath, "original")
+
+	resp := runEdit(t, tool, ctx, EditParams{
+		FilePath:  tmpPath,
+		NewString: "replacement",
+	})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "file already exists")
+
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+}
+
+func TestEditTool_CreateFileOverwrite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Return(true).AnyTimes()
+	files := newStubHistoryService()
+	tool := NewEditTool(&noopLspService{}, mockPerms, files, &stubRegistry{})
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+
+	tmpPath := filepath.Join(t.TempDir(), "overwrite.txt")
+	writeAndTrack(t, tmpPath, "original")
+
+	resp := runEdit(t, tool, ctx, EditParams{
+		FilePath:  tmpPath,
+		NewString: "replacement",
+		Overwrite: true,
+	})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "File overwritten")
+
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "replacement", string(content))
+
+	// The pre-overwrite content is recoverable from history
+	require.Len(t, files.versions, 2)
+	assert.Equal(t, "original", files.versions[0])
+	assert.Equal(t, "replacement", files.versions[1])
+}
+
+func TestEditTool_OverwriteRequiresRead(t *testing.T) {
+	ctx, _, tool := setupEditTest(t)
+	tmpPath := filepath.Join(t.TempDir(), "unread.txt")
+	require.NoError(t, os.WriteFile(tmpPath, []byte("original"), 0o644))
+
+	resp := runEdit(t, tool, ctx, EditParams{
+		FilePath:  tmpPath,
+		NewString: "replacement",
+		Overwrite: true,
+	})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "must read the file")
+}
+
 func TestEditTool_FileNotRead(t *testing.T) {
 	ctx, tmpPath, tool := setupEditTest(t)
 	require.NoError(t, os.WriteFile(tmpPath, []byte("content"), 0o644))
@@ -439,6 +676,78 @@ func TestMultiEditTool_ReplaceAll(t *testing.T) {
 	assert.Equal(t, "var z = 1;\nvar y = z + z;", string(content))
 }
 
+func TestMultiEditTool_MatchesSequentialEdits(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		edits   []MultiEditItem
+	}{
+		{
+			name:    "edit depends on previous",
+			content: "foo bar",
+			edits: []MultiEditItem{
+				{OldString: "foo", NewString: "baz"},
+				{OldString: "baz bar", NewString: "done"},
+			},
+		},
+		{
+			name:    "replace all then edit",
+			content: "var x = 1;\nvar y = x + x;",
+			edits: []MultiEditItem{
+				{OldString: "x", NewString: "z", ReplaceAll: true},
+				{OldString: "var y", NewString: "const y"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, multiPath, multiTool := setupMultiEditTest(t)
+			writeAndTrack(t, multiPath, tt.content)
+			resp := runMultiEdit(t, multiTool, ctx, MultiEditParams{FilePath: multiPath, Edits: tt.edits})
+			require.False(t, resp.IsError, resp.Content)
+
+			// Apply the same edits one call at a time with the edit tool
+			editTool := NewEditTool(&noopLspService{}, mock_permission.NewMockService(gomock.NewController(t)), newStubHistoryService(), &stubRegistry{})
+			editPath := filepath.Join(t.TempDir(), "file.txt")
+			writeAndTrack(t, editPath, tt.content)
+			for _, edit := range tt.edits {
+				input, err := json.Marshal(EditParams{FilePath: editPath, OldString: edit.OldString, NewString: edit.NewString, ReplaceAll: edit.ReplaceAll})
+				require.NoError(t, err)
+				resp, err := editTool.Run(ctx, ToolCall{Name: EditToolName, Input: string(input)})
+				require.NoError(t, err)
+				require.False(t, resp.IsError, resp.Content)
+			}
+
+			multiContent, err := os.ReadFile(multiPath)
+			require.NoError(t, err)
+			editContent, err := os.ReadFile(editPath)
+			require.NoError(t, err)
+			assert.Equal(t, string(editContent), string(multiContent))
+		})
+	}
+}
+
+func BenchmarkApplyMultiEdits(b *testing.B) {
+	var sb strings.Builder
+	for i := range 20000 {
+		fmt.Fprintf(&sb, "func handler%d() { return value%d }\n", i, i)
+	}
+	content := sb.String()
+	edits := []MultiEditItem{
+		{OldString: "func handler10()", NewString: "func handlerTen()"},
+		{OldString: "return value19999 }", NewString: "return nil }"},
+		{OldString: "handler", NewString: "Handler", ReplaceAll: true},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, _, err := applyMultiEdits(content, edits); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestMultiEditTool_Validation(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -543,6 +852,162 @@ func TestMultiEditTool_FileNotRead(t *testing.T) {
 	assert.Contains(t, resp.Content, "must read the file")
 }
 
+func TestWriteFileAtomic_FollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+	require.NoError(t, os.WriteFile(target, []byte("original\n"), 0o600))
+	require.NoError(t, os.Symlink(target, link))
+
+	require.NoError(t, writeFileAtomic(context.Background(), link, []byte("changed\n")))
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "changed\n", string(content), "the link's target is written")
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeSymlink, "the link is kept")
+	info, err = os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestEditTools_CancelBeforeWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		tool  func(permission.Service, history.Service) BaseTool
+		input func(path string) any
+	}{
+		{
+			name: EditToolName,
+			tool: func(perms permission.Service, files history.Service) BaseTool {
+				return NewEditTool(&noopLspService{}, perms, files, &askRegistry{})
+			},
+			input: func(path string) any {
+				return EditParams{FilePath: path, OldString: "original", NewString: "changed"}
+			},
+		},
+		{
+			name: MultiEditToolName,
+			tool: func(perms permission.Service, files history.Service) BaseTool {
+				return NewMultiEditTool(&noopLspService{}, perms, files, &askRegistry{})
+			},
+			input: func(path string) any {
+				return MultiEditParams{FilePath: path, Edits: []MultiEditItem{{OldString: "original", NewString: "changed"}}}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ctx = context.WithValue(ctx, SessionIDContextKey, "test-session")
+			ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+
+			// The turn is cancelled while the user is approving the write
+			mockPerms := mock_permission.NewMockService(ctrl)
+			mockPerms.EXPECT().Request(gomock.Any()).DoAndReturn(func(permission.CreatePermissionRequest) bool {
+				cancel()
+				return true
+			})
+
+			files := newStubHistoryService()
+			dir := t.TempDir()
+			path := filepath.Join(dir, "file.txt")
+			writeAndTrack(t, path, "original content\n")
+
+			input, err := json.Marshal(tt.input(path))
+			require.NoError(t, err)
+			_, err = tt.tool(mockPerms, files).Run(ctx, ToolCall{Name: tt.name, Input: string(input)})
+			require.ErrorIs(t, err, context.Canceled)
+
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, "original content\n", string(content), "file must be untouched")
+			assert.Empty(t, files.versions, "history must not be recorded")
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, entries, 1, "temporary file must be removed")
+		})
+	}
+}
+
+// corruptEditWrites makes the edit tools store corrupt(data) instead of the
+// content they meant to write.
+func corruptEditWrites(t *testing.T, corrupt func(data []byte) []byte) {
+	t.Helper()
+	old := writeEditedFile
+	writeEditedFile = func(ctx context.Context, path string, data []byte) error {
+		return writeFileAtomic(ctx, path, corrupt(data))
+	}
+	t.Cleanup(func() { writeEditedFile = old })
+}
+
+func TestEditTools_VerifyWrite(t *testing.T) {
+	t.Run("matching write has no warning", func(t *testing.T) {
+		ctx, tmpPath, tool := setupEditTest(t)
+		writeAndTrack(t, tmpPath, "call(a)\n")
+
+		resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "call(a)", NewString: "call(a)\ncall(b)"})
+		require.False(t, resp.IsError)
+		assert.NotContains(t, resp.Content, "Warning")
+	})
+
+	t.Run("dropped write", func(t *testing.T) {
+		ctx, tmpPath, tool := setupEditTest(t)
+		writeAndTrack(t, tmpPath, "hello world\n")
+		corruptEditWrites(t, func([]byte) []byte { return []byte("hello world\n") })
+
+		resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "world", NewString: "there"})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "Warning: the edit was written but re-reading")
+		assert.Contains(t, resp.Content, "new_string is missing from the file and old_string is still in the file")
+	})
+
+	t.Run("encoding mismatch", func(t *testing.T) {
+		ctx, tmpPath, tool := setupEditTest(t)
+		writeAndTrack(t, tmpPath, "name = cafe\n")
+		// The UTF-8 é is stored as Latin-1
+		corruptEditWrites(t, func(data []byte) []byte {
+			return []byte(strings.ReplaceAll(string(data), "é", "\xe9"))
+		})
+
+		resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "cafe", NewString: "café"})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "new_string is missing from the file")
+		assert.NotContains(t, resp.Content, "old_string is still in the file")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		ctx, tmpPath, tool := setupEditTest(t)
+		writeAndTrack(t, tmpPath, "keep\ndrop\n")
+		corruptEditWrites(t, func([]byte) []byte { return []byte("keep\ndrop\n") })
+
+		resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "drop\n"})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "old_string is still in the file")
+	})
+
+	t.Run(MultiEditToolName, func(t *testing.T) {
+		ctx, tmpPath, tool := setupMultiEditTest(t)
+		writeAndTrack(t, tmpPath, "aaa bbb")
+		corruptEditWrites(t, func(data []byte) []byte { return []byte(strings.ReplaceAll(string(data), "yyy", "")) })
+
+		resp := runMultiEdit(t, tool, ctx, MultiEditParams{
+			FilePath: tmpPath,
+			Edits: []MultiEditItem{
+				{OldString: "aaa", NewString: "xxx"},
+				{OldString: "bbb", NewString: "yyy"},
+			},
+		})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "new_string is missing from the file")
+	})
+}
+
 func TestMultiEditTool_MultipleMatchesWithoutReplaceAll(t *testing.T) {
 	ctx, tmpPath, tool := setupMultiEditTest(t)
 	writeAndTrack(t, tmpPath, "foo bar foo")
@@ -557,3 +1022,39 @@ func TestMultiEditTool_MultipleMatchesWithoutReplaceAll(t *testing.T) {
 	assert.Contains(t, resp.Content, "2 times")
 	assert.Contains(t, resp.Content, "replace_all")
 }
+
+func TestEditTool_AutoAllowPaths(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Get()
+	oldDir, oldPerms := cfg.WorkingDir, cfg.Permission
+	cfg.WorkingDir = dir
+	cfg.Permission = &config.PermissionConfig{AutoAllowPaths: []string{"tmp/**"}}
+	t.Cleanup(func() { cfg.WorkingDir, cfg.Permission = oldDir, oldPerms })
+
+	ctrl := gomock.NewController(t)
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+
+	srcPath := filepath.Join(dir, "src", "main.go")
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).DoAndReturn(func(req permission.CreatePermissionRequest) bool {
+		assert.Equal(t, srcPath, req.Params.(EditPermissionsParams).FilePath, "only the src edit may prompt")
+		return true
+	}).Times(1)
+
+	tool := NewEditTool(&noopLspService{}, mockPerms, newStubHistoryService(), &askRegistry{})
+	for _, path := range []string{filepath.Join(dir, "tmp", "notes.txt"), srcPath} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		writeAndTrack(t, path, "original content\n")
+
+		input, err := json.Marshal(EditParams{FilePath: path, OldString: "original", NewString: "changed"})
+		require.NoError(t, err)
+		resp, err := tool.Run(ctx, ToolCall{Name: EditToolName, Input: string(input)})
+		require.NoError(t, err)
+		assert.False(t, resp.IsError, resp.Content)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "changed content\n", string(content))
+	}
+}
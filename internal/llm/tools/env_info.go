@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/llm/tools/shell"
+)
+
+// EnvInfoResponseMetadata is the snapshot of the runtime environment. It
+// holds no environment variables, so nothing secret is exposed.
+type EnvInfoResponseMetadata struct {
+	OS         string            `json:"os"`
+	Arch       string            `json:"arch"`
+	Shell      string            `json:"shell"`
+	ShellArgs  []string          `json:"shell_args,omitempty"`
+	WorkingDir string            `json:"working_dir"`
+	Ripgrep    bool              `json:"ripgrep"`
+	Fzf        bool              `json:"fzf"`
+	Toolchains map[string]string `json:"toolchains"`
+}
+
+type envInfoTool struct{}
+
+const (
+	EnvInfoToolName    = "env_info"
+	envInfoDescription = `Describes the environment commands run in: operating system, shell and installed toolchains.
+
+WHEN TO USE THIS TOOL:
+- Before writing shell commands whose syntax depends on the OS or shell
+- To check which language toolchains are installed, and their versions, before building or testing
+
+HOW TO USE:
+- Call it without parameters
+
+FEATURES:
+- Reports the OS and architecture, the shell the bash tool uses and the working directory
+- Reports whether ripgrep (rg) and fzf are available to the search tools
+- Reports the versions of the Go, Node.js, Python and Rust toolchains on the PATH
+
+LIMITATIONS:
+- Environment variables are never listed, so no secrets are exposed
+- Toolchains that are not on the PATH, or do not answer within a few seconds, are reported as not found`
+)
+
+// envToolchains are the toolchains whose version is reported, with the
+// command printing it.
+var envToolchains = []struct {
+	name    string
+	command []string
+}{
+	{"go", []string{"go", "version"}},
+	{"node", []string{"node", "-v"}},
+	{"python", []string{"python3", "--version"}},
+	{"rust", []string{"rustc", "--version"}},
+}
+
+// envInfoTimeout bounds the version commands, which run in parallel.
+const envInfoTimeout = 5 * time.Second
+
+func NewEnvInfoTool() BaseTool {
+	return &envInfoTool{}
+}
+
+func (e *envInfoTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        EnvInfoToolName,
+		ReadOnly:    true,
+		Description: envInfoDescription,
+		Parameters:  map[string]any{},
+		Required:    []string{},
+	}
+}
+
+func (e *envInfoTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	dir := callDirectory(ctx)
+	shellPath, shellArgs := shell.ResolveShell()
+	info := EnvInfoResponseMetadata{
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		Shell:      shellPath,
+		ShellArgs:  shellArgs,
+		WorkingDir: dir,
+		Ripgrep:    fileutil.RgPath() != "",
+		Fzf:        fileutil.FzfPath() != "",
+		Toolchains: toolchainVersions(ctx, dir),
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "OS: %s/%s\n", info.OS, info.Arch)
+	fmt.Fprintf(&sb, "Shell: %s\n", strings.Join(append([]string{info.Shell}, info.ShellArgs...), " "))
+	fmt.Fprintf(&sb, "Working directory: %s\n", info.WorkingDir)
+	fmt.Fprintf(&sb, "ripgrep (rg): %s\n", availability(info.Ripgrep))
+	fmt.Fprintf(&sb, "fzf: %s\n", availability(info.Fzf))
+	sb.WriteString("Toolchains:\n")
+	for _, toolchain := range envToolchains {
+		version := info.Toolchains[toolchain.name]
+		if version == "" {
+			version = "not found"
+		}
+		fmt.Fprintf(&sb, "  %s: %s\n", toolchain.name, version)
+	}
+	return WithResponseMetadata(NewTextResponse(sb.String()), info), nil
+}
+
+func availability(available bool) string {
+	if available {
+		return "available"
+	}
+	return "not installed"
+}
+
+// toolchainVersions runs the version command of each toolchain on the PATH
+// in dir and returns the first line each printed. Toolchains that are
+// missing, fail or time out are left out.
+func toolchainVersions(ctx context.Context, dir string) map[string]string {
+	ctx, cancel := context.WithTimeout(ctx, envInfoTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	versions := map[string]string{}
+	for _, toolchain := range envToolchains {
+		path, err := exec.LookPath(toolchain.command[0])
+		if err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(name, path string, args []string) {
+			defer wg.Done()
+			cmd := exec.CommandContext(ctx, path, args...)
+			cmd.Dir = dir
+			// Report the installed Go rather than downloading the one go.mod asks for
+			cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+			output, err := cmd.Output()
+			if err != nil {
+				return
+			}
+			version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+			mu.Lock()
+			versions[name] = version
+			mu.Unlock()
+		}(toolchain.name, path, toolchain.command[1:])
+	}
+	wg.Wait()
+	return versions
+}
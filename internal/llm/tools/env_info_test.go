@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withPath replaces PATH with dir, holding the given shell scripts, for the
+// rest of the test and reloads the external tools fileutil resolved.
+func withPath(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake executables are shell scripts")
+	}
+	// Registered before Setenv so it runs after PATH is restored
+	t.Cleanup(fileutil.ReloadTools)
+	dir := t.TempDir()
+	for name, script := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0o755))
+	}
+	t.Setenv("PATH", dir)
+	fileutil.ReloadTools()
+}
+
+func runEnvInfo(t *testing.T) (ToolResponse, EnvInfoResponseMetadata) {
+	t.Helper()
+	resp, err := NewEnvInfoTool().Run(context.Background(), ToolCall{Name: EnvInfoToolName, Input: "{}"})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+	var meta EnvInfoResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	return resp, meta
+}
+
+func TestEnvInfoTool_ReportsRipgrep(t *testing.T) {
+	t.Run("installed", func(t *testing.T) {
+		withPath(t, map[string]string{"rg": "exit 0"})
+		resp, meta := runEnvInfo(t)
+		assert.True(t, meta.Ripgrep)
+		assert.False(t, meta.Fzf)
+		assert.Contains(t, resp.Content, "ripgrep (rg): available")
+		assert.Contains(t, resp.Content, "fzf: not installed")
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		withPath(t, nil)
+		resp, meta := runEnvInfo(t)
+		assert.False(t, meta.Ripgrep)
+		assert.Contains(t, resp.Content, "ripgrep (rg): not installed")
+	})
+}
+
+func TestEnvInfoTool_ReportsPlatformAndToolchains(t *testing.T) {
+	withPath(t, map[string]string{
+		"go":   `echo "go version go1.99.0 fake/arch"`,
+		"node": "exit 1",
+	})
+	resp, meta := runEnvInfo(t)
+
+	assert.Equal(t, runtime.GOOS, meta.OS)
+	assert.Equal(t, runtime.GOARCH, meta.Arch)
+	assert.Contains(t, resp.Content, "OS: "+runtime.GOOS+"/"+runtime.GOARCH)
+	assert.NotEmpty(t, meta.Shell)
+	assert.NotEmpty(t, meta.WorkingDir)
+
+	assert.Equal(t, map[string]string{"go": "go version go1.99.0 fake/arch"}, meta.Toolchains)
+	assert.Contains(t, resp.Content, "go: go version go1.99.0 fake/arch")
+	assert.Contains(t, resp.Content, "node: not found")
+}
+
+func TestEnvInfoTool_OmitsEnvironmentVariables(t *testing.T) {
+	withPath(t, nil)
+	t.Setenv("ENV_INFO_TEST_SECRET", "s3cr3t-value")
+	resp, _ := runEnvInfo(t)
+	assert.NotContains(t, resp.Content, "s3cr3t-value")
+	assert.NotContains(t, resp.Metadata, "s3cr3t-value")
+}
@@ -0,0 +1,129 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// redactedEnvValue replaces the values of environment file entries shown to
+// the model.
+const redactedEnvValue = "***"
+
+// isEnvFile reports whether path is a dotenv file such as .env, .env.local or
+// production.env. These typically hold secrets, so they are only read with
+// their values redacted. Templates like .env.example are regular files.
+func isEnvFile(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	for _, suffix := range []string{".example", ".sample", ".template"} {
+		if strings.HasSuffix(base, suffix) {
+			return false
+		}
+	}
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+// envFileWriteError is returned by tools that would overwrite an environment
+// file instead of adding keys to it with the edit tool.
+func envFileWriteError(path string) ToolResponse {
+	return NewTextErrorResponse(fmt.Sprintf("%s is an environment file; use the edit tool to add new keys to it. Existing entries cannot be changed.", path))
+}
+
+// parseEnvLine returns the key of a KEY=value line and the offset of its
+// value. ok is false for blank lines, comments and lines without a key.
+func parseEnvLine(line string) (key string, valueStart int, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return "", 0, false
+	}
+	eq := strings.IndexByte(line, '=')
+	if eq == -1 {
+		return "", 0, false
+	}
+	key = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line[:eq]), "export "))
+	return key, eq + 1, key != ""
+}
+
+// isEnvComment reports whether line is blank or a comment.
+func isEnvComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+// redactEnvContent replaces every value in an environment file with
+// redactedEnvValue, keeping keys, comments and blank lines. Continuation
+// lines of multi-line quoted values and lines that are not KEY=value entries
+// are redacted entirely. The result has the same number of lines as content.
+func redactEnvContent(content string) string {
+	lines := strings.Split(content, "\n")
+	var openQuote byte
+	for i, line := range lines {
+		if openQuote != 0 {
+			if strings.IndexByte(line, openQuote) != -1 {
+				openQuote = 0
+			}
+			lines[i] = redactedEnvValue
+			continue
+		}
+		if isEnvComment(line) {
+			continue
+		}
+		_, valueStart, ok := parseEnvLine(line)
+		if !ok {
+			lines[i] = redactedEnvValue
+			continue
+		}
+		value := strings.TrimSpace(line[valueStart:])
+		if value == "" {
+			continue
+		}
+		if q := value[0]; (q == '"' || q == '\'') && strings.IndexByte(value[1:], q) == -1 {
+			openQuote = q
+		}
+		lines[i] = line[:valueStart] + redactedEnvValue
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mergeEnvAdditions applies an edit made to the redacted view of an
+// environment file to its real content. The edited view must keep every
+// existing line unchanged and may only add comments, blank lines and entries
+// for new keys; existing lines are taken from original so their values are
+// preserved.
+func mergeEnvAdditions(original, edited string) (string, error) {
+	originalLines := strings.Split(original, "\n")
+	redactedLines := strings.Split(redactEnvContent(original), "\n")
+	existing := make(map[string]bool)
+	for _, line := range originalLines {
+		if key, _, ok := parseEnvLine(line); ok {
+			existing[key] = true
+		}
+	}
+
+	merged := make([]string, 0, len(originalLines))
+	next := 0
+	for _, line := range strings.Split(edited, "\n") {
+		if next < len(redactedLines) && line == redactedLines[next] {
+			merged = append(merged, originalLines[next])
+			next++
+			continue
+		}
+		if isEnvComment(line) {
+			merged = append(merged, line)
+			continue
+		}
+		key, _, ok := parseEnvLine(line)
+		if !ok {
+			return "", fmt.Errorf("only KEY=value entries, comments and blank lines can be added to an environment file, got %q", line)
+		}
+		if existing[key] {
+			return "", fmt.Errorf("%s already exists; existing entries of an environment file cannot be changed", key)
+		}
+		existing[key] = true
+		merged = append(merged, line)
+	}
+	if next < len(redactedLines) {
+		return "", fmt.Errorf("existing entries of an environment file cannot be changed or removed (line %d: %q)", next+1, redactedLines[next])
+	}
+	return strings.Join(merged, "\n"), nil
+}
@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const testEnvContent = `# Database
+DB_HOST=localhost
+export DB_PASSWORD="s3cr3t"
+EMPTY=
+PRIVATE_KEY="-----BEGIN KEY-----
+abc123
+-----END KEY-----"
+API_TOKEN=tok_live_42
+`
+
+func TestIsEnvFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/project/.env":             true,
+		"/project/.env.local":       true,
+		"/project/production.env":   true,
+		"/project/.ENV":             true,
+		"/project/.env.example":     false,
+		"/project/.env.sample":      false,
+		"/project/config/env.go":    false,
+		"/project/environment.yaml": false,
+	} {
+		assert.Equal(t, want, isEnvFile(path), path)
+	}
+}
+
+func TestRedactEnvContent(t *testing.T) {
+	want := `# Database
+DB_HOST=***
+export DB_PASSWORD=***
+EMPTY=
+PRIVATE_KEY=***
+***
+***
+API_TOKEN=***
+`
+	assert.Equal(t, want, redactEnvContent(testEnvContent))
+}
+
+func TestMergeEnvAdditions(t *testing.T) {
+	view := redactEnvContent(testEnvContent)
+
+	merged, err := mergeEnvAdditions(testEnvContent, view+"NEW_KEY=value\n")
+	require.NoError(t, err)
+	assert.Equal(t, testEnvContent+"NEW_KEY=value\n", merged)
+
+	for name, edited := range map[string]string{
+		"changes a value":   strings.Replace(view, "DB_HOST=***", "DB_HOST=remote", 1),
+		"removes an entry":  strings.Replace(view, "API_TOKEN=***\n", "", 1),
+		"re-adds a key":     view + "DB_HOST=other\n",
+		"adds a bare value": view + "not an entry\n",
+	} {
+		_, err := mergeEnvAdditions(testEnvContent, edited)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestReadTool_EnvFile(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.env", []byte(testEnvContent))
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-env")
+
+	resp := runRead(t, ctx, ViewParams{FilePath: path})
+	assert.True(t, resp.IsError, "environment files are not read by default")
+	assert.Contains(t, resp.Content, "redact_env=true")
+	assert.NotContains(t, resp.Content, "s3cr3t")
+
+	resp = runRead(t, ctx, ViewParams{FilePath: path, RedactEnv: true})
+	require.False(t, resp.IsError, resp.Content)
+	for _, key := range []string{"DB_HOST=***", "export DB_PASSWORD=***", "API_TOKEN=***"} {
+		assert.Contains(t, resp.Content, key)
+	}
+	for _, secret := range []string{"localhost", "s3cr3t", "abc123", "tok_live_42"} {
+		assert.NotContains(t, resp.Content, secret)
+	}
+}
+
+func TestEditTool_EnvFileAddsKey(t *testing.T) {
+	path := writeWorkingDirFile(t, "edit-*.env", []byte(testEnvContent))
+	recordFileRead(path)
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	tool := NewEditTool(&noopLspService{}, mock_permission.NewMockService(gomock.NewController(t)), newStubHistoryService(), &stubRegistry{})
+
+	resp := runEdit(t, tool, ctx, EditParams{FilePath: path, OldString: "API_TOKEN=***\n", NewString: "API_TOKEN=***\nNEW_FEATURE_FLAG=true\n"})
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testEnvContent+"NEW_FEATURE_FLAG=true\n", string(content), "existing values are kept on disk")
+
+	resp = runEdit(t, tool, ctx, EditParams{FilePath: path, OldString: "DB_HOST=***", NewString: "DB_HOST=remote"})
+	assert.True(t, resp.IsError, "existing entries cannot be changed")
+	resp = runEdit(t, tool, ctx, EditParams{FilePath: path, OldString: "API_TOKEN=***\n", NewString: ""})
+	assert.True(t, resp.IsError, "existing entries cannot be removed")
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testEnvContent+"NEW_FEATURE_FLAG=true\n", string(content))
+}
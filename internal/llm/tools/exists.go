@@ -0,0 +1,100 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+)
+
+type ExistsParams struct {
+	Path string `json:"path"`
+}
+
+// ExistsResponseMetadata describes a path. It is also the JSON body returned
+// to the model. Size and Modified are only set when the path exists.
+type ExistsResponseMetadata struct {
+	Exists   bool   `json:"exists"`
+	IsDir    bool   `json:"is_dir"`
+	Size     int64  `json:"size,omitempty"`
+	Modified string `json:"modified,omitempty"`
+}
+
+type existsTool struct{}
+
+const (
+	ExistsToolName    = "exists"
+	existsDescription = `Checks whether a file or directory exists and returns its basic metadata.
+
+WHEN TO USE THIS TOOL:
+- Before reading, editing or deleting a path you are not sure exists
+- To tell whether a path is a file or a directory
+
+HOW TO USE:
+- Provide the path to check
+
+FEATURES:
+- Returns a JSON object: {"exists": bool, "is_dir": bool, "size": bytes, "modified": RFC 3339 time}
+- A missing path is not an error; it returns {"exists": false}
+- Cheap: the file contents are never read
+
+LIMITATIONS:
+- Only paths inside the working directory can be checked`
+)
+
+func NewExistsTool() BaseTool {
+	return &existsTool{}
+}
+
+func (e *existsTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ExistsToolName,
+		ReadOnly:    true,
+		Description: existsDescription,
+		Parameters: map[string]any{
+			"path": map[string]any{
+				"type":        "string",
+				"description": "The path of the file or directory to check",
+			},
+		},
+		Required: []string{"path"},
+	}
+}
+
+func (e *existsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ExistsParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+	}
+
+	if params.Path == "" {
+		return NewMissingParamResponse("path", "path is required"), nil
+	}
+
+	path, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.Path))
+	if err != nil {
+		return NewPathErrorResponse(err, params.Path), nil
+	}
+
+	var meta ExistsResponseMetadata
+	info, err := fileutil.GetFileInfo(path)
+	switch {
+	case err == nil:
+		meta = ExistsResponseMetadata{
+			Exists:   true,
+			IsDir:    info.IsDir(),
+			Size:     info.Size(),
+			Modified: info.ModTime().Format(time.RFC3339),
+		}
+	case !os.IsNotExist(err):
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	output, err := json.Marshal(meta)
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	return WithResponseMetadata(NewTextResponse(string(output)), meta), nil
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExistsTool(t *testing.T) {
+	file := writeWorkingDirFile(t, "exists-*.txt", []byte("hello"))
+	dir := createTempDirInWorkingDir(t, "exists-")
+
+	tests := []struct {
+		name     string
+		path     string
+		expected ExistsResponseMetadata
+	}{
+		{
+			name:     "existing file",
+			path:     file,
+			expected: ExistsResponseMetadata{Exists: true, Size: 5},
+		},
+		{
+			name:     "existing directory",
+			path:     dir,
+			expected: ExistsResponseMetadata{Exists: true, IsDir: true},
+		},
+		{
+			name:     "nonexistent path",
+			path:     filepath.Join(dir, "missing.txt"),
+			expected: ExistsResponseMetadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := json.Marshal(ExistsParams{Path: tt.path})
+			require.NoError(t, err)
+
+			resp, err := NewExistsTool().Run(t.Context(), ToolCall{Name: ExistsToolName, Input: string(input)})
+			require.NoError(t, err)
+			require.False(t, resp.IsError, resp.Content)
+
+			var got ExistsResponseMetadata
+			require.NoError(t, json.Unmarshal([]byte(resp.Content), &got))
+			assert.Equal(t, tt.expected.Exists, got.Exists)
+			assert.Equal(t, tt.expected.IsDir, got.IsDir)
+			if tt.expected.Exists {
+				assert.NotEmpty(t, got.Modified)
+			} else {
+				assert.Empty(t, got.Modified)
+			}
+			if !tt.expected.IsDir {
+				assert.Equal(t, tt.expected.Size, got.Size)
+			}
+		})
+	}
+}
+
+func TestExistsTool_OutsideWorkingDirectory(t *testing.T) {
+	input, err := json.Marshal(ExistsParams{Path: "/etc/passwd"})
+	require.NoError(t, err)
+
+	resp, err := NewExistsTool().Run(t.Context(), ToolCall{Name: ExistsToolName, Input: string(input)})
+	require.NoError(t, err)
+	assert.True(t, resp.IsError)
+}
@@ -3,6 +3,9 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
@@ -200,3 +203,66 @@ func TestExtractTextFromHTML(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchTool_ContentTypeAllowlist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html><body><h1>Hello</h1></body></html>")
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		}
+	}))
+	defer server.Close()
+
+	permService := permission.NewPermissionService()
+	permService.AutoApproveSession("test-session")
+	tool := NewFetchTool(permService)
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	fetch := func(path string) ToolResponse {
+		t.Helper()
+		input, err := json.Marshal(FetchParams{URL: server.URL + path, Format: "markdown"})
+		require.NoError(t, err)
+		resp, err := tool.Run(ctx, ToolCall{Name: WebFetchToolName, Input: string(input)})
+		require.NoError(t, err)
+		return resp
+	}
+
+	html := fetch("/page.html")
+	assert.False(t, html.IsError, html.Content)
+	assert.Contains(t, html.Content, "Hello")
+
+	png := fetch("/image.png")
+	assert.True(t, png.IsError)
+	assert.Contains(t, png.Content, `"image/png"`)
+}
+
+func TestContentTypeAllowed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        bool
+	}{
+		{"text/html; charset=utf-8", true},
+		{"text/plain", true},
+		{"application/json", true},
+		{"Application/XML", true},
+		{"image/png", false},
+		{"application/octet-stream", false},
+		{"video/mp4", false},
+		{"", true},
+		{"not a media type;;", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			assert.Equal(t, tt.want, contentTypeAllowed(tt.contentType, DefaultFetchAllowedContentTypes))
+		})
+	}
+
+	assert.True(t, contentTypeAllowed("image/png", []string{"image/*"}))
+	assert.True(t, contentTypeAllowed("image/png", []string{"*/*"}))
+}
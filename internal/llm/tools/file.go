@@ -1,8 +1,21 @@
 package tools
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
 )
 
 // File record to track when files were read/written
@@ -12,8 +25,18 @@ type fileRecord struct {
 	writeTime time.Time
 }
 
+// fileView records what the read tool last returned for a file in a session
+// and the turn it was returned in
+type fileView struct {
+	turnID string
+	offset int
+	limit  int
+	hash   string
+}
+
 var (
 	fileRecords     = make(map[string]fileRecord)
+	fileViews       = make(map[string]fileView)
 	fileRecordMutex sync.RWMutex
 )
 
@@ -40,6 +63,44 @@ func getLastReadTime(path string) time.Time {
 	return record.readTime
 }
 
+// WasRecentlyRead reports whether path was read by a tool within the given
+// duration.
+func WasRecentlyRead(path string, within time.Duration) bool {
+	lastRead := getLastReadTime(path)
+	return !lastRead.IsZero() && time.Since(lastRead) <= within
+}
+
+func fileViewKey(sessionID, path string) string {
+	return sessionID + "\x00" + path
+}
+
+func recordFileView(sessionID, path string, view fileView) {
+	fileRecordMutex.Lock()
+	defer fileRecordMutex.Unlock()
+	fileViews[fileViewKey(sessionID, path)] = view
+}
+
+func getFileView(sessionID, path string) (fileView, bool) {
+	fileRecordMutex.RLock()
+	defer fileRecordMutex.RUnlock()
+	view, ok := fileViews[fileViewKey(sessionID, path)]
+	return view, ok
+}
+
+// ForgetFileViews drops what the read tool returned in sessionID, so every
+// file is read in full again. It is called once the conversation is
+// summarized and the earlier content no longer is in the context.
+func ForgetFileViews(sessionID string) {
+	fileRecordMutex.Lock()
+	defer fileRecordMutex.Unlock()
+	prefix := fileViewKey(sessionID, "")
+	for key := range fileViews {
+		if strings.HasPrefix(key, prefix) {
+			delete(fileViews, key)
+		}
+	}
+}
+
 func recordFileWrite(path string) {
 	fileRecordMutex.Lock()
 	defer fileRecordMutex.Unlock()
@@ -51,3 +112,180 @@ func recordFileWrite(path string) {
 	record.writeTime = time.Now()
 	fileRecords[path] = record
 }
+
+// evaluateFilePermission evaluates the permission rules for a file tool
+// operating on filePath. Operations that would prompt are allowed instead when
+// filePath matches permission.autoAllowPaths.
+func evaluateFilePermission(ctx context.Context, registry agentregistry.Registry, toolName, filePath string) permission.Action {
+	action := registry.EvaluatePermission(GetAgentID(ctx), toolName, filePath)
+	if action != permission.ActionAsk {
+		return action
+	}
+	cfg := config.Get()
+	if cfg == nil || cfg.Permission == nil {
+		return action
+	}
+	if permission.IsAutoAllowedPath(filePath, cfg.WorkingDir, cfg.Permission.AutoAllowPaths) {
+		sessionID, _ := GetContextValues(ctx)
+		logging.Info("Permission auto-allowed by autoAllowPaths", "tool", toolName, "path", filePath, "session_id", sessionID)
+		return permission.ActionAllow
+	}
+	return action
+}
+
+// recordOverwriteHistory stores the content a file had before it was
+// overwritten, followed by its new content, so the previous version can be
+// recovered from the file history. existed is false when the write created
+// the file.
+func recordOverwriteHistory(ctx context.Context, files history.Service, sessionID, filePath, oldContent, newContent string, existed bool) error {
+	file, err := files.GetByPathAndSession(ctx, filePath, sessionID)
+	if err != nil {
+		if existed {
+			_, err = files.Create(ctx, sessionID, filePath, oldContent)
+		} else {
+			_, err = files.CreateNew(ctx, sessionID, filePath)
+		}
+		if err != nil {
+			return fmt.Errorf("error creating file history: %w", err)
+		}
+	} else if file.Content != oldContent {
+		// The content changed outside the session, store an intermediate version
+		if _, err = files.CreateVersion(ctx, sessionID, filePath, oldContent); err != nil {
+			logging.Debug("Error creating file history version", "error", err)
+		}
+	}
+	if _, err = files.CreateVersion(ctx, sessionID, filePath, newContent); err != nil {
+		logging.Debug("Error creating file history version", "error", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces path with data by writing a temporary file in the
+// same directory and renaming it over path, so readers never observe a
+// partially written file. The file keeps its existing permissions. A symlink
+// at path is followed and its target replaced, leaving the link in place. If
+// ctx is canceled before the rename, path is left untouched and ctx.Err()
+// returned.
+func writeFileAtomic(ctx context.Context, path string, data []byte) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	// Last point at which a cancel leaves the original file in place
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// writeEditedFile writes the result of the edit tools. Tests replace it to
+// simulate a write that does not store what was intended.
+var writeEditedFile = writeFileAtomic
+
+// editCheck is one replacement an edit tool expects to find applied on disk.
+// An empty oldString or newString is not checked.
+type editCheck struct {
+	oldString string
+	newString string
+}
+
+// verifyEditWritten re-reads path after an edit that meant to write intended
+// and returns a warning for the tool response when a newString is missing
+// from the file or an oldString is still in it, or "" when the edit checks
+// out. A string is only checked when intended agrees, so a replacement whose
+// new text contains the old text is not flagged.
+func verifyEditWritten(path, intended string, checks ...editCheck) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logging.Warn("Could not re-read edited file", "path", path, "error", err)
+		return fmt.Sprintf("\n\nWarning: could not re-read %s to verify the edit: %s", path, err)
+	}
+	written := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	var problems []string
+	for _, check := range checks {
+		if check.newString != "" && strings.Contains(intended, check.newString) && !strings.Contains(written, check.newString) {
+			problems = append(problems, "new_string is missing from the file")
+			break
+		}
+	}
+	for _, check := range checks {
+		if check.oldString != "" && !strings.Contains(intended, check.oldString) && strings.Contains(written, check.oldString) {
+			problems = append(problems, "old_string is still in the file")
+			break
+		}
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	logging.Warn("Edited file does not contain the expected result", "path", path, "problems", problems)
+	return fmt.Sprintf("\n\nWarning: the edit was written but re-reading %s shows %s. The write may have been altered, for example by an encoding mismatch. Read the file again before making further edits.",
+		path, strings.Join(problems, " and "))
+}
+
+// countFileLines returns the number of lines in the file at path. A final
+// line without a trailing newline is counted.
+func countFileLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 32*1024)
+	lines := 0
+	last := byte('\n')
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			lines += bytes.Count(buf[:n], []byte{'\n'})
+			last = buf[n-1]
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	if last != '\n' {
+		lines++
+	}
+	return lines, nil
+}
+
+// lineCountSuffix annotates a file listed by a search tool with its line
+// count, or returns "" when the file cannot be read.
+func lineCountSuffix(path string) string {
+	n, err := countFileLines(path)
+	if err != nil {
+		return ""
+	}
+	if n == 1 {
+		return " (1 line)"
+	}
+	return fmt.Sprintf(" (%d lines)", n)
+}
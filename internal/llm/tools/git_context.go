@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+)
+
+// gitLogTimeout bounds the git log call so a slow repository never holds up
+// a read.
+const gitLogTimeout = 5 * time.Second
+
+// gitBlameCommits returns how many recent commits to attach to a read, or
+// zero when context.gitBlame is off.
+func gitBlameCommits() int {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Context.GitBlame {
+		return 0
+	}
+	if cfg.Context.GitBlameCommits > 0 {
+		return cfg.Context.GitBlameCommits
+	}
+	return config.DefaultGitBlameCommits
+}
+
+// recentCommits returns one line per commit, newest first, for the last n
+// commits touching path: abbreviated hash, date, author and subject. It
+// returns an empty string when git is unavailable, path is not inside a
+// repository, or the file has no history.
+func recentCommits(ctx context.Context, path string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(ctx, gitLogTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "log", "-n", strconv.Itoa(n),
+		"--no-color", "--date=short", "--format=%h %ad %an: %s", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// recentCommitsSection formats the recent commits of path for a tool
+// response, or returns an empty string when context.gitBlame is off or there
+// is nothing to show.
+func recentCommitsSection(ctx context.Context, path string) string {
+	commits := recentCommits(ctx, path, gitBlameCommits())
+	if commits == "" {
+		return ""
+	}
+	return "\n<recent_commits>\n" + commits + "\n</recent_commits>\n"
+}
+
+// Git status annotations for listed files.
+const (
+	gitStatusTracked   = "tracked"
+	gitStatusModified  = "modified"
+	gitStatusUntracked = "untracked"
+	gitStatusIgnored   = "ignored"
+)
+
+// gitStatusTimeout bounds the git status call made for a listing.
+const gitStatusTimeout = 10 * time.Second
+
+// gitStatuses holds the result of one git status call under a directory.
+type gitStatuses struct {
+	dir     string            // the directory the statuses were read for
+	dirRel  string            // dir relative to the repository root
+	changed map[string]string // repository-relative path to status
+}
+
+// gitFileStatuses runs git status once for dir. It returns nil when git is
+// unavailable or dir is not inside a repository.
+func gitFileStatuses(ctx context.Context, dir string) *gitStatuses {
+	ctx, cancel := context.WithTimeout(ctx, gitStatusTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--show-prefix")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	dirRel := strings.TrimSuffix(strings.TrimSpace(string(output)), "/")
+
+	cmd = exec.CommandContext(ctx, "git", "status", "--porcelain", "-z",
+		"--ignored", "--untracked-files=all", "--", ".")
+	cmd.Dir = dir
+	output, err = cmd.Output()
+	if err != nil {
+		return nil
+	}
+
+	statuses := &gitStatuses{dir: dir, dirRel: dirRel, changed: map[string]string{}}
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		code, path := entry[:2], entry[3:]
+		switch code {
+		case "??":
+			statuses.changed[path] = gitStatusUntracked
+		case "!!":
+			statuses.changed[path] = gitStatusIgnored
+		default:
+			statuses.changed[path] = gitStatusModified
+		}
+		// Renames and copies are followed by the original path
+		if code[0] == 'R' || code[0] == 'C' {
+			i++
+		}
+	}
+	return statuses
+}
+
+// status returns the status of the file at path, which must be inside the
+// directory the statuses were read for.
+func (s *gitStatuses) status(path string) string {
+	rel, err := filepath.Rel(s.dir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	rel = filepath.ToSlash(filepath.Join(s.dirRel, rel))
+	if status, ok := s.changed[rel]; ok {
+		return status
+	}
+	// Wholly ignored directories are reported once, with a trailing slash
+	for dir := rel; strings.Contains(dir, "/"); {
+		dir = dir[:strings.LastIndex(dir, "/")]
+		if status, ok := s.changed[dir+"/"]; ok {
+			return status
+		}
+	}
+	return gitStatusTracked
+}
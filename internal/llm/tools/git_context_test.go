@@ -0,0 +1,74 @@
+package tools
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestRecentCommits(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	gitRun(t, repo, "init", "-q")
+
+	tracked := filepath.Join(repo, "main.go")
+	for i, message := range []string{"Add main", "Handle empty input", "Log startup time"} {
+		require.NoError(t, os.WriteFile(tracked, []byte(strings.Repeat("x\n", i+1)), 0o644))
+		gitRun(t, repo, "add", "main.go")
+		gitRun(t, repo, "commit", "-q", "-m", message)
+	}
+	untracked := filepath.Join(repo, "scratch.go")
+	require.NoError(t, os.WriteFile(untracked, []byte("package main\n"), 0o644))
+
+	t.Run("lists recent commit messages newest first", func(t *testing.T) {
+		lines := strings.Split(recentCommits(t.Context(), tracked, 2), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[0], "Test: Log startup time")
+		assert.Contains(t, lines[1], "Test: Handle empty input")
+	})
+
+	t.Run("untracked file", func(t *testing.T) {
+		assert.Empty(t, recentCommits(t.Context(), untracked, 5))
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "plain.go")
+		require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0o644))
+		assert.Empty(t, recentCommits(t.Context(), path, 5))
+	})
+
+	t.Run("section follows config", func(t *testing.T) {
+		cfg := config.Get()
+		old := cfg.Context
+		t.Cleanup(func() { cfg.Context = old })
+
+		cfg.Context = config.ContextConfig{}
+		assert.Empty(t, recentCommitsSection(t.Context(), tracked))
+
+		cfg.Context = config.ContextConfig{GitBlame: true, GitBlameCommits: 1}
+		section := recentCommitsSection(t.Context(), tracked)
+		assert.Contains(t, section, "<recent_commits>")
+		assert.Contains(t, section, "Log startup time")
+		assert.NotContains(t, section, "Handle empty input")
+	})
+}
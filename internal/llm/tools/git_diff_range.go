@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+type GitDiffRangeParams struct {
+	From      string `json:"from"`
+	To        string `json:"to,omitempty"`
+	Glob      string `json:"glob,omitempty"`
+	ShowDiffs bool   `json:"show_diffs,omitempty"`
+}
+
+// GitChangedFile is a file that differs between two refs. Status is the git
+// status letter: A (added), M (modified), D (deleted), R (renamed), C
+// (copied) or T (type changed). OldPath is set for renames and copies.
+type GitChangedFile struct {
+	Status  string           `json:"status"`
+	Path    string           `json:"path"`
+	OldPath string           `json:"old_path,omitempty"`
+	Binary  bool             `json:"binary,omitempty"`
+	Diff    *diff.DiffResult `json:"diff,omitempty"`
+}
+
+// GitDiffRangeResponseMetadata lists the files changed between two refs,
+// with their parsed diffs when show_diffs is set.
+type GitDiffRangeResponseMetadata struct {
+	From  string           `json:"from"`
+	To    string           `json:"to"`
+	Files []GitChangedFile `json:"files"`
+}
+
+type gitDiffRangeTool struct{}
+
+const (
+	GitDiffRangeToolName    = "git_diff_range"
+	gitDiffRangeDescription = `Lists the files changed between two git refs, optionally with the diff of each file.
+
+WHEN TO USE THIS TOOL:
+- To review a branch: what changed between main and HEAD
+- To see what a range of commits touched before reading the files
+
+HOW TO USE:
+- Provide from, a branch, tag or commit, and optionally to, which defaults to HEAD
+- Optionally provide a glob to only list matching files (e.g. "**/*.go")
+- Set show_diffs to include the unified diff of each changed file
+
+FEATURES:
+- Runs git diff --name-status from..to, so changes on "to" are compared with "from" directly
+- Each file is listed with its status: A added, M modified, D deleted, R renamed, C copied, T type changed
+- Renamed files show their old and new paths
+
+LIMITATIONS:
+- Only works inside a git repository; both refs must exist
+- Binary files and environment files (.env) are listed without a diff
+- Uncommitted changes are not included
+
+TIPS:
+- Start without show_diffs to see the size of the change, then narrow it down with a glob`
+)
+
+// gitDiffRangeTimeout bounds each git call of the tool.
+const gitDiffRangeTimeout = 30 * time.Second
+
+func NewGitDiffRangeTool() BaseTool {
+	return &gitDiffRangeTool{}
+}
+
+func (g *gitDiffRangeTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        GitDiffRangeToolName,
+		ReadOnly:    true,
+		Description: gitDiffRangeDescription,
+		Parameters: map[string]any{
+			"from": map[string]any{
+				"type":        "string",
+				"description": "The base ref: a branch, tag or commit",
+			},
+			"to": map[string]any{
+				"type":        "string",
+				"description": "The ref compared with from (default HEAD)",
+			},
+			"glob": map[string]any{
+				"type":        "string",
+				"description": "Only list files whose repository-relative path matches this pattern (e.g. \"**/*.go\")",
+			},
+			"show_diffs": map[string]any{
+				"type":        "boolean",
+				"description": "Include the unified diff of each changed file",
+			},
+		},
+		Required: []string{"from"},
+	}
+}
+
+func (g *gitDiffRangeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params GitDiffRangeParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+	}
+	if params.From == "" {
+		return NewMissingParamResponse("from", "from is required"), nil
+	}
+	if params.To == "" {
+		params.To = "HEAD"
+	}
+	if err := fileutil.ValidateGlob(params.Glob); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	dir := callDirectory(ctx)
+	if _, err := exec.LookPath("git"); err != nil {
+		return NewTextErrorResponse("git is not installed, so refs cannot be compared"), nil
+	}
+	toplevel, err := runGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("%s is not inside a git repository, so refs cannot be compared", dir)), nil
+	}
+	for _, ref := range []string{params.From, params.To} {
+		if strings.HasPrefix(ref, "-") {
+			return NewTextErrorResponse(fmt.Sprintf("invalid ref %q", ref)), nil
+		}
+		if _, err := runGit(ctx, dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+			return NewTextErrorResponse(fmt.Sprintf("unknown ref %q: no branch, tag or commit has that name", ref)), nil
+		}
+	}
+
+	revRange := params.From + ".." + params.To
+	output, err := runGit(ctx, dir, "diff", "--name-status", "-z", "--no-color", revRange, "--")
+	if err != nil {
+		return ToolResponse{}, err
+	}
+	files := parseNameStatus(output)
+	if params.Glob != "" {
+		matching := files[:0]
+		for _, file := range files {
+			if matched, _ := doublestar.Match(params.Glob, file.Path); matched {
+				matching = append(matching, file)
+			}
+		}
+		files = matching
+	}
+
+	var diffs strings.Builder
+	if params.ShowDiffs {
+		root := strings.TrimSpace(string(toplevel))
+		for i := range files {
+			file := &files[i]
+			if isEnvFile(file.Path) {
+				continue
+			}
+			paths := []string{file.Path}
+			if file.OldPath != "" {
+				paths = append(paths, file.OldPath)
+			}
+			// Paths from --name-status are relative to the repository root
+			unified, err := runGit(ctx, root, append([]string{"diff", "--no-color", "--no-ext-diff", revRange, "--"}, paths...)...)
+			if err != nil {
+				return ToolResponse{}, err
+			}
+			if bytes.Contains(unified, []byte("\nBinary files ")) || bytes.HasPrefix(unified, []byte("Binary files ")) {
+				file.Binary = true
+				continue
+			}
+			parsed, err := diff.ParseUnifiedDiff(string(unified))
+			if err != nil {
+				return NewTextErrorResponse(fmt.Sprintf("error parsing the diff of %s: %s", file.Path, err)), nil
+			}
+			file.Diff = &parsed
+			fmt.Fprintf(&diffs, "\n=== %s ===\n%s", file.Path, unified)
+		}
+	}
+
+	meta := GitDiffRangeResponseMetadata{From: params.From, To: params.To, Files: files}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d files changed between %s and %s\n", len(files), params.From, params.To)
+	for _, file := range files {
+		line := file.Path
+		if file.OldPath != "" {
+			line = file.OldPath + " -> " + file.Path
+		}
+		switch {
+		case file.Binary:
+			line += " (binary)"
+		case params.ShowDiffs && isEnvFile(file.Path):
+			line += " (environment file, diff hidden)"
+		}
+		fmt.Fprintf(&sb, "  %s %s\n", file.Status, line)
+	}
+	sb.WriteString(diffs.String())
+
+	return WithResponseMetadata(NewTextResponse(sb.String()), meta), nil
+}
+
+// runGit runs git in dir and returns its output. The error holds git's
+// message when it fails.
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitDiffRangeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("git %s timed out after %s", args[0], gitDiffRangeTimeout)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// parseNameStatus parses the NUL-separated output of git diff --name-status
+// -z. Renames and copies are followed by a score and two paths, the old one
+// first.
+func parseNameStatus(output []byte) []GitChangedFile {
+	fields := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	files := []GitChangedFile{}
+	for i := 0; i+1 < len(fields); {
+		status := fields[i]
+		if status == "" {
+			break
+		}
+		file := GitChangedFile{Status: status[:1]}
+		if (file.Status == "R" || file.Status == "C") && i+2 < len(fields) {
+			file.OldPath, file.Path = fields[i+1], fields[i+2]
+			i += 3
+		} else {
+			file.Path = fields[i+1]
+			i += 2
+		}
+		files = append(files, file)
+	}
+	return files
+}
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitDiffRangeTool(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	gitRun(t, repo, "init", "-q")
+	write("main.go", "package main\n\nfunc main() {}\n")
+	write("docs/readme.md", "# Docs\n")
+	write("old_name.go", "package main\n\n// helper does nothing\nfunc helper() {}\n")
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-q", "-m", "Initial")
+	gitRun(t, repo, "tag", "v1")
+
+	write("main.go", "package main\n\nfunc main() {\n\thelper()\n}\n")
+	write("lib/util.go", "package lib\n")
+	require.NoError(t, os.Remove(filepath.Join(repo, "docs/readme.md")))
+	gitRun(t, repo, "mv", "old_name.go", "helper.go")
+	gitRun(t, repo, "add", "-A")
+	gitRun(t, repo, "commit", "-q", "-m", "Second")
+
+	ctx := context.WithValue(context.Background(), CallDirectoryContextKey, repo)
+	run := func(t *testing.T, params GitDiffRangeParams) (ToolResponse, GitDiffRangeResponseMetadata) {
+		t.Helper()
+		input, err := json.Marshal(params)
+		require.NoError(t, err)
+		resp, err := NewGitDiffRangeTool().Run(ctx, ToolCall{Name: GitDiffRangeToolName, Input: string(input)})
+		require.NoError(t, err)
+		var meta GitDiffRangeResponseMetadata
+		if resp.Metadata != "" {
+			require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+		}
+		return resp, meta
+	}
+
+	t.Run("lists the changed files", func(t *testing.T) {
+		resp, meta := run(t, GitDiffRangeParams{From: "v1"})
+		require.False(t, resp.IsError, resp.Content)
+		assert.Equal(t, "HEAD", meta.To)
+		assert.Equal(t, []GitChangedFile{
+			{Status: "D", Path: "docs/readme.md"},
+			{Status: "R", Path: "helper.go", OldPath: "old_name.go"},
+			{Status: "A", Path: "lib/util.go"},
+			{Status: "M", Path: "main.go"},
+		}, meta.Files)
+		assert.Contains(t, resp.Content, "4 files changed between v1 and HEAD")
+		assert.Contains(t, resp.Content, "R old_name.go -> helper.go")
+		assert.NotContains(t, resp.Content, "@@")
+	})
+
+	t.Run("diffs per file", func(t *testing.T) {
+		resp, meta := run(t, GitDiffRangeParams{From: "v1", To: "HEAD", Glob: "**/*.go", ShowDiffs: true})
+		require.False(t, resp.IsError, resp.Content)
+		require.Len(t, meta.Files, 3)
+		byPath := map[string]GitChangedFile{}
+		for _, file := range meta.Files {
+			byPath[file.Path] = file
+		}
+
+		mainDiff := byPath["main.go"].Diff
+		require.NotNil(t, mainDiff)
+		assert.Equal(t, "main.go", mainDiff.OldFile)
+		assert.Equal(t, "main.go", mainDiff.NewFile)
+		require.Len(t, mainDiff.Hunks, 1)
+		var added, removed []string
+		for _, line := range mainDiff.Hunks[0].Lines {
+			switch line.Kind {
+			case diff.LineAdded:
+				added = append(added, line.Content)
+			case diff.LineRemoved:
+				removed = append(removed, line.Content)
+			}
+		}
+		assert.Equal(t, []string{"func main() {", "\thelper()", "}"}, added)
+		assert.Equal(t, []string{"func main() {}"}, removed)
+
+		utilDiff := byPath["lib/util.go"].Diff
+		require.NotNil(t, utilDiff)
+		assert.Equal(t, "/dev/null", utilDiff.OldFile)
+		assert.Contains(t, resp.Content, "=== lib/util.go ===")
+		assert.NotContains(t, resp.Content, "docs/readme.md")
+	})
+
+	t.Run("diffs from a subdirectory", func(t *testing.T) {
+		subCtx := context.WithValue(context.Background(), CallDirectoryContextKey, filepath.Join(repo, "lib"))
+		resp, err := NewGitDiffRangeTool().Run(subCtx, ToolCall{Name: GitDiffRangeToolName, Input: `{"from":"v1","glob":"main.go","show_diffs":true}`})
+		require.NoError(t, err)
+		require.False(t, resp.IsError, resp.Content)
+		var meta GitDiffRangeResponseMetadata
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+		require.Len(t, meta.Files, 1)
+		require.NotNil(t, meta.Files[0].Diff)
+		assert.Len(t, meta.Files[0].Diff.Hunks, 1)
+		assert.Contains(t, resp.Content, "+\thelper()")
+	})
+
+	t.Run("unknown ref", func(t *testing.T) {
+		resp, _ := run(t, GitDiffRangeParams{From: "v1", To: "no-such-branch"})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, `unknown ref "no-such-branch"`)
+
+		resp, _ = run(t, GitDiffRangeParams{From: "--output=/tmp/x"})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "invalid ref")
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		dir := t.TempDir()
+		input := `{"from":"HEAD~1"}`
+		resp, err := NewGitDiffRangeTool().Run(context.WithValue(context.Background(), CallDirectoryContextKey, dir), ToolCall{Name: GitDiffRangeToolName, Input: input})
+		require.NoError(t, err)
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "is not inside a git repository")
+	})
+}
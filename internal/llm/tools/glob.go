@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -29,6 +30,7 @@ HOW TO USE:
 - Provide a glob pattern to match against file paths
 - Optionally specify a starting directory (defaults to current working directory)
 - Results are sorted with most recently modified files first
+- Set with_line_count=true to see how many lines each file has, e.g. to decide which to read first
 
 GLOB PATTERN SYNTAX:
 - '*' matches any sequence of non-separator characters
@@ -47,6 +49,9 @@ LIMITATIONS:
 - Results are limited to 100 files (newest first)
 - Does not search file contents (use Grep tool for that)
 - Hidden files (starting with '.') are skipped
+- Files matching the tools.defaultIgnore setting are skipped unless no_default_ignore is set
+- Malformed patterns, such as an unclosed '{' or '[', are rejected with an error rather than matching nothing
+- When maxFileSizeForTools is configured, larger files are left out and counted in a note
 
 TIPS:
 - For the most useful results, combine with the Grep tool: first find files with Glob, then search their contents with Grep
@@ -56,13 +61,18 @@ TIPS:
 )
 
 type GlobParams struct {
-	Pattern string `json:"pattern"`
-	Path    string `json:"path"`
+	Pattern         string   `json:"pattern"`
+	Path            string   `json:"path"`
+	Ignore          []string `json:"ignore,omitempty"`
+	NoDefaultIgnore bool     `json:"no_default_ignore,omitempty"`
+	WithLineCount   bool     `json:"with_line_count,omitempty"`
 }
 
 type GlobResponseMetadata struct {
 	NumberOfFiles int  `json:"number_of_files"`
 	Truncated     bool `json:"truncated"`
+	// Skipped counts the files left out for exceeding maxFileSizeForTools.
+	Skipped int `json:"skipped,omitempty"`
 }
 
 type globTool struct{}
@@ -74,6 +84,7 @@ func NewGlobTool() BaseTool {
 func (g *globTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        GlobToolName,
+		ReadOnly:    true,
 		Description: globDescription,
 		Parameters: map[string]any{
 			"pattern": map[string]any{
@@ -84,6 +95,21 @@ func (g *globTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The directory to search in. Defaults to the current working directory.",
 			},
+			"ignore": map[string]any{
+				"type":        "array",
+				"description": "List of glob patterns to exclude from the results",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"no_default_ignore": map[string]any{
+				"type":        "boolean",
+				"description": "If true, the configured default ignore patterns are not applied. Default is false.",
+			},
+			"with_line_count": map[string]any{
+				"type":        "boolean",
+				"description": "If true, each file is annotated with its number of lines. Default is false.",
+			},
 		},
 		Required: []string{"pattern"},
 	}
@@ -92,14 +118,17 @@ func (g *globTool) Info() ToolInfo {
 func (g *globTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params GlobParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.Pattern == "" {
-		return NewTextErrorResponse("pattern is required"), nil
+		return NewMissingParamResponse("pattern", "pattern is required"), nil
+	}
+	if err := validateGlobs(append([]string{params.Pattern}, params.Ignore...)...); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
 	}
 
-	searchPath := params.Path
+	searchPath := resolveCallPath(ctx, params.Path)
 	if searchPath == "" {
 		searchPath = config.WorkingDirectory()
 	}
@@ -107,7 +136,7 @@ func (g *globTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	info, err := os.Stat(searchPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewTextErrorResponse("path does not exist: " + searchPath), nil
+			return NewNotFoundResponse("path does not exist: "+searchPath, searchPath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("error accessing path: %w", err)
 	}
@@ -115,34 +144,48 @@ func (g *globTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse(fmt.Sprintf("path is a file, not a directory: %s. Provide a directory path instead.", searchPath)), nil
 	}
 
-	files, truncated, err := globFiles(params.Pattern, searchPath, 100)
+	ignore := ignorePatterns(params.Ignore, params.NoDefaultIgnore)
+	files, truncated, err := globFiles(params.Pattern, searchPath, 100, followSymlinks(), ignore)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error finding files: %w", err)
 	}
+	files, skipped := skipLargeFiles(files)
 
 	var output string
 	if len(files) == 0 {
 		output = "No files found"
 	} else {
-		output = strings.Join(files, "\n")
+		paths := make([]string, len(files))
+		for i, file := range files {
+			paths[i] = fileutil.NormalizePathForAI(file)
+			if params.WithLineCount {
+				paths[i] += lineCountSuffix(file)
+			}
+		}
+		output = strings.Join(paths, "\n")
 		if truncated {
 			output += "\n\n(Results are truncated. Consider using a more specific path or pattern.)"
 		}
 	}
+	output += skippedLargeFilesNote(skipped)
 
 	return WithResponseMetadata(
 		NewTextResponse(output),
 		GlobResponseMetadata{
 			NumberOfFiles: len(files),
 			Truncated:     truncated,
+			Skipped:       skipped,
 		},
 	), nil
 }
 
-func globFiles(pattern, searchPath string, limit int) ([]string, bool, error) {
-	cmdRg := fileutil.GetRgCmd(pattern)
+func globFiles(pattern, searchPath string, limit int, followSymlinks bool, ignore []string) ([]string, bool, error) {
+	cmdRg := fileutil.GetRgCmd(pattern, followSymlinks)
 	if cmdRg != nil {
 		cmdRg.Dir = searchPath
+		for _, p := range ignore {
+			cmdRg.Args = append(cmdRg.Args, "--glob", "!"+p)
+		}
 		matches, err := runRipgrep(cmdRg, searchPath, limit)
 		if err == nil {
 			return matches, len(matches) >= limit && limit > 0, nil
@@ -150,7 +193,28 @@ func globFiles(pattern, searchPath string, limit int) ([]string, bool, error) {
 		logging.Warn(fmt.Sprintf("Ripgrep execution failed: %v. Falling back to doublestar.", err))
 	}
 
-	return fileutil.GlobWithDoublestar(pattern, searchPath, limit)
+	if len(ignore) == 0 {
+		return fileutil.GlobWithDoublestar(pattern, searchPath, limit, followSymlinks)
+	}
+
+	// Filter before applying the limit so ignored files don't use up slots.
+	all, _, err := fileutil.GlobWithDoublestar(pattern, searchPath, 0, followSymlinks)
+	if err != nil {
+		return nil, false, err
+	}
+	matches := make([]string, 0, len(all))
+	for _, match := range all {
+		rel, relErr := filepath.Rel(searchPath, match)
+		if relErr == nil && matchesIgnorePattern(rel, ignore) {
+			continue
+		}
+		matches = append(matches, match)
+	}
+	truncated := limit > 0 && len(matches) > limit
+	if truncated {
+		matches = matches[:limit]
+	}
+	return matches, truncated, nil
 }
 
 func runRipgrep(cmd *exec.Cmd, searchRoot string, limit int) ([]string, error) {
@@ -2,8 +2,12 @@ package tools
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -35,6 +39,23 @@ func TestGlobTool_Run(t *testing.T) {
 				assert.Contains(t, resp.Content, "pattern is required")
 			},
 		},
+		{
+			name:      "rejects an unbalanced brace",
+			callInput: `{"pattern":"**/*.{go,md"}`,
+			assertResult: func(t *testing.T, resp ToolResponse) {
+				assert.True(t, resp.IsError)
+				assert.Contains(t, resp.Content, "invalid glob pattern: ")
+				assert.Contains(t, resp.Content, "unclosed '{'")
+			},
+		},
+		{
+			name:      "rejects an invalid ignore pattern",
+			callInput: `{"pattern":"**/*.go","ignore":["!vendor/[a-"]}`,
+			assertResult: func(t *testing.T, resp ToolResponse) {
+				assert.True(t, resp.IsError)
+				assert.Contains(t, resp.Content, "unterminated character class")
+			},
+		},
 		{
 			name:      "handles valid pattern with files found",
 			callInput: `{"pattern":"*.go"}`,
@@ -59,6 +80,36 @@ func TestGlobTool_Run(t *testing.T) {
 	}
 }
 
+func TestGlobTool_WithLineCount(t *testing.T) {
+	dir := createTempDirInWorkingDir(t, "glob_lines_*")
+	for name, content := range map[string]string{
+		"empty.txt":        "",
+		"one.txt":          "single line without newline",
+		"three.txt":        "a\nb\nc\n",
+		"unterminated.txt": "a\nb\nc\nd",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+
+	run := func(input string) string {
+		t.Helper()
+		resp, err := NewGlobTool().Run(context.Background(), ToolCall{Name: GlobToolName, Input: input})
+		require.NoError(t, err)
+		require.False(t, resp.IsError, resp.Content)
+		return resp.Content
+	}
+
+	plain := run(fmt.Sprintf(`{"pattern":"*.txt","path":%q}`, dir))
+	assert.Contains(t, plain, "three.txt")
+	assert.NotContains(t, plain, "lines)", "line counts are off by default")
+
+	counted := run(fmt.Sprintf(`{"pattern":"*.txt","path":%q,"with_line_count":true}`, dir))
+	assert.Contains(t, counted, "empty.txt (0 lines)")
+	assert.Contains(t, counted, "one.txt (1 line)")
+	assert.Contains(t, counted, "three.txt (3 lines)")
+	assert.Contains(t, counted, "unterminated.txt (4 lines)")
+}
+
 func TestGlobResponseMetadata(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -217,3 +268,27 @@ func TestGlobToRegex(t *testing.T) {
 		})
 	}
 }
+
+func TestGlobTool_SkipsLargeFiles(t *testing.T) {
+	dir := createTempDirInWorkingDir(t, "glob_large_*")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "small.csv"), []byte("a,b\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dataset.csv"), make([]byte, 1024), 0o644))
+	input := fmt.Sprintf(`{"pattern":"*.csv","path":%q}`, dir)
+
+	resp, err := NewGlobTool().Run(context.Background(), ToolCall{Name: GlobToolName, Input: input})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "dataset.csv", "large files are listed without a threshold")
+
+	cfg := config.Get()
+	old := cfg.MaxFileSizeForTools
+	cfg.MaxFileSizeForTools = 512
+	t.Cleanup(func() { cfg.MaxFileSizeForTools = old })
+
+	resp, err = NewGlobTool().Run(context.Background(), ToolCall{Name: GlobToolName, Input: input})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "small.csv")
+	assert.NotContains(t, resp.Content, "dataset.csv")
+	assert.Contains(t, resp.Content, "1 files larger than maxFileSizeForTools (512 bytes) were skipped")
+	assert.Contains(t, resp.Metadata, `"skipped":1`)
+}
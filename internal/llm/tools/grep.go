@@ -20,10 +20,15 @@ import (
 )
 
 type GrepParams struct {
-	Pattern     string `json:"pattern"`
-	Path        string `json:"path"`
-	Include     string `json:"include"`
-	LiteralText bool   `json:"literal_text"`
+	Pattern         string   `json:"pattern"`
+	Path            string   `json:"path"`
+	Include         string   `json:"include"`
+	LiteralText     bool     `json:"literal_text"`
+	Ignore          []string `json:"ignore,omitempty"`
+	NoDefaultIgnore bool     `json:"no_default_ignore,omitempty"`
+	MaxResults      int      `json:"max_results,omitempty"`
+	Offset          int      `json:"offset,omitempty"`
+	WithLineCount   bool     `json:"with_line_count,omitempty"`
 }
 
 type grepMatch struct {
@@ -35,9 +40,19 @@ type grepMatch struct {
 
 type GrepResponseMetadata struct {
 	NumberOfMatches int  `json:"number_of_matches"`
+	Offset          int  `json:"offset"`
+	TotalEstimate   int  `json:"total_estimate"`
 	Truncated       bool `json:"truncated"`
 }
 
+const (
+	defaultGrepMaxResults = 100
+	maxGrepMaxResults     = 1000
+	// grepCollectLimit bounds how many matches are read from the search
+	// backend, so a broad pattern never buffers the whole tree in memory.
+	grepCollectLimit = 5000
+)
+
 type grepTool struct{}
 
 const (
@@ -55,6 +70,8 @@ HOW TO USE:
 - Optionally specify a starting directory (defaults to current working directory)
 - Optionally provide an include pattern to filter which files to search
 - Results are sorted with most recently modified files first
+- Use max_results (default 100, max 1000) and offset to page through large result sets
+- Set with_line_count=true to see how many lines each matching file has
 
 REGEX PATTERN SYNTAX (when literal_text=false):
 - Supports standard regular expression syntax
@@ -68,16 +85,17 @@ COMMON INCLUDE PATTERN EXAMPLES:
 - '*.go' - Only search Go files
 
 LIMITATIONS:
-- Results are limited to 100 files (newest first)
+- Returns at most max_results matches per call; at most 5000 matches are collected in total
 - Performance depends on the number of files being searched
 - Very large binary files may be skipped
 - Hidden files (starting with '.') are skipped
+- Files matching the tools.defaultIgnore setting are skipped unless no_default_ignore is set
 
 TIPS:
 - For faster, more targeted searches, first use Glob to find relevant files, then use Grep
 - If you need to identify or count the number of matches within files, use the Bash tool with ` + "`rg`" + ` directly
 - When doing iterative exploration that may require multiple rounds of searching, consider using the Task tool instead
-- Always check if results are truncated and refine your search pattern if needed
+- Always check if results are truncated; request the next page with offset or refine your search pattern
 - Use literal_text=true when searching for exact text containing special characters like dots, parentheses, etc.`
 )
 
@@ -88,6 +106,7 @@ func NewGrepTool() BaseTool {
 func (g *grepTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        GrepToolName,
+		ReadOnly:    true,
 		Description: grepDescription,
 		Parameters: map[string]any{
 			"pattern": map[string]any{
@@ -106,6 +125,29 @@ func (g *grepTool) Info() ToolInfo {
 				"type":        "boolean",
 				"description": "If true, the pattern will be treated as literal text with special regex characters escaped. Default is false.",
 			},
+			"ignore": map[string]any{
+				"type":        "array",
+				"description": "List of glob patterns for files to leave out of the search",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"no_default_ignore": map[string]any{
+				"type":        "boolean",
+				"description": "If true, the configured default ignore patterns are not applied. Default is false.",
+			},
+			"max_results": map[string]any{
+				"type":        "integer",
+				"description": "Maximum number of matches to return (default 100, max 1000)",
+			},
+			"offset": map[string]any{
+				"type":        "integer",
+				"description": "Number of matches to skip, for paging through results (default 0)",
+			},
+			"with_line_count": map[string]any{
+				"type":        "boolean",
+				"description": "If true, each file is annotated with its number of lines. Default is false.",
+			},
 		},
 		Required: []string{"pattern"},
 	}
@@ -126,11 +168,14 @@ func escapeRegexPattern(pattern string) string {
 func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params GrepParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.Pattern == "" {
-		return NewTextErrorResponse("pattern is required"), nil
+		return NewMissingParamResponse("pattern", "pattern is required"), nil
+	}
+	if err := validateGlobs(append([]string{params.Include}, params.Ignore...)...); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
 	}
 
 	// If literal_text is true, escape the pattern
@@ -139,21 +184,38 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		searchPattern = escapeRegexPattern(params.Pattern)
 	}
 
-	searchPath := params.Path
+	searchPath := resolveCallPath(ctx, params.Path)
 	if searchPath == "" {
 		searchPath = config.WorkingDirectory()
 	}
 
-	matches, truncated, err := searchFiles(ctx, searchPattern, searchPath, params.Include, 100)
+	if params.Offset < 0 {
+		return NewTextErrorResponse("offset must not be negative"), nil
+	}
+	limit := params.MaxResults
+	if limit <= 0 {
+		limit = defaultGrepMaxResults
+	}
+	limit = min(limit, maxGrepMaxResults)
+
+	ignore := ignorePatterns(params.Ignore, params.NoDefaultIgnore)
+	page, err := searchFiles(ctx, searchPattern, searchPath, params.Include, ignore, params.Offset, limit)
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error searching files: %w", err)
 	}
+	matches := page.matches
 
 	var output string
 	if len(matches) == 0 {
 		output = "No files found"
+		if params.Offset > 0 && page.total > 0 {
+			output = fmt.Sprintf("No matches past offset %d (found %s matches)", params.Offset, page.totalString())
+		}
 	} else {
-		output = fmt.Sprintf("Found %d matches\n", len(matches))
+		output = fmt.Sprintf("Found %s matches\n", page.totalString())
+		if params.Offset > 0 || page.truncated {
+			output = fmt.Sprintf("Found %s matches, showing %d-%d\n", page.totalString(), params.Offset+1, params.Offset+len(matches))
+		}
 
 		currentFile := ""
 		var outputSb158 strings.Builder
@@ -163,18 +225,22 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 					outputSb158.WriteString("\n")
 				}
 				currentFile = match.path
-				outputSb158.WriteString(match.path + ":\n")
+				header := fileutil.NormalizePathForAI(match.path)
+				if params.WithLineCount {
+					header += lineCountSuffix(match.path)
+				}
+				outputSb158.WriteString(header + ":\n")
 			}
 			if match.lineNum > 0 {
 				outputSb158.WriteString(fmt.Sprintf("  Line %d: %s\n", match.lineNum, match.lineText))
 			} else {
-				outputSb158.WriteString(fmt.Sprintf("  %s\n", match.path))
+				outputSb158.WriteString(fmt.Sprintf("  %s\n", fileutil.NormalizePathForAI(match.path)))
 			}
 		}
 		output += outputSb158.String()
 
-		if truncated {
-			output += fmt.Sprintf("\n(Results truncated: showing %d matches. Consider using a more specific path or pattern.)", len(matches))
+		if page.truncated {
+			output += fmt.Sprintf("\n(Results truncated. Use offset=%d to see the next page, or use a more specific path or pattern.)", params.Offset+len(matches))
 		}
 	}
 
@@ -182,68 +248,97 @@ func (g *grepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		NewTextResponse(output),
 		GrepResponseMetadata{
 			NumberOfMatches: len(matches),
-			Truncated:       truncated,
+			Offset:          params.Offset,
+			TotalEstimate:   page.total,
+			Truncated:       page.truncated,
 		},
 	), nil
 }
 
-func searchFiles(ctx context.Context, pattern, rootPath, include string, limit int) ([]grepMatch, bool, error) {
-	matches, err := searchWithRipgrep(ctx, pattern, rootPath, include)
+// grepPage is one page of search results.
+type grepPage struct {
+	matches   []grepMatch
+	total     int  // Number of matches collected, a lower bound when capped
+	capped    bool // Collection stopped at grepCollectLimit
+	truncated bool // More matches exist after this page
+}
+
+func (p grepPage) totalString() string {
+	if p.capped {
+		return fmt.Sprintf("at least %d", p.total)
+	}
+	return strconv.Itoa(p.total)
+}
+
+func searchFiles(ctx context.Context, pattern, rootPath, include string, ignore []string, offset, limit int) (grepPage, error) {
+	matches, err := searchWithRipgrep(ctx, pattern, rootPath, include, ignore, grepCollectLimit)
 	if err != nil {
-		matches, err = searchFilesWithRegex(pattern, rootPath, include)
+		matches, err = searchFilesWithRegex(pattern, rootPath, include, ignore, grepCollectLimit)
 		if err != nil {
-			return nil, false, err
+			return grepPage{}, err
 		}
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].modTime.After(matches[j].modTime)
+	// Ties are broken by location so that pages are stable between calls
+	sort.SliceStable(matches, func(i, j int) bool {
+		if !matches[i].modTime.Equal(matches[j].modTime) {
+			return matches[i].modTime.After(matches[j].modTime)
+		}
+		if matches[i].path != matches[j].path {
+			return matches[i].path < matches[j].path
+		}
+		return matches[i].lineNum < matches[j].lineNum
 	})
 
-	totalMatches := len(matches)
-	truncated := totalMatches > limit
-	if truncated {
-		matches = matches[:limit]
+	page := grepPage{
+		total:  len(matches),
+		capped: len(matches) >= grepCollectLimit,
 	}
-
-	return matches, truncated, nil
+	if offset >= len(matches) {
+		return page, nil
+	}
+	end := min(offset+limit, len(matches))
+	page.matches = matches[offset:end]
+	page.truncated = end < len(matches)
+	return page, nil
 }
 
-func searchWithRipgrep(ctx context.Context, pattern, path, include string) ([]grepMatch, error) {
+func searchWithRipgrep(ctx context.Context, pattern, path, include string, ignore []string, limit int) ([]grepMatch, error) {
 	_, err := exec.LookPath("rg")
 	if err != nil {
 		return nil, fmt.Errorf("ripgrep not found: %w", err)
 	}
 
-	args := []string{"-H", "-n", "--no-messages", "--field-match-separator=\x00", pattern}
+	// Sorting by path makes ripgrep, which otherwise searches files in
+	// parallel, collect the same matches on every call once the collection
+	// limit is reached, so the pages of a search line up.
+	args := []string{"-H", "-n", "--no-messages", "--sort", "path", "--field-match-separator=\x00", "--max-columns=1000", pattern}
 	if include != "" {
 		args = append(args, "--glob", include)
 	}
+	for _, p := range ignore {
+		args = append(args, "--glob", "!"+p)
+	}
 	args = append(args, path)
 
-	cmd := exec.CommandContext(ctx, "rg", args...)
-	output, err := cmd.Output()
+	// Read matches as they are produced and stop ripgrep once the limit is
+	// reached instead of buffering its entire output.
+	rgCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(rgCtx, "rg", args...)
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		exitErr := &exec.ExitError{}
-		if errors.As(err, &exitErr) {
-			switch exitErr.ExitCode() {
-			case 1:
-				return []grepMatch{}, nil
-			case 2:
-				// Partial results (e.g. broken symlinks, permission denied)
-				// Continue processing whatever output we got
-			default:
-				return nil, err
-			}
-		} else {
-			return nil, err
-		}
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	matches := make([]grepMatch, 0, len(lines))
-
-	for _, line := range lines {
+	matches := []grepMatch{}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
 		if line == "" {
 			continue
 		}
@@ -272,12 +367,38 @@ func searchWithRipgrep(ctx context.Context, pattern, path, include string) ([]gr
 			lineNum:  lineNum,
 			lineText: lineText,
 		})
+		if len(matches) >= limit {
+			cancel()
+			break
+		}
+	}
+
+	err = cmd.Wait()
+	if len(matches) >= limit {
+		// ripgrep was stopped on purpose
+		return matches, nil
+	}
+	if err != nil {
+		exitErr := &exec.ExitError{}
+		if errors.As(err, &exitErr) {
+			switch exitErr.ExitCode() {
+			case 1:
+				return []grepMatch{}, nil
+			case 2:
+				// Partial results (e.g. broken symlinks, permission denied)
+				// Continue processing whatever output we got
+			default:
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
 	}
 
 	return matches, nil
 }
 
-func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error) {
+func searchFilesWithRegex(pattern, rootPath, include string, ignore []string, limit int) ([]grepMatch, error) {
 	matches := []grepMatch{}
 
 	regex, err := regexp.Compile(pattern)
@@ -299,6 +420,15 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 			return nil // Skip errors
 		}
 
+		if len(ignore) > 0 {
+			if rel, relErr := filepath.Rel(rootPath, path); relErr == nil && rel != "." && matchesIgnorePattern(rel, ignore) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+		}
+
 		if info.IsDir() {
 			return nil // Skip directories
 		}
@@ -324,7 +454,7 @@ func searchFilesWithRegex(pattern, rootPath, include string) ([]grepMatch, error
 				lineText: lineText,
 			})
 
-			if len(matches) >= 200 {
+			if len(matches) >= limit {
 				return filepath.SkipAll
 			}
 		}
@@ -2,7 +2,14 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -111,3 +118,102 @@ func TestGrepMatch(t *testing.T) {
 	assert.Equal(t, 42, match.lineNum)
 	assert.Equal(t, "func test()", match.lineText)
 }
+
+func TestGrepTool_Pagination(t *testing.T) {
+	dir := createTempDirInWorkingDir(t, "grep_page_*")
+	base := time.Now().Add(-time.Hour)
+	for i := range 5 {
+		path := filepath.Join(dir, fmt.Sprintf("file%d.txt", i))
+		require.NoError(t, os.WriteFile(path, []byte("needle\n"), 0o644))
+		// Newer files sort first, so file4 leads the results
+		modTime := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	runGrep := func(offset int) (ToolResponse, GrepResponseMetadata) {
+		t.Helper()
+		input := fmt.Sprintf(`{"pattern":"needle","path":%q,"max_results":2,"offset":%d}`, dir, offset)
+		resp, err := NewGrepTool().Run(context.Background(), ToolCall{Name: GrepToolName, Input: input})
+		require.NoError(t, err)
+		var meta GrepResponseMetadata
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+		return resp, meta
+	}
+
+	first, firstMeta := runGrep(0)
+	assert.Equal(t, 2, firstMeta.NumberOfMatches)
+	assert.Equal(t, 5, firstMeta.TotalEstimate)
+	assert.True(t, firstMeta.Truncated)
+	assert.Contains(t, first.Content, "file4.txt")
+	assert.Contains(t, first.Content, "file3.txt")
+	assert.Contains(t, first.Content, "offset=2")
+
+	second, secondMeta := runGrep(2)
+	assert.Equal(t, 2, secondMeta.NumberOfMatches)
+	assert.True(t, secondMeta.Truncated)
+	assert.Contains(t, second.Content, "file2.txt")
+	assert.Contains(t, second.Content, "file1.txt")
+	assert.NotContains(t, second.Content, "file4.txt")
+	assert.NotContains(t, second.Content, "file3.txt")
+
+	last, lastMeta := runGrep(4)
+	assert.Equal(t, 1, lastMeta.NumberOfMatches)
+	assert.False(t, lastMeta.Truncated)
+	assert.Contains(t, last.Content, "file0.txt")
+}
+
+func TestGrepTool_WithLineCount(t *testing.T) {
+	dir := createTempDirInWorkingDir(t, "grep_lines_*")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "short.txt"), []byte("needle\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "long.txt"), []byte(strings.Repeat("hay\n", 41)+"needle\n"), 0o644))
+
+	run := func(input string) string {
+		t.Helper()
+		resp, err := NewGrepTool().Run(context.Background(), ToolCall{Name: GrepToolName, Input: input})
+		require.NoError(t, err)
+		require.False(t, resp.IsError, resp.Content)
+		return resp.Content
+	}
+
+	plain := run(fmt.Sprintf(`{"pattern":"needle","path":%q}`, dir))
+	assert.Contains(t, plain, "short.txt:")
+	assert.NotContains(t, plain, "lines)", "line counts are off by default")
+
+	counted := run(fmt.Sprintf(`{"pattern":"needle","path":%q,"with_line_count":true}`, dir))
+	assert.Contains(t, counted, "short.txt (1 line):")
+	assert.Contains(t, counted, "long.txt (42 lines):")
+}
+
+func TestGrepTool_InvalidInclude(t *testing.T) {
+	resp, err := NewGrepTool().Run(context.Background(), ToolCall{Name: GrepToolName, Input: `{"pattern":"x","include":"*.{ts,tsx"}`})
+	require.NoError(t, err)
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, `invalid glob pattern: "*.{ts,tsx" has an unclosed '{'`)
+}
+
+func TestGrepTool_NegativeOffset(t *testing.T) {
+	resp, err := NewGrepTool().Run(context.Background(), ToolCall{Name: GrepToolName, Input: `{"pattern":"x","offset":-1}`})
+	require.NoError(t, err)
+	assert.True(t, resp.IsError)
+}
+
+func TestSearchWithRipgrep_CollectsInPathOrder(t *testing.T) {
+	if _, err := exec.LookPath("rg"); err != nil {
+		t.Skip("ripgrep not installed, skipping ripgrep-specific tests")
+	}
+	dir := createTempDirInWorkingDir(t, "grep_order_*")
+	for i := range 50 {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("file%02d.txt", i)), []byte("needle\n"), 0o644))
+	}
+
+	// With the limit reached, the same first files are collected every time
+	for range 3 {
+		matches, err := searchWithRipgrep(context.Background(), "needle", dir, "", nil, 5)
+		require.NoError(t, err)
+		var names []string
+		for _, m := range matches {
+			names = append(names, filepath.Base(m.path))
+		}
+		assert.Equal(t, []string{"file00.txt", "file01.txt", "file02.txt", "file03.txt", "file04.txt"}, names)
+	}
+}
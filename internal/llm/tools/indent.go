@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"strings"
+)
+
+const defaultIndentWidth = 4
+
+// indentStyle describes how a piece of text indents its lines.
+type indentStyle struct {
+	useTabs bool
+	width   int // spaces per level when useTabs is false
+}
+
+func (s indentStyle) unit() string {
+	if s.useTabs {
+		return "\t"
+	}
+	return strings.Repeat(" ", s.width)
+}
+
+// level returns the indentation level of the given leading whitespace.
+func (s indentStyle) level(lead string) int {
+	level, spaces := 0, 0
+	for _, r := range lead {
+		if r == '\t' {
+			level++
+			continue
+		}
+		spaces++
+	}
+	return level + spaces/s.width
+}
+
+// detectIndentStyle guesses whether text is indented with tabs or spaces and,
+// for spaces, the most common step between consecutive indented lines.
+// ok is false when text contains no indented lines.
+func detectIndentStyle(text string) (style indentStyle, ok bool) {
+	tabLines, spaceLines := 0, 0
+	steps := make(map[int]int)
+	prev := 0
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lead := leadingWhitespace(line)
+		if strings.HasPrefix(lead, "\t") {
+			tabLines++
+			continue
+		}
+		n := len(lead)
+		if n > 0 {
+			spaceLines++
+		}
+		if step := n - prev; step > 1 || step < -1 {
+			steps[max(step, -step)]++
+		}
+		prev = n
+	}
+
+	if tabLines == 0 && spaceLines == 0 {
+		return indentStyle{width: defaultIndentWidth}, false
+	}
+	if tabLines > spaceLines {
+		return indentStyle{useTabs: true, width: defaultIndentWidth}, true
+	}
+
+	width, best := defaultIndentWidth, 0
+	for step, count := range steps {
+		if step <= 8 && (count > best || (count == best && step < width)) {
+			width, best = step, count
+		}
+	}
+	return indentStyle{width: width}, true
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// reindentReplace replaces old with replacement in content, re-basing the
+// indentation of each inserted block onto the line it lands on. When old
+// starts at the beginning of a line (after optional indentation), the whole
+// line is re-indented; otherwise the first inserted line continues the
+// existing text and only the following lines are re-indented.
+func reindentReplace(content, old, replacement string, replaceAll bool) string {
+	fileStyle, _ := detectIndentStyle(content)
+
+	var sb strings.Builder
+	pos := 0
+	for {
+		idx := strings.Index(content[pos:], old)
+		if idx < 0 {
+			break
+		}
+		idx += pos
+
+		lineStart := strings.LastIndex(content[:idx], "\n") + 1
+		anchor := leadingWhitespace(content[lineStart:])
+		inline := lineStart < pos || strings.TrimSpace(content[lineStart:idx]) != ""
+		if inline {
+			sb.WriteString(content[pos:idx])
+		} else {
+			sb.WriteString(content[pos:lineStart])
+		}
+		sb.WriteString(reindentBlock(replacement, anchor, fileStyle, inline))
+
+		pos = idx + len(old)
+		if !replaceAll {
+			break
+		}
+	}
+	sb.WriteString(content[pos:])
+	return sb.String()
+}
+
+// reindentBlock shifts block so its least-indented line sits at anchor,
+// converting relative indentation to the target style. When inline is set the
+// first line is left unindented, as it continues an existing line.
+func reindentBlock(block, anchor string, target indentStyle, inline bool) string {
+	lines := strings.Split(block, "\n")
+
+	blockStyle, ok := detectIndentStyle(block)
+	if !ok {
+		blockStyle = target
+	}
+
+	first := 0
+	if inline {
+		first = 1
+	}
+	base := -1
+	for _, line := range lines[first:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if l := blockStyle.level(leadingWhitespace(line)); base < 0 || l < base {
+			base = l
+		}
+	}
+
+	unit := target.unit()
+	for i, line := range lines {
+		trimmed := strings.TrimLeft(line, " \t")
+		switch {
+		case i == 0 && inline:
+			lines[i] = trimmed
+		case trimmed == "":
+			lines[i] = ""
+		default:
+			rel := blockStyle.level(leadingWhitespace(line)) - base
+			lines[i] = anchor + strings.Repeat(unit, max(rel, 0)) + trimmed
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+)
+
+// Descriptions holds the localized text of a tool. Parameter names are part
+// of the tool's API and are never localized, only their descriptions.
+type Descriptions struct {
+	Description string
+	// Parameters maps parameter names to their localized descriptions.
+	Parameters map[string]string
+}
+
+// DescriptionResolver returns the descriptions of the named tool in locale,
+// reporting false when it has none.
+type DescriptionResolver func(locale, toolName string) (Descriptions, bool)
+
+var (
+	descriptionsMu        sync.RWMutex
+	localizedDescriptions = map[string]map[string]Descriptions{}
+	descriptionResolvers  []DescriptionResolver
+)
+
+// RegisterDescriptions adds the descriptions of tools, keyed by tool name,
+// for locale.
+func RegisterDescriptions(locale string, byTool map[string]Descriptions) {
+	locale = normalizeLocale(locale)
+	descriptionsMu.Lock()
+	defer descriptionsMu.Unlock()
+	if localizedDescriptions[locale] == nil {
+		localizedDescriptions[locale] = map[string]Descriptions{}
+	}
+	maps.Copy(localizedDescriptions[locale], byTool)
+}
+
+// RegisterDescriptionResolver adds a resolver consulted after the registered
+// descriptions, in the order resolvers were added.
+func RegisterDescriptionResolver(resolver DescriptionResolver) {
+	descriptionsMu.Lock()
+	defer descriptionsMu.Unlock()
+	descriptionResolvers = append(descriptionResolvers, resolver)
+}
+
+// resetDescriptions removes every registered description and resolver.
+func resetDescriptions() {
+	descriptionsMu.Lock()
+	defer descriptionsMu.Unlock()
+	localizedDescriptions = map[string]map[string]Descriptions{}
+	descriptionResolvers = nil
+}
+
+// normalizeLocale lowercases locale and uses "-" as the region separator, so
+// "es_MX" and "es-mx" are the same locale.
+func normalizeLocale(locale string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(locale)), "_", "-")
+}
+
+// lookupDescriptions returns the descriptions of toolName in locale, trying
+// the language without its region when the full locale has none.
+func lookupDescriptions(locale, toolName string) (Descriptions, bool) {
+	candidates := []string{locale}
+	if lang, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, lang)
+	}
+	descriptionsMu.RLock()
+	defer descriptionsMu.RUnlock()
+	for _, candidate := range candidates {
+		if d, ok := localizedDescriptions[candidate][toolName]; ok {
+			return d, true
+		}
+		for _, resolve := range descriptionResolvers {
+			if d, ok := resolve(candidate, toolName); ok {
+				return d, true
+			}
+		}
+	}
+	return Descriptions{}, false
+}
+
+// LocalizeInfo returns info with its descriptions in the configured locale.
+// Text without a translation stays in English, and the parameter names and
+// schema are unchanged.
+func LocalizeInfo(info ToolInfo) ToolInfo {
+	cfg := config.Get()
+	if cfg == nil {
+		return info
+	}
+	locale := normalizeLocale(cfg.Locale)
+	if locale == "" || locale == "en" || strings.HasPrefix(locale, "en-") {
+		return info
+	}
+	d, ok := lookupDescriptions(locale, info.Name)
+	if !ok {
+		return info
+	}
+
+	if d.Description != "" {
+		info.Description = d.Description
+	}
+	if len(d.Parameters) == 0 {
+		return info
+	}
+	params := maps.Clone(info.Parameters)
+	for name, description := range d.Parameters {
+		schema, ok := params[name].(map[string]any)
+		if !ok || description == "" {
+			continue
+		}
+		schema = maps.Clone(schema)
+		schema["description"] = description
+		params[name] = schema
+	}
+	info.Parameters = params
+	return info
+}
+
+// localizedTool describes a tool in the configured locale.
+type localizedTool struct {
+	BaseTool
+}
+
+// WithLocalizedDescriptions wraps tool so its Info is localized with
+// LocalizeInfo.
+func WithLocalizedDescriptions(tool BaseTool) BaseTool {
+	return &localizedTool{BaseTool: tool}
+}
+
+func (l *localizedTool) Info() ToolInfo {
+	return LocalizeInfo(l.BaseTool.Info())
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizeInfo(t *testing.T) {
+	cfg, err := config.Load(t.TempDir(), false)
+	require.NoError(t, err)
+	old := cfg.Locale
+	t.Cleanup(func() { cfg.Locale = old })
+	t.Cleanup(resetDescriptions)
+
+	edit := WithLocalizedDescriptions(NewEditTool(nil, nil, nil, nil))
+	english := NewEditTool(nil, nil, nil, nil).Info()
+	spanish := Descriptions{
+		Description: "Edita archivos reemplazando texto.",
+		Parameters: map[string]string{
+			"file_path":  "La ruta absoluta del archivo a modificar",
+			"unknown":    "ignored",
+			"new_string": "",
+		},
+	}
+
+	t.Run("english without a translation", func(t *testing.T) {
+		cfg.Locale = "es"
+		assert.Equal(t, english, edit.Info())
+	})
+
+	RegisterDescriptions("es", map[string]Descriptions{EditToolName: spanish})
+
+	t.Run("spanish when provided", func(t *testing.T) {
+		cfg.Locale = "es"
+		info := edit.Info()
+		assert.Equal(t, spanish.Description, info.Description)
+		assert.Equal(t, english.Name, info.Name)
+		assert.Equal(t, english.Required, info.Required)
+
+		filePath := info.Parameters["file_path"].(map[string]any)
+		assert.Equal(t, "La ruta absoluta del archivo a modificar", filePath["description"])
+		assert.Equal(t, "string", filePath["type"])
+		// Untranslated parameters stay in English and names are unchanged
+		assert.Equal(t, english.Parameters["old_string"], info.Parameters["old_string"])
+		assert.Equal(t, english.Parameters["new_string"], info.Parameters["new_string"])
+		assert.NotContains(t, info.Parameters, "unknown")
+		assert.Len(t, info.Parameters, len(english.Parameters))
+	})
+
+	t.Run("regional locale falls back to the language", func(t *testing.T) {
+		cfg.Locale = "es_MX"
+		assert.Equal(t, spanish.Description, edit.Info().Description)
+	})
+
+	t.Run("english for other locales", func(t *testing.T) {
+		for _, locale := range []string{"", "en", "fr"} {
+			cfg.Locale = locale
+			assert.Equal(t, english, edit.Info(), "locale %q", locale)
+		}
+	})
+
+	t.Run("resolver", func(t *testing.T) {
+		RegisterDescriptionResolver(func(locale, toolName string) (Descriptions, bool) {
+			if locale == "fr" && toolName == EditToolName {
+				return Descriptions{Description: "Modifie des fichiers."}, true
+			}
+			return Descriptions{}, false
+		})
+		cfg.Locale = "fr"
+		info := edit.Info()
+		assert.Equal(t, "Modifie des fichiers.", info.Description)
+		assert.Equal(t, english.Parameters, info.Parameters)
+	})
+
+	// The English description is never modified
+	assert.Equal(t, english, NewEditTool(nil, nil, nil, nil).Info())
+}
@@ -17,20 +17,28 @@ import (
 )
 
 type LSParams struct {
-	Path   string   `json:"path"`
-	Ignore []string `json:"ignore"`
+	Path            string   `json:"path"`
+	Ignore          []string `json:"ignore"`
+	NoDefaultIgnore bool     `json:"no_default_ignore,omitempty"`
+	ShowOpencode    bool     `json:"show_opencode,omitempty"`
+	GitStatus       bool     `json:"git_status,omitempty"`
 }
 
 type TreeNode struct {
-	Name     string      `json:"name"`
-	Path     string      `json:"path"`
-	Type     string      `json:"type"` // "file" or "directory"
-	Children []*TreeNode `json:"children,omitempty"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // "file" or "directory"
+	// GitStatus is the file's git status when requested with git_status:
+	// tracked, modified, untracked or ignored.
+	GitStatus string      `json:"git_status,omitempty"`
+	Children  []*TreeNode `json:"children,omitempty"`
 }
 
 type LSResponseMetadata struct {
 	NumberOfFiles int  `json:"number_of_files"`
 	Truncated     bool `json:"truncated"`
+	// Skipped counts the files left out for exceeding maxFileSizeForTools.
+	Skipped int `json:"skipped,omitempty"`
 }
 
 type lsTool struct {
@@ -49,15 +57,18 @@ WHEN TO USE THIS TOOL:
 
 HOW TO USE:
 - Provide a path to list (defaults to current working directory)
-- Optionally specify glob patterns to ignore
+- Optionally specify glob patterns to ignore; prefix a pattern with "!" to re-include paths an earlier pattern ignored (last match wins, as in .gitignore)
 - Results are displayed in a tree structure
 
 FEATURES:
 - Displays a hierarchical view of files and directories
 - Automatically skips hidden files/directories (starting with '.')
+- Set show_opencode=true to include the project's .opencode directory (config, history, skills)
+- Set git_status=true to mark each file as tracked, modified, untracked or ignored in a git repository
 - Automatically respects .gitignore rules when ripgrep is available
 - Skips common system directories like __pycache__
 - Can filter out files matching specific patterns
+- Also skips the project-wide ignore patterns from the tools.defaultIgnore setting (set no_default_ignore to include them)
 
 LIMITATIONS:
 - Results are limited to 1000 files
@@ -65,6 +76,7 @@ LIMITATIONS:
 - Does not show file sizes or permissions
 - Cannot recursively list all directories in a large project
 - Falls back to built-in walker if ripgrep is not installed (no .gitignore support in fallback mode)
+- When maxFileSizeForTools is configured, larger files are left out and counted in a note
 
 TIPS:
 - You should generally prefer the Glob and Grep tools if you know which directories or file patterns to search for
@@ -80,6 +92,7 @@ func NewLsTool(cfg config.Configurator) BaseTool {
 func (l *lsTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        LSToolName,
+		ReadOnly:    true,
 		Description: lsDescription,
 		Parameters: map[string]any{
 			"path": map[string]any{
@@ -88,23 +101,48 @@ func (l *lsTool) Info() ToolInfo {
 			},
 			"ignore": map[string]any{
 				"type":        "array",
-				"description": "List of glob patterns to ignore",
+				"description": "List of glob patterns to ignore. A leading \"!\" re-includes matching paths; the last matching pattern wins",
 				"items": map[string]any{
 					"type": "string",
 				},
 			},
+			"no_default_ignore": map[string]any{
+				"type":        "boolean",
+				"description": "If true, the configured default ignore patterns are not applied. Default is false.",
+			},
+			"show_opencode": map[string]any{
+				"type":        "boolean",
+				"description": "If true, the contents of the .opencode directory are included. Default is false.",
+			},
+			"git_status": map[string]any{
+				"type":        "boolean",
+				"description": "If true, each file is marked with its git status (tracked, modified, untracked or ignored). Default is false.",
+			},
 		},
 		Required: []string{"path"},
+		Examples: []ToolExample{
+			{
+				Description: "List the project root",
+				Input:       `{"path": "."}`,
+			},
+			{
+				Description: "List a source directory without test files",
+				Input:       `{"path": "/project/internal", "ignore": ["*_test.go"]}`,
+			},
+		},
 	}
 }
 
 func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params LSParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+	}
+	if err := validateGlobs(params.Ignore...); err != nil {
+		return NewTextErrorResponse(err.Error()), nil
 	}
 
-	searchPath := params.Path
+	searchPath := resolveCallPath(ctx, params.Path)
 	if searchPath == "" {
 		searchPath = l.cfg.WorkingDirectory()
 	}
@@ -113,34 +151,76 @@ func (l *lsTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	}
 
 	if _, err := os.Stat(searchPath); os.IsNotExist(err) {
-		return NewTextErrorResponse("path does not exist: " + searchPath), nil
+		return NewNotFoundResponse("path does not exist: "+searchPath, searchPath), nil
 	}
 
-	files, truncated, err := listDirectory(ctx, searchPath, params.Ignore, MaxLSFiles)
+	ignore := ignorePatterns(params.Ignore, params.NoDefaultIgnore)
+	files, truncated, err := listDirectory(ctx, searchPath, ignore, MaxLSFiles, followSymlinks())
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error listing directory: %w", err)
 	}
+	if params.ShowOpencode {
+		files, truncated, err = appendOpencodeDir(files, truncated, searchPath, ignore, MaxLSFiles, followSymlinks())
+		if err != nil {
+			return NewEmptyResponse(), fmt.Errorf("error listing .opencode directory: %w", err)
+		}
+	}
+	files, skipped := skipLargeFiles(files)
 
 	tree := createFileTree(files)
+	if params.GitStatus {
+		if statuses := gitFileStatuses(ctx, searchPath); statuses != nil {
+			annotateGitStatus(tree, statuses)
+		}
+	}
 	output := printTree(tree, searchPath)
 
 	if truncated {
 		output = fmt.Sprintf("There are more than %d files in the directory. Use a more specific path or use the Glob tool to find specific files. The first %d files and directories are included below:\n\n%s", MaxLSFiles, MaxLSFiles, output)
 	}
+	output += skippedLargeFilesNote(skipped)
 
 	return WithResponseMetadata(
 		NewTextResponse(output),
 		LSResponseMetadata{
 			NumberOfFiles: len(files),
 			Truncated:     truncated,
+			Skipped:       skipped,
 		},
 	), nil
 }
 
 var errRipgrepNotFound = errors.New("ripgrep not found")
 
-func listDirectory(ctx context.Context, initialPath string, ignorePatterns []string, limit int) ([]string, bool, error) {
-	files, truncated, err := listDirectoryWithRipgrep(ctx, initialPath, ignorePatterns, limit)
+// opencodeDirName is the project directory holding OpenCode's own config,
+// history and skills. It is hidden from listings unless explicitly requested.
+const opencodeDirName = ".opencode"
+
+// appendOpencodeDir adds the contents of the .opencode directory directly
+// under rootPath to files, which the regular listing always leaves out.
+func appendOpencodeDir(files []string, truncated bool, rootPath string, ignorePatterns []string, limit int, followSymlinks bool) ([]string, bool, error) {
+	dir := filepath.Join(rootPath, opencodeDirName)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return files, truncated, nil
+	}
+
+	extra, extraTruncated, err := listDirectoryWithWalk(dir, ignorePatterns, limit, followSymlinks)
+	if err != nil {
+		return files, truncated, err
+	}
+
+	files = append(files, dir+string(filepath.Separator))
+	files = append(files, extra...)
+	sort.Strings(files)
+	if len(files) > limit {
+		files = files[:limit]
+		truncated = true
+	}
+	return files, truncated || extraTruncated, nil
+}
+
+func listDirectory(ctx context.Context, initialPath string, ignorePatterns []string, limit int, followSymlinks bool) ([]string, bool, error) {
+	files, truncated, err := listDirectoryWithRipgrep(ctx, initialPath, ignorePatterns, limit, followSymlinks)
 	if err == nil {
 		return files, truncated, nil
 	}
@@ -149,21 +229,29 @@ func listDirectory(ctx context.Context, initialPath string, ignorePatterns []str
 	} else {
 		logging.Debug("ls: ripgrep failed, falling back to filepath.Walk", "error", err)
 	}
-	return listDirectoryWithWalk(initialPath, ignorePatterns, limit)
+	return listDirectoryWithWalk(initialPath, ignorePatterns, limit, followSymlinks)
 }
 
-func listDirectoryWithRipgrep(ctx context.Context, initialPath string, ignorePatterns []string, limit int) ([]string, bool, error) {
+func listDirectoryWithRipgrep(ctx context.Context, initialPath string, ignorePatterns []string, limit int, followSymlinks bool) ([]string, bool, error) {
 	rgPath, err := exec.LookPath("rg")
 	if err != nil {
 		return nil, false, errRipgrepNotFound
 	}
 
 	args := []string{"--files"}
+	if followSymlinks {
+		args = append(args, "-L")
+	}
 	// Add default ignore for __pycache__
 	args = append(args, "--glob", "!__pycache__")
 	args = append(args, "--glob", "!**/__pycache__")
-	for _, pattern := range ignorePatterns {
-		args = append(args, "--glob", "!"+pattern)
+	// ripgrep treats a glob without "!" as an include filter, so negated
+	// ignore patterns are evaluated on the results instead
+	negations := hasNegatedPattern(ignorePatterns)
+	if !negations {
+		for _, pattern := range ignorePatterns {
+			args = append(args, "--glob", "!"+pattern)
+		}
 	}
 	args = append(args, initialPath)
 
@@ -193,6 +281,11 @@ func listDirectoryWithRipgrep(ctx context.Context, initialPath string, ignorePat
 		if line == "" {
 			continue
 		}
+		if negations {
+			if rel, relErr := filepath.Rel(initialPath, line); relErr == nil && matchesIgnorePattern(rel, ignorePatterns) {
+				continue
+			}
+		}
 		results = append(results, line)
 	}
 
@@ -207,14 +300,14 @@ func listDirectoryWithRipgrep(ctx context.Context, initialPath string, ignorePat
 	return results, truncated, nil
 }
 
-func listDirectoryWithWalk(initialPath string, ignorePatterns []string, limit int) ([]string, bool, error) {
+func listDirectoryWithWalk(initialPath string, ignorePatterns []string, limit int, followSymlinks bool) ([]string, bool, error) {
 	var results []string
 	truncated := false
 
 	// Clean and resolve the initial path to handle trailing slashes consistently
 	initialPath = filepath.Clean(initialPath)
 
-	err := filepath.Walk(initialPath, func(path string, info os.FileInfo, err error) error {
+	err := fileutil.Walk(initialPath, followSymlinks, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // Skip files we don't have permission to access
 		}
@@ -232,6 +325,12 @@ func listDirectoryWithWalk(initialPath string, ignorePatterns []string, limit in
 		// even if it's in a location like /tmp that would normally be ignored
 		if shouldSkip(cleanPath, ignorePatterns, initialPath) {
 			if info.IsDir() {
+				// Keep walking when a negated pattern may re-include a file
+				// below; the directory still shows up as that file's parent
+				if rel, relErr := filepath.Rel(initialPath, cleanPath); relErr == nil &&
+					!shouldSkip(cleanPath, nil, initialPath) && negationMayMatchUnder(rel, ignorePatterns) {
+					return nil
+				}
 				return filepath.SkipDir
 			}
 			return nil
@@ -271,8 +370,6 @@ func shouldSkip(path string, ignorePatterns []string, rootPath string) bool {
 		checkPath = path
 	}
 
-	// Check custom ignore patterns
-	base := filepath.Base(checkPath)
 	// Use fileutil.SkipHidden for consistent hidden/ignored detection
 	if fileutil.SkipHidden(checkPath) {
 		return true
@@ -282,14 +379,7 @@ func shouldSkip(path string, ignorePatterns []string, rootPath string) bool {
 		return true
 	}
 
-	for _, pattern := range ignorePatterns {
-		matched, err := filepath.Match(pattern, base)
-		if err == nil && matched {
-			return true
-		}
-	}
-
-	return false
+	return matchesIgnorePattern(checkPath, ignorePatterns)
 }
 
 func createFileTree(sortedPaths []string) []*TreeNode {
@@ -298,9 +388,11 @@ func createFileTree(sortedPaths []string) []*TreeNode {
 
 	// Build a set of all paths for efficient directory detection
 	// A path is a directory if it's a prefix of any other path
+	// Keys drop the leading separator so they match the node paths built
+	// below from the split path segments
 	pathSet := make(map[string]bool)
 	for _, p := range sortedPaths {
-		pathSet[p] = true
+		pathSet[strings.TrimPrefix(p, string(filepath.Separator))] = true
 	}
 
 	// Helper to check if a path is a directory (has children)
@@ -377,10 +469,22 @@ func createFileTree(sortedPaths []string) []*TreeNode {
 	return root
 }
 
+// annotateGitStatus sets the git status of the file nodes in tree. Node paths
+// are the listed paths without their leading separator.
+func annotateGitStatus(tree []*TreeNode, statuses *gitStatuses) {
+	for _, node := range tree {
+		if node.Type == "directory" {
+			annotateGitStatus(node.Children, statuses)
+			continue
+		}
+		node.GitStatus = statuses.status(string(filepath.Separator) + node.Path)
+	}
+}
+
 func printTree(tree []*TreeNode, rootPath string) string {
 	var result strings.Builder
 
-	fmt.Fprintf(&result, "- %s%s\n", rootPath, string(filepath.Separator))
+	fmt.Fprintf(&result, "- %s/\n", fileutil.NormalizePathForAI(rootPath))
 
 	for _, node := range tree {
 		printNode(&result, node, 1)
@@ -394,7 +498,11 @@ func printNode(builder *strings.Builder, node *TreeNode, level int) {
 
 	nodeName := node.Name
 	if node.Type == "directory" {
-		nodeName += string(filepath.Separator)
+		nodeName += "/"
+	}
+
+	if node.GitStatus != "" {
+		nodeName += " [" + node.GitStatus + "]"
 	}
 
 	fmt.Fprintf(builder, "%s- %s\n", indent, nodeName)
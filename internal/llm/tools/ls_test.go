@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	mock_config "github.com/MerrukTechnology/OpenCode-Native/internal/config/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -214,6 +215,194 @@ func TestLsTool_Run(t *testing.T) {
 	}
 }
 
+func TestLsTool_ShowOpencode(t *testing.T) {
+	tempDir := setupTestDir(t)
+	defer os.RemoveAll(tempDir)
+
+	opencodeDir := filepath.Join(tempDir, ".opencode")
+	require.NoError(t, os.MkdirAll(filepath.Join(opencodeDir, "skills", "review"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(opencodeDir, "skills", "review", "SKILL.md"), []byte("skill"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(opencodeDir, "config.json"), []byte("{}"), 0o644))
+
+	ctrl := gomock.NewController(t)
+	cfg := mock_config.NewMockConfigurator(ctrl)
+	cfg.EXPECT().WorkingDirectory().Times(0)
+	tool := NewLsTool(cfg)
+
+	t.Run("hidden by default", func(t *testing.T) {
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: tempDir}))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Content, "file1.txt")
+		assert.NotContains(t, resp.Content, ".opencode")
+		assert.NotContains(t, resp.Content, "SKILL.md")
+	})
+
+	t.Run("included with show_opencode", func(t *testing.T) {
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: tempDir, ShowOpencode: true}))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Content, "file1.txt")
+		assert.Contains(t, resp.Content, "- .opencode/")
+		assert.Contains(t, resp.Content, "config.json")
+		assert.Contains(t, resp.Content, "SKILL.md")
+		// Other hidden entries stay hidden
+		assert.NotContains(t, resp.Content, ".hidden_root_file.txt")
+	})
+
+	t.Run("no .opencode directory", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(opencodeDir))
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: tempDir, ShowOpencode: true}))
+		require.NoError(t, err)
+		assert.False(t, resp.IsError)
+		assert.NotContains(t, resp.Content, ".opencode")
+	})
+}
+
+func TestLsTool_IgnoreNegation(t *testing.T) {
+	tempDir := setupTestDir(t)
+	defer os.RemoveAll(tempDir)
+
+	// dist/ is skipped by the built-in ignores in the walk fallback, so use
+	// a directory only the ignore param excludes
+	publicDir := filepath.Join(tempDir, "public")
+	require.NoError(t, os.MkdirAll(filepath.Join(publicDir, "assets"), 0o755))
+	for _, name := range []string{"index.html", "bundle.js", filepath.Join("assets", "app.css")} {
+		require.NoError(t, os.WriteFile(filepath.Join(publicDir, name), []byte("x"), 0o644))
+	}
+
+	ctrl := gomock.NewController(t)
+	cfg := mock_config.NewMockConfigurator(ctrl)
+	cfg.EXPECT().WorkingDirectory().Times(0)
+	tool := NewLsTool(cfg)
+
+	resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{
+		Path:   tempDir,
+		Ignore: []string{"public/**", "!public/index.html"},
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "file1.txt")
+	assert.Contains(t, resp.Content, "index.html")
+	assert.NotContains(t, resp.Content, "bundle.js")
+	assert.NotContains(t, resp.Content, "app.css")
+	assert.NotContains(t, resp.Content, "assets")
+}
+
+func TestLsTool_SkipsLargeFiles(t *testing.T) {
+	tempDir := setupTestDir(t)
+	defer os.RemoveAll(tempDir)
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "dataset.bin"), make([]byte, 4096), 0o644))
+
+	cfg := config.Get()
+	old := cfg.MaxFileSizeForTools
+	cfg.MaxFileSizeForTools = 1024
+	t.Cleanup(func() { cfg.MaxFileSizeForTools = old })
+
+	ctrl := gomock.NewController(t)
+	mockCfg := mock_config.NewMockConfigurator(ctrl)
+	mockCfg.EXPECT().WorkingDirectory().Times(0)
+
+	resp, err := NewLsTool(mockCfg).Run(context.Background(), newTestToolCall(LSParams{Path: tempDir}))
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "file1.txt")
+	assert.Contains(t, resp.Content, "dir1")
+	assert.NotContains(t, resp.Content, "dataset.bin")
+	assert.Contains(t, resp.Content, "1 files larger than maxFileSizeForTools (1024 bytes) were skipped")
+}
+
+func TestLsTool_GitStatus(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	gitRun(t, repo, "init", "-q")
+	files := map[string]string{
+		"clean.go":      "package demo\n",
+		"modified.go":   "package demo\n",
+		"pkg/nested.go": "package pkg\n",
+		".gitignore":    "*.log\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0o644))
+	}
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-q", "-m", "Initial")
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "modified.go"), []byte("package demo\n\nvar x = 1\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "pkg", "new.go"), []byte("package pkg\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "debug.log"), []byte("log\n"), 0o644))
+
+	ctrl := gomock.NewController(t)
+	cfg := mock_config.NewMockConfigurator(ctrl)
+	tool := NewLsTool(cfg)
+
+	t.Run("annotates each file", func(t *testing.T) {
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: repo, GitStatus: true}))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Content, "- clean.go [tracked]")
+		assert.Contains(t, resp.Content, "- modified.go [modified]")
+		assert.Contains(t, resp.Content, "- nested.go [tracked]")
+		assert.Contains(t, resp.Content, "- new.go [untracked]")
+		assert.Contains(t, resp.Content, "- pkg/\n")
+	})
+
+	t.Run("subdirectory of the repository", func(t *testing.T) {
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: filepath.Join(repo, "pkg"), GitStatus: true}))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Content, "- nested.go [tracked]")
+		assert.Contains(t, resp.Content, "- new.go [untracked]")
+	})
+
+	t.Run("ignored files", func(t *testing.T) {
+		statuses := gitFileStatuses(context.Background(), repo)
+		require.NotNil(t, statuses)
+		assert.Equal(t, gitStatusIgnored, statuses.status(filepath.Join(repo, "debug.log")))
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: repo}))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Content, "- modified.go\n")
+		assert.NotContains(t, resp.Content, "[tracked]")
+	})
+
+	t.Run("outside a repository", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "plain.go"), []byte("package main\n"), 0o644))
+		resp, err := tool.Run(context.Background(), newTestToolCall(LSParams{Path: dir, GitStatus: true}))
+		require.NoError(t, err)
+		assert.Contains(t, resp.Content, "- plain.go\n")
+	})
+}
+
+func TestMatchesIgnorePattern_Negation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		path     string
+		patterns []string
+		expected bool
+	}{
+		{"negation re-includes", "dist/index.html", []string{"dist/**", "!dist/index.html"}, false},
+		{"siblings stay excluded", "dist/bundle.js", []string{"dist/**", "!dist/index.html"}, true},
+		{"last match wins", "dist/index.html", []string{"!dist/index.html", "dist/**"}, true},
+		{"base name negation", "src/keep.min.js", []string{"*.min.js", "!keep.min.js"}, false},
+		{"negation alone ignores nothing", "a.txt", []string{"!a.txt"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, matchesIgnorePattern(tc.path, tc.patterns))
+		})
+	}
+}
+
+func TestNegationMayMatchUnder(t *testing.T) {
+	patterns := []string{"dist/**", "!dist/index.html"}
+	assert.True(t, negationMayMatchUnder("dist", patterns))
+	assert.False(t, negationMayMatchUnder("dist/assets", patterns))
+	assert.False(t, negationMayMatchUnder("build", patterns))
+	assert.True(t, negationMayMatchUnder("build", []string{"build/**", "!*.html"}))
+	assert.False(t, negationMayMatchUnder("dist", []string{"dist/**"}))
+}
+
 func TestShouldSkip(t *testing.T) {
 	testCases := []struct {
 		name           string
@@ -409,7 +598,7 @@ func TestListDirectory(t *testing.T) {
 	}
 
 	t.Run("lists files with no limit", func(t *testing.T) {
-		files, truncated, err := listDirectory(context.Background(), tempDir, []string{}, 1000)
+		files, truncated, err := listDirectory(context.Background(), tempDir, []string{}, 1000, false)
 		require.NoError(t, err)
 		assert.False(t, truncated)
 
@@ -435,14 +624,14 @@ func TestListDirectory(t *testing.T) {
 	})
 
 	t.Run("respects limit and returns truncated flag", func(t *testing.T) {
-		files, truncated, err := listDirectory(context.Background(), tempDir, []string{}, 2)
+		files, truncated, err := listDirectory(context.Background(), tempDir, []string{}, 2, false)
 		require.NoError(t, err)
 		assert.True(t, truncated)
 		assert.Len(t, files, 2)
 	})
 
 	t.Run("respects ignore patterns", func(t *testing.T) {
-		files, truncated, err := listDirectory(context.Background(), tempDir, []string{"*.txt"}, 1000)
+		files, truncated, err := listDirectory(context.Background(), tempDir, []string{"*.txt"}, 1000, false)
 		require.NoError(t, err)
 		assert.False(t, truncated)
 
@@ -499,7 +688,7 @@ func TestListDirectoryWithRipgrep(t *testing.T) {
 	require.NoError(t, os.WriteFile(filepath.Join(tempDir, ".gitignore"), []byte("build/\n"), 0o644))
 
 	t.Run("respects gitignore", func(t *testing.T) {
-		files, truncated, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 1000)
+		files, truncated, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 1000, false)
 		require.NoError(t, err)
 		assert.False(t, truncated)
 
@@ -520,7 +709,7 @@ func TestListDirectoryWithRipgrep(t *testing.T) {
 	})
 
 	t.Run("user ignore patterns become glob flags", func(t *testing.T) {
-		files, _, err := listDirectoryWithRipgrep(context.Background(), tempDir, []string{"*.md"}, 1000)
+		files, _, err := listDirectoryWithRipgrep(context.Background(), tempDir, []string{"*.md"}, 1000, false)
 		require.NoError(t, err)
 
 		for _, f := range files {
@@ -529,14 +718,14 @@ func TestListDirectoryWithRipgrep(t *testing.T) {
 	})
 
 	t.Run("truncation at limit returns lexicographically earliest entries", func(t *testing.T) {
-		files, truncated, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 2)
+		files, truncated, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 2, false)
 		require.NoError(t, err)
 		assert.True(t, truncated)
 		assert.Len(t, files, 2)
 		assert.True(t, sort.StringsAreSorted(files), "truncated results should be sorted")
 
 		// The 2 returned files must be the lexicographically smallest from the full set
-		allFiles, _, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 1000)
+		allFiles, _, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 1000, false)
 		require.NoError(t, err)
 		sort.Strings(allFiles)
 		assert.Equal(t, allFiles[:2], files, "truncated results should be the first entries in sorted order")
@@ -547,14 +736,14 @@ func TestListDirectoryWithRipgrep(t *testing.T) {
 		require.NoError(t, err)
 		defer os.RemoveAll(emptyDir)
 
-		files, truncated, err := listDirectoryWithRipgrep(context.Background(), emptyDir, nil, 1000)
+		files, truncated, err := listDirectoryWithRipgrep(context.Background(), emptyDir, nil, 1000, false)
 		require.NoError(t, err)
 		assert.False(t, truncated)
 		assert.Empty(t, files)
 	})
 
 	t.Run("output is sorted", func(t *testing.T) {
-		files, _, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 1000)
+		files, _, err := listDirectoryWithRipgrep(context.Background(), tempDir, nil, 1000, false)
 		require.NoError(t, err)
 		assert.True(t, sort.StringsAreSorted(files), "ripgrep output should be sorted")
 	})
@@ -587,7 +776,7 @@ func TestListDirectoryWithWalk(t *testing.T) {
 	}
 
 	t.Run("commonIgnored list is applied in walk fallback", func(t *testing.T) {
-		files, _, err := listDirectoryWithWalk(tempDir, nil, 1000)
+		files, _, err := listDirectoryWithWalk(tempDir, nil, 1000, false)
 		require.NoError(t, err)
 
 		containsPath := func(paths []string, substr string) bool {
@@ -610,3 +799,55 @@ func TestListDirectoryWithWalk(t *testing.T) {
 		assert.False(t, containsPath(files, ".hidden"), "hidden dirs should be skipped")
 	})
 }
+
+func TestListDirectoryFollowSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	project := filepath.Join(tempDir, "project")
+	shared := filepath.Join(tempDir, "shared")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(project, "src"), 0o755))
+	require.NoError(t, os.MkdirAll(shared, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(project, "src", "main.go"), []byte("main"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "lib.go"), []byte("lib"), 0o644))
+	if err := os.Symlink(shared, filepath.Join(project, "linked")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	require.NoError(t, os.Symlink(project, filepath.Join(shared, "loop")))
+
+	linkedFile := filepath.Join(project, "linked", "lib.go")
+
+	listers := map[string]func(follow bool) ([]string, error){
+		"walk": func(follow bool) ([]string, error) {
+			files, _, err := listDirectoryWithWalk(project, nil, 1000, follow)
+			return files, err
+		},
+		"ripgrep": func(follow bool) ([]string, error) {
+			files, _, err := listDirectoryWithRipgrep(context.Background(), project, nil, 1000, follow)
+			return files, err
+		},
+		"glob": func(follow bool) ([]string, error) {
+			files, _, err := globFiles("**/*.go", project, 1000, follow, nil)
+			return files, err
+		},
+	}
+
+	for name, list := range listers {
+		t.Run(name, func(t *testing.T) {
+			if name == "ripgrep" {
+				if _, err := exec.LookPath("rg"); err != nil {
+					t.Skip("ripgrep not installed, skipping ripgrep-specific tests")
+				}
+			}
+
+			files, err := list(false)
+			require.NoError(t, err)
+			assert.Contains(t, files, filepath.Join(project, "src", "main.go"))
+			assert.NotContains(t, files, linkedFile, "symlinked subtree should be skipped when not following")
+
+			files, err = list(true)
+			require.NoError(t, err)
+			assert.Contains(t, files, filepath.Join(project, "src", "main.go"))
+			assert.Contains(t, files, linkedFile, "symlinked subtree should be included when following")
+		})
+	}
+}
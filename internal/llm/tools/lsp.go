@@ -71,6 +71,7 @@ func NewLspTool(lspService lsp.LspService) BaseTool {
 func (t *lspTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        LSPToolName,
+		ReadOnly:    true,
 		Description: lspDescription,
 		Parameters: map[string]any{
 			"operation": map[string]any{
@@ -98,20 +99,20 @@ func (t *lspTool) Info() ToolInfo {
 func (t *lspTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params LspParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if !validOperations[params.Operation] {
 		return NewTextErrorResponse("invalid operation: " + params.Operation), nil
 	}
 
-	file, err := ValidatePathInWorkingDirectory(params.FilePath)
+	file, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
 	if err != nil {
-		return NewTextErrorResponse(err.Error()), nil
+		return NewPathErrorResponse(err, params.FilePath), nil
 	}
 
 	if _, err := os.Stat(file); os.IsNotExist(err) {
-		return NewTextErrorResponse("file not found: " + file), nil
+		return NewNotFoundResponse("file not found: "+file, file), nil
 	}
 
 	// Find LSP clients that handle this file type
@@ -103,7 +103,7 @@ func NewMultiEditTool(lspService lsp.LspService, permissions permission.Service,
 }
 
 func (m *multiEditTool) Info() ToolInfo {
-	return ToolInfo{
+	return withPreconditions(ToolInfo{
 		Name:        MultiEditToolName,
 		Description: multiEditDescription,
 		Parameters: map[string]any{
@@ -135,29 +135,39 @@ func (m *multiEditTool) Info() ToolInfo {
 			},
 		},
 		Required: []string{"file_path", "edits"},
-	}
+		Examples: []ToolExample{
+			{
+				Description: "Make two sequential changes to one file",
+				Input:       `{"file_path": "/project/config.go", "edits": [{"old_string": "Timeout: 10", "new_string": "Timeout: 30"}, {"old_string": "Retries: 1", "new_string": "Retries: 3"}]}`,
+			},
+			{
+				Description: "Rename a type and update a single call site",
+				Input:       `{"file_path": "/project/store.go", "edits": [{"old_string": "memStore", "new_string": "memoryStore", "replace_all": true}, {"old_string": "return &memoryStore{}", "new_string": "return &memoryStore{items: map[string]string{}}"}]}`,
+			},
+		},
+	}, ReadFilePrecondition)
 }
 
 func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params MultiEditParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("invalid parameters"), err
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
 	}
 
 	if params.FilePath == "" {
-		return NewTextErrorResponse("file_path is required"), nil
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
 	}
 
 	if len(params.Edits) == 0 {
 		return NewTextErrorResponse("edits array must not be empty"), nil
 	}
 
-	params.FilePath = fileutil.ResolvePath(params.FilePath, config.WorkingDirectory())
+	params.FilePath = fileutil.ResolvePath(params.FilePath, callDirectory(ctx))
 
 	fileInfo, err := os.Stat(params.FilePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewTextErrorResponse("file not found: " + params.FilePath), nil
+			return NewNotFoundResponse("file not found: "+params.FilePath, params.FilePath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
 	}
@@ -166,12 +176,20 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 		return NewTextErrorResponse("path is a directory, not a file: " + params.FilePath), nil
 	}
 
-	if getLastReadTime(params.FilePath).IsZero() {
+	if isEnvFile(params.FilePath) {
+		return envFileWriteError(params.FilePath), nil
+	}
+
+	if response, err := checkProtectedFile(ctx, m.permissions, MultiEditToolName, params.FilePath); err != nil || response.IsError {
+		return response, err
+	}
+
+	lastRead := getLastReadTime(params.FilePath)
+	if lastRead.IsZero() {
 		return NewTextErrorResponse("you must read the file before editing it. Use the Read tool first"), nil
 	}
 
 	modTime := fileInfo.ModTime()
-	lastRead := getLastReadTime(params.FilePath)
 	if modTime.After(lastRead) {
 		return NewTextErrorResponse(
 			fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
@@ -185,56 +203,35 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 	}
 
 	oldContent := strings.ReplaceAll(string(content), "\r\n", "\n")
-	currentContent := oldContent
-	perEditDiffs := make([]MultiEditPermissionEdit, 0, len(params.Edits))
-
-	for i, edit := range params.Edits {
-		if edit.OldString == "" {
-			return NewTextErrorResponse(fmt.Sprintf("edit %d: old_string cannot be empty in multiedit", i+1)), nil
-		}
-
-		if edit.OldString == edit.NewString {
-			return NewTextErrorResponse(fmt.Sprintf("edit %d: old_string and new_string must be different", i+1)), nil
-		}
-
-		normalizedOldString := strings.ReplaceAll(edit.OldString, "\r\n", "\n")
-		normalizedNewString := strings.ReplaceAll(edit.NewString, "\r\n", "\n")
-
-		index := strings.Index(currentContent, normalizedOldString)
-		if index == -1 {
-			return NewTextErrorResponse(fmt.Sprintf("edit %d: old_string not found in file. Make sure it matches exactly, including whitespace and line breaks", i+1)), nil
-		}
-
-		lineNumber := strings.Count(currentContent[:index], "\n") + 1
-		beforeEdit := currentContent
+	currentContent, applied, err := applyMultiEdits(oldContent, params.Edits)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
 
-		if edit.ReplaceAll {
-			currentContent = strings.ReplaceAll(currentContent, normalizedOldString, normalizedNewString)
-		} else {
-			lastIndex := strings.LastIndex(currentContent, normalizedOldString)
-			if index != lastIndex {
-				count := strings.Count(currentContent, normalizedOldString)
-				return NewTextErrorResponse(fmt.Sprintf("edit %d: old_string appears %d times in the file. Please provide more surrounding context lines in old_string to make the match unique, or use replace_all=true to replace all occurrences", i+1, count)), nil
-			}
-			currentContent = currentContent[:index] + normalizedNewString + currentContent[index+len(normalizedOldString):]
-		}
+	if oldContent == currentContent {
+		return NewTextErrorResponse("no changes were made. All edits resulted in the same content."), nil
+	}
 
-		editDiff, _, _ := diff.GenerateDiff(beforeEdit, currentContent, params.FilePath)
+	// Per-edit diffs are only needed once every edit has applied cleanly
+	perEditDiffs := make([]MultiEditPermissionEdit, 0, len(applied))
+	for _, a := range applied {
+		editDiff, _, _ := diff.GenerateDiff(a.before, a.after, params.FilePath)
 		perEditDiffs = append(perEditDiffs, MultiEditPermissionEdit{
 			Diff:       editDiff,
-			LineNumber: lineNumber,
+			LineNumber: a.lineNumber,
 		})
 	}
 
-	if oldContent == currentContent {
-		return NewTextErrorResponse("no changes were made. All edits resulted in the same content."), nil
-	}
-
 	sessionID, messageID := GetContextValues(ctx)
 	if sessionID == "" || messageID == "" {
 		return NewEmptyResponse(), errors.New("session ID and message ID are required")
 	}
 
+	syntaxResponse, syntaxWarning := checkSyntax(params.FilePath, oldContent, currentContent)
+	if syntaxResponse.IsError {
+		return syntaxResponse, nil
+	}
+
 	combinedDiff, additions, removals := diff.GenerateDiff(
 		oldContent,
 		currentContent,
@@ -246,7 +243,7 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 	if strings.HasPrefix(params.FilePath, rootDir) {
 		permissionPath = rootDir
 	}
-	action := m.registry.EvaluatePermission(GetAgentID(ctx), MultiEditToolName, params.FilePath)
+	action := evaluateFilePermission(ctx, m.registry, MultiEditToolName, params.FilePath)
 	switch action {
 	case permission.ActionAllow:
 		// Allowed by config
@@ -271,7 +268,7 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 		}
 	}
 
-	err = os.WriteFile(params.FilePath, []byte(currentContent), 0o644)
+	err = writeEditedFile(ctx, params.FilePath, []byte(currentContent))
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
 	}
@@ -297,8 +294,14 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 	recordFileWrite(params.FilePath)
 	recordFileRead(params.FilePath)
 
+	checks := make([]editCheck, len(params.Edits))
+	for i, edit := range params.Edits {
+		checks[i] = editCheck{newString: edit.NewString}
+	}
+	warning := verifyEditWritten(params.FilePath, currentContent, checks...) + syntaxWarning
+
 	response := WithResponseMetadata(
-		NewTextResponse(fmt.Sprintf("%d edits applied to file: %s", len(params.Edits), params.FilePath)),
+		NewTextResponse(fmt.Sprintf("%d edits applied to file: %s%s", len(params.Edits), params.FilePath, warning)),
 		MultiEditResponseMetadata{
 			Diff:      combinedDiff,
 			Additions: additions,
@@ -312,3 +315,52 @@ func (m *multiEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, e
 	response.Content = text
 	return response, nil
 }
+
+// appliedEdit is the content before and after one edit of a multiedit.
+type appliedEdit struct {
+	before     string
+	after      string
+	lineNumber int
+}
+
+// applyMultiEdits applies edits to content in order, each against the result
+// of the previous one. The returned error names the first edit that failed;
+// on error no edits are applied.
+func applyMultiEdits(content string, edits []MultiEditItem) (string, []appliedEdit, error) {
+	applied := make([]appliedEdit, 0, len(edits))
+	for i, edit := range edits {
+		if edit.OldString == "" {
+			return "", nil, fmt.Errorf("edit %d: old_string cannot be empty in multiedit", i+1)
+		}
+
+		if edit.OldString == edit.NewString {
+			return "", nil, fmt.Errorf("edit %d: old_string and new_string must be different", i+1)
+		}
+
+		oldString := strings.ReplaceAll(edit.OldString, "\r\n", "\n")
+		newString := strings.ReplaceAll(edit.NewString, "\r\n", "\n")
+
+		index := strings.Index(content, oldString)
+		if index == -1 {
+			return "", nil, fmt.Errorf("edit %d: old_string not found in file. Make sure it matches exactly, including whitespace and line breaks", i+1)
+		}
+
+		before := content
+		if edit.ReplaceAll {
+			content = strings.ReplaceAll(content, oldString, newString)
+		} else {
+			if strings.LastIndex(content, oldString) != index {
+				count := strings.Count(content, oldString)
+				return "", nil, fmt.Errorf("edit %d: old_string appears %d times in the file. Please provide more surrounding context lines in old_string to make the match unique, or use replace_all=true to replace all occurrences", i+1, count)
+			}
+			content = content[:index] + newString + content[index+len(oldString):]
+		}
+
+		applied = append(applied, appliedEdit{
+			before:     before,
+			after:      content,
+			lineNumber: strings.Count(before[:index], "\n") + 1,
+		})
+	}
+	return content, applied, nil
+}
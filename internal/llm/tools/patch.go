@@ -23,6 +23,7 @@ import (
 
 type PatchParams struct {
 	PatchText string `json:"patch_text"`
+	DryRun    bool   `json:"dry_run,omitempty"`
 }
 
 type PatchResponseMetadata struct {
@@ -31,6 +32,14 @@ type PatchResponseMetadata struct {
 	Removals     int      `json:"removals"`
 }
 
+// PatchDryRunResponseMetadata reports, for a dry run, how every file section
+// and hunk of the patch would apply.
+type PatchDryRunResponseMetadata struct {
+	WouldApply bool              `json:"would_apply"`
+	Fuzz       int               `json:"fuzz"`
+	Files      []diff.FileReport `json:"files"`
+}
+
 type patchTool struct {
 	lsp         lsp.LspService
 	permissions permission.Service
@@ -38,6 +47,10 @@ type patchTool struct {
 	registry    agentregistry.Registry
 }
 
+// patchMaxFuzz is the total fuzz a patch may need for its context lines to
+// match before it is rejected.
+const patchMaxFuzz = 3
+
 const (
 	PatchToolName    = "patch"
 	patchDescription = `Applies a patch to multiple files in one operation. This tool is useful for making coordinated changes across multiple files.
@@ -58,6 +71,8 @@ Each section starts with one of three headers:
 
 Context lines (@@) are matched with a fuzz tolerance of up to 3 lines — if the context drifts by more than 3 lines from the expected position, the patch is rejected.
 
+Set dry_run to true to check a patch without changing any file. The result lists every hunk with the line it would apply at and the fuzz it needs, or why it fails (context not found, or context that matches more than one place).
+
 Example patch:
 
 ` + "```" + `
@@ -90,6 +105,7 @@ Important:
 - You must include a header with your intended action (Add/Delete/Update)
 - You must prefix new lines with ` + "`+`" + ` even when creating a new file
 - Context lines (@@) must uniquely identify the section you want to change
+- The target of a move must not exist yet
 - All whitespace, indentation, and surrounding code must match exactly
 - Always use absolute file paths (starting with /)`
 )
@@ -104,7 +120,7 @@ func NewPatchTool(lspService lsp.LspService, permissions permission.Service, fil
 }
 
 func (p *patchTool) Info() ToolInfo {
-	return ToolInfo{
+	return withPreconditions(ToolInfo{
 		Name:        PatchToolName,
 		Description: patchDescription,
 		Parameters: map[string]any{
@@ -112,19 +128,23 @@ func (p *patchTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The full patch text that describes all changes to be made",
 			},
+			"dry_run": map[string]any{
+				"type":        "boolean",
+				"description": "Report how each hunk would apply without writing any file (default false)",
+			},
 		},
 		Required: []string{"patch_text"},
-	}
+	}, "requires prior read of every file the patch updates or deletes: each must be read with the Read tool first and must not change on disk after that read")
 }
 
 func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params PatchParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("invalid parameters"), nil
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
 	}
 
 	if params.PatchText == "" {
-		return NewTextErrorResponse("patch_text is required"), nil
+		return NewMissingParamResponse("patch_text", "patch_text is required"), nil
 	}
 
 	normalized := strings.ReplaceAll(strings.ReplaceAll(params.PatchText, "\r\n", "\n"), "\r", "\n")
@@ -136,16 +156,17 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	// Identify all files needed for the patch and verify they've been read
 	filesToRead := diff.IdentifyFilesNeeded(params.PatchText)
 	for _, filePath := range filesToRead {
-		absPath := fileutil.ResolvePath(filePath, config.WorkingDirectory())
+		absPath := fileutil.ResolvePath(filePath, callDirectory(ctx))
 
-		if getLastReadTime(absPath).IsZero() {
+		// A dry run writes nothing, so it does not need the files read first
+		if !params.DryRun && getLastReadTime(absPath).IsZero() {
 			return NewTextErrorResponse(fmt.Sprintf("you must read the file %s before patching it. Use the FileRead tool first", filePath)), nil
 		}
 
 		fileInfo, err := os.Stat(absPath)
 		if err != nil {
 			if os.IsNotExist(err) {
-				return NewTextErrorResponse("file not found: " + absPath), nil
+				return NewNotFoundResponse("file not found: "+absPath, absPath), nil
 			}
 			return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
 		}
@@ -154,9 +175,19 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			return NewTextErrorResponse("path is a directory, not a file: " + absPath), nil
 		}
 
+		if isEnvFile(absPath) {
+			return envFileWriteError(absPath), nil
+		}
+
+		if !params.DryRun {
+			if response, err := checkProtectedFile(ctx, p.permissions, PatchToolName, absPath); err != nil || response.IsError {
+				return response, err
+			}
+		}
+
 		modTime := fileInfo.ModTime()
 		lastRead := getLastReadTime(absPath)
-		if modTime.After(lastRead) {
+		if !params.DryRun && modTime.After(lastRead) {
 			return NewTextErrorResponse(
 				fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
 					absPath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
@@ -167,7 +198,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	// Check for new files to ensure they don't already exist
 	filesToAdd := diff.IdentifyFilesAdded(params.PatchText)
 	for _, filePath := range filesToAdd {
-		absPath := fileutil.ResolvePath(filePath, config.WorkingDirectory())
+		absPath := fileutil.ResolvePath(filePath, callDirectory(ctx))
 
 		_, err := os.Stat(absPath)
 		if err == nil {
@@ -175,12 +206,18 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		} else if !os.IsNotExist(err) {
 			return NewEmptyResponse(), fmt.Errorf("failed to check file: %w", err)
 		}
+
+		if !params.DryRun {
+			if response, err := checkProtectedFile(ctx, p.permissions, PatchToolName, absPath); err != nil || response.IsError {
+				return response, err
+			}
+		}
 	}
 
 	// Load all required files
 	currentFiles := make(map[string]string)
 	for _, filePath := range filesToRead {
-		absPath := fileutil.ResolvePath(filePath, config.WorkingDirectory())
+		absPath := fileutil.ResolvePath(filePath, callDirectory(ctx))
 
 		content, err := os.ReadFile(absPath)
 		if err != nil {
@@ -189,13 +226,17 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		currentFiles[filePath] = string(content)
 	}
 
+	if params.DryRun {
+		return dryRunPatch(ctx, params.PatchText, currentFiles), nil
+	}
+
 	// Process the patch
 	patch, fuzz, err := diff.TextToPatch(params.PatchText, currentFiles)
 	if err != nil {
 		return NewTextErrorResponse(fmt.Sprintf("failed to parse patch: %s", err)), nil
 	}
 
-	if fuzz > 3 {
+	if fuzz > patchMaxFuzz {
 		return NewTextErrorResponse(fmt.Sprintf("patch contains fuzzy matches (fuzz level: %d). Please make your context lines more precise", fuzz)), nil
 	}
 
@@ -211,13 +252,47 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return NewEmptyResponse(), errors.New("session ID and message ID are required for creating a patch")
 	}
 
+	// Check the syntax of every file the patch writes before changing any of them
+	changedPaths := make([]string, 0, len(commit.Changes))
+	for filePath := range commit.Changes {
+		changedPaths = append(changedPaths, filePath)
+	}
+	sort.Strings(changedPaths)
+	var syntaxWarnings strings.Builder
+	for _, filePath := range changedPaths {
+		change := commit.Changes[filePath]
+		if change.Type == diff.ActionDelete || change.NewContent == nil {
+			continue
+		}
+		target := filePath
+		if change.MovePath != nil {
+			target = *change.MovePath
+		}
+		oldContent := ""
+		if change.OldContent != nil {
+			oldContent = *change.OldContent
+		}
+		syntaxResponse, syntaxWarning := checkSyntax(fileutil.ResolvePath(target, callDirectory(ctx)), oldContent, *change.NewContent)
+		if syntaxResponse.IsError {
+			return syntaxResponse, nil
+		}
+		syntaxWarnings.WriteString(syntaxWarning)
+	}
+
 	// Request permission for all changes
 	var combinedDiff string
 	needsPermission := false
 	permissionFiles := make([]string, 0)
 	var combinedDiffSb217 strings.Builder
 	for filePath, change := range commit.Changes {
-		fileAction := p.registry.EvaluatePermission(string(GetAgentID(ctx)), PatchToolName, filePath)
+		fileAction := evaluateFilePermission(ctx, p.registry, PatchToolName, fileutil.ResolvePath(filePath, callDirectory(ctx)))
+		if change.MovePath != nil && fileAction != permission.ActionDeny {
+			// A move also writes the new path
+			movePath := fileutil.ResolvePath(*change.MovePath, callDirectory(ctx))
+			if moveAction := evaluateFilePermission(ctx, p.registry, PatchToolName, movePath); moveAction != permission.ActionAllow {
+				fileAction = moveAction
+			}
+		}
 		if fileAction == permission.ActionDeny {
 			return NewEmptyResponse(), permission.ErrorPermissionDenied
 		}
@@ -225,7 +300,11 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			continue
 		}
 		needsPermission = true
-		permissionFiles = append(permissionFiles, filePath)
+		if change.MovePath != nil {
+			permissionFiles = append(permissionFiles, filePath+" -> "+*change.MovePath)
+		} else {
+			permissionFiles = append(permissionFiles, filePath)
+		}
 
 		oldContent := ""
 		if change.OldContent != nil {
@@ -265,7 +344,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 
 	// Apply the changes to the filesystem
 	err = diff.ApplyCommit(commit, func(path string, content string) error {
-		absPath := fileutil.ResolvePath(path, config.WorkingDirectory())
+		absPath := fileutil.ResolvePath(path, callDirectory(ctx))
 
 		// Create parent directories if needed
 		dir := filepath.Dir(absPath)
@@ -275,7 +354,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 
 		return os.WriteFile(absPath, []byte(content), 0o644)
 	}, func(path string) error {
-		absPath := fileutil.ResolvePath(path, config.WorkingDirectory())
+		absPath := fileutil.ResolvePath(path, callDirectory(ctx))
 		return os.Remove(absPath)
 	})
 	if err != nil {
@@ -288,7 +367,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	totalRemovals := 0
 
 	for path, change := range commit.Changes {
-		absPath := fileutil.ResolvePath(path, config.WorkingDirectory())
+		absPath := fileutil.ResolvePath(path, callDirectory(ctx))
 		changedFiles = append(changedFiles, absPath)
 
 		oldContent := ""
@@ -306,33 +385,18 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		totalAdditions += additions
 		totalRemovals += removals
 
-		// Update history
-		file, err := p.files.GetByPathAndSession(ctx, absPath, sessionID)
-		if err != nil && change.Type != diff.ActionAdd {
-			// If not adding a file, create history entry for existing file
-			_, err = p.files.Create(ctx, sessionID, absPath, oldContent)
-			if err != nil {
-				logging.Debug("Error creating file history", "error", err)
-			}
-		}
-
-		if err == nil && change.Type != diff.ActionAdd && file.Content != oldContent {
-			// User manually changed content, store intermediate version
-			_, err = p.files.CreateVersion(ctx, sessionID, absPath, oldContent)
-			if err != nil {
-				logging.Debug("Error creating file history version", "error", err)
-			}
+		if change.MovePath != nil {
+			// A move deletes the old path and creates the new one
+			movePath := fileutil.ResolvePath(*change.MovePath, callDirectory(ctx))
+			changedFiles = append(changedFiles, movePath)
+			p.recordHistory(ctx, sessionID, absPath, diff.ActionDelete, oldContent, "")
+			p.recordHistory(ctx, sessionID, movePath, diff.ActionAdd, "", newContent)
+			recordFileWrite(movePath)
+			recordFileRead(movePath)
+			continue
 		}
 
-		// Store new version
-		if change.Type == diff.ActionDelete {
-			_, err = p.files.CreateVersion(ctx, sessionID, absPath, "")
-		} else {
-			_, err = p.files.CreateVersion(ctx, sessionID, absPath, newContent)
-		}
-		if err != nil {
-			logging.Debug("Error creating file history version", "error", err)
-		}
+		p.recordHistory(ctx, sessionID, absPath, change.Type, oldContent, newContent)
 
 		// Record file operations
 		recordFileWrite(absPath)
@@ -346,6 +410,7 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 
 	result := fmt.Sprintf("Patch applied successfully. %d files changed, %d additions, %d removals",
 		len(changedFiles), totalAdditions, totalRemovals)
+	result += syntaxWarnings.String()
 
 	diagnosticsText := ""
 	var diagnosticsTextSb348 strings.Builder
@@ -366,3 +431,89 @@ func (p *patchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			Removals:     totalRemovals,
 		}), nil
 }
+
+// recordHistory stores the change a patch made to absPath in the file
+// history. A deleted file gets an empty version.
+func (p *patchTool) recordHistory(ctx context.Context, sessionID, absPath string, action diff.ActionType, oldContent, newContent string) {
+	file, err := p.files.GetByPathAndSession(ctx, absPath, sessionID)
+	if err != nil {
+		if action == diff.ActionAdd {
+			// Record that the file did not exist, so discarding the session removes it
+			_, err = p.files.CreateNew(ctx, sessionID, absPath)
+		} else {
+			// If not adding a file, create history entry for existing file
+			_, err = p.files.Create(ctx, sessionID, absPath, oldContent)
+		}
+		if err != nil {
+			logging.Debug("Error creating file history", "error", err)
+		}
+	}
+
+	if err == nil && action != diff.ActionAdd && file.Content != oldContent {
+		// User manually changed content, store intermediate version
+		_, err = p.files.CreateVersion(ctx, sessionID, absPath, oldContent)
+		if err != nil {
+			logging.Debug("Error creating file history version", "error", err)
+		}
+	}
+
+	// Store new version
+	if action == diff.ActionDelete {
+		_, err = p.files.CreateVersion(ctx, sessionID, absPath, "")
+	} else {
+		_, err = p.files.CreateVersion(ctx, sessionID, absPath, newContent)
+	}
+	if err != nil {
+		logging.Debug("Error creating file history version", "error", err)
+	}
+}
+
+// dryRunPatch reports how each hunk of patchText would apply to files.
+func dryRunPatch(ctx context.Context, patchText string, files map[string]string) ToolResponse {
+	report, err := diff.DryRunPatch(patchText, files)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to parse patch: %s", err))
+	}
+
+	failed, total := report.Failed(), 0
+	for _, file := range report.Files {
+		total += len(file.Hunks)
+	}
+	var result strings.Builder
+	result.WriteString("Dry run, no files were changed. ")
+	switch {
+	case failed > 0:
+		fmt.Fprintf(&result, "The patch would not apply: %d of %d hunks failed.\n", failed, total)
+	case report.Fuzz > patchMaxFuzz:
+		fmt.Fprintf(&result, "The patch would be rejected for fuzzy matches (fuzz level: %d). Make the context lines more precise.\n", report.Fuzz)
+	default:
+		result.WriteString("The patch would apply.\n")
+	}
+	for _, file := range report.Files {
+		absPath := fileutil.ResolvePath(file.Path, callDirectory(ctx))
+		fmt.Fprintf(&result, "\n%s: %s", absPath, file.Action)
+		if file.MovePath != "" {
+			fmt.Fprintf(&result, ", move to %s", fileutil.ResolvePath(file.MovePath, callDirectory(ctx)))
+		}
+		result.WriteString("\n")
+		for _, hunk := range file.Hunks {
+			if hunk.Status == diff.HunkFailed {
+				fmt.Fprintf(&result, "  hunk %d: failed, %s\n", hunk.Hunk, hunk.Reason)
+				continue
+			}
+			fmt.Fprintf(&result, "  hunk %d: applied at line %d", hunk.Hunk, hunk.Line)
+			if hunk.Fuzz > 0 {
+				fmt.Fprintf(&result, " with fuzz %d", hunk.Fuzz)
+			}
+			result.WriteString("\n")
+		}
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimRight(result.String(), "\n")),
+		PatchDryRunResponseMetadata{
+			WouldApply: failed == 0 && report.Fuzz <= patchMaxFuzz,
+			Fuzz:       report.Fuzz,
+			Files:      report.Files,
+		})
+}
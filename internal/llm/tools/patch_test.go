@@ -1,11 +1,19 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func TestPatchTool_Info(t *testing.T) {
@@ -143,3 +151,129 @@ func TestPatchParams(t *testing.T) {
 		})
 	}
 }
+
+func TestPatchTool_DryRun(t *testing.T) {
+	original := "func a() {  \n\treturn 1\n}\n\nfunc b() {\n\treturn 2\n}\n"
+	path := writeWorkingDirFile(t, "patch-dryrun-*.go", []byte(original))
+	patchText := "*** Begin Patch\n*** Update File: " + path + "\n" +
+		"@@\n func a() {\n-\treturn 1\n+\treturn 10\n" +
+		"@@\n func c() {\n-\treturn 3\n+\treturn 30\n" +
+		"*** End Patch"
+	input, err := json.Marshal(PatchParams{PatchText: patchText, DryRun: true})
+	require.NoError(t, err)
+
+	resp, err := NewPatchTool(nil, nil, nil, nil).Run(t.Context(), ToolCall{Name: PatchToolName, Input: string(input)})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+
+	assert.Contains(t, resp.Content, "Dry run, no files were changed. The patch would not apply: 1 of 2 hunks failed.")
+	assert.Contains(t, resp.Content, "hunk 1: applied at line 1 with fuzz 1")
+	assert.Contains(t, resp.Content, "hunk 2: failed, context not found")
+
+	var meta PatchDryRunResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.False(t, meta.WouldApply)
+	require.Len(t, meta.Files, 1)
+	assert.Equal(t, []diff.HunkReport{
+		{Hunk: 1, Status: diff.HunkApplied, Line: 1, Fuzz: 1},
+		{Hunk: 2, Status: diff.HunkFailed, Reason: diff.HunkContextNotFound},
+	}, meta.Files[0].Hunks)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content), "a dry run must not write the file")
+}
+
+// pathHistoryService records the path of every version stored.
+type pathHistoryService struct {
+	*stubHistoryService
+	versions map[string][]string
+}
+
+func (s *pathHistoryService) CreateVersion(ctx context.Context, sessionID, path, content string) (history.File, error) {
+	s.versions[path] = append(s.versions[path], content)
+	return s.stubHistoryService.CreateVersion(ctx, sessionID, path, content)
+}
+
+func TestPatchTool_MoveFile(t *testing.T) {
+	dir := createTempDirInWorkingDir(t, "patch-move-*")
+	oldPath := filepath.Join(dir, "old.go")
+	newPath := filepath.Join(dir, "renamed", "new.go")
+	writeAndTrack(t, oldPath, "package demo\n\nfunc Old() {}\n")
+
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Return(true).AnyTimes()
+	files := &pathHistoryService{stubHistoryService: newStubHistoryService(), versions: map[string][]string{}}
+	tool := NewPatchTool(&noopLspService{}, mockPerms, files, &stubRegistry{})
+
+	patchText := "*** Begin Patch\n*** Update File: " + oldPath + "\n*** Move to: " + newPath + "\n" +
+		"@@\n package demo\n \n-func Old() {}\n+func New() {}\n*** End Patch"
+	input, err := json.Marshal(PatchParams{PatchText: patchText})
+	require.NoError(t, err)
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	resp, err := tool.Run(ctx, ToolCall{Name: PatchToolName, Input: string(input)})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+
+	assert.NoFileExists(t, oldPath)
+	content, err := os.ReadFile(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package demo\n\nfunc New() {}\n", string(content))
+
+	require.NotEmpty(t, files.versions[oldPath])
+	assert.Empty(t, files.versions[oldPath][len(files.versions[oldPath])-1], "the old path is recorded as deleted")
+	assert.Equal(t, []string{"package demo\n\nfunc New() {}\n"}, files.versions[newPath], "the new path is recorded as created")
+
+	var meta PatchResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.ElementsMatch(t, []string{oldPath, newPath}, meta.FilesChanged)
+}
+
+func TestPatchTool_MoveOntoExistingFile(t *testing.T) {
+	oldPath := writeWorkingDirFile(t, "patch-move-*.go", []byte("package demo\n"))
+	existing := writeWorkingDirFile(t, "patch-move-*.go", []byte("package other\n"))
+	recordFileRead(oldPath)
+
+	patchText := "*** Begin Patch\n*** Update File: " + oldPath + "\n*** Move to: " + existing + "\n" +
+		"@@\n-package demo\n+package moved\n*** End Patch"
+	input, err := json.Marshal(PatchParams{PatchText: patchText})
+	require.NoError(t, err)
+	resp, err := NewPatchTool(nil, nil, nil, nil).Run(t.Context(), ToolCall{Name: PatchToolName, Input: string(input)})
+	require.NoError(t, err)
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "file already exists")
+}
+
+func TestPatchTool_AutoAllowPathsUnderCallDirectory(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Get()
+	oldDir, oldPerms := cfg.WorkingDir, cfg.Permission
+	cfg.WorkingDir = dir
+	cfg.Permission = &config.PermissionConfig{AutoAllowPaths: []string{"tmp/**"}}
+	t.Cleanup(func() { cfg.WorkingDir, cfg.Permission = oldDir, oldPerms })
+
+	path := filepath.Join(dir, "tmp", "notes.txt")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	writeAndTrack(t, path, "original\n")
+
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Times(0)
+	tool := NewPatchTool(&noopLspService{}, mockPerms, newStubHistoryService(), &askRegistry{})
+
+	patchText := "*** Begin Patch\n*** Update File: notes.txt\n@@\n-original\n+changed\n*** End Patch"
+	input, err := json.Marshal(PatchParams{PatchText: patchText})
+	require.NoError(t, err)
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	ctx = context.WithValue(ctx, CallDirectoryContextKey, filepath.Dir(path))
+	resp, err := tool.Run(ctx, ToolCall{Name: PatchToolName, Input: string(input)})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "changed\n", string(content))
+}
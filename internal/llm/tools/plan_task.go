@@ -87,15 +87,15 @@ func (p *planTaskTool) Info() ToolInfo {
 func (p *planTaskTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params PlanTaskParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.Title == "" {
-		return NewTextErrorResponse("title is required"), nil
+		return NewMissingParamResponse("title", "title is required"), nil
 	}
 
 	if len(params.Steps) == 0 {
-		return NewTextErrorResponse("at least one step is required"), nil
+		return NewMissingParamResponse("steps", "at least one step is required"), nil
 	}
 
 	sessionID, _ := GetContextValues(ctx)
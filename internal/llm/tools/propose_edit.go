@@ -0,0 +1,242 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/google/uuid"
+)
+
+type ProposeEditParams struct {
+	FilePath string          `json:"file_path"`
+	Edits    []MultiEditItem `json:"edits"`
+}
+
+type ProposeEditResponseMetadata struct {
+	ProposalID string `json:"proposal_id"`
+	FilePath   string `json:"file_path"`
+	Diff       string `json:"diff"`
+	Additions  int    `json:"additions"`
+	Removals   int    `json:"removals"`
+}
+
+// EditProposal is an edit saved by the propose_edit tool for review. It is
+// applied later by the apply_proposal tool.
+type EditProposal struct {
+	ID       string          `json:"id"`
+	FilePath string          `json:"file_path"`
+	Edits    []MultiEditItem `json:"edits"`
+	Diff     string          `json:"diff"`
+	// FileHash is the content hash of the file the edits were computed
+	// against, so a proposal is never applied to a file that changed since.
+	FileHash  string    `json:"file_hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type proposeEditTool struct{}
+
+const (
+	ProposeEditToolName    = "propose_edit"
+	proposeEditDescription = `Proposes edits to a file for review without changing it. Computes the diff the edits would produce, saves the proposal and returns the diff with a proposal ID.
+
+WHEN TO USE THIS TOOL:
+- When the user asked to review changes before they are made
+- For risky or sweeping changes that a human should approve first
+
+HOW TO USE:
+- Provide file_path and edits exactly as for the multiedit tool
+- Show the returned diff to the user and mention the proposal ID
+- Once approved, apply it with the apply_proposal tool and the proposal ID
+
+FEATURES:
+- Edits are applied in sequence, like multiedit, but only in memory
+- The file is not modified; the proposal is stored in the .opencode/proposals directory
+
+LIMITATIONS:
+- Environment files (.env) cannot be proposed
+- A proposal can only be applied while the file is unchanged since it was proposed
+
+TIPS:
+- Read the file first so old_string matches exactly
+- If the file changed, create a new proposal instead of applying the old one`
+)
+
+// proposalIDPattern matches the IDs created by propose_edit, which also keeps
+// IDs given to apply_proposal from naming paths outside the proposals
+// directory.
+var proposalIDPattern = regexp.MustCompile(`^[0-9a-f]{8}$`)
+
+func NewProposeEditTool() BaseTool {
+	return &proposeEditTool{}
+}
+
+func (p *proposeEditTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ProposeEditToolName,
+		ReadOnly:    true,
+		Description: proposeEditDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The absolute path to the file the edits are for",
+			},
+			"edits": map[string]any{
+				"type":        "array",
+				"description": "Array of edit operations to apply sequentially to the file",
+				"items": map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"old_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace",
+						},
+						"new_string": map[string]any{
+							"type":        "string",
+							"description": "The text to replace it with (must be different from old_string)",
+						},
+						"replace_all": map[string]any{
+							"type":        "boolean",
+							"description": "Replace all occurrences of old_string (default false)",
+						},
+					},
+					"required": []string{"old_string", "new_string"},
+				},
+			},
+		},
+		Required: []string{"file_path", "edits"},
+	}
+}
+
+func (p *proposeEditTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ProposeEditParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
+	}
+	if params.FilePath == "" {
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
+	}
+	if len(params.Edits) == 0 {
+		return NewTextErrorResponse("edits array must not be empty"), nil
+	}
+
+	filePath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
+	if err != nil {
+		return NewPathErrorResponse(err, params.FilePath), nil
+	}
+	if isEnvFile(filePath) {
+		return envFileWriteError(filePath), nil
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewNotFoundResponse("file not found: "+filePath, filePath), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
+	}
+
+	oldContent := strings.ReplaceAll(string(content), "\r\n", "\n")
+	newContent, _, err := applyMultiEdits(oldContent, params.Edits)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+	if oldContent == newContent {
+		return NewTextErrorResponse("no changes were made. All edits resulted in the same content."), nil
+	}
+	hash, err := fileContentHash(filePath)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
+	}
+
+	unified, additions, removals := diff.GenerateDiff(oldContent, newContent, filePath)
+	proposal := EditProposal{
+		ID:        uuid.New().String()[:8],
+		FilePath:  filePath,
+		Edits:     params.Edits,
+		Diff:      unified,
+		FileHash:  hash,
+		CreatedAt: time.Now(),
+	}
+	if err := saveProposal(proposal); err != nil {
+		return NewEmptyResponse(), err
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("Proposal %s for %s (+%d -%d). The file was not changed.\n\n%s\n\nApply it with apply_proposal once it has been reviewed.",
+			proposal.ID, filePath, additions, removals, unified)),
+		ProposeEditResponseMetadata{
+			ProposalID: proposal.ID,
+			FilePath:   filePath,
+			Diff:       unified,
+			Additions:  additions,
+			Removals:   removals,
+		},
+	), nil
+}
+
+// proposalsDir is where proposals are stored, inside the data directory.
+func proposalsDir() string {
+	dataDir := ".opencode"
+	if cfg := config.Get(); cfg != nil && cfg.Data.Directory != "" {
+		dataDir = cfg.Data.Directory
+	}
+	return filepath.Join(fileutil.ResolvePath(dataDir, config.WorkingDirectory()), "proposals")
+}
+
+func proposalPath(id string) string {
+	return filepath.Join(proposalsDir(), id+".json")
+}
+
+func saveProposal(proposal EditProposal) error {
+	data, err := json.MarshalIndent(proposal, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode proposal: %w", err)
+	}
+	if err := os.MkdirAll(proposalsDir(), 0o755); err != nil {
+		return fmt.Errorf("failed to create proposals directory: %w", err)
+	}
+	if err := os.WriteFile(proposalPath(proposal.ID), data, 0o644); err != nil {
+		return fmt.Errorf("failed to save proposal: %w", err)
+	}
+	return nil
+}
+
+// errProposalNotFound is returned by loadProposal for unknown IDs.
+var errProposalNotFound = errors.New("proposal not found")
+
+func loadProposal(id string) (EditProposal, error) {
+	if !proposalIDPattern.MatchString(id) {
+		return EditProposal{}, errProposalNotFound
+	}
+	data, err := os.ReadFile(proposalPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return EditProposal{}, errProposalNotFound
+		}
+		return EditProposal{}, fmt.Errorf("failed to read proposal: %w", err)
+	}
+	var proposal EditProposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return EditProposal{}, fmt.Errorf("failed to decode proposal %s: %w", id, err)
+	}
+	return proposal, nil
+}
+
+// ProposalFilePath returns the absolute path of the file the proposal with the
+// given ID edits.
+func ProposalFilePath(id string) (string, error) {
+	proposal, err := loadProposal(id)
+	if err != nil {
+		return "", err
+	}
+	return proposal.FilePath, nil
+}
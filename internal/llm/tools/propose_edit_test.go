@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func useTempProposalsDir(t *testing.T) {
+	t.Helper()
+	cfg := config.Get()
+	old := cfg.Data.Directory
+	cfg.Data.Directory = t.TempDir()
+	t.Cleanup(func() { cfg.Data.Directory = old })
+}
+
+func runProposeEdit(t *testing.T, params ProposeEditParams) (ToolResponse, ProposeEditResponseMetadata) {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := NewProposeEditTool().Run(t.Context(), ToolCall{Name: ProposeEditToolName, Input: string(input)})
+	require.NoError(t, err)
+
+	var meta ProposeEditResponseMetadata
+	if !resp.IsError {
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	}
+	return resp, meta
+}
+
+func runApplyProposal(t *testing.T, id string) ToolResponse {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Return(true).AnyTimes()
+	tool := NewApplyProposalTool(&noopLspService{}, mockPerms, newStubHistoryService(), &stubRegistry{})
+
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	input, err := json.Marshal(ApplyProposalParams{ProposalID: id})
+	require.NoError(t, err)
+	resp, err := tool.Run(ctx, ToolCall{Name: ApplyProposalToolName, Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestProposeEditTool(t *testing.T) {
+	useTempProposalsDir(t)
+	original := "package demo\n\nconst Timeout = 10\nconst Retries = 1\n"
+	edits := []MultiEditItem{
+		{OldString: "Timeout = 10", NewString: "Timeout = 30"},
+		{OldString: "Retries = 1", NewString: "Retries = 3"},
+	}
+
+	t.Run("proposal leaves the file unchanged and can be applied", func(t *testing.T) {
+		path := writeWorkingDirFile(t, "propose-*.go", []byte(original))
+
+		resp, meta := runProposeEdit(t, ProposeEditParams{FilePath: path, Edits: edits})
+		require.False(t, resp.IsError, resp.Content)
+		assert.Regexp(t, `^[0-9a-f]{8}$`, meta.ProposalID)
+		assert.Equal(t, 2, meta.Additions)
+		assert.Equal(t, 2, meta.Removals)
+		assert.Contains(t, meta.Diff, "-const Timeout = 10")
+		assert.Contains(t, meta.Diff, "+const Timeout = 30")
+		assert.Contains(t, meta.Diff, "+const Retries = 3")
+		assert.Contains(t, resp.Content, meta.ProposalID)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, original, string(content), "proposing must not write the file")
+		assert.FileExists(t, proposalPath(meta.ProposalID))
+
+		applied := runApplyProposal(t, meta.ProposalID)
+		require.False(t, applied.IsError, applied.Content)
+		assert.Contains(t, applied.Content, "Applied proposal "+meta.ProposalID)
+
+		content, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "package demo\n\nconst Timeout = 30\nconst Retries = 3\n", string(content))
+		assert.NoFileExists(t, proposalPath(meta.ProposalID), "applied proposals are removed")
+	})
+
+	t.Run("file changed after the proposal", func(t *testing.T) {
+		path := writeWorkingDirFile(t, "propose-*.go", []byte(original))
+		resp, meta := runProposeEdit(t, ProposeEditParams{FilePath: path, Edits: edits})
+		require.False(t, resp.IsError, resp.Content)
+
+		require.NoError(t, os.WriteFile(path, []byte(original+"// edited by hand\n"), 0o644))
+		applied := runApplyProposal(t, meta.ProposalID)
+		assert.True(t, applied.IsError)
+		assert.Contains(t, applied.Content, "has changed since proposal")
+	})
+
+	t.Run("edit that does not match", func(t *testing.T) {
+		path := writeWorkingDirFile(t, "propose-*.go", []byte(original))
+		resp, _ := runProposeEdit(t, ProposeEditParams{FilePath: path, Edits: []MultiEditItem{{OldString: "missing", NewString: "x"}}})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "old_string not found")
+	})
+
+	t.Run("unknown proposal", func(t *testing.T) {
+		for _, id := range []string{"0123abcd", "../../etc/passwd"} {
+			applied := runApplyProposal(t, id)
+			assert.True(t, applied.IsError)
+			assert.Contains(t, applied.Content, "not found")
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+// ProtectedFileToolName is the tool name of the permission request that
+// confirms an edit of a generated or vendored file when guard.protectGenerated
+// is enabled.
+const ProtectedFileToolName = "protected_file"
+
+// generatedHeaderBytes is how much of the start of a file is searched for a
+// generated-file marker.
+const generatedHeaderBytes = 8 * 1024
+
+// generatedMarker matches the "// Code generated ... DO NOT EDIT." line Go
+// tools write, and an @generated tag starting a comment. Other mentions of
+// the phrases, such as in prose or string literals, are not markers.
+var generatedMarker = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.\r?$|^\s*(//|#|\*|/\*)\s*@generated\b`)
+
+// vendoredDir reports the first directory of path, relative to the working
+// directory, that holds vendored or build output, such as vendor or
+// node_modules. Dot directories of CommonIgnoredDirs, like .github, hold
+// project configuration rather than copies of other code and are not counted.
+func vendoredDir(path string) string {
+	rel, err := filepath.Rel(config.WorkingDirectory(), path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	for _, dir := range strings.Split(filepath.Dir(rel), string(filepath.Separator)) {
+		if !strings.HasPrefix(dir, ".") && fileutil.CommonIgnoredDirs[dir] {
+			return dir
+		}
+	}
+	return ""
+}
+
+// generatedHeader returns the generated-file marker at the start of the file
+// at path, or "" when it has none or cannot be read.
+func generatedHeader(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	header, err := io.ReadAll(io.LimitReader(f, generatedHeaderBytes))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(generatedMarker.Find(header)))
+}
+
+// protectedFileReason explains why path should not be edited, or returns ""
+// when it is a regular source file.
+func protectedFileReason(path string) string {
+	if header := generatedHeader(path); header != "" {
+		return fmt.Sprintf("is a generated file (%q)", header)
+	}
+	if dir := vendoredDir(path); dir != "" {
+		return fmt.Sprintf("is a vendored file, under %s/", dir)
+	}
+	return ""
+}
+
+// checkProtectedFile guards an edit of path when guard.protectGenerated is
+// enabled. Edits of generated and vendored files are blocked in strict mode
+// and otherwise need the user's confirmation. The edit may go ahead when the
+// returned response is not an error and err is nil.
+func checkProtectedFile(ctx context.Context, permissions permission.Service, toolName, path string) (ToolResponse, error) {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Guard.ProtectGenerated {
+		return NewEmptyResponse(), nil
+	}
+	reason := protectedFileReason(path)
+	if reason == "" {
+		return NewEmptyResponse(), nil
+	}
+
+	if cfg.Guard.Strict {
+		return NewToolErrorResponse(
+			fmt.Sprintf("%s %s and cannot be edited; change its source or regenerate it instead", path, reason),
+			ToolErrorDetail{Code: ErrorCodePermissionDenied, Path: path},
+		), nil
+	}
+	sessionID, _ := GetContextValues(ctx)
+	if !permissions.Request(permission.CreatePermissionRequest{
+		SessionID:   sessionID,
+		Path:        path,
+		ToolName:    ProtectedFileToolName,
+		Action:      toolName,
+		Description: fmt.Sprintf("%s %s. Changes to it are usually overwritten or belong in its source. Edit it anyway?", path, reason),
+	}) {
+		return NewEmptyResponse(), permission.ErrorPermissionDenied
+	}
+	return NewEmptyResponse(), nil
+}
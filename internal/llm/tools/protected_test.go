@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProtectedFileReason(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	wd := config.WorkingDirectory()
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"go generated header", write("gen.go", "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n"), `is a generated file ("// Code generated by protoc-gen-go. DO NOT EDIT.")`},
+		{"go generated header with CRLF", write("gen_windows.go", "// Code generated by stringer. DO NOT EDIT.\r\n\r\npackage pb\r\n"), `is a generated file ("// Code generated by stringer. DO NOT EDIT.")`},
+		{"hash comment header", write("gen.py", "# Code generated by tool; DO NOT EDIT.\n"), ""},
+		{"generated tag", write("gen.js", "/**\n * @generated\n */\n"), `is a generated file ("* @generated")`},
+		{"hash generated tag", write("gen.rb", "# @generated by tool\n"), `is a generated file ("# @generated")`},
+		{"generated tag in code", write("tag.js", "const tag = \"@generated\";\n"), ""},
+		{"phrase in a string", write("msg.go", "package msg\n\nconst s = `// Code generated by x. DO NOT EDIT.`\n"), ""},
+		{"normal source", write("main.go", "package main\n\n// DO NOT EDIT this comment is not a marker\n"), ""},
+		{"vendored", filepath.Join(wd, "vendor", "github.com", "x", "x.go"), "is a vendored file, under vendor/"},
+		{"node modules", filepath.Join(wd, "web", "node_modules", "lib", "index.js"), "is a vendored file, under node_modules/"},
+		{"dot directories are project files", filepath.Join(wd, ".github", "workflows", "ci.yml"), ""},
+		{"missing regular file", filepath.Join(wd, "src", "app.go"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, protectedFileReason(tt.path))
+		})
+	}
+}
+
+func TestEditGuardsGeneratedFiles(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.Guard
+	t.Cleanup(func() { cfg.Guard = old })
+	cfg.Guard = config.GuardConfig{ProtectGenerated: true}
+
+	var guardRequests []permission.CreatePermissionRequest
+	confirm := false
+	ctrl := gomock.NewController(t)
+	perms := mock_permission.NewMockService(ctrl)
+	perms.EXPECT().Request(gomock.Any()).DoAndReturn(func(opts permission.CreatePermissionRequest) bool {
+		if opts.ToolName == ProtectedFileToolName {
+			guardRequests = append(guardRequests, opts)
+			return confirm
+		}
+		return true
+	}).AnyTimes()
+	ctx, _, _ := setupEditTest(t)
+	tool := NewEditTool(&noopLspService{}, perms, newStubHistoryService(), &stubRegistry{})
+
+	dir := t.TempDir()
+	generated := filepath.Join(dir, "api.pb.go")
+	source := filepath.Join(dir, "main.go")
+	edit := func(path string) (ToolResponse, error) {
+		writeAndTrack(t, path, map[string]string{
+			generated: "// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage api\n",
+			source:    "package main\n",
+		}[path])
+		input := `{"file_path":"` + filepath.ToSlash(path) + `","old_string":"package","new_string":"package  "}`
+		return tool.Run(ctx, ToolCall{Name: EditToolName, Input: input})
+	}
+
+	t.Run("generated file asks for confirmation", func(t *testing.T) {
+		guardRequests, confirm = nil, false
+		_, err := edit(generated)
+		assert.ErrorIs(t, err, permission.ErrorPermissionDenied)
+		require.Len(t, guardRequests, 1)
+		assert.Equal(t, generated, guardRequests[0].Path)
+		assert.Contains(t, guardRequests[0].Description, "is a generated file")
+
+		guardRequests, confirm = nil, true
+		resp, err := edit(generated)
+		require.NoError(t, err)
+		assert.False(t, resp.IsError, resp.Content)
+		assert.Len(t, guardRequests, 1)
+	})
+
+	t.Run("normal source file is not guarded", func(t *testing.T) {
+		guardRequests = nil
+		resp, err := edit(source)
+		require.NoError(t, err)
+		assert.False(t, resp.IsError, resp.Content)
+		assert.Empty(t, guardRequests)
+	})
+
+	t.Run("strict mode blocks", func(t *testing.T) {
+		cfg.Guard.Strict = true
+		t.Cleanup(func() { cfg.Guard.Strict = false })
+		guardRequests = nil
+		resp, err := edit(generated)
+		require.NoError(t, err)
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "is a generated file")
+		require.NotNil(t, resp.ErrorDetail)
+		assert.Equal(t, ErrorCodePermissionDenied, resp.ErrorDetail.Code)
+		assert.Empty(t, guardRequests)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg.Guard.ProtectGenerated = false
+		guardRequests = nil
+		resp, err := edit(generated)
+		require.NoError(t, err)
+		assert.False(t, resp.IsError, resp.Content)
+		assert.Empty(t, guardRequests)
+	})
+}
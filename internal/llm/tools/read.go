@@ -3,15 +3,21 @@ package tools
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
 )
 
@@ -19,6 +25,10 @@ type ViewParams struct {
 	FilePath string `json:"file_path"`
 	Offset   int    `json:"offset"`
 	Limit    int    `json:"limit"`
+	Force    bool   `json:"force,omitempty"`
+	Full     bool   `json:"full,omitempty"`
+	// RedactEnv allows reading an environment file, with its values redacted.
+	RedactEnv bool `json:"redact_env,omitempty"`
 }
 
 type viewTool struct {
@@ -35,7 +45,18 @@ const (
 	MaxReadSize      = 250 * 1024
 	DefaultReadLimit = 2000
 	MaxLineLength    = 2000
-	viewDescription  = `File reading tool that reads and displays the contents of files with line numbers, allowing you to examine code, logs, or text data.
+	// readChunkLines is the chunk size suggested for files too large to
+	// read whole.
+	readChunkLines = 500
+	// summaryEdgeLines is how many lines from the start and the end of a file
+	// a summary includes.
+	summaryEdgeLines = 50
+	// maxOutlineEntries caps the declarations listed in a summary.
+	maxOutlineEntries = 200
+	// alreadyReadWindow is how recently a file must have been read for a
+	// repeated read in the same turn to be answered with a note.
+	alreadyReadWindow = 10 * time.Minute
+	viewDescription   = `File reading tool that reads and displays the contents of files with line numbers, allowing you to examine code, logs, or text data.
 
 WHEN TO USE THIS TOOL:
 - Use when you need to read the contents of a specific file
@@ -51,11 +72,16 @@ FEATURES:
 - Displays file contents with line numbers for easy reference
 - Can read from any position in a file using the offset parameter
 - Handles large files by limiting the number of lines read
+- Gzip (.gz) and bzip2 (.bz2) text files are decompressed transparently
 - Automatically truncates very long lines for better display
 - Suggests similar file names when the requested file isn't found
+- Re-reading an unchanged file with the same offset and limit in the same turn returns a short note instead of the content; set force=true to get the content again
+- When configured, reading a large file without offset or limit returns a summary (outline plus first and last lines); set full=true to get the content instead
+- When context.gitBlame is enabled, the most recent commits touching the file are listed after its content
+- Environment files (.env, .env.local, ...) are not read by default; set redact_env=true to see their keys with the values redacted (KEY=***). The edit tool can then add new keys without touching existing values
 
 LIMITATIONS:
-- Maximum file size is 250KB
+- Files over 250KB, or over maxFileSizeForTools when that is configured and smaller, can only be read in chunks with offset and limit
 - Default reading limit is 2000 lines
 - Lines longer than 2000 characters are truncated
 - Cannot display binary files or images
@@ -77,6 +103,7 @@ func NewViewTool(lspService lsp.LspService) BaseTool {
 func (v *viewTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        ReadToolName,
+		ReadOnly:    true,
 		Description: viewDescription,
 		Parameters: map[string]any{
 			"file_path": map[string]any{
@@ -91,6 +118,18 @@ func (v *viewTool) Info() ToolInfo {
 				"type":        "integer",
 				"description": "The number of lines to read (defaults to 2000)",
 			},
+			"force": map[string]any{
+				"type":        "boolean",
+				"description": "Return the content even if the file was already read and is unchanged (default false)",
+			},
+			"full": map[string]any{
+				"type":        "boolean",
+				"description": "Return the content instead of a summary for files above the configured summary threshold (default false)",
+			},
+			"redact_env": map[string]any{
+				"type":        "boolean",
+				"description": "Read an environment file (.env) with its values redacted, showing only the keys (default false)",
+			},
 		},
 		Required: []string{"file_path"},
 	}
@@ -100,17 +139,17 @@ func (v *viewTool) Info() ToolInfo {
 func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params ViewParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.FilePath == "" {
-		return NewTextErrorResponse("file_path is required"), nil
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
 	}
 
 	// Validate and resolve the file path
-	filePath, err := ValidatePathInWorkingDirectory(params.FilePath)
+	filePath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
 	if err != nil {
-		return NewTextErrorResponse(err.Error()), nil
+		return NewPathErrorResponse(err, params.FilePath), nil
 	}
 
 	// Check if file exists
@@ -135,12 +174,12 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 				}
 
 				if len(suggestions) > 0 {
-					return NewTextErrorResponse(fmt.Sprintf("File not found: %s\n\nDid you mean one of these?\n%s",
-						filePath, strings.Join(suggestions, "\n"))), nil
+					return NewNotFoundResponse(fmt.Sprintf("File not found: %s\n\nDid you mean one of these?\n%s",
+						filePath, strings.Join(suggestions, "\n")), filePath), nil
 				}
 			}
 
-			return NewTextErrorResponse("File not found: " + filePath), nil
+			return NewNotFoundResponse("File not found: "+filePath, filePath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("error accessing file: %w", err)
 	}
@@ -150,10 +189,12 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse("Path is a directory, not a file: " + filePath), nil
 	}
 
-	// Check file size
-	if fileInfo.Size() > MaxReadSize {
-		return NewTextErrorResponse(fmt.Sprintf("File is too large (%d bytes). Maximum size is %d bytes",
-			fileInfo.Size(), MaxReadSize)), nil
+	// A summary is only offered when no specific range was asked for
+	wholeFile := params.Offset <= 0 && params.Limit <= 0
+
+	// Files above the size limit are streamed one range at a time
+	if sizeLimit := readSizeLimit(); wholeFile && fileInfo.Size() > sizeLimit {
+		return NewTextErrorResponse(fileTooLargeMessage(fileInfo.Size(), sizeLimit)), nil
 	}
 
 	// Set default limit if not provided
@@ -172,11 +213,63 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 		return NewTextErrorResponse(fmt.Sprintf("This is an image file of type: %s\nUse a view_image tool to process images", imageType)), nil
 	}
 
-	// Check if it's a binary file by sampling content
-	if isBinary, err := isBinaryFile(filePath); err == nil && isBinary {
+	// Check if it's a binary file by sampling content, decompressed for gzip and bzip2 files
+	if isBinary, err := isBinaryContent(filePath); err == nil && isBinary {
 		return NewTextErrorResponse("File appears to be binary. Use the appropriate tool for this file type."), nil
 	}
 
+	if isEnvFile(filePath) {
+		if !params.RedactEnv {
+			return NewTextErrorResponse(fmt.Sprintf("%s is an environment file and may contain secrets, so it is not read by default. Set redact_env=true to read its keys with the values redacted.", filePath)), nil
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return NewEmptyResponse(), fmt.Errorf("error reading file: %w", err)
+		}
+		redacted := redactEnvContent(strings.TrimSuffix(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n"))
+		recordFileRead(filePath)
+		return WithResponseMetadata(
+			NewTextResponse("<file>\n"+addLineNumbers(redacted, 1)+"\n</file>\n\n(Values are redacted. Edits to this file may only add new keys.)"),
+			ViewResponseMetadata{FilePath: filePath, Content: redacted},
+		), nil
+	}
+
+	sessionID, _ := GetContextValues(ctx)
+	hash, err := fileContentHash(filePath)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error reading file: %w", err)
+	}
+	turnID := GetTurnID(ctx)
+	if !params.Force && turnID != "" {
+		view, ok := getFileView(sessionID, filePath)
+		if ok && view.turnID == turnID && view.hash == hash && view.offset == params.Offset && view.limit == params.Limit &&
+			WasRecentlyRead(filePath, alreadyReadWindow) {
+			recordFileRead(filePath)
+			return WithResponseMetadata(
+				NewTextResponse(fmt.Sprintf("(already read this turn, unchanged; sha256:%s. Use force=true to read it again.)", hash)),
+				ViewResponseMetadata{FilePath: filePath},
+			), nil
+		}
+	}
+
+	if threshold := readSummaryThreshold(); wholeFile && !params.Full && threshold > 0 {
+		data, err := readDecompressedFile(filePath)
+		if err != nil {
+			return NewEmptyResponse(), fmt.Errorf("error reading file: %w", err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if len(lines) > threshold {
+			v.lsp.NotifyOpenFile(ctx, filePath)
+			recordFileRead(filePath)
+			summary := summarizeFile(lines)
+			return WithResponseMetadata(
+				NewTextResponse(fmt.Sprintf("<file_summary>\n%s\n</file_summary>\n\n(File has %d lines, above the summary threshold of %d. Use offset and limit to read specific ranges, or full=true to read the file.)%s",
+					summary, len(lines), threshold, recentCommitsSection(ctx, filePath))),
+				ViewResponseMetadata{FilePath: filePath, Content: summary},
+			), nil
+		}
+	}
+
 	// Read the file content
 	content, linesRead, lineCount, err := readTextFile(filePath, params.Offset, params.Limit)
 	if err != nil {
@@ -197,7 +290,9 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	}
 	output += "\n</file>\n"
 	output += v.lsp.FormatDiagnostics(filePath)
+	output += recentCommitsSection(ctx, filePath)
 	recordFileRead(filePath)
+	recordFileView(sessionID, filePath, fileView{turnID: turnID, offset: params.Offset, limit: params.Limit, hash: hash})
 	return WithResponseMetadata(
 		NewTextResponse(output),
 		ViewResponseMetadata{
@@ -207,6 +302,93 @@ func (v *viewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error)
 	), nil
 }
 
+// readSizeLimit returns the largest file the read tool returns whole:
+// MaxReadSize, or maxFileSizeForTools when that is smaller.
+func readSizeLimit() int64 {
+	if limit := maxFileSizeForTools(); limit > 0 && limit < MaxReadSize {
+		return limit
+	}
+	return MaxReadSize
+}
+
+// fileTooLargeMessage points the model at reading a file in chunks instead of
+// whole.
+func fileTooLargeMessage(size, limit int64) string {
+	return fmt.Sprintf("file too large (%d bytes, limit %d); read it in chunks with offset and limit, e.g. offset=0 limit=%d, or search it with Grep",
+		size, limit, readChunkLines)
+}
+
+func readSummaryThreshold() int {
+	if cfg := config.Get(); cfg != nil {
+		return cfg.Tools.Read.SummaryThreshold
+	}
+	return 0
+}
+
+// outlinePattern matches lines that commonly declare a top-level symbol:
+// functions, types, classes, and similar constructs in popular languages.
+var outlinePattern = regexp.MustCompile(`^\s*(export\s+)?(default\s+)?(pub(\(\w+\))?\s+)?(public\s+|private\s+|protected\s+|static\s+|abstract\s+|async\s+)*(func|type|class|interface|struct|enum|trait|impl|fn|def|function|module|object)\b`)
+
+// summarizeFile returns an outline of the declarations in lines followed by
+// the first and last summaryEdgeLines lines, all with line numbers.
+func summarizeFile(lines []string) string {
+	var sb strings.Builder
+	sb.WriteString("Outline:\n")
+	entries := 0
+	for i, line := range lines {
+		if !outlinePattern.MatchString(line) {
+			continue
+		}
+		if entries == maxOutlineEntries {
+			sb.WriteString("    ...\n")
+			break
+		}
+		sb.WriteString(addLineNumbers(truncateReadLine(strings.TrimRight(line, " \t{")), i+1) + "\n")
+		entries++
+	}
+	if entries == 0 {
+		sb.WriteString("    (no declarations found)\n")
+	}
+
+	edge := min(summaryEdgeLines, len(lines))
+	fmt.Fprintf(&sb, "\nFirst %d lines:\n%s\n", edge, addLineNumbers(truncateReadLines(lines[:edge]), 1))
+	tail := max(edge, len(lines)-summaryEdgeLines)
+	if tail < len(lines) {
+		fmt.Fprintf(&sb, "\nLast %d lines:\n%s", len(lines)-tail, addLineNumbers(truncateReadLines(lines[tail:]), tail+1))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func truncateReadLine(line string) string {
+	if len(line) > MaxLineLength {
+		return line[:MaxLineLength] + "..."
+	}
+	return line
+}
+
+func truncateReadLines(lines []string) string {
+	truncated := make([]string, len(lines))
+	for i, line := range lines {
+		truncated[i] = truncateReadLine(line)
+	}
+	return strings.Join(truncated, "\n")
+}
+
+// fileContentHash returns a short SHA-256 digest of the file's content.
+func fileContentHash(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
 func addLineNumbers(content string, startLine int) string {
 	if content == "" {
 		return ""
@@ -233,7 +415,7 @@ func addLineNumbers(content string, startLine int) string {
 }
 
 func readTextFile(filePath string, offset, limit int) (string, int, int, error) {
-	file, err := os.Open(filePath)
+	file, err := fileutil.OpenDecompressed(filePath)
 	if err != nil {
 		return "", 0, 0, err
 	}
@@ -251,23 +433,12 @@ func readTextFile(filePath string, offset, limit int) (string, int, int, error)
 		}
 	}
 
-	if offset == 0 {
-		_, err = file.Seek(0, io.SeekStart)
-		if err != nil {
-			return "", 0, 0, err
-		}
-	}
-
 	var lines []string
 	lineCount = offset
 
 	for scanner.Scan() && len(lines) < limit {
 		lineCount++
-		lineText := scanner.Text()
-		if len(lineText) > MaxLineLength {
-			lineText = lineText[:MaxLineLength] + "..."
-		}
-		lines = append(lines, lineText)
+		lines = append(lines, truncateReadLine(scanner.Text()))
 	}
 
 	// Continue scanning to get total line count
@@ -282,6 +453,17 @@ func readTextFile(filePath string, offset, limit int) (string, int, int, error)
 	return strings.Join(lines, "\n"), len(lines), lineCount, nil
 }
 
+// readDecompressedFile reads the whole of filePath, decompressing gzip and
+// bzip2 files.
+func readDecompressedFile(filePath string) ([]byte, error) {
+	r, err := fileutil.OpenDecompressed(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
 func isImageFile(filePath string) (bool, string) {
 	ext := strings.ToLower(filepath.Ext(filePath))
 	switch ext {
@@ -330,10 +512,24 @@ func isBinaryFile(filePath string) (bool, error) {
 		return false, err
 	}
 	defer f.Close()
+	return isBinaryReader(f)
+}
+
+// isBinaryContent is isBinaryFile for the decompressed content of gzip and
+// bzip2 files.
+func isBinaryContent(filePath string) (bool, error) {
+	r, err := fileutil.OpenDecompressed(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer r.Close()
+	return isBinaryReader(r)
+}
 
+func isBinaryReader(r io.Reader) (bool, error) {
 	buf := make([]byte, 4096)
-	n, err := f.Read(buf)
-	if err != nil && !errors.Is(err, io.EOF) {
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
 		return false, err
 	}
 	if n == 0 {
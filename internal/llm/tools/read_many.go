@@ -0,0 +1,188 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+)
+
+type ReadManyParams struct {
+	Paths         []string `json:"paths"`
+	MaxTotalBytes int      `json:"max_total_bytes,omitempty"`
+}
+
+type ReadManyResponseMetadata struct {
+	FilesRead    int  `json:"files_read"`
+	FilesSkipped int  `json:"files_skipped"`
+	TotalBytes   int  `json:"total_bytes"`
+	Truncated    bool `json:"truncated"`
+}
+
+type readManyTool struct{}
+
+const (
+	ReadManyToolName          = "read_many"
+	DefaultReadManyTotalBytes = 200 * 1024
+	MaxReadManyTotalBytes     = 1024 * 1024
+	readManyDescription       = `Reads several text files in one call and returns their contents separated by "=== path ===" headers.
+
+WHEN TO USE THIS TOOL:
+- Use when you already know which files you need, e.g. all files of a small package
+- Saves a round-trip per file compared to calling the Read tool repeatedly
+
+HOW TO USE:
+- Provide the list of file paths to read
+- Optionally set max_total_bytes to cap the combined size of the returned contents
+
+FEATURES:
+- Files are returned in the order given, each under its own "=== path ===" header
+- Binary, missing and oversized files and environment files (.env) are skipped with the reason noted under their header
+- Gzip (.gz) and bzip2 (.bz2) text files are decompressed transparently
+- Once the byte budget is used up, the current file is truncated and later files are skipped
+
+LIMITATIONS:
+- Contents are returned without line numbers; use the Read tool when you need line numbers or offsets
+- Each file must be at most 250KB
+- The total budget defaults to 200KB and cannot exceed 1MB
+
+TIPS:
+- Use Glob or LS first to find the files you want, then read them together with this tool`
+)
+
+func NewReadManyTool() BaseTool {
+	return &readManyTool{}
+}
+
+func (r *readManyTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReadManyToolName,
+		ReadOnly:    true,
+		Description: readManyDescription,
+		Parameters: map[string]any{
+			"paths": map[string]any{
+				"type":        "array",
+				"description": "The paths of the files to read",
+				"items": map[string]any{
+					"type": "string",
+				},
+			},
+			"max_total_bytes": map[string]any{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum combined size of the returned contents in bytes (defaults to %d)", DefaultReadManyTotalBytes),
+			},
+		},
+		Required: []string{"paths"},
+	}
+}
+
+func (r *readManyTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ReadManyParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+	}
+
+	if len(params.Paths) == 0 {
+		return NewMissingParamResponse("paths", "paths is required"), nil
+	}
+
+	budget := params.MaxTotalBytes
+	if budget <= 0 {
+		budget = DefaultReadManyTotalBytes
+	}
+	budget = min(budget, MaxReadManyTotalBytes)
+
+	workingDir := config.WorkingDirectory()
+	var sb strings.Builder
+	var meta ReadManyResponseMetadata
+
+	for _, path := range params.Paths {
+		fmt.Fprintf(&sb, "=== %s ===\n", path)
+
+		if meta.TotalBytes >= budget {
+			meta.FilesSkipped++
+			sb.WriteString("(skipped: total byte budget exhausted)\n\n")
+			continue
+		}
+
+		filePath, content, reason := readManyFile(resolveCallPath(ctx, path), workingDir)
+		if reason != "" {
+			meta.FilesSkipped++
+			fmt.Fprintf(&sb, "(skipped: %s)\n\n", reason)
+			continue
+		}
+
+		remaining := budget - meta.TotalBytes
+		if len(content) > remaining {
+			cut := remaining
+			for cut > 0 && !utf8.RuneStart(content[cut]) {
+				cut--
+			}
+			sb.WriteString(content[:cut])
+			fmt.Fprintf(&sb, "\n(truncated: total byte budget of %d bytes reached, %d bytes omitted)\n\n", budget, len(content)-cut)
+			meta.TotalBytes = budget
+			meta.Truncated = true
+		} else {
+			sb.WriteString(content)
+			if !strings.HasSuffix(content, "\n") {
+				sb.WriteString("\n")
+			}
+			sb.WriteString("\n")
+			meta.TotalBytes += len(content)
+			// Only a file returned in full counts as read for the edit tools
+			recordFileRead(filePath)
+		}
+		meta.FilesRead++
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimRight(sb.String(), "\n")),
+		meta,
+	), nil
+}
+
+// readManyFile reads a single file for the read_many tool and returns its
+// validated path. When the file cannot be returned, reason explains why it
+// was skipped.
+func readManyFile(path, workingDir string) (filePath, content, reason string) {
+	filePath, err := ValidatePathInWorkingDirectory(path)
+	if err != nil {
+		return "", "", err.Error()
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", "file not found"
+		}
+		return "", "", err.Error()
+	}
+	if info.IsDir() {
+		return "", "", "path is a directory"
+	}
+	if limit := readSizeLimit(); info.Size() > limit {
+		return "", "", fmt.Sprintf("file is too large (%d bytes, maximum is %d); read it in chunks with the read tool's offset and limit", info.Size(), limit)
+	}
+	if isImage, imageType := isImageFile(filePath); isImage {
+		return "", "", fmt.Sprintf("image file of type %s, use the view_image tool", imageType)
+	}
+	if isEnvFile(filePath) {
+		return "", "", "environment file, use the read tool with redact_env=true to see its keys"
+	}
+	// Compressed files are binary on disk; SafeReadFile checks their decompressed content
+	if isBinary, err := isBinaryFile(filePath); err == nil && isBinary && !fileutil.IsCompressedFile(filePath) {
+		return "", "", "file appears to be binary"
+	}
+
+	content, err = fileutil.SafeReadFile(filePath, workingDir)
+	if err != nil {
+		return "", "", err.Error()
+	}
+
+	return filePath, content, ""
+}
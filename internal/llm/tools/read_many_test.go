@@ -0,0 +1,103 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runReadMany(t *testing.T, params ReadManyParams) (ToolResponse, ReadManyResponseMetadata) {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := NewReadManyTool().Run(t.Context(), ToolCall{Name: ReadManyToolName, Input: string(input)})
+	require.NoError(t, err)
+
+	var meta ReadManyResponseMetadata
+	if resp.Metadata != "" {
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	}
+	return resp, meta
+}
+
+func writeWorkingDirFile(t *testing.T, pattern string, content []byte) string {
+	t.Helper()
+	path := createTempFileInWorkingDir(t, pattern)
+	require.NoError(t, os.WriteFile(path, content, 0o644))
+	return path
+}
+
+func TestReadManyTool_SkipsBinary(t *testing.T) {
+	first := writeWorkingDirFile(t, "readmany-*.go", []byte("package a\n"))
+	binary := writeWorkingDirFile(t, "readmany-*.bin", []byte{0x00, 0x01, 0x02, 0xff, 0x00, 0x10})
+	third := writeWorkingDirFile(t, "readmany-*.txt", []byte("third file"))
+
+	resp, meta := runReadMany(t, ReadManyParams{Paths: []string{first, binary, third}})
+	require.False(t, resp.IsError)
+
+	assert.Contains(t, resp.Content, "=== "+first+" ===\npackage a\n")
+	assert.Contains(t, resp.Content, "=== "+binary+" ===\n(skipped: file appears to be binary")
+	assert.Contains(t, resp.Content, "=== "+third+" ===\nthird file")
+	assert.Less(t, strings.Index(resp.Content, first), strings.Index(resp.Content, third), "files should keep their order")
+
+	assert.Equal(t, 2, meta.FilesRead)
+	assert.Equal(t, 1, meta.FilesSkipped)
+	assert.False(t, meta.Truncated)
+}
+
+func TestReadManyTool_Gzip(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte("compressed log line\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	path := writeWorkingDirFile(t, "readmany-*.log.gz", buf.Bytes())
+
+	resp, meta := runReadMany(t, ReadManyParams{Paths: []string{path}})
+	require.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "=== "+path+" ===\ncompressed log line")
+	assert.Equal(t, 1, meta.FilesRead)
+}
+
+func TestReadManyTool_TotalBudget(t *testing.T) {
+	first := writeWorkingDirFile(t, "readmany-*.txt", []byte(strings.Repeat("a", 60)))
+	second := writeWorkingDirFile(t, "readmany-*.txt", []byte(strings.Repeat("b", 60)))
+	third := writeWorkingDirFile(t, "readmany-*.txt", []byte("never read"))
+
+	resp, meta := runReadMany(t, ReadManyParams{Paths: []string{first, second, third}, MaxTotalBytes: 100})
+	require.False(t, resp.IsError)
+
+	assert.Contains(t, resp.Content, strings.Repeat("a", 60))
+	assert.Contains(t, resp.Content, strings.Repeat("b", 40)+"\n(truncated: total byte budget of 100 bytes reached, 20 bytes omitted)")
+	assert.NotContains(t, resp.Content, strings.Repeat("b", 41))
+	assert.Contains(t, resp.Content, "=== "+third+" ===\n(skipped: total byte budget exhausted)")
+	assert.NotContains(t, resp.Content, "never read")
+
+	assert.Equal(t, 2, meta.FilesRead)
+	assert.Equal(t, 1, meta.FilesSkipped)
+	assert.Equal(t, 100, meta.TotalBytes)
+	assert.True(t, meta.Truncated)
+
+	assert.False(t, getLastReadTime(first).IsZero(), "a file returned in full counts as read")
+	assert.True(t, getLastReadTime(second).IsZero(), "a truncated file does not count as read")
+	assert.True(t, getLastReadTime(third).IsZero(), "a skipped file does not count as read")
+}
+
+func TestReadManyTool_MissingFile(t *testing.T) {
+	resp, meta := runReadMany(t, ReadManyParams{Paths: []string{"missing_read_many_file.txt"}})
+	require.False(t, resp.IsError)
+	assert.Contains(t, resp.Content, "(skipped: file not found)")
+	assert.Equal(t, 1, meta.FilesSkipped)
+}
+
+func TestReadManyTool_NoPaths(t *testing.T) {
+	resp, _ := runReadMany(t, ReadManyParams{})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "paths is required")
+}
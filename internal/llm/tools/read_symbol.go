@@ -0,0 +1,351 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
+)
+
+type ReadSymbolParams struct {
+	FilePath string `json:"file_path"`
+	Symbol   string `json:"symbol"`
+}
+
+type ReadSymbolResponseMetadata struct {
+	FilePath  string `json:"file_path"`
+	Symbol    string `json:"symbol"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	// Source is "lsp" when the language server located the symbol and
+	// "pattern" when it was found by matching declarations.
+	Source string `json:"source"`
+}
+
+// symbolLister is the part of *lsp.Client used by the read_symbol tool.
+type symbolLister interface {
+	OpenFile(ctx context.Context, filePath string) error
+	DocumentSymbol(ctx context.Context, params protocol.DocumentSymbolParams) (protocol.Or_Result_textDocument_documentSymbol, error)
+}
+
+type readSymbolTool struct {
+	// listers returns the servers that can handle filePath. It wraps
+	// lsp.ClientsForFile and is replaced in tests.
+	listers func(filePath string) []symbolLister
+}
+
+// fileSymbol is a symbol declared in a file. Lines are 0-based and inclusive.
+type fileSymbol struct {
+	name      string
+	qualified string // name prefixed with its containers, e.g. Server.Start
+	kind      string
+	startLine int
+	endLine   int
+}
+
+const (
+	ReadSymbolToolName    = "read_symbol"
+	readSymbolDescription = `Reads the source of one symbol (function, method, type, class...) from a file by name, with line numbers.
+
+WHEN TO USE THIS TOOL:
+- Use when you want to see a specific function or type rather than the whole file
+- Far cheaper than reading a large file when only one declaration matters
+
+HOW TO USE:
+- Provide the file path and the symbol name, e.g. "ParseConfig"
+- Qualify methods with their type to pick one, e.g. "Server.Start"
+
+FEATURES:
+- Uses the language server's document symbols when one is available for the file
+- Falls back to matching declarations (func, type, class, def, fn...) and their braces or indentation
+- When several symbols share the name, lists them with their lines so you can pick one
+- Line numbers match the Read tool, so the result can be used with offset and for edits
+
+LIMITATIONS:
+- The fallback recognizes common declaration styles only and may miss unusual ones
+- Environment files (.env) cannot be read with this tool`
+)
+
+// symbolDeclPattern captures the keyword and name of a declaration in the
+// pattern fallback, using the keywords of outlinePattern. A parenthesized Go
+// receiver may precede the name.
+var symbolDeclPattern = regexp.MustCompile(`\b(func|type|class|interface|struct|enum|trait|impl|fn|def|function|module|object)\s+(?:\([^)]*\)\s*)?([A-Za-z_$][\w$]*)`)
+
+// goReceiverPattern extracts the receiver type of a Go method declaration.
+var goReceiverPattern = regexp.MustCompile(`^\s*func\s*\(\s*(?:\w+\s+)?\*?\s*(\w+)`)
+
+func NewReadSymbolTool(lspService lsp.LspService) BaseTool {
+	return &readSymbolTool{
+		listers: func(filePath string) []symbolLister {
+			var listers []symbolLister
+			for _, client := range lspService.ClientsForFile(filePath) {
+				listers = append(listers, client)
+			}
+			return listers
+		},
+	}
+}
+
+func (r *readSymbolTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        ReadSymbolToolName,
+		ReadOnly:    true,
+		Description: readSymbolDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to the file declaring the symbol",
+			},
+			"symbol": map[string]any{
+				"type":        "string",
+				"description": "The name of the symbol, optionally qualified with its type, e.g. Server.Start",
+			},
+		},
+		Required: []string{"file_path", "symbol"},
+	}
+}
+
+func (r *readSymbolTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params ReadSymbolParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
+	}
+	if params.FilePath == "" {
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
+	}
+	if params.Symbol == "" {
+		return NewMissingParamResponse("symbol", "symbol is required"), nil
+	}
+
+	filePath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
+	if err != nil {
+		return NewPathErrorResponse(err, params.FilePath), nil
+	}
+	if isEnvFile(filePath) {
+		return NewTextErrorResponse("environment files cannot be read with read_symbol; use the Read tool with redact_env"), nil
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewNotFoundResponse("file not found: "+filePath, filePath), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	source := "lsp"
+	symbols, ok := r.lspSymbols(ctx, filePath)
+	if !ok {
+		source = "pattern"
+		symbols = patternSymbols(lines)
+	}
+
+	matches := matchSymbols(symbols, params.Symbol)
+	switch {
+	case len(matches) == 0:
+		return NewTextErrorResponse(fmt.Sprintf("symbol %s not found in %s", params.Symbol, filePath)), nil
+	case len(matches) > 1:
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "symbol %s is ambiguous in %s. Call again with one of:\n", params.Symbol, filePath)
+		for _, s := range matches {
+			fmt.Fprintf(&sb, "- %s (%s, lines %d-%d)\n", s.qualified, s.kind, s.startLine+1, s.endLine+1)
+		}
+		return NewTextErrorResponse(strings.TrimSuffix(sb.String(), "\n")), nil
+	}
+
+	symbol := matches[0]
+	endLine := min(symbol.endLine, len(lines)-1)
+	body := truncateReadLines(lines[symbol.startLine : endLine+1])
+	output := fmt.Sprintf("%s (%s) in %s, lines %d-%d:\n<file>\n%s\n</file>\n",
+		symbol.qualified, symbol.kind, filePath, symbol.startLine+1, endLine+1,
+		addLineNumbers(body, symbol.startLine+1))
+	recordFileRead(filePath)
+
+	return WithResponseMetadata(
+		NewTextResponse(output),
+		ReadSymbolResponseMetadata{
+			FilePath:  filePath,
+			Symbol:    symbol.qualified,
+			StartLine: symbol.startLine + 1,
+			EndLine:   endLine + 1,
+			Source:    source,
+		},
+	), nil
+}
+
+// lspSymbols asks the language servers for the file's symbols. It reports
+// false when no server handles the file or none returned any symbols.
+func (r *readSymbolTool) lspSymbols(ctx context.Context, filePath string) ([]fileSymbol, bool) {
+	for _, lister := range r.listers(filePath) {
+		if err := lister.OpenFile(ctx, filePath); err != nil {
+			continue
+		}
+		result, err := lister.DocumentSymbol(ctx, protocol.DocumentSymbolParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + filePath)},
+		})
+		if err != nil {
+			continue
+		}
+		var symbols []fileSymbol
+		switch value := result.Value.(type) {
+		case []protocol.DocumentSymbol:
+			symbols = flattenDocumentSymbols(value, "")
+		case []protocol.SymbolInformation:
+			for _, info := range value {
+				symbols = append(symbols, newFileSymbol(info.Name, info.ContainerName, info.Kind, info.Location.Range))
+			}
+		}
+		if len(symbols) > 0 {
+			return symbols, true
+		}
+	}
+	return nil, false
+}
+
+func flattenDocumentSymbols(symbols []protocol.DocumentSymbol, container string) []fileSymbol {
+	var flat []fileSymbol
+	for _, s := range symbols {
+		symbol := newFileSymbol(s.Name, container, s.Kind, s.Range)
+		flat = append(flat, symbol)
+		flat = append(flat, flattenDocumentSymbols(s.Children, symbol.qualified)...)
+	}
+	return flat
+}
+
+func newFileSymbol(name, container string, kind protocol.SymbolKind, r protocol.Range) fileSymbol {
+	qualified := name
+	if container != "" && !strings.Contains(name, ".") {
+		qualified = container + "." + name
+	}
+	return fileSymbol{
+		name:      name,
+		qualified: qualified,
+		kind:      symbolKindName(kind),
+		startLine: int(r.Start.Line),
+		endLine:   int(r.End.Line),
+	}
+}
+
+// matchSymbols returns the symbols named symbol, either plainly or qualified
+// with their containers. Method names such as "(*Server).Start", as some
+// servers report them, also match "Server.Start" and "Start".
+func matchSymbols(symbols []fileSymbol, symbol string) []fileSymbol {
+	normalize := strings.NewReplacer("(*", "", "(", "", ")", "")
+	var matches []fileSymbol
+	for _, s := range symbols {
+		name := normalize.Replace(s.name)
+		short := name[strings.LastIndex(name, ".")+1:]
+		if s.name == symbol || name == symbol || short == symbol || normalize.Replace(s.qualified) == symbol {
+			s.qualified = normalize.Replace(s.qualified)
+			matches = append(matches, s)
+		}
+	}
+	return matches
+}
+
+// patternSymbols finds declarations in lines with the same keywords as the
+// read tool's outline, ending each at its closing brace or, for blocks
+// introduced by a colon, where the indentation returns to the declaration's.
+func patternSymbols(lines []string) []fileSymbol {
+	var symbols []fileSymbol
+	for i, line := range lines {
+		if !outlinePattern.MatchString(line) {
+			continue
+		}
+		match := symbolDeclPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		symbol := fileSymbol{name: match[2], qualified: match[2], kind: match[1], startLine: i, endLine: symbolEndLine(lines, i)}
+		if receiver := goReceiverPattern.FindStringSubmatch(line); receiver != nil {
+			symbol.qualified = receiver[1] + "." + symbol.name
+			symbol.kind = "method"
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// symbolEndLine returns the last line of the declaration starting at start.
+func symbolEndLine(lines []string, start int) int {
+	// A signature may continue over several lines
+	headerEnd := start
+	for headerEnd < len(lines)-1 {
+		trimmed := strings.TrimRight(lines[headerEnd], " \t")
+		if !strings.HasSuffix(trimmed, "(") && !strings.HasSuffix(trimmed, ",") {
+			break
+		}
+		headerEnd++
+	}
+
+	if strings.HasSuffix(strings.TrimRight(lines[headerEnd], " \t"), ":") {
+		indent := indentWidth(lines[start])
+		end := headerEnd
+		for i := headerEnd + 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "" {
+				continue
+			}
+			if indentWidth(lines[i]) <= indent {
+				break
+			}
+			end = i
+		}
+		return end
+	}
+
+	depth, opened := 0, false
+	for i := start; i < len(lines); i++ {
+		for _, c := range lines[i] {
+			switch c {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+			}
+		}
+		if opened && depth <= 0 {
+			return i
+		}
+		if !opened && i >= headerEnd {
+			// A declaration without a body, e.g. type ID string
+			return headerEnd
+		}
+	}
+	return len(lines) - 1
+}
+
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+var symbolKindNames = map[protocol.SymbolKind]string{
+	protocol.Module:        "module",
+	protocol.Namespace:     "namespace",
+	protocol.Package:       "package",
+	protocol.Class:         "class",
+	protocol.Method:        "method",
+	protocol.Property:      "property",
+	protocol.Field:         "field",
+	protocol.Constructor:   "constructor",
+	protocol.Enum:          "enum",
+	protocol.Interface:     "interface",
+	protocol.Function:      "function",
+	protocol.Variable:      "variable",
+	protocol.Constant:      "constant",
+	protocol.Struct:        "struct",
+	protocol.EnumMember:    "enum member",
+	protocol.TypeParameter: "type parameter",
+}
+
+func symbolKindName(kind protocol.SymbolKind) string {
+	if name, ok := symbolKindNames[kind]; ok {
+		return name
+	}
+	return "symbol"
+}
@@ -0,0 +1,172 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSymbolLister struct {
+	symbols []protocol.DocumentSymbol
+}
+
+func (f *fakeSymbolLister) OpenFile(context.Context, string) error { return nil }
+
+func (f *fakeSymbolLister) DocumentSymbol(context.Context, protocol.DocumentSymbolParams) (protocol.Or_Result_textDocument_documentSymbol, error) {
+	return protocol.Or_Result_textDocument_documentSymbol{Value: f.symbols}, nil
+}
+
+func runReadSymbol(t *testing.T, listers []symbolLister, params ReadSymbolParams) (ToolResponse, ReadSymbolResponseMetadata) {
+	t.Helper()
+	tool := NewReadSymbolTool(&noopLspService{}).(*readSymbolTool)
+	tool.listers = func(string) []symbolLister { return listers }
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := tool.Run(t.Context(), ToolCall{Name: ReadSymbolToolName, Input: string(input)})
+	require.NoError(t, err)
+
+	var meta ReadSymbolResponseMetadata
+	if !resp.IsError {
+		require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	}
+	return resp, meta
+}
+
+func symbolRange(start, end uint32) protocol.Range {
+	return protocol.Range{Start: protocol.Position{Line: start}, End: protocol.Position{Line: end}}
+}
+
+const readSymbolSource = `package demo
+
+type Server struct {
+	addr string
+}
+
+// Start listens on the server's address.
+func (s *Server) Start() error {
+	if s.addr == "" {
+		return errNoAddr
+	}
+	return nil
+}
+
+func (c *Client) Start() error {
+	return nil
+}
+
+func Parse(input string) (Config, error) {
+	cfg := Config{}
+	return cfg, nil
+}
+
+type ID string
+`
+
+func TestReadSymbolTool_LSP(t *testing.T) {
+	path := writeWorkingDirFile(t, "readsymbol-*.go", []byte(readSymbolSource))
+	lister := &fakeSymbolLister{symbols: []protocol.DocumentSymbol{
+		{Name: "Server", Kind: protocol.Struct, Range: symbolRange(2, 4), Children: []protocol.DocumentSymbol{
+			{Name: "addr", Kind: protocol.Field, Range: symbolRange(3, 3)},
+		}},
+		{Name: "(*Server).Start", Kind: protocol.Method, Range: symbolRange(7, 12)},
+		{Name: "(*Client).Start", Kind: protocol.Method, Range: symbolRange(14, 16)},
+		{Name: "Parse", Kind: protocol.Function, Range: symbolRange(18, 21)},
+	}}
+
+	resp, meta := runReadSymbol(t, []symbolLister{lister}, ReadSymbolParams{FilePath: path, Symbol: "Parse"})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Equal(t, ReadSymbolResponseMetadata{FilePath: path, Symbol: "Parse", StartLine: 19, EndLine: 22, Source: "lsp"}, meta)
+	assert.Contains(t, resp.Content, "Parse (function)")
+	assert.Contains(t, resp.Content, "    19|func Parse(input string) (Config, error) {")
+	assert.Contains(t, resp.Content, "    22|}")
+	assert.NotContains(t, resp.Content, "type ID string")
+
+	t.Run("qualified method", func(t *testing.T) {
+		resp, meta := runReadSymbol(t, []symbolLister{lister}, ReadSymbolParams{FilePath: path, Symbol: "Server.Start"})
+		require.False(t, resp.IsError, resp.Content)
+		assert.Equal(t, 8, meta.StartLine)
+		assert.Equal(t, 13, meta.EndLine)
+	})
+
+	t.Run("nested symbol", func(t *testing.T) {
+		resp, meta := runReadSymbol(t, []symbolLister{lister}, ReadSymbolParams{FilePath: path, Symbol: "Server.addr"})
+		require.False(t, resp.IsError, resp.Content)
+		assert.Equal(t, 4, meta.StartLine)
+	})
+
+	t.Run("ambiguous name", func(t *testing.T) {
+		resp, _ := runReadSymbol(t, []symbolLister{lister}, ReadSymbolParams{FilePath: path, Symbol: "Start"})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "symbol Start is ambiguous")
+		assert.Contains(t, resp.Content, "- Server.Start (method, lines 8-13)")
+		assert.Contains(t, resp.Content, "- Client.Start (method, lines 15-17)")
+	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		resp, _ := runReadSymbol(t, []symbolLister{lister}, ReadSymbolParams{FilePath: path, Symbol: "Missing"})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "symbol Missing not found")
+	})
+}
+
+func TestReadSymbolTool_PatternFallback(t *testing.T) {
+	path := writeWorkingDirFile(t, "readsymbol-*.go", []byte(readSymbolSource))
+
+	tests := []struct {
+		symbol      string
+		start, end  int
+		wantContent string
+	}{
+		{symbol: "Parse", start: 19, end: 22, wantContent: "    21|\treturn cfg, nil"},
+		{symbol: "Server.Start", start: 8, end: 13, wantContent: "    10|\t\treturn errNoAddr"},
+		{symbol: "Server", start: 3, end: 5, wantContent: "     4|\taddr string"},
+		{symbol: "ID", start: 24, end: 24, wantContent: "    24|type ID string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			resp, meta := runReadSymbol(t, nil, ReadSymbolParams{FilePath: path, Symbol: tt.symbol})
+			require.False(t, resp.IsError, resp.Content)
+			assert.Equal(t, "pattern", meta.Source)
+			assert.Equal(t, tt.start, meta.StartLine)
+			assert.Equal(t, tt.end, meta.EndLine)
+			assert.Contains(t, resp.Content, tt.wantContent)
+		})
+	}
+
+	t.Run("ambiguous method", func(t *testing.T) {
+		resp, _ := runReadSymbol(t, nil, ReadSymbolParams{FilePath: path, Symbol: "Start"})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "- Server.Start (method, lines 8-13)")
+		assert.Contains(t, resp.Content, "- Client.Start (method, lines 15-17)")
+	})
+}
+
+func TestSymbolEndLine_IndentedBlock(t *testing.T) {
+	lines := []string{
+		"class Parser:",
+		"    def parse(self,",
+		"              text):",
+		"        return text",
+		"",
+		"    def reset(self):",
+		"        pass",
+		"",
+		"def main():",
+		"    pass",
+	}
+	symbols := patternSymbols(lines)
+	got := map[string][2]int{}
+	for _, s := range symbols {
+		got[s.name] = [2]int{s.startLine, s.endLine}
+	}
+	assert.Equal(t, map[string][2]int{
+		"Parser": {0, 6},
+		"parse":  {1, 3},
+		"reset":  {5, 6},
+		"main":   {8, 9},
+	}, got)
+}
@@ -0,0 +1,227 @@
+package tools
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runRead(t *testing.T, ctx context.Context, params ViewParams) ToolResponse {
+	t.Helper()
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := NewViewTool(&noopLspService{}).Run(ctx, ToolCall{Name: ReadToolName, Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestReadTool_CachedRead(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.txt", []byte("hello\nworld\n"))
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-cached")
+	ctx = context.WithValue(ctx, TurnIDContextKey, "turn-1")
+
+	first := runRead(t, ctx, ViewParams{FilePath: path})
+	require.False(t, first.IsError)
+	assert.Contains(t, first.Content, "hello")
+
+	second := runRead(t, ctx, ViewParams{FilePath: path})
+	require.False(t, second.IsError)
+	assert.Contains(t, second.Content, "already read this turn, unchanged")
+	assert.NotContains(t, second.Content, "hello")
+
+	forced := runRead(t, ctx, ViewParams{FilePath: path, Force: true})
+	assert.Contains(t, forced.Content, "hello")
+
+	otherRange := runRead(t, ctx, ViewParams{FilePath: path, Offset: 1})
+	assert.Contains(t, otherRange.Content, "world")
+
+	otherSession := runRead(t, context.WithValue(ctx, SessionIDContextKey, "read-other"), ViewParams{FilePath: path})
+	assert.Contains(t, otherSession.Content, "hello", "another session has not seen the content")
+}
+
+func TestReadTool_ChangedFileIsReadAgain(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.txt", []byte("before\n"))
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-changed")
+	ctx = context.WithValue(ctx, TurnIDContextKey, "turn-1")
+
+	first := runRead(t, ctx, ViewParams{FilePath: path})
+	require.Contains(t, first.Content, "before")
+
+	require.NoError(t, os.WriteFile(path, []byte("after\n"), 0o644))
+
+	second := runRead(t, ctx, ViewParams{FilePath: path})
+	require.False(t, second.IsError)
+	assert.Contains(t, second.Content, "after")
+	assert.NotContains(t, second.Content, "already read")
+}
+
+func TestReadTool_NewTurnReadsAgain(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.txt", []byte("hello\n"))
+	session := context.WithValue(t.Context(), SessionIDContextKey, "read-turns")
+
+	first := runRead(t, context.WithValue(session, TurnIDContextKey, "turn-1"), ViewParams{FilePath: path})
+	require.Contains(t, first.Content, "hello")
+
+	nextTurn := runRead(t, context.WithValue(session, TurnIDContextKey, "turn-2"), ViewParams{FilePath: path})
+	assert.Contains(t, nextTurn.Content, "hello", "the note only applies within the turn")
+
+	noTurn := runRead(t, session, ViewParams{FilePath: path})
+	assert.Contains(t, noTurn.Content, "hello", "reads outside a turn are never answered with the note")
+}
+
+// backdateRead moves the last recorded read of path back by d.
+func backdateRead(path string, d time.Duration) {
+	fileRecordMutex.Lock()
+	defer fileRecordMutex.Unlock()
+	record := fileRecords[path]
+	record.readTime = record.readTime.Add(-d)
+	fileRecords[path] = record
+}
+
+func TestWasRecentlyRead(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.txt", []byte("hello\n"))
+	assert.False(t, WasRecentlyRead(path, time.Hour), "a file no tool has read")
+
+	recordFileRead(path)
+	assert.True(t, WasRecentlyRead(path, time.Hour))
+
+	backdateRead(path, 2*alreadyReadWindow)
+	assert.False(t, WasRecentlyRead(path, alreadyReadWindow))
+}
+
+func TestReadTool_StaleReadIsReadAgain(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.txt", []byte("hello\n"))
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-stale")
+	ctx = context.WithValue(ctx, TurnIDContextKey, "turn-1")
+
+	runRead(t, ctx, ViewParams{FilePath: path})
+	backdateRead(path, 2*alreadyReadWindow)
+
+	assert.Contains(t, runRead(t, ctx, ViewParams{FilePath: path}).Content, "hello",
+		"the note is only given for files read within alreadyReadWindow")
+}
+
+func TestReadTool_ForgetFileViews(t *testing.T) {
+	path := writeWorkingDirFile(t, "read-*.txt", []byte("hello\n"))
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-summarized")
+	ctx = context.WithValue(ctx, TurnIDContextKey, "turn-1")
+
+	runRead(t, ctx, ViewParams{FilePath: path})
+	require.Contains(t, runRead(t, ctx, ViewParams{FilePath: path}).Content, "already read")
+
+	ForgetFileViews("read-summarized")
+	assert.Contains(t, runRead(t, ctx, ViewParams{FilePath: path}).Content, "hello")
+}
+
+func TestReadTool_Gzip(t *testing.T) {
+	gzipped := func(t *testing.T, content []byte) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+
+	path := writeWorkingDirFile(t, "read-*.log.gz", gzipped(t, []byte("first line\nsecond line\n")))
+	resp := runRead(t, t.Context(), ViewParams{FilePath: path, Offset: 1})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "2|second line")
+	assert.NotContains(t, resp.Content, "first line")
+
+	binary := writeWorkingDirFile(t, "read-*.bin.gz", gzipped(t, []byte{0x00, 0x01, 0x02}))
+	resp = runRead(t, t.Context(), ViewParams{FilePath: binary})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "binary")
+}
+
+func TestReadTool_SummaryMode(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.Tools.Read
+	cfg.Tools.Read.SummaryThreshold = 300
+	t.Cleanup(func() { cfg.Tools.Read = old })
+
+	var large strings.Builder
+	large.WriteString("package demo\n")
+	for i := range 400 {
+		fmt.Fprintf(&large, "func handler%d() {}\n", i)
+	}
+	large.WriteString("// end of file\n")
+	largePath := writeWorkingDirFile(t, "read-large-*.go", []byte(large.String()))
+	smallPath := writeWorkingDirFile(t, "read-small-*.go", []byte("package demo\n\nfunc small() {}\n"))
+
+	t.Run("large file is summarized", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-summary-large")
+		resp := runRead(t, ctx, ViewParams{FilePath: largePath})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "<file_summary>")
+		assert.Contains(t, resp.Content, "Outline:")
+		assert.Contains(t, resp.Content, "   150|func handler148()", "outline lists declarations from the middle")
+		assert.Contains(t, resp.Content, "First 50 lines:")
+		assert.Contains(t, resp.Content, "Last 50 lines:")
+		assert.Contains(t, resp.Content, "   402|// end of file")
+		assert.Contains(t, resp.Content, "File has 402 lines")
+		assert.NotContains(t, resp.Content, "<file>")
+	})
+
+	t.Run("full overrides", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-summary-full")
+		resp := runRead(t, ctx, ViewParams{FilePath: largePath, Full: true})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "<file>")
+		assert.NotContains(t, resp.Content, "<file_summary>")
+		assert.Contains(t, resp.Content, "   200|func handler198() {}")
+	})
+
+	t.Run("range request is not summarized", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-summary-range")
+		resp := runRead(t, ctx, ViewParams{FilePath: largePath, Offset: 100, Limit: 10})
+		assert.Contains(t, resp.Content, "<file>")
+		assert.NotContains(t, resp.Content, "<file_summary>")
+	})
+
+	t.Run("small file is read in full", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-summary-small")
+		resp := runRead(t, ctx, ViewParams{FilePath: smallPath})
+		require.False(t, resp.IsError)
+		assert.Contains(t, resp.Content, "<file>")
+		assert.Contains(t, resp.Content, "func small() {}")
+		assert.NotContains(t, resp.Content, "<file_summary>")
+	})
+}
+
+func TestReadTool_FileOverSizeLimit(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.MaxFileSizeForTools
+	cfg.MaxFileSizeForTools = 64
+	t.Cleanup(func() { cfg.MaxFileSizeForTools = old })
+
+	path := writeWorkingDirFile(t, "read-oversize-*.txt", []byte(strings.Repeat("0123456789\n", 20)))
+
+	t.Run("whole read points at chunked reading", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-oversize-whole")
+		resp := runRead(t, ctx, ViewParams{FilePath: path})
+		assert.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "file too large (220 bytes, limit 64)")
+		assert.Contains(t, resp.Content, "read it in chunks with offset and limit")
+	})
+
+	t.Run("range is read", func(t *testing.T) {
+		ctx := context.WithValue(t.Context(), SessionIDContextKey, "read-oversize-range")
+		resp := runRead(t, ctx, ViewParams{FilePath: path, Offset: 5, Limit: 2})
+		require.False(t, resp.IsError, resp.Content)
+		assert.Contains(t, resp.Content, "     6|0123456789")
+		assert.Contains(t, resp.Content, "Showing lines 6-7 of")
+	})
+}
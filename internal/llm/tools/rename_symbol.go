@@ -0,0 +1,303 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/util"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+type RenameSymbolParams struct {
+	FilePath  string `json:"file_path"`
+	Line      int    `json:"line"`
+	Character int    `json:"character"`
+	NewName   string `json:"new_name"`
+}
+
+type RenameSymbolResponseMetadata struct {
+	Files     []string `json:"files"`
+	Diff      string   `json:"diff"`
+	Additions int      `json:"additions"`
+	Removals  int      `json:"removals"`
+}
+
+// symbolRenamer is the part of *lsp.Client used by the rename_symbol tool.
+type symbolRenamer interface {
+	OpenFile(ctx context.Context, filePath string) error
+	SupportsRename() bool
+	Rename(ctx context.Context, params protocol.RenameParams) (protocol.WorkspaceEdit, error)
+}
+
+type renameSymbolTool struct {
+	lsp         lsp.LspService
+	permissions permission.Service
+	files       history.Service
+	registry    agentregistry.Registry
+	// renamers returns the servers that can handle filePath. It wraps
+	// lsp.ClientsForFile and is replaced in tests.
+	renamers func(filePath string) []symbolRenamer
+}
+
+// renamedFile is one file changed by a rename.
+type renamedFile struct {
+	path       string
+	oldContent string
+	newContent string
+	edits      int
+}
+
+const (
+	RenameSymbolToolName    = "rename_symbol"
+	renameSymbolDescription = `Renames a symbol across the project using the language server (LSP).
+
+WHEN TO USE THIS TOOL:
+- Use to rename a function, type, variable, field or method everywhere it is referenced
+- Prefer it over text replacement, which can miss references or change unrelated text
+
+HOW TO USE:
+- Provide the file containing the symbol and the position of the symbol in it
+- line and character are 1-based, as shown in editors and by the Read tool
+- Provide the new name
+
+FEATURES:
+- Every file the language server reports is updated, and file history is recorded
+- Returns a summary of the changed files
+
+LIMITATIONS:
+- Requires an LSP server for the file type that supports renaming
+- Renames that create, move or delete files are not supported`
+)
+
+func NewRenameSymbolTool(lspService lsp.LspService, permissions permission.Service, files history.Service, reg agentregistry.Registry) BaseTool {
+	return &renameSymbolTool{
+		lsp:         lspService,
+		permissions: permissions,
+		files:       files,
+		registry:    reg,
+		renamers: func(filePath string) []symbolRenamer {
+			var renamers []symbolRenamer
+			for _, client := range lspService.ClientsForFile(filePath) {
+				renamers = append(renamers, client)
+			}
+			return renamers
+		},
+	}
+}
+
+func (r *renameSymbolTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RenameSymbolToolName,
+		Description: renameSymbolDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The path to a file containing the symbol",
+			},
+			"line": map[string]any{
+				"type":        "integer",
+				"description": "The line of the symbol (1-based)",
+			},
+			"character": map[string]any{
+				"type":        "integer",
+				"description": "The character offset of the symbol in the line (1-based)",
+			},
+			"new_name": map[string]any{
+				"type":        "string",
+				"description": "The new name of the symbol",
+			},
+		},
+		Required: []string{"file_path", "line", "character", "new_name"},
+	}
+}
+
+func (r *renameSymbolTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RenameSymbolParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
+	}
+
+	if params.FilePath == "" {
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
+	}
+	if params.NewName == "" {
+		return NewMissingParamResponse("new_name", "new_name is required"), nil
+	}
+	if params.Line < 1 || params.Character < 1 {
+		return NewTextErrorResponse("line and character must be 1-based positive numbers"), nil
+	}
+
+	filePath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
+	if err != nil {
+		return NewPathErrorResponse(err, params.FilePath), nil
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return NewNotFoundResponse("file not found: "+filePath, filePath), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return NewEmptyResponse(), errors.New("session ID and message ID are required for renaming a symbol")
+	}
+
+	renamers := r.renamers(filePath)
+	if len(renamers) == 0 {
+		return NewTextErrorResponse("no LSP server available for this file type"), nil
+	}
+	idx := slices.IndexFunc(renamers, symbolRenamer.SupportsRename)
+	if idx == -1 {
+		return NewTextErrorResponse("the LSP server for this file type does not support renaming symbols"), nil
+	}
+	renamer := renamers[idx]
+
+	if err := renamer.OpenFile(ctx, filePath); err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("failed to open file in LSP server: %s", err)), nil
+	}
+	workspaceEdit, err := renamer.Rename(ctx, protocol.RenameParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + filePath)},
+		Position: protocol.Position{
+			Line:      uint32(params.Line - 1),
+			Character: uint32(params.Character - 1),
+		},
+		NewName: params.NewName,
+	})
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("rename failed: %s", err)), nil
+	}
+
+	changed, err := renamedFiles(workspaceEdit)
+	if err != nil {
+		return NewTextErrorResponse(fmt.Sprintf("cannot apply rename: %s", err)), nil
+	}
+	if len(changed) == 0 {
+		return NewTextErrorResponse("the LSP server returned no changes for this rename"), nil
+	}
+
+	var combinedDiff strings.Builder
+	var permissionFiles []string
+	totalAdditions, totalRemovals := 0, 0
+	for _, f := range changed {
+		fileDiff, additions, removals := diff.GenerateDiff(f.oldContent, f.newContent, f.path)
+		combinedDiff.WriteString(fileDiff + "\n")
+		totalAdditions += additions
+		totalRemovals += removals
+
+		switch evaluateFilePermission(ctx, r.registry, RenameSymbolToolName, f.path) {
+		case permission.ActionAllow:
+		case permission.ActionDeny:
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		default:
+			permissionFiles = append(permissionFiles, f.path)
+		}
+	}
+
+	// A protected file blocks the whole rename, which is applied to every
+	// file or none
+	for _, f := range changed {
+		if response, err := checkProtectedFile(ctx, r.permissions, RenameSymbolToolName, f.path); err != nil || response.IsError {
+			return response, err
+		}
+	}
+
+	if len(permissionFiles) > 0 {
+		allowed := r.permissions.Request(
+			permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        config.WorkingDirectory(),
+				ToolName:    RenameSymbolToolName,
+				Action:      "write",
+				Description: fmt.Sprintf("Rename symbol to %s in %d files: %s", params.NewName, len(permissionFiles), strings.Join(permissionFiles, ", ")),
+				Params: EditPermissionsParams{
+					FilePath: strings.Join(permissionFiles, ", "),
+					Diff:     combinedDiff.String(),
+				},
+			},
+		)
+		if !allowed {
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+	}
+
+	paths := make([]string, 0, len(changed))
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "Renamed symbol to %s in %d files:\n", params.NewName, len(changed))
+	for _, f := range changed {
+		if err := writeFileAtomic(ctx, f.path, []byte(f.newContent)); err != nil {
+			return NewEmptyResponse(), fmt.Errorf("failed to write file %s: %w", f.path, err)
+		}
+		if err := recordOverwriteHistory(ctx, r.files, sessionID, f.path, f.oldContent, f.newContent, true); err != nil {
+			return NewEmptyResponse(), err
+		}
+		recordFileWrite(f.path)
+		recordFileRead(f.path)
+		r.lsp.NotifyOpenFile(ctx, f.path)
+
+		paths = append(paths, f.path)
+		relPath, err := filepath.Rel(config.WorkingDirectory(), f.path)
+		if err != nil {
+			relPath = f.path
+		}
+		fmt.Fprintf(&summary, "- %s (%d edits)\n", relPath, f.edits)
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(strings.TrimSuffix(summary.String(), "\n")),
+		RenameSymbolResponseMetadata{
+			Files:     paths,
+			Diff:      combinedDiff.String(),
+			Additions: totalAdditions,
+			Removals:  totalRemovals,
+		},
+	), nil
+}
+
+// renamedFiles computes the new content of every file in a rename's
+// workspace edit, sorted by path. Files outside the working directory are
+// rejected so a rename never changes dependencies or system files.
+func renamedFiles(workspaceEdit protocol.WorkspaceEdit) ([]renamedFile, error) {
+	editsByPath, err := util.TextEditsByPath(workspaceEdit)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]renamedFile, 0, len(editsByPath))
+	for path, edits := range editsByPath {
+		if _, err := ValidatePathInWorkingDirectory(path); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		newContent, err := util.ApplyTextEditsToContent(content, edits)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if string(newContent) == string(content) {
+			continue
+		}
+		changed = append(changed, renamedFile{
+			path:       path,
+			oldContent: string(content),
+			newContent: string(newContent),
+			edits:      len(edits),
+		})
+	}
+	slices.SortFunc(changed, func(a, b renamedFile) int { return strings.Compare(a.path, b.path) })
+	return changed, nil
+}
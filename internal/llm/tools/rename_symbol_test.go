@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeRenamer struct {
+	supportsRename bool
+	edit           protocol.WorkspaceEdit
+	params         protocol.RenameParams
+}
+
+func (f *fakeRenamer) OpenFile(context.Context, string) error { return nil }
+func (f *fakeRenamer) SupportsRename() bool                   { return f.supportsRename }
+
+func (f *fakeRenamer) Rename(_ context.Context, params protocol.RenameParams) (protocol.WorkspaceEdit, error) {
+	f.params = params
+	return f.edit, nil
+}
+
+func newTestRenameTool(t *testing.T, renamer *fakeRenamer) (BaseTool, *stubHistoryService) {
+	t.Helper()
+	files := newStubHistoryService()
+	tool := NewRenameSymbolTool(&noopLspService{}, mock_permission.NewMockService(gomock.NewController(t)), files, &stubRegistry{}).(*renameSymbolTool)
+	tool.renamers = func(string) []symbolRenamer { return []symbolRenamer{renamer} }
+	return tool, files
+}
+
+func runRenameSymbol(t *testing.T, tool BaseTool, params RenameSymbolParams) ToolResponse {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	input, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := tool.Run(ctx, ToolCall{Name: RenameSymbolToolName, Input: string(input)})
+	require.NoError(t, err)
+	return resp
+}
+
+func textEdit(line, start, end uint32, newText string) protocol.TextEdit {
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: protocol.Position{Line: line, Character: start},
+			End:   protocol.Position{Line: line, Character: end},
+		},
+		NewText: newText,
+	}
+}
+
+func TestRenameSymbolTool_MultiFileEdit(t *testing.T) {
+	def := writeWorkingDirFile(t, "rename-def-*.go", []byte("package demo\n\nfunc oldName() {}\n"))
+	use := writeWorkingDirFile(t, "rename-use-*.go", []byte("package demo\n\nfunc caller() {\n\toldName()\n\toldName()\n}\n"))
+
+	renamer := &fakeRenamer{
+		supportsRename: true,
+		edit: protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				protocol.DocumentUri("file://" + def): {textEdit(2, 5, 12, "newName")},
+			},
+			DocumentChanges: []protocol.DocumentChange{{
+				TextDocumentEdit: &protocol.TextDocumentEdit{
+					TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+						TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: protocol.DocumentUri("file://" + use)},
+					},
+					Edits: []protocol.Or_TextDocumentEdit_edits_Elem{
+						{Value: textEdit(3, 1, 8, "newName")},
+						{Value: textEdit(4, 1, 8, "newName")},
+					},
+				},
+			}},
+		},
+	}
+	tool, files := newTestRenameTool(t, renamer)
+
+	resp := runRenameSymbol(t, tool, RenameSymbolParams{FilePath: def, Line: 3, Character: 6, NewName: "newName"})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "Renamed symbol to newName in 2 files")
+	assert.Contains(t, resp.Content, "(2 edits)")
+
+	assert.Equal(t, protocol.Position{Line: 2, Character: 5}, renamer.params.Position, "position is sent 0-based")
+	assert.Equal(t, "newName", renamer.params.NewName)
+
+	defContent, err := os.ReadFile(def)
+	require.NoError(t, err)
+	assert.Equal(t, "package demo\n\nfunc newName() {}\n", string(defContent))
+	useContent, err := os.ReadFile(use)
+	require.NoError(t, err)
+	assert.Equal(t, "package demo\n\nfunc caller() {\n\tnewName()\n\tnewName()\n}\n", string(useContent))
+
+	assert.Contains(t, files.versions, string(defContent), "history records the renamed definition")
+	assert.Contains(t, files.versions, string(useContent), "history records the renamed caller")
+	assert.Contains(t, files.versions, "package demo\n\nfunc oldName() {}\n", "history keeps the previous content")
+
+	var meta RenameSymbolResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.ElementsMatch(t, []string{def, use}, meta.Files)
+}
+
+func TestRenameSymbolTool_NoRenameSupport(t *testing.T) {
+	path := writeWorkingDirFile(t, "rename-*.go", []byte("package demo\n"))
+	tool, _ := newTestRenameTool(t, &fakeRenamer{})
+
+	resp := runRenameSymbol(t, tool, RenameSymbolParams{FilePath: path, Line: 1, Character: 9, NewName: "other"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "does not support renaming")
+}
+
+func TestRenameSymbolTool_RejectsFileOperations(t *testing.T) {
+	path := writeWorkingDirFile(t, "rename-*.go", []byte("package demo\n"))
+	tool, _ := newTestRenameTool(t, &fakeRenamer{
+		supportsRename: true,
+		edit: protocol.WorkspaceEdit{
+			DocumentChanges: []protocol.DocumentChange{{
+				RenameFile: &protocol.RenameFile{OldURI: protocol.DocumentUri("file://" + path), NewURI: "file:///tmp/other.go"},
+			}},
+		},
+	})
+
+	resp := runRenameSymbol(t, tool, RenameSymbolParams{FilePath: path, Line: 1, Character: 9, NewName: "other"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "not supported")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "package demo\n", string(content))
+}
+
+func TestRenameSymbolTool_GuardsGeneratedFiles(t *testing.T) {
+	cfg := config.Get()
+	old := cfg.Guard
+	t.Cleanup(func() { cfg.Guard = old })
+	cfg.Guard = config.GuardConfig{ProtectGenerated: true, Strict: true}
+
+	def := writeWorkingDirFile(t, "rename-def-*.go", []byte("package demo\n\nfunc oldName() {}\n"))
+	generated := writeWorkingDirFile(t, "rename-gen-*.go", []byte("// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage demo\n\nvar _ = oldName\n"))
+	tool, files := newTestRenameTool(t, &fakeRenamer{
+		supportsRename: true,
+		edit: protocol.WorkspaceEdit{
+			Changes: map[protocol.DocumentUri][]protocol.TextEdit{
+				protocol.DocumentUri("file://" + def):       {textEdit(2, 5, 12, "newName")},
+				protocol.DocumentUri("file://" + generated): {textEdit(4, 8, 15, "newName")},
+			},
+		},
+	})
+
+	resp := runRenameSymbol(t, tool, RenameSymbolParams{FilePath: def, Line: 3, Character: 6, NewName: "newName"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "is a generated file")
+
+	content, err := os.ReadFile(def)
+	require.NoError(t, err)
+	assert.Equal(t, "package demo\n\nfunc oldName() {}\n", string(content), "no file is renamed")
+	assert.Empty(t, files.versions)
+}
@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/skill"
+)
+
+type RepoOverviewParams struct {
+	Depth int `json:"depth,omitempty"`
+}
+
+type RepoOverviewResponseMetadata struct {
+	BuildFiles   []string `json:"build_files"`
+	ContextFiles []string `json:"context_files"`
+	Skills       int      `json:"skills"`
+	Truncated    bool     `json:"truncated"`
+}
+
+type repoOverviewTool struct {
+	cfg config.Configurator
+}
+
+const (
+	RepoOverviewToolName    = "repo_overview"
+	repoOverviewDescription = `Summarizes the structure of the current repository in one call.
+
+WHEN TO USE THIS TOOL:
+- At the start of work in an unfamiliar repository, before exploring with ls, glob or grep
+- To find out which languages, build systems and instruction files a project uses
+
+HOW TO USE:
+- Call it without parameters for a two-level directory tree
+- Optionally set depth (1-4) to show more or fewer directory levels
+
+FEATURES:
+- Lists build and manifest files found at the root (go.mod, package.json, Cargo.toml, ...)
+- Counts source files per language
+- Shows a depth-limited directory tree
+- Lists the context instruction files (AGENTS.md, CLAUDE.md, ...) and the available skills
+
+LIMITATIONS:
+- Output is bounded; large trees are cut off and marked as truncated
+- Hidden files and the tools.defaultIgnore patterns are skipped
+
+TIPS:
+- Follow up with ls or glob on the directories that look relevant
+- Read the listed context files before making changes`
+
+	defaultOverviewDepth = 2
+	maxOverviewDepth     = 4
+	// Bounds that keep the overview small enough for a single tool result.
+	maxOverviewTreeEntries = 150
+	maxOverviewScanFiles   = 10000
+	maxOverviewLanguages   = 8
+	maxOverviewSkills      = 20
+	maxOverviewChars       = 8000
+)
+
+// repoBuildFiles maps well-known build and manifest files to the ecosystem
+// they indicate, in the order they are reported.
+var repoBuildFiles = []struct {
+	name      string
+	ecosystem string
+}{
+	{"go.mod", "Go modules"},
+	{"package.json", "Node.js"},
+	{"Cargo.toml", "Rust (Cargo)"},
+	{"pyproject.toml", "Python"},
+	{"requirements.txt", "Python"},
+	{"setup.py", "Python"},
+	{"pom.xml", "Java (Maven)"},
+	{"build.gradle", "Java/Kotlin (Gradle)"},
+	{"build.gradle.kts", "Java/Kotlin (Gradle)"},
+	{"Gemfile", "Ruby (Bundler)"},
+	{"composer.json", "PHP (Composer)"},
+	{"mix.exs", "Elixir (Mix)"},
+	{"CMakeLists.txt", "CMake"},
+	{"Makefile", "Make"},
+}
+
+func NewRepoOverviewTool(cfg config.Configurator) BaseTool {
+	return &repoOverviewTool{cfg: cfg}
+}
+
+func (r *repoOverviewTool) Info() ToolInfo {
+	return ToolInfo{
+		Name:        RepoOverviewToolName,
+		ReadOnly:    true,
+		Description: repoOverviewDescription,
+		Parameters: map[string]any{
+			"depth": map[string]any{
+				"type":        "integer",
+				"description": "Number of directory levels to show in the tree (default 2, max 4)",
+			},
+		},
+		Required: []string{},
+	}
+}
+
+func (r *repoOverviewTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params RepoOverviewParams
+	if call.Input != "" {
+		if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+			return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
+		}
+	}
+
+	depth := params.Depth
+	if depth <= 0 {
+		depth = defaultOverviewDepth
+	}
+	depth = min(depth, maxOverviewDepth)
+
+	root := r.cfg.WorkingDirectory()
+	scan, err := scanRepository(ctx, root, depth)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("error scanning repository: %w", err)
+	}
+
+	var buildFiles []string
+	var out strings.Builder
+	fmt.Fprintf(&out, "# Repository: %s\n", fileutil.NormalizePathForAI(root))
+
+	out.WriteString("\n## Build files\n")
+	for _, bf := range repoBuildFiles {
+		if info, err := os.Stat(filepath.Join(root, bf.name)); err == nil && !info.IsDir() {
+			buildFiles = append(buildFiles, bf.name)
+			fmt.Fprintf(&out, "- %s (%s)\n", bf.name, bf.ecosystem)
+		}
+	}
+	if len(buildFiles) == 0 {
+		out.WriteString("- none found\n")
+	}
+
+	if languages := topLanguages(scan.languages, maxOverviewLanguages); len(languages) > 0 {
+		out.WriteString("\n## Languages (files)\n")
+		out.WriteString(strings.Join(languages, ", ") + "\n")
+	}
+
+	fmt.Fprintf(&out, "\n## Structure (depth %d)\n", depth)
+	out.WriteString(printTree(createFileTree(scan.treePaths), root))
+	if scan.truncated {
+		fmt.Fprintf(&out, "(tree truncated after %d entries)\n", maxOverviewTreeEntries)
+	}
+
+	var contextFiles []string
+	if cfg := config.Get(); cfg != nil {
+		contextFiles = config.ResolveContextFiles(root, cfg.ContextPaths)
+	}
+	if len(contextFiles) > 0 {
+		out.WriteString("\n## Context files\n")
+		for _, f := range contextFiles {
+			fmt.Fprintf(&out, "- %s\n", filepath.ToSlash(f))
+		}
+	}
+
+	skills := skill.All()
+	if len(skills) > 0 {
+		slices.SortFunc(skills, func(a, b skill.Info) int { return cmp.Compare(a.Name, b.Name) })
+		out.WriteString("\n## Skills\n")
+		for i, s := range skills {
+			if i == maxOverviewSkills {
+				fmt.Fprintf(&out, "- ... and %d more\n", len(skills)-maxOverviewSkills)
+				break
+			}
+			fmt.Fprintf(&out, "- %s: %s\n", s.Name, truncateOverviewLine(s.Description, 100))
+		}
+	}
+
+	output := out.String()
+	truncated := scan.truncated
+	if len(output) > maxOverviewChars {
+		cut := maxOverviewChars
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+		output = output[:cut] + "\n(overview truncated)"
+		truncated = true
+	}
+
+	return WithResponseMetadata(
+		NewTextResponse(output),
+		RepoOverviewResponseMetadata{
+			BuildFiles:   buildFiles,
+			ContextFiles: contextFiles,
+			Skills:       len(skills),
+			Truncated:    truncated,
+		},
+	), nil
+}
+
+type repoScan struct {
+	treePaths []string
+	languages map[string]int
+	truncated bool
+}
+
+// scanRepository walks root once, collecting the entries up to depth for the
+// tree and counting files per language across the whole tree.
+func scanRepository(ctx context.Context, root string, depth int) (repoScan, error) {
+	scan := repoScan{languages: make(map[string]int)}
+	ignore := ignorePatterns(nil, false)
+	scanned := 0
+
+	root = filepath.Clean(root)
+	err := fileutil.Walk(root, followSymlinks(), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		path = filepath.Clean(path)
+		if path == root {
+			return nil
+		}
+		if shouldSkip(path, ignore, root) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		level := len(strings.Split(rel, string(filepath.Separator)))
+		if level <= depth {
+			if len(scan.treePaths) < maxOverviewTreeEntries {
+				if info.IsDir() {
+					rel += string(filepath.Separator)
+				}
+				scan.treePaths = append(scan.treePaths, rel)
+			} else {
+				scan.truncated = true
+			}
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+		if lang := lsp.DetectLanguageID(path); lang != "" {
+			scan.languages[string(lang)]++
+		}
+		scanned++
+		if scanned >= maxOverviewScanFiles {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return scan, err
+}
+
+// topLanguages formats the most common languages as "name (count)".
+func topLanguages(counts map[string]int, limit int) []string {
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+
+	result := make([]string, 0, min(limit, len(names)))
+	for _, name := range names[:min(limit, len(names))] {
+		result = append(result, fmt.Sprintf("%s (%d)", name, counts[name]))
+	}
+	return result
+}
+
+func truncateOverviewLine(s string, limit int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= limit {
+		return s
+	}
+	return s[:limit-3] + "..."
+}
@@ -0,0 +1,97 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedWorkingDir string
+
+func (d fixedWorkingDir) WorkingDirectory() string { return string(d) }
+
+func writeFixtureFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestRepoOverviewTool_Run(t *testing.T) {
+	root := createTempDirInWorkingDir(t, "overview_fixture_*")
+	writeFixtureFile(t, root, "go.mod", "module example.com/fixture\n")
+	writeFixtureFile(t, root, "AGENTS.md", "# Instructions\n")
+	writeFixtureFile(t, root, "cmd/app/main.go", "package main\n")
+	writeFixtureFile(t, root, "internal/store/store.go", "package store\n")
+	writeFixtureFile(t, root, "internal/store/deep/nested/file.go", "package nested\n")
+	writeFixtureFile(t, root, "web/index.ts", "export {}\n")
+
+	tool := NewRepoOverviewTool(fixedWorkingDir(root))
+	resp, err := tool.Run(context.Background(), ToolCall{Name: RepoOverviewToolName, Input: `{}`})
+	require.NoError(t, err)
+	require.False(t, resp.IsError, resp.Content)
+
+	assert.Contains(t, resp.Content, "go.mod (Go modules)")
+	for _, dir := range []string{"- cmd/", "- internal/", "- web/"} {
+		assert.Contains(t, resp.Content, dir)
+	}
+	assert.Contains(t, resp.Content, "go (3)")
+	assert.Contains(t, resp.Content, "- AGENTS.md")
+	// Depth 2 stops before internal/store/deep/nested
+	assert.Contains(t, resp.Content, "store/")
+	assert.NotContains(t, resp.Content, "nested")
+
+	var meta RepoOverviewResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.Equal(t, []string{"go.mod"}, meta.BuildFiles)
+	assert.Contains(t, meta.ContextFiles, "AGENTS.md")
+	assert.False(t, meta.Truncated)
+}
+
+func TestRepoOverviewTool_Depth(t *testing.T) {
+	root := createTempDirInWorkingDir(t, "overview_fixture_*")
+	writeFixtureFile(t, root, "package.json", "{}\n")
+	writeFixtureFile(t, root, "src/components/button/Button.tsx", "export {}\n")
+
+	tool := NewRepoOverviewTool(fixedWorkingDir(root))
+
+	resp, err := tool.Run(context.Background(), ToolCall{Name: RepoOverviewToolName, Input: `{"depth":1}`})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "package.json (Node.js)")
+	assert.Contains(t, resp.Content, "- src/")
+	assert.NotContains(t, resp.Content, "components/")
+
+	resp, err = tool.Run(context.Background(), ToolCall{Name: RepoOverviewToolName, Input: `{"depth":4}`})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "Button.tsx")
+}
+
+func TestTopLanguages(t *testing.T) {
+	got := topLanguages(map[string]int{"go": 10, "python": 3, "yaml": 3, "json": 1}, 3)
+	assert.Equal(t, []string{"go (10)", "python (3)", "yaml (3)"}, got)
+}
+
+func TestRepoOverviewTool_TruncatesOnRuneBoundary(t *testing.T) {
+	root := createTempDirInWorkingDir(t, "overview_fixture_*")
+	for i := range 200 {
+		writeFixtureFile(t, root, fmt.Sprintf("a%s%03d/a.txt", strings.Repeat("日本", 20), i), "x\n")
+	}
+
+	tool := NewRepoOverviewTool(fixedWorkingDir(root))
+	resp, err := tool.Run(context.Background(), ToolCall{Name: RepoOverviewToolName, Input: `{"depth":1}`})
+	require.NoError(t, err)
+
+	var meta RepoOverviewResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	require.True(t, meta.Truncated)
+	assert.True(t, utf8.ValidString(resp.Content), "the overview was cut inside a character")
+	assert.True(t, strings.HasSuffix(resp.Content, "\n(overview truncated)"))
+}
@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	agentregistry "github.com/MerrukTechnology/OpenCode-Native/internal/agent"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
+)
+
+type SearchReplaceParams struct {
+	FilePath string `json:"file_path"`
+	Blocks   string `json:"blocks"`
+}
+
+type SearchReplaceResponseMetadata struct {
+	Diff      string `json:"diff"`
+	Additions int    `json:"additions"`
+	Removals  int    `json:"removals"`
+	Blocks    int    `json:"blocks"`
+}
+
+// SearchReplaceBlock is one SEARCH/REPLACE pair parsed from the block format.
+type SearchReplaceBlock struct {
+	Search  string
+	Replace string
+}
+
+type searchReplaceTool struct {
+	lsp         lsp.LspService
+	permissions permission.Service
+	files       history.Service
+	registry    agentregistry.Registry
+}
+
+const (
+	SearchReplaceToolName    = "search_replace"
+	searchReplaceDescription = `Applies one or more SEARCH/REPLACE blocks to a single file.
+
+WHEN TO USE THIS TOOL:
+- When you find it easier to express changes as SEARCH/REPLACE blocks than as edit parameters
+- To make several sequential changes to the same file in one call
+
+HOW TO USE:
+- Read the file first with the Read tool
+- Provide file_path and the blocks text, with each block written as:
+
+<<<<<<< SEARCH
+exact text currently in the file
+=======
+text to replace it with
+>>>>>>> REPLACE
+
+FEATURES:
+- Blocks are applied in order, each to the result of the previous one
+- All blocks are atomic: if any block fails, the file is left unchanged
+- Text outside the blocks (file names, code fences) is ignored
+
+LIMITATIONS:
+- Each SEARCH text must match the file exactly, including whitespace, and occur exactly once
+- SEARCH cannot be empty; use the Write tool to create new files
+
+TIPS:
+- Include a few surrounding lines in SEARCH to make the match unique
+- Keep blocks small and focused on the lines that change`
+
+	searchMarker  = "<<<<<<< SEARCH"
+	dividerMarker = "======="
+	replaceMarker = ">>>>>>> REPLACE"
+)
+
+func NewSearchReplaceTool(lspService lsp.LspService, permissions permission.Service, files history.Service, reg agentregistry.Registry) BaseTool {
+	return &searchReplaceTool{
+		lsp:         lspService,
+		permissions: permissions,
+		files:       files,
+		registry:    reg,
+	}
+}
+
+func (s *searchReplaceTool) Info() ToolInfo {
+	return withPreconditions(ToolInfo{
+		Name:        SearchReplaceToolName,
+		Description: searchReplaceDescription,
+		Parameters: map[string]any{
+			"file_path": map[string]any{
+				"type":        "string",
+				"description": "The absolute path to the file to modify",
+			},
+			"blocks": map[string]any{
+				"type":        "string",
+				"description": "One or more <<<<<<< SEARCH / ======= / >>>>>>> REPLACE blocks",
+			},
+		},
+		Required: []string{"file_path", "blocks"},
+	}, ReadFilePrecondition)
+}
+
+// ParseSearchReplaceBlocks extracts the SEARCH/REPLACE blocks from text.
+// Marker lines must appear on their own; anything outside a block is ignored.
+func ParseSearchReplaceBlocks(text string) ([]SearchReplaceBlock, error) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var blocks []SearchReplaceBlock
+	for i := 0; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) != searchMarker {
+			continue
+		}
+		n := len(blocks) + 1
+
+		var search, replace []string
+		j := i + 1
+		for ; j < len(lines) && strings.TrimSpace(lines[j]) != dividerMarker; j++ {
+			if strings.TrimSpace(lines[j]) == searchMarker || strings.TrimSpace(lines[j]) == replaceMarker {
+				return nil, fmt.Errorf("block %d: expected %q before line %d", n, dividerMarker, j+1)
+			}
+			search = append(search, lines[j])
+		}
+		if j == len(lines) {
+			return nil, fmt.Errorf("block %d: missing %q", n, dividerMarker)
+		}
+
+		k := j + 1
+		for ; k < len(lines) && strings.TrimSpace(lines[k]) != replaceMarker; k++ {
+			if strings.TrimSpace(lines[k]) == searchMarker || strings.TrimSpace(lines[k]) == dividerMarker {
+				return nil, fmt.Errorf("block %d: expected %q before line %d", n, replaceMarker, k+1)
+			}
+			replace = append(replace, lines[k])
+		}
+		if k == len(lines) {
+			return nil, fmt.Errorf("block %d: missing %q", n, replaceMarker)
+		}
+
+		blocks = append(blocks, SearchReplaceBlock{
+			Search:  strings.Join(search, "\n"),
+			Replace: strings.Join(replace, "\n"),
+		})
+		i = k
+	}
+	return blocks, nil
+}
+
+// applySearchReplaceBlocks applies blocks to content in order. Each SEARCH
+// must match exactly once in the content produced by the previous blocks.
+func applySearchReplaceBlocks(content string, blocks []SearchReplaceBlock, filePath string) (string, []MultiEditPermissionEdit, error) {
+	edits := make([]MultiEditPermissionEdit, 0, len(blocks))
+	for i, block := range blocks {
+		if block.Search == "" {
+			return "", nil, fmt.Errorf("block %d: SEARCH cannot be empty", i+1)
+		}
+		if block.Search == block.Replace {
+			return "", nil, fmt.Errorf("block %d: SEARCH and REPLACE are identical", i+1)
+		}
+
+		index := strings.Index(content, block.Search)
+		if index == -1 {
+			firstLine, _, _ := strings.Cut(block.Search, "\n")
+			return "", nil, fmt.Errorf("block %d: SEARCH text not found in file (starting with %q). Make sure it matches exactly, including whitespace and line breaks", i+1, firstLine)
+		}
+		if count := strings.Count(content, block.Search); count > 1 {
+			return "", nil, fmt.Errorf("block %d: SEARCH text appears %d times in the file. Include more surrounding lines to make it unique", i+1, count)
+		}
+
+		before := content
+		content = content[:index] + block.Replace + content[index+len(block.Search):]
+		editDiff, _, _ := diff.GenerateDiff(before, content, filePath)
+		edits = append(edits, MultiEditPermissionEdit{
+			Diff:       editDiff,
+			LineNumber: strings.Count(before[:index], "\n") + 1,
+		})
+	}
+	return content, edits, nil
+}
+
+func (s *searchReplaceTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
+	var params SearchReplaceParams
+	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
+	}
+
+	if params.FilePath == "" {
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
+	}
+	if strings.TrimSpace(params.Blocks) == "" {
+		return NewMissingParamResponse("blocks", "blocks is required"), nil
+	}
+
+	blocks, err := ParseSearchReplaceBlocks(params.Blocks)
+	if err != nil {
+		return NewTextErrorResponse("invalid blocks: " + err.Error()), nil
+	}
+	if len(blocks) == 0 {
+		return NewTextErrorResponse("no SEARCH/REPLACE blocks found. Each block must start with a line containing only " + searchMarker), nil
+	}
+
+	filePath := fileutil.ResolvePath(params.FilePath, callDirectory(ctx))
+
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewNotFoundResponse("file not found: "+filePath, filePath), nil
+		}
+		return NewEmptyResponse(), fmt.Errorf("failed to access file: %w", err)
+	}
+	if fileInfo.IsDir() {
+		return NewTextErrorResponse("path is a directory, not a file: " + filePath), nil
+	}
+
+	if isEnvFile(filePath) {
+		return envFileWriteError(filePath), nil
+	}
+
+	if response, err := checkProtectedFile(ctx, s.permissions, SearchReplaceToolName, filePath); err != nil || response.IsError {
+		return response, err
+	}
+
+	lastRead := getLastReadTime(filePath)
+	if lastRead.IsZero() {
+		return NewTextErrorResponse("you must read the file before editing it. Use the Read tool first"), nil
+	}
+	if modTime := fileInfo.ModTime(); modTime.After(lastRead) {
+		return NewTextErrorResponse(
+			fmt.Sprintf("file %s has been modified since it was last read (mod time: %s, last read: %s)",
+				filePath, modTime.Format(time.RFC3339), lastRead.Format(time.RFC3339),
+			)), nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return NewEmptyResponse(), fmt.Errorf("failed to read file: %w", err)
+	}
+	oldContent := strings.ReplaceAll(string(content), "\r\n", "\n")
+
+	newContent, edits, err := applySearchReplaceBlocks(oldContent, blocks, filePath)
+	if err != nil {
+		return NewTextErrorResponse(err.Error()), nil
+	}
+
+	sessionID, messageID := GetContextValues(ctx)
+	if sessionID == "" || messageID == "" {
+		return NewEmptyResponse(), errors.New("session ID and message ID are required")
+	}
+
+	syntaxResponse, syntaxWarning := checkSyntax(filePath, oldContent, newContent)
+	if syntaxResponse.IsError {
+		return syntaxResponse, nil
+	}
+
+	combinedDiff, additions, removals := diff.GenerateDiff(oldContent, newContent, filePath)
+
+	rootDir := config.WorkingDirectory()
+	permissionPath := filepath.Dir(filePath)
+	if strings.HasPrefix(filePath, rootDir) {
+		permissionPath = rootDir
+	}
+	switch evaluateFilePermission(ctx, s.registry, SearchReplaceToolName, filePath) {
+	case permission.ActionAllow:
+		// Allowed by config
+	case permission.ActionDeny:
+		return NewEmptyResponse(), permission.ErrorPermissionDenied
+	default:
+		p := s.permissions.Request(
+			permission.CreatePermissionRequest{
+				SessionID:   sessionID,
+				Path:        permissionPath,
+				ToolName:    SearchReplaceToolName,
+				Action:      "write",
+				Description: fmt.Sprintf("Apply %d search/replace blocks to file %s", len(blocks), filePath),
+				Params: MultiEditPermissionsParams{
+					FilePath: filePath,
+					Edits:    edits,
+				},
+			},
+		)
+		if !p {
+			return NewEmptyResponse(), permission.ErrorPermissionDenied
+		}
+	}
+
+	if err := writeEditedFile(ctx, filePath, []byte(newContent)); err != nil {
+		return NewEmptyResponse(), fmt.Errorf("failed to write file: %w", err)
+	}
+
+	if err := recordOverwriteHistory(ctx, s.files, sessionID, filePath, oldContent, newContent, true); err != nil {
+		return NewEmptyResponse(), err
+	}
+
+	recordFileWrite(filePath)
+	recordFileRead(filePath)
+
+	checks := make([]editCheck, len(blocks))
+	for i, block := range blocks {
+		checks[i] = editCheck{newString: block.Replace}
+	}
+	warning := verifyEditWritten(filePath, newContent, checks...) + syntaxWarning
+
+	response := WithResponseMetadata(
+		NewTextResponse(fmt.Sprintf("%d search/replace blocks applied to file: %s%s", len(blocks), filePath, warning)),
+		SearchReplaceResponseMetadata{
+			Diff:      combinedDiff,
+			Additions: additions,
+			Removals:  removals,
+			Blocks:    len(blocks),
+		},
+	)
+
+	s.lsp.WaitForDiagnostics(ctx, filePath)
+	text := fmt.Sprintf("<result>\n%s\n</result>\n", response.Content)
+	text += s.lsp.FormatDiagnostics(filePath)
+	response.Content = text
+	return response, nil
+}
@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupSearchReplaceTest(t *testing.T) (context.Context, string, BaseTool, *stubHistoryService) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Return(true).AnyTimes()
+
+	files := newStubHistoryService()
+	tool := NewSearchReplaceTool(&noopLspService{}, mockPerms, files, &stubRegistry{})
+
+	tmpPath := createTempFileInWorkingDir(t, "search_replace_test_*.go")
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+
+	return ctx, tmpPath, tool, files
+}
+
+func runSearchReplace(t *testing.T, tool BaseTool, ctx context.Context, params SearchReplaceParams) ToolResponse {
+	t.Helper()
+	paramsJSON, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := tool.Run(ctx, ToolCall{Name: SearchReplaceToolName, Input: string(paramsJSON)})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestParseSearchReplaceBlocks(t *testing.T) {
+	t.Run("ignores text outside blocks", func(t *testing.T) {
+		blocks, err := ParseSearchReplaceBlocks("main.go\n```go\n<<<<<<< SEARCH\nold line\n=======\nnew line\n>>>>>>> REPLACE\n```\n")
+		require.NoError(t, err)
+		assert.Equal(t, []SearchReplaceBlock{{Search: "old line", Replace: "new line"}}, blocks)
+	})
+
+	t.Run("empty replace deletes", func(t *testing.T) {
+		blocks, err := ParseSearchReplaceBlocks("<<<<<<< SEARCH\na\nb\n=======\n>>>>>>> REPLACE")
+		require.NoError(t, err)
+		assert.Equal(t, []SearchReplaceBlock{{Search: "a\nb", Replace: ""}}, blocks)
+	})
+
+	t.Run("missing divider", func(t *testing.T) {
+		_, err := ParseSearchReplaceBlocks("<<<<<<< SEARCH\na\n>>>>>>> REPLACE")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "block 1")
+	})
+
+	t.Run("unterminated block", func(t *testing.T) {
+		_, err := ParseSearchReplaceBlocks("<<<<<<< SEARCH\na\n=======\nb")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ">>>>>>> REPLACE")
+	})
+}
+
+func TestSearchReplaceTool_SingleBlock(t *testing.T) {
+	ctx, tmpPath, tool, files := setupSearchReplaceTest(t)
+	writeAndTrack(t, tmpPath, "package main\n\nfunc a() int {\n\treturn 1\n}\n")
+
+	resp := runSearchReplace(t, tool, ctx, SearchReplaceParams{
+		FilePath: tmpPath,
+		Blocks:   "<<<<<<< SEARCH\n\treturn 1\n=======\n\treturn 2\n>>>>>>> REPLACE\n",
+	})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "1 search/replace blocks applied")
+
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package main\n\nfunc a() int {\n\treturn 2\n}\n", string(content))
+	assert.Equal(t, string(content), files.lastContent, "new content should be recorded in history")
+
+	var meta SearchReplaceResponseMetadata
+	require.NoError(t, json.Unmarshal([]byte(resp.Metadata), &meta))
+	assert.Equal(t, 1, meta.Blocks)
+	assert.Equal(t, 1, meta.Additions)
+	assert.Equal(t, 1, meta.Removals)
+}
+
+func TestSearchReplaceTool_SequentialBlocks(t *testing.T) {
+	ctx, tmpPath, tool, _ := setupSearchReplaceTest(t)
+	writeAndTrack(t, tmpPath, "one\ntwo\nthree\n")
+
+	// The second block matches text produced by the first
+	resp := runSearchReplace(t, tool, ctx, SearchReplaceParams{
+		FilePath: tmpPath,
+		Blocks: "<<<<<<< SEARCH\none\n=======\nuno\n>>>>>>> REPLACE\n" +
+			"<<<<<<< SEARCH\nuno\ntwo\n=======\nuno\ndos\n>>>>>>> REPLACE\n" +
+			"<<<<<<< SEARCH\nthree\n=======\ntres\n>>>>>>> REPLACE\n",
+	})
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "uno\ndos\ntres\n", string(content))
+}
+
+func TestSearchReplaceTool_BlockErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		blocks  string
+		wantErr string
+	}{
+		{
+			name:    "search not found",
+			content: "alpha\nbeta\n",
+			blocks: "<<<<<<< SEARCH\nalpha\n=======\nALPHA\n>>>>>>> REPLACE\n" +
+				"<<<<<<< SEARCH\ngamma\n=======\nGAMMA\n>>>>>>> REPLACE\n",
+			wantErr: `block 2: SEARCH text not found in file (starting with "gamma")`,
+		},
+		{
+			name:    "ambiguous search",
+			content: "x = 1\nx = 1\n",
+			blocks:  "<<<<<<< SEARCH\nx = 1\n=======\nx = 2\n>>>>>>> REPLACE\n",
+			wantErr: "block 1: SEARCH text appears 2 times",
+		},
+		{
+			name:    "no blocks",
+			content: "alpha\n",
+			blocks:  "just some text",
+			wantErr: "no SEARCH/REPLACE blocks found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, tmpPath, tool, _ := setupSearchReplaceTest(t)
+			writeAndTrack(t, tmpPath, tt.content)
+
+			resp := runSearchReplace(t, tool, ctx, SearchReplaceParams{FilePath: tmpPath, Blocks: tt.blocks})
+			assert.True(t, resp.IsError)
+			assert.Contains(t, resp.Content, tt.wantErr)
+
+			content, err := os.ReadFile(tmpPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(content), "file must be unchanged when a block fails")
+		})
+	}
+}
+
+func TestSearchReplaceTool_RequiresRead(t *testing.T) {
+	ctx, tmpPath, tool, _ := setupSearchReplaceTest(t)
+	require.NoError(t, os.WriteFile(tmpPath, []byte("content\n"), 0o644))
+
+	resp := runSearchReplace(t, tool, ctx, SearchReplaceParams{
+		FilePath: tmpPath,
+		Blocks:   "<<<<<<< SEARCH\ncontent\n=======\nnew\n>>>>>>> REPLACE",
+	})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "must read the file")
+}
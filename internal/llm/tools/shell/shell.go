@@ -61,7 +61,10 @@ func GetPersistentShell(workingDir string) *PersistentShell {
 	return sh
 }
 
-func newPersistentShell(cwd string) *PersistentShell {
+// ResolveShell returns the shell commands run in and its arguments: the
+// configured shell, else $SHELL, else /bin/bash, started as a login shell
+// unless arguments are configured.
+func ResolveShell() (string, []string) {
 	// Get shell configuration from config
 	cfg := config.Get()
 
@@ -85,6 +88,11 @@ func newPersistentShell(cwd string) *PersistentShell {
 	if len(shellArgs) == 0 {
 		shellArgs = []string{"-l"}
 	}
+	return shellPath, shellArgs
+}
+
+func newPersistentShell(cwd string) *PersistentShell {
+	shellPath, shellArgs := ResolveShell()
 
 	cmd := exec.Command(shellPath, shellArgs...)
 	cmd.Dir = cwd
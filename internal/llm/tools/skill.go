@@ -40,6 +40,7 @@ func NewSkillTool(permissions permission.Service, reg agentregistry.Registry) Ba
 func (s *skillTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        SkillToolName,
+		ReadOnly:    true,
 		Description: s.buildSkillDescription(),
 		Parameters: map[string]any{
 			"name": map[string]any{
@@ -54,11 +55,11 @@ func (s *skillTool) Info() ToolInfo {
 func (s *skillTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params SkillParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("invalid parameters"), err
+		return NewInvalidParamsResponse("invalid parameters", call.Input, err), nil
 	}
 
 	if params.Name == "" {
-		return NewTextErrorResponse("skill name is required"), nil
+		return NewMissingParamResponse("name", "skill name is required"), nil
 	}
 
 	skillInfo, err := skill.Get(params.Name)
@@ -132,6 +132,7 @@ func NewSourcegraphTool() BaseTool {
 func (t *sourcegraphTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        SourcegraphToolName,
+		ReadOnly:    true,
 		Description: sourcegraphToolDescription,
 		Parameters: map[string]any{
 			"query": map[string]any{
@@ -158,11 +159,11 @@ func (t *sourcegraphTool) Info() ToolInfo {
 func (t *sourcegraphTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params SourcegraphParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("Failed to parse sourcegraph parameters: " + err.Error()), nil
+		return NewInvalidParamsResponse("Failed to parse sourcegraph parameters", call.Input, err), nil
 	}
 
 	if params.Query == "" {
-		return NewTextErrorResponse("Query parameter is required"), nil
+		return NewMissingParamResponse("query", "Query parameter is required"), nil
 	}
 
 	if params.Count <= 0 {
@@ -38,6 +38,7 @@ func NewStructOutputTool(schema map[string]any) BaseTool {
 func (s *structOutputTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        StructOutputToolName,
+		ReadOnly:    true,
 		Description: structOutputDescription,
 		Parameters:  s.structParams,
 		Required:    s.required,
@@ -47,7 +48,7 @@ func (s *structOutputTool) Info() ToolInfo {
 func (s *structOutputTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var result map[string]any
 	if err := json.Unmarshal([]byte(call.Input), &result); err != nil {
-		return NewTextErrorResponse("Invalid JSON: " + err.Error()), nil
+		return NewInvalidParamsResponse("Invalid JSON", call.Input, err), nil
 	}
 	output, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+)
+
+// syntaxCheckers parse file content by extension and return its first
+// syntax error. Languages without an entry are not checked.
+var syntaxCheckers = map[string]func(content string) error{
+	".go":   goSyntaxError,
+	".json": jsonSyntaxError,
+}
+
+// goSyntaxError parses content as a Go source file.
+func goSyntaxError(content string) error {
+	_, err := parser.ParseFile(token.NewFileSet(), "", content, parser.SkipObjectResolution)
+	var list scanner.ErrorList
+	if errors.As(err, &list) && len(list) > 0 {
+		return fmt.Errorf("line %d, column %d: %s", list[0].Pos.Line, list[0].Pos.Column, list[0].Msg)
+	}
+	return err
+}
+
+// jsonSyntaxError parses content as a JSON document.
+func jsonSyntaxError(content string) error {
+	var value any
+	err := json.Unmarshal([]byte(content), &value)
+	var syntaxErr *json.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		return err
+	}
+	before := content[:min(int(syntaxErr.Offset), len(content))]
+	line := strings.Count(before, "\n") + 1
+	column := len(before) - strings.LastIndex(before, "\n")
+	if syntaxErr.Offset > 0 {
+		// Offset is just past the byte that failed
+		column--
+	}
+	return fmt.Errorf("line %d, column %d: %s", line, column, syntaxErr)
+}
+
+// checkSyntax parses the content an edit of path would write when
+// guard.syntaxCheck is enabled, unless oldContent already failed to parse.
+// A syntax error blocks the edit with an error response in strict mode;
+// otherwise it is returned as a warning to append to the tool's result.
+func checkSyntax(path, oldContent, newContent string) (ToolResponse, string) {
+	cfg := config.Get()
+	if cfg == nil || !cfg.Guard.SyntaxCheck {
+		return NewEmptyResponse(), ""
+	}
+	parse, ok := syntaxCheckers[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return NewEmptyResponse(), ""
+	}
+	if oldContent != "" && parse(oldContent) != nil {
+		return NewEmptyResponse(), ""
+	}
+	err := parse(newContent)
+	if err == nil {
+		return NewEmptyResponse(), ""
+	}
+
+	if cfg.Guard.Strict {
+		return NewToolErrorResponse(
+			fmt.Sprintf("the edit would leave %s with a syntax error at %s. The file was not changed; fix the edit and try again", path, err),
+			ToolErrorDetail{Code: ErrorCodeParseError, Path: path},
+		), ""
+	}
+	return NewEmptyResponse(), fmt.Sprintf("\n\nWarning: %s now has a syntax error at %s. Fix it before continuing.", path, err)
+}
@@ -0,0 +1,197 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+const validGoSource = "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+
+func TestSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   func(string) error
+		content string
+		want    string
+	}{
+		{"valid go", goSyntaxError, validGoSource, ""},
+		{"invalid go", goSyntaxError, "package main\n\nfunc main() {\n\tx := \n}\n", "line 5, column 1: expected operand, found '}'"},
+		{"valid json", jsonSyntaxError, "{\n  \"a\": [1, 2]\n}\n", ""},
+		{"invalid json", jsonSyntaxError, "{\n  \"a\": 1,\n  \"b\" 2\n}", "line 3, column 7: invalid character '2' after object key"},
+		{"truncated json", jsonSyntaxError, "{\"a\": 1", "unexpected end of JSON input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check(tt.content)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func setGuard(t *testing.T, guard config.GuardConfig) {
+	t.Helper()
+	cfg := config.Get()
+	old := cfg.Guard
+	t.Cleanup(func() { cfg.Guard = old })
+	cfg.Guard = guard
+}
+
+func setupGoEditTest(t *testing.T) (string, func(EditParams) ToolResponse) {
+	t.Helper()
+	ctx, _, tool := setupEditTest(t)
+	path := filepath.Join(t.TempDir(), "main.go")
+	writeAndTrack(t, path, validGoSource)
+	return path, func(params EditParams) ToolResponse {
+		params.FilePath = path
+		return runEdit(t, tool, ctx, params)
+	}
+}
+
+func TestEditTool_SyntaxCheckWarns(t *testing.T) {
+	setGuard(t, config.GuardConfig{SyntaxCheck: true})
+	path, edit := setupGoEditTest(t)
+
+	resp := edit(EditParams{OldString: "println(\"hi\")\n}", NewString: "println(\"hi\"\n}"})
+	require.False(t, resp.IsError, resp.Content)
+	assert.Contains(t, resp.Content, "now has a syntax error at line 4")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "println(\"hi\"\n}")
+}
+
+func TestEditTool_SyntaxCheckStrictBlocks(t *testing.T) {
+	setGuard(t, config.GuardConfig{SyntaxCheck: true, Strict: true})
+	path, edit := setupGoEditTest(t)
+
+	resp := edit(EditParams{OldString: "func main() {", NewString: "func main( {"})
+	require.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "syntax error at line 3")
+	require.NotNil(t, resp.ErrorDetail)
+	assert.Equal(t, ErrorCodeParseError, resp.ErrorDetail.Code)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, validGoSource, string(content))
+}
+
+func TestEditTool_SyntaxCheckValidEdit(t *testing.T) {
+	setGuard(t, config.GuardConfig{SyntaxCheck: true, Strict: true})
+	path, edit := setupGoEditTest(t)
+
+	resp := edit(EditParams{OldString: "println(\"hi\")", NewString: "println(\"hello\")"})
+	require.False(t, resp.IsError, resp.Content)
+	assert.NotContains(t, resp.Content, "syntax error")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "println(\"hello\")")
+}
+
+func TestEditTool_SyntaxCheckSkipsBrokenAndUnknownFiles(t *testing.T) {
+	setGuard(t, config.GuardConfig{SyntaxCheck: true, Strict: true})
+
+	t.Run("already broken", func(t *testing.T) {
+		path, edit := setupGoEditTest(t)
+		writeAndTrack(t, path, "package main\n\nfunc main() {\n")
+		resp := edit(EditParams{OldString: "func main() {", NewString: "func run() {"})
+		require.False(t, resp.IsError, resp.Content)
+		assert.NotContains(t, resp.Content, "syntax error")
+	})
+
+	t.Run("unknown language", func(t *testing.T) {
+		ctx, tmpPath, tool := setupEditTest(t)
+		writeAndTrack(t, tmpPath, "key: value\n")
+		resp := runEdit(t, tool, ctx, EditParams{FilePath: tmpPath, OldString: "key: value", NewString: "key: {"})
+		require.False(t, resp.IsError, resp.Content)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		setGuard(t, config.GuardConfig{})
+		_, edit := setupGoEditTest(t)
+		resp := edit(EditParams{OldString: "func main() {", NewString: "func main( {"})
+		require.False(t, resp.IsError, resp.Content)
+		assert.NotContains(t, resp.Content, "syntax error")
+	})
+}
+
+func TestWriteTool_SyntaxCheckStrictBlocksJSON(t *testing.T) {
+	setGuard(t, config.GuardConfig{SyntaxCheck: true, Strict: true})
+	ctx, _, tool := setupWriteTest(t)
+	path := filepath.Join(createTempDirInWorkingDir(t, "syntax_test_*"), "config.json")
+
+	resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "{\"a\": 1,}"})
+	require.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "syntax error at line 1, column 9")
+	assert.NoFileExists(t, path)
+
+	resp = runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "{\"a\": 1}"})
+	require.False(t, resp.IsError, resp.Content)
+	assert.FileExists(t, path)
+}
+
+func runGoPatch(t *testing.T, newBody string) (string, ToolResponse) {
+	t.Helper()
+	path := filepath.Join(createTempDirInWorkingDir(t, "syntax_patch_*"), "main.go")
+	writeAndTrack(t, path, validGoSource)
+
+	ctrl := gomock.NewController(t)
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Return(true).AnyTimes()
+	tool := NewPatchTool(&noopLspService{}, mockPerms, newStubHistoryService(), &stubRegistry{})
+
+	patchText := "*** Begin Patch\n*** Update File: " + path + "\n" +
+		"@@\n func main() {\n-\tprintln(\"hi\")\n+" + newBody + "\n }\n*** End Patch"
+	input, err := json.Marshal(PatchParams{PatchText: patchText})
+	require.NoError(t, err)
+	ctx := context.WithValue(t.Context(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	resp, err := tool.Run(ctx, ToolCall{Name: PatchToolName, Input: string(input)})
+	require.NoError(t, err)
+	return path, resp
+}
+
+func TestPatchTool_SyntaxCheck(t *testing.T) {
+	t.Run("warns", func(t *testing.T) {
+		setGuard(t, config.GuardConfig{SyntaxCheck: true})
+		path, resp := runGoPatch(t, "\tprintln(\"hi\"")
+		require.False(t, resp.IsError, resp.Content)
+		assert.Contains(t, resp.Content, "now has a syntax error at line 4")
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "println(\"hi\"\n}")
+	})
+
+	t.Run("strict blocks", func(t *testing.T) {
+		setGuard(t, config.GuardConfig{SyntaxCheck: true, Strict: true})
+		path, resp := runGoPatch(t, "\tprintln(\"hi\"")
+		require.True(t, resp.IsError)
+		assert.Contains(t, resp.Content, "syntax error at line 4")
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, validGoSource, string(content))
+	})
+
+	t.Run("valid patch", func(t *testing.T) {
+		setGuard(t, config.GuardConfig{SyntaxCheck: true, Strict: true})
+		_, resp := runGoPatch(t, "\tprintln(\"hello\")")
+		require.False(t, resp.IsError, resp.Content)
+		assert.NotContains(t, resp.Content, "syntax error")
+	})
+}
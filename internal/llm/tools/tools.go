@@ -6,10 +6,19 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/fileutil"
+	"github.com/bmatcuk/doublestar/v4"
 )
 
 type ToolInfo struct {
@@ -17,14 +26,57 @@ type ToolInfo struct {
 	Description string
 	Parameters  map[string]any
 	Required    []string
+	// Preconditions are requirements the tool checks before doing any work.
+	// withPreconditions also lists them in Description so the model sees them.
+	Preconditions []string
+	// Examples are canonical invocations of the tool. Providers without
+	// native example support fold them into the description.
+	Examples []ToolExample
+	// ReadOnly marks tools whose calls only inspect the project or the web:
+	// they change no files, run no commands and have no other side effects.
+	// Tools that may do any of these leave it unset.
+	ReadOnly bool
 	// TODO: Consider to add Output parameters: https://modelcontextprotocol.io/specification/2025-06-18/server/tools#output-schema
 }
 
+// Mutating reports whether calls of the tool may change state, such as
+// files, processes or remote services. Tools are mutating unless they are
+// marked ReadOnly, so tools from MCP servers always are.
+func (i ToolInfo) Mutating() bool {
+	return !i.ReadOnly
+}
+
+// ToolExample is a sample invocation of a tool. Input is the JSON arguments
+// object, in the same form as ToolCall.Input.
+type ToolExample struct {
+	Description string
+	Input       string
+}
+
+// ReadFilePrecondition is the precondition of tools that modify an existing
+// file only after it has been read.
+const ReadFilePrecondition = "requires prior read of file_path: an existing file must be read with the Read tool first and must not change on disk after that read"
+
+// withPreconditions sets the preconditions of info and appends them to its
+// description.
+func withPreconditions(info ToolInfo, preconditions ...string) ToolInfo {
+	info.Preconditions = preconditions
+	var b strings.Builder
+	b.WriteString(info.Description)
+	b.WriteString("\n\nPRECONDITIONS:")
+	for _, p := range preconditions {
+		b.WriteString("\n- " + p)
+	}
+	info.Description = b.String()
+	return info
+}
+
 type toolResponseType string
 
 type (
 	sessionIDContextKey   string
 	messageIDContextKey   string
+	turnIDContextKey      string
 	isTaskAgentContextKey string
 	agentIDContextKey     string
 )
@@ -35,19 +87,59 @@ const (
 
 	SessionIDContextKey   sessionIDContextKey   = "session_id"
 	MessageIDContextKey   messageIDContextKey   = "message_id"
+	TurnIDContextKey      turnIDContextKey      = "turn_id"
 	IsTaskAgentContextKey isTaskAgentContextKey = "is_task_agent"
 	AgentIDContextKey     agentIDContextKey     = "agent_id"
 
 	// MaxToolResponseTokens is the maximum number of tokens allowed in a tool response
 	// to prevent context overflow. ~1200KB of text content.
 	MaxToolResponseTokens = 300_000
+
+	// DefaultMaxErrorInputChars is how much of a tool's raw input is echoed back
+	// in a parameter parsing error when tools.maxErrorInputChars is not set.
+	DefaultMaxErrorInputChars = 1000
 )
 
+// ToolErrorCode classifies a tool error for structured consumers.
+type ToolErrorCode string
+
+const (
+	ErrorCodeNotFound         ToolErrorCode = "not_found"
+	ErrorCodePermissionDenied ToolErrorCode = "permission_denied"
+	ErrorCodeOutsideWorkdir   ToolErrorCode = "outside_workdir"
+	ErrorCodeParseError       ToolErrorCode = "parse_error"
+	ErrorCodeMissingParam     ToolErrorCode = "missing_param"
+)
+
+// ToolErrorDetail is the machine-readable form of a tool error. Content stays
+// the human-readable message sent to the model.
+type ToolErrorDetail struct {
+	Code  ToolErrorCode `json:"code"`
+	Path  string        `json:"path,omitempty"`
+	Param string        `json:"param,omitempty"`
+}
+
+// ErrOutsideWorkingDirectory is wrapped by ValidatePathInWorkingDirectory when
+// a path escapes the working directory.
+var ErrOutsideWorkingDirectory = errors.New("outside the working directory")
+
+// ToolAttachment is binary output of a tool, such as an image, sent to the
+// model next to the text content of the response.
+type ToolAttachment struct {
+	Path     string `json:"path,omitempty"`
+	MIMEType string `json:"mime_type"`
+	Data     []byte `json:"data"`
+}
+
 type toolResponse struct {
-	Type     toolResponseType `json:"type"`
-	Content  string           `json:"content"`
-	Metadata string           `json:"metadata,omitempty"`
-	IsError  bool             `json:"is_error"`
+	Type        toolResponseType `json:"type"`
+	Content     string           `json:"content"`
+	Metadata    string           `json:"metadata,omitempty"`
+	IsError     bool             `json:"is_error"`
+	ErrorDetail *ToolErrorDetail `json:"error_detail,omitempty"`
+	// Attachments are sent as separate binary parts of the tool message,
+	// so a tool can return a text summary together with e.g. an image.
+	Attachments []ToolAttachment `json:"attachments,omitempty"`
 }
 
 // ToolResponse is the public interface for tool responses
@@ -100,6 +192,13 @@ func WithResponseMetadata(response toolResponse, metadata any) toolResponse {
 	return response
 }
 
+// WithResponseAttachments adds binary attachments to response. The text
+// content is still sent to the model as the tool result.
+func WithResponseAttachments(response toolResponse, attachments ...ToolAttachment) toolResponse {
+	response.Attachments = append(response.Attachments, attachments...)
+	return response
+}
+
 func NewTextErrorResponse(content string) toolResponse {
 	return validateAndTruncate(toolResponse{
 		Type:    ToolResponseTypeText,
@@ -108,13 +207,204 @@ func NewTextErrorResponse(content string) toolResponse {
 	})
 }
 
+// NewToolErrorResponse builds an error response carrying a structured detail.
+func NewToolErrorResponse(content string, detail ToolErrorDetail) toolResponse {
+	response := NewTextErrorResponse(content)
+	response.ErrorDetail = &detail
+	return response
+}
+
+// NewMissingParamResponse reports a required parameter that was not provided.
+func NewMissingParamResponse(param, content string) toolResponse {
+	return NewToolErrorResponse(content, ToolErrorDetail{Code: ErrorCodeMissingParam, Param: param})
+}
+
+// NewNotFoundResponse reports a file or directory that does not exist.
+func NewNotFoundResponse(content, path string) toolResponse {
+	return NewToolErrorResponse(content, ToolErrorDetail{Code: ErrorCodeNotFound, Path: path})
+}
+
+// NewPathErrorResponse reports an error resolving or accessing path, deriving
+// the error code from err.
+func NewPathErrorResponse(err error, path string) toolResponse {
+	response := NewTextErrorResponse(err.Error())
+	var code ToolErrorCode
+	switch {
+	case errors.Is(err, ErrOutsideWorkingDirectory):
+		code = ErrorCodeOutsideWorkdir
+	case errors.Is(err, fs.ErrNotExist):
+		code = ErrorCodeNotFound
+	case errors.Is(err, fs.ErrPermission):
+		code = ErrorCodePermissionDenied
+	default:
+		return response
+	}
+	response.ErrorDetail = &ToolErrorDetail{Code: code, Path: path}
+	return response
+}
+
+// NewInvalidParamsResponse builds the error response for a tool call whose input
+// could not be parsed. The parse error and the echoed input are both truncated so
+// large payloads (e.g. an edit's new_string) don't bloat the error sent back.
+func NewInvalidParamsResponse(msg, input string, err error) toolResponse {
+	limit := maxErrorInputChars()
+	return NewToolErrorResponse(fmt.Sprintf("%s: %s\ninput: %s",
+		msg, truncateEchoedInput(err.Error(), limit), truncateEchoedInput(input, limit)),
+		ToolErrorDetail{Code: ErrorCodeParseError})
+}
+
+func maxErrorInputChars() int {
+	if cfg := config.Get(); cfg != nil && cfg.Tools.MaxErrorInputChars > 0 {
+		return cfg.Tools.MaxErrorInputChars
+	}
+	return DefaultMaxErrorInputChars
+}
+
+// followSymlinks reports whether listing and glob tools should descend into
+// symlinked directories.
+func followSymlinks() bool {
+	cfg := config.Get()
+	return cfg != nil && cfg.Tools.FollowSymlinks
+}
+
+// maxFileSizeForTools returns the size in bytes above which files are left
+// out of listings and only read in chunks, or zero when there is no limit.
+func maxFileSizeForTools() int64 {
+	if cfg := config.Get(); cfg != nil && cfg.MaxFileSizeForTools > 0 {
+		return cfg.MaxFileSizeForTools
+	}
+	return 0
+}
+
+// skipLargeFiles removes the files larger than maxFileSizeForTools from paths
+// and returns how many were removed. Directories and paths that cannot be
+// stat'ed are kept.
+func skipLargeFiles(paths []string) ([]string, int) {
+	limit := maxFileSizeForTools()
+	if limit == 0 {
+		return paths, 0
+	}
+	kept := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && info.Size() > limit {
+			continue
+		}
+		kept = append(kept, path)
+	}
+	return kept, len(paths) - len(kept)
+}
+
+// skippedLargeFilesNote tells the model that skipped files were left out of
+// a listing, or returns an empty string when none were.
+func skippedLargeFilesNote(skipped int) string {
+	if skipped == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\n(%d files larger than maxFileSizeForTools (%d bytes) were skipped. Read them in chunks with offset and limit.)", skipped, maxFileSizeForTools())
+}
+
+// ignorePatterns merges the configured tools.defaultIgnore patterns with the
+// ignore patterns passed to a single call. The defaults are left out when
+// noDefault is set.
+func ignorePatterns(perCall []string, noDefault bool) []string {
+	cfg := config.Get()
+	if noDefault || cfg == nil || len(cfg.Tools.DefaultIgnore) == 0 {
+		return perCall
+	}
+	return append(slices.Clone(cfg.Tools.DefaultIgnore), perCall...)
+}
+
+// matchesIgnorePattern reports whether relPath, relative to the search root,
+// is ignored by patterns. Patterns without a separator also match against
+// the base name, so "*.min.js" excludes minified files at any depth. As in
+// .gitignore, a leading "!" re-includes matching paths and the last matching
+// pattern wins.
+func matchesIgnorePattern(relPath string, patterns []string) bool {
+	relPath = filepath.ToSlash(relPath)
+	base := path.Base(relPath)
+	ignored := false
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		if ignorePatternMatches(pattern, relPath, base) {
+			ignored = !negated
+		}
+	}
+	return ignored
+}
+
+// validateGlobs checks the glob patterns given to a tool and returns an error
+// naming the first malformed one. The leading "!" of a negated ignore
+// pattern is not part of the glob.
+func validateGlobs(patterns ...string) error {
+	for _, pattern := range patterns {
+		if err := fileutil.ValidateGlob(strings.TrimPrefix(pattern, "!")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ignorePatternMatches(pattern, relPath, base string) bool {
+	if matched, _ := doublestar.Match(pattern, relPath); matched {
+		return true
+	}
+	if !strings.Contains(pattern, "/") {
+		matched, _ := doublestar.Match(pattern, base)
+		return matched
+	}
+	return false
+}
+
+func hasNegatedPattern(patterns []string) bool {
+	return slices.ContainsFunc(patterns, func(p string) bool { return strings.HasPrefix(p, "!") })
+}
+
+// negationMayMatchUnder reports whether a negated pattern could re-include a
+// path below the ignored directory relDir, in which case the directory must
+// still be descended into.
+func negationMayMatchUnder(relDir string, patterns []string) bool {
+	relDir = filepath.ToSlash(relDir)
+	for _, pattern := range patterns {
+		pattern, ok := strings.CutPrefix(pattern, "!")
+		if !ok {
+			continue
+		}
+		if !strings.Contains(pattern, "/") {
+			return true
+		}
+		// Compare against the literal part of the pattern before any glob syntax
+		prefix := pattern
+		if i := strings.IndexAny(pattern, "*?[{"); i >= 0 {
+			prefix = pattern[:i]
+		}
+		if strings.HasPrefix(prefix, relDir+"/") || strings.HasPrefix(relDir+"/", prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// truncateEchoedInput cuts s to at most limit bytes on a rune boundary and
+// appends a marker noting how much was dropped.
+func truncateEchoedInput(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... [truncated %d chars]", s[:cut], len(s)-cut)
+}
+
 // ValidatePathInWorkingDirectory checks if a path is within the working directory
 // to prevent path traversal attacks. It returns the absolute path if valid, or an error if not.
 // This is a wrapper around fileutil.SecureResolvePath that uses config.WorkingDirectory().
 func ValidatePathInWorkingDirectory(filePath string) (string, error) {
-	absPath, err := fileutil.SecureResolvePath(filePath, config.WorkingDirectory())
+	absPath, err := fileutil.SecureResolvePath(fileutil.ToNativePath(filePath), config.WorkingDirectory())
 	if err != nil {
-		return "", fmt.Errorf("invalid file path: %s attempts to escape working directory (outside the working directory)", filePath)
+		return "", fmt.Errorf("invalid file path: %s attempts to escape working directory (%w)", filePath, ErrOutsideWorkingDirectory)
 	}
 	return absPath, nil
 }
@@ -142,6 +432,13 @@ func GetContextValues(ctx context.Context) (string, string) {
 	return sessionID.(string), messageID.(string)
 }
 
+// GetTurnID returns the ID of the user message that started the current
+// turn, or empty string if not set
+func GetTurnID(ctx context.Context) string {
+	turnID, _ := ctx.Value(TurnIDContextKey).(string)
+	return turnID
+}
+
 // IsTaskAgent returns true if the context indicates this is a task agent
 func IsTaskAgent(ctx context.Context) bool {
 	isTaskAgent := ctx.Value(IsTaskAgentContextKey)
@@ -2,8 +2,17 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 )
@@ -308,3 +317,252 @@ func TestConstants(t *testing.T) {
 		})
 	}
 }
+
+func TestNewInvalidParamsResponse(t *testing.T) {
+	t.Run("short input echoed in full", func(t *testing.T) {
+		resp := NewInvalidParamsResponse("error parsing parameters", `{"path": 1}`, errors.New("bad type"))
+		if !resp.IsError {
+			t.Fatal("expected error response")
+		}
+		if !strings.Contains(resp.Content, `input: {"path": 1}`) {
+			t.Errorf("expected full input echoed, got %q", resp.Content)
+		}
+	})
+
+	t.Run("multiedit with huge new_string is bounded", func(t *testing.T) {
+		// Missing closing brackets make the input invalid JSON.
+		input := `{"file_path": "main.go", "edits": [{"old_string": "a", "new_string": "` +
+			strings.Repeat("x", 50*1024) + `"`
+
+		tool := NewMultiEditTool(nil, nil, nil, nil)
+		resp, err := tool.Run(context.Background(), ToolCall{Name: MultiEditToolName, Input: input})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !resp.IsError {
+			t.Fatal("expected error response")
+		}
+		if !strings.Contains(resp.Content, "invalid parameters") {
+			t.Errorf("expected parse error message, got %q", resp.Content[:100])
+		}
+		if !strings.Contains(resp.Content, "[truncated") {
+			t.Error("expected truncation marker in echoed input")
+		}
+		if len(resp.Content) > 3*DefaultMaxErrorInputChars {
+			t.Errorf("error message not bounded: %d bytes", len(resp.Content))
+		}
+	})
+
+	t.Run("truncation respects rune boundaries", func(t *testing.T) {
+		result := truncateEchoedInput(strings.Repeat("é", 10), 5)
+		if !utf8.ValidString(result) {
+			t.Errorf("truncated input is not valid UTF-8: %q", result)
+		}
+		if !strings.HasPrefix(result, "éé...") {
+			t.Errorf("unexpected truncation result %q", result)
+		}
+	})
+}
+
+func TestToolErrorDetail(t *testing.T) {
+	workingDir := config.WorkingDirectory()
+	missing := filepath.Join(workingDir, "does_not_exist_12345.txt")
+
+	tests := []struct {
+		name      string
+		tool      BaseTool
+		input     string
+		wantCode  ToolErrorCode
+		wantPath  string
+		wantParam string
+	}{
+		{"read parse error", NewViewTool(&noopLspService{}), "{", ErrorCodeParseError, "", ""},
+		{"read missing param", NewViewTool(&noopLspService{}), `{}`, ErrorCodeMissingParam, "", "file_path"},
+		{"read not found", NewViewTool(&noopLspService{}), `{"file_path":"` + missing + `"}`, ErrorCodeNotFound, missing, ""},
+		{"read outside workdir", NewViewTool(&noopLspService{}), `{"file_path":"/etc/passwd"}`, ErrorCodeOutsideWorkdir, "/etc/passwd", ""},
+		{"exists outside workdir", NewExistsTool(), `{"path":"/etc/passwd"}`, ErrorCodeOutsideWorkdir, "/etc/passwd", ""},
+		{"ls not found", NewLsTool(config.Get()), `{"path":"` + missing + `"}`, ErrorCodeNotFound, missing, ""},
+		{"glob missing param", NewGlobTool(), `{}`, ErrorCodeMissingParam, "", "pattern"},
+		{"write missing content", NewWriteTool(nil, nil, nil, nil), `{"file_path":"a.txt"}`, ErrorCodeMissingParam, "", "content"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.tool.Run(context.Background(), ToolCall{Input: tt.input})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !resp.IsError {
+				t.Fatalf("expected error response, got %q", resp.Content)
+			}
+			if resp.ErrorDetail == nil {
+				t.Fatalf("expected error detail for %q", resp.Content)
+			}
+			if resp.ErrorDetail.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.ErrorDetail.Code, tt.wantCode)
+			}
+			if resp.ErrorDetail.Path != tt.wantPath {
+				t.Errorf("path = %q, want %q", resp.ErrorDetail.Path, tt.wantPath)
+			}
+			if resp.ErrorDetail.Param != tt.wantParam {
+				t.Errorf("param = %q, want %q", resp.ErrorDetail.Param, tt.wantParam)
+			}
+		})
+	}
+}
+
+func TestNewPathErrorResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode ToolErrorCode
+	}{
+		{"outside workdir", fmt.Errorf("bad path (%w)", ErrOutsideWorkingDirectory), ErrorCodeOutsideWorkdir},
+		{"not exist", fs.ErrNotExist, ErrorCodeNotFound},
+		{"permission", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrPermission}, ErrorCodePermissionDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := NewPathErrorResponse(tt.err, "x")
+			if resp.ErrorDetail == nil || resp.ErrorDetail.Code != tt.wantCode {
+				t.Errorf("detail = %+v, want code %q", resp.ErrorDetail, tt.wantCode)
+			}
+			if resp.Content != tt.err.Error() {
+				t.Errorf("content = %q, want %q", resp.Content, tt.err.Error())
+			}
+		})
+	}
+
+	if resp := NewPathErrorResponse(errors.New("other"), "x"); resp.ErrorDetail != nil {
+		t.Errorf("unclassified error should have no detail, got %+v", resp.ErrorDetail)
+	}
+}
+
+func TestDefaultIgnore(t *testing.T) {
+	cfg := config.Get()
+	saved := cfg.Tools.DefaultIgnore
+	cfg.Tools.DefaultIgnore = []string{"**/*.min.js", "assets/**"}
+	t.Cleanup(func() { cfg.Tools.DefaultIgnore = saved })
+
+	// The grep fallback skips paths under system temp directories, so work
+	// inside the working directory instead of t.TempDir().
+	dir, err := os.MkdirTemp(config.WorkingDirectory(), "defaultignore-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.js", "app.min.js", filepath.Join("assets", "bundle.js")} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("const hello = 1\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tools := []struct {
+		name  string
+		tool  BaseTool
+		input map[string]any
+	}{
+		{name: LSToolName, tool: NewLsTool(cfg), input: map[string]any{"path": dir}},
+		{name: GlobToolName, tool: NewGlobTool(), input: map[string]any{"pattern": "**/*.js", "path": dir}},
+		{name: GrepToolName, tool: NewGrepTool(), input: map[string]any{"pattern": "hello", "path": dir}},
+	}
+
+	for _, tt := range tools {
+		run := func(t *testing.T, input map[string]any) string {
+			t.Helper()
+			raw, err := json.Marshal(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := tt.tool.Run(context.Background(), ToolCall{Name: tt.name, Input: string(raw)})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if resp.IsError {
+				t.Fatalf("unexpected error response: %s", resp.Content)
+			}
+			return resp.Content
+		}
+
+		t.Run(tt.name+" applies configured patterns", func(t *testing.T) {
+			out := run(t, tt.input)
+			if !strings.Contains(out, "app.js") {
+				t.Errorf("expected app.js in output:\n%s", out)
+			}
+			for _, ignored := range []string{"app.min.js", "assets"} {
+				if strings.Contains(out, ignored) {
+					t.Errorf("expected %s to be ignored by default:\n%s", ignored, out)
+				}
+			}
+		})
+
+		t.Run(tt.name+" no_default_ignore re-includes files", func(t *testing.T) {
+			input := maps.Clone(tt.input)
+			input["no_default_ignore"] = true
+			out := run(t, input)
+			for _, name := range []string{"app.js", "app.min.js", "assets"} {
+				if !strings.Contains(out, name) {
+					t.Errorf("expected %s with no_default_ignore:\n%s", name, out)
+				}
+			}
+		})
+	}
+}
+
+func TestIgnorePatterns(t *testing.T) {
+	cfg := config.Get()
+	saved := cfg.Tools.DefaultIgnore
+	cfg.Tools.DefaultIgnore = []string{"dist/**"}
+	t.Cleanup(func() { cfg.Tools.DefaultIgnore = saved })
+
+	if got := ignorePatterns([]string{"*.log"}, false); !slices.Equal(got, []string{"dist/**", "*.log"}) {
+		t.Errorf("ignorePatterns() = %v, want defaults merged with per-call patterns", got)
+	}
+	if got := ignorePatterns([]string{"*.log"}, true); !slices.Equal(got, []string{"*.log"}) {
+		t.Errorf("ignorePatterns() with noDefault = %v, want only per-call patterns", got)
+	}
+	if !slices.Equal(cfg.Tools.DefaultIgnore, []string{"dist/**"}) {
+		t.Errorf("configured defaults were modified: %v", cfg.Tools.DefaultIgnore)
+	}
+}
+
+func TestToolInfoMutating(t *testing.T) {
+	mutating := []BaseTool{
+		NewEditTool(&noopLspService{}, nil, nil, nil),
+		NewMultiEditTool(&noopLspService{}, nil, nil, nil),
+		NewWriteTool(&noopLspService{}, nil, nil, nil),
+		NewDeleteTool(nil, nil, nil),
+		NewPatchTool(&noopLspService{}, nil, nil, nil),
+		NewBashTool(nil, nil),
+		NewFetchTool(nil),
+		NewPlanTaskTool(nil),
+		NewUpdateStepTool(nil),
+	}
+	for _, tool := range mutating {
+		if info := tool.Info(); !info.Mutating() {
+			t.Errorf("%s: Mutating() = false, want true", info.Name)
+		}
+	}
+
+	readOnly := []BaseTool{
+		NewLsTool(nil),
+		NewViewTool(&noopLspService{}),
+		NewGrepTool(),
+		NewGlobTool(),
+	}
+	for _, tool := range readOnly {
+		if info := tool.Info(); info.Mutating() {
+			t.Errorf("%s: Mutating() = true, want false", info.Name)
+		}
+	}
+
+	if !(ToolInfo{Name: "mcp_tool"}).Mutating() {
+		t.Error("tools not marked ReadOnly must count as mutating")
+	}
+	if info := WithCallDirectory(NewViewTool(&noopLspService{})).Info(); info.Mutating() {
+		t.Error("WithCallDirectory must keep ReadOnly")
+	}
+}
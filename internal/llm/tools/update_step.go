@@ -88,11 +88,11 @@ func (u *updateStepTool) Info() ToolInfo {
 func (u *updateStepTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params UpdateStepParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.TaskID == "" {
-		return NewTextErrorResponse("task_id is required"), nil
+		return NewMissingParamResponse("task_id", "task_id is required"), nil
 	}
 
 	if params.StepIndex < 0 {
@@ -69,6 +69,7 @@ func NewViewImageTool() BaseTool {
 func (v *viewImageTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        ViewImageToolName,
+		ReadOnly:    true,
 		Description: viewImageDescription,
 		Parameters: map[string]any{
 			"file_path": map[string]any{
@@ -83,23 +84,23 @@ func (v *viewImageTool) Info() ToolInfo {
 func (v *viewImageTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params ViewImageParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.FilePath == "" {
-		return NewTextErrorResponse("file_path is required"), nil
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
 	}
 
-	filePath, err := ValidatePathInWorkingDirectory(params.FilePath)
+	filePath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
 	if err != nil {
-		return NewTextErrorResponse(err.Error()), nil
+		return NewPathErrorResponse(err, params.FilePath), nil
 	}
 
 	// Check if file exists
 	fileInfo, err := os.Stat(filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return NewTextErrorResponse("File not found: " + filePath), nil
+			return NewNotFoundResponse("File not found: "+filePath, filePath), nil
 		}
 		return NewEmptyResponse(), fmt.Errorf("error accessing file: %w", err)
 	}
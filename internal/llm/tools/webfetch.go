@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -56,6 +58,7 @@ FEATURES:
 
 LIMITATIONS:
 - Maximum response size is 5MB
+- Only text-like content types (HTML, plain text, JSON, XML, ...) are downloaded unless tools.fetch.allowedContentTypes says otherwise
 - Only supports HTTP and HTTPS protocols
 - Cannot handle authentication or cookies
 - Some websites may block automated requests
@@ -103,11 +106,11 @@ func (t *fetchTool) Info() ToolInfo {
 func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params FetchParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("Failed to parse fetch parameters: " + err.Error()), nil
+		return NewInvalidParamsResponse("Failed to parse fetch parameters", call.Input, err), nil
 	}
 
 	if params.URL == "" {
-		return NewTextErrorResponse("URL parameter is required"), nil
+		return NewMissingParamResponse("url", "URL parameter is required"), nil
 	}
 
 	format := strings.ToLower(params.Format)
@@ -179,6 +182,12 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return NewTextErrorResponse(fmt.Sprintf("Request failed with status code: %d", resp.StatusCode)), nil
 	}
 
+	contentType := resp.Header.Get("Content-Type")
+	if !contentTypeAllowed(contentType, fetchAllowedContentTypes()) {
+		return NewTextErrorResponse(fmt.Sprintf("Refusing to download content of type %q. Allowed types: %s (configure tools.fetch.allowedContentTypes to change this)",
+			contentType, strings.Join(fetchAllowedContentTypes(), ", "))), nil
+	}
+
 	maxSize := int64(5 * 1024 * 1024) // 5MB
 	if cl := resp.Header.Get("Content-Length"); cl != "" {
 		if size, err := strconv.ParseInt(cl, 10, 64); err == nil && size > maxSize {
@@ -192,7 +201,6 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	}
 
 	content := string(body)
-	contentType := resp.Header.Get("Content-Type")
 
 	switch format {
 	case "text":
@@ -224,6 +232,42 @@ func (t *fetchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	}
 }
 
+// DefaultFetchAllowedContentTypes are the media types the webfetch tool
+// downloads when tools.fetch.allowedContentTypes is not set.
+var DefaultFetchAllowedContentTypes = []string{
+	"text/*",
+	"application/json",
+	"application/xml",
+	"application/xhtml+xml",
+	"application/javascript",
+	"application/yaml",
+}
+
+func fetchAllowedContentTypes() []string {
+	if cfg := config.Get(); cfg != nil && len(cfg.Tools.Fetch.AllowedContentTypes) > 0 {
+		return cfg.Tools.Fetch.AllowedContentTypes
+	}
+	return DefaultFetchAllowedContentTypes
+}
+
+// contentTypeAllowed reports whether the media type of a Content-Type header
+// matches one of allowed. Patterns are exact media types, "type/*" or "*/*".
+// A missing header is allowed since the type cannot be known in advance.
+func contentTypeAllowed(contentType string, allowed []string) bool {
+	if strings.TrimSpace(contentType) == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	major, _, _ := strings.Cut(mediaType, "/")
+	return slices.ContainsFunc(allowed, func(pattern string) bool {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		return pattern == "*/*" || pattern == mediaType || pattern == major+"/*"
+	})
+}
+
 func extractTextFromHTML(html string) (string, error) {
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
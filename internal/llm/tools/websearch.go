@@ -145,6 +145,7 @@ func NewWebSearchTool(registry SearchProviderRegistry, permissions permission.Se
 func (t *websearchTool) Info() ToolInfo {
 	return ToolInfo{
 		Name:        WebSearchToolName,
+		ReadOnly:    true,
 		Description: t.buildDescription(),
 		Parameters: map[string]any{
 			"query": map[string]any{
@@ -205,14 +206,14 @@ type searchResult struct {
 func (t *websearchTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params WebSearchParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse("Failed to parse parameters: " + err.Error()), nil
+		return NewInvalidParamsResponse("Failed to parse parameters", call.Input, err), nil
 	}
 
 	if params.Query == "" {
-		return NewTextErrorResponse("Query parameter is required"), nil
+		return NewMissingParamResponse("query", "Query parameter is required"), nil
 	}
 	if params.Provider == "" {
-		return NewTextErrorResponse("Provider parameter is required"), nil
+		return NewMissingParamResponse("provider", "Provider parameter is required"), nil
 	}
 
 	provider, err := t.registry.GetProvider(params.Provider)
@@ -14,14 +14,15 @@ import (
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/diff"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/history"
-	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/permission"
 )
 
 type WriteParams struct {
-	FilePath string `json:"file_path"`
-	Content  string `json:"content"`
+	FilePath  string `json:"file_path"`
+	Content   string `json:"content"`
+	Overwrite bool   `json:"overwrite,omitempty"`
+	BOM       string `json:"bom,omitempty"`
 }
 
 type WritePermissionsParams struct {
@@ -55,9 +56,11 @@ HOW TO USE:
 - Provide the path to the file you want to write
 - Include the content to be written to the file
 - The tool will create any necessary parent directories
+- Set overwrite to true to replace a file that already exists
 
 FEATURES:
-- Can create new files or overwrite existing ones
+- Can create new files or, with overwrite=true, replace existing ones
+- The previous content of an overwritten file is kept in the file history
 - Creates parent directories automatically if they don't exist
 - Checks if the file has been modified since last read for safety
 - Avoids unnecessary writes when content hasn't changed
@@ -87,7 +90,7 @@ func NewWriteTool(lspService lsp.LspService, permissions permission.Service, fil
 }
 
 func (w *writeTool) Info() ToolInfo {
-	return ToolInfo{
+	return withPreconditions(ToolInfo{
 		Name:        WriteToolName,
 		Description: writeDescription,
 		Parameters: map[string]any{
@@ -99,29 +102,40 @@ func (w *writeTool) Info() ToolInfo {
 				"type":        "string",
 				"description": "The content to write to the file",
 			},
+			"overwrite": map[string]any{
+				"type":        "boolean",
+				"description": "Replace the file if it already exists (default false)",
+			},
+			"bom": bomParameter,
 		},
 		Required: []string{"file_path", "content"},
-	}
+	}, ReadFilePrecondition)
 }
 
 func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error) {
 	var params WriteParams
 	if err := json.Unmarshal([]byte(call.Input), &params); err != nil {
-		return NewTextErrorResponse(fmt.Sprintf("error parsing parameters: %s", err)), nil
+		return NewInvalidParamsResponse("error parsing parameters", call.Input, err), nil
 	}
 
 	if params.FilePath == "" {
-		return NewTextErrorResponse("file_path is required"), nil
+		return NewMissingParamResponse("file_path", "file_path is required"), nil
 	}
 
 	if params.Content == "" {
-		return NewTextErrorResponse("content is required"), nil
+		return NewMissingParamResponse("content", "content is required"), nil
+	}
+
+	filePath, err := ValidatePathInWorkingDirectory(resolveCallPath(ctx, params.FilePath))
+	if err != nil {
+		return NewPathErrorResponse(err, params.FilePath), nil
 	}
 
-	filePath, err := ValidatePathInWorkingDirectory(params.FilePath)
+	bom, err := resolveBOMMode(params.BOM)
 	if err != nil {
 		return NewTextErrorResponse(err.Error()), nil
 	}
+	content, _ := splitBOM(params.Content)
 
 	fileInfo, err := os.Stat(filePath)
 	if err == nil {
@@ -129,6 +143,14 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 			return NewTextErrorResponse("Path is a directory, not a file: " + filePath), nil
 		}
 
+		if !params.Overwrite {
+			return NewTextErrorResponse(fmt.Sprintf("File %s already exists. Set overwrite to true to replace it.", filePath)), nil
+		}
+
+		if isEnvFile(filePath) {
+			return envFileWriteError(filePath), nil
+		}
+
 		modTime := fileInfo.ModTime()
 		lastRead := getLastReadTime(filePath)
 		if modTime.After(lastRead) {
@@ -137,23 +159,32 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 
 		oldContent, readErr := os.ReadFile(filePath)
-		if readErr == nil && string(oldContent) == params.Content {
-			return NewTextErrorResponse(fmt.Sprintf("File %s already contains the exact content. No changes made.", filePath)), nil
+		if readErr == nil {
+			current, hadBOM := splitBOM(string(oldContent))
+			if current == content && string(oldContent) == withBOM(content, bom, hadBOM) {
+				return NewTextErrorResponse(fmt.Sprintf("File %s already contains the exact content. No changes made.", filePath)), nil
+			}
 		}
 	} else if !os.IsNotExist(err) {
 		return NewEmptyResponse(), fmt.Errorf("error checking file: %w", err)
 	}
 
+	if response, err := checkProtectedFile(ctx, w.permissions, WriteToolName, filePath); err != nil || response.IsError {
+		return response, err
+	}
+
 	dir := filepath.Dir(filePath)
 	if err = os.MkdirAll(dir, 0o755); err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error creating directory: %w", err)
 	}
 
-	oldContent := ""
+	oldContent, oldBytes := "", ""
+	hadBOM := false
 	if fileInfo != nil && !fileInfo.IsDir() {
-		oldBytes, readErr := os.ReadFile(filePath)
+		data, readErr := os.ReadFile(filePath)
 		if readErr == nil {
-			oldContent = string(oldBytes)
+			oldBytes = string(data)
+			oldContent, hadBOM = splitBOM(oldBytes)
 		}
 	}
 
@@ -162,9 +193,14 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		return NewEmptyResponse(), errors.New("session_id and message_id are required")
 	}
 
+	syntaxResponse, syntaxWarning := checkSyntax(filePath, oldContent, content)
+	if syntaxResponse.IsError {
+		return syntaxResponse, nil
+	}
+
 	diff, additions, removals := diff.GenerateDiff(
 		oldContent,
-		params.Content,
+		content,
 		filePath,
 	)
 
@@ -173,7 +209,11 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 	if strings.HasPrefix(filePath, rootDir) {
 		permissionPath = rootDir
 	}
-	action := w.registry.EvaluatePermission(GetAgentID(ctx), WriteToolName, filePath)
+	description := "Create file " + filePath
+	if fileInfo != nil {
+		description = "Overwrite file " + filePath
+	}
+	action := evaluateFilePermission(ctx, w.registry, WriteToolName, filePath)
 	switch action {
 	case permission.ActionAllow:
 		// Allowed by config
@@ -186,7 +226,7 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 				Path:        permissionPath,
 				ToolName:    WriteToolName,
 				Action:      "write",
-				Description: "Create file " + filePath,
+				Description: description,
 				Params: WritePermissionsParams{
 					FilePath: filePath,
 					Diff:     diff,
@@ -198,31 +238,14 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		}
 	}
 
-	err = os.WriteFile(filePath, []byte(params.Content), 0o644)
+	written := withBOM(content, bom, hadBOM)
+	err = writeFileAtomic(ctx, filePath, []byte(written))
 	if err != nil {
 		return NewEmptyResponse(), fmt.Errorf("error writing file: %w", err)
 	}
 
-	// Check if file exists in history
-	file, err := w.files.GetByPathAndSession(ctx, filePath, sessionID)
-	if err != nil {
-		_, err = w.files.Create(ctx, sessionID, filePath, oldContent)
-		if err != nil {
-			// Log error but don't fail the operation
-			return NewEmptyResponse(), fmt.Errorf("error creating file history: %w", err)
-		}
-	}
-	if file.Content != oldContent {
-		// User Manually changed the content store an intermediate version
-		_, err = w.files.CreateVersion(ctx, sessionID, filePath, oldContent)
-		if err != nil {
-			logging.Debug("Error creating file history version", "error", err)
-		}
-	}
-	// Store the new version
-	_, err = w.files.CreateVersion(ctx, sessionID, filePath, params.Content)
-	if err != nil {
-		logging.Debug("Error creating file history version", "error", err)
+	if err = recordOverwriteHistory(ctx, w.files, sessionID, filePath, oldBytes, written, fileInfo != nil); err != nil {
+		return NewEmptyResponse(), err
 	}
 
 	recordFileWrite(filePath)
@@ -231,7 +254,7 @@ func (w *writeTool) Run(ctx context.Context, call ToolCall) (ToolResponse, error
 		w.lsp.WaitForDiagnostics(ctx, filePath)
 	}
 
-	result := "File successfully written: " + filePath
+	result := "File successfully written: " + filePath + syntaxWarning
 	result = fmt.Sprintf("<result>\n%s\n</result>", result)
 	if w.lsp != nil {
 		result += w.lsp.FormatDiagnostics(filePath)
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mock_permission "github.com/MerrukTechnology/OpenCode-Native/internal/permission/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func setupWriteTest(t *testing.T) (context.Context, *stubHistoryService, BaseTool) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+
+	mockPerms := mock_permission.NewMockService(ctrl)
+	mockPerms.EXPECT().Request(gomock.Any()).Return(true).AnyTimes()
+
+	files := newStubHistoryService()
+	tool := NewWriteTool(nil, mockPerms, files, &stubRegistry{})
+
+	ctx := context.WithValue(context.Background(), SessionIDContextKey, "test-session")
+	ctx = context.WithValue(ctx, MessageIDContextKey, "test-message")
+	return ctx, files, tool
+}
+
+func runWrite(t *testing.T, tool BaseTool, ctx context.Context, params WriteParams) ToolResponse {
+	t.Helper()
+	paramsJSON, err := json.Marshal(params)
+	require.NoError(t, err)
+	resp, err := tool.Run(ctx, ToolCall{Name: WriteToolName, Input: string(paramsJSON)})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestWriteTool_CreatesNewFile(t *testing.T) {
+	ctx, files, tool := setupWriteTest(t)
+	dir := createTempDirInWorkingDir(t, "write_test_*")
+	path := filepath.Join(dir, "new.txt")
+
+	resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "hello"})
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	assert.Equal(t, "hello", files.lastContent)
+}
+
+func TestWriteTool_ExistingFileRequiresOverwrite(t *testing.T) {
+	ctx, files, tool := setupWriteTest(t)
+	path := createTempFileInWorkingDir(t, "write_test_*.txt")
+	writeAndTrack(t, path, "original")
+
+	resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "replacement"})
+	assert.True(t, resp.IsError)
+	assert.Contains(t, resp.Content, "already exists")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+	assert.Empty(t, files.versions)
+}
+
+func TestWriteTool_OverwriteRecordsPreviousContent(t *testing.T) {
+	ctx, files, tool := setupWriteTest(t)
+	path := createTempFileInWorkingDir(t, "write_test_*.txt")
+	writeAndTrack(t, path, "original")
+
+	resp := runWrite(t, tool, ctx, WriteParams{FilePath: path, Content: "replacement", Overwrite: true})
+	require.False(t, resp.IsError, resp.Content)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "replacement", string(content))
+
+	// The pre-overwrite content is the first recorded version and can be restored
+	require.Len(t, files.versions, 2)
+	assert.Equal(t, "original", files.versions[0])
+	assert.Equal(t, "replacement", files.versions[1])
+}
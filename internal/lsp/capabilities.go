@@ -0,0 +1,18 @@
+package lsp
+
+import "github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
+
+// textDocumentSyncKind returns how the server wants document changes, from a
+// textDocumentSync capability that is either a kind or an options object.
+// Servers that leave it out get full-document changes, as they always have.
+func textDocumentSyncKind(sync any) protocol.TextDocumentSyncKind {
+	switch s := sync.(type) {
+	case float64:
+		return protocol.TextDocumentSyncKind(s)
+	case map[string]any:
+		if change, ok := s["change"].(float64); ok {
+			return protocol.TextDocumentSyncKind(change)
+		}
+	}
+	return protocol.Full
+}
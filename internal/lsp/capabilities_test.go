@@ -0,0 +1,38 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/lsp/protocol"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextDocumentSyncKind(t *testing.T) {
+	tests := []struct {
+		name string
+		sync any
+		want protocol.TextDocumentSyncKind
+	}{
+		{name: "unset", sync: nil, want: protocol.Full},
+		{name: "kind", sync: float64(protocol.Incremental), want: protocol.Incremental},
+		{name: "options", sync: map[string]any{"openClose": true, "change": float64(protocol.None)}, want: protocol.None},
+		{name: "options without change", sync: map[string]any{"openClose": true}, want: protocol.Full},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, textDocumentSyncKind(tt.sync))
+		})
+	}
+}
+
+func TestNotifyChangeWithoutSync(t *testing.T) {
+	client := &Client{}
+	client.textDocumentSync.Store(uint32(protocol.None))
+	path := filepath.Join(t.TempDir(), "main.go")
+	require.NoError(t, os.WriteFile(path, []byte("package main\n"), 0o644))
+
+	assert.NoError(t, client.NotifyChange(t.Context(), path), "servers that do not sync changes get no didChange")
+}
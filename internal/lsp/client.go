@@ -14,6 +14,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
@@ -58,6 +59,19 @@ type Client struct {
 
 	// Server state
 	serverState atomic.Value
+
+	// Whether the server advertised textDocument/rename support
+	renameSupport atomic.Bool
+
+	// The protocol.TextDocumentSyncKind the server asked for
+	textDocumentSync atomic.Uint32
+
+	// transportFailed is closed once the connection to the server breaks
+	transportFailed     chan struct{}
+	transportFailedOnce sync.Once
+	// closing is set by Close and the exit notification, so the end of the
+	// connection they cause is not reported as a failure
+	closing atomic.Bool
 }
 
 // NewClient creates and starts a new LSP client with the given command and environment.
@@ -94,10 +108,12 @@ func NewClient(ctx context.Context, command string, env map[string]string, args
 		serverRequestHandlers: make(map[string]ServerRequestHandler),
 		diagnostics:           make(map[protocol.DocumentUri][]protocol.Diagnostic),
 		openFiles:             make(map[string]*OpenFileInfo),
+		transportFailed:       make(chan struct{}),
 	}
 
 	// Initialize server state
 	client.serverState.Store(StateStarting)
+	client.textDocumentSync.Store(uint32(protocol.Full))
 
 	// Start the LSP server process
 	if err := cmd.Start(); err != nil {
@@ -202,6 +218,7 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string, i
 						DynamicRegistration: true,
 					},
 					DocumentSymbol: protocol.DocumentSymbolClientCapabilities{},
+					Rename:         &protocol.RenameClientCapabilities{},
 					CodeAction: protocol.CodeActionClientCapabilities{
 						CodeActionLiteralSupport: protocol.ClientCodeActionLiteralOptions{
 							CodeActionKind: protocol.ClientCodeActionKindOptions{
@@ -232,6 +249,8 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string, i
 	if err := c.Call(ctx, "initialize", initParams, &result); err != nil {
 		return nil, fmt.Errorf("initialize failed: %w", err)
 	}
+	c.renameSupport.Store(hasRenameProvider(result.Capabilities.RenameProvider))
+	c.textDocumentSync.Store(uint32(textDocumentSyncKind(result.Capabilities.TextDocumentSync)))
 
 	if err := c.Notify(ctx, "initialized", struct{}{}); err != nil {
 		return nil, fmt.Errorf("initialized notification failed: %w", err)
@@ -255,6 +274,8 @@ func (c *Client) InitializeLSPClient(ctx context.Context, workspaceDir string, i
 }
 
 func (c *Client) Close() error {
+	c.closing.Store(true)
+
 	// Try to close all open files first
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -286,6 +307,35 @@ func (c *Client) Close() error {
 	}
 }
 
+// TransportFailed returns a channel that is closed once the connection to
+// the server breaks, e.g. because the server exited or closed its pipes. It
+// is not closed when the connection ends through Close or an exit
+// notification.
+func (c *Client) TransportFailed() <-chan struct{} {
+	return c.transportFailed
+}
+
+// markTransportFailed records that the connection to the server broke with
+// err and moves the server to StateError.
+func (c *Client) markTransportFailed(err error) {
+	if c.closing.Load() {
+		return
+	}
+	c.transportFailedOnce.Do(func() {
+		logging.Warn("LSP server connection failed", "cmd", c.Cmd.Path, "error", err)
+		c.SetServerState(StateError)
+		close(c.transportFailed)
+	})
+}
+
+// isTransportError reports whether err means the pipes to the server are
+// gone rather than that a single message was bad.
+func isTransportError(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
 type ServerState int
 
 const (
@@ -295,6 +345,24 @@ const (
 )
 
 // GetServerState returns the current state of the LSP server
+// SupportsRename reports whether the server handles textDocument/rename.
+func (c *Client) SupportsRename() bool {
+	return c.renameSupport.Load()
+}
+
+// hasRenameProvider reports whether a renameProvider server capability, which
+// is either a boolean or a RenameOptions object, enables renaming.
+func hasRenameProvider(provider any) bool {
+	switch p := provider.(type) {
+	case nil:
+		return false
+	case bool:
+		return p
+	default:
+		return true
+	}
+}
+
 func (c *Client) GetServerState() ServerState {
 	if val := c.serverState.Load(); val != nil {
 		return val.(ServerState)
@@ -308,16 +376,20 @@ func (c *Client) SetServerState(state ServerState) {
 }
 
 // WaitForServerReady waits for the server to be ready by polling the server
-// with a simple request until it responds successfully or times out
+// with a simple request until it responds successfully or times out. When ctx
+// has no deadline, the wait is limited to 30 seconds.
 func (c *Client) WaitForServerReady(ctx context.Context) error {
 	cnf := config.Get()
 
 	// Set initial state
 	c.SetServerState(StateStarting)
 
-	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
+	// Create a context with timeout unless the caller set one
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+	}
 
 	// Try to ping the server with a simple request
 	ticker := time.NewTicker(500 * time.Millisecond)
@@ -666,8 +738,14 @@ func (c *Client) OpenFile(ctx context.Context, filePath string) error {
 	return nil
 }
 
+// NotifyChange sends the whole content of an open file to the server. Servers
+// that sync incrementally accept whole-document changes too; servers that do
+// not want changes get none.
 func (c *Client) NotifyChange(ctx context.Context, filepath string) error {
 	uri := "file://" + filepath
+	if protocol.TextDocumentSyncKind(c.textDocumentSync.Load()) == protocol.None {
+		return nil
+	}
 
 	content, err := os.ReadFile(filepath)
 	if err != nil {
@@ -1,7 +1,12 @@
 package install
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/stretchr/testify/assert"
@@ -76,6 +81,30 @@ func TestResolveServers_UserOverridesCommand(t *testing.T) {
 	assert.Equal(t, []string{".go"}, gopls.Extensions) // keeps built-in extensions
 }
 
+func TestResolveServers_DedupesSameCommand(t *testing.T) {
+	cfg := &config.Config{
+		LSP: map[string]config.LSPConfig{
+			"gopls":  {},
+			"gomod":  {Command: "gopls", Extensions: []string{".mod"}},
+			"remote": {Command: "gopls", Args: []string{"-remote=auto"}},
+		},
+	}
+
+	servers := ResolveServers(cfg)
+	assert.Len(t, servers, 2)
+
+	_, ok := servers["gopls"]
+	assert.False(t, ok, "duplicate merged into the first name")
+	gomod, ok := servers["gomod"]
+	require.True(t, ok)
+	assert.Equal(t, []string{"gopls"}, gomod.Command)
+	assert.Equal(t, []string{".mod", ".go"}, gomod.Extensions)
+
+	remote, ok := servers["remote"]
+	require.True(t, ok, "different args are a different server")
+	assert.Equal(t, []string{"gopls", "-remote=auto"}, remote.Command)
+}
+
 func TestResolveServers_UserOverridesExtensions(t *testing.T) {
 	cfg := &config.Config{
 		LSP: map[string]config.LSPConfig{
@@ -125,6 +154,55 @@ func TestResolveServers_UserAddsInitialization(t *testing.T) {
 	assert.Equal(t, init, gopls.Initialization)
 }
 
+func TestResolveServers_ReadyOptions(t *testing.T) {
+	cfg := &config.Config{
+		LSP: map[string]config.LSPConfig{
+			"rust-analyzer": {
+				ReadyTimeout: 120,
+				ReadyRetries: 2,
+			},
+		},
+	}
+
+	servers := ResolveServers(cfg)
+	assert.Len(t, servers, 1)
+
+	server := servers["rust-analyzer"]
+	assert.Equal(t, 120*time.Second, server.ReadyTimeout)
+	assert.Equal(t, 2, server.ReadyRetries)
+}
+
+func TestResolveServers_RestartOptions(t *testing.T) {
+	off := false
+	cfg := &config.Config{
+		LSP: map[string]config.LSPConfig{
+			"gopls":         {},
+			"rust-analyzer": {AutoRestart: &off, MaxRestarts: 5},
+		},
+	}
+
+	servers := ResolveServers(cfg)
+	assert.True(t, servers["gopls"].AutoRestart, "auto restart is on by default")
+	assert.Equal(t, config.DefaultLSPMaxRestarts, servers["gopls"].MaxRestarts)
+	assert.False(t, servers["rust-analyzer"].AutoRestart)
+	assert.Equal(t, 5, servers["rust-analyzer"].MaxRestarts)
+}
+
+func TestResolveServers_InstallOptions(t *testing.T) {
+	cfg := &config.Config{
+		LSP: map[string]config.LSPConfig{
+			"gopls":     {Version: "0.16.2", Download: config.LSPDownloadAllow},
+			"terraform": {Download: config.LSPDownloadDeny},
+		},
+	}
+
+	servers := ResolveServers(cfg)
+	assert.Equal(t, "0.16.2", servers["gopls"].Version)
+	assert.Equal(t, config.LSPDownloadAllow, servers["gopls"].Download)
+	assert.Empty(t, servers["terraform"].Version)
+	assert.Equal(t, config.LSPDownloadDeny, servers["terraform"].Download)
+}
+
 func TestResolveServers_CustomServer(t *testing.T) {
 	cfg := &config.Config{
 		LSP: map[string]config.LSPConfig{
@@ -180,6 +258,74 @@ func TestResolveServers_GoplsDefaultInit(t *testing.T) {
 	assert.True(t, hasCodelenses, "gopls should have default codelenses init options")
 }
 
+func TestResolveServers_Auto(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the server binary")
+	}
+	cfg := &config.Config{
+		LSP: map[string]config.LSPConfig{
+			"go":   {Auto: true},
+			"rust": {Auto: true, Env: map[string]string{"RUST_LOG": "info"}},
+		},
+	}
+	require.NoError(t, ValidateAuto(cfg))
+
+	servers := ResolveServers(cfg)
+	assert.Len(t, servers, 2)
+
+	gopls, ok := servers["go"]
+	require.True(t, ok)
+	assert.Equal(t, "gopls", gopls.ID)
+	assert.Equal(t, []string{"gopls"}, gopls.Command)
+	assert.Equal(t, StrategyGoInstall, gopls.Strategy)
+	assert.NotNil(t, gopls.Initialization, "auto entries get the built-in defaults")
+
+	rust := servers["rust"]
+	assert.Equal(t, "rust-analyzer", rust.ID)
+	assert.Equal(t, map[string]string{"RUST_LOG": "info"}, rust.Env)
+
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "gopls"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", binDir)
+	cmd, args, err := ResolveCommand(t.Context(), gopls, true)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(binDir, "gopls"), cmd)
+	assert.Empty(t, args)
+}
+
+func TestAutoServer(t *testing.T) {
+	for _, language := range []string{"go", ".go", "golang", "GO", "gopls"} {
+		def, err := AutoServer(language)
+		require.NoError(t, err, language)
+		assert.Equal(t, "gopls", def.ID, language)
+	}
+	def, err := AutoServer("python")
+	require.NoError(t, err)
+	assert.Equal(t, "pyright", def.ID)
+
+	_, err = AutoServer("cobol")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `no built-in LSP server for "cobol"`)
+}
+
+func TestValidateAuto_UnknownLanguage(t *testing.T) {
+	cfg := &config.Config{
+		LSP: map[string]config.LSPConfig{
+			"go":    {Auto: true},
+			"cobol": {Auto: true},
+		},
+	}
+	err := ValidateAuto(cfg)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid lsp.cobol")
+
+	_, ok := ResolveServers(cfg)["cobol"]
+	assert.False(t, ok, "unknown auto entries are not started")
+
+	cfg.LSP["cobol"] = config.LSPConfig{Auto: true, Disabled: true}
+	assert.NoError(t, ValidateAuto(cfg), "disabled entries are not checked")
+}
+
 func TestBuiltinServers_NoDuplicateIDs(t *testing.T) {
 	seen := make(map[string]bool)
 	for _, s := range BuiltinServers {
@@ -221,3 +367,120 @@ func TestBinDir(t *testing.T) {
 	assert.Contains(t, dir, ".opencode")
 	assert.Contains(t, dir, "bin")
 }
+
+// fakeInstallers replaces the installers with one that records the servers
+// it installs and creates their binary.
+func fakeInstallers(t *testing.T) *[]ResolvedServer {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	var installed []ResolvedServer
+	fake := func(_ context.Context, server ResolvedServer, dir string) error {
+		installed = append(installed, server)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		return os.WriteFile(filepath.Join(dir, server.Command[0]), []byte("#!/bin/sh\n"), 0o755)
+	}
+	original := installers
+	installers = map[InstallStrategy]func(context.Context, ResolvedServer, string) error{
+		StrategyNpm:           fake,
+		StrategyGoInstall:     fake,
+		StrategyGitHubRelease: fake,
+	}
+	t.Cleanup(func() { installers = original })
+	return &installed
+}
+
+func TestResolveCommand_DownloadOverride(t *testing.T) {
+	server := ResolvedServer{
+		ID:             "gopls",
+		Command:        []string{"opencode-test-gopls"},
+		Strategy:       StrategyGoInstall,
+		InstallPackage: "golang.org/x/tools/gopls@latest",
+	}
+
+	t.Run("per-language deny overrides a global allow", func(t *testing.T) {
+		installed := fakeInstallers(t)
+		denied := server
+		denied.Download = config.LSPDownloadDeny
+		_, _, err := ResolveCommand(context.Background(), denied, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "auto-install disabled")
+		assert.Empty(t, *installed)
+	})
+
+	t.Run("per-language allow overrides a global deny", func(t *testing.T) {
+		installed := fakeInstallers(t)
+		allowed := server
+		allowed.Download = config.LSPDownloadAllow
+		path, _, err := ResolveCommand(context.Background(), allowed, true)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(BinDir(), "opencode-test-gopls"), path)
+		assert.Len(t, *installed, 1)
+	})
+
+	t.Run("global setting applies without an override", func(t *testing.T) {
+		installed := fakeInstallers(t)
+		_, _, err := ResolveCommand(context.Background(), server, true)
+		require.Error(t, err)
+		assert.Empty(t, *installed)
+	})
+}
+
+func TestResolveCommand_PinnedVersion(t *testing.T) {
+	installed := fakeInstallers(t)
+	server := ResolvedServer{
+		ID:             "gopls",
+		Command:        []string{"opencode-test-gopls", "serve"},
+		Strategy:       StrategyGoInstall,
+		InstallPackage: "golang.org/x/tools/gopls@latest",
+		Version:        "0.16.2",
+	}
+	// An unpinned install must not satisfy the pinned version
+	require.NoError(t, os.MkdirAll(BinDir(), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(BinDir(), "opencode-test-gopls"), nil, 0o755))
+
+	path, args, err := ResolveCommand(context.Background(), server, false)
+	require.NoError(t, err)
+	wantPath := filepath.Join(BinDir(), "versions", "gopls@0.16.2", "opencode-test-gopls")
+	assert.Equal(t, wantPath, path)
+	assert.Equal(t, []string{"serve"}, args)
+	require.Len(t, *installed, 1)
+	assert.Equal(t, "0.16.2", (*installed)[0].Version)
+	assert.Equal(t, "golang.org/x/tools/gopls@v0.16.2", goPackage((*installed)[0]))
+
+	// The installed version is reused, even with downloads disabled
+	path, _, err = ResolveCommand(context.Background(), server, true)
+	require.NoError(t, err)
+	assert.Equal(t, wantPath, path)
+	assert.Len(t, *installed, 1)
+
+	other := server
+	other.Version = "0.17.0"
+	_, _, err = ResolveCommand(context.Background(), other, true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gopls 0.17.0 is not installed")
+}
+
+func TestPinnedPackages(t *testing.T) {
+	npm := ResolvedServer{InstallPackage: "typescript-language-server typescript", Version: "v4.3.3"}
+	assert.Equal(t, []string{"typescript-language-server@4.3.3", "typescript"}, npmPackages(npm))
+	npm.Version = ""
+	assert.Equal(t, []string{"typescript-language-server", "typescript"}, npmPackages(npm))
+
+	scoped := ResolvedServer{InstallPackage: "@vue/language-server", Version: "2.1.0"}
+	assert.Equal(t, []string{"@vue/language-server@2.1.0"}, npmPackages(scoped))
+
+	goServer := ResolvedServer{InstallPackage: "golang.org/x/tools/gopls@latest"}
+	assert.Equal(t, "golang.org/x/tools/gopls@latest", goPackage(goServer))
+	goServer.Version = "v0.16.2"
+	assert.Equal(t, "golang.org/x/tools/gopls@v0.16.2", goPackage(goServer))
+
+	release := ResolvedServer{InstallRepo: "hashicorp/terraform-ls", TagPrefix: "v"}
+	assert.Equal(t, "https://api.github.com/repos/hashicorp/terraform-ls/releases/latest", releaseURL(release))
+	release.Version = "v0.34.0"
+	assert.Equal(t, "https://api.github.com/repos/hashicorp/terraform-ls/releases/tags/v0.34.0", releaseURL(release))
+	release.Version = "0.34.0"
+	assert.Equal(t, "https://api.github.com/repos/hashicorp/terraform-ls/releases/tags/v0.34.0", releaseURL(release))
+
+	untagged := ResolvedServer{InstallRepo: "LuaLS/lua-language-server", Version: "v3.13.5"}
+	assert.Equal(t, "https://api.github.com/repos/LuaLS/lua-language-server/releases/tags/3.13.5", releaseURL(untagged))
+}
@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 )
 
@@ -24,7 +25,54 @@ func BinDir() string {
 	return filepath.Join(home, ".opencode", "bin")
 }
 
+// installers install the server of each download strategy into a
+// directory.
+var installers = map[InstallStrategy]func(ctx context.Context, server ResolvedServer, dir string) error{
+	StrategyNpm:           installNpm,
+	StrategyGoInstall:     installGo,
+	StrategyGitHubRelease: installGitHubRelease,
+}
+
+// installDir returns the directory server is installed in. Pinned versions
+// get a directory of their own so they never mix with other releases.
+func installDir(server ResolvedServer) string {
+	if server.Version == "" {
+		return BinDir()
+	}
+	return filepath.Join(BinDir(), "versions", server.ID+"@"+server.Version)
+}
+
+// downloadDisabled applies the download option of the server's entry to the
+// global disableDownload setting.
+func (s ResolvedServer) downloadDisabled(disableDownload bool) bool {
+	switch s.Download {
+	case config.LSPDownloadAllow:
+		return false
+	case config.LSPDownloadDeny:
+		return true
+	default:
+		return disableDownload
+	}
+}
+
+// findInstalled returns the path of the binary cmd installed in dir, directly
+// or by npm, or "" when it is not there.
+func findInstalled(dir, cmd string) string {
+	for _, path := range []string{
+		filepath.Join(dir, cmd),
+		filepath.Join(dir, "node_modules", ".bin", cmd),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
 // ResolveCommand finds or installs the LSP server binary and returns the command + args.
+// The download option of the server's entry overrides disableDownload. A
+// server with a pinned version only runs that version, which is installed
+// on first use, instead of a binary on PATH.
 func ResolveCommand(ctx context.Context, server ResolvedServer, disableDownload bool) (string, []string, error) {
 	if len(server.Command) == 0 {
 		return "", nil, fmt.Errorf("no command configured for %s", server.ID)
@@ -32,6 +80,7 @@ func ResolveCommand(ctx context.Context, server ResolvedServer, disableDownload
 
 	cmd := server.Command[0]
 	args := server.Command[1:]
+	disableDownload = server.downloadDisabled(disableDownload)
 
 	// If user provided an absolute path, use it directly
 	if filepath.IsAbs(cmd) {
@@ -42,23 +91,20 @@ func ResolveCommand(ctx context.Context, server ResolvedServer, disableDownload
 		return "", nil, fmt.Errorf("configured command not found: %s", cmd)
 	}
 
+	if server.Version != "" && server.Strategy != StrategyNone {
+		return resolvePinnedCommand(ctx, server, disableDownload)
+	}
+
 	// Check system PATH
 	if path, err := exec.LookPath(cmd); err == nil {
 		logServerVersion(ctx, path, server.ID)
 		return path, args, nil
 	}
 
-	// Check our bin directory
+	// Check our bin directory, including node_modules/.bin for npm packages
 	binDir := BinDir()
-	localBin := filepath.Join(binDir, cmd)
-	if _, err := os.Stat(localBin); err == nil {
-		return localBin, args, nil
-	}
-
-	// For npm packages, check node_modules/.bin
-	npmBin := filepath.Join(binDir, "node_modules", ".bin", cmd)
-	if _, err := os.Stat(npmBin); err == nil {
-		return npmBin, args, nil
+	if path := findInstalled(binDir, cmd); path != "" {
+		return path, args, nil
 	}
 
 	// Try auto-install if not disabled
@@ -66,39 +112,88 @@ func ResolveCommand(ctx context.Context, server ResolvedServer, disableDownload
 		return "", nil, fmt.Errorf("binary %q not found for %s (auto-install disabled or not supported)", cmd, server.ID)
 	}
 
-	logging.Info("Auto-installing LSP server", "name", server.ID, "strategy", server.Strategy)
+	if err := install(ctx, server, binDir); err != nil {
+		return "", nil, err
+	}
 
-	var err error
-	switch server.Strategy {
-	case StrategyNpm:
-		err = installNpm(ctx, server)
-	case StrategyGoInstall:
-		err = installGo(ctx, server)
-	case StrategyGitHubRelease:
-		err = installGitHubRelease(ctx, server)
-	default:
-		return "", nil, fmt.Errorf("unknown install strategy for %s", server.ID)
+	// Re-check after install
+	if path, err := exec.LookPath(cmd); err == nil {
+		logServerVersion(ctx, path, server.ID)
+		return path, args, nil
+	}
+	if path := findInstalled(binDir, cmd); path != "" {
+		logServerVersion(ctx, path, server.ID)
+		return path, args, nil
 	}
 
-	if err != nil {
-		return "", nil, fmt.Errorf("auto-install failed for %s: %w", server.ID, err)
+	return "", nil, fmt.Errorf("binary %q still not found after install for %s", cmd, server.ID)
+}
+
+// resolvePinnedCommand finds or installs the pinned version of server.
+func resolvePinnedCommand(ctx context.Context, server ResolvedServer, disableDownload bool) (string, []string, error) {
+	cmd := server.Command[0]
+	args := server.Command[1:]
+	dir := installDir(server)
+	if path := findInstalled(dir, cmd); path != "" {
+		logServerVersion(ctx, path, server.ID)
+		return path, args, nil
+	}
+	if disableDownload {
+		return "", nil, fmt.Errorf("%s %s is not installed in %s (auto-install disabled)", server.ID, server.Version, dir)
 	}
 
-	// Re-check after install
-	if path, err := exec.LookPath(cmd); err == nil {
+	if err := install(ctx, server, dir); err != nil {
+		return "", nil, err
+	}
+	if path := findInstalled(dir, cmd); path != "" {
 		logServerVersion(ctx, path, server.ID)
 		return path, args, nil
 	}
-	if _, err := os.Stat(localBin); err == nil {
-		logServerVersion(ctx, localBin, server.ID)
-		return localBin, args, nil
+	return "", nil, fmt.Errorf("binary %q still not found after installing %s %s", cmd, server.ID, server.Version)
+}
+
+// install installs server into dir with the installer of its strategy.
+func install(ctx context.Context, server ResolvedServer, dir string) error {
+	installer, ok := installers[server.Strategy]
+	if !ok {
+		return fmt.Errorf("unknown install strategy for %s", server.ID)
+	}
+	logging.Info("Auto-installing LSP server", "name", server.ID, "strategy", server.Strategy, "version", server.Version)
+	if err := installer(ctx, server, dir); err != nil {
+		return fmt.Errorf("auto-install failed for %s: %w", server.ID, err)
 	}
-	if _, err := os.Stat(npmBin); err == nil {
-		logServerVersion(ctx, npmBin, server.ID)
-		return npmBin, args, nil
+	return nil
+}
+
+// npmPackages returns the packages npm installs for server. A pinned version
+// applies to the first package, the server itself.
+func npmPackages(server ResolvedServer) []string {
+	packages := strings.Fields(server.InstallPackage)
+	if server.Version != "" && len(packages) > 0 {
+		packages[0] += "@" + strings.TrimPrefix(server.Version, "v")
 	}
+	return packages
+}
 
-	return "", nil, fmt.Errorf("binary %q still not found after install for %s", cmd, server.ID)
+// goPackage returns the module path go installs for server, at its pinned
+// version when it has one.
+func goPackage(server ResolvedServer) string {
+	if server.Version == "" {
+		return server.InstallPackage
+	}
+	pkg, _, _ := strings.Cut(server.InstallPackage, "@")
+	return pkg + "@v" + strings.TrimPrefix(server.Version, "v")
+}
+
+// releaseURL returns the GitHub API URL of the release server is downloaded
+// from: the latest one, or the one tagged with its pinned version. The
+// version may be pinned with or without a "v", whichever the repo tags with.
+func releaseURL(server ResolvedServer) string {
+	if server.Version == "" {
+		return fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", server.InstallRepo)
+	}
+	tag := server.TagPrefix + strings.TrimPrefix(server.Version, "v")
+	return fmt.Sprintf("https://api.github.com/repos/%s/releases/tags/%s", server.InstallRepo, tag)
 }
 
 // logServerVersion attempts to get and log the server version for debugging.
@@ -117,18 +212,17 @@ func logServerVersion(ctx context.Context, binaryPath, serverID string) {
 	logging.Info("LSP server resolved", "name", serverID, "path", binaryPath)
 }
 
-func installNpm(ctx context.Context, server ResolvedServer) error {
+func installNpm(ctx context.Context, server ResolvedServer, binDir string) error {
 	npmPath, err := exec.LookPath("npm")
 	if err != nil {
 		return fmt.Errorf("npm not found in PATH, cannot auto-install %s", server.ID)
 	}
 
-	binDir := BinDir()
 	if err := os.MkdirAll(binDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
-	packages := strings.Fields(server.InstallPackage)
+	packages := npmPackages(server)
 	args := append([]string{"install", "--prefix", binDir}, packages...)
 
 	cmd := exec.CommandContext(ctx, npmPath, args...)
@@ -144,18 +238,17 @@ func installNpm(ctx context.Context, server ResolvedServer) error {
 	return nil
 }
 
-func installGo(ctx context.Context, server ResolvedServer) error {
+func installGo(ctx context.Context, server ResolvedServer, binDir string) error {
 	goPath, err := exec.LookPath("go")
 	if err != nil {
 		return fmt.Errorf("go not found in PATH, cannot auto-install %s", server.ID)
 	}
 
-	binDir := BinDir()
 	if err := os.MkdirAll(binDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, goPath, "install", server.InstallPackage)
+	cmd := exec.CommandContext(ctx, goPath, "install", goPackage(server))
 	cmd.Env = append(os.Environ(), "GOBIN="+binDir)
 
 	output, err := cmd.CombinedOutput()
@@ -167,19 +260,17 @@ func installGo(ctx context.Context, server ResolvedServer) error {
 	return nil
 }
 
-func installGitHubRelease(ctx context.Context, server ResolvedServer) error {
+func installGitHubRelease(ctx context.Context, server ResolvedServer, binDir string) error {
 	if server.InstallRepo == "" {
 		return fmt.Errorf("no GitHub repo configured for %s", server.ID)
 	}
 
-	binDir := BinDir()
 	if err := os.MkdirAll(binDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create bin directory: %w", err)
 	}
 
-	// Fetch latest release info
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", server.InstallRepo)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	// Fetch the release info
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseURL(server), nil)
 	if err != nil {
 		return err
 	}
@@ -110,6 +110,7 @@ var BuiltinServers = []ServerDefinition{
 		Command:     []string{"terraform-ls", "serve"},
 		Strategy:    StrategyGitHubRelease,
 		InstallRepo: "hashicorp/terraform-ls",
+		TagPrefix:   "v",
 	},
 
 	// Tinymist (Typst)
@@ -119,6 +120,7 @@ var BuiltinServers = []ServerDefinition{
 		Command:     []string{"tinymist", "lsp"},
 		Strategy:    StrategyGitHubRelease,
 		InstallRepo: "Myriad-Dreamin/tinymist",
+		TagPrefix:   "v",
 	},
 
 	// --- Servers that require pre-installation (StrategyNone) ---
@@ -1,7 +1,13 @@
 package install
 
 import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
 )
 
 // InstallStrategy defines how an LSP server binary is obtained.
@@ -22,6 +28,7 @@ type ServerDefinition struct {
 	Strategy       InstallStrategy
 	InstallPackage string // npm package name or go module path
 	InstallRepo    string // GitHub owner/repo for release downloads
+	TagPrefix      string // Prefix of the repo's release tags, such as "v"
 	DefaultInit    map[string]any
 }
 
@@ -32,9 +39,16 @@ type ResolvedServer struct {
 	Command        []string
 	Env            map[string]string
 	Initialization any
+	ReadyTimeout   time.Duration
+	ReadyRetries   int
+	AutoRestart    bool
+	MaxRestarts    int
+	Download       config.LSPDownload
+	Version        string
 	Strategy       InstallStrategy
 	InstallPackage string
 	InstallRepo    string
+	TagPrefix      string
 }
 
 // builtinByID returns a lookup map from server ID to its built-in definition.
@@ -46,9 +60,63 @@ func builtinByID() map[string]ServerDefinition {
 	return m
 }
 
+// languageExtensions maps language names to the extension of their files, so
+// auto entries can be keyed by either.
+var languageExtensions = map[string]string{
+	"golang":     "go",
+	"typescript": "ts",
+	"javascript": "js",
+	"python":     "py",
+	"rust":       "rs",
+	"c++":        "cpp",
+	"kotlin":     "kt",
+	"haskell":    "hs",
+	"ocaml":      "ml",
+	"clojure":    "clj",
+}
+
+// AutoServer returns the built-in server for an auto LSP entry. language is
+// a file extension such as "go" or ".rs", a language name such as "rust", or
+// the ID of a built-in server.
+func AutoServer(language string) (ServerDefinition, error) {
+	key := strings.ToLower(strings.TrimPrefix(language, "."))
+	if def, ok := builtinByID()[key]; ok {
+		return def, nil
+	}
+	if ext, ok := languageExtensions[key]; ok {
+		key = ext
+	}
+	for _, def := range BuiltinServers {
+		if slices.Contains(def.Extensions, "."+key) {
+			return def, nil
+		}
+	}
+	return ServerDefinition{}, fmt.Errorf("no built-in LSP server for %q; set a command instead of auto", language)
+}
+
+// ValidateAuto checks that every enabled auto LSP entry of cfg names a
+// language with a built-in server.
+func ValidateAuto(cfg *config.Config) error {
+	names := make([]string, 0, len(cfg.LSP))
+	for name := range cfg.LSP {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if lspCfg := cfg.LSP[name]; lspCfg.Auto && !lspCfg.Disabled {
+			if _, err := AutoServer(name); err != nil {
+				return fmt.Errorf("invalid lsp.%s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // ResolveServers returns only LSP servers explicitly configured by the user.
-// If a configured server matches a built-in, its defaults are merged.
-// Disabled servers are excluded from the result.
+// If a configured server matches a built-in, or is an auto entry for a
+// language with a built-in server, its defaults are merged.
+// Disabled servers are excluded from the result, and servers that resolve to
+// the same command share one entry.
 func ResolveServers(cfg *config.Config) map[string]ResolvedServer {
 	result := make(map[string]ResolvedServer)
 	builtins := builtinByID()
@@ -60,7 +128,16 @@ func ResolveServers(cfg *config.Config) map[string]ResolvedServer {
 
 		var server ResolvedServer
 
-		if def, ok := builtins[name]; ok {
+		def, ok := builtins[name]
+		if !ok && lspCfg.Auto {
+			var err error
+			if def, err = AutoServer(name); err != nil {
+				logging.Warn("Skipping auto LSP configuration", "language", name, "error", err)
+				continue
+			}
+			ok = true
+		}
+		if ok {
 			var initOpts any
 			if def.DefaultInit != nil {
 				initOpts = def.DefaultInit
@@ -72,6 +149,7 @@ func ResolveServers(cfg *config.Config) map[string]ResolvedServer {
 				Strategy:       def.Strategy,
 				InstallPackage: def.InstallPackage,
 				InstallRepo:    def.InstallRepo,
+				TagPrefix:      def.TagPrefix,
 				Initialization: initOpts,
 			}
 		} else {
@@ -93,9 +171,53 @@ func ResolveServers(cfg *config.Config) map[string]ResolvedServer {
 		if lspCfg.Initialization != nil {
 			server.Initialization = lspCfg.Initialization
 		}
+		server.ReadyTimeout = time.Duration(lspCfg.ReadyTimeout) * time.Second
+		server.ReadyRetries = lspCfg.ReadyRetries
+		server.AutoRestart = lspCfg.AutoRestart == nil || *lspCfg.AutoRestart
+		server.MaxRestarts = lspCfg.MaxRestarts
+		if server.MaxRestarts == 0 {
+			server.MaxRestarts = config.DefaultLSPMaxRestarts
+		}
+		server.Download = lspCfg.Download
+		server.Version = lspCfg.Version
 
 		result[name] = server
 	}
 
+	dedupeServers(result)
 	return result
 }
+
+// dedupeServers merges servers that resolve to the same command and arguments
+// so the command is only started once. The entry whose name sorts first is
+// kept and takes the extensions of the others.
+func dedupeServers(servers map[string]ResolvedServer) {
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	kept := make(map[string]string)
+	for _, name := range names {
+		server := servers[name]
+		if len(server.Command) == 0 {
+			continue
+		}
+		key := strings.Join(server.Command, "\x00")
+		keptName, ok := kept[key]
+		if !ok {
+			kept[key] = name
+			continue
+		}
+		target := servers[keptName]
+		for _, ext := range server.Extensions {
+			if !slices.Contains(target.Extensions, ext) {
+				target.Extensions = append(slices.Clip(target.Extensions), ext)
+			}
+		}
+		servers[keptName] = target
+		delete(servers, name)
+		logging.Debug("Merged duplicate LSP configuration", "server", name, "into", keptName)
+	}
+}
@@ -126,6 +126,8 @@ func (c *Client) handleMessages() {
 			if cnf.DebugLSP {
 				logging.Error("Error reading message", "error", err)
 			}
+			// The loop ends here, so any read error leaves the client unusable
+			c.markTransportFailed(err)
 			return
 		}
 
@@ -242,6 +244,9 @@ func (c *Client) Call(ctx context.Context, method string, params any, result any
 
 	// Send request
 	if err := WriteMessage(c.stdin, msg); err != nil {
+		if isTransportError(err) {
+			c.markTransportFailed(err)
+		}
 		return fmt.Errorf("failed to send request: %w", err)
 	}
 
@@ -293,8 +298,15 @@ func (c *Client) Notify(ctx context.Context, method string, params any) error {
 	if err != nil {
 		return fmt.Errorf("failed to create notification: %w", err)
 	}
+	// The server exits after an exit notification, which is not a failure
+	if method == "exit" {
+		c.closing.Store(true)
+	}
 
 	if err := WriteMessage(c.stdin, msg); err != nil {
+		if isTransportError(err) {
+			c.markTransportFailed(err)
+		}
 		return fmt.Errorf("failed to send notification: %w", err)
 	}
 
@@ -0,0 +1,161 @@
+package message
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// CodeBlock is a fenced code block found in markdown text.
+type CodeBlock struct {
+	Lang     string
+	Content  string
+	Filename string
+}
+
+// ExtractCodeBlocks parses the fenced code blocks (``` or ~~~) in text.
+// A filename is taken from the info string (e.g. "go main.go", "go:main.go"
+// or "path/to/main.go") or from a leading "filename: main.go" hint line,
+// which is stripped from the content. Unclosed fences are ignored.
+func ExtractCodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	lines := strings.Split(text, "\n")
+
+	for i := 0; i < len(lines); i++ {
+		fence, info, ok := openingFence(lines[i])
+		if !ok {
+			continue
+		}
+
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if isClosingFence(lines[j], fence) {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			break
+		}
+
+		lang, filename := parseInfoString(info)
+		body := lines[i+1 : end]
+		if len(body) > 0 {
+			if hint, ok := filenameHint(body[0]); ok {
+				if filename == "" {
+					filename = hint
+				}
+				body = body[1:]
+			}
+		}
+
+		blocks = append(blocks, CodeBlock{
+			Lang:     lang,
+			Content:  strings.Join(body, "\n"),
+			Filename: filename,
+		})
+		i = end
+	}
+
+	return blocks
+}
+
+// CodeBlocks returns the fenced code blocks in the message's text content.
+func (m *Message) CodeBlocks() []CodeBlock {
+	return ExtractCodeBlocks(m.Content().Text)
+}
+
+// openingFence reports whether line opens a fence, returning the fence
+// marker and the trimmed info string.
+func openingFence(line string) (fence, info string, ok bool) {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 || len(trimmed) < 3 {
+		return "", "", false
+	}
+	ch := trimmed[0]
+	if ch != '`' && ch != '~' {
+		return "", "", false
+	}
+	n := 0
+	for n < len(trimmed) && trimmed[n] == ch {
+		n++
+	}
+	if n < 3 {
+		return "", "", false
+	}
+	info = strings.TrimSpace(trimmed[n:])
+	// Backtick fences may not contain backticks in the info string.
+	if ch == '`' && strings.Contains(info, "`") {
+		return "", "", false
+	}
+	return trimmed[:n], info, true
+}
+
+func isClosingFence(line, fence string) bool {
+	trimmed := strings.TrimLeft(line, " ")
+	if len(line)-len(trimmed) > 3 {
+		return false
+	}
+	trimmed = strings.TrimRight(trimmed, " \t\r")
+	if len(trimmed) < len(fence) {
+		return false
+	}
+	return strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// parseInfoString splits a fence info string into a language and an
+// optional filename.
+func parseInfoString(info string) (lang, filename string) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	first := fields[0]
+	if l, f, found := strings.Cut(first, ":"); found && f != "" {
+		return l, f
+	}
+	if looksLikePath(first) {
+		return strings.TrimPrefix(filepath.Ext(first), "."), first
+	}
+
+	lang = first
+	for _, field := range fields[1:] {
+		if v, found := strings.CutPrefix(field, "filename="); found {
+			return lang, strings.Trim(v, `"'`)
+		}
+		if v, found := strings.CutPrefix(field, "title="); found {
+			return lang, strings.Trim(v, `"'`)
+		}
+		if looksLikePath(field) {
+			return lang, field
+		}
+	}
+	return lang, ""
+}
+
+// filenameHint extracts the filename from a "filename: path" line, which may
+// be wrapped in a line comment.
+func filenameHint(line string) (string, bool) {
+	s := strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#", "--", ";", "<!--", "/*"} {
+		if rest, found := strings.CutPrefix(s, prefix); found {
+			s = strings.TrimSpace(rest)
+			break
+		}
+	}
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, "-->"), "*/"))
+
+	for _, key := range []string{"filename:", "file:", "path:"} {
+		if len(s) > len(key) && strings.EqualFold(s[:len(key)], key) {
+			name := strings.TrimSpace(s[len(key):])
+			if name != "" && !strings.ContainsAny(name, " \t") {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+func looksLikePath(s string) bool {
+	return strings.ContainsAny(s, "/\\") || filepath.Ext(s) != ""
+}
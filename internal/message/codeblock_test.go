@@ -0,0 +1,99 @@
+package message
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractCodeBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []CodeBlock
+	}{
+		{
+			name:     "no blocks",
+			text:     "just some prose",
+			expected: nil,
+		},
+		{
+			name: "multiple blocks",
+			text: "First:\n```go\nfunc main() {}\n```\nThen:\n~~~python\nprint(1)\nprint(2)\n~~~\n",
+			expected: []CodeBlock{
+				{Lang: "go", Content: "func main() {}"},
+				{Lang: "python", Content: "print(1)\nprint(2)"},
+			},
+		},
+		{
+			name: "filename hint line",
+			text: "```go\n// filename: cmd/main.go\npackage main\n```",
+			expected: []CodeBlock{
+				{Lang: "go", Content: "package main", Filename: "cmd/main.go"},
+			},
+		},
+		{
+			name: "filename in info string",
+			text: "```go internal/app.go\npackage app\n```",
+			expected: []CodeBlock{
+				{Lang: "go", Content: "package app", Filename: "internal/app.go"},
+			},
+		},
+		{
+			name: "lang and path joined by colon",
+			text: "```ts:src/index.ts\nexport {}\n```",
+			expected: []CodeBlock{
+				{Lang: "ts", Content: "export {}", Filename: "src/index.ts"},
+			},
+		},
+		{
+			name: "path only info string",
+			text: "```scripts/build.sh\necho hi\n```",
+			expected: []CodeBlock{
+				{Lang: "sh", Content: "echo hi", Filename: "scripts/build.sh"},
+			},
+		},
+		{
+			name: "longer fence contains shorter one",
+			text: "````md\n```go\nx := 1\n```\n````",
+			expected: []CodeBlock{
+				{Lang: "md", Content: "```go\nx := 1\n```"},
+			},
+		},
+		{
+			name: "unclosed fence is ignored",
+			text: "```go\nfunc a() {}\n```\n```python\nprint('never closed')",
+			expected: []CodeBlock{
+				{Lang: "go", Content: "func a() {}"},
+			},
+		},
+		{
+			name:     "only unclosed fence",
+			text:     "```go\npackage main",
+			expected: nil,
+		},
+		{
+			name: "empty block",
+			text: "```\n```",
+			expected: []CodeBlock{
+				{Content: ""},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractCodeBlocks(tt.text)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ExtractCodeBlocks() = %#v, want %#v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMessageCodeBlocks(t *testing.T) {
+	msg := newMessage(TextContent{Text: "```go\n// file: a.go\npackage a\n```"})
+	blocks := msg.CodeBlocks()
+	if len(blocks) != 1 || blocks[0].Filename != "a.go" || blocks[0].Content != "package a" {
+		t.Errorf("Message.CodeBlocks() = %#v, want one block for a.go", blocks)
+	}
+}
@@ -21,12 +21,14 @@ const (
 type FinishReason string
 
 const (
-	FinishReasonEndTurn          FinishReason = "end_turn"
-	FinishReasonMaxTokens        FinishReason = "max_tokens"
-	FinishReasonToolUse          FinishReason = "tool_use"
-	FinishReasonCanceled         FinishReason = "canceled"
-	FinishReasonError            FinishReason = "error"
-	FinishReasonPermissionDenied FinishReason = "permission_denied"
+	FinishReasonEndTurn           FinishReason = "end_turn"
+	FinishReasonMaxTokens         FinishReason = "max_tokens"
+	FinishReasonToolUse           FinishReason = "tool_use"
+	FinishReasonCanceled          FinishReason = "canceled"
+	FinishReasonError             FinishReason = "error"
+	FinishReasonPermissionDenied  FinishReason = "permission_denied"
+	FinishReasonBudgetExceeded    FinishReason = "budget_exceeded"
+	FinishReasonMaxToolIterations FinishReason = "max_tool_iterations"
 
 	// Should never happen
 	FinishReasonUnknown FinishReason = "unknown"
@@ -108,6 +110,8 @@ type ToolResult struct {
 	Content    string         `json:"content"`
 	Metadata   string         `json:"metadata"`
 	IsError    bool           `json:"is_error"`
+	// ErrorDetail is the structured form of the error for non-model consumers
+	ErrorDetail *tools.ToolErrorDetail `json:"error_detail,omitempty"`
 }
 
 func (r ToolResult) IsImageToolResponse() bool {
@@ -89,6 +89,8 @@ func TestConstants(t *testing.T) {
 		{name: "FinishReason/canceled", got: string(FinishReasonCanceled), want: "canceled"},
 		{name: "FinishReason/error", got: string(FinishReasonError), want: "error"},
 		{name: "FinishReason/permission_denied", got: string(FinishReasonPermissionDenied), want: "permission_denied"},
+		{name: "FinishReason/budget_exceeded", got: string(FinishReasonBudgetExceeded), want: "budget_exceeded"},
+		{name: "FinishReason/max_tool_iterations", got: string(FinishReasonMaxToolIterations), want: "max_tool_iterations"},
 		{name: "FinishReason/unknown", got: string(FinishReasonUnknown), want: "unknown"},
 
 		// ToolResultType constants
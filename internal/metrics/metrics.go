@@ -0,0 +1,181 @@
+// Package metrics collects local, per-session tool usage statistics. Nothing
+// is sent over the network; each session's metrics are written as JSON to the
+// metrics directory under the data directory.
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/MerrukTechnology/OpenCode-Native/internal/logging"
+)
+
+// ToolMetrics summarizes the runs of one tool in a session.
+type ToolMetrics struct {
+	Calls        int     `json:"calls"`
+	Errors       int     `json:"errors"`
+	ErrorRate    float64 `json:"error_rate"`
+	TotalLatency int64   `json:"total_latency_ms"`
+	AvgLatency   float64 `json:"avg_latency_ms"`
+}
+
+// Session summarizes the tool runs of a session.
+type Session struct {
+	SessionID string                 `json:"session_id"`
+	Calls     int                    `json:"calls"`
+	Errors    int                    `json:"errors"`
+	ErrorRate float64                `json:"error_rate"`
+	Tools     map[string]ToolMetrics `json:"tools"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+type toolStats struct {
+	calls  int
+	errors int
+	total  time.Duration
+}
+
+// Collector accumulates tool metrics per session.
+type Collector struct {
+	mu       sync.Mutex
+	dir      string
+	sessions map[string]map[string]*toolStats
+	updated  map[string]time.Time
+}
+
+// NewCollector creates a collector that writes each session's metrics to
+// dir/<session>.json after every recorded run. An empty dir keeps the metrics
+// in memory only.
+func NewCollector(dir string) *Collector {
+	return &Collector{
+		dir:      dir,
+		sessions: make(map[string]map[string]*toolStats),
+		updated:  make(map[string]time.Time),
+	}
+}
+
+// Record adds one run of toolName to the metrics of sessionID.
+func (c *Collector) Record(sessionID, toolName string, duration time.Duration, isError bool) {
+	if sessionID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	tools, ok := c.sessions[sessionID]
+	if !ok {
+		tools = make(map[string]*toolStats)
+		c.sessions[sessionID] = tools
+	}
+	stats, ok := tools[toolName]
+	if !ok {
+		stats = &toolStats{}
+		tools[toolName] = stats
+	}
+	stats.calls++
+	if isError {
+		stats.errors++
+	}
+	stats.total += duration
+	c.updated[sessionID] = time.Now()
+	snapshot := c.snapshot(sessionID)
+	c.mu.Unlock()
+
+	if c.dir != "" {
+		if err := c.write(snapshot); err != nil {
+			logging.Debug("Failed to write tool metrics", "session", sessionID, "error", err)
+		}
+	}
+}
+
+// SessionMetrics returns the metrics recorded for sessionID.
+func (c *Collector) SessionMetrics(sessionID string) Session {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshot(sessionID)
+}
+
+func (c *Collector) snapshot(sessionID string) Session {
+	m := Session{
+		SessionID: sessionID,
+		Tools:     make(map[string]ToolMetrics),
+		UpdatedAt: c.updated[sessionID],
+	}
+	for name, stats := range c.sessions[sessionID] {
+		tm := ToolMetrics{
+			Calls:        stats.calls,
+			Errors:       stats.errors,
+			TotalLatency: stats.total.Milliseconds(),
+		}
+		if stats.calls > 0 {
+			tm.ErrorRate = float64(stats.errors) / float64(stats.calls)
+			tm.AvgLatency = float64(stats.total.Microseconds()) / 1000 / float64(stats.calls)
+		}
+		m.Tools[name] = tm
+		m.Calls += stats.calls
+		m.Errors += stats.errors
+	}
+	if m.Calls > 0 {
+		m.ErrorRate = float64(m.Errors) / float64(m.Calls)
+	}
+	return m
+}
+
+func (c *Collector) write(m Session) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, filepath.Base(m.SessionID)+".json"), data, 0o644)
+}
+
+var (
+	defaultCollector     *Collector
+	defaultCollectorOnce sync.Once
+)
+
+// Default returns the process-wide collector, which writes to the metrics
+// directory inside the configured data directory.
+func Default() *Collector {
+	defaultCollectorOnce.Do(func() {
+		defaultCollector = NewCollector(metricsDir(config.Get()))
+	})
+	return defaultCollector
+}
+
+// metricsDir returns the metrics directory inside the data directory of cfg,
+// or "" when none is configured. A relative data directory is resolved
+// against the working directory, not the process's.
+func metricsDir(cfg *config.Config) string {
+	if cfg == nil || cfg.Data.Directory == "" {
+		return ""
+	}
+	dir := filepath.Join(cfg.Data.Directory, "metrics")
+	if !filepath.IsAbs(dir) && cfg.WorkingDir != "" {
+		dir = filepath.Join(cfg.WorkingDir, dir)
+	}
+	return dir
+}
+
+// SetDefault replaces the process-wide collector, so tests can keep metrics
+// in memory or in a temporary directory.
+func SetDefault(c *Collector) {
+	defaultCollectorOnce.Do(func() {})
+	defaultCollector = c
+}
+
+// Record adds one tool run to the default collector.
+func Record(sessionID, toolName string, duration time.Duration, isError bool) {
+	Default().Record(sessionID, toolName, duration, isError)
+}
+
+// SessionMetrics returns the metrics of sessionID from the default collector.
+func SessionMetrics(sessionID string) Session {
+	return Default().SessionMetrics(sessionID)
+}
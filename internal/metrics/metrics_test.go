@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector_CountsAndErrorRates(t *testing.T) {
+	dir := t.TempDir()
+	c := NewCollector(dir)
+
+	c.Record("s1", "view", 10*time.Millisecond, false)
+	c.Record("s1", "view", 30*time.Millisecond, false)
+	c.Record("s1", "edit", 20*time.Millisecond, true)
+	c.Record("s1", "edit", 40*time.Millisecond, false)
+	c.Record("s1", "edit", 60*time.Millisecond, true)
+	c.Record("s2", "bash", 5*time.Millisecond, true)
+
+	m := c.SessionMetrics("s1")
+	assert.Equal(t, "s1", m.SessionID)
+	assert.Equal(t, 5, m.Calls)
+	assert.Equal(t, 2, m.Errors)
+	assert.InDelta(t, 0.4, m.ErrorRate, 1e-9)
+
+	view := m.Tools["view"]
+	assert.Equal(t, 2, view.Calls)
+	assert.Equal(t, 0, view.Errors)
+	assert.Zero(t, view.ErrorRate)
+	assert.InDelta(t, 20.0, view.AvgLatency, 1e-9)
+
+	edit := m.Tools["edit"]
+	assert.Equal(t, 3, edit.Calls)
+	assert.Equal(t, 2, edit.Errors)
+	assert.InDelta(t, 2.0/3.0, edit.ErrorRate, 1e-9)
+	assert.Equal(t, int64(120), edit.TotalLatency)
+	assert.InDelta(t, 40.0, edit.AvgLatency, 1e-9)
+
+	assert.NotContains(t, m.Tools, "bash", "metrics are kept per session")
+
+	data, err := os.ReadFile(filepath.Join(dir, "s1.json"))
+	require.NoError(t, err)
+	var written Session
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, 5, written.Calls)
+	assert.Equal(t, 3, written.Tools["edit"].Calls)
+}
+
+func TestCollector_UnknownSession(t *testing.T) {
+	c := NewCollector("")
+	c.Record("", "view", time.Millisecond, false)
+
+	m := c.SessionMetrics("missing")
+	assert.Zero(t, m.Calls)
+	assert.Zero(t, m.ErrorRate)
+	assert.Empty(t, m.Tools)
+}
+
+func TestMetricsDir(t *testing.T) {
+	workingDir := t.TempDir()
+	absData := t.TempDir()
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want string
+	}{
+		{"no config", nil, ""},
+		{"no data directory", &config.Config{WorkingDir: workingDir}, ""},
+		{"relative data directory", &config.Config{WorkingDir: workingDir, Data: config.Data{Directory: ".opencode"}}, filepath.Join(workingDir, ".opencode", "metrics")},
+		{"absolute data directory", &config.Config{WorkingDir: workingDir, Data: config.Data{Directory: absData}}, filepath.Join(absData, "metrics")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, metricsDir(tt.cfg))
+		})
+	}
+}
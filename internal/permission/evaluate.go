@@ -1,6 +1,7 @@
 package permission
 
 import (
+	"path/filepath"
 	"strings"
 )
 
@@ -48,6 +49,49 @@ func EvaluateToolPermission(toolName, input string, agentPerms, globalPerms map[
 	return ActionAsk
 }
 
+// IsAutoAllowedPath reports whether path matches one of the autoAllowPaths
+// patterns. Relative paths and patterns are resolved against workingDir;
+// patterns use the same wildcards as permission rules. Symlinks are resolved
+// first, so a link inside an allowed directory does not allow its target.
+func IsAutoAllowedPath(path, workingDir string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workingDir, path)
+	}
+	path = resolveSymlinks(path)
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(workingDir, pattern)
+		}
+		pattern = resolveSymlinks(pattern)
+		if MatchWildcard(filepath.ToSlash(pattern), filepath.ToSlash(path)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSymlinks resolves the symlinks of the longest existing prefix of
+// path, which may name a file yet to be created or hold wildcards, and joins
+// the rest of path back onto it.
+func resolveSymlinks(path string) string {
+	path = filepath.Clean(path)
+	dir, rest := path, ""
+	for {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(resolved, rest)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return path
+		}
+		rest = filepath.Join(filepath.Base(dir), rest)
+		dir = parent
+	}
+}
+
 func IsToolEnabled(toolName string, toolsConfig map[string]bool) bool {
 	if toolsConfig == nil {
 		return true
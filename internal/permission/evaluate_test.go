@@ -1,6 +1,10 @@
 package permission
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestMatchWildcard(t *testing.T) {
 	tests := []struct {
@@ -157,3 +161,59 @@ func TestIsToolEnabled(t *testing.T) {
 		})
 	}
 }
+
+func TestIsAutoAllowedPath(t *testing.T) {
+	patterns := []string{"tmp/**", "build/*", "/var/scratch/*"}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/work/tmp/a.txt", true},
+		{"/work/tmp/nested/dir/a.txt", true},
+		{"tmp/a.txt", true},
+		{"/work/build/out.bin", true},
+		{"/work/src/main.go", false},
+		{"/work/tmpfile", false},
+		{"/work/src/tmp/a.txt", false},
+		{"/var/scratch/x", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := IsAutoAllowedPath(tt.path, "/work", patterns); got != tt.want {
+				t.Errorf("IsAutoAllowedPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+	if IsAutoAllowedPath("/work/tmp/a.txt", "/work", nil) {
+		t.Error("IsAutoAllowedPath without patterns allowed a path")
+	}
+}
+
+func TestIsAutoAllowedPath_Symlinks(t *testing.T) {
+	root := t.TempDir()
+	work := filepath.Join(root, "work")
+	outside := filepath.Join(root, "outside")
+	for _, dir := range []string{filepath.Join(work, "tmp"), outside} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(work, "tmp", "escape")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	linkedWork := filepath.Join(root, "linked-work")
+	if err := os.Symlink(work, linkedWork); err != nil {
+		t.Fatal(err)
+	}
+	patterns := []string{"tmp/**"}
+
+	if IsAutoAllowedPath(filepath.Join(work, "tmp", "escape", "new.txt"), work, patterns) {
+		t.Error("a path through a symlink leaving the allowed directory was allowed")
+	}
+	if !IsAutoAllowedPath(filepath.Join(work, "tmp", "new", "a.txt"), work, patterns) {
+		t.Error("a file yet to be created in the allowed directory was refused")
+	}
+	if !IsAutoAllowedPath(filepath.Join(work, "tmp", "a.txt"), linkedWork, patterns) {
+		t.Error("a path was refused when the working directory is reached through a symlink")
+	}
+}
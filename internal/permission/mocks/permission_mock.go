@@ -104,6 +104,20 @@ func (mr *MockServiceMockRecorder) IsAutoApproveSession(sessionID any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAutoApproveSession", reflect.TypeOf((*MockService)(nil).IsAutoApproveSession), sessionID)
 }
 
+// ListSessionGrants mocks base method.
+func (m *MockService) ListSessionGrants(sessionID string) []permission.Grant {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSessionGrants", sessionID)
+	ret0, _ := ret[0].([]permission.Grant)
+	return ret0
+}
+
+// ListSessionGrants indicates an expected call of ListSessionGrants.
+func (mr *MockServiceMockRecorder) ListSessionGrants(sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSessionGrants", reflect.TypeOf((*MockService)(nil).ListSessionGrants), sessionID)
+}
+
 // Request mocks base method.
 func (m *MockService) Request(opts permission.CreatePermissionRequest) bool {
 	m.ctrl.T.Helper()
@@ -118,6 +132,30 @@ func (mr *MockServiceMockRecorder) Request(opts any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockService)(nil).Request), opts)
 }
 
+// RevokeAll mocks base method.
+func (m *MockService) RevokeAll(sessionID string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeAll", sessionID)
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MockServiceMockRecorder) RevokeAll(sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockService)(nil).RevokeAll), sessionID)
+}
+
+// RevokeSessionGrant mocks base method.
+func (m *MockService) RevokeSessionGrant(sessionID, tool, pattern string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RevokeSessionGrant", sessionID, tool, pattern)
+}
+
+// RevokeSessionGrant indicates an expected call of RevokeSessionGrant.
+func (mr *MockServiceMockRecorder) RevokeSessionGrant(sessionID, tool, pattern any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessionGrant", reflect.TypeOf((*MockService)(nil).RevokeSessionGrant), sessionID, tool, pattern)
+}
+
 // SubscribeWithContext mocks base method.
 func (m *MockService) SubscribeWithContext(arg0 context.Context) <-chan pubsub.Event[permission.PermissionRequest] {
 	m.ctrl.T.Helper()
@@ -3,7 +3,9 @@ package permission
 import (
 	"errors"
 	"path/filepath"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 	"github.com/MerrukTechnology/OpenCode-Native/internal/pubsub"
@@ -31,6 +33,20 @@ type PermissionRequest struct {
 	Path        string `json:"path"`
 }
 
+// Grant is a permission the user allowed for the rest of a session. Pattern is
+// the directory the grant covers.
+type Grant struct {
+	Tool      string    `json:"tool"`
+	Action    string    `json:"action"`
+	Pattern   string    `json:"pattern"`
+	GrantedAt time.Time `json:"granted_at"`
+}
+
+type sessionGrant struct {
+	PermissionRequest
+	grantedAt time.Time
+}
+
 type Service interface {
 	pubsub.Subscriber[PermissionRequest]
 	GrantPersistant(permission PermissionRequest)
@@ -39,14 +55,19 @@ type Service interface {
 	Request(opts CreatePermissionRequest) bool
 	AutoApproveSession(sessionID string)
 	IsAutoApproveSession(sessionID string) bool
+	ListSessionGrants(sessionID string) []Grant
+	RevokeSessionGrant(sessionID, tool, pattern string)
+	RevokeAll(sessionID string)
 }
 
 type permissionService struct {
 	*pubsub.Broker[PermissionRequest]
 
-	sessionPermissions  []PermissionRequest
-	pendingRequests     sync.Map
-	autoApproveSessions sync.Map
+	// Tool calls may request permissions concurrently
+	sessionPermissionsMu sync.RWMutex
+	sessionPermissions   []sessionGrant
+	pendingRequests      sync.Map
+	autoApproveSessions  sync.Map
 }
 
 func (s *permissionService) GrantPersistant(permission PermissionRequest) {
@@ -54,7 +75,9 @@ func (s *permissionService) GrantPersistant(permission PermissionRequest) {
 	if ok {
 		respCh.(chan bool) <- true
 	}
-	s.sessionPermissions = append(s.sessionPermissions, permission)
+	s.sessionPermissionsMu.Lock()
+	s.sessionPermissions = append(s.sessionPermissions, sessionGrant{PermissionRequest: permission, grantedAt: time.Now()})
+	s.sessionPermissionsMu.Unlock()
 }
 
 func (s *permissionService) Grant(permission PermissionRequest) {
@@ -89,11 +112,14 @@ func (s *permissionService) Request(opts CreatePermissionRequest) bool {
 		Params:      opts.Params,
 	}
 
+	s.sessionPermissionsMu.RLock()
 	for _, p := range s.sessionPermissions {
 		if p.ToolName == permission.ToolName && p.Action == permission.Action && p.SessionID == permission.SessionID && p.Path == permission.Path {
+			s.sessionPermissionsMu.RUnlock()
 			return true
 		}
 	}
+	s.sessionPermissionsMu.RUnlock()
 
 	respCh := make(chan bool, 1)
 
@@ -116,9 +142,51 @@ func (s *permissionService) IsAutoApproveSession(sessionID string) bool {
 	return ok
 }
 
+// ListSessionGrants returns the permissions granted for the rest of the
+// session, oldest first.
+func (s *permissionService) ListSessionGrants(sessionID string) []Grant {
+	s.sessionPermissionsMu.RLock()
+	defer s.sessionPermissionsMu.RUnlock()
+	var grants []Grant
+	for _, p := range s.sessionPermissions {
+		if p.SessionID == sessionID {
+			grants = append(grants, Grant{
+				Tool:      p.ToolName,
+				Action:    p.Action,
+				Pattern:   p.Path,
+				GrantedAt: p.grantedAt,
+			})
+		}
+	}
+	return grants
+}
+
+// RevokeSessionGrant removes the session grants for tool on pattern, so the
+// next matching request prompts the user again.
+func (s *permissionService) RevokeSessionGrant(sessionID, tool, pattern string) {
+	s.revoke(func(p sessionGrant) bool {
+		return p.SessionID == sessionID && p.ToolName == tool && p.Path == pattern
+	})
+}
+
+// RevokeAll removes every grant of the session, including auto-approval of
+// all its requests.
+func (s *permissionService) RevokeAll(sessionID string) {
+	s.autoApproveSessions.Delete(sessionID)
+	s.revoke(func(p sessionGrant) bool {
+		return p.SessionID == sessionID
+	})
+}
+
+func (s *permissionService) revoke(match func(sessionGrant) bool) {
+	s.sessionPermissionsMu.Lock()
+	s.sessionPermissions = slices.DeleteFunc(s.sessionPermissions, match)
+	s.sessionPermissionsMu.Unlock()
+}
+
 func NewPermissionService() Service {
 	return &permissionService{
 		Broker:             pubsub.NewBroker[PermissionRequest](),
-		sessionPermissions: make([]PermissionRequest, 0),
+		sessionPermissions: make([]sessionGrant, 0),
 	}
 }
@@ -0,0 +1,78 @@
+package permission
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionGrants_ListAndRevoke(t *testing.T) {
+	s := NewPermissionService()
+	events, unsubscribe := s.(*permissionService).Subscribe()
+	defer unsubscribe()
+
+	s.GrantPersistant(PermissionRequest{SessionID: "s1", ToolName: "edit", Action: "write", Path: "/project/src"})
+	s.GrantPersistant(PermissionRequest{SessionID: "s1", ToolName: "bash", Action: "execute", Path: "/project"})
+	s.GrantPersistant(PermissionRequest{SessionID: "s2", ToolName: "edit", Action: "write", Path: "/project/src"})
+
+	grants := s.ListSessionGrants("s1")
+	if len(grants) != 2 {
+		t.Fatalf("ListSessionGrants = %d grants, want 2", len(grants))
+	}
+	if grants[0].Tool != "edit" || grants[0].Pattern != "/project/src" || grants[1].Tool != "bash" {
+		t.Errorf("ListSessionGrants = %+v, want edit then bash", grants)
+	}
+	if grants[0].GrantedAt.IsZero() {
+		t.Error("GrantedAt is not set")
+	}
+
+	s.RevokeSessionGrant("s1", "edit", "/project/src")
+
+	grants = s.ListSessionGrants("s1")
+	if len(grants) != 1 || grants[0].Tool != "bash" {
+		t.Fatalf("after revoke ListSessionGrants = %+v, want only bash", grants)
+	}
+	if got := s.ListSessionGrants("s2"); len(got) != 1 {
+		t.Errorf("revoke affected another session: %+v", got)
+	}
+
+	if !s.Request(CreatePermissionRequest{SessionID: "s1", ToolName: "bash", Action: "execute", Path: "/project/run.sh"}) {
+		t.Error("bash request was not allowed by its remaining grant")
+	}
+
+	result := make(chan bool, 1)
+	go func() {
+		result <- s.Request(CreatePermissionRequest{SessionID: "s1", ToolName: "edit", Action: "write", Path: "/project/src/main.go"})
+	}()
+	select {
+	case event := <-events:
+		if event.Payload.ToolName != "edit" {
+			t.Fatalf("prompted for %q, want edit", event.Payload.ToolName)
+		}
+		s.Deny(event.Payload)
+	case <-time.After(time.Second):
+		t.Fatal("revoked edit request did not prompt again")
+	}
+	if <-result {
+		t.Error("denied edit request was allowed")
+	}
+
+	s.RevokeAll("s1")
+	if got := s.ListSessionGrants("s1"); len(got) != 0 {
+		t.Errorf("after RevokeAll ListSessionGrants = %+v, want none", got)
+	}
+}
+
+func TestRevokeAll_EndsAutoApproval(t *testing.T) {
+	s := NewPermissionService()
+	s.AutoApproveSession("s1")
+	s.AutoApproveSession("s2")
+
+	s.RevokeAll("s1")
+
+	if s.IsAutoApproveSession("s1") {
+		t.Error("s1 is still auto-approved after RevokeAll")
+	}
+	if !s.IsAutoApproveSession("s2") {
+		t.Error("RevokeAll ended auto-approval of another session")
+	}
+}
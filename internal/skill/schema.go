@@ -0,0 +1,56 @@
+package skill
+
+import "encoding/json"
+
+// FrontmatterSchema returns a JSON Schema for the SKILL.md frontmatter so
+// editors can validate and autocomplete it. The constraints mirror
+// validateFrontmatter.
+func FrontmatterSchema() []byte {
+	schema := map[string]any{
+		"$schema":     "http://json-schema.org/draft-07/schema#",
+		"title":       "OpenCode Skill Frontmatter",
+		"description": "YAML frontmatter of a SKILL.md file",
+		"type":        "object",
+		"properties": map[string]any{
+			"name": map[string]any{
+				"type":        "string",
+				"description": "Skill name; must match the name of the directory containing SKILL.md",
+				"pattern":     nameRegex.String(),
+				"minLength":   1,
+				"maxLength":   maxNameLength,
+			},
+			"description": map[string]any{
+				"type":        "string",
+				"description": "What the skill does and when the agent should use it",
+				"minLength":   1,
+				"maxLength":   maxDescriptionLength,
+			},
+			"license": map[string]any{
+				"type":        "string",
+				"description": "License of the skill",
+			},
+			"compatibility": map[string]any{
+				"type":        "string",
+				"description": "Compatibility marker, e.g. opencode",
+			},
+			"allowed-directories": map[string]any{
+				"type":        "array",
+				"description": "Glob patterns, relative to the working directory, for the paths file-writing tools may change once the skill is loaded, e.g. infra/**",
+				"items": map[string]any{
+					"type":      "string",
+					"minLength": 1,
+				},
+			},
+			"metadata": map[string]any{
+				"type":                 "object",
+				"description":          "Arbitrary key-value metadata",
+				"additionalProperties": true,
+			},
+		},
+		"required": []string{"name", "description"},
+	}
+
+	// A map of plain values always marshals successfully.
+	data, _ := json.MarshalIndent(schema, "", "  ")
+	return data
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 
@@ -39,8 +41,12 @@ type Info struct {
 	License       string         `yaml:"license,omitempty"`
 	Compatibility string         `yaml:"compatibility,omitempty"`
 	Metadata      map[string]any `yaml:"metadata,omitempty"`
-	Location      string         `yaml:"-"` // File path, not in frontmatter
-	Content       string         `yaml:"-"` // Markdown content, not in frontmatter
+	// AllowedDirectories are glob patterns, relative to the working
+	// directory, for the paths a turn that loaded the skill may write to.
+	// Empty means the skill adds no restriction.
+	AllowedDirectories []string `yaml:"allowed-directories,omitempty"`
+	Location           string   `yaml:"-"` // File path, not in frontmatter
+	Content            string   `yaml:"-"` // Markdown content, not in frontmatter
 }
 
 // Error types
@@ -51,6 +57,7 @@ var (
 	ErrNameMismatch       = errors.New("skill name does not match directory name")
 	ErrInvalidFrontmatter = errors.New("invalid skill frontmatter")
 	ErrContentTooLarge    = errors.New("skill content exceeds maximum size")
+	ErrInvalidAllowedDirs = errors.New("invalid skill allowed-directories")
 )
 
 // SkillError wraps an error with additional context.
@@ -81,13 +88,14 @@ func Get(name string) (*Info, error) {
 	return &skill, nil
 }
 
-// All returns all available skills.
+// All returns all available skills sorted by name.
 func All() []Info {
 	skills := state()
 	result := make([]Info, 0, len(skills))
 	for _, skill := range skills {
 		result = append(result, skill)
 	}
+	slices.SortFunc(result, func(a, b Info) int { return strings.Compare(a.Name, b.Name) })
 	return result
 }
 
@@ -102,6 +110,11 @@ func state() map[string]Info {
 	return skillCache
 }
 
+func init() {
+	// Project skills are discovered relative to the working directory
+	config.OnWorkingDirectoryChange(func(string) { Invalidate() })
+}
+
 // Invalidate clears the skill cache, forcing rediscovery on next access.
 func Invalidate() {
 	skillCacheLock.Lock()
@@ -126,8 +139,8 @@ func discoverSkills() map[string]Info {
 		return skills
 	}
 
-	// Get git worktree root
-	worktreeRoot := getWorktreeRoot(workingDir)
+	// Get the project root, marked by .git or the configured worktreeMarkers
+	worktreeRoot := config.WorktreeRoot(workingDir)
 
 	// Discover project-level skills (walk up from working dir to worktree)
 	projectSkills := discoverProjectSkills(workingDir, worktreeRoot)
@@ -196,7 +209,7 @@ func discoverProjectSkills(workingDir, worktreeRoot string) []Info {
 		current = filepath.Dir(current)
 	}
 
-	return skills
+	return dedupeSkills(skills)
 }
 
 // discoverGlobalSkills scans global skill directories.
@@ -226,24 +239,15 @@ func discoverGlobalSkills() []Info {
 		skills = append(skills, claudeSkills...)
 	}
 
-	return skills
+	return dedupeSkills(skills)
 }
 
 // discoverCustomPaths scans custom skill paths from config.
 func discoverCustomPaths(paths []string, workingDir string) []Info {
 	var skills []Info
 
-	homeDir, _ := os.UserHomeDir()
-
 	for _, skillPath := range paths {
-		// Expand ~ to home directory
-		expanded := skillPath
-		if strings.HasPrefix(skillPath, "~/") && homeDir != "" {
-			expanded = filepath.Join(homeDir, skillPath[2:])
-		}
-
-		// Resolve relative paths
-		resolved := fileutil.ResolvePath(expanded, workingDir)
+		resolved := fileutil.ResolvePath(fileutil.ExpandHome(skillPath), workingDir)
 
 		// Check if directory exists
 		if info, err := os.Stat(resolved); err != nil || !info.IsDir() {
@@ -256,7 +260,36 @@ func discoverCustomPaths(paths []string, workingDir string) []Info {
 		skills = append(skills, pathSkills...)
 	}
 
-	return skills
+	return dedupeSkills(skills)
+}
+
+// dedupeSkills drops skills whose file was already found through another
+// path, such as overlapping custom paths or a symlinked directory. The first
+// occurrence is kept, so the order of precedence is unchanged.
+func dedupeSkills(skills []Info) []Info {
+	seen := make(map[string]bool, len(skills))
+	result := make([]Info, 0, len(skills))
+	for _, skill := range skills {
+		location := canonicalLocation(skill.Location)
+		if seen[location] {
+			continue
+		}
+		seen[location] = true
+		result = append(result, skill)
+	}
+	return result
+}
+
+// canonicalLocation resolves path to an absolute path without symlinks, or
+// returns it cleaned when it cannot be resolved.
+func canonicalLocation(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
 }
 
 // scanDirectory scans a directory for SKILL.md files matching the pattern.
@@ -379,6 +412,11 @@ func validateFrontmatter(skill *Info) error {
 		return err
 	}
 
+	// Validate allowed directories
+	if err := validateAllowedDirectories(skill.AllowedDirectories); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -412,23 +450,61 @@ func validateDescription(description string) error {
 	return nil
 }
 
-// getWorktreeRoot returns the git worktree root, or the working directory if not in a git repo.
-func getWorktreeRoot(workingDir string) string {
-	// Try to find .git directory by walking up
-	current := workingDir
-	for {
-		gitDir := filepath.Join(current, ".git")
-		if _, err := os.Stat(gitDir); err == nil {
-			return current
+// validateAllowedDirectories validates the allowed-directories patterns.
+func validateAllowedDirectories(patterns []string) error {
+	for _, pattern := range patterns {
+		clean := cleanDirPattern(pattern)
+		switch {
+		case clean == "":
+			return fmt.Errorf("%w: pattern must not be empty", ErrInvalidAllowedDirs)
+		case filepath.IsAbs(pattern) || strings.HasPrefix(clean, "/"):
+			return fmt.Errorf("%w: %q must be relative to the working directory", ErrInvalidAllowedDirs, pattern)
+		case clean == ".." || strings.HasPrefix(clean, "../"):
+			return fmt.Errorf("%w: %q must not leave the working directory", ErrInvalidAllowedDirs, pattern)
+		case !doublestar.ValidatePattern(clean):
+			return fmt.Errorf("%w: %q is not a valid glob pattern", ErrInvalidAllowedDirs, pattern)
 		}
+	}
+	return nil
+}
 
-		parent := filepath.Dir(current)
-		if parent == current {
-			// Reached filesystem root
-			return workingDir
+// cleanDirPattern normalizes an allowed-directories pattern to a slash
+// separated pattern without a leading ./ or trailing slash.
+func cleanDirPattern(pattern string) string {
+	pattern = strings.TrimSpace(filepath.ToSlash(pattern))
+	if pattern == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path.Clean(pattern), "/")
+}
+
+// AllowsPath reports whether a turn that loaded the skill may write to the
+// file p. It is true for any path inside workingDir when the skill has no
+// allowed-directories. Otherwise p must be inside workingDir and match one
+// of the patterns, either itself or through a parent directory, so "infra"
+// and "infra/**" both allow infra/main.tf.
+func (s *Info) AllowsPath(p, workingDir string) bool {
+	rel, err := filepath.Rel(workingDir, p)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return false
+	}
+	if len(s.AllowedDirectories) == 0 {
+		return true
+	}
+	for _, pattern := range s.AllowedDirectories {
+		pattern = cleanDirPattern(pattern)
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return true
+		}
+		if ok, _ := doublestar.Match(pattern+"/**", rel); ok {
+			return true
 		}
-		current = parent
 	}
+	return false
 }
 
 // isClaudeSkillsDisabled checks if Claude skills discovery is disabled.
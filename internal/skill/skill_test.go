@@ -1,10 +1,15 @@
 package skill
 
 import (
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"testing"
+
+	"github.com/MerrukTechnology/OpenCode-Native/internal/config"
 )
 
 func TestValidateName(t *testing.T) {
@@ -225,55 +230,6 @@ Content here
 	}
 }
 
-func TestGetWorktreeRoot(t *testing.T) {
-	// Create temporary directory structure
-	tmpDir := t.TempDir()
-
-	// Create nested directories
-	gitDir := filepath.Join(tmpDir, "project")
-	subDir := filepath.Join(gitDir, "src", "pkg")
-	if err := os.MkdirAll(subDir, 0o755); err != nil {
-		t.Fatal(err)
-	}
-
-	// Create .git directory
-	dotGit := filepath.Join(gitDir, ".git")
-	if err := os.MkdirAll(dotGit, 0o755); err != nil {
-		t.Fatal(err)
-	}
-
-	tests := []struct {
-		name       string
-		workingDir string
-		want       string
-	}{
-		{
-			name:       "from git root",
-			workingDir: gitDir,
-			want:       gitDir,
-		},
-		{
-			name:       "from subdirectory",
-			workingDir: subDir,
-			want:       gitDir,
-		},
-		{
-			name:       "not in git repo",
-			workingDir: tmpDir,
-			want:       tmpDir,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := getWorktreeRoot(tt.workingDir)
-			if got != tt.want {
-				t.Errorf("getWorktreeRoot() = %q, want %q", got, tt.want)
-			}
-		})
-	}
-}
-
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 && (s == substr || len(s) > len(substr) &&
 		(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
@@ -333,7 +289,7 @@ Sub content`
 	}
 
 	// Discover from subdirectory - should find both
-	worktreeRoot := getWorktreeRoot(subDir)
+	worktreeRoot := config.WorktreeRoot(subDir)
 	skills := discoverProjectSkills(subDir, worktreeRoot)
 
 	if len(skills) != 2 {
@@ -742,6 +698,42 @@ func TestValidateFrontmatter(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "allowed directories",
+			skill: &Info{
+				Name:               "infra-ops",
+				Description:        "Has description",
+				AllowedDirectories: []string{"infra/**", "./deploy/", "docs/*.md"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "absolute allowed directory",
+			skill: &Info{
+				Name:               "infra-ops",
+				Description:        "Has description",
+				AllowedDirectories: []string{"/etc"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "allowed directory outside the working directory",
+			skill: &Info{
+				Name:               "infra-ops",
+				Description:        "Has description",
+				AllowedDirectories: []string{"../other/**"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid allowed directory pattern",
+			skill: &Info{
+				Name:               "infra-ops",
+				Description:        "Has description",
+				AllowedDirectories: []string{"infra/[a"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -841,3 +833,142 @@ func TestValidateDescription_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestFrontmatterSchema(t *testing.T) {
+	var schema struct {
+		Required   []string                  `json:"required"`
+		Properties map[string]map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal(FrontmatterSchema(), &schema); err != nil {
+		t.Fatalf("FrontmatterSchema() is not valid JSON: %v", err)
+	}
+
+	for _, field := range []string{"name", "description"} {
+		if !slices.Contains(schema.Required, field) {
+			t.Errorf("required = %v, want it to contain %q", schema.Required, field)
+		}
+	}
+	for _, field := range []string{"name", "description", "license", "compatibility", "metadata"} {
+		if _, ok := schema.Properties[field]; !ok {
+			t.Errorf("schema has no property %q", field)
+		}
+	}
+
+	name := schema.Properties["name"]
+	pattern, _ := name["pattern"].(string)
+	if pattern != nameRegex.String() {
+		t.Errorf("name pattern = %q, want %q", pattern, nameRegex.String())
+	}
+	if maxLength, _ := name["maxLength"].(float64); int(maxLength) != maxNameLength {
+		t.Errorf("name maxLength = %v, want %d", name["maxLength"], maxNameLength)
+	}
+
+	// The schema pattern must accept and reject the same names as validateName.
+	re := regexp.MustCompile(pattern)
+	for _, input := range []string{"git-release", "skill-123", "Git-Release", "-skill", "skill--name", "skill_name"} {
+		if got, want := re.MatchString(input), validateName(input) == nil; got != want {
+			t.Errorf("schema pattern match for %q = %v, validateName accepts = %v", input, got, want)
+		}
+	}
+}
+
+func writeTestSkill(t *testing.T, dir string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	name := filepath.Base(dir)
+	content := "---\nname: " + name + "\ndescription: Test skill " + name + "\n---\nContent"
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscoverCustomPaths_Overlapping(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestSkill(t, filepath.Join(tmpDir, "shared", "common-skill"))
+	if err := os.Symlink(filepath.Join(tmpDir, "shared"), filepath.Join(tmpDir, "shared-link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	paths := []string{"shared", filepath.Join(tmpDir, "shared"), "./shared/", "shared-link", "."}
+	skills := discoverCustomPaths(paths, tmpDir)
+
+	if len(skills) != 1 {
+		t.Fatalf("Expected the skill once, got %d: %v", len(skills), skills)
+	}
+	if skills[0].Location != filepath.Join(tmpDir, "shared", "common-skill", "SKILL.md") {
+		t.Errorf("Expected the first path to win, got %s", skills[0].Location)
+	}
+}
+
+func TestAll_SortedByName(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "xdg"))
+
+	workDir := filepath.Join(tmpDir, "project")
+	if err := os.MkdirAll(filepath.Join(workDir, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"zeta-skill", "alpha-skill", "mid-skill"} {
+		writeTestSkill(t, filepath.Join(workDir, ".opencode", "skills", name))
+	}
+	writeTestSkill(t, filepath.Join(workDir, "extra", "beta-skill"))
+	configJSON := `{"skills": {"paths": ["extra", "./extra", ".opencode/skills"]}}`
+	if err := os.WriteFile(filepath.Join(workDir, ".opencode.json"), []byte(configJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config.Reset()
+	if _, err := config.Load(workDir, false); err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	Invalidate()
+	t.Cleanup(func() {
+		config.Reset()
+		Invalidate()
+	})
+
+	var names []string
+	for _, skill := range All() {
+		names = append(names, skill.Name)
+	}
+	want := []string{"alpha-skill", "beta-skill", "mid-skill", "zeta-skill"}
+	if !slices.Equal(names, want) {
+		t.Errorf("All() names = %v, want %v", names, want)
+	}
+}
+
+func TestInfoAllowsPath(t *testing.T) {
+	workingDir := filepath.Join(t.TempDir(), "repo")
+	scoped := &Info{AllowedDirectories: []string{"infra/**", "deploy", "docs/*.md"}}
+	unscoped := &Info{}
+
+	tests := []struct {
+		name  string
+		skill *Info
+		path  string
+		want  bool
+	}{
+		{"file under a recursive pattern", scoped, "infra/main.tf", true},
+		{"nested file under a recursive pattern", scoped, "infra/modules/vpc/main.tf", true},
+		{"file under a plain directory", scoped, "deploy/prod/values.yaml", true},
+		{"file matching a file pattern", scoped, "docs/infra.md", true},
+		{"file outside the patterns", scoped, "src/app.go", false},
+		{"sibling with a common prefix", scoped, "infrastructure/main.tf", false},
+		{"file not matching a file pattern", scoped, "docs/guide/intro.md", false},
+		{"outside the working directory", scoped, "../infra/main.tf", false},
+		{"no restriction", unscoped, "src/app.go", true},
+		{"no restriction outside the working directory", unscoped, "../other.go", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(workingDir, filepath.FromSlash(tt.path))
+			if got := tt.skill.AllowsPath(path, workingDir); got != tt.want {
+				t.Errorf("AllowsPath(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
@@ -278,6 +278,18 @@ func renderAssistantMessage(
 				Foreground(t.TextMuted()).
 				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "permission denied")),
 			)
+		case message.FinishReasonBudgetExceeded:
+			info = append(info, baseStyle.
+				Width(width-1).
+				Foreground(t.TextMuted()).
+				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "budget exceeded")),
+			)
+		case message.FinishReasonMaxToolIterations:
+			info = append(info, baseStyle.
+				Width(width-1).
+				Foreground(t.TextMuted()).
+				Render(fmt.Sprintf(" %s (%s)", models.SupportedModels[msg.Model].Name, "tool call limit reached")),
+			)
 		}
 	}
 	contentRendered := false
@@ -348,6 +360,8 @@ func toolName(name string) string {
 		return "Edit"
 	case tools.MultiEditToolName:
 		return "MultiEdit"
+	case tools.SearchReplaceToolName:
+		return "SearchReplace"
 	case tools.WebFetchToolName:
 		return "Fetch"
 	case tools.GlobToolName:
@@ -360,6 +374,24 @@ func toolName(name string) string {
 		return "Sourcegraph"
 	case tools.ReadToolName:
 		return "View"
+	case tools.ReadManyToolName:
+		return "View Many"
+	case tools.ReadSymbolToolName:
+		return "View Symbol"
+	case tools.ExistsToolName:
+		return "Exists"
+	case tools.RepoOverviewToolName:
+		return "Repo Overview"
+	case tools.DirDiffToolName:
+		return "Dir Diff"
+	case tools.GitDiffRangeToolName:
+		return "Git Diff Range"
+	case tools.EnvInfoToolName:
+		return "Env Info"
+	case tools.ProposeEditToolName:
+		return "Propose Edit"
+	case tools.ApplyProposalToolName:
+		return "Apply Proposal"
 	case tools.ViewImageToolName:
 		return "View Image"
 	case tools.WriteToolName:
@@ -370,6 +402,10 @@ func toolName(name string) string {
 		return "Delete"
 	case tools.LSPToolName:
 		return "Code Intelligence"
+	case tools.RenameSymbolToolName:
+		return "Rename Symbol"
+	case tools.DiagnosticsToolName:
+		return "Diagnostics"
 	case tools.StructOutputToolName:
 		return "Structured Output"
 	case tools.PlanTaskToolName:
@@ -389,7 +425,7 @@ func getToolAction(name string) string {
 		return "Building command..."
 	case tools.EditToolName:
 		return "Preparing edit..."
-	case tools.MultiEditToolName:
+	case tools.MultiEditToolName, tools.SearchReplaceToolName:
 		return "Preparing edits..."
 	case tools.WebFetchToolName:
 		return "Writing fetch..."
@@ -403,6 +439,24 @@ func getToolAction(name string) string {
 		return "Searching code..."
 	case tools.ReadToolName:
 		return "Reading file..."
+	case tools.ReadManyToolName:
+		return "Reading files..."
+	case tools.ReadSymbolToolName:
+		return "Reading symbol..."
+	case tools.ExistsToolName:
+		return "Checking path..."
+	case tools.RepoOverviewToolName:
+		return "Summarizing repository..."
+	case tools.DirDiffToolName:
+		return "Comparing directories..."
+	case tools.GitDiffRangeToolName:
+		return "Comparing refs..."
+	case tools.EnvInfoToolName:
+		return "Inspecting environment..."
+	case tools.ProposeEditToolName:
+		return "Preparing proposal..."
+	case tools.ApplyProposalToolName:
+		return "Applying proposal..."
 	case tools.ViewImageToolName:
 		return "Loading image..."
 	case tools.WriteToolName:
@@ -413,6 +467,10 @@ func getToolAction(name string) string {
 		return "Deleting..."
 	case tools.LSPToolName:
 		return "Doing code intelligence..."
+	case tools.RenameSymbolToolName:
+		return "Preparing rename..."
+	case tools.DiagnosticsToolName:
+		return "Checking diagnostics..."
 	case tools.StructOutputToolName:
 		return "Formatting output..."
 	case tools.PlanTaskToolName:
@@ -524,6 +582,11 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		filePath := removeWorkingDirPrefix(params.FilePath)
 		return renderParams(paramWidth, filePath, "edits", strconv.Itoa(len(params.Edits)))
+	case tools.SearchReplaceToolName:
+		var params tools.SearchReplaceParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		filePath := removeWorkingDirPrefix(params.FilePath)
+		return renderParams(paramWidth, filePath)
 	case tools.WebFetchToolName:
 		var params tools.FetchParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -582,6 +645,51 @@ func renderToolParams(paramWidth int, toolCall message.ToolCall) string {
 		var params tools.LSParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
 		return renderParams(paramWidth, params.Path, "ignore: "+strings.Join(params.Ignore, ", "))
+	case tools.ReadManyToolName:
+		var params tools.ReadManyParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		paths := make([]string, len(params.Paths))
+		for i, p := range params.Paths {
+			paths[i] = removeWorkingDirPrefix(p)
+		}
+		return renderParams(paramWidth, strings.Join(paths, ", "))
+	case tools.ReadSymbolToolName:
+		var params tools.ReadSymbolParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, removeWorkingDirPrefix(params.FilePath), "symbol", params.Symbol)
+	case tools.ExistsToolName:
+		var params tools.ExistsParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, removeWorkingDirPrefix(params.Path))
+	case tools.RepoOverviewToolName:
+		var params tools.RepoOverviewParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		if params.Depth > 0 {
+			return renderParams(paramWidth, fmt.Sprintf("depth=%d", params.Depth))
+		}
+		return ""
+	case tools.DirDiffToolName:
+		var params tools.DirDiffParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, removeWorkingDirPrefix(params.Left), "right", removeWorkingDirPrefix(params.Right), "glob", params.Glob)
+	case tools.GitDiffRangeToolName:
+		var params tools.GitDiffRangeParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.From, "to", params.To, "glob", params.Glob)
+	case tools.EnvInfoToolName:
+		return ""
+	case tools.DiagnosticsToolName:
+		var params tools.DiagnosticsParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, removeWorkingDirPrefix(params.FilePath))
+	case tools.ProposeEditToolName:
+		var params tools.ProposeEditParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, removeWorkingDirPrefix(params.FilePath), "edits", strconv.Itoa(len(params.Edits)))
+	case tools.ApplyProposalToolName:
+		var params tools.ApplyProposalParams
+		json.Unmarshal([]byte(toolCall.Input), &params)
+		return renderParams(paramWidth, params.ProposalID)
 	case tools.ReadToolName:
 		var params tools.ViewParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
@@ -683,12 +791,24 @@ func renderToolResponse(toolCall message.ToolCall, response message.ToolResult,
 		truncDiff := truncateHeight(metadata.Diff, maxResultHeight)
 		formattedDiff, _ := diff.FormatDiff(truncDiff, diff.WithTotalWidth(width))
 		return formattedDiff
-	case tools.MultiEditToolName:
+	case tools.MultiEditToolName, tools.ApplyProposalToolName:
 		metadata := tools.MultiEditResponseMetadata{}
 		json.Unmarshal([]byte(response.Metadata), &metadata)
 		truncDiff := truncateHeight(metadata.Diff, maxResultHeight)
 		formattedDiff, _ := diff.FormatDiff(truncDiff, diff.WithTotalWidth(width))
 		return formattedDiff
+	case tools.ProposeEditToolName:
+		metadata := tools.ProposeEditResponseMetadata{}
+		json.Unmarshal([]byte(response.Metadata), &metadata)
+		truncDiff := truncateHeight(metadata.Diff, maxResultHeight)
+		formattedDiff, _ := diff.FormatDiff(truncDiff, diff.WithTotalWidth(width))
+		return formattedDiff
+	case tools.SearchReplaceToolName:
+		metadata := tools.SearchReplaceResponseMetadata{}
+		json.Unmarshal([]byte(response.Metadata), &metadata)
+		truncDiff := truncateHeight(metadata.Diff, maxResultHeight)
+		formattedDiff, _ := diff.FormatDiff(truncDiff, diff.WithTotalWidth(width))
+		return formattedDiff
 	case tools.WebFetchToolName:
 		var params tools.FetchParams
 		json.Unmarshal([]byte(toolCall.Input), &params)
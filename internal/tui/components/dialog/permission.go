@@ -246,7 +246,7 @@ func (p *permissionDialogCmp) renderHeader() string {
 			),
 			baseStyle.Render(strings.Repeat(" ", p.width)),
 		)
-	case tools.MultiEditToolName:
+	case tools.MultiEditToolName, tools.SearchReplaceToolName:
 		params := p.permission.Params.(tools.MultiEditPermissionsParams)
 		fileKey := baseStyle.Foreground(t.TextMuted()).Bold(true).Render("File")
 		filePath := baseStyle.
@@ -523,9 +523,9 @@ func (p *permissionDialogCmp) render() string {
 		contentFinal = p.renderBashContent()
 	case tools.EditToolName:
 		contentFinal = p.renderEditContent()
-	case tools.MultiEditToolName:
+	case tools.MultiEditToolName, tools.SearchReplaceToolName:
 		contentFinal = p.renderMultiEditContent()
-	case tools.PatchToolName:
+	case tools.PatchToolName, tools.RenameSymbolToolName:
 		contentFinal = p.renderPatchContent()
 	case tools.WriteToolName:
 		contentFinal = p.renderWriteContent()
@@ -575,7 +575,7 @@ func (p *permissionDialogCmp) SetSize() tea.Cmd {
 	case tools.BashToolName:
 		p.width = max(40, int(float64(p.windowSize.Width)*0.4))
 		p.height = max(15, int(float64(p.windowSize.Height)*0.4))
-	case tools.EditToolName, tools.MultiEditToolName:
+	case tools.EditToolName, tools.MultiEditToolName, tools.SearchReplaceToolName:
 		p.width = int(float64(p.windowSize.Width) * 0.8)
 		p.height = int(float64(p.windowSize.Height) * 0.8)
 	case tools.WriteToolName:
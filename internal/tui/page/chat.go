@@ -236,6 +236,9 @@ func (p *chatPage) clearSidebar() tea.Cmd {
 func (p *chatPage) sendMessage(text string, attachments []message.Attachment) tea.Cmd {
 	var cmds []tea.Cmd
 	if p.session.ID == "" {
+		if err := p.app.ApplySessionWorkingDir(); err != nil {
+			return util.ReportError(err)
+		}
 		var sess session.Session
 		var err error
 		if p.app.InitialSessionID != "" {